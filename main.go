@@ -10,6 +10,7 @@ import (
 // Version information (can be set during build)
 var (
 	Version   = "dev"
+	Commit    = "" // taken from the VCS information Go embeds when empty
 	BuildTime = "unknown"
 	BuildOS   = "unknown"
 	BuildArch = "unknown"
@@ -17,7 +18,7 @@ var (
 
 func main() {
 	// Set version information in CLI package
-	cli.SetVersion(Version, BuildTime, BuildOS, BuildArch)
+	cli.SetVersion(Version, Commit, BuildTime, BuildOS, BuildArch)
 
 	// Create CLI with arguments (skip the program name)
 	c := cli.New(os.Args[1:])
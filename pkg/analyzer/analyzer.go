@@ -0,0 +1,168 @@
+package analyzer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+// Analyzer is the interface for AI analysis providers
+type Analyzer interface {
+	// Name returns the analyzer name (e.g., "gemini"), as listed in AI_ANALYZERS
+	Name() string
+
+	// Enabled returns true if the analyzer is configured
+	Enabled() bool
+
+	// Analyze analyzes an audit result of app. It returns nil when the app's results
+	// are not analyzed.
+	Analyze(ctx context.Context, app models.AppConfig, result *models.AuditResult) (*models.AIAnalysis, error)
+
+	// AnalyzeFleet analyzes the results of all apps of a summary together. It returns
+	// nil when none is vulnerable.
+	AnalyzeFleet(ctx context.Context, summary *models.AuditSummary) (*models.FleetAnalysis, error)
+
+	// Close releases the analyzer's clients
+	Close() error
+}
+
+// Manager analyzes audit results with the registered analyzers in priority order: the
+// first enabled one analyzes, and the next ones take over when it fails. Apps can turn
+// the analysis off.
+type Manager struct {
+	analyzers  map[string]Analyzer
+	registered []string // analyzer names in registration order
+	order      []string // analyzer names by priority; empty for registration order
+	mu         sync.RWMutex
+}
+
+// NewManager creates a new Manager trying the analyzers named in order, first to last.
+// Registered analyzers not in order are not used; with an empty order, every analyzer is
+// tried in registration order.
+func NewManager(order []string) *Manager {
+	return &Manager{
+		analyzers: make(map[string]Analyzer),
+		order:     order,
+	}
+}
+
+// Register adds an analyzer, replacing any with the same name
+func (m *Manager) Register(a Analyzer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !slices.Contains(m.registered, a.Name()) {
+		m.registered = append(m.registered, a.Name())
+	}
+	m.analyzers[a.Name()] = a
+}
+
+// Get returns an analyzer by name
+func (m *Manager) Get(name string) (Analyzer, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	a, ok := m.analyzers[name]
+	return a, ok
+}
+
+// EnabledAnalyzers returns the names of the enabled analyzers, by priority
+func (m *Manager) EnabledAnalyzers() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var names []string
+	for _, a := range m.chain() {
+		names = append(names, a.Name())
+	}
+	return names
+}
+
+// Enabled returns true if results are analyzed
+func (m *Manager) Enabled() bool {
+	return len(m.EnabledAnalyzers()) > 0
+}
+
+// Analyze analyzes an audit result of app with the first analyzer that succeeds. It
+// returns nil when the app's results are not analyzed.
+func (m *Manager) Analyze(ctx context.Context, app models.AppConfig, result *models.AuditResult) (*models.AIAnalysis, error) {
+	if app.AIDisabled {
+		return nil, nil
+	}
+
+	m.mu.RLock()
+	chain := m.chain()
+	m.mu.RUnlock()
+
+	var errs []error
+	for _, a := range chain {
+		analysis, err := a.Analyze(ctx, app, result)
+		if err == nil {
+			return analysis, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", a.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+		zap.S().Warnf("Analyzer failed app=%s analyzer=%s, trying the next one: %v", app.Name, a.Name(), err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// AnalyzeFleet analyzes the results of all apps of a summary together with the first
+// analyzer that succeeds. It returns nil when results are not analyzed or none is
+// vulnerable.
+func (m *Manager) AnalyzeFleet(ctx context.Context, summary *models.AuditSummary) (*models.FleetAnalysis, error) {
+	m.mu.RLock()
+	chain := m.chain()
+	m.mu.RUnlock()
+
+	var errs []error
+	for _, a := range chain {
+		analysis, err := a.AnalyzeFleet(ctx, summary)
+		if err == nil {
+			return analysis, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", a.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+		zap.S().Warnf("Fleet analyzer failed analyzer=%s, trying the next one: %v", a.Name(), err)
+	}
+	return nil, errors.Join(errs...)
+}
+
+// chain returns the enabled analyzers by priority. The caller holds mu.
+func (m *Manager) chain() []Analyzer {
+	order := m.order
+	if len(order) == 0 {
+		order = m.registered
+	}
+
+	var chain []Analyzer
+	for _, name := range order {
+		if a, ok := m.analyzers[name]; ok && a.Enabled() {
+			chain = append(chain, a)
+		}
+	}
+	return chain
+}
+
+// Close closes every analyzer
+func (m *Manager) Close() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var errs []error
+	for _, a := range m.analyzers {
+		if err := a.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", a.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,272 @@
+package analyzer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/google/generative-ai-go/genai"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// fleetPromptVersion is the version of fleetPromptTemplate (see analysisPromptVersion)
+	fleetPromptVersion = "1"
+	// fleetPromptPackages limits the packages listed in the fleet prompt
+	fleetPromptPackages = 60
+	// fleetAdvisories limits the advisories listed per package in the fleet prompt
+	fleetAdvisories = 3
+	// fallbackSystemicPackages limits the systemic packages of the fallback analysis
+	fallbackSystemicPackages = 10
+)
+
+// fleetPackage is a vulnerable package across the apps of a summary
+type fleetPackage struct {
+	Name       string
+	Severity   string // highest across the apps
+	Apps       []string
+	Advisories []string
+	Patched    string // patched versions, when known
+}
+
+// fleetPackages groups the vulnerabilities of a summary by package, the packages
+// vulnerable in the most apps and with the highest severity first
+func fleetPackages(summary *models.AuditSummary) []fleetPackage {
+	byName := make(map[string]*fleetPackage)
+	for _, r := range summary.Results {
+		for _, v := range r.Vulnerabilities {
+			pkg, ok := byName[v.PackageName]
+			if !ok {
+				pkg = &fleetPackage{Name: v.PackageName, Severity: v.Severity}
+				byName[v.PackageName] = pkg
+			}
+			if models.SeverityOrder[v.Severity] > models.SeverityOrder[pkg.Severity] {
+				pkg.Severity = v.Severity
+			}
+			if !slices.Contains(pkg.Apps, r.AppName) {
+				pkg.Apps = append(pkg.Apps, r.AppName)
+			}
+			id := v.CVEID
+			if id == "" {
+				id = v.Title
+			}
+			if id != "" && !slices.Contains(pkg.Advisories, id) {
+				pkg.Advisories = append(pkg.Advisories, id)
+			}
+			if pkg.Patched == "" {
+				pkg.Patched = v.PatchedVersions
+			}
+		}
+	}
+
+	packages := make([]fleetPackage, 0, len(byName))
+	for _, pkg := range byName {
+		sort.Strings(pkg.Apps)
+		packages = append(packages, *pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		x, y := packages[i], packages[j]
+		if len(x.Apps) != len(y.Apps) {
+			return len(x.Apps) > len(y.Apps)
+		}
+		if models.SeverityOrder[x.Severity] != models.SeverityOrder[y.Severity] {
+			return models.SeverityOrder[x.Severity] > models.SeverityOrder[y.Severity]
+		}
+		return x.Name < y.Name
+	})
+	return packages
+}
+
+// fleetPromptData holds data for the fleet prompt template
+type fleetPromptData struct {
+	Summary  *models.AuditSummary
+	Packages []fleetPackage
+	Omitted  int
+}
+
+// fleetPromptTemplate is the template for fleet analysis prompts
+var fleetPromptTemplate = template.Must(template.New("fleet").Funcs(template.FuncMap{
+	"join": strings.Join,
+	"advisories": func(ids []string) string {
+		if len(ids) <= fleetAdvisories {
+			return strings.Join(ids, ", ")
+		}
+		return fmt.Sprintf("%s and %d more", strings.Join(ids[:fleetAdvisories], ", "), len(ids)-fleetAdvisories)
+	},
+}).Parse(`
+You are a security analyst reviewing the vulnerabilities found across a fleet of {{.Summary.TotalApps}} applications
+audited together ({{.Summary.AppsWithVulns}} of them vulnerable, {{.Summary.TotalVulnerabilities}} findings:
+{{.Summary.CriticalCount}} critical, {{.Summary.HighCount}} high, {{.Summary.ModerateCount}} moderate, {{.Summary.LowCount}} low, {{.Summary.InfoCount}} info).
+Each application was already analyzed on its own; look at the fleet as a whole instead.
+
+Analyze these vulnerable packages and provide a JSON response with the following structure:
+{
+  "summary": "A plain-language summary (2-4 sentences) of the security situation of the fleet for non-technical stakeholders",
+  "themes": ["theme1", "theme2", ...],
+  "systemic_packages": [{"package": "name", "note": "why it matters across the fleet"}, ...],
+  "campaigns": ["campaign1", "campaign2", ...]
+}
+
+Guidelines:
+- summary: Mention how concentrated the risk is (a few packages or apps, or spread out)
+- themes: Recurring kinds of vulnerabilities or causes (e.g. "outdated build tooling", "prototype pollution in utility libraries")
+- systemic_packages: Packages vulnerable in several applications, most important first, only from the list below
+- campaigns: Fleet-wide remediation campaigns, most effective first, e.g. "Upgrade lodash to 4.17.21 in the 12 apps using it"
+
+Vulnerable packages (apps affected, highest severity, advisories):
+{{range .Packages}}
+- Package: {{.Name}}
+  Severity: {{.Severity}}
+  Apps ({{len .Apps}}): {{join .Apps ", "}}
+  Advisories: {{advisories .Advisories}}
+  Patched Versions: {{if .Patched}}{{.Patched}}{{else}}Unknown{{end}}
+{{end}}{{if .Omitted}}
+({{.Omitted}} more packages vulnerable in fewer apps are not listed)
+{{end}}
+Respond ONLY with valid JSON. Do not include any markdown formatting or explanation outside the JSON.
+`))
+
+// AnalyzeFleet sends the vulnerabilities of all apps of a summary to Gemini for the
+// fleet-level analysis, with the default model. It returns nil when nothing is vulnerable.
+func (g *GeminiAnalyzer) AnalyzeFleet(ctx context.Context, summary *models.AuditSummary) (*models.FleetAnalysis, error) {
+	if !g.enabled {
+		return nil, nil
+	}
+
+	packages := fleetPackages(summary)
+	if len(packages) == 0 {
+		return nil, nil
+	}
+	m := g.modelOf(g.defaultModel)
+
+	zap.S().Infof("Sending fleet vulnerabilities to Gemini for analysis apps=%d packages=%d model=%s",
+		summary.AppsWithVulns,
+		len(packages),
+		m.name,
+	)
+
+	data := fleetPromptData{
+		Summary:  summary,
+		Packages: packages[:min(len(packages), fleetPromptPackages)],
+		Omitted:  max(len(packages)-fleetPromptPackages, 0),
+	}
+	var prompt bytes.Buffer
+	if err := fleetPromptTemplate.Execute(&prompt, data); err != nil {
+		return nil, fmt.Errorf("failed to build prompt: %w", err)
+	}
+
+	started := time.Now()
+	resp, err := m.model.GenerateContent(ctx, genai.Text(prompt.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+	provenance := m.provenance(ctx, resp, time.Since(started), fleetPromptVersion)
+
+	analysis, err := parseFleetResponse(resp, packages)
+	if err != nil {
+		zap.S().Warnf("Failed to parse Gemini fleet response, using fallback: %v", err)
+		analysis = fallbackFleetAnalysis(summary, packages)
+		provenance.Fallback = true
+	}
+	analysis.Provenance = provenance
+
+	zap.S().Infof("Gemini fleet analysis completed apps=%d model=%s latency_ms=%d prompt_tokens=%d output_tokens=%d",
+		summary.AppsWithVulns, m.name, provenance.LatencyMs, provenance.PromptTokens, provenance.OutputTokens)
+	return analysis, nil
+}
+
+// parseFleetResponse parses the Gemini response into a FleetAnalysis. The apps and
+// severity of the systemic packages come from the findings, not from Gemini, and
+// packages that are not among them are dropped.
+func parseFleetResponse(resp *genai.GenerateContentResponse, packages []fleetPackage) (*models.FleetAnalysis, error) {
+	responseText, err := responseJSON(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed struct {
+		Summary          string   `json:"summary"`
+		Themes           []string `json:"themes"`
+		SystemicPackages []struct {
+			Package string `json:"package"`
+			Note    string `json:"note"`
+		} `json:"systemic_packages"`
+		Campaigns []string `json:"campaigns"`
+	}
+	if err := json.Unmarshal([]byte(responseText), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w (response: %s)", err, responseText)
+	}
+
+	analysis := &models.FleetAnalysis{
+		Summary:          parsed.Summary,
+		Themes:           parsed.Themes,
+		SystemicPackages: []models.SystemicPackage{},
+		Campaigns:        parsed.Campaigns,
+	}
+	for _, s := range parsed.SystemicPackages {
+		i := slices.IndexFunc(packages, func(p fleetPackage) bool { return p.Name == s.Package })
+		if i < 0 {
+			continue
+		}
+		analysis.SystemicPackages = append(analysis.SystemicPackages, models.SystemicPackage{
+			Package:  packages[i].Name,
+			Severity: packages[i].Severity,
+			Apps:     packages[i].Apps,
+			Note:     s.Note,
+		})
+	}
+	return analysis, nil
+}
+
+// fallbackFleetAnalysis creates a basic fleet analysis when Gemini fails: the packages
+// vulnerable in several apps, and a campaign to upgrade each
+func fallbackFleetAnalysis(summary *models.AuditSummary, packages []fleetPackage) *models.FleetAnalysis {
+	analysis := &models.FleetAnalysis{
+		Summary: fmt.Sprintf("Found %d vulnerabilities in %d of %d apps: %d critical, %d high, %d moderate, %d low, %d info.",
+			summary.TotalVulnerabilities,
+			summary.AppsWithVulns,
+			summary.TotalApps,
+			summary.CriticalCount,
+			summary.HighCount,
+			summary.ModerateCount,
+			summary.LowCount,
+			summary.InfoCount,
+		),
+		Themes:           []string{},
+		SystemicPackages: []models.SystemicPackage{},
+		Campaigns:        []string{},
+	}
+
+	for _, pkg := range packages {
+		if len(pkg.Apps) < 2 || len(analysis.SystemicPackages) == fallbackSystemicPackages {
+			break
+		}
+		analysis.SystemicPackages = append(analysis.SystemicPackages, models.SystemicPackage{
+			Package:  pkg.Name,
+			Severity: pkg.Severity,
+			Apps:     pkg.Apps,
+		})
+
+		campaign := fmt.Sprintf("Upgrade %s in the %d apps using it", pkg.Name, len(pkg.Apps))
+		if pkg.Patched != "" {
+			campaign = fmt.Sprintf("Upgrade %s to %s in the %d apps using it", pkg.Name, pkg.Patched, len(pkg.Apps))
+		}
+		analysis.Campaigns = append(analysis.Campaigns, campaign)
+	}
+
+	switch n := len(analysis.SystemicPackages); {
+	case n == 1:
+		analysis.Summary += fmt.Sprintf(" %s is vulnerable in %d apps; upgrading it fleet-wide fixes the most findings.",
+			analysis.SystemicPackages[0].Package, len(analysis.SystemicPackages[0].Apps))
+	case n > 1:
+		analysis.Summary += fmt.Sprintf(" %d packages are vulnerable in several apps; upgrading them fleet-wide fixes the most findings.", n)
+	}
+	return analysis
+}
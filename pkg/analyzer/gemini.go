@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 
 	"github.com/google/generative-ai-go/genai"
 	"github.com/shadowbane/audit-checks/pkg/models"
@@ -14,20 +16,46 @@ import (
 	"google.golang.org/api/option"
 )
 
-// GeminiAnalyzer provides AI-powered vulnerability analysis using Google Gemini
+// ProviderGemini is the provider recorded in the provenance of Gemini analyses
+const ProviderGemini = "gemini"
+
+// analysisPromptVersion is the version of promptTemplate, recorded with each analysis so
+// that analyses made with different prompts are not compared as equals. Increment it
+// when the prompt changes.
+const analysisPromptVersion = "2"
+
+// GeminiAnalyzer provides AI-powered vulnerability analysis using Google Gemini. Each
+// app's results are analyzed with its own model (e.g. a pro model for payment apps, a
+// lite one for internal tools) or the default one; every model shares one client.
 type GeminiAnalyzer struct {
-	client    *genai.Client
-	model     *genai.GenerativeModel
-	modelName string
-	enabled   bool
+	client        *genai.Client
+	defaultModel  string
+	maxAdvisories int // listed in a prompt, the others are only counted
+	enabled       bool
+
+	models map[string]*geminiModel // by name, created on first use
+	mu     sync.Mutex
+}
+
+// geminiModel is a Gemini model configured for the analyses
+type geminiModel struct {
+	model *genai.GenerativeModel
+	name  string
+
+	version     string // of the model, fetched once
+	versionOnce sync.Once
 }
 
-// NewGeminiAnalyzer creates a new GeminiAnalyzer
-func NewGeminiAnalyzer(ctx context.Context, apiKey string, modelName string, enabled bool) (*GeminiAnalyzer, error) {
+// NewGeminiAnalyzer creates a new GeminiAnalyzer using defaultModel for apps without
+// their own. It analyzes nothing when not enabled or without an API key.
+func NewGeminiAnalyzer(ctx context.Context, apiKey string, defaultModel string, enabled bool) (*GeminiAnalyzer, error) {
+	g := &GeminiAnalyzer{
+		defaultModel:  defaultModel,
+		maxAdvisories: DefaultMaxAdvisories,
+		models:        make(map[string]*geminiModel),
+	}
 	if !enabled || apiKey == "" {
-		return &GeminiAnalyzer{
-			enabled: false,
-		}, nil
+		return g, nil
 	}
 
 	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
@@ -35,21 +63,21 @@ func NewGeminiAnalyzer(ctx context.Context, apiKey string, modelName string, ena
 		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
 	}
 
-	zap.S().Infof("Using Gemini model: %s", modelName)
-	model := client.GenerativeModel(modelName)
-	model.SetTemperature(0.2)
-	model.SetTopK(40)
-	model.SetTopP(0.95)
+	zap.S().Infof("Using Gemini model: %s", defaultModel)
+	g.client = client
+	g.enabled = true
+	return g, nil
+}
 
-	// Configure for JSON output
-	model.ResponseMIMEType = "application/json"
+// Name returns "gemini"
+func (g *GeminiAnalyzer) Name() string {
+	return ProviderGemini
+}
 
-	return &GeminiAnalyzer{
-		client:    client,
-		model:     model,
-		modelName: modelName,
-		enabled:   true,
-	}, nil
+// SetMaxAdvisories sets the number of advisories listed in a prompt, the most important
+// ones; the others are only counted. 0 lists them all.
+func (g *GeminiAnalyzer) SetMaxAdvisories(n int) {
+	g.maxAdvisories = n
 }
 
 // Enabled returns true if the analyzer is enabled
@@ -57,9 +85,22 @@ func (g *GeminiAnalyzer) Enabled() bool {
 	return g.enabled
 }
 
-// Analyze sends vulnerability data to Gemini for enhanced analysis
-func (g *GeminiAnalyzer) Analyze(ctx context.Context, result *models.AuditResult) (*models.AIAnalysis, error) {
-	if !g.enabled {
+// Model returns the model analyzing the results of app, or "" when they are not analyzed
+func (g *GeminiAnalyzer) Model(app models.AppConfig) string {
+	if !g.enabled || app.AIDisabled {
+		return ""
+	}
+	if app.AIModel != "" {
+		return app.AIModel
+	}
+	return g.defaultModel
+}
+
+// Analyze sends vulnerability data to Gemini for enhanced analysis, with the model of
+// app. It returns nil when the app's results are not analyzed.
+func (g *GeminiAnalyzer) Analyze(ctx context.Context, app models.AppConfig, result *models.AuditResult) (*models.AIAnalysis, error) {
+	modelName := g.Model(app)
+	if modelName == "" {
 		return nil, nil
 	}
 
@@ -71,33 +112,118 @@ func (g *GeminiAnalyzer) Analyze(ctx context.Context, result *models.AuditResult
 			RiskAssessment: "No security risks identified.",
 		}, nil
 	}
+	m := g.modelOf(modelName)
 
-	zap.S().Infof("[%s] Sending vulnerabilities to Gemini for analysis app=%s count=%d",
+	zap.S().Infof("[%s] Sending vulnerabilities to Gemini for analysis app=%s count=%d model=%s",
 		result.AuditorType,
 		result.AppName,
 		len(result.Vulnerabilities),
+		m.name,
 	)
 
-	prompt, err := g.buildPrompt(result)
+	prompt, selection, err := g.buildPrompt(result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build prompt: %w", err)
 	}
+	if selection.Omitted > 0 {
+		zap.S().Infof("[%s] Prompt lists the %d most important of %d advisories app=%s omitted=%q",
+			result.AuditorType,
+			len(selection.Advisories),
+			len(selection.Advisories)+selection.Omitted,
+			result.AppName,
+			selection.OmittedCounts(),
+		)
+	}
 
-	resp, err := g.model.GenerateContent(ctx, genai.Text(prompt))
+	started := time.Now()
+	resp, err := m.model.GenerateContent(ctx, genai.Text(prompt))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate content: %w", err)
 	}
+	provenance := m.provenance(ctx, resp, time.Since(started), analysisPromptVersion)
 
 	analysis, err := g.parseResponse(resp)
 	if err != nil {
 		zap.S().Warnf("Failed to parse Gemini response, using fallback: %v", err)
-		return g.fallbackAnalysis(result), nil
+		analysis = g.fallbackAnalysis(result)
+		provenance.Fallback = true
 	}
+	analysis.Provenance = provenance
 
-	zap.S().Infof("[%s] Gemini analysis completed for app=%s", result.AuditorType, result.AppName)
+	zap.S().Infof("[%s] Gemini analysis completed for app=%s model=%s latency_ms=%d prompt_tokens=%d output_tokens=%d",
+		result.AuditorType,
+		result.AppName,
+		m.name,
+		provenance.LatencyMs,
+		provenance.PromptTokens,
+		provenance.OutputTokens,
+	)
 	return analysis, nil
 }
 
+// modelOf returns the model named name, configured on first use
+func (g *GeminiAnalyzer) modelOf(name string) *geminiModel {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	m, ok := g.models[name]
+	if !ok {
+		model := g.client.GenerativeModel(name)
+		model.SetTemperature(0.2)
+		model.SetTopK(40)
+		model.SetTopP(0.95)
+
+		// Configure for JSON output
+		model.ResponseMIMEType = "application/json"
+
+		m = &geminiModel{model: model, name: name}
+		g.models[name] = m
+	}
+	return m
+}
+
+// provenance returns the provenance of an analysis answered by resp after latency
+func (m *geminiModel) provenance(ctx context.Context, resp *genai.GenerateContentResponse, latency time.Duration, promptVersion string) *models.AIProvenance {
+	provenance := &models.AIProvenance{
+		Provider:      ProviderGemini,
+		Model:         m.name,
+		ModelVersion:  m.modelVersion(ctx),
+		PromptVersion: promptVersion,
+		LatencyMs:     latency.Milliseconds(),
+	}
+	if usage := resp.UsageMetadata; usage != nil {
+		provenance.PromptTokens = int(usage.PromptTokenCount)
+		provenance.OutputTokens = int(usage.CandidatesTokenCount)
+	}
+	return provenance
+}
+
+// modelVersion returns the version of the model, fetched on first use; empty when unknown
+func (m *geminiModel) modelVersion(ctx context.Context) string {
+	m.versionOnce.Do(func() {
+		info, err := m.model.Info(ctx)
+		if err != nil {
+			zap.S().Debugf("Failed to get Gemini model info model=%s: %v", m.name, err)
+			return
+		}
+		m.version = info.Version
+	})
+	return m.version
+}
+
+// Ping sends a minimal request to verify the API key and default model name
+func (g *GeminiAnalyzer) Ping(ctx context.Context) error {
+	if !g.enabled {
+		return fmt.Errorf("gemini analyzer is not enabled")
+	}
+
+	if _, err := g.modelOf(g.defaultModel).model.GenerateContent(ctx, genai.Text(`Respond with {"ok": true}`)); err != nil {
+		return fmt.Errorf("gemini request failed for model %s: %w", g.defaultModel, err)
+	}
+
+	return nil
+}
+
 // Close closes the Gemini client
 func (g *GeminiAnalyzer) Close() error {
 	if g.client != nil {
@@ -108,13 +234,15 @@ func (g *GeminiAnalyzer) Close() error {
 
 // promptData holds data for the prompt template
 type promptData struct {
-	AppName         string
-	AuditorType     string
-	Vulnerabilities []models.Vulnerability
+	AppName     string
+	AuditorType string
+	promptSelection
 }
 
 // promptTemplate is the template for Gemini prompts
-var promptTemplate = template.Must(template.New("prompt").Parse(`
+var promptTemplate = template.Must(template.New("prompt").Funcs(template.FuncMap{
+	"add": func(a, b int) int { return a + b },
+}).Parse(`
 You are a security analyst reviewing vulnerabilities found in a {{.AuditorType}} project named "{{.AppName}}".
 
 Analyze these vulnerabilities and provide a JSON response with the following structure:
@@ -131,44 +259,61 @@ Guidelines:
 - remediation: Provide specific commands to fix each vulnerability (e.g., "npm update lodash@4.17.21")
 - risk_assessment: Explain the business impact in terms non-technical stakeholders can understand
 
-Vulnerabilities found:
-{{range .Vulnerabilities}}
-- Package: {{.PackageName}}
+Vulnerabilities found ({{.Findings}} findings, {{len .Advisories}}{{if .Omitted}} most important of {{add (len .Advisories) .Omitted}}{{end}} advisories listed):
+{{range .Advisories}}
+- Package: {{.PackageList}}
   Severity: {{.Severity}}
-  CVE: {{if .CVEID}}{{.CVEID}}{{else}}N/A{{end}}
+  CVE: {{if .ID}}{{.ID}}{{else}}N/A{{end}}
   Title: {{.Title}}
   Vulnerable Versions: {{.VulnerableVersions}}
   Patched Versions: {{if .PatchedVersions}}{{.PatchedVersions}}{{else}}Unknown{{end}}
+{{end}}{{if .Omitted}}
+Not listed: {{.Omitted}} lower-priority advisories ({{.OmittedCounts}}). Take them into account in the summary
+and risk assessment, but base the priority and remediation on the listed ones.
 {{end}}
-
 Respond ONLY with valid JSON. Do not include any markdown formatting or explanation outside the JSON.
 `))
 
-// buildPrompt creates the prompt for Gemini
-func (g *GeminiAnalyzer) buildPrompt(result *models.AuditResult) (string, error) {
+// buildPrompt creates the prompt for Gemini from the most important advisories of result
+func (g *GeminiAnalyzer) buildPrompt(result *models.AuditResult) (string, promptSelection, error) {
 	data := promptData{
 		AppName:         result.AppName,
 		AuditorType:     result.AuditorType,
-		Vulnerabilities: result.Vulnerabilities,
+		promptSelection: selectAdvisories(result.Vulnerabilities, g.maxAdvisories),
 	}
 
 	var buf bytes.Buffer
 	if err := promptTemplate.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute template: %w", err)
+		return "", data.promptSelection, fmt.Errorf("failed to execute template: %w", err)
 	}
 
-	return buf.String(), nil
+	return buf.String(), data.promptSelection, nil
 }
 
 // parseResponse parses the Gemini response into AIAnalysis
 func (g *GeminiAnalyzer) parseResponse(resp *genai.GenerateContentResponse) (*models.AIAnalysis, error) {
+	responseText, err := responseJSON(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var analysis models.AIAnalysis
+	if err := json.Unmarshal([]byte(responseText), &analysis); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w (response: %s)", err, responseText)
+	}
+
+	return &analysis, nil
+}
+
+// responseJSON returns the JSON text of a Gemini response
+func responseJSON(resp *genai.GenerateContentResponse) (string, error) {
 	if len(resp.Candidates) == 0 {
-		return nil, fmt.Errorf("no candidates in response")
+		return "", fmt.Errorf("no candidates in response")
 	}
 
 	candidate := resp.Candidates[0]
 	if candidate.Content == nil || len(candidate.Content.Parts) == 0 {
-		return nil, fmt.Errorf("no content in candidate")
+		return "", fmt.Errorf("no content in candidate")
 	}
 
 	// Extract text from response
@@ -180,7 +325,7 @@ func (g *GeminiAnalyzer) parseResponse(resp *genai.GenerateContentResponse) (*mo
 	}
 
 	if responseText == "" {
-		return nil, fmt.Errorf("empty response text")
+		return "", fmt.Errorf("empty response text")
 	}
 
 	// Clean up the response (remove markdown code blocks if present)
@@ -188,14 +333,7 @@ func (g *GeminiAnalyzer) parseResponse(resp *genai.GenerateContentResponse) (*mo
 	responseText = strings.TrimPrefix(responseText, "```json")
 	responseText = strings.TrimPrefix(responseText, "```")
 	responseText = strings.TrimSuffix(responseText, "```")
-	responseText = strings.TrimSpace(responseText)
-
-	var analysis models.AIAnalysis
-	if err := json.Unmarshal([]byte(responseText), &analysis); err != nil {
-		return nil, fmt.Errorf("failed to parse JSON response: %w (response: %s)", err, responseText)
-	}
-
-	return &analysis, nil
+	return strings.TrimSpace(responseText), nil
 }
 
 // fallbackAnalysis creates a basic analysis when Gemini fails
@@ -205,7 +343,7 @@ func (g *GeminiAnalyzer) fallbackAnalysis(result *models.AuditResult) *models.AI
 	seen := make(map[string]bool)
 
 	// Add critical first, then high, etc.
-	for _, severity := range []string{models.SeverityCritical, models.SeverityHigh, models.SeverityModerate, models.SeverityLow} {
+	for _, severity := range []string{models.SeverityCritical, models.SeverityHigh, models.SeverityModerate, models.SeverityLow, models.SeverityInfo} {
 		for _, v := range result.Vulnerabilities {
 			if v.Severity == severity && !seen[v.PackageName] {
 				priority = append(priority, v.PackageName)
@@ -223,12 +361,13 @@ func (g *GeminiAnalyzer) fallbackAnalysis(result *models.AuditResult) *models.AI
 	}
 
 	// Build summary
-	summary := fmt.Sprintf("Found %d vulnerabilities: %d critical, %d high, %d moderate, %d low.",
+	summary := fmt.Sprintf("Found %d vulnerabilities: %d critical, %d high, %d moderate, %d low, %d info.",
 		result.TotalVulnerabilities,
 		result.CriticalCount,
 		result.HighCount,
 		result.ModerateCount,
 		result.LowCount,
+		result.InfoCount,
 	)
 
 	if result.CriticalCount > 0 {
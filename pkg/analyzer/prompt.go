@@ -0,0 +1,138 @@
+package analyzer
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+const (
+	// DefaultMaxAdvisories is the number of advisories listed in a prompt by default
+	DefaultMaxAdvisories = 50
+	// promptPackages limits the packages listed per advisory in a prompt
+	promptPackages = 10
+)
+
+// promptAdvisory is an advisory of an audit result, with every package it was found in:
+// npm reports an advisory once per package, and lockfiles can repeat a finding
+type promptAdvisory struct {
+	ID                 string
+	Title              string
+	Severity           string // highest across the packages
+	Packages           []string
+	VulnerableVersions string
+	PatchedVersions    string
+	Findings           int
+	firstSeen          time.Time
+}
+
+// PackageList returns the packages of the advisory, the first ones only when it is in many
+func (p promptAdvisory) PackageList() string {
+	if len(p.Packages) <= promptPackages {
+		return strings.Join(p.Packages, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(p.Packages[:promptPackages], ", "), len(p.Packages)-promptPackages)
+}
+
+// promptSelection is the part of an audit result's findings sent to Gemini, and the
+// counts of the rest
+type promptSelection struct {
+	Findings   int // findings of the result
+	Advisories []promptAdvisory
+	Omitted    int            // advisories not listed
+	OmittedBy  map[string]int // advisories not listed, by severity
+}
+
+// OmittedCounts describes the advisories not listed, e.g. "3 moderate, 12 low"
+func (s promptSelection) OmittedCounts() string {
+	var counts []string
+	for _, severity := range []string{models.SeverityCritical, models.SeverityHigh, models.SeverityModerate, models.SeverityLow, models.SeverityInfo} {
+		if n := s.OmittedBy[severity]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, severity))
+		}
+	}
+	return strings.Join(counts, ", ")
+}
+
+// selectAdvisories deduplicates the vulnerabilities of a result by advisory and keeps
+// the limit most important: the most severe first, then those in the most packages,
+// then the longest open. The rest is only counted, so that apps with hundreds of
+// findings fit in the model's context.
+func selectAdvisories(vulns []models.Vulnerability, limit int) promptSelection {
+	var advisories []*promptAdvisory
+	byID := make(map[string]*promptAdvisory)
+	for _, v := range vulns {
+		id := v.CVEID
+		if id == "" {
+			id = v.Title
+		}
+		if id == "" {
+			id = v.PackageName
+		}
+
+		advisory, ok := byID[id]
+		if !ok {
+			advisory = &promptAdvisory{
+				ID:                 v.CVEID,
+				Title:              v.Title,
+				Severity:           v.Severity,
+				VulnerableVersions: v.VulnerableVersions,
+				PatchedVersions:    v.PatchedVersions,
+			}
+			byID[id] = advisory
+			advisories = append(advisories, advisory)
+		}
+		advisory.Findings++
+		if models.SeverityOrder[v.Severity] > models.SeverityOrder[advisory.Severity] {
+			advisory.Severity = v.Severity
+		}
+		if !slices.Contains(advisory.Packages, v.PackageName) {
+			advisory.Packages = append(advisory.Packages, v.PackageName)
+		}
+		if advisory.PatchedVersions == "" {
+			advisory.PatchedVersions = v.PatchedVersions
+		}
+		if v.FirstSeenAt != nil && (advisory.firstSeen.IsZero() || v.FirstSeenAt.Before(advisory.firstSeen)) {
+			advisory.firstSeen = *v.FirstSeenAt
+		}
+	}
+
+	slices.SortStableFunc(advisories, func(a, b *promptAdvisory) int {
+		if c := cmp.Compare(models.SeverityOrder[b.Severity], models.SeverityOrder[a.Severity]); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(len(b.Packages), len(a.Packages)); c != 0 {
+			return c
+		}
+		// Unknown first sightings sort last
+		switch {
+		case a.firstSeen.IsZero() || b.firstSeen.IsZero():
+			return cmp.Compare(boolInt(a.firstSeen.IsZero()), boolInt(b.firstSeen.IsZero()))
+		default:
+			return a.firstSeen.Compare(b.firstSeen)
+		}
+	})
+
+	selection := promptSelection{Findings: len(vulns), OmittedBy: make(map[string]int)}
+	for i, advisory := range advisories {
+		if limit > 0 && i >= limit {
+			selection.Omitted++
+			selection.OmittedBy[advisory.Severity]++
+			continue
+		}
+		selection.Advisories = append(selection.Advisories, *advisory)
+	}
+	return selection
+}
+
+// boolInt returns 1 for true and 0 for false
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// eventPollInterval is how often the stream checks the database for new events
+	eventPollInterval = time.Second
+
+	// eventHeartbeatInterval keeps idle connections open through proxies
+	eventHeartbeatInterval = 15 * time.Second
+
+	// eventBatchSize limits the events read per poll
+	eventBatchSize = 500
+)
+
+// handleEvents streams run progress events as Server-Sent Events.
+// Runs record their events in the database, so runs started by cron or by
+// another process are streamed too. Clients resume with the Last-Event-ID
+// header (sent automatically by EventSource) or the after query parameter;
+// without either, only new events are streamed. Filters: run_id, app.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "streaming not supported")
+		return
+	}
+
+	lastID, ok, err := parseLastEventID(r)
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+	if !ok {
+		// No resume point: stream only events recorded from now on
+		var latest models.RunEvent
+		if err := s.db.WithContext(r.Context()).Order("id DESC").Limit(1).Find(&latest).Error; err != nil {
+			s.internalError(w, err)
+			return
+		}
+		lastID = latest.ID
+	}
+
+	runID := r.URL.Query().Get("run_id")
+	appName := r.URL.Query().Get("app")
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Tell the browser how long to wait before reconnecting
+	fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	flusher.Flush()
+
+	poll := time.NewTicker(eventPollInterval)
+	defer poll.Stop()
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		query := s.db.WithContext(r.Context()).Where("id > ?", lastID)
+		if runID != "" {
+			query = query.Where("run_id = ?", runID)
+		}
+		if appName != "" {
+			query = query.Where("app_name = ?", appName)
+		}
+
+		var events []models.RunEvent
+		if err := query.Order("id ASC").Limit(eventBatchSize).Find(&events).Error; err != nil {
+			if r.Context().Err() == nil {
+				s.internalStreamError(w, flusher, err)
+			}
+			return
+		}
+
+		for _, event := range events {
+			data, _ := json.Marshal(event)
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+			lastID = event.ID
+		}
+		if len(events) > 0 {
+			flusher.Flush()
+		}
+
+		// Drain a backlog without waiting
+		if len(events) == eventBatchSize {
+			continue
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-poll.C:
+		}
+	}
+}
+
+// parseLastEventID returns the event ID to resume after, from the
+// Last-Event-ID header or the after query parameter
+func parseLastEventID(r *http.Request) (id uint, ok bool, err error) {
+	for _, v := range []string{r.Header.Get("Last-Event-ID"), r.URL.Query().Get("after")} {
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return 0, false, &paramError{"after", "must be a non-negative integer event ID"}
+		}
+		return uint(n), true, nil
+	}
+	return 0, false, nil
+}
+
+// internalStreamError reports an error on an already-open event stream
+func (s *Server) internalStreamError(w http.ResponseWriter, flusher http.Flusher, err error) {
+	zap.S().Errorf("API error: %v", err)
+	data, _ := json.Marshal(ErrorEnvelope{Error: ErrorBody{Code: ErrCodeInternal, Message: "internal server error"}})
+	fmt.Fprintf(w, "event: error\ndata: %s\n\n", data)
+	flusher.Flush()
+}
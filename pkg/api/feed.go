@@ -0,0 +1,56 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io/fs"
+	"net/http"
+	"os"
+)
+
+// SetFeed enables GET /api/v1/feed, serving the Atom feed written to path. Feed readers
+// rarely send headers, so with a non-empty token the feed is also served to requests
+// with ?token=<token>, without the bearer token.
+func (s *Server) SetFeed(path, token string) {
+	s.feedPath = path
+	s.feedToken = token
+}
+
+// handleFeed serves the Atom feed of new findings
+func (s *Server) handleFeed(w http.ResponseWriter, r *http.Request) {
+	if s.feedPath == "" {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "endpoint not found")
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if s.feedToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(s.feedToken)) != 1 {
+		s.requireAuth(s.serveFeed)(w, r)
+		return
+	}
+	s.serveFeed(w, r)
+}
+
+// serveFeed writes the feed file, supporting conditional requests so readers polling it
+// only download it when it changed
+func (s *Server) serveFeed(w http.ResponseWriter, r *http.Request) {
+	f, err := os.Open(s.feedPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "feed not generated yet")
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to read feed")
+		return
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, ErrCodeInternal, "failed to read feed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	http.ServeContent(w, r, "feed.atom", info.ModTime(), f)
+}
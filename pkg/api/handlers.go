@@ -0,0 +1,509 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// VulnerabilityItem is a vulnerability together with the app and auditor it was found in
+type VulnerabilityItem struct {
+	models.Vulnerability
+	AppName     string `json:"app_name"`
+	AuditorType string `json:"auditor_type"`
+}
+
+// handleListApps lists apps.
+// Filters: enabled, type, q (substring of the name).
+func (s *Server) handleListApps(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	enabled, err := parseBool(r, "enabled")
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	query := s.db.WithContext(r.Context()).Model(&models.App{})
+	if enabled != nil {
+		query = query.Where("enabled = ?", *enabled)
+	}
+	if appType := r.URL.Query().Get("type"); appType != "" {
+		query = query.Where("type = ?", appType)
+	}
+	if q := r.URL.Query().Get("q"); q != "" {
+		query = query.Where("name LIKE ?", "%"+q+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	apps := make([]models.App, 0)
+	if err := query.Order("name ASC").Offset((page - 1) * perPage).Limit(perPage).Find(&apps).Error; err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	writeList(w, apps, page, perPage, total)
+}
+
+// handleGetApp returns a single app by name
+func (s *Server) handleGetApp(w http.ResponseWriter, r *http.Request) {
+	var app models.App
+	err := s.db.WithContext(r.Context()).Where("name = ?", r.PathValue("name")).First(&app).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("app %q not found", r.PathValue("name")))
+		return
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, DataEnvelope{Data: app})
+}
+
+// handlePauseApp pauses an app until the time given by the "until" query parameter
+// (a duration like 72h or 3d, a date, or RFC 3339)
+func (s *Server) handlePauseApp(w http.ResponseWriter, r *http.Request) {
+	pausedUntil, err := helpers.ParseUntil(r.URL.Query().Get("until"), time.Now())
+	if err != nil {
+		writeParamError(w, &paramError{"until", err.Error()})
+		return
+	}
+
+	s.updateApp(w, r, "paused_until", pausedUntil.UTC(),
+		models.ActivityAppPaused, "until="+pausedUntil.UTC().Format(time.RFC3339))
+}
+
+// handleResumeApp clears an app's pause
+func (s *Server) handleResumeApp(w http.ResponseWriter, r *http.Request) {
+	s.updateApp(w, r, "paused_until", nil, models.ActivityAppResumed, "")
+}
+
+// updateApp sets one column of the app named in the path, records the change
+// in the activity log and writes the updated app
+func (s *Server) updateApp(w http.ResponseWriter, r *http.Request, column string, value any, action, details string) {
+	name := r.PathValue("name")
+	db := s.db.WithContext(r.Context())
+
+	result := db.Model(&models.App{}).Where("name = ?", name).Update(column, value)
+	if result.Error != nil {
+		s.internalError(w, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("app %q not found", name))
+		return
+	}
+
+	var app models.App
+	if err := db.Where("name = ?", name).First(&app).Error; err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	operator := requestOperator(r)
+	entry := &models.ActivityLog{
+		Operator: operator,
+		Source:   models.ActivitySourceAPI,
+		Action:   action,
+		AppName:  name,
+		Details:  details,
+	}
+	if err := db.Create(entry).Error; err != nil {
+		zap.S().Warnf("Failed to record activity action=%s app=%s: %v", action, name, err)
+	}
+
+	zap.S().Infof("API updated app=%s %s=%v operator=%s", name, column, value, operator)
+	writeJSON(w, http.StatusOK, DataEnvelope{Data: app})
+}
+
+// requestOperator returns the operator named by the X-Audit-Operator header.
+// The API token is shared, so without the header changes are attributed to "api".
+func requestOperator(r *http.Request) string {
+	if operator := strings.TrimSpace(r.Header.Get("X-Audit-Operator")); operator != "" {
+		return operator
+	}
+	return "api"
+}
+
+// handleListActivity lists the activity log, newest first.
+// Filters: app (comma-separated), operator, action, source, since, until.
+func (s *Server) handleListActivity(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	since, err := parseTime(r, "since", false)
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+	until, err := parseTime(r, "until", true)
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	query := s.db.WithContext(r.Context()).Model(&models.ActivityLog{})
+	if apps := parseList(r, "app"); len(apps) > 0 {
+		query = query.Where("app_name IN ?", apps)
+	}
+	for _, column := range []string{"operator", "action", "source"} {
+		if v := r.URL.Query().Get(column); v != "" {
+			query = query.Where(column+" = ?", v)
+		}
+	}
+	if since != nil {
+		query = query.Where("created_at >= ?", *since)
+	}
+	if until != nil {
+		query = query.Where("created_at <= ?", *until)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	entries := make([]models.ActivityLog, 0)
+	if err := query.Order("created_at DESC").Offset((page - 1) * perPage).Limit(perPage).Find(&entries).Error; err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	writeList(w, entries, page, perPage, total)
+}
+
+// handleListRuns lists audit runs (one per app and auditor), newest first.
+// Filters: app (comma-separated), auditor, since, until, has_vulnerabilities, run_id.
+func (s *Server) handleListRuns(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	query, err := s.runsQuery(r)
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	runs := make([]models.AuditResult, 0)
+	err = query.
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Find(&runs).Error
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	writeList(w, runs, page, perPage, total)
+}
+
+// runsQuery builds the filtered audit results query
+func (s *Server) runsQuery(r *http.Request) (*gorm.DB, error) {
+	query := s.db.WithContext(r.Context()).Model(&models.AuditResult{})
+
+	if apps := parseList(r, "app"); len(apps) > 0 {
+		query = query.Where("app_name IN ?", apps)
+	}
+	if auditorType := r.URL.Query().Get("auditor"); auditorType != "" {
+		query = query.Where("auditor_type = ?", auditorType)
+	}
+	if runID := r.URL.Query().Get("run_id"); runID != "" {
+		query = query.Where("run_id = ?", runID)
+	}
+
+	since, err := parseTime(r, "since", false)
+	if err != nil {
+		return nil, err
+	}
+	if since != nil {
+		query = query.Where("created_at >= ?", *since)
+	}
+
+	until, err := parseTime(r, "until", true)
+	if err != nil {
+		return nil, err
+	}
+	if until != nil {
+		query = query.Where("created_at <= ?", *until)
+	}
+
+	hasVulns, err := parseBool(r, "has_vulnerabilities")
+	if err != nil {
+		return nil, err
+	}
+	if hasVulns != nil {
+		if *hasVulns {
+			query = query.Where("total_vulnerabilities > 0")
+		} else {
+			query = query.Where("total_vulnerabilities = 0")
+		}
+	}
+
+	return query, nil
+}
+
+// handleGetRun returns a single audit run with its vulnerabilities.
+// Pass include_raw=true to include the raw auditor output.
+func (s *Server) handleGetRun(w http.ResponseWriter, r *http.Request) {
+	includeRaw, err := parseBool(r, "include_raw")
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	query := s.db.WithContext(r.Context()).Preload("Vulnerabilities", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	})
+
+	var run models.AuditResult
+	err = query.Where("id = ?", r.PathValue("id")).First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("run %q not found", r.PathValue("id")))
+		return
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	if includeRaw != nil && *includeRaw {
+		if run.RawOutput, err = s.rawOutput(r, run.RawOutputHash); err != nil {
+			s.internalError(w, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, DataEnvelope{Data: run})
+}
+
+// handleGetRunLog returns the log file of the run an audit belongs to (RUN_LOG_ENABLED) as plain text
+func (s *Server) handleGetRunLog(w http.ResponseWriter, r *http.Request) {
+	var run models.AuditResult
+	err := s.db.WithContext(r.Context()).Select("id", "log_file").Where("id = ?", r.PathValue("id")).First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("run %q not found", r.PathValue("id")))
+		return
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	if run.LogFile == "" {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("run %q has no log file (RUN_LOG_ENABLED was off)", run.ID))
+		return
+	}
+
+	file, err := os.Open(run.LogFile)
+	if errors.Is(err, os.ErrNotExist) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("log file of run %q was removed (LOG_MAX_AGE)", run.ID))
+		return
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	http.ServeContent(w, r, filepath.Base(run.LogFile), info.ModTime(), file)
+}
+
+// handleGetRunRaw returns the whole raw auditor output of an audit as plain text: from
+// its file when it was cut to RAW_OUTPUT_MAX_SIZE, from the database otherwise
+func (s *Server) handleGetRunRaw(w http.ResponseWriter, r *http.Request) {
+	var run models.AuditResult
+	err := s.db.WithContext(r.Context()).Select("id", "raw_output_hash", "raw_output_file", "created_at").
+		Where("id = ?", r.PathValue("id")).First(&run).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("run %q not found", r.PathValue("id")))
+		return
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	if run.RawOutputFile == "" {
+		raw, err := s.rawOutput(r, run.RawOutputHash)
+		if err != nil {
+			s.internalError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		http.ServeContent(w, r, run.ID+".txt", run.CreatedAt, strings.NewReader(raw))
+		return
+	}
+
+	file, err := os.Open(run.RawOutputFile)
+	if errors.Is(err, os.ErrNotExist) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("raw output file of run %q was removed (LOG_MAX_AGE)", run.ID))
+		return
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	http.ServeContent(w, r, filepath.Base(run.RawOutputFile), info.ModTime(), file)
+}
+
+// rawOutput returns the raw output stored under hash, empty when there is none
+func (s *Server) rawOutput(r *http.Request, hash string) (string, error) {
+	if hash == "" {
+		return "", nil
+	}
+	var raw models.RawOutput
+	err := s.db.WithContext(r.Context()).Where("hash = ?", hash).Limit(1).Find(&raw).Error
+	return raw.Content, err
+}
+
+// handleListVulnerabilities lists vulnerabilities across runs, newest first.
+// Filters: severity (comma-separated), min_severity, app (comma-separated),
+// auditor, package, cve, since, until.
+func (s *Server) handleListVulnerabilities(w http.ResponseWriter, r *http.Request) {
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	query, err := s.vulnerabilitiesQuery(r)
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	items := make([]VulnerabilityItem, 0)
+	err = query.Select("vulnerabilities.*, audit_results.app_name, audit_results.auditor_type").
+		Order("vulnerabilities.created_at DESC, vulnerabilities.id DESC").
+		Offset((page - 1) * perPage).
+		Limit(perPage).
+		Scan(&items).Error
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	writeList(w, items, page, perPage, total)
+}
+
+// vulnerabilitiesQuery builds the filtered vulnerabilities query (joined with audit results)
+func (s *Server) vulnerabilitiesQuery(r *http.Request) (*gorm.DB, error) {
+	query := s.db.WithContext(r.Context()).
+		Table("vulnerabilities").
+		Joins("JOIN audit_results ON audit_results.id = vulnerabilities.audit_result_id")
+
+	if severities := parseList(r, "severity"); len(severities) > 0 {
+		for i, sev := range severities {
+			sev = strings.ToLower(sev)
+			if _, ok := models.SeverityOrder[sev]; !ok {
+				return nil, &paramError{"severity", fmt.Sprintf("unknown severity %q", sev)}
+			}
+			severities[i] = sev
+		}
+		query = query.Where("vulnerabilities.severity IN ?", severities)
+	}
+
+	if minSeverity := strings.ToLower(r.URL.Query().Get("min_severity")); minSeverity != "" {
+		if _, ok := models.SeverityOrder[minSeverity]; !ok {
+			return nil, &paramError{"min_severity", fmt.Sprintf("unknown severity %q", minSeverity)}
+		}
+		var allowed []string
+		for sev := range models.SeverityOrder {
+			if models.MeetsSeverityThreshold(sev, minSeverity) {
+				allowed = append(allowed, sev)
+			}
+		}
+		query = query.Where("vulnerabilities.severity IN ?", allowed)
+	}
+
+	if apps := parseList(r, "app"); len(apps) > 0 {
+		query = query.Where("audit_results.app_name IN ?", apps)
+	}
+	if auditorType := r.URL.Query().Get("auditor"); auditorType != "" {
+		query = query.Where("audit_results.auditor_type = ?", auditorType)
+	}
+	if pkg := r.URL.Query().Get("package"); pkg != "" {
+		query = query.Where("vulnerabilities.package_name = ?", pkg)
+	}
+	if cve := r.URL.Query().Get("cve"); cve != "" {
+		query = query.Where("vulnerabilities.cve_id = ?", cve)
+	}
+
+	since, err := parseTime(r, "since", false)
+	if err != nil {
+		return nil, err
+	}
+	if since != nil {
+		query = query.Where("vulnerabilities.created_at >= ?", *since)
+	}
+
+	until, err := parseTime(r, "until", true)
+	if err != nil {
+		return nil, err
+	}
+	if until != nil {
+		query = query.Where("vulnerabilities.created_at <= ?", *until)
+	}
+
+	return query, nil
+}
+
+// internalError logs err and writes a generic internal_error envelope
+func (s *Server) internalError(w http.ResponseWriter, err error) {
+	zap.S().Errorf("API error: %v", err)
+	writeError(w, http.StatusInternalServerError, ErrCodeInternal, "internal server error")
+}
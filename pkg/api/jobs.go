@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/shadowbane/audit-checks/pkg/jobs"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SetJobQueue enables POST /api/v1/apps/{name}/audit and the jobs endpoints, which
+// queue audits for the workers of queue and show its jobs
+func (s *Server) SetJobQueue(queue jobs.Queue) {
+	s.jobs = queue
+}
+
+// requireJobQueue writes an unavailable error when the job queue is off
+func (s *Server) requireJobQueue(w http.ResponseWriter) bool {
+	if s.jobs == nil {
+		writeError(w, http.StatusServiceUnavailable, ErrCodeUnavailable, "the job queue is off (JOB_QUEUE)")
+		return false
+	}
+	return true
+}
+
+// handleAuditApp queues an audit of the app named in the path and returns its job
+func (s *Server) handleAuditApp(w http.ResponseWriter, r *http.Request) {
+	if !s.requireJobQueue(w) {
+		return
+	}
+
+	name := r.PathValue("name")
+	db := s.db.WithContext(r.Context())
+
+	var app models.App
+	err := db.Where("name = ?", name).First(&app).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("app %q not found", name))
+		return
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	operator := requestOperator(r)
+	job := &models.Job{
+		Type:     models.JobTypeAudit,
+		AppName:  app.Name,
+		Source:   models.JobSourceAPI,
+		Operator: operator,
+	}
+	if err := s.jobs.Enqueue(r.Context(), job); err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	entry := &models.ActivityLog{
+		Operator: operator,
+		Source:   models.ActivitySourceAPI,
+		Action:   models.ActivityRunQueued,
+		AppName:  app.Name,
+		Details:  "job_id=" + job.ID,
+	}
+	if err := db.Create(entry).Error; err != nil {
+		zap.S().Warnf("Failed to record activity action=%s app=%s: %v", entry.Action, app.Name, err)
+	}
+
+	zap.S().Infof("API queued audit app=%s job_id=%s operator=%s", app.Name, job.ID, operator)
+	writeJSON(w, http.StatusAccepted, DataEnvelope{Data: job})
+}
+
+// handleListJobs lists the jobs, newest first, without their payloads.
+// Filters: status (comma-separated), type, app.
+func (s *Server) handleListJobs(w http.ResponseWriter, r *http.Request) {
+	if !s.requireJobQueue(w) {
+		return
+	}
+
+	page, perPage, err := parsePagination(r)
+	if err != nil {
+		writeParamError(w, err)
+		return
+	}
+
+	filter := jobs.Filter{
+		Statuses: parseList(r, "status"),
+		Type:     r.URL.Query().Get("type"),
+		AppName:  r.URL.Query().Get("app"),
+		Offset:   (page - 1) * perPage,
+		Limit:    perPage,
+	}
+	list, total, err := s.jobs.List(r.Context(), filter)
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	// Payloads carry whole reports; GET /jobs/{id} returns them
+	for i := range list {
+		list[i].Payload = ""
+	}
+	if list == nil {
+		list = make([]models.Job, 0)
+	}
+
+	writeList(w, list, page, perPage, total)
+}
+
+// handleGetJob returns a job with its payload
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	if !s.requireJobQueue(w) {
+		return
+	}
+
+	job, err := s.jobs.Get(r.Context(), r.PathValue("id"))
+	if errors.Is(err, jobs.ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, fmt.Sprintf("job %q not found", r.PathValue("id")))
+		return
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, DataEnvelope{Data: job})
+}
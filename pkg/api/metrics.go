@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/jobs"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// julianUnixEpoch is the Julian day of the Unix epoch, to convert SQLite's julianday()
+const julianUnixEpoch = 2440587.5
+
+// metricSeverities are the severities open vulnerabilities are counted by, every one
+// reported for every app so alerting rules don't depend on a series appearing
+var metricSeverities = []string{
+	models.SeverityCritical,
+	models.SeverityHigh,
+	models.SeverityModerate,
+	models.SeverityLow,
+	models.SeverityInfo,
+}
+
+// metricEmailStatuses are the email delivery statuses, every one reported
+var metricEmailStatuses = []string{
+	models.EmailStatusSent,
+	models.EmailStatusDelayed,
+	models.EmailStatusDelivered,
+	models.EmailStatusBounced,
+	models.EmailStatusComplaint,
+}
+
+// labelEscaper escapes label values: only backslashes, quotes and newlines are escaped
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricsWriter writes metrics in the Prometheus text exposition format
+type metricsWriter struct {
+	buf bytes.Buffer
+}
+
+// header writes the help and type of a metric
+func (m *metricsWriter) header(name, kind, help string) {
+	fmt.Fprintf(&m.buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes a value of a metric with labels, given as name and value pairs
+func (m *metricsWriter) sample(name string, value float64, labels ...string) {
+	m.buf.WriteString(name)
+	if len(labels) > 0 {
+		m.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				m.buf.WriteByte(',')
+			}
+			fmt.Fprintf(&m.buf, `%s="%s"`, labels[i], labelEscaper.Replace(labels[i+1]))
+		}
+		m.buf.WriteByte('}')
+	}
+	m.buf.WriteByte(' ')
+	m.buf.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	m.buf.WriteByte('\n')
+}
+
+// handleMetrics serves the state of the apps in the Prometheus text format: when each
+// was last audited, its open vulnerabilities, and the notifications that failed. These
+// are the metrics of the alerting rules printed by `alerts`.
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	db := s.db.WithContext(r.Context())
+	now := time.Now()
+
+	// Disabled and paused apps are not expected to be audited
+	var apps []models.App
+	if err := db.Where("enabled = ?", true).Order("name").Find(&apps).Error; err != nil {
+		s.internalError(w, err)
+		return
+	}
+	apps = slices.DeleteFunc(apps, func(app models.App) bool { return app.IsPaused(now) })
+
+	var lastAudits []struct {
+		AppName string
+		Last    float64
+	}
+	err := db.Raw("SELECT app_name, MAX(julianday(created_at)) AS last FROM audit_results GROUP BY app_name").
+		Scan(&lastAudits).Error
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	lastAudit := make(map[string]float64, len(lastAudits))
+	for _, row := range lastAudits {
+		lastAudit[row.AppName] = (row.Last - julianUnixEpoch) * 86400
+	}
+
+	var openRows []struct {
+		AppName  string
+		Severity string
+		Open     int
+	}
+	if err := db.Raw("SELECT app_name, severity, open FROM metrics_open_by_severity").Scan(&openRows).Error; err != nil {
+		s.internalError(w, err)
+		return
+	}
+	open := make(map[[2]string]int, len(openRows))
+	for _, row := range openRows {
+		open[[2]string{row.AppName, strings.ToLower(row.Severity)}] += row.Open
+	}
+
+	var emailRows []struct {
+		Status string
+		Count  int
+	}
+	err = db.Model(&models.EmailDelivery{}).Select("status, COUNT(*) AS count").Group("status").Scan(&emailRows).Error
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+	emails := make(map[string]int, len(emailRows))
+	for _, row := range emailRows {
+		emails[row.Status] = row.Count
+	}
+
+	var m metricsWriter
+
+	m.header("audit_checks_app_last_audit_timestamp_seconds", "gauge",
+		"Time of the latest audit of the enabled app, 0 if never audited.")
+	for _, app := range apps {
+		m.sample("audit_checks_app_last_audit_timestamp_seconds", lastAudit[app.Name], "app", app.Name)
+	}
+
+	m.header("audit_checks_open_vulnerabilities", "gauge",
+		"Vulnerabilities reported by the latest audits of the enabled app, by severity.")
+	for _, app := range apps {
+		for _, severity := range metricSeverities {
+			m.sample("audit_checks_open_vulnerabilities", float64(open[[2]string{app.Name, severity}]),
+				"app", app.Name, "severity", severity)
+		}
+	}
+
+	m.header("audit_checks_emails_total", "counter",
+		"Emails sent through Resend, by delivery status (updated by the Resend webhook).")
+	for _, status := range metricEmailStatuses {
+		m.sample("audit_checks_emails_total", float64(emails[status]), "status", status)
+	}
+
+	// Finished jobs are purged after jobs.Retention, which Prometheus sees as a reset
+	if s.jobs != nil {
+		failed, _, err := s.jobs.List(r.Context(), jobs.Filter{
+			Statuses: []string{models.JobFailed},
+			Type:     models.JobTypeNotify,
+		})
+		if err != nil {
+			s.internalError(w, err)
+			return
+		}
+		byApp := make(map[string]int)
+		for _, job := range failed {
+			byApp[job.AppName]++
+		}
+
+		m.header("audit_checks_notification_retries_failed_total", "counter",
+			"Notification retries of the job queue that failed for good, by app.")
+		for _, app := range apps {
+			m.sample("audit_checks_notification_retries_failed_total", float64(byApp[app.Name]), "app", app.Name)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(m.buf.Bytes())
+}
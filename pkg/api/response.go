@@ -0,0 +1,167 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPerPage = 50
+	maxPerPage     = 200
+)
+
+// Error codes returned in the error envelope
+const (
+	ErrCodeInvalidParameter = "invalid_parameter"
+	ErrCodeNotFound         = "not_found"
+	ErrCodeUnauthorized     = "unauthorized"
+	ErrCodeInternal         = "internal_error"
+	ErrCodeUnavailable      = "unavailable"
+)
+
+// ErrorBody is the body of the error envelope
+type ErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ErrorEnvelope wraps every error response: {"error": {"code": "...", "message": "..."}}
+type ErrorEnvelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+// Pagination describes the page returned by a list endpoint
+type Pagination struct {
+	Page       int   `json:"page"`
+	PerPage    int   `json:"per_page"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"total_pages"`
+}
+
+// ListEnvelope wraps every list response: {"data": [...], "pagination": {...}}
+type ListEnvelope struct {
+	Data       any        `json:"data"`
+	Pagination Pagination `json:"pagination"`
+}
+
+// DataEnvelope wraps every single-object response: {"data": {...}}
+type DataEnvelope struct {
+	Data any `json:"data"`
+}
+
+// paramError is returned by query parsers for invalid parameters
+type paramError struct {
+	param string
+	msg   string
+}
+
+func (e *paramError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.param, e.msg)
+}
+
+// writeJSON writes v as JSON with the given status code
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		zap.S().Debugf("Failed to write API response: %v", err)
+	}
+}
+
+// writeError writes an error envelope
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, ErrorEnvelope{Error: ErrorBody{Code: code, Message: message}})
+}
+
+// writeParamError writes an invalid_parameter error for a query parsing failure
+func writeParamError(w http.ResponseWriter, err error) {
+	writeError(w, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
+}
+
+// writeList writes a list envelope for the given page
+func writeList(w http.ResponseWriter, data any, page, perPage int, total int64) {
+	totalPages := int((total + int64(perPage) - 1) / int64(perPage))
+	writeJSON(w, http.StatusOK, ListEnvelope{
+		Data: data,
+		Pagination: Pagination{
+			Page:       page,
+			PerPage:    perPage,
+			Total:      total,
+			TotalPages: totalPages,
+		},
+	})
+}
+
+// parsePagination reads page (1-based) and per_page from the query
+func parsePagination(r *http.Request) (page, perPage int, err error) {
+	page, perPage = 1, defaultPerPage
+
+	if v := r.URL.Query().Get("page"); v != "" {
+		page, err = strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return 0, 0, &paramError{"page", "must be a positive integer"}
+		}
+	}
+
+	if v := r.URL.Query().Get("per_page"); v != "" {
+		perPage, err = strconv.Atoi(v)
+		if err != nil || perPage < 1 || perPage > maxPerPage {
+			return 0, 0, &paramError{"per_page", fmt.Sprintf("must be between 1 and %d", maxPerPage)}
+		}
+	}
+
+	return page, perPage, nil
+}
+
+// parseBool reads an optional boolean query parameter
+func parseBool(r *http.Request, name string) (*bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, &paramError{name, "must be true or false"}
+	}
+	return &b, nil
+}
+
+// parseTime reads an optional time query parameter (RFC 3339 or YYYY-MM-DD).
+// A date-only "until" is inclusive, so it is moved to the end of that day.
+func parseTime(r *http.Request, name string, endOfDay bool) (*time.Time, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.ParseInLocation(time.DateOnly, v, time.UTC)
+	if err != nil {
+		return nil, &paramError{name, "must be RFC 3339 (2006-01-02T15:04:05Z) or a date (2006-01-02)"}
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
+
+// parseList reads an optional comma-separated query parameter
+func parseList(r *http.Request, name string) []string {
+	var values []string
+	for _, v := range strings.Split(r.URL.Query().Get(name), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	_ "embed"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/buildinfo"
+	"github.com/shadowbane/audit-checks/pkg/jobs"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// Server serves the REST API over the audit database
+type Server struct {
+	db    *gorm.DB
+	token string
+	build buildinfo.Info
+	mux   *http.ServeMux
+
+	// resendWebhookSecret is the signing secret of the Resend webhook; its endpoint is
+	// disabled when empty
+	resendWebhookSecret string
+
+	// jobs is the queue audits are triggered through; nil when JOB_QUEUE is off
+	jobs jobs.Queue
+
+	// feedPath is the Atom feed file served at /api/v1/feed, disabled when empty;
+	// feedToken is accepted as ?token= instead of the bearer token
+	feedPath  string
+	feedToken string
+}
+
+// NewServer creates a new API server reporting build at /api/v1/version. If token is
+// non-empty, every request except the OpenAPI spec, the health check and the signed
+// webhooks must send "Authorization: Bearer <token>".
+func NewServer(db *gorm.DB, token string, build buildinfo.Info) *Server {
+	s := &Server{
+		db:    db,
+		token: token,
+		build: build,
+		mux:   http.NewServeMux(),
+	}
+
+	s.routes()
+
+	return s
+}
+
+// routes registers the API endpoints
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /api/v1/openapi.yaml", s.handleOpenAPI)
+	s.mux.HandleFunc("GET /api/v1/health", s.handleHealth)
+	s.mux.HandleFunc("GET /api/v1/version", s.requireAuth(s.handleVersion))
+
+	s.mux.HandleFunc("GET /api/v1/apps", s.requireAuth(s.handleListApps))
+	s.mux.HandleFunc("GET /api/v1/apps/{name}", s.requireAuth(s.handleGetApp))
+	s.mux.HandleFunc("POST /api/v1/apps/{name}/pause", s.requireAuth(s.handlePauseApp))
+	s.mux.HandleFunc("POST /api/v1/apps/{name}/resume", s.requireAuth(s.handleResumeApp))
+	s.mux.HandleFunc("POST /api/v1/apps/{name}/audit", s.requireAuth(s.handleAuditApp))
+	s.mux.HandleFunc("GET /api/v1/runs", s.requireAuth(s.handleListRuns))
+	s.mux.HandleFunc("GET /api/v1/runs/{id}", s.requireAuth(s.handleGetRun))
+	s.mux.HandleFunc("GET /api/v1/runs/{id}/log", s.requireAuth(s.handleGetRunLog))
+	s.mux.HandleFunc("GET /api/v1/runs/{id}/raw", s.requireAuth(s.handleGetRunRaw))
+	s.mux.HandleFunc("GET /api/v1/vulnerabilities", s.requireAuth(s.handleListVulnerabilities))
+	s.mux.HandleFunc("GET /api/v1/events", s.requireAuth(s.handleEvents))
+	s.mux.HandleFunc("GET /api/v1/activity", s.requireAuth(s.handleListActivity))
+	s.mux.HandleFunc("GET /api/v1/jobs", s.requireAuth(s.handleListJobs))
+	s.mux.HandleFunc("GET /api/v1/jobs/{id}", s.requireAuth(s.handleGetJob))
+
+	// Checks the bearer token itself, feed readers may send a token parameter instead
+	s.mux.HandleFunc("GET /api/v1/feed", s.handleFeed)
+
+	// Where Prometheus scrapes by default, outside of the versioned API
+	s.mux.HandleFunc("GET /metrics", s.requireAuth(s.handleMetrics))
+
+	// Webhooks are signed by their sender rather than sending the bearer token
+	s.mux.HandleFunc("POST /api/v1/webhooks/resend", s.handleResendWebhook)
+
+	// Anything else under /api gets the JSON error envelope instead of the default text 404
+	s.mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "endpoint not found")
+	})
+}
+
+// Handler returns the HTTP handler for the API
+func (s *Server) Handler() http.Handler {
+	return s.logRequests(s.mux)
+}
+
+// ListenAndServe serves the API until ctx is cancelled, then shuts down gracefully
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		// Cancel request contexts on shutdown so open event streams end promptly
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		zap.S().Infof("API listening on http://%s", addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		zap.S().Info("Shutting down API server...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
+
+// requireAuth checks the bearer token when one is configured
+func (s *Server) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, "missing or invalid bearer token")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// logRequests logs each request at debug level. Only the path is logged: the query
+// may hold a secret, such as the token of the Atom feed.
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		zap.S().Debugf("API %s %s duration=%s", r.Method, r.URL.Path, time.Since(start))
+	})
+}
+
+// handleOpenAPI serves the OpenAPI 3 specification
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write(openAPISpec)
+}
+
+// handleHealth reports whether the database is reachable
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	sqlDB, err := s.db.DB()
+	if err == nil {
+		err = sqlDB.PingContext(r.Context())
+	}
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, ErrCodeInternal, "database unavailable")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, DataEnvelope{Data: map[string]string{"status": "ok"}})
+}
+
+// handleVersion returns the build information of the server and its enabled features
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, DataEnvelope{Data: s.build})
+}
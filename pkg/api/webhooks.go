@@ -0,0 +1,213 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxWebhookBody is the size of the largest webhook body accepted
+	maxWebhookBody = 1 << 20
+
+	// webhookTolerance is how far a webhook's timestamp may be from now, so a captured
+	// request cannot be replayed later
+	webhookTolerance = 5 * time.Minute
+)
+
+// resendEventStatuses maps the Resend webhook event types to email delivery statuses;
+// other events (opened, clicked...) are ignored
+var resendEventStatuses = map[string]string{
+	"email.sent":             models.EmailStatusSent,
+	"email.delivery_delayed": models.EmailStatusDelayed,
+	"email.delivered":        models.EmailStatusDelivered,
+	"email.bounced":          models.EmailStatusBounced,
+	"email.complained":       models.EmailStatusComplaint,
+}
+
+// resendEvent is a Resend webhook event about an email
+type resendEvent struct {
+	Type string `json:"type"`
+	Data struct {
+		EmailID string   `json:"email_id"`
+		To      []string `json:"to"`
+		Bounce  struct {
+			Message string `json:"message"`
+			Type    string `json:"type"` // Permanent, Transient or Undetermined
+		} `json:"bounce"`
+	} `json:"data"`
+}
+
+// SetResendWebhookSecret enables POST /api/v1/webhooks/resend, which records the delivery
+// status of the emails sent. Resend signs its webhooks with secret ("whsec_..."), so the
+// endpoint needs no bearer token.
+func (s *Server) SetResendWebhookSecret(secret string) {
+	s.resendWebhookSecret = secret
+}
+
+// handleResendWebhook updates the delivery status of an email sent through Resend
+func (s *Server) handleResendWebhook(w http.ResponseWriter, r *http.Request) {
+	if s.resendWebhookSecret == "" {
+		writeError(w, http.StatusNotFound, ErrCodeNotFound, "endpoint not found")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidParameter, "failed to read body")
+		return
+	}
+	if err := verifySvixSignature(s.resendWebhookSecret, r.Header, body, time.Now()); err != nil {
+		writeError(w, http.StatusUnauthorized, ErrCodeUnauthorized, err.Error())
+		return
+	}
+
+	var event resendEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		writeError(w, http.StatusBadRequest, ErrCodeInvalidParameter, "invalid JSON body")
+		return
+	}
+
+	status, ok := resendEventStatuses[event.Type]
+	if !ok || event.Data.EmailID == "" {
+		writeJSON(w, http.StatusOK, DataEnvelope{Data: map[string]string{"status": "ignored"}})
+		return
+	}
+
+	db := s.db.WithContext(r.Context())
+
+	// Emails not recorded were sent before the webhook was configured, or by another instance
+	var delivery models.EmailDelivery
+	err = db.Where("id = ?", event.Data.EmailID).First(&delivery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		writeJSON(w, http.StatusOK, DataEnvelope{Data: map[string]string{"status": "ignored"}})
+		return
+	}
+	if err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	// Webhooks are not delivered in order: a late "delivered" must not hide a bounce
+	if !delivery.Supersedes(status) {
+		writeJSON(w, http.StatusOK, DataEnvelope{Data: delivery})
+		return
+	}
+
+	delivery.Status = status
+	if status == models.EmailStatusBounced || status == models.EmailStatusComplaint {
+		delivery.Bounced = bouncedRecipients(delivery.Recipients, event.Data.Bounce.Message)
+		delivery.Reason = event.Data.Bounce.Message
+		zap.S().Warnf("Email %s app=%s id=%s recipients=%s reason=%q",
+			status, delivery.AppName, delivery.ID, strings.Join(delivery.Bounced, ","), delivery.Reason)
+	}
+	if err := db.Save(&delivery).Error; err != nil {
+		s.internalError(w, err)
+		return
+	}
+
+	// Addresses that cannot receive emails, or don't want them, are not sent to again;
+	// a transient bounce (full mailbox...) may deliver next time
+	if status == models.EmailStatusComplaint || (status == models.EmailStatusBounced && !strings.EqualFold(event.Data.Bounce.Type, "Transient")) {
+		if err := suppressEmails(db, delivery); err != nil {
+			s.internalError(w, err)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, DataEnvelope{Data: delivery})
+}
+
+// suppressEmails adds the recipients an email bounced for, or who complained, to the
+// suppression list
+func suppressEmails(db *gorm.DB, delivery models.EmailDelivery) error {
+	source := models.SuppressionBounced
+	if delivery.Status == models.EmailStatusComplaint {
+		source = models.SuppressionComplaint
+	}
+
+	for _, recipient := range delivery.Bounced {
+		suppression := models.EmailSuppression{
+			Address:   strings.ToLower(recipient),
+			Source:    source,
+			Reason:    delivery.Reason,
+			AppName:   delivery.AppName,
+			CreatedAt: time.Now(),
+		}
+		if err := db.Save(&suppression).Error; err != nil {
+			return err
+		}
+		zap.S().Warnf("Email suppressed address=%s source=%s app=%s", suppression.Address, source, delivery.AppName)
+	}
+	return nil
+}
+
+// bouncedRecipients returns the recipients named in a bounce message, or every recipient
+// when it names none: Resend reports one bounce for an email sent to several addresses
+func bouncedRecipients(recipients []string, message string) []string {
+	message = strings.ToLower(message)
+
+	var named []string
+	for _, recipient := range recipients {
+		if strings.Contains(message, strings.ToLower(recipient)) {
+			named = append(named, recipient)
+		}
+	}
+	if len(named) == 0 {
+		return recipients
+	}
+	return named
+}
+
+// verifySvixSignature checks the signature Resend sends with its webhooks: an HMAC-SHA256,
+// keyed with the base64 part of the "whsec_" secret, of "<svix-id>.<svix-timestamp>.<body>",
+// among the space-separated "v1,<base64 signature>" of the svix-signature header
+func verifySvixSignature(secret string, header http.Header, body []byte, now time.Time) error {
+	id := header.Get("svix-id")
+	timestamp := header.Get("svix-timestamp")
+	signatures := header.Get("svix-signature")
+	if id == "" || timestamp == "" || signatures == "" {
+		return errors.New("missing webhook signature headers")
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return errors.New("invalid webhook timestamp")
+	}
+	if sent := time.Unix(seconds, 0); sent.Before(now.Add(-webhookTolerance)) || sent.After(now.Add(webhookTolerance)) {
+		return errors.New("webhook timestamp is too old or too new")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, "whsec_"))
+	if err != nil {
+		return fmt.Errorf("invalid webhook secret: %w", err)
+	}
+	mac := hmac.New(sha256.New, key)
+	fmt.Fprintf(mac, "%s.%s.", id, timestamp)
+	mac.Write(body)
+	expected := mac.Sum(nil)
+
+	for _, signature := range strings.Fields(signatures) {
+		version, encoded, ok := strings.Cut(signature, ",")
+		if !ok || version != "v1" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err == nil && hmac.Equal(decoded, expected) {
+			return nil
+		}
+	}
+	return errors.New("invalid webhook signature")
+}
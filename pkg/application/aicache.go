@@ -0,0 +1,107 @@
+package application
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/buildinfo"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// analyze runs the AI analysis of a result, or reuses the cached analysis of an earlier
+// audit of the app with the same findings: daily audits of unchanged apps would spend
+// quota and latency on the same answer. Only parsed answers are cached.
+func (a *Application) analyze(ctx context.Context, appConfig models.AppConfig, result *models.AuditResult) (*models.AIAnalysis, error) {
+	if a.Config.AICacheTTL <= 0 || appConfig.AIDisabled || !a.AnalyzerManager.Enabled() {
+		return a.AnalyzerManager.Analyze(ctx, appConfig, result)
+	}
+	log := helpers.Logger(ctx)
+
+	key := a.aiCacheKey(appConfig, result)
+	if !a.Config.NoAICache {
+		if analysis := a.cachedAnalysis(ctx, key); analysis != nil {
+			log.Debugf("Reusing cached AI analysis app=%s key=%s", appConfig.Name, key)
+			return analysis, nil
+		}
+	}
+
+	analysis, err := a.AnalyzerManager.Analyze(ctx, appConfig, result)
+	if err != nil || analysis == nil || (analysis.Provenance != nil && analysis.Provenance.Fallback) {
+		return analysis, err
+	}
+
+	content, err := json.Marshal(analysis)
+	if err != nil {
+		return analysis, nil
+	}
+	entry := &models.CachedAIAnalysis{
+		Key:       key,
+		AppName:   appConfig.Name,
+		Analysis:  string(content),
+		CreatedAt: time.Now(),
+	}
+	if err := a.Store.SaveCachedAIAnalysis(entry); err != nil {
+		log.Warnf("Failed to cache AI analysis: %v", err)
+	}
+	return analysis, nil
+}
+
+// cachedAnalysis returns the analysis cached under key, or nil if there is none or it
+// is older than AI_CACHE_TTL. Its provenance is marked cached, at no latency or tokens.
+func (a *Application) cachedAnalysis(ctx context.Context, key string) *models.AIAnalysis {
+	entry, err := a.Store.CachedAIAnalysis(key)
+	if err != nil {
+		helpers.Logger(ctx).Warnf("Failed to read cached AI analysis: %v", err)
+		return nil
+	}
+	if entry == nil || time.Since(entry.CreatedAt) >= a.Config.AICacheTTL {
+		return nil
+	}
+
+	var analysis models.AIAnalysis
+	if json.Unmarshal([]byte(entry.Analysis), &analysis) != nil {
+		return nil
+	}
+	if analysis.Provenance != nil {
+		analysis.Provenance.Cached = true
+		analysis.Provenance.LatencyMs = 0
+		analysis.Provenance.PromptTokens = 0
+		analysis.Provenance.OutputTokens = 0
+	}
+	return &analysis
+}
+
+// aiCacheKey hashes what an analysis depends on: the app and its model, the auditor, the
+// analyzers, the release (and so the prompt) and the findings of the result
+func (a *Application) aiCacheKey(appConfig models.AppConfig, result *models.AuditResult) string {
+	findings := make([]string, len(result.Vulnerabilities))
+	for i, v := range result.Vulnerabilities {
+		findings[i] = v.FindingKey()
+	}
+	slices.Sort(findings)
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s\x00%v\x00",
+		buildinfo.Version(), appConfig.Name, result.AuditorType, a.Config.GeminiModel, appConfig.AIModel,
+		a.AnalyzerManager.EnabledAnalyzers())
+	for _, finding := range findings {
+		fmt.Fprintf(h, "%s\x00", finding)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// purgeAICache deletes the cached AI analyses older than AI_CACHE_TTL
+func (a *Application) purgeAICache(ctx context.Context) {
+	if a.Config.AICacheTTL <= 0 {
+		return
+	}
+	if err := a.Store.PurgeCachedAIAnalyses(time.Now().Add(-a.Config.AICacheTTL)); err != nil {
+		helpers.Logger(ctx).Debugf("Failed to purge expired AI analyses: %v", err)
+	}
+}
@@ -4,53 +4,82 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/glebarez/sqlite"
 	"github.com/shadowbane/audit-checks/pkg/analyzer"
 	"github.com/shadowbane/audit-checks/pkg/auditor"
+	"github.com/shadowbane/audit-checks/pkg/buildinfo"
 	"github.com/shadowbane/audit-checks/pkg/config"
-	"github.com/shadowbane/audit-checks/pkg/config/dblogger"
 	"github.com/shadowbane/audit-checks/pkg/exithandler"
+	"github.com/shadowbane/audit-checks/pkg/exporter"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/jobs"
 	"github.com/shadowbane/audit-checks/pkg/models"
 	"github.com/shadowbane/audit-checks/pkg/notifier"
+	"github.com/shadowbane/audit-checks/pkg/policy"
 	"github.com/shadowbane/audit-checks/pkg/reporter"
+	"github.com/shadowbane/audit-checks/pkg/store"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
-	gormlogger "gorm.io/gorm/logger"
 )
 
 // Application is the main application container
 type Application struct {
 	Config          *config.Config
-	DB              *gorm.DB
+	Store           store.Store
 	AuditorRegistry *auditor.Registry
 	ReporterManager *reporter.Manager
 	NotifierManager *notifier.Manager
-	GeminiAnalyzer  *analyzer.GeminiAnalyzer
+	AnalyzerManager *analyzer.Manager
+	DefectDojo      *exporter.DefectDojoExporter      // nil unless DefectDojo is configured
+	DependencyTrack *exporter.DependencyTrackExporter // nil unless Dependency-Track is configured
+	Confluence      *exporter.ConfluenceExporter      // nil unless Confluence is configured
+	Policies        *policy.Set                       // nil unless POLICY_FILE is set
+	Jobs            jobs.Queue                        // set by `serve` to retry failed notifications as jobs
 	ExitHandler     *exithandler.ExitHandler
 
 	// State
+	runID              string // Groups the progress events of one run
+	runLogFile         string // The run's log file (RUN_LOG_ENABLED)
+	appsTotal          int
+	appsCompleted      int
 	results            []*models.AuditResult
 	hasVulnerabilities bool
+	canaryFailures     []canaryFailure
+	policyViolations   []models.PolicyViolation
 	mu                 sync.Mutex
 }
 
-// New creates a new Application instance
+// New creates a new Application instance storing results in the SQLite database
 func New(cfg *config.Config) (*Application, error) {
+	st, err := store.OpenSQLite(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	app, err := NewWithStore(cfg, st)
+	if err != nil {
+		st.Close()
+		return nil, err
+	}
+	return app, nil
+}
+
+// NewWithStore creates a new Application instance storing results in st.
+// The application closes st when it is closed.
+func NewWithStore(cfg *config.Config, st store.Store) (*Application, error) {
 	app := &Application{
 		Config:      cfg,
+		Store:       st,
 		ExitHandler: exithandler.New(),
 		results:     make([]*models.AuditResult, 0),
 	}
 
-	// Initialize database
-	if err := app.initDatabase(); err != nil {
-		return nil, fmt.Errorf("failed to initialize database: %w", err)
-	}
-
-	// Load apps from database
+	// Load apps from the store
 	if err := app.loadApps(); err != nil {
 		return nil, fmt.Errorf("failed to load apps: %w", err)
 	}
@@ -66,55 +95,48 @@ func New(cfg *config.Config) (*Application, error) {
 		return nil, fmt.Errorf("failed to initialize notifiers: %w", err)
 	}
 
-	// Initialize Gemini analyzer
-	if err := app.initGemini(); err != nil {
-		zap.S().Warnf("Failed to initialize Gemini analyzer: %v", err)
-	}
-
-	return app, nil
-}
+	// Initialize AI analyzers
+	app.initAnalyzers()
 
-// initDatabase initializes the SQLite database
-func (a *Application) initDatabase() error {
-	gormConfig := &gorm.Config{
-		Logger: &dblogger.ZapLogger{
-			Config: gormlogger.Config{
-				SlowThreshold:             time.Second,
-				LogLevel:                  dblogger.LogLevelToGormLevel(a.Config.GetDBLogLevel()),
-				IgnoreRecordNotFoundError: true,
-				ParameterizedQueries:      true,
-			},
-		},
+	// Load the pass/fail policies
+	if err := app.initPolicies(); err != nil {
+		return nil, fmt.Errorf("failed to load policies: %w", err)
 	}
 
-	zap.S().Debugf("Connecting to SQLite database at %s", a.Config.DBSQLitePath)
-
-	db, err := gorm.Open(sqlite.Open(a.Config.DBSQLitePath), gormConfig)
-	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+	// Import the results into DefectDojo
+	if cfg.IsDefectDojoEnabled() {
+		app.DefectDojo = exporter.NewDefectDojoExporter(cfg.DefectDojoURL, cfg.DefectDojoAPIKey,
+			cfg.DefectDojoProductType, cfg.DefectDojoEngagement)
 	}
 
-	// Run migrations
-	if err := db.AutoMigrate(models.AllModels()...); err != nil {
-		return fmt.Errorf("failed to run migrations: %w", err)
+	// Upload the apps' SBOMs to Dependency-Track
+	if cfg.IsDependencyTrackEnabled() {
+		app.DependencyTrack = exporter.NewDependencyTrackExporter(cfg.DependencyTrackURL, cfg.DependencyTrackAPIKey,
+			cfg.DependencyTrackTags)
 	}
 
-	// SQLite works best with a single connection for write operations
-	sqlDB, err := db.DB()
-	if err == nil {
-		sqlDB.SetMaxOpenConns(1)
+	// Publish a Confluence page per app
+	if cfg.IsConfluenceEnabled() {
+		app.Confluence = exporter.NewConfluenceExporter(cfg.ConfluenceURL, cfg.ConfluenceUser, cfg.ConfluenceAPIToken,
+			cfg.ConfluenceSpace, cfg.ConfluenceParentPageID, cfg.ConfluenceTitle)
 	}
 
-	a.DB = db
-	zap.S().Infof("Database initialized at %s", a.Config.DBSQLitePath)
-
-	return nil
+	return app, nil
 }
 
-// loadApps loads apps from the database into config
+// loadApps loads apps from the store into config
 func (a *Application) loadApps() error {
-	var apps []models.App
-	if err := a.DB.Find(&apps).Error; err != nil {
+	// Re-enable apps whose pause has expired
+	resumed, err := a.Store.ResumeExpiredPauses(time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to resume paused apps: %w", err)
+	}
+	if resumed > 0 {
+		zap.S().Infof("Resumed %d app(s) whose pause expired", resumed)
+	}
+
+	apps, err := a.Store.Apps()
+	if err != nil {
 		return fmt.Errorf("failed to query apps: %w", err)
 	}
 
@@ -134,18 +156,46 @@ func (a *Application) loadApps() error {
 
 // initAuditors registers all auditors
 func (a *Application) initAuditors() {
-	a.AuditorRegistry = auditor.NewRegistry()
-	a.AuditorRegistry.Register(auditor.NewNPMAuditor())
-	a.AuditorRegistry.Register(auditor.NewComposerAuditor())
+	a.AuditorRegistry = NewAuditorRegistry(a.Config.Settings)
 
 	zap.S().Debugf("Auditors registered: %v", a.AuditorRegistry.Names())
 }
 
+// NewAuditorRegistry creates a registry with every auditor, configured from settings
+func NewAuditorRegistry(settings config.Settings) *auditor.Registry {
+	registry := auditor.NewRegistry()
+	registry.Register(auditor.NewNPMAuditor(settings.NPMAuditOptions))
+	registry.Register(auditor.NewPnpmAuditor())
+	registry.Register(auditor.NewComposerAuditor(settings.ComposerAuditOptions))
+	registry.Register(auditor.NewGoAuditor())
+	registry.Register(auditor.NewCargoAuditor())
+	registry.Register(auditor.NewJavaAuditor(settings.JavaAuditBackend))
+	registry.Register(auditor.NewDotnetAuditor())
+	registry.Register(auditor.NewHelmAuditor())
+	registry.Register(auditor.NewSystemAuditor(settings.SystemAuditBackend))
+	osv := auditor.NewOSVAuditor(settings.OSVCacheDir, settings.OSVCacheTTL)
+	registry.Register(osv)
+	registry.Register(auditor.NewPubAuditor(osv))
+	registry.Register(auditor.NewTerraformAuditor(osv))
+	registry.Register(auditor.NewPolicyAuditor(settings.PinningPolicyEnabled))
+	registry.Register(auditor.NewOutdatedAuditor(settings.OutdatedAuditEnabled, settings.OutdatedAuditSeverity))
+	registry.Register(auditor.NewSupplyChainAuditor(settings.SupplyChainAuditEnabled, settings.NPMAuditOptions))
+	registry.Register(auditor.NewSecretsAuditor(settings.SecretsAuditEnabled))
+	registry.Register(auditor.NewPHPAuditor(settings.PHPEOLAuditEnabled))
+	registry.Register(auditor.NewNodeAuditor(settings.NodeEOLAuditEnabled, osv))
+	return registry
+}
+
 // initReporters registers all reporters
 func (a *Application) initReporters() {
 	a.ReporterManager = reporter.NewManager(a.Config.Settings.ReportOutputDir)
+	a.ReporterManager.SetFilenameTemplate(a.Config.Settings.ReportFilename, a.Config.Settings.ReportTimestamp)
 	a.ReporterManager.Register(reporter.NewJSONReporter())
 	a.ReporterManager.Register(reporter.NewMarkdownReporter())
+	a.ReporterManager.Register(reporter.NewJUnitReporter())
+	a.ReporterManager.Register(reporter.NewXLSXReporter())
+	a.ReporterManager.Register(reporter.NewHTMLReporter())
+	a.ReporterManager.RegisterApp(reporter.NewCycloneDXReporter(buildinfo.Version()))
 
 	zap.S().Debugf("Reporters registered: %v", a.ReporterManager.Formats())
 }
@@ -154,11 +204,40 @@ func (a *Application) initReporters() {
 func (a *Application) initNotifiers() error {
 	a.NotifierManager = notifier.NewManager(a.Config.DryRun)
 
-	// Email notifier
-	emailNotifier := notifier.NewEmailNotifier(
-		a.Config.ResendAPIKey,
-		a.Config.ResendFromEmail,
-	)
+	// Email notifier, through Resend or an SMTP server
+	var emailNotifier *notifier.EmailNotifier
+	switch a.Config.EmailProvider {
+	case "", "resend":
+		emailNotifier = notifier.NewEmailNotifier(
+			a.Config.ResendAPIKey,
+			a.Config.ResendFromEmail,
+		)
+		// Record the emails sent, whose delivery status Resend's webhooks report to the API server
+		if a.Config.ResendWebhookSecret != "" {
+			emailNotifier.RecordDeliveries(func(delivery *models.EmailDelivery) {
+				if err := a.Store.SaveEmailDelivery(delivery); err != nil {
+					zap.S().Warnf("Failed to record email delivery id=%s error=%v", delivery.ID, err)
+				}
+			})
+		}
+	case "smtp":
+		switch a.Config.SMTPTLS {
+		case "", notifier.SMTPTLSStartTLS, notifier.SMTPTLSImplicit, notifier.SMTPTLSNone:
+		default:
+			return fmt.Errorf("unknown SMTP_TLS %q (expected starttls, tls or none)", a.Config.SMTPTLS)
+		}
+		emailNotifier = notifier.NewSMTPEmailNotifier(notifier.SMTPConfig{
+			Host:     a.Config.SMTPHost,
+			Port:     a.Config.SMTPPort,
+			Username: a.Config.SMTPUsername,
+			Password: a.Config.SMTPPassword,
+			TLS:      a.Config.SMTPTLS,
+		}, a.Config.SMTPFromEmail)
+	default:
+		return fmt.Errorf("unknown EMAIL_PROVIDER %q (expected resend or smtp)", a.Config.EmailProvider)
+	}
+	// Addresses that bounced, complained or were suppressed by hand are not sent to
+	emailNotifier.SkipSuppressed(a.Store.EmailSuppressions)
 	a.NotifierManager.Register(emailNotifier)
 
 	// Telegram notifier
@@ -173,68 +252,229 @@ func (a *Application) initNotifiers() error {
 		a.NotifierManager.Register(telegramNotifier)
 	}
 
+	// Discord notifier
+	a.NotifierManager.Register(notifier.NewDiscordNotifier(
+		a.Config.DiscordBotToken,
+		a.Config.DiscordChannelID,
+		a.Config.DiscordWebhookURL,
+		a.Config.DiscordEnabled,
+	))
+
+	// Mattermost notifier
+	a.NotifierManager.Register(notifier.NewMattermostNotifier(
+		a.Config.MattermostURL,
+		a.Config.MattermostBotToken,
+		a.Config.MattermostChannelID,
+		a.Config.MattermostWebhookURL,
+		a.Config.MattermostEnabled,
+	))
+
+	// Webhook notifier
+	a.NotifierManager.Register(notifier.NewWebhookNotifier(
+		a.Config.WebhookURLs,
+		a.Config.WebhookSecret,
+		a.Config.WebhookEnabled,
+	))
+
+	// PagerDuty notifier
+	a.NotifierManager.Register(notifier.NewPagerDutyNotifier(
+		a.Config.PagerDutyRoutingKey,
+		a.Config.PagerDutyEnabled,
+	))
+
+	// Opsgenie notifier
+	a.NotifierManager.Register(notifier.NewOpsgenieNotifier(
+		a.Config.OpsgenieAPIKey,
+		a.Config.OpsgenieAPIURL,
+		a.Config.OpsgeniePriorities,
+		a.Config.OpsgenieEnabled,
+	))
+
+	// ntfy notifier
+	a.NotifierManager.Register(notifier.NewNtfyNotifier(notifier.NtfyConfig{
+		ServerURL:   a.Config.NtfyServerURL,
+		Token:       a.Config.NtfyToken,
+		TopicPrefix: a.Config.NtfyTopicPrefix,
+		Priorities:  a.Config.NtfyPriorities,
+		ReportURL:   a.Config.NtfyReportURL,
+		ReportDir:   a.Config.Settings.ReportOutputDir,
+	}, a.Config.IsNtfyEnabled()))
+
+	// Gotify notifier
+	a.NotifierManager.Register(notifier.NewGotifyNotifier(
+		a.Config.GotifyURL,
+		a.Config.GotifyToken,
+		a.Config.GotifyPriorities,
+		a.Config.GotifyEnabled,
+	))
+
+	// Zulip notifier
+	a.NotifierManager.Register(notifier.NewZulipNotifier(
+		a.Config.ZulipURL,
+		a.Config.ZulipBotEmail,
+		a.Config.ZulipAPIKey,
+		a.Config.ZulipStream,
+		a.Config.ZulipEnabled,
+	))
+
+	// GitLab notifier
+	a.NotifierManager.Register(notifier.NewGitLabNotifier(
+		a.Config.GitLabURL,
+		a.Config.GitLabToken,
+		a.Config.GitLabProject,
+		a.Config.GitLabLabels,
+		a.Config.GitLabEnabled,
+	))
+
+	// Desktop notifier, for audits run on a workstation
+	desktop := notifier.NewDesktopNotifier(a.Config.DesktopEventSource, a.Config.DesktopEnabled)
+	if command := desktop.Command(); a.Config.DesktopEnabled && command == "" {
+		zap.S().Warnf("Desktop notifications are not supported on %s", runtime.GOOS)
+	} else if a.Config.DesktopEnabled && !desktop.Enabled() {
+		zap.S().Warnf("Desktop notifications are not available: %s not found in PATH", command)
+	}
+	a.NotifierManager.Register(desktop)
+
 	zap.S().Debugf("Notifiers registered: %v", a.NotifierManager.EnabledNotifiers())
 
 	return nil
 }
 
-// initGemini initializes the Gemini analyzer
-func (a *Application) initGemini() error {
+// CheckTelegramPermissions warns about each right the Telegram bot lacks in the
+// group, instead of leaving them to be discovered at the first notification. The
+// commands that notify call it once on startup; doctor reports the same check.
+func CheckTelegramPermissions(cfg *config.Config) {
+	if !cfg.IsTelegramEnabled() {
+		return
+	}
+
+	check, err := notifier.CheckTelegram(cfg.TelegramBotToken, cfg.TelegramGroupID)
+	if err != nil {
+		zap.S().Warnf("Telegram permission check failed: %v", err)
+		return
+	}
+	for _, problem := range check.Problems() {
+		zap.S().Warnf("Telegram notifications will fail: %s (%s)", problem.Message, problem.Hint)
+	}
+}
+
+// initAnalyzers registers the AI analyzers, tried in the order of AI_ANALYZERS
+func (a *Application) initAnalyzers() {
 	ctx := context.Background()
-	zap.S().Debugf("Initializing Gemini analyzer")
+	a.AnalyzerManager = analyzer.NewManager(a.Config.AIAnalyzers)
 
-	geminiAnalyzer, err := analyzer.NewGeminiAnalyzer(
+	// Gemini analyzer, with each app's own model or GEMINI_MODEL
+	gemini, err := analyzer.NewGeminiAnalyzer(
 		ctx,
 		a.Config.GeminiAPIKey,
 		a.Config.GeminiModel,
 		a.Config.IsGeminiEnabled(),
 	)
 	if err != nil {
-		return err
+		zap.S().Warnf("Failed to initialize Gemini analyzer: %v", err)
+	} else {
+		gemini.SetMaxAdvisories(a.Config.GeminiMaxAdvisories)
+		a.AnalyzerManager.Register(gemini)
 	}
-	a.GeminiAnalyzer = geminiAnalyzer
 
-	if geminiAnalyzer.Enabled() {
-		zap.S().Info("Gemini analyzer enabled")
+	for _, name := range a.Config.AIAnalyzers {
+		if _, ok := a.AnalyzerManager.Get(name); !ok {
+			zap.S().Warnf("Unknown analyzer %q in AI_ANALYZERS", name)
+		}
 	}
 
-	return nil
+	if enabled := a.AnalyzerManager.EnabledAnalyzers(); len(enabled) > 0 {
+		zap.S().Infof("AI analyzers enabled: %v", enabled)
+	}
 }
 
 // Run executes the audit process
 func (a *Application) Run(ctx context.Context) error {
-	zap.S().Info("Starting security audit")
+	// Every log line of the run carries its ID, so concurrent audits can be told apart
+	a.runID = helpers.MustNewULID()
+	a.runLogFile = ""
+	started := time.Now()
+	base := helpers.Logger(ctx)
+	log := base.With("run_id", a.runID)
+	ctx = helpers.WithLogger(ctx, log)
+
+	log.Info("Starting security audit")
 
 	// Get apps to audit
 	apps := a.getAppsToAudit()
 	if len(apps) == 0 {
-		zap.S().Warn("No apps to audit. Use 'audit-checks app add' to add apps.")
+		log.Warn("No apps to audit. Use 'audit-checks app add' to add apps.")
 		return nil
 	}
 
-	zap.S().Infof("Auditing %d apps", len(apps))
+	// A canary run audits a random sample, whatever the adaptive schedule says;
+	// an explicitly targeted app is always audited
+	canary := a.Config.CanarySize > 0 && a.Config.TargetApp == ""
+	if canary {
+		apps = sampleCanaryApps(apps, a.Config.CanarySize)
+		a.canaryFailures = nil
+		log.Infof("Canary run: auditing %d random app(s)", len(apps))
+	} else if a.Config.Settings.AdaptiveSchedule && a.Config.TargetApp == "" {
+		apps = a.filterDueApps(apps)
+		if len(apps) == 0 {
+			log.Info("No apps are due for audit (adaptive schedule)")
+			return nil
+		}
+	}
+
+	// Once there is something to audit, the run also logs to its own file
+	runLogger, closeRunLog := a.openRunLog(base.Desugar())
+	defer closeRunLog()
+	log = runLogger.Sugar().With("run_id", a.runID)
+	ctx = helpers.WithLogger(ctx, log)
+
+	log.Infof("Auditing %d apps operator=%s", len(apps), a.Config.Operator)
+	if a.runLogFile != "" {
+		log.Infof("Run log file=%s", a.runLogFile)
+	}
+	a.purgeRawOutputs(ctx)
+
+	a.appsTotal = len(apps)
+	a.purgeRunEvents()
+	a.purgeAICache(ctx)
+	a.recordRunTrigger()
+	a.emitEvent(models.RunEvent{Type: models.EventRunStarted, Message: "started by " + a.Config.Operator})
+
+	// Audit apps concurrently, started in turn by owner
+	queue := make(chan models.AppConfig, len(apps))
+	for _, app := range fairOrder(apps, a.Config.Settings.OwnerWeights) {
+		queue <- app
+	}
+	close(queue)
 
-	// Audit apps concurrently
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, a.Config.Settings.MaxConcurrent)
 	errChan := make(chan error, len(apps))
 
-	for _, app := range apps {
+	for range min(a.Config.Settings.MaxConcurrent, len(apps)) {
 		wg.Add(1)
-		go func(appConfig models.AppConfig) {
+		go func() {
 			defer wg.Done()
-
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			if err := a.auditApp(ctx, appConfig); err != nil {
-				zap.S().Errorf("Failed to audit app=%s error=%v",
-					appConfig.Name,
-					err,
-				)
-				errChan <- fmt.Errorf("audit failed for %s: %w", appConfig.Name, err)
+			for appConfig := range queue {
+				a.emitEvent(models.RunEvent{Type: models.EventAppStarted, AppName: appConfig.Name})
+
+				err := a.auditApp(ctx, appConfig)
+
+				a.mu.Lock()
+				a.appsCompleted++
+				a.mu.Unlock()
+
+				appCompleted := models.RunEvent{Type: models.EventAppCompleted, AppName: appConfig.Name}
+				if err != nil {
+					log.Errorf("Failed to audit app=%s error=%v",
+						appConfig.Name,
+						err,
+					)
+					errChan <- fmt.Errorf("audit failed for %s: %w", appConfig.Name, err)
+					appCompleted.Message = err.Error()
+				}
+				a.emitEvent(appCompleted)
 			}
-		}(app)
+		}()
 	}
 
 	wg.Wait()
@@ -246,23 +486,69 @@ func (a *Application) Run(ctx context.Context) error {
 		errs = append(errs, err)
 	}
 
-	// Generate summary report
+	var totalVulns int
+	for _, result := range a.results {
+		totalVulns += result.TotalVulnerabilities
+	}
+	runCompleted := models.RunEvent{Type: models.EventRunCompleted, Vulnerabilities: totalVulns}
+	if len(errs) > 0 {
+		runCompleted.Message = fmt.Sprintf("%d app(s) failed", len(errs))
+	}
+	a.emitEvent(runCompleted)
+
+	if canary {
+		a.reportCanary(ctx, len(apps))
+	}
+
+	// Generate summary report, with the fleet-level AI analysis when enabled
+	summary := models.NewAuditSummary(a.results)
+	summary.RunID = a.runID
+	summary.PolicyViolations = a.policyViolations
+	for _, v := range summary.PolicyViolations {
+		if v.Error != "" {
+			summary.PolicyErrors++
+		}
+	}
+	if summary.PolicyErrors > 0 {
+		log.Warnf("Policies could not be evaluated errors=%d: counted as violated", summary.PolicyErrors)
+	}
 	if len(a.results) > 0 {
-		if err := a.generateSummary(); err != nil {
-			zap.S().Errorf("Failed to generate summary: %v", err)
+		summary.AIAnalysis = a.analyzeFleet(ctx, summary)
+		if err := a.generateSummary(ctx, summary); err != nil {
+			log.Errorf("Failed to generate summary: %v", err)
 		}
 	}
 
 	// Output JSON if requested
 	if a.Config.JSONOutput {
-		a.outputJSON()
+		a.outputJSON(summary)
+	}
+
+	// GitLab Dependency Scanning report for the pipeline's Security tab
+	if a.Config.GitLabReportPath != "" {
+		a.writeGitLabReport(ctx, started)
 	}
 
+	// Weekly executive report (when due)
+	a.maybeGenerateExecutiveReport(ctx)
+
+	// Service catalog scorecards
+	a.maybeGenerateScorecards(ctx)
+
+	// Sanitized status page for wallboards
+	a.maybeGenerateStatusPage(ctx)
+
+	// Atom feed of the new findings
+	a.maybeGenerateFeed(ctx)
+
+	// History older than the apps' retention
+	a.purgeExpiredHistory(ctx)
+
 	if len(errs) > 0 {
 		return fmt.Errorf("audit completed with errors: %v", errs)
 	}
 
-	zap.S().Infof("Security audit completed apps=%d vulnerabilities_found=%t",
+	log.Infof("Security audit completed apps=%d vulnerabilities_found=%t",
 		len(a.results),
 		a.hasVulnerabilities,
 	)
@@ -278,15 +564,29 @@ func (a *Application) getAppsToAudit() []models.AppConfig {
 			zap.S().Errorf("Target app not found: %s", a.Config.TargetApp)
 			return nil
 		}
+		if app.IsPaused(time.Now()) {
+			zap.S().Infof("App %s is paused until %s; auditing because it was requested explicitly",
+				app.Name, app.PausedUntil.Format(time.RFC3339))
+		}
 		return []models.AppConfig{*app}
 	}
 
+	now := time.Now()
+	for _, app := range a.Config.Apps {
+		if app.Enabled && app.IsPaused(now) {
+			zap.S().Infof("Skipping paused app=%s until=%s", app.Name, app.PausedUntil.Format(time.RFC3339))
+		}
+	}
+
 	return a.Config.GetEnabledApps()
 }
 
 // auditApp audits a single application (may run multiple auditors)
 func (a *Application) auditApp(ctx context.Context, appConfig models.AppConfig) error {
-	zap.S().Infof("Auditing app=%s path=%s", appConfig.Name, appConfig.Path)
+	log := helpers.Logger(ctx).With("app", appConfig.Name)
+	ctx = helpers.WithLogger(ctx, log)
+
+	log.Infof("Auditing app=%s path=%s", appConfig.Name, appConfig.Path)
 
 	// Get all applicable auditors
 	auditors, err := a.AuditorRegistry.GetAuditorsForApp(appConfig)
@@ -294,42 +594,116 @@ func (a *Application) auditApp(ctx context.Context, appConfig models.AppConfig)
 		return fmt.Errorf("failed to get auditors: %w", err)
 	}
 
-	zap.S().Infof("Running %d auditor(s) for app=%s: %v", len(auditors), appConfig.Name, auditorNames(auditors))
+	log.Infof("Running %d auditor(s) for app=%s: %v", len(auditors), appConfig.Name, auditorNames(auditors))
+
+	// All auditors of the app share one snapshot of it (AUDIT_WORKSPACE)
+	workspace, cleanup, err := a.prepareWorkspace(ctx, appConfig)
+	if err != nil {
+		return fmt.Errorf("failed to prepare workspace: %w", err)
+	}
+	defer cleanup()
 
 	// Create combined report for this app
 	combinedReport := models.NewCombinedAppReport(appConfig.Name, appConfig.Path)
+	combinedReport.RunID = a.runID
+	combinedReport.IssueOrder = a.Config.Settings.IssueOrder
 
 	// Run each auditor and collect results
 	var errs []error
 	for _, aud := range auditors {
-		report, filePaths, err := a.runSingleAudit(ctx, appConfig, aud)
+		report, filePaths, err := a.runSingleAudit(ctx, appConfig, aud, workspace)
 		if err != nil {
+			failure := auditor.Classify(aud.Name(), err)
+			log.Errorf("Auditor failed app=%s auditor=%s kind=%s hint=%q",
+				appConfig.Name, aud.Name(), failure.Kind, failure.Hint)
+			a.emitEvent(models.RunEvent{
+				Type:        models.EventAuditorFailed,
+				AppName:     appConfig.Name,
+				AuditorType: aud.Name(),
+				Message:     err.Error(),
+				FailureKind: failure.Kind,
+				Hint:        failure.Hint,
+			})
+			combinedReport.AddFailure(models.AuditFailure{
+				AuditorType: aud.Name(),
+				Kind:        failure.Kind,
+				Message:     err.Error(),
+				Hint:        failure.Hint,
+			})
 			errs = append(errs, fmt.Errorf("%s: %w", aud.Name(), err))
 			continue
 		}
+		if report != nil && report.AuditResult.Questionable != "" {
+			// Reported like a failure so a likely false negative is not taken for a clean audit
+			combinedReport.AddFailure(models.AuditFailure{
+				AuditorType: aud.Name(),
+				Kind:        auditor.FailureQuestionable,
+				Message:     report.AuditResult.Questionable,
+				Hint:        auditor.FailureHint(aud.Name(), auditor.FailureQuestionable, report.AuditResult.Questionable),
+			})
+		}
 		if report != nil {
+			a.emitEvent(models.RunEvent{
+				Type:            models.EventAuditorCompleted,
+				AppName:         appConfig.Name,
+				AuditorType:     aud.Name(),
+				Vulnerabilities: report.AuditResult.TotalVulnerabilities,
+			})
 			combinedReport.AddReport(report, filePaths)
 		}
 	}
 
-	// Send ONE combined notification if vulnerabilities found and not report-only mode
-	if combinedReport.HasVulnerabilities() && !a.Config.ReportOnly {
-		notifyResult, err := a.NotifierManager.NotifyAllCombined(ctx, combinedReport, appConfig.Notifications)
+	// Record the app's packages so the advisory watcher can tell when a new advisory affects it
+	a.saveInstalledPackages(ctx, appConfig)
+
+	a.uploadDependencyTrack(ctx, appConfig)
+
+	// Policies see every finding: muted and snoozed findings still violate them
+	combinedReport.PolicyViolations = a.evaluateAppPolicies(ctx, appConfig, combinedReport)
+
+	a.generateAppReports(ctx, appConfig, combinedReport)
+	a.generateCombinedReports(ctx, combinedReport)
+	a.publishConfluence(ctx, combinedReport)
+
+	// Severities the app mutes and findings snoozed with `triage` are recorded and
+	// reported, but left out of its notifications
+	notifyReport := combinedReport.WithoutSeverities(appConfig.Notifications.MutedSeverities)
+	if triages, err := a.Store.FindingTriages(appConfig.Name); err != nil {
+		log.Warnf("Failed to load the triages of app=%s: %v", appConfig.Name, err)
+	} else {
+		notifyReport = notifyReport.WithoutSnoozed(triages, time.Now())
+	}
+
+	// Canary runs look for broken tools and registries: only auditor failures are
+	// notified, findings are left to the full runs
+	canary := a.Config.CanarySize > 0 && a.Config.TargetApp == ""
+	notify := notifyReport.HasVulnerabilities() || notifyReport.HasFailures() || notifyReport.HasPolicyViolations()
+	if canary {
+		a.recordCanaryFailures(appConfig.Name, combinedReport.Failures)
+		notify = combinedReport.HasFailures()
+	}
+	if !notify && combinedReport.HasVulnerabilities() && !canary {
+		log.Infof("Not notifying app=%s: its findings are all of muted severities %v or snoozed",
+			appConfig.Name, appConfig.Notifications.MutedSeverities)
+	}
+
+	// Send ONE combined notification if vulnerabilities found, an auditor failed or a policy is violated, and not report-only mode
+	if notify && !a.Config.ReportOnly {
+		notifyResult, err := a.NotifierManager.NotifyAllCombined(ctx, notifyReport, appConfig.Notifications)
 		if err != nil {
-			zap.S().Errorf("Failed to send notifications: %v", err)
+			log.Errorf("Failed to send notifications: %v", err)
+			a.enqueueNotifyRetry(ctx, appConfig, notifyReport, notifyResult.Failed)
 		}
+		a.saveNotificationTargets(ctx, appConfig, notifyResult)
+		a.saveNotificationStatus(ctx, appConfig, notifyResult)
+	}
 
-		// Save Telegram topic ID if it was created/updated
-		if notifyResult != nil && notifyResult.TelegramTopicID > 0 {
-			if notifyResult.TelegramTopicID != appConfig.Notifications.TelegramTopicID {
-				if err := a.DB.Model(&models.App{}).Where("name = ?", appConfig.Name).
-					Update("telegram_topic_id", notifyResult.TelegramTopicID).Error; err != nil {
-					zap.S().Errorf("Failed to save Telegram topic ID: %v", err)
-				} else {
-					zap.S().Debugf("Saved Telegram topic ID=%d for app=%s", notifyResult.TelegramTopicID, appConfig.Name)
-				}
-			}
-		}
+	// Open, replace or resolve the app's PagerDuty incident, Opsgenie alerts and GitLab
+	// issue, also when nothing was found
+	if !a.Config.ReportOnly && !canary {
+		a.syncPagerDuty(ctx, appConfig, notifyReport)
+		a.syncOpsgenie(ctx, appConfig, notifyReport)
+		a.syncGitLab(ctx, appConfig, notifyReport)
 	}
 
 	if len(errs) > 0 {
@@ -339,6 +713,93 @@ func (a *Application) auditApp(ctx context.Context, appConfig models.AppConfig)
 	return nil
 }
 
+// saveNotificationTargets persists the Telegram topic and Discord thread created or
+// replaced while notifying an app
+func (a *Application) saveNotificationTargets(ctx context.Context, appConfig models.AppConfig, notifyResult *notifier.NotificationResult) {
+	log := helpers.Logger(ctx)
+
+	if notifyResult == nil {
+		return
+	}
+
+	// Save Telegram topic ID if it was created/updated
+	if notifyResult.TelegramTopicID > 0 && notifyResult.TelegramTopicID != appConfig.Notifications.TelegramTopicID {
+		if err := a.Store.SaveTelegramTopicID(appConfig.Name, notifyResult.TelegramTopicID); err != nil {
+			log.Errorf("Failed to save Telegram topic ID: %v", err)
+		} else {
+			log.Debugf("Saved Telegram topic ID=%d for app=%s", notifyResult.TelegramTopicID, appConfig.Name)
+		}
+	}
+
+	// Save Discord thread ID if it was created/updated
+	if notifyResult.DiscordThreadID != "" && notifyResult.DiscordThreadID != appConfig.Notifications.DiscordThreadID {
+		if err := a.Store.SaveDiscordThreadID(appConfig.Name, notifyResult.DiscordThreadID); err != nil {
+			log.Errorf("Failed to save Discord thread ID: %v", err)
+		} else {
+			log.Debugf("Saved Discord thread ID=%s for app=%s", notifyResult.DiscordThreadID, appConfig.Name)
+		}
+	}
+}
+
+// saveNotificationStatus persists when an app was notified and the channels that
+// failed, shown by `app show --notifications`. Dry runs send nothing and are not recorded.
+func (a *Application) saveNotificationStatus(ctx context.Context, appConfig models.AppConfig, notifyResult *notifier.NotificationResult) {
+	if notifyResult == nil || a.Config.DryRun {
+		return
+	}
+	if err := a.Store.SaveNotificationStatus(appConfig.Name, time.Now(), notifyResult.Failed); err != nil {
+		helpers.Logger(ctx).Errorf("Failed to save notification status app=%s: %v", appConfig.Name, err)
+	}
+}
+
+// syncPagerDuty updates the app's PagerDuty incident and persists its deduplication key
+func (a *Application) syncPagerDuty(ctx context.Context, appConfig models.AppConfig, combinedReport *models.CombinedAppReport) {
+	log := helpers.Logger(ctx)
+
+	dedupKey, err := a.NotifierManager.SyncPagerDuty(ctx, combinedReport, appConfig.Notifications)
+	if err != nil {
+		log.Errorf("Failed to update PagerDuty incident: %v", err)
+	}
+
+	if dedupKey != appConfig.Notifications.PagerDutyDedupKey {
+		if err := a.Store.SavePagerDutyDedupKey(appConfig.Name, dedupKey); err != nil {
+			log.Errorf("Failed to save PagerDuty dedup key: %v", err)
+		}
+	}
+}
+
+// syncOpsgenie creates and closes the app's Opsgenie alerts and persists their aliases
+func (a *Application) syncOpsgenie(ctx context.Context, appConfig models.AppConfig, combinedReport *models.CombinedAppReport) {
+	log := helpers.Logger(ctx)
+
+	aliases, err := a.NotifierManager.SyncOpsgenie(ctx, combinedReport, appConfig.Notifications)
+	if err != nil {
+		log.Errorf("Failed to update Opsgenie alerts: %v", err)
+	}
+
+	if !slices.Equal(aliases, appConfig.Notifications.OpsgenieAliases) {
+		if err := a.Store.SaveOpsgenieAliases(appConfig.Name, aliases); err != nil {
+			log.Errorf("Failed to save Opsgenie aliases: %v", err)
+		}
+	}
+}
+
+// syncGitLab creates, updates and closes the app's GitLab issue and persists its reference
+func (a *Application) syncGitLab(ctx context.Context, appConfig models.AppConfig, combinedReport *models.CombinedAppReport) {
+	log := helpers.Logger(ctx)
+
+	issue, err := a.NotifierManager.SyncGitLab(ctx, combinedReport, appConfig.Notifications)
+	if err != nil {
+		log.Errorf("Failed to update GitLab issue: %v", err)
+	}
+
+	if issue != appConfig.Notifications.GitLabIssue {
+		if err := a.Store.SaveGitLabIssue(appConfig.Name, issue); err != nil {
+			log.Errorf("Failed to save GitLab issue: %v", err)
+		}
+	}
+}
+
 // auditorNames returns the names of auditors
 func auditorNames(auditors []auditor.Auditor) []string {
 	names := make([]string, len(auditors))
@@ -348,34 +809,59 @@ func auditorNames(auditors []auditor.Auditor) []string {
 	return names
 }
 
-// runSingleAudit runs a single auditor for an app.
+// runSingleAudit runs a single auditor for an app in workspace (the app path, or its snapshot).
 // Returns the report and generated file paths (does NOT send notifications).
-func (a *Application) runSingleAudit(ctx context.Context, appConfig models.AppConfig, aud auditor.Auditor) (*models.Report, []string, error) {
+func (a *Application) runSingleAudit(ctx context.Context, appConfig models.AppConfig, aud auditor.Auditor, workspace string) (*models.Report, []string, error) {
+	log := helpers.Logger(ctx).With("auditor", aud.Name())
+	ctx = helpers.WithLogger(ctx, log)
+
+	auditConfig := appConfig
+	auditConfig.Path = workspace
+
 	// Run audit with retry
 	var result *models.AuditResult
 	var err error
 	for attempt := 1; attempt <= a.Config.Settings.RetryAttempts; attempt++ {
-		result, err = aud.Audit(ctx, appConfig)
+		result, err = aud.Audit(ctx, auditConfig)
+		if err == nil && result.Questionable != "" && a.Config.Settings.StrictMode {
+			// Strict mode: a result that failed its sanity check is not trusted as clean
+			err = auditor.QuestionableError(aud.Name(), result.Questionable)
+		}
 		if err == nil {
 			break
 		}
 
-		zap.S().Warnf("Audit attempt failed app=%s auditor=%s attempt=%d error=%v",
+		log.Warnf("Audit attempt failed app=%s auditor=%s attempt=%d error=%v",
 			appConfig.Name,
 			aud.Name(),
 			attempt,
 			err,
 		)
 
+		// A missing binary or rejected credentials fail the same way on every attempt
+		failure := auditor.Classify(aud.Name(), err)
+		if !failure.Retryable() {
+			return nil, nil, failure
+		}
+
 		if attempt < a.Config.Settings.RetryAttempts {
 			time.Sleep(time.Second * time.Duration(attempt))
 		}
 	}
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("all audit attempts failed: %w", err)
+		return nil, nil, fmt.Errorf("all audit attempts failed: %w", auditor.Classify(aud.Name(), err))
 	}
 
+	// Results refer to the app, not its snapshot
+	result.AppPath = appConfig.Path
+	result.RunID = a.runID
+	result.LogFile = a.runLogFile
+	a.capRawOutput(ctx, result)
+
+	// Warn if the tool is older than the configured minimum
+	a.checkToolVersion(ctx, appConfig.Name, aud.Name(), result.ToolVersion)
+
 	// Filter by severity threshold
 	result.Vulnerabilities = auditor.FilterVulnerabilities(
 		result.Vulnerabilities,
@@ -383,34 +869,42 @@ func (a *Application) runSingleAudit(ctx context.Context, appConfig models.AppCo
 	)
 	result.UpdateCounts()
 
-	// Run Gemini analysis if enabled and vulnerabilities found
+	// Date every finding from the first audit that reported it, and order them for the reports
+	a.markFirstSeen(ctx, result)
+	models.SortVulnerabilities(result.Vulnerabilities, a.Config.Settings.IssueOrder, time.Now())
+
+	// Run the AI analysis, falling back through AI_ANALYZERS, if enabled for the app and vulnerabilities
+	// found, unless an earlier audit with the same findings was analyzed (AI_CACHE_TTL)
 	var aiAnalysis *models.AIAnalysis
-	if a.GeminiAnalyzer != nil && a.GeminiAnalyzer.Enabled() && result.HasVulnerabilities() {
-		analysis, err := a.GeminiAnalyzer.Analyze(ctx, result)
+	if a.AnalyzerManager != nil && result.HasVulnerabilities() {
+		analysis, err := a.analyze(ctx, appConfig, result)
 		if err != nil {
-			zap.S().Warnf("Gemini analysis failed: %v", err)
+			log.Warnf("AI analysis failed: %v", err)
 		} else {
 			aiAnalysis = analysis
 			if analysis != nil {
 				result.AISummary = analysis.Summary
+				result.AIProvenance = analysis.Provenance
 			}
 		}
 	}
 
-	// Store in database
-	if err := a.DB.Create(result).Error; err != nil {
-		zap.S().Errorf("Failed to store audit result: %v", err)
+	// Store the result
+	if err := a.Store.SaveAuditResult(result); err != nil {
+		log.Errorf("Failed to store audit result: %v", err)
 	}
 
 	// Create report
 	report := models.NewReport(result, aiAnalysis)
 
 	// Generate report files
-	filePaths, err := a.ReporterManager.GenerateFormats(report, a.Config.Settings.ReportFormats)
+	filePaths, err := a.ReporterManager.GenerateFormats(ctx, report, a.Config.Settings.ReportFormats)
 	if err != nil {
-		zap.S().Errorf("Failed to generate reports: %v", err)
+		log.Errorf("Failed to generate reports: %v", err)
 	}
 
+	a.exportDefectDojo(ctx, result)
+
 	// Update state
 	a.mu.Lock()
 	a.results = append(a.results, result)
@@ -422,16 +916,219 @@ func (a *Application) runSingleAudit(ctx context.Context, appConfig models.AppCo
 	return report, filePaths, nil
 }
 
-// generateSummary creates a summary report across all apps
-func (a *Application) generateSummary() error {
-	summary := models.NewAuditSummary(a.results)
+// exportDefectDojo imports an audit result into DefectDojo. Questionable results are
+// not imported, since they would close the findings they may have missed.
+func (a *Application) exportDefectDojo(ctx context.Context, result *models.AuditResult) {
+	if a.DefectDojo == nil {
+		return
+	}
+	log := helpers.Logger(ctx)
+
+	if result.Questionable != "" {
+		log.Warnf("Not importing questionable result into DefectDojo app=%s auditor=%s", result.AppName, result.AuditorType)
+		return
+	}
+	if a.Config.DryRun {
+		log.Infof("[DRY RUN] Would import into DefectDojo app=%s auditor=%s findings=%d",
+			result.AppName, result.AuditorType, len(result.Vulnerabilities))
+		return
+	}
 
-	return a.ReporterManager.GenerateSummaryReport(summary, a.Config.Settings.ReportFormats)
+	imported, err := a.DefectDojo.Export(ctx, result)
+	if err != nil {
+		log.Errorf("Failed to import into DefectDojo app=%s auditor=%s: %v", result.AppName, result.AuditorType, err)
+		return
+	}
+	log.Infof("Imported into DefectDojo app=%s auditor=%s findings=%d product_id=%d engagement_id=%d test_id=%d",
+		result.AppName, result.AuditorType, len(result.Vulnerabilities), imported.ProductID, imported.EngagementID, imported.TestID)
 }
 
-// outputJSON outputs results as JSON to stdout
-func (a *Application) outputJSON() {
-	summary := models.NewAuditSummary(a.results)
+// uploadDependencyTrack uploads the CycloneDX SBOM of an app to Dependency-Track, as
+// the version of its package.json or composer.json, or DEPENDENCYTRACK_PROJECT_VERSION
+func (a *Application) uploadDependencyTrack(ctx context.Context, appConfig models.AppConfig) {
+	if a.DependencyTrack == nil {
+		return
+	}
+	log := helpers.Logger(ctx)
+
+	deps, err := auditor.Dependencies(appConfig)
+	if auditor.IsNoLockfile(err) {
+		log.Debugf("No lockfile to upload to Dependency-Track app=%s", appConfig.Name)
+		return
+	}
+	if err != nil {
+		log.Errorf("Failed to read dependencies for Dependency-Track app=%s: %v", appConfig.Name, err)
+		return
+	}
+
+	version := auditor.AppVersion(appConfig)
+	if version == "" {
+		version = a.Config.DependencyTrackProjectVersion
+	}
+
+	if a.Config.DryRun {
+		log.Infof("[DRY RUN] Would upload SBOM to Dependency-Track app=%s version=%s components=%d",
+			appConfig.Name, version, len(deps))
+		return
+	}
+
+	bom, err := reporter.GenerateCycloneDX(appConfig.Name, version, deps, buildinfo.Version(), time.Now())
+	if err != nil {
+		log.Errorf("Failed to generate CycloneDX SBOM app=%s: %v", appConfig.Name, err)
+		return
+	}
+
+	token, err := a.DependencyTrack.Upload(ctx, appConfig.Name, version, bom)
+	if err != nil {
+		log.Errorf("Failed to upload SBOM to Dependency-Track app=%s: %v", appConfig.Name, err)
+		return
+	}
+	log.Infof("Uploaded SBOM to Dependency-Track app=%s version=%s components=%d token=%s",
+		appConfig.Name, version, len(deps), token)
+}
+
+// publishConfluence replaces the content of the app's Confluence page with its latest
+// report, creating the page on the app's first audit
+func (a *Application) publishConfluence(ctx context.Context, combinedReport *models.CombinedAppReport) {
+	if a.Confluence == nil {
+		return
+	}
+	log := helpers.Logger(ctx)
+
+	if a.Config.DryRun {
+		log.Infof("[DRY RUN] Would publish Confluence page app=%s title=%q",
+			combinedReport.AppName, a.Confluence.PageTitle(combinedReport.AppName))
+		return
+	}
+
+	body, err := reporter.GenerateConfluencePage(combinedReport)
+	if err != nil {
+		log.Errorf("Failed to generate Confluence page app=%s: %v", combinedReport.AppName, err)
+		return
+	}
+
+	page, err := a.Confluence.Publish(ctx, combinedReport.AppName, body)
+	if err != nil {
+		log.Errorf("Failed to publish Confluence page app=%s: %v", combinedReport.AppName, err)
+		return
+	}
+	log.Infof("Published Confluence page app=%s title=%q page_id=%s version=%d created=%t",
+		combinedReport.AppName, page.Title, page.ID, page.Version, page.Created)
+}
+
+// generateAppReports generates the reports of REPORT_FORMATS covering the whole app,
+// e.g. its CycloneDX SBOM with the findings of every auditor
+func (a *Application) generateAppReports(ctx context.Context, appConfig models.AppConfig, combinedReport *models.CombinedAppReport) {
+	formats := a.Config.Settings.ReportFormats
+	if !a.ReporterManager.HasAppFormats(formats) {
+		return
+	}
+	log := helpers.Logger(ctx)
+
+	// Without lockfile, the findings are still reported, against the app
+	deps, err := auditor.Dependencies(appConfig)
+	if err != nil && !auditor.IsNoLockfile(err) {
+		log.Errorf("Failed to read dependencies for the app reports app=%s: %v", appConfig.Name, err)
+		return
+	}
+
+	report := &reporter.AppReport{
+		AppName:      appConfig.Name,
+		AppVersion:   auditor.AppVersion(appConfig),
+		RunID:        a.runID,
+		Dependencies: deps,
+		GeneratedAt:  time.Now(),
+	}
+	for _, auditReport := range combinedReport.Reports {
+		report.Vulnerabilities = append(report.Vulnerabilities, auditReport.Vulnerabilities...)
+	}
+
+	if _, err := a.ReporterManager.GenerateAppFormats(ctx, report, formats); err != nil {
+		log.Errorf("Failed to generate app reports app=%s: %v", appConfig.Name, err)
+	}
+}
+
+// generateCombinedReports writes the combined report of an app in COMBINED_REPORT_FORMATS
+// and attaches it to the app's notifications instead of the report files of each auditor
+func (a *Application) generateCombinedReports(ctx context.Context, combinedReport *models.CombinedAppReport) {
+	formats := a.Config.Settings.CombinedFormats
+	if len(formats) == 0 || len(combinedReport.Reports) == 0 {
+		return
+	}
+
+	files, err := a.ReporterManager.GenerateCombined(ctx, combinedReport, formats)
+	if err != nil {
+		helpers.Logger(ctx).Errorf("Failed to generate combined reports app=%s: %v", combinedReport.AppName, err)
+	}
+	if len(files) > 0 {
+		combinedReport.ReportFiles = files
+	}
+}
+
+// markFirstSeen sets when each finding of result was first seen: at the first earlier
+// audit of the app and auditor that reported it, or now for new findings
+func (a *Application) markFirstSeen(ctx context.Context, result *models.AuditResult) {
+	if len(result.Vulnerabilities) == 0 {
+		return
+	}
+
+	seen, err := a.Store.FindingsFirstSeen(result.AppName, result.AuditorType)
+	if err != nil {
+		helpers.Logger(ctx).Warnf("Failed to query when findings were first seen: %v", err)
+		seen = nil
+	}
+
+	now := time.Now()
+	for i := range result.Vulnerabilities {
+		firstSeen := now
+		if at, ok := seen[result.Vulnerabilities[i].FindingKey()]; ok {
+			firstSeen = at
+		}
+		result.Vulnerabilities[i].FirstSeenAt = &firstSeen
+	}
+}
+
+// checkToolVersion logs a warning when the auditor tool version is unknown or
+// older than the configured minimum for that auditor
+func (a *Application) checkToolVersion(ctx context.Context, appName, auditorName, toolVersion string) {
+	log := helpers.Logger(ctx)
+
+	// Auditors that wrap several tools prefix the version with the tool name (e.g. "yarn 4.1.0")
+	if tool, _, ok := strings.Cut(toolVersion, " "); ok {
+		auditorName = tool
+	}
+
+	minVersion := a.Config.MinToolVersion(auditorName)
+	if minVersion == "" {
+		return
+	}
+
+	if toolVersion == "" {
+		log.Warnf("Could not determine %s version app=%s (minimum required: %s)",
+			auditorName,
+			appName,
+			minVersion,
+		)
+		return
+	}
+
+	if helpers.CompareVersions(toolVersion, minVersion) < 0 {
+		log.Warnf("%s version %s is older than the configured minimum %s app=%s",
+			auditorName,
+			toolVersion,
+			minVersion,
+			appName,
+		)
+	}
+}
+
+// generateSummary creates a summary report across all apps
+func (a *Application) generateSummary(ctx context.Context, summary *models.AuditSummary) error {
+	return a.ReporterManager.GenerateSummaryReport(ctx, summary, a.Config.Settings.ReportFormats)
+}
+
+// outputJSON outputs the run's summary as JSON to stdout
+func (a *Application) outputJSON(summary *models.AuditSummary) {
 	jsonData, err := json.MarshalIndent(summary, "", "  ")
 	if err != nil {
 		zap.S().Errorf("Failed to marshal JSON output: %v", err)
@@ -440,6 +1137,22 @@ func (a *Application) outputJSON() {
 	fmt.Println(string(jsonData))
 }
 
+// writeGitLabReport writes the run's results as a GitLab Dependency Scanning report
+func (a *Application) writeGitLabReport(ctx context.Context, started time.Time) {
+	log := helpers.Logger(ctx)
+
+	content, err := reporter.GenerateGitLabDependencyScanning(a.results, buildinfo.Version(), started, time.Now())
+	if err != nil {
+		log.Errorf("Failed to generate GitLab report: %v", err)
+		return
+	}
+	if err := os.WriteFile(a.Config.GitLabReportPath, content, 0644); err != nil {
+		log.Errorf("Failed to write GitLab report: %v", err)
+		return
+	}
+	log.Infof("GitLab Dependency Scanning report written file=%s", a.Config.GitLabReportPath)
+}
+
 // HasVulnerabilities returns true if any vulnerabilities were found
 func (a *Application) HasVulnerabilities() bool {
 	return a.hasVulnerabilities
@@ -447,16 +1160,15 @@ func (a *Application) HasVulnerabilities() bool {
 
 // Close cleans up resources
 func (a *Application) Close() error {
-	if a.GeminiAnalyzer != nil {
-		if err := a.GeminiAnalyzer.Close(); err != nil {
-			zap.S().Warnf("Failed to close Gemini analyzer: %v", err)
+	if a.AnalyzerManager != nil {
+		if err := a.AnalyzerManager.Close(); err != nil {
+			zap.S().Warnf("Failed to close AI analyzers: %v", err)
 		}
 	}
 
-	if a.DB != nil {
-		sqlDB, err := a.DB.DB()
-		if err == nil {
-			sqlDB.Close()
+	if a.Store != nil {
+		if err := a.Store.Close(); err != nil {
+			zap.S().Warnf("Failed to close store: %v", err)
 		}
 	}
 
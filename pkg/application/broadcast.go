@@ -0,0 +1,114 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/auditor"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// FindExposures returns, for each enabled app (paused ones included), the installed
+// packages the advisory affects. Packages are read from the app's lockfiles, or taken
+// from those recorded at its last audit when the lockfiles cannot be read here.
+func (a *Application) FindExposures(ctx context.Context, advisory auditor.Advisory) ([]models.ExposureNotice, error) {
+	log := helpers.Logger(ctx)
+
+	recorded := make(map[string][]models.AppPackage) // app name -> recorded packages
+	for _, ecosystem := range auditor.WatchedEcosystems {
+		names := advisory.PackageNames(ecosystem)
+		if len(names) == 0 {
+			continue
+		}
+		packages, err := a.Store.AppPackages(ecosystem, names)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up packages: %w", err)
+		}
+		for _, pkg := range packages {
+			recorded[pkg.AppName] = append(recorded[pkg.AppName], pkg)
+		}
+	}
+
+	var notices []models.ExposureNotice
+	for _, app := range a.Config.Apps {
+		if !app.Enabled {
+			continue
+		}
+
+		packages, err := auditor.InstalledPackages(app)
+		if err != nil || len(packages) == 0 {
+			if err != nil {
+				log.Debugf("Failed to read lockfiles of app=%s; using the packages recorded at its last audit: %v", app.Name, err)
+			}
+			packages = recorded[app.Name]
+		}
+
+		notice := models.ExposureNotice{
+			VulnerabilityID: advisory.ID,
+			Summary:         advisory.Summary,
+			AppName:         app.Name,
+		}
+		for _, pkg := range packages {
+			if advisory.Affects(pkg) {
+				notice.Packages = append(notice.Packages, pkg)
+			}
+		}
+		if len(notice.Packages) > 0 {
+			notices = append(notices, notice)
+		}
+	}
+
+	return notices, nil
+}
+
+// NotifyExposures sends each exposure notice to the owners of its app, with message
+// added, and records the broadcast in the activity log. Returns the names of the apps
+// notified.
+func (a *Application) NotifyExposures(ctx context.Context, notices []models.ExposureNotice, message string) ([]string, error) {
+	log := helpers.Logger(ctx)
+
+	var notified []string
+	var errs []error
+	for _, notice := range notices {
+		appConfig, err := a.Config.GetApp(notice.AppName)
+		if err != nil || appConfig == nil {
+			errs = append(errs, fmt.Errorf("%s: app not found", notice.AppName))
+			continue
+		}
+
+		notice.Message = message
+		notifyResult, err := a.NotifierManager.NotifyExposure(ctx, &notice, appConfig.Notifications)
+		if err != nil {
+			log.Errorf("Failed to send exposure notice app=%s: %v", notice.AppName, err)
+			errs = append(errs, fmt.Errorf("%s: %w", notice.AppName, err))
+		}
+		if !a.Config.DryRun {
+			a.saveNotificationTargets(ctx, *appConfig, notifyResult)
+		}
+		notified = append(notified, notice.AppName)
+	}
+
+	if !a.Config.DryRun && len(notified) > 0 {
+		a.recordBroadcast(ctx, notices[0].VulnerabilityID, notified)
+	}
+
+	if len(errs) > 0 {
+		return notified, fmt.Errorf("broadcast errors: %v", errs)
+	}
+	return notified, nil
+}
+
+// recordBroadcast records the broadcast in the activity log
+func (a *Application) recordBroadcast(ctx context.Context, vulnerabilityID string, notified []string) {
+	entry := &models.ActivityLog{
+		Operator: a.Config.Operator,
+		Source:   models.ActivitySourceCLI,
+		Action:   models.ActivityBroadcast,
+		Details:  fmt.Sprintf("vulnerability=%s apps=%s", vulnerabilityID, strings.Join(notified, ",")),
+	}
+	if err := a.Store.SaveActivity(entry); err != nil {
+		helpers.Logger(ctx).Warnf("Failed to record broadcast activity: %v", err)
+	}
+}
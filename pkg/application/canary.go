@@ -0,0 +1,82 @@
+package application
+
+import (
+	"context"
+	"math/rand/v2"
+	"slices"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// canaryFailure is an auditor failure seen by a canary run
+type canaryFailure struct {
+	AppName string
+	Failure models.AuditFailure
+}
+
+// sampleCanaryApps returns n apps picked at random, or all of them when there are no more
+func sampleCanaryApps(apps []models.AppConfig, n int) []models.AppConfig {
+	if n >= len(apps) {
+		return apps
+	}
+
+	sample := make([]models.AppConfig, 0, n)
+	for _, i := range rand.Perm(len(apps))[:n] {
+		sample = append(sample, apps[i])
+	}
+	return sample
+}
+
+// recordCanaryFailures keeps the auditor failures of an app audited by a canary run
+func (a *Application) recordCanaryFailures(appName string, failures []models.AuditFailure) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, failure := range failures {
+		a.canaryFailures = append(a.canaryFailures, canaryFailure{AppName: appName, Failure: failure})
+	}
+}
+
+// reportCanary logs the outcome of a canary run. Failures are grouped by auditor and
+// kind: the same failure in several sampled apps points at the tool or registry rather
+// than at one app.
+func (a *Application) reportCanary(ctx context.Context, sampled int) {
+	log := helpers.Logger(ctx)
+
+	a.mu.Lock()
+	failures := slices.Clone(a.canaryFailures)
+	a.mu.Unlock()
+
+	if len(failures) == 0 {
+		log.Infof("Canary passed apps=%d", sampled)
+		return
+	}
+
+	type failureGroup struct {
+		AuditorType string
+		Kind        string
+		Hint        string
+		Apps        []string
+	}
+	var groups []*failureGroup
+	for _, f := range failures {
+		i := slices.IndexFunc(groups, func(g *failureGroup) bool {
+			return g.AuditorType == f.Failure.AuditorType && g.Kind == f.Failure.Kind
+		})
+		if i < 0 {
+			groups = append(groups, &failureGroup{AuditorType: f.Failure.AuditorType, Kind: f.Failure.Kind, Hint: f.Failure.Hint})
+			i = len(groups) - 1
+		}
+		groups[i].Apps = append(groups[i].Apps, f.AppName)
+	}
+
+	for _, g := range groups {
+		if len(g.Apps) > 1 {
+			log.Errorf("Canary failed, likely systemic auditor=%s kind=%s apps=%d/%d (%s) hint=%q",
+				g.AuditorType, g.Kind, len(g.Apps), sampled, strings.Join(g.Apps, ", "), g.Hint)
+			continue
+		}
+		log.Errorf("Canary failed auditor=%s kind=%s app=%s hint=%q", g.AuditorType, g.Kind, g.Apps[0], g.Hint)
+	}
+}
@@ -0,0 +1,235 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/auditor"
+	"github.com/shadowbane/audit-checks/pkg/buildinfo"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// checkManifests are the manifests hashed into the check's cache key besides the
+// dependencyFiles: the auditors read them too
+var checkManifests = []string{
+	"package.json", "npm-shrinkwrap.json", "composer.json", "go.mod", "pubspec.lock",
+}
+
+// checkUncachedAuditors read files the cache key does not cover (the working copy,
+// nested charts and lockfiles): they run on every check
+var checkUncachedAuditors = []string{"secrets", "helm", "terraform"}
+
+// CheckOptions are the options of a developer check of a working copy
+type CheckOptions struct {
+	Path       string
+	Type       string   // auditor types, comma-separated, or auto
+	Threshold  string   // lowest severity failing the check
+	IgnoreList []string // CVEs or package names to ignore
+	NoCache    bool     // audit even if the dependency files are unchanged
+}
+
+// CheckResult is the outcome of a developer check
+type CheckResult struct {
+	Auditors []string         // auditors run or read from the cache
+	Findings []CheckFinding   // findings at or above the threshold, by severity
+	Failures map[string]error // auditor name -> error
+	Cached   bool             // every result was read from the cache
+}
+
+// CheckFinding is a finding of a check, with the auditor that reported it
+type CheckFinding struct {
+	Auditor string
+	models.Vulnerability
+}
+
+// key identifies the finding across checks of different commits
+func (f CheckFinding) key() string {
+	return f.Auditor + "\x00" + f.FindingKey()
+}
+
+// checkCacheEntry is a cached check: the results of the cached auditors, before the
+// threshold is applied
+type checkCacheEntry struct {
+	CreatedAt time.Time            `json:"created_at"`
+	Results   []models.AuditResult `json:"results"`
+}
+
+// Check audits a working copy without the database, for git hooks and other developer
+// workflows. Results are cached by a hash of the dependency files, so that repeated
+// checks of unchanged dependencies only read the cache (CHECK_CACHE_DIR) until it
+// expires (CHECK_CACHE_TTL).
+func Check(ctx context.Context, settings config.Settings, opts CheckOptions) (*CheckResult, error) {
+	log := helpers.Logger(ctx)
+
+	path, err := filepath.Abs(opts.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", path)
+	}
+
+	appConfig := models.AppConfig{
+		Name:       filepath.Base(path),
+		Path:       path,
+		Type:       opts.Type,
+		Enabled:    true,
+		IgnoreList: opts.IgnoreList,
+	}
+	auditors, err := NewAuditorRegistry(settings).GetAuditorsForApp(appConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CheckResult{Failures: make(map[string]error)}
+	for _, aud := range auditors {
+		result.Auditors = append(result.Auditors, aud.Name())
+	}
+	slices.Sort(result.Auditors)
+
+	key, err := checkCacheKey(settings, appConfig, result.Auditors)
+	if err != nil {
+		return nil, err
+	}
+	cachePath := filepath.Join(settings.CheckCacheDir, key+".json")
+
+	var cached checkCacheEntry
+	hit := !opts.NoCache && readCheckCache(cachePath, settings.CheckCacheTTL, &cached)
+	if hit {
+		log.Debugf("Check cache hit path=%s key=%s", path, key)
+	}
+
+	var results []models.AuditResult
+	ran := 0
+	fresh := checkCacheEntry{CreatedAt: time.Now().UTC()}
+	for _, aud := range auditors {
+		uncached := slices.Contains(checkUncachedAuditors, aud.Name())
+		if hit && !uncached {
+			continue
+		}
+
+		ran++
+		audited, err := aud.Audit(ctx, appConfig)
+		if err != nil {
+			result.Failures[aud.Name()] = auditor.Classify(aud.Name(), err)
+			continue
+		}
+		audited.RawOutput = ""
+		results = append(results, *audited)
+		if !uncached {
+			fresh.Results = append(fresh.Results, *audited)
+		}
+	}
+
+	if hit {
+		results = append(results, cached.Results...)
+		result.Cached = ran == 0
+	} else if len(result.Failures) == 0 {
+		// A failed auditor would pass every check until the cache expires
+		writeCheckCache(ctx, settings.CheckCacheDir, cachePath, settings.CheckCacheTTL, fresh)
+	}
+
+	for _, r := range results {
+		for _, v := range auditor.FilterVulnerabilities(r.Vulnerabilities, opts.Threshold) {
+			result.Findings = append(result.Findings, CheckFinding{Auditor: r.AuditorType, Vulnerability: v})
+		}
+	}
+	sortCheckFindings(result.Findings)
+
+	return result, nil
+}
+
+// sortCheckFindings orders findings most severe first
+func sortCheckFindings(findings []CheckFinding) {
+	slices.SortStableFunc(findings, func(a, b CheckFinding) int {
+		return cmp.Or(
+			models.SeverityOrder[b.Severity]-models.SeverityOrder[a.Severity],
+			cmp.Compare(a.PackageName, b.PackageName),
+			cmp.Compare(a.CVEID, b.CVEID),
+			cmp.Compare(a.Title, b.Title),
+			cmp.Compare(a.Auditor, b.Auditor),
+		)
+	})
+}
+
+// checkCacheKey hashes what a check's results depend on: the dependency files of the
+// app, its auditors and their settings, the ignore list and the release
+func checkCacheKey(settings config.Settings, app models.AppConfig, auditors []string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%v\x00%v\x00", buildinfo.Version(), app.Path, auditors, app.IgnoreList)
+	fmt.Fprintf(h, "%v\x00%v\x00%s\x00%s\x00%t\x00%t\x00%s\x00%t\x00%t\x00%t\x00%t\x00",
+		settings.NPMAuditOptions, settings.ComposerAuditOptions, settings.JavaAuditBackend,
+		settings.SystemAuditBackend, settings.PinningPolicyEnabled, settings.OutdatedAuditEnabled,
+		settings.OutdatedAuditSeverity, settings.SupplyChainAuditEnabled, settings.SecretsAuditEnabled,
+		settings.PHPEOLAuditEnabled, settings.NodeEOLAuditEnabled)
+
+	var files []string
+	for _, pattern := range append(slices.Clone(dependencyFiles), checkManifests...) {
+		matches, _ := filepath.Glob(filepath.Join(app.Path, pattern))
+		files = append(files, matches...)
+	}
+	slices.Sort(files)
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		sum := sha256.Sum256(content)
+		fmt.Fprintf(h, "%s\x00%s\x00", filepath.Base(file), hex.EncodeToString(sum[:]))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// readCheckCache decodes the cache entry at path into out. Returns false if there is
+// none or it is older than ttl.
+func readCheckCache(path string, ttl time.Duration, out *checkCacheEntry) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	if json.Unmarshal(content, out) != nil {
+		return false
+	}
+	return time.Since(out.CreatedAt) < ttl
+}
+
+// writeCheckCache stores entry at path, and removes the expired entries of dir: every
+// change of the dependency files leaves one behind. Failures only disable caching.
+func writeCheckCache(ctx context.Context, dir, path string, ttl time.Duration, entry checkCacheEntry) {
+	log := helpers.Logger(ctx)
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Debugf("Failed to create check cache directory: %v", err)
+		return
+	}
+
+	if files, err := os.ReadDir(dir); err == nil {
+		for _, file := range files {
+			info, err := file.Info()
+			if err == nil && strings.HasSuffix(file.Name(), ".json") && time.Since(info.ModTime()) > ttl {
+				_ = os.Remove(filepath.Join(dir, file.Name()))
+			}
+		}
+	}
+
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		log.Debugf("Failed to write check cache entry %s: %v", path, err)
+	}
+}
@@ -0,0 +1,127 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// notifierDrillSettingKey is the setting holding when the last notifier drill ran
+const notifierDrillSettingKey = "notifier_drill_last_run"
+
+// DrillNotifiers sends a labeled test message on each enabled channel of channels, to
+// the destinations of the enabled apps, and raises a meta-alert through the channels
+// that passed when others failed. The drill is recorded in the activity log.
+func (a *Application) DrillNotifiers(ctx context.Context, channels []string) ([]notifier.DrillResult, error) {
+	log := helpers.Logger(ctx)
+
+	targets := notifier.DrillTargets{Email: a.Config.Settings.NotifierDrillEmails}
+	for _, app := range a.Config.GetEnabledApps() {
+		targets.Apps = append(targets.Apps, app.Notifications)
+	}
+
+	log.Infof("Starting notifier drill channels=%v", channels)
+	results := a.NotifierManager.Drill(ctx, channels, targets)
+
+	// A configured channel whose notifier could not start, e.g. with a revoked Telegram
+	// token, is as broken as one that fails the drill
+	configured := map[string]bool{
+		"email":      a.Config.IsEmailEnabled(),
+		"telegram":   a.Config.IsTelegramEnabled(),
+		"discord":    a.Config.IsDiscordEnabled(),
+		"mattermost": a.Config.IsMattermostEnabled(),
+		"webhook":    a.Config.IsWebhookEnabled(),
+		"ntfy":       a.Config.IsNtfyEnabled(),
+		"gotify":     a.Config.IsGotifyEnabled(),
+		"zulip":      a.Config.IsZulipEnabled(),
+		"opsgenie":   a.Config.IsOpsgenieEnabled(),
+		"pagerduty":  a.Config.IsPagerDutyEnabled(),
+		"desktop":    a.Config.DesktopEnabled,
+	}
+	started := a.NotifierManager.EnabledNotifiers()
+	for _, channel := range notifier.DrillChannels {
+		if slices.Contains(channels, channel) && configured[channel] && !slices.Contains(started, channel) {
+			log.Errorf("Notifier drill failed channel=%s: the notifier did not start", channel)
+			results = append(results, notifier.DrillResult{
+				Channel: channel,
+				Error:   fmt.Errorf("the notifier did not start; check its settings and the log"),
+			})
+		}
+	}
+
+	var passed, failed []string
+	for _, result := range results {
+		if result.Error != nil {
+			failed = append(failed, result.Channel)
+		} else {
+			passed = append(passed, result.Channel)
+		}
+	}
+
+	if len(failed) > 0 {
+		if err := a.NotifierManager.DrillAlert(ctx, results, targets); err != nil {
+			log.Errorf("Failed to alert about the notifier drill: %v", err)
+		}
+	}
+
+	// A dry run must not delay the real drill
+	if !a.Config.DryRun {
+		a.recordDrill(ctx, passed, failed)
+		if err := a.Store.SaveSetting(notifierDrillSettingKey, time.Now().UTC().Format(time.RFC3339)); err != nil {
+			log.Warnf("Failed to save notifier drill time: %v", err)
+		}
+	}
+
+	if len(failed) > 0 {
+		return results, fmt.Errorf("notifier drill failed on %s", strings.Join(failed, ", "))
+	}
+	log.Infof("Notifier drill completed channels=%d", len(results))
+	return results, nil
+}
+
+// recordDrill records the drill in the activity log
+func (a *Application) recordDrill(ctx context.Context, passed, failed []string) {
+	entry := &models.ActivityLog{
+		Operator: a.Config.Operator,
+		Source:   models.ActivitySourceCLI,
+		Action:   models.ActivityDrill,
+		Details:  fmt.Sprintf("passed=%s failed=%s", strings.Join(passed, ","), strings.Join(failed, ",")),
+	}
+	if err := a.Store.SaveActivity(entry); err != nil {
+		helpers.Logger(ctx).Warnf("Failed to record notifier drill activity: %v", err)
+	}
+}
+
+// maybeDrillNotifiers runs the notifier drill once NOTIFIER_DRILL_INTERVAL has elapsed
+// since the last one, or when there was none yet
+func (w *AdvisoryWatcher) maybeDrillNotifiers(ctx context.Context) {
+	log := helpers.Logger(ctx)
+
+	if !w.cfg.Settings.NotifierDrillEnabled || ctx.Err() != nil {
+		return
+	}
+
+	if value, ok, err := w.store.Setting(notifierDrillSettingKey); err == nil && ok {
+		if last, err := time.Parse(time.RFC3339, value); err == nil && time.Since(last) < w.cfg.Settings.NotifierDrillInterval {
+			return
+		}
+	}
+
+	cfg := *w.cfg
+	app, err := New(&cfg)
+	if err != nil {
+		log.Errorf("Failed to initialize application for the notifier drill: %v", err)
+		return
+	}
+	defer app.Close()
+
+	if _, err := app.DrillNotifiers(ctx, cfg.Settings.NotifierDrillChannels); err != nil {
+		log.Errorf("Notifier drill: %v", err)
+	}
+}
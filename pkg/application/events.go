@@ -0,0 +1,65 @@
+package application
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+// runEventRetention is how long progress events are kept before being purged
+const runEventRetention = 7 * 24 * time.Hour
+
+// emitEvent records a progress event for the current run.
+// Failures are logged and otherwise ignored; events must never break an audit.
+func (a *Application) emitEvent(event models.RunEvent) {
+	event.RunID = a.runID
+
+	a.mu.Lock()
+	event.AppsCompleted = a.appsCompleted
+	event.AppsTotal = a.appsTotal
+	a.mu.Unlock()
+
+	if err := a.Store.SaveRunEvent(&event); err != nil {
+		zap.S().Debugf("Failed to record run event type=%s: %v", event.Type, err)
+	}
+}
+
+// recordRunTrigger records who started the run in the activity log
+func (a *Application) recordRunTrigger() {
+	details := fmt.Sprintf("run_id=%s apps=%d", a.runID, a.appsTotal)
+	if a.Config.DryRun {
+		details += " dry_run=true"
+	}
+	if a.Config.CanarySize > 0 && a.Config.TargetApp == "" {
+		details += fmt.Sprintf(" canary=%d", a.Config.CanarySize)
+	}
+	if a.Config.RunReason != "" {
+		details += fmt.Sprintf(" reason=%q", a.Config.RunReason)
+	}
+
+	source := a.Config.RunSource
+	if source == "" {
+		source = models.ActivitySourceCLI
+	}
+
+	entry := &models.ActivityLog{
+		Operator: a.Config.Operator,
+		Source:   source,
+		Action:   models.ActivityRunStarted,
+		AppName:  a.Config.TargetApp,
+		Details:  details,
+	}
+	if err := a.Store.SaveActivity(entry); err != nil {
+		zap.S().Warnf("Failed to record run activity: %v", err)
+	}
+}
+
+// purgeRunEvents deletes progress events older than runEventRetention
+func (a *Application) purgeRunEvents() {
+	cutoff := time.Now().Add(-runEventRetention)
+	if err := a.Store.PurgeRunEvents(cutoff); err != nil {
+		zap.S().Debugf("Failed to purge old run events: %v", err)
+	}
+}
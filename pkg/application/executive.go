@@ -0,0 +1,423 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+	"github.com/shadowbane/audit-checks/pkg/reporter"
+)
+
+const (
+	// executiveReportSettingKey stores when the scheduled executive report was last generated
+	executiveReportSettingKey = "executive_report_last_generated"
+	// ExecutiveReportPeriod is the period covered by the scheduled executive report
+	ExecutiveReportPeriod = 7 * 24 * time.Hour
+	// executiveReportTopN limits the offender, advisory and overdue lists
+	executiveReportTopN = 10
+)
+
+// findingKey identifies a finding across audits of the same app and auditor
+type findingKey struct {
+	AppName     string
+	AuditorType string
+	PackageName string
+	ID          string // CVE ID, or the title when there is none
+}
+
+// BuildExecutiveReport builds the executive report for the period ending at end
+func (a *Application) BuildExecutiveReport(end time.Time, period time.Duration) (*models.ExecutiveReport, error) {
+	start := end.Add(-period)
+
+	report := &models.ExecutiveReport{
+		PeriodStart: start,
+		PeriodEnd:   end,
+		GeneratedAt: time.Now(),
+		TotalApps:   len(a.Config.Apps),
+	}
+
+	apps := make(map[string]bool, len(a.Config.Apps))
+	for _, app := range a.Config.Apps {
+		apps[app.Name] = true
+	}
+
+	// Times are compared in Go: SQLite stores them as text, so SQL comparisons
+	// would depend on the time zone the rows were written in
+	results, err := a.Store.AuditResults()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit results: %w", err)
+	}
+
+	audited := make(map[string]bool)
+	resultsByID := make(map[string]models.AuditResult, len(results))
+	for _, r := range results {
+		resultsByID[r.ID] = r
+		if apps[r.AppName] && !r.CreatedAt.Before(start) && !r.CreatedAt.After(end) {
+			report.Audits++
+			audited[r.AppName] = true
+		}
+	}
+	report.AppsAudited = len(audited)
+
+	current := latestResults(results, apps, end)
+	previous := latestResults(results, apps, start)
+
+	// Trends and top offenders
+	risks := make(map[string]*models.AppRisk)
+	risk := func(appName string) *models.AppRisk {
+		if risks[appName] == nil {
+			risks[appName] = &models.AppRisk{AppName: appName}
+		}
+		return risks[appName]
+	}
+	for _, r := range current {
+		addToSummary(&report.Current, r)
+		addToSummary(&risk(r.AppName).Current, r)
+	}
+	for _, r := range previous {
+		addToSummary(&report.Previous, r)
+		addToSummary(&risk(r.AppName).Previous, r)
+	}
+
+	for _, r := range risks {
+		if r.Current.Total > 0 {
+			report.TopOffenders = append(report.TopOffenders, *r)
+		}
+	}
+	sort.Slice(report.TopOffenders, func(i, j int) bool {
+		x, y := report.TopOffenders[i].Current, report.TopOffenders[j].Current
+		if x.Critical != y.Critical {
+			return x.Critical > y.Critical
+		}
+		if x.High != y.High {
+			return x.High > y.High
+		}
+		if x.Total != y.Total {
+			return x.Total > y.Total
+		}
+		return report.TopOffenders[i].AppName < report.TopOffenders[j].AppName
+	})
+	report.TopOffenders = report.TopOffenders[:min(len(report.TopOffenders), executiveReportTopN)]
+
+	// Open findings, and when each was first seen
+	openFindings, err := a.openFindings(current, resultsByID, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report.SLA = a.slaCompliance(openFindings)
+	report.NewAdvisories = newAdvisories(openFindings, start)
+
+	for _, f := range openFindings {
+		if f.SLADays > 0 && f.AgeDays > f.SLADays {
+			report.Overdue = append(report.Overdue, f.OpenFinding)
+		}
+	}
+	sort.Slice(report.Overdue, func(i, j int) bool {
+		x, y := report.Overdue[i], report.Overdue[j]
+		if x.AgeDays-x.SLADays != y.AgeDays-y.SLADays {
+			return x.AgeDays-x.SLADays > y.AgeDays-y.SLADays
+		}
+		return x.AppName < y.AppName
+	})
+	report.Overdue = report.Overdue[:min(len(report.Overdue), executiveReportTopN)]
+
+	return report, nil
+}
+
+// latestResults returns the latest result of each app and auditor at t.
+// results must be sorted by creation time.
+func latestResults(results []models.AuditResult, apps map[string]bool, t time.Time) []models.AuditResult {
+	latest := make(map[[2]string]models.AuditResult)
+	for _, r := range results {
+		if r.CreatedAt.After(t) {
+			break
+		}
+		if apps[r.AppName] {
+			latest[[2]string{r.AppName, r.AuditorType}] = r
+		}
+	}
+
+	list := make([]models.AuditResult, 0, len(latest))
+	for _, r := range latest {
+		list = append(list, r)
+	}
+	return list
+}
+
+// addToSummary adds an audit result's counts to a summary
+func addToSummary(s *models.Summary, r models.AuditResult) {
+	s.Total += r.TotalVulnerabilities
+	s.Critical += r.CriticalCount
+	s.High += r.HighCount
+	s.Moderate += r.ModerateCount
+	s.Low += r.LowCount
+	s.Info += r.InfoCount
+}
+
+// openFinding is an open finding with the details needed to group advisories
+type openFinding struct {
+	models.OpenFinding
+	AuditorType string
+	URL         string
+}
+
+// openFindings returns the findings of the current results, dated by the first
+// audit of the same app and auditor that reported them
+func (a *Application) openFindings(current []models.AuditResult, resultsByID map[string]models.AuditResult, now time.Time) ([]openFinding, error) {
+	if len(current) == 0 {
+		return nil, nil
+	}
+
+	currentIDs := make([]string, 0, len(current))
+	for _, r := range current {
+		currentIDs = append(currentIDs, r.ID)
+	}
+
+	vulns, err := a.Store.Vulnerabilities(currentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vulnerabilities: %w", err)
+	}
+
+	// First sighting of every finding in the history
+	history, err := a.Store.VulnerabilityHistory()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vulnerability history: %w", err)
+	}
+
+	firstSeen := make(map[findingKey]time.Time)
+	for _, v := range history {
+		r, ok := resultsByID[v.AuditResultID]
+		if !ok {
+			continue
+		}
+		key := newFindingKey(r, v)
+		if seen, ok := firstSeen[key]; !ok || r.CreatedAt.Before(seen) {
+			firstSeen[key] = r.CreatedAt
+		}
+	}
+
+	findings := make([]openFinding, 0, len(vulns))
+	for _, v := range vulns {
+		r := resultsByID[v.AuditResultID]
+		seen, ok := firstSeen[newFindingKey(r, v)]
+		if !ok {
+			seen = r.CreatedAt
+		}
+
+		findings = append(findings, openFinding{
+			OpenFinding: models.OpenFinding{
+				AppName:     r.AppName,
+				PackageName: v.PackageName,
+				Severity:    v.Severity,
+				CVEID:       v.CVEID,
+				Title:       v.Title,
+				FirstSeen:   seen,
+				AgeDays:     int(now.Sub(seen).Hours() / 24),
+				SLADays:     a.Config.Settings.SLADays[v.Severity],
+			},
+			AuditorType: r.AuditorType,
+			URL:         v.URL,
+		})
+	}
+
+	return findings, nil
+}
+
+// newFindingKey identifies a vulnerability of an audit result
+func newFindingKey(r models.AuditResult, v models.Vulnerability) findingKey {
+	id := v.CVEID
+	if id == "" {
+		id = v.Title
+	}
+	return findingKey{AppName: r.AppName, AuditorType: r.AuditorType, PackageName: v.PackageName, ID: id}
+}
+
+// slaCompliance counts open findings within and beyond their severity's SLA
+func (a *Application) slaCompliance(findings []openFinding) []models.SLACompliance {
+	severities := []string{models.SeverityCritical, models.SeverityHigh, models.SeverityModerate, models.SeverityLow}
+
+	compliance := make([]models.SLACompliance, 0, len(severities))
+	for _, severity := range severities {
+		slaDays := a.Config.Settings.SLADays[severity]
+		if slaDays <= 0 {
+			continue
+		}
+
+		c := models.SLACompliance{Severity: severity, SLADays: slaDays}
+		for _, f := range findings {
+			if f.Severity != severity {
+				continue
+			}
+			c.Open++
+			if f.AgeDays > slaDays {
+				c.Overdue++
+			} else {
+				c.WithinSLA++
+			}
+		}
+		compliance = append(compliance, c)
+	}
+
+	return compliance
+}
+
+// newAdvisories groups the critical and high findings first seen since start by advisory
+func newAdvisories(findings []openFinding, start time.Time) []models.Advisory {
+	byID := make(map[string]*models.Advisory)
+	var order []string
+
+	for _, f := range findings {
+		if f.FirstSeen.Before(start) || models.SeverityOrder[f.Severity] < models.SeverityOrder[models.SeverityHigh] {
+			continue
+		}
+
+		id := f.CVEID
+		if id == "" {
+			id = f.Title
+		}
+		key := f.PackageName + "|" + id
+
+		advisory, ok := byID[key]
+		if !ok {
+			advisory = &models.Advisory{
+				ID:          f.CVEID,
+				PackageName: f.PackageName,
+				Severity:    f.Severity,
+				Title:       f.Title,
+				URL:         f.URL,
+				FirstSeen:   f.FirstSeen,
+			}
+			byID[key] = advisory
+			order = append(order, key)
+		}
+		if f.FirstSeen.Before(advisory.FirstSeen) {
+			advisory.FirstSeen = f.FirstSeen
+		}
+		if !slices.Contains(advisory.Apps, f.AppName) {
+			advisory.Apps = append(advisory.Apps, f.AppName)
+		}
+	}
+
+	advisories := make([]models.Advisory, 0, len(order))
+	for _, key := range order {
+		sort.Strings(byID[key].Apps)
+		advisories = append(advisories, *byID[key])
+	}
+	sort.SliceStable(advisories, func(i, j int) bool {
+		x, y := advisories[i], advisories[j]
+		if models.SeverityOrder[x.Severity] != models.SeverityOrder[y.Severity] {
+			return models.SeverityOrder[x.Severity] > models.SeverityOrder[y.Severity]
+		}
+		if len(x.Apps) != len(y.Apps) {
+			return len(x.Apps) > len(y.Apps)
+		}
+		return x.FirstSeen.Before(y.FirstSeen)
+	})
+
+	return advisories[:min(len(advisories), executiveReportTopN)]
+}
+
+// GenerateExecutiveReport builds the executive report for the period ending now,
+// saves it under the executive reports directory and emails it to the
+// executive report recipients. Returns the generated file paths.
+func (a *Application) GenerateExecutiveReport(ctx context.Context, period time.Duration, sendEmail bool) ([]string, error) {
+	log := helpers.Logger(ctx)
+
+	report, err := a.BuildExecutiveReport(time.Now(), period)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fleet-level AI analysis of the open findings (GEMINI_FLEET_ANALYSIS)
+	if a.Config.GeminiFleetAnalysis && a.AnalyzerManager != nil && a.AnalyzerManager.Enabled() {
+		summary, err := a.openFindingsSummary(report.PeriodEnd)
+		if err != nil {
+			log.Warnf("Failed to summarize open findings for the fleet analysis: %v", err)
+		} else {
+			report.AIAnalysis = a.analyzeFleet(ctx, summary)
+		}
+	}
+
+	files, err := a.ReporterManager.SaveExecutiveReport(report)
+	if err != nil {
+		return files, err
+	}
+
+	if !sendEmail {
+		return files, nil
+	}
+
+	recipients := a.Config.Settings.ExecutiveReportEmails
+	if len(recipients) == 0 {
+		log.Info("No executive report recipients configured (EXECUTIVE_REPORT_EMAILS)")
+		return files, nil
+	}
+
+	email, ok := a.NotifierManager.Get("email")
+	if !ok || !email.Enabled() {
+		log.Warn("Email is not configured, executive report not sent")
+		return files, nil
+	}
+
+	if a.Config.DryRun {
+		log.Infof("DRY RUN: Would send executive report recipients=%v", recipients)
+		return files, nil
+	}
+
+	htmlBody, err := reporter.GenerateExecutiveHTML(report)
+	if err != nil {
+		return files, err
+	}
+	// The Markdown report reads well as the plain-text alternative
+	textBody, err := reporter.GenerateExecutiveMarkdown(report)
+	if err != nil {
+		return files, err
+	}
+
+	if err := email.(*notifier.EmailNotifier).SendExecutiveReport(ctx, report, string(htmlBody), string(textBody), recipients); err != nil {
+		return files, fmt.Errorf("failed to send executive report: %w", err)
+	}
+
+	log.Infof("Executive report sent recipients=%d", len(recipients))
+
+	return files, nil
+}
+
+// maybeGenerateExecutiveReport generates the weekly executive report at the end
+// of a run when one has not been generated for a week. The last generation time
+// is stored in the settings table, so frequent cron runs only send it once.
+func (a *Application) maybeGenerateExecutiveReport(ctx context.Context) {
+	log := helpers.Logger(ctx)
+
+	if !a.Config.Settings.ExecutiveReportEnabled {
+		return
+	}
+
+	if value, ok, err := a.Store.Setting(executiveReportSettingKey); err == nil && ok {
+		if last, err := time.Parse(time.RFC3339, value); err == nil && time.Since(last) < ExecutiveReportPeriod {
+			return
+		}
+	}
+
+	log.Info("Generating weekly executive report")
+
+	if _, err := a.GenerateExecutiveReport(ctx, ExecutiveReportPeriod, true); err != nil {
+		log.Errorf("Failed to generate executive report: %v", err)
+		return
+	}
+
+	// A dry run must not delay the real report
+	if a.Config.DryRun {
+		return
+	}
+
+	if err := a.Store.SaveSetting(executiveReportSettingKey, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		log.Warnf("Failed to save executive report time: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package application
+
+import (
+	"slices"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// fairOrder orders apps so that their owners take turns: each turn takes the next
+// apps of every owner, as many as its weight (OWNER_WEIGHTS, 1 by default). Started in
+// this order, one team's fifty apps can't keep another team's five waiting when
+// MAX_CONCURRENT is limited. Apps without an owner take turns as one owner, and each
+// owner's apps keep their order.
+func fairOrder(apps []models.AppConfig, weights map[string]int) []models.AppConfig {
+	queues := make(map[string][]models.AppConfig)
+	var owners []string
+	for _, app := range apps {
+		if _, ok := queues[app.Owner]; !ok {
+			owners = append(owners, app.Owner)
+		}
+		queues[app.Owner] = append(queues[app.Owner], app)
+	}
+	if len(owners) <= 1 {
+		return apps
+	}
+	slices.Sort(owners)
+
+	ordered := make([]models.AppConfig, 0, len(apps))
+	for len(ordered) < len(apps) {
+		for _, owner := range owners {
+			n := min(max(weights[owner], 1), len(queues[owner]))
+			ordered = append(ordered, queues[owner][:n]...)
+			queues[owner] = queues[owner][n:]
+		}
+	}
+	return ordered
+}
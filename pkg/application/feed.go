@@ -0,0 +1,136 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// feedMaxEntries caps the entries of the feed, for the weeks every app gets new findings
+const feedMaxEntries = 200
+
+// BuildFeed builds the feed of the findings first seen in the last FEED_DAYS at now: an
+// entry per run and app, with the findings that run reported for the first time. A
+// finding is new in the first audit of its app and auditor since it was first seen.
+func (a *Application) BuildFeed(now time.Time) (*models.Feed, error) {
+	apps := make(map[string]bool, len(a.Config.Apps))
+	for _, app := range a.Config.Apps {
+		apps[app.Name] = true
+	}
+
+	results, err := a.Store.AuditResults()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit results: %w", err)
+	}
+	resultsByID := make(map[string]models.AuditResult, len(results))
+	for _, r := range results {
+		resultsByID[r.ID] = r
+	}
+
+	since := now.AddDate(0, 0, -a.Config.Settings.FeedDays)
+	vulns, err := a.Store.VulnerabilitiesFirstSeenSince(since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query new findings: %w", err)
+	}
+
+	// Later audits report the finding again, with the same first sighting
+	type finding struct{ app, auditor, key string }
+	first := make(map[finding]models.AuditResult)
+	firstVulns := make(map[finding]models.Vulnerability)
+	for _, v := range vulns {
+		r, ok := resultsByID[v.AuditResultID]
+		if !ok || !apps[r.AppName] || r.CreatedAt.After(now) {
+			continue
+		}
+		key := finding{r.AppName, r.AuditorType, v.FindingKey()}
+		if previous, ok := first[key]; ok && !r.CreatedAt.Before(previous.CreatedAt) {
+			continue
+		}
+		first[key], firstVulns[key] = r, v
+	}
+
+	entries := make(map[[2]string]*models.FeedEntry)
+	for key, r := range first {
+		id := [2]string{cmp.Or(r.RunID, r.ID), r.AppName}
+		entry, ok := entries[id]
+		if !ok {
+			entry = &models.FeedEntry{RunID: id[0], AppName: r.AppName}
+			entries[id] = entry
+		}
+		if r.CreatedAt.After(entry.UpdatedAt) {
+			entry.UpdatedAt = r.CreatedAt
+		}
+		entry.Findings = append(entry.Findings, models.FeedFinding{Vulnerability: firstVulns[key], AuditorType: r.AuditorType})
+	}
+
+	feed := &models.Feed{
+		Title:     a.Config.Settings.FeedTitle,
+		URL:       a.Config.Settings.FeedURL,
+		UpdatedAt: now,
+		Entries:   []models.FeedEntry{},
+	}
+	for _, entry := range entries {
+		counts := models.AuditResult{}
+		for _, f := range entry.Findings {
+			counts.Vulnerabilities = append(counts.Vulnerabilities, f.Vulnerability)
+		}
+		counts.UpdateCounts()
+		addToSummary(&entry.Summary, counts)
+
+		slices.SortFunc(entry.Findings, func(x, y models.FeedFinding) int {
+			return cmp.Or(
+				models.SeverityOrder[y.Severity]-models.SeverityOrder[x.Severity],
+				cmp.Compare(x.PackageName, y.PackageName),
+				cmp.Compare(x.CVEID, y.CVEID),
+				cmp.Compare(x.Title, y.Title),
+			)
+		})
+		feed.Entries = append(feed.Entries, *entry)
+	}
+	slices.SortFunc(feed.Entries, func(x, y models.FeedEntry) int {
+		return cmp.Or(y.UpdatedAt.Compare(x.UpdatedAt), cmp.Compare(x.AppName, y.AppName))
+	})
+	if len(feed.Entries) > feedMaxEntries {
+		feed.Entries = feed.Entries[:feedMaxEntries]
+	}
+	if len(feed.Entries) > 0 {
+		feed.UpdatedAt = feed.Entries[0].UpdatedAt
+	}
+
+	return feed, nil
+}
+
+// GenerateFeed writes the feed of new findings to path, or to FEED_PATH when empty.
+// Returns the written file path.
+func (a *Application) GenerateFeed(ctx context.Context, path string) (string, error) {
+	feed, err := a.BuildFeed(time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	path = cmp.Or(path, a.Config.Settings.FeedPath)
+	if err := a.ReporterManager.SaveFeed(feed, path); err != nil {
+		return "", err
+	}
+
+	helpers.Logger(ctx).Infof("Feed generated entries=%d file=%s", len(feed.Entries), path)
+
+	return path, nil
+}
+
+// maybeGenerateFeed rewrites the feed at the end of a run, so subscribers get the
+// findings the run found
+func (a *Application) maybeGenerateFeed(ctx context.Context) {
+	if !a.Config.Settings.FeedEnabled {
+		return
+	}
+
+	if _, err := a.GenerateFeed(ctx, ""); err != nil {
+		helpers.Logger(ctx).Errorf("Failed to generate feed: %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// analyzeFleet returns the Gemini analysis of the findings of all apps of summary
+// together (GEMINI_FLEET_ANALYSIS), or nil when it is off, nothing is vulnerable or
+// it failed. The results of apps whose AI analysis is off are left out.
+func (a *Application) analyzeFleet(ctx context.Context, summary *models.AuditSummary) *models.FleetAnalysis {
+	if !a.Config.GeminiFleetAnalysis || a.AnalyzerManager == nil || !a.AnalyzerManager.Enabled() {
+		return nil
+	}
+	log := helpers.Logger(ctx)
+
+	aiDisabled := make(map[string]bool)
+	for _, app := range a.Config.Apps {
+		aiDisabled[app.Name] = app.AIDisabled
+	}
+	results := make([]*models.AuditResult, 0, len(summary.Results))
+	for _, r := range summary.Results {
+		if !aiDisabled[r.AppName] {
+			results = append(results, r)
+		}
+	}
+
+	fleet := models.NewAuditSummary(results)
+	if fleet.TotalVulnerabilities == 0 {
+		return nil
+	}
+
+	analysis, err := a.AnalyzerManager.AnalyzeFleet(ctx, fleet)
+	if err != nil {
+		log.Warnf("Gemini fleet analysis failed: %v", err)
+		return nil
+	}
+	return analysis
+}
+
+// openFindingsSummary returns the latest results of each app and auditor at end, with
+// their vulnerabilities, as the summary the executive report's fleet analysis is of
+func (a *Application) openFindingsSummary(end time.Time) (*models.AuditSummary, error) {
+	apps := make(map[string]bool, len(a.Config.Apps))
+	for _, app := range a.Config.Apps {
+		apps[app.Name] = true
+	}
+
+	results, err := a.Store.AuditResults()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit results: %w", err)
+	}
+	current := latestResults(results, apps, end)
+
+	ids := make([]string, 0, len(current))
+	byID := make(map[string]*models.AuditResult, len(current))
+	latest := make([]*models.AuditResult, 0, len(current))
+	for i := range current {
+		ids = append(ids, current[i].ID)
+		byID[current[i].ID] = &current[i]
+		latest = append(latest, &current[i])
+	}
+
+	if len(ids) > 0 {
+		vulns, err := a.Store.Vulnerabilities(ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query vulnerabilities: %w", err)
+		}
+		for _, v := range vulns {
+			if r, ok := byID[v.AuditResultID]; ok {
+				r.Vulnerabilities = append(r.Vulnerabilities, v)
+			}
+		}
+	}
+
+	return models.NewAuditSummary(latest), nil
+}
@@ -0,0 +1,167 @@
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/jobs"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+const (
+	// notifyJobAttempts is how many times a notify job sends on the channels that failed
+	notifyJobAttempts = 5
+	// notifyRetryDelay is the delay before the first notify job attempt
+	notifyRetryDelay = time.Minute
+)
+
+// notifyJobPayload is the payload of a notify job: the app's report and the channels
+// that still have to send it
+type notifyJobPayload struct {
+	Channels []string                  `json:"channels"`
+	Report   *models.CombinedAppReport `json:"report"`
+}
+
+// RegisterJobHandlers sets the handlers of the audit and notify jobs run by worker.
+// Each job gets its own Application of a copy of cfg, which retries the notifications
+// that fail through queue.
+func RegisterJobHandlers(worker *jobs.Worker, cfg *config.Config, queue jobs.Queue) {
+	worker.Handle(models.JobTypeAudit, func(ctx context.Context, job *models.Job) error {
+		return runAuditJob(ctx, cfg, queue, job)
+	})
+	worker.Handle(models.JobTypeNotify, func(ctx context.Context, job *models.Job) error {
+		return runNotifyJob(ctx, cfg, queue, job)
+	})
+}
+
+// runAuditJob audits the job's app, or every enabled app, as `run` does
+func runAuditJob(ctx context.Context, cfg *config.Config, queue jobs.Queue, job *models.Job) error {
+	jobCfg := *cfg
+	jobCfg.TargetApp = job.AppName
+	jobCfg.RunReason = fmt.Sprintf("job %s (%s)", job.ID, job.Source)
+	jobCfg.RunSource = job.Source
+	if job.Operator != "" {
+		jobCfg.Operator = job.Operator
+	}
+
+	app, err := New(&jobCfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer app.Close()
+	app.Jobs = queue
+
+	return app.Run(ctx)
+}
+
+// runNotifyJob sends the job's report again on the channels that failed. The channels
+// that fail again are kept in the payload for the next attempt.
+func runNotifyJob(ctx context.Context, cfg *config.Config, queue jobs.Queue, job *models.Job) error {
+	var payload notifyJobPayload
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return fmt.Errorf("invalid notify job payload: %w", err)
+	}
+	if payload.Report == nil {
+		return fmt.Errorf("invalid notify job payload: no report")
+	}
+
+	jobCfg := *cfg
+	app, err := New(&jobCfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer app.Close()
+	app.Jobs = queue
+
+	appConfig, err := app.Config.GetApp(job.AppName)
+	if err != nil || appConfig == nil {
+		return fmt.Errorf("app %q not found", job.AppName)
+	}
+
+	result, err := app.NotifierManager.RetryCombined(ctx, payload.Report, appConfig.Notifications, payload.Channels)
+	app.saveNotificationTargets(ctx, *appConfig, result)
+	app.saveNotificationStatus(ctx, *appConfig, result)
+	if err != nil {
+		payload.Channels = result.Failed
+		if data, marshalErr := json.Marshal(payload); marshalErr == nil {
+			job.Payload = string(data)
+		}
+		return err
+	}
+	return nil
+}
+
+// enqueueNotifyRetry queues a notify job sending an app's report again on the channels
+// that failed, when the run has a job queue
+func (a *Application) enqueueNotifyRetry(ctx context.Context, appConfig models.AppConfig, report *models.CombinedAppReport, channels []string) {
+	if a.Jobs == nil || len(channels) == 0 {
+		return
+	}
+	log := helpers.Logger(ctx)
+
+	payload, err := json.Marshal(notifyJobPayload{Channels: channels, Report: report})
+	if err != nil {
+		log.Errorf("Failed to encode notification retry app=%s: %v", appConfig.Name, err)
+		return
+	}
+
+	job := &models.Job{
+		Type:        models.JobTypeNotify,
+		AppName:     appConfig.Name,
+		Payload:     string(payload),
+		Source:      models.JobSourceRetry,
+		Operator:    a.Config.Operator,
+		MaxAttempts: notifyJobAttempts,
+		RunAt:       time.Now().Add(notifyRetryDelay),
+	}
+	if err := a.Jobs.Enqueue(ctx, job); err != nil {
+		log.Errorf("Failed to queue notification retry app=%s channels=%v: %v", appConfig.Name, channels, err)
+		return
+	}
+	log.Infof("Queued notification retry job_id=%s app=%s channels=%v", job.ID, appConfig.Name, channels)
+}
+
+// ScheduleAudits queues an audit of every enabled app each interval until ctx is
+// cancelled, unless the previous one is still queued or running. With
+// ADAPTIVE_SCHEDULE, the audit only audits the apps that are due.
+func ScheduleAudits(ctx context.Context, queue jobs.Queue, interval time.Duration) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Scheduling audits of every app every %s", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pending, _, err := queue.List(ctx, jobs.Filter{
+			Statuses: []string{models.JobQueued, models.JobRunning},
+			Type:     models.JobTypeAudit,
+		})
+		if err != nil {
+			log.Errorf("Failed to list pending audit jobs: %v", err)
+			continue
+		}
+		if i := slices.IndexFunc(pending, func(j models.Job) bool { return j.AppName == "" }); i >= 0 {
+			log.Infof("Not scheduling an audit: job_id=%s is still %s", pending[i].ID, pending[i].Status)
+			continue
+		}
+
+		job := &models.Job{Type: models.JobTypeAudit, Source: models.JobSourceSchedule}
+		if err := queue.Enqueue(ctx, job); err != nil {
+			log.Errorf("Failed to queue scheduled audit: %v", err)
+			continue
+		}
+		log.Infof("Queued scheduled audit job_id=%s", job.ID)
+	}
+}
@@ -0,0 +1,138 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/auditor"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/policy"
+)
+
+// initPolicies loads the policies of POLICY_FILE. An invalid file fails the start
+// rather than every policy silently passing.
+func (a *Application) initPolicies() error {
+	if a.Config.Settings.PolicyFile == "" {
+		return nil
+	}
+
+	set, err := policy.Load(a.Config.Settings.PolicyFile)
+	if err != nil {
+		return err
+	}
+	a.Policies = set
+	return nil
+}
+
+// evaluateAppPolicies evaluates the policies on the results of an app's audit, and
+// records the violations for the run's exit code and summary
+func (a *Application) evaluateAppPolicies(ctx context.Context, appConfig models.AppConfig, report *models.CombinedAppReport) []models.PolicyViolation {
+	if a.Policies == nil {
+		return nil
+	}
+
+	input := policy.Input{App: appConfig, Now: time.Now()}
+	for _, r := range report.Reports {
+		input.Results = append(input.Results, *r.AuditResult)
+	}
+	for _, f := range report.Failures {
+		if !slices.Contains(input.Failed, f.AuditorType) {
+			input.Failed = append(input.Failed, f.AuditorType)
+		}
+	}
+
+	violations := a.evaluatePolicies(ctx, input)
+	for _, v := range violations {
+		helpers.Logger(ctx).Infof("Policy violated app=%s policy=%s action=%s subjects=%d", v.AppName, v.Policy, v.Action, len(v.Subjects))
+	}
+
+	a.mu.Lock()
+	a.policyViolations = append(a.policyViolations, violations...)
+	a.mu.Unlock()
+
+	return violations
+}
+
+// EvaluatePolicies evaluates the policies on the latest results of each enabled app
+// at now, or of appName only when not empty. Auditor failures are not stored, so
+// app.failed_auditors is empty.
+func (a *Application) EvaluatePolicies(ctx context.Context, appName string, now time.Time) ([]models.PolicyViolation, error) {
+	if a.Policies == nil {
+		return nil, nil
+	}
+
+	apps := make(map[string]bool)
+	for _, app := range a.Config.Apps {
+		if app.Enabled && (appName == "" || app.Name == appName) {
+			apps[app.Name] = true
+		}
+	}
+
+	results, err := a.Store.AuditResults()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit results: %w", err)
+	}
+	latest := latestResults(results, apps, now)
+
+	ids := make([]string, len(latest))
+	for i, r := range latest {
+		ids[i] = r.ID
+	}
+	vulns, err := a.Store.Vulnerabilities(ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query vulnerabilities: %w", err)
+	}
+	byResult := make(map[string][]models.Vulnerability, len(latest))
+	for _, v := range vulns {
+		byResult[v.AuditResultID] = append(byResult[v.AuditResultID], v)
+	}
+
+	var violations []models.PolicyViolation
+	for _, app := range a.Config.Apps {
+		if !apps[app.Name] {
+			continue
+		}
+		input := policy.Input{App: app, Now: now}
+		for _, r := range latest {
+			if r.AppName == app.Name {
+				r.Vulnerabilities = byResult[r.ID]
+				input.Results = append(input.Results, r)
+			}
+		}
+		violations = append(violations, a.evaluatePolicies(ctx, input)...)
+	}
+
+	return violations, nil
+}
+
+// evaluatePolicies evaluates the policies on input, reading the app's dependencies when
+// a policy needs them. Policies failing to evaluate are logged, and violated with their
+// error.
+func (a *Application) evaluatePolicies(ctx context.Context, input policy.Input) []models.PolicyViolation {
+	log := helpers.Logger(ctx)
+
+	if a.Policies.NeedsDependencies() {
+		deps, err := auditor.Dependencies(input.App)
+		if err != nil && !auditor.IsNoLockfile(err) {
+			log.Warnf("Failed to read the dependencies of app=%s for the policies: %v", input.App.Name, err)
+		}
+		input.Dependencies = deps
+	}
+
+	violations, err := a.Policies.Evaluate(input)
+	if err != nil {
+		log.Warnf("Failed to evaluate policies app=%s: %v", input.App.Name, err)
+	}
+	return violations
+}
+
+// HasPolicyFailures returns true if an app audited by the run violates a policy whose
+// action is fail
+func (a *Application) HasPolicyFailures() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.ContainsFunc(a.policyViolations, models.PolicyViolation.Failing)
+}
@@ -0,0 +1,213 @@
+package application
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+)
+
+// CheckDiff is the outcome of a check of a pull request: the findings it introduces
+// and fixes, compared with the check of its base commit
+type CheckDiff struct {
+	*CheckResult
+	Base       string         // the commit compared with
+	Introduced []CheckFinding // findings of the head the base does not have
+	Fixed      []CheckFinding // findings of the base the head does not have
+}
+
+// CheckPullRequest checks a working copy and its base commit, checked out in a
+// temporary git worktree, with the same auditors. Base findings of an auditor that
+// failed on the base are missing: the head's findings of that auditor count as
+// introduced.
+func CheckPullRequest(ctx context.Context, settings config.Settings, opts CheckOptions, base string) (*CheckDiff, error) {
+	log := helpers.Logger(ctx)
+
+	head, err := Check(ctx, settings, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := filepath.Abs(opts.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	top, err := gitOutput(ctx, path, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("not a git working copy: %w", err)
+	}
+	rel, err := filepath.Rel(top, path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	commit, err := gitOutput(ctx, top, "rev-parse", "--verify", base+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("unknown base %s (fetch it first): %w", base, err)
+	}
+
+	dir, err := os.MkdirTemp("", "audit-checks-base-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create base worktree: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	if _, err := gitOutput(ctx, top, "worktree", "add", "--detach", dir, commit); err != nil {
+		return nil, fmt.Errorf("failed to check out base %s: %w", base, err)
+	}
+	defer func() {
+		if _, err := gitOutput(context.WithoutCancel(ctx), top, "worktree", "remove", "--force", dir); err != nil {
+			log.Warnf("Failed to remove base worktree %s: %v", dir, err)
+		}
+	}()
+
+	diff := &CheckDiff{CheckResult: head, Base: commit}
+
+	var baseFindings []CheckFinding
+	if _, err := os.Stat(filepath.Join(dir, rel)); err == nil {
+		baseOpts := opts
+		baseOpts.Path = filepath.Join(dir, rel)
+		baseOpts.Type = strings.Join(head.Auditors, ",")
+		baseOpts.NoCache = true // worktree paths never repeat
+		baseResult, err := Check(ctx, settings, baseOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check base %s: %w", base, err)
+		}
+		for name, err := range baseResult.Failures {
+			log.Warnf("Auditor failed on base %s auditor=%s error=%v", base, name, err)
+		}
+		baseFindings = baseResult.Findings
+	}
+
+	baseKeys := make(map[string]bool, len(baseFindings))
+	for _, f := range baseFindings {
+		baseKeys[f.key()] = true
+	}
+	headKeys := make(map[string]bool, len(head.Findings))
+	for _, f := range head.Findings {
+		headKeys[f.key()] = true
+		if !baseKeys[f.key()] {
+			diff.Introduced = append(diff.Introduced, f)
+		}
+	}
+	for _, f := range baseFindings {
+		if !headKeys[f.key()] {
+			diff.Fixed = append(diff.Fixed, f)
+		}
+	}
+
+	return diff, nil
+}
+
+// gitOutput runs git in dir and returns its trimmed output
+func gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%w: %s", err, message)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// pullRequestMaxFindings is the number of findings listed in a pull request comment
+const pullRequestMaxFindings = 100
+
+// Comment renders the pull request comment of the check: the findings the pull request
+// introduces, those it fixes and the auditors that failed, in GitHub Flavored Markdown
+func (d *CheckDiff) Comment(threshold string) string {
+	var sb strings.Builder
+	sb.WriteString("### audit-checks\n\n")
+
+	if len(d.Introduced) == 0 {
+		fmt.Fprintf(&sb, ":white_check_mark: No new vulnerabilities at or above **%s**.\n", threshold)
+	} else {
+		fmt.Fprintf(&sb, ":x: This pull request introduces **%s** at or above **%s**.\n\n", vulnerabilityCount(len(d.Introduced)), threshold)
+		sb.WriteString("| Severity | Package | Vulnerability | Title | Patched | Auditor |\n| :-- | :-- | :-- | :-- | :-- | :-- |\n")
+		for i, f := range d.Introduced {
+			if i == pullRequestMaxFindings {
+				fmt.Fprintf(&sb, "\n_... and %d more_\n", len(d.Introduced)-i)
+				break
+			}
+			id := markdownCell(f.CVEID)
+			if f.CVEID != "" && f.URL != "" {
+				id = fmt.Sprintf("[%s](%s)", id, f.URL)
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s |\n",
+				strings.ToUpper(f.Severity), markdownCode(f.PackageName), id, markdownCell(f.Title),
+				markdownCode(f.PatchedVersions), f.Auditor)
+		}
+	}
+
+	if len(d.Fixed) > 0 {
+		var fixed []string
+		for i, f := range d.Fixed {
+			if i == pullRequestMaxFindings {
+				fixed = append(fixed, fmt.Sprintf("and %d more", len(d.Fixed)-i))
+				break
+			}
+			fixed = append(fixed, fmt.Sprintf("%s %s", markdownCode(f.PackageName), markdownCell(cmp.Or(f.CVEID, f.Title))))
+		}
+		fmt.Fprintf(&sb, "\n:tada: Fixes %s: %s\n", vulnerabilityCount(len(d.Fixed)), strings.Join(fixed, ", "))
+	}
+
+	if existing := len(d.Findings) - len(d.Introduced); existing > 0 {
+		fmt.Fprintf(&sb, "\nNot listed: %s already on the base branch.\n", vulnerabilityCount(existing))
+	}
+
+	if len(d.Failures) > 0 {
+		sb.WriteString("\n:warning: Failed auditors, whose new findings may be missing:\n\n")
+		for _, name := range slices.Sorted(maps.Keys(d.Failures)) {
+			fmt.Fprintf(&sb, "- %s: %s\n", name, markdownCell(d.Failures[name].Error()))
+		}
+	}
+
+	fmt.Fprintf(&sb, "\n<sub>Compared with base %s. Updated on every push.</sub>\n", markdownCode(shortCommit(d.Base)))
+	return sb.String()
+}
+
+// vulnerabilityCount returns n with the noun, e.g. "1 vulnerability"
+func vulnerabilityCount(n int) string {
+	if n == 1 {
+		return "1 vulnerability"
+	}
+	return fmt.Sprintf("%d vulnerabilities", n)
+}
+
+// markdownCell escapes a table cell: on one line, without the HTML, pipes and @mentions
+// GitHub would render, split or notify
+func markdownCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return "-"
+	}
+	return strings.NewReplacer("|", "\\|", "<", "&lt;", ">", "&gt;", "@", "&#64;").Replace(s)
+}
+
+// markdownCode renders s as an inline code span, where nothing is linked or mentioned
+func markdownCode(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return "-"
+	}
+	return "`" + strings.NewReplacer("`", "'", "|", "\\|").Replace(s) + "`"
+}
+
+// shortCommit abbreviates a commit hash as git does
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
+}
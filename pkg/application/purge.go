@@ -0,0 +1,101 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// PurgeAppData deletes the history of an app created before purge.Before: what the
+// store holds of it (see store.Store.PurgeAppData), its report files, and the run log
+// and raw output files no other result refers to. Its lines are removed from the run
+// logs of runs shared with other apps. With purge.DryRun, only lists what would be
+// deleted.
+func (a *Application) PurgeAppData(ctx context.Context, purge *models.DataPurge) error {
+	log := helpers.Logger(ctx)
+
+	files, err := a.Store.PurgeAppData(purge)
+	if err != nil {
+		return fmt.Errorf("failed to purge the history of %s: %w", purge.AppName, err)
+	}
+	reports, err := a.ReporterManager.AppReportFiles(purge.AppName, a.AuditorRegistry.Names(), purge.Before)
+	if err != nil {
+		return fmt.Errorf("failed to list the report files of %s: %w", purge.AppName, err)
+	}
+
+	var errs []error
+	shared := purge.RedactedFiles
+	purge.RedactedFiles = nil
+	for _, file := range shared {
+		if purge.DryRun {
+			purge.RedactedFiles = append(purge.RedactedFiles, file)
+			continue
+		}
+		if err := redactRunLog(file, purge.AppName); err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		purge.RedactedFiles = append(purge.RedactedFiles, file)
+	}
+	for _, file := range append(files, reports...) {
+		if purge.DryRun {
+			purge.Files = append(purge.Files, file)
+			continue
+		}
+		if err := os.Remove(file); err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		purge.Files = append(purge.Files, file)
+	}
+
+	prefix := ""
+	if purge.DryRun {
+		prefix = "[DRY RUN] Would purge: "
+	}
+	log.Infof("%sApp data purged app=%s before=%s results=%d vulnerabilities=%d raw_outputs=%d events=%d emails=%d "+
+		"packages=%d triages=%d activities=%d jobs=%d suppressions=%d notification_state=%t files=%d redacted_files=%d "+
+		"kept_files=%d kept_jobs=%d kept_notification_state=%t",
+		prefix, purge.AppName, purge.Before.Format(time.RFC3339), purge.AuditResults, purge.Vulnerabilities,
+		purge.RawOutputs, purge.RunEvents, purge.EmailDeliveries, purge.AppPackages, purge.Triages, purge.Activities,
+		purge.Jobs, purge.EmailSuppressions, purge.NotificationState, len(purge.Files), len(purge.RedactedFiles),
+		len(purge.KeptFiles), purge.KeptJobs, purge.KeptNotificationState)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to delete or redact %d files of %s: %w", len(errs), purge.AppName, errors.Join(errs...))
+	}
+	return nil
+}
+
+// purgeExpiredHistory deletes the history of each app older than its retention
+// (its own, or RETENTION_DAYS), keeping the latest result of each of its auditors so
+// that the next audit still tells new findings from known ones
+func (a *Application) purgeExpiredHistory(ctx context.Context) {
+	now := time.Now()
+	for _, app := range a.Config.Apps {
+		days := cmp.Or(app.RetentionDays, a.Config.Settings.RetentionDays)
+		if days <= 0 {
+			continue
+		}
+
+		purge := &models.DataPurge{
+			AppName:    app.Name,
+			Before:     now.AddDate(0, 0, -days),
+			KeepLatest: true,
+			DryRun:     a.Config.DryRun,
+		}
+		if err := a.PurgeAppData(ctx, purge); err != nil {
+			helpers.Logger(ctx).Errorf("Failed to apply the retention of %s: %v", app.Name, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// rawOutputDirectory is where raw outputs over RAW_OUTPUT_MAX_SIZE are written, inside
+// LOG_DIRECTORY
+const rawOutputDirectory = "raw"
+
+// capRawOutput cuts the raw output of result to RAW_OUTPUT_MAX_SIZE, so that giant
+// outputs do not slow down every query of the audit history. The whole output is
+// written to <LOG_DIRECTORY>/raw/<hash>.txt, referenced by RawOutputFile and removed
+// LOG_MAX_AGE days after the last audit that had it. Identical outputs share the file,
+// and their cut output is identical too, so that it is stored once.
+func (a *Application) capRawOutput(ctx context.Context, result *models.AuditResult) {
+	limit := a.Config.RawOutputMaxSize * 1024
+	if limit <= 0 || len(result.RawOutput) <= limit {
+		return
+	}
+	log := helpers.Logger(ctx)
+	size := len(result.RawOutput)
+
+	dir := filepath.Join(a.Config.LogDirectory, rawOutputDirectory)
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	path := filepath.Join(dir, models.RawOutputHash(result.RawOutput)+".txt")
+
+	// An existing file has the same output, it is kept LOG_MAX_AGE days longer
+	note := "not kept"
+	now := time.Now()
+	err := os.MkdirAll(dir, 0755)
+	if err == nil && os.Chtimes(path, now, now) != nil {
+		err = os.WriteFile(path, []byte(result.RawOutput), 0644)
+	}
+	if err != nil {
+		log.Warnf("Failed to write raw output file=%s: %v", path, err)
+	} else {
+		result.RawOutputFile = path
+		note = "in " + path
+	}
+
+	// Cut on a character boundary
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(result.RawOutput[cut]) {
+		cut--
+	}
+	result.RawOutput = result.RawOutput[:cut] +
+		fmt.Sprintf("\n[cut at %d of %d bytes (RAW_OUTPUT_MAX_SIZE), whole output %s]", cut, size, note)
+
+	log.Infof("Raw output of %d bytes cut to RAW_OUTPUT_MAX_SIZE auditor=%s file=%s", size, result.AuditorType, result.RawOutputFile)
+}
+
+// purgeRawOutputs deletes the raw output files older than LOG_MAX_AGE days
+func (a *Application) purgeRawOutputs(ctx context.Context) {
+	dir := filepath.Join(a.Config.LogDirectory, rawOutputDirectory)
+	if _, err := os.Stat(dir); err != nil {
+		return
+	}
+	a.purgeLogFiles(helpers.Logger(ctx), dir, ".txt")
+}
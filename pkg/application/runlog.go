@@ -0,0 +1,130 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// runLogDirectory is where run log files are written, inside LOG_DIRECTORY
+const runLogDirectory = "runs"
+
+// openRunLog creates the log file of the current run (RUN_LOG_ENABLED) and returns a
+// logger writing to both logger and the file, and a function closing the file.
+// The file gets every line of the run at debug level, whatever LOG_LEVEL is, so it
+// can be attached to a support request as-is.
+func (a *Application) openRunLog(logger *zap.Logger) (*zap.Logger, func()) {
+	noop := func() {}
+	if !a.Config.RunLogEnabled {
+		return logger, noop
+	}
+
+	dir := filepath.Join(a.Config.LogDirectory, runLogDirectory)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.Sugar().Warnf("Failed to create run log directory=%s: %v", dir, err)
+		return logger, noop
+	}
+	a.purgeLogFiles(logger.Sugar(), dir, ".log")
+
+	// The path is stored with the audit results, so the API can serve it from any working directory
+	path := filepath.Join(dir, a.runID+".log")
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		logger.Sugar().Warnf("Failed to create run log file=%s: %v", path, err)
+		return logger, noop
+	}
+	a.runLogFile = path
+
+	encoder := zapcore.NewConsoleEncoder(zapcore.EncoderConfig{
+		TimeKey:        "@timestamp",
+		LevelKey:       "level",
+		MessageKey:     "msg",
+		StacktraceKey:  "stacktrace",
+		EncodeLevel:    zapcore.CapitalLevelEncoder,
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeTime:     zapcore.ISO8601TimeEncoder,
+		EncodeDuration: zapcore.StringDurationEncoder,
+	})
+	core := zapcore.NewCore(encoder, zapcore.Lock(file), zapcore.DebugLevel)
+
+	return zap.New(zapcore.NewTee(logger.Core(), core)), func() {
+		if err := file.Close(); err != nil {
+			logger.Sugar().Warnf("Failed to close run log file=%s: %v", path, err)
+		}
+	}
+}
+
+// runLogEntry matches the first line of an entry of a run log, starting with its time
+var runLogEntry = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T`)
+
+// redactRunLog removes the entries of an app from a run log shared with other apps:
+// those logged with its app field, or naming it as app=<name>. The lines following
+// an entry, such as a stack trace, go with it.
+func redactRunLog(path, appName string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// The app field as the console encoder writes it
+	var name bytes.Buffer
+	encoder := json.NewEncoder(&name)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(appName); err != nil {
+		return err
+	}
+	field := append([]byte(`"app": `), bytes.TrimSpace(name.Bytes())...)
+	mention := regexp.MustCompile(`(^|\s)app=` + regexp.QuoteMeta(appName) + `(\s|$)`)
+
+	var redacted []byte
+	drop := false
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if runLogEntry.Match(line) {
+			drop = bytes.Contains(line, field) || mention.Match(line)
+		}
+		if !drop {
+			redacted = append(redacted, line...)
+		}
+	}
+	return os.WriteFile(path, redacted, info.Mode().Perm())
+}
+
+// purgeLogFiles deletes the files of dir with extension ext older than LOG_MAX_AGE days
+func (a *Application) purgeLogFiles(log *zap.SugaredLogger, dir, ext string) {
+	if a.Config.LogMaxAge <= 0 {
+		return
+	}
+	cutoff := time.Now().AddDate(0, 0, -a.Config.LogMaxAge)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		log.Debugf("Failed to read log directory=%s: %v", dir, err)
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ext) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			log.Debugf("Failed to remove log file=%s: %v", entry.Name(), err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package application
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+// dependencyFiles are the manifests and lockfiles whose modification marks an app as changed
+var dependencyFiles = []string{
+	"package-lock.json", "yarn.lock", "pnpm-lock.yaml", "composer.lock", "go.sum", "Cargo.lock", "pubspec.lock",
+	"pom.xml", "build.gradle", "build.gradle.kts", "gradle.lockfile", "packages.lock.json",
+	"*.csproj", "*.fsproj", "*.vbproj",
+	// Terraform root modules may be one or two directories down, e.g. envs/prod
+	".terraform.lock.hcl", "*/.terraform.lock.hcl", "*/*/.terraform.lock.hcl",
+}
+
+// latestAuditWindow groups the auditor results written by one run of an app
+const latestAuditWindow = time.Hour
+
+// appSchedule is the adaptive audit interval computed for an app
+type appSchedule struct {
+	LastAudit time.Time
+	Interval  time.Duration
+	Reason    string
+}
+
+// Due reports whether the app should be audited at now
+func (s appSchedule) Due(now time.Time) bool {
+	return s.LastAudit.IsZero() || !now.Before(s.LastAudit.Add(s.Interval))
+}
+
+// filterDueApps keeps the apps whose adaptive interval has elapsed since their last audit
+func (a *Application) filterDueApps(apps []models.AppConfig) []models.AppConfig {
+	now := time.Now()
+	due := make([]models.AppConfig, 0, len(apps))
+
+	for _, app := range apps {
+		schedule, err := a.scheduleFor(app)
+		if err != nil {
+			// Never skip an app because its history could not be read
+			zap.S().Warnf("Failed to compute schedule for app=%s, auditing it: %v", app.Name, err)
+			due = append(due, app)
+			continue
+		}
+
+		if schedule.Due(now) {
+			zap.S().Infof("App due app=%s interval=%s reason=%s", app.Name, schedule.Interval, schedule.Reason)
+			due = append(due, app)
+			continue
+		}
+
+		zap.S().Infof("Skipping app=%s next_audit_in=%s interval=%s reason=%s",
+			app.Name,
+			schedule.LastAudit.Add(schedule.Interval).Sub(now).Round(time.Minute),
+			schedule.Interval,
+			schedule.Reason,
+		)
+	}
+
+	return due
+}
+
+// scheduleFor computes an app's audit interval from its recent results:
+// apps with criticals, highs or changed dependencies are audited at the minimum interval,
+// and every consecutive clean audit doubles the interval of a quiet app, up to the maximum.
+func (a *Application) scheduleFor(app models.AppConfig) (appSchedule, error) {
+	settings := a.Config.Settings
+
+	results, err := a.Store.RecentAuditResults(app.Name, 50)
+	if err != nil {
+		return appSchedule{}, err
+	}
+
+	if len(results) == 0 {
+		return appSchedule{Reason: "never audited"}, nil
+	}
+
+	schedule := appSchedule{LastAudit: results[0].CreatedAt}
+
+	// Results of the latest run (one per auditor)
+	var latest []models.AuditResult
+	for _, r := range results {
+		if schedule.LastAudit.Sub(r.CreatedAt) > latestAuditWindow {
+			break
+		}
+		latest = append(latest, r)
+	}
+
+	var critical, high int
+	for _, r := range latest {
+		critical += r.CriticalCount
+		high += r.HighCount
+	}
+
+	switch {
+	case critical > 0:
+		schedule.Interval = settings.ScheduleMinInterval
+		schedule.Reason = fmt.Sprintf("%d critical", critical)
+	case high > 0:
+		schedule.Interval = 2 * settings.ScheduleMinInterval
+		schedule.Reason = fmt.Sprintf("%d high", high)
+	case dependenciesChangedSince(app.Path, schedule.LastAudit):
+		schedule.Interval = settings.ScheduleMinInterval
+		schedule.Reason = "dependencies changed"
+	default:
+		// Count consecutive clean runs, approximating a run by the latest run's auditor count
+		clean := 0
+		for _, r := range results {
+			if r.TotalVulnerabilities > 0 {
+				break
+			}
+			clean++
+		}
+		cleanRuns := clean / len(latest)
+
+		schedule.Interval = settings.ScheduleBaseInterval
+		for i := 1; i < cleanRuns && schedule.Interval < settings.ScheduleMaxInterval; i++ {
+			schedule.Interval *= 2
+		}
+		schedule.Reason = fmt.Sprintf("%d clean run(s)", cleanRuns)
+	}
+
+	schedule.Interval = min(max(schedule.Interval, settings.ScheduleMinInterval), settings.ScheduleMaxInterval)
+
+	return schedule, nil
+}
+
+// dependenciesChangedSince reports whether a manifest or lockfile in dir was modified after t
+func dependenciesChangedSince(dir string, t time.Time) bool {
+	for _, pattern := range dependencyFiles {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, path := range matches {
+			if info, err := os.Stat(path); err == nil && info.ModTime().After(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Scorecard entity identifiers
+const (
+	ScorecardAPIVersion = "audit-checks/v1"
+	ScorecardKind       = "SecurityScorecard"
+)
+
+// BuildScorecards builds the security scorecard of every configured app from the
+// latest result of each of its auditors at now. Findings are aged from their first
+// sighting, as in the executive report, to check them against SLA_DAYS.
+func (a *Application) BuildScorecards(now time.Time) ([]models.Scorecard, error) {
+	apps := make(map[string]bool, len(a.Config.Apps))
+	for _, app := range a.Config.Apps {
+		apps[app.Name] = true
+	}
+
+	results, err := a.Store.AuditResults()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit results: %w", err)
+	}
+
+	resultsByID := make(map[string]models.AuditResult, len(results))
+	lastAudit := make(map[string]time.Time)
+	for _, r := range results {
+		resultsByID[r.ID] = r
+		if apps[r.AppName] && !r.CreatedAt.After(now) {
+			lastAudit[r.AppName] = r.CreatedAt
+		}
+	}
+
+	current := latestResults(results, apps, now)
+	findings, err := a.openFindings(current, resultsByID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	currentByApp := make(map[string][]models.AuditResult)
+	for _, r := range current {
+		currentByApp[r.AppName] = append(currentByApp[r.AppName], r)
+	}
+	findingsByApp := make(map[string][]openFinding)
+	for _, f := range findings {
+		findingsByApp[f.AppName] = append(findingsByApp[f.AppName], f)
+	}
+
+	scorecards := make([]models.Scorecard, 0, len(a.Config.Apps))
+	for _, app := range a.Config.Apps {
+		spec := models.ScorecardSpec{
+			GeneratedAt: now.UTC(),
+			Status:      models.ScorecardStatusNotAudited,
+			Enabled:     app.Enabled,
+			Owners:      app.Notifications.Email,
+			SLA:         models.ScorecardSLA{Status: models.ScorecardSLAMet},
+		}
+
+		if last, ok := lastAudit[app.Name]; ok {
+			last = last.UTC()
+			spec.LastAuditAt = &last
+			spec.Status = models.ScorecardStatusOK
+		}
+
+		for _, r := range currentByApp[app.Name] {
+			addToSummary(&spec.Vulnerabilities, r)
+			spec.Auditors = append(spec.Auditors, r.AuditorType)
+		}
+		slices.Sort(spec.Auditors)
+		if spec.Vulnerabilities.Total > 0 {
+			spec.Status = models.ScorecardStatusVulnerable
+		}
+
+		appFindings := findingsByApp[app.Name]
+		spec.SLA.BySeverity = a.slaCompliance(appFindings)
+		for _, c := range spec.SLA.BySeverity {
+			spec.SLA.Overdue += c.Overdue
+		}
+		for _, f := range appFindings {
+			spec.SLA.OldestOpenDays = max(spec.SLA.OldestOpenDays, f.AgeDays)
+		}
+		if spec.SLA.Overdue > 0 {
+			spec.SLA.Status = models.ScorecardSLABreached
+		}
+
+		scorecards = append(scorecards, models.Scorecard{
+			APIVersion: ScorecardAPIVersion,
+			Kind:       ScorecardKind,
+			Metadata:   models.ScorecardMetadata{Name: app.Name},
+			Spec:       spec,
+		})
+	}
+
+	return scorecards, nil
+}
+
+// GenerateScorecards writes the scorecard of every app in each of formats (yaml,
+// json) under the scorecards directory. Returns the written file paths.
+func (a *Application) GenerateScorecards(ctx context.Context, formats []string) ([]string, error) {
+	scorecards, err := a.BuildScorecards(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := a.ReporterManager.SaveScorecards(scorecards, formats)
+	if err != nil {
+		return files, err
+	}
+
+	helpers.Logger(ctx).Infof("Scorecards generated apps=%d files=%d", len(scorecards), len(files))
+
+	return files, nil
+}
+
+// maybeGenerateScorecards refreshes the scorecards at the end of a run, so the
+// service catalog reflects the latest audits
+func (a *Application) maybeGenerateScorecards(ctx context.Context) {
+	if !a.Config.Settings.ScorecardsEnabled {
+		return
+	}
+
+	if _, err := a.GenerateScorecards(ctx, a.Config.Settings.ScorecardFormats); err != nil {
+		helpers.Logger(ctx).Errorf("Failed to generate scorecards: %v", err)
+	}
+}
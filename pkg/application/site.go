@@ -0,0 +1,139 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// BuildSite builds the site of the audit history of every configured app at now: the
+// open findings of each app and of the fleet at the end of each day with audits, and
+// the findings of the latest audits, aged as in the executive report
+func (a *Application) BuildSite(now time.Time) (*models.Site, error) {
+	apps := make(map[string]bool, len(a.Config.Apps))
+	for _, app := range a.Config.Apps {
+		apps[app.Name] = true
+	}
+
+	results, err := a.Store.AuditResults()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit results: %w", err)
+	}
+
+	site := &models.Site{GeneratedAt: now}
+	pages := make(map[string]*models.SiteApp, len(a.Config.Apps))
+	for _, app := range a.Config.Apps {
+		site.Apps = append(site.Apps, models.SiteApp{Name: app.Name, Owner: app.Owner, Enabled: app.Enabled})
+	}
+	slices.SortFunc(site.Apps, func(x, y models.SiteApp) int { return cmp.Compare(x.Name, y.Name) })
+	for i := range site.Apps {
+		pages[site.Apps[i].Name] = &site.Apps[i]
+	}
+
+	// Replay the history: each result replaces the previous one of its app and auditor
+	resultsByID := make(map[string]models.AuditResult, len(results))
+	latest := make(map[[2]string]models.AuditResult)
+	for _, r := range results {
+		resultsByID[r.ID] = r
+		page, ok := pages[r.AppName]
+		if !ok || r.CreatedAt.After(now) {
+			continue
+		}
+
+		key := [2]string{r.AppName, r.AuditorType}
+		if previous, ok := latest[key]; ok {
+			subtractFromSummary(&page.Current, previous)
+			subtractFromSummary(&site.Current, previous)
+		}
+		latest[key] = r
+		addToSummary(&page.Current, r)
+		addToSummary(&site.Current, r)
+
+		createdAt := r.CreatedAt
+		page.LastAuditAt = &createdAt
+		page.Audits++
+
+		day := startOfDay(r.CreatedAt)
+		page.Trend = setTrendPoint(page.Trend, day, page.Current)
+		site.Trend = setTrendPoint(site.Trend, day, site.Current)
+	}
+
+	current := latestResults(results, apps, now)
+	for _, r := range current {
+		page := pages[r.AppName]
+		page.Auditors = append(page.Auditors, r.AuditorType)
+	}
+
+	findings, err := a.openFindings(current, resultsByID, now)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range findings {
+		page := pages[f.AppName]
+		page.Findings = append(page.Findings, models.SiteFinding{OpenFinding: f.OpenFinding, AuditorType: f.AuditorType, URL: f.URL})
+	}
+
+	for i := range site.Apps {
+		page := &site.Apps[i]
+		slices.Sort(page.Auditors)
+		slices.SortFunc(page.Findings, func(x, y models.SiteFinding) int {
+			return cmp.Or(
+				models.SeverityOrder[y.Severity]-models.SeverityOrder[x.Severity],
+				y.AgeDays-x.AgeDays,
+				cmp.Compare(x.PackageName, y.PackageName),
+				cmp.Compare(x.CVEID, y.CVEID),
+				cmp.Compare(x.Title, y.Title),
+			)
+		})
+	}
+
+	return site, nil
+}
+
+// GenerateSite writes the site of the audit history to dir, or the site directory of
+// the report output directory when empty. Returns the written file paths.
+func (a *Application) GenerateSite(ctx context.Context, dir string) ([]string, error) {
+	site, err := a.BuildSite(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := a.ReporterManager.SaveSite(site, dir)
+	if err != nil {
+		return files, err
+	}
+
+	helpers.Logger(ctx).Infof("Site generated apps=%d files=%d", len(site.Apps), len(files))
+
+	return files, nil
+}
+
+// subtractFromSummary removes an audit result's counts from a summary
+func subtractFromSummary(s *models.Summary, r models.AuditResult) {
+	s.Total -= r.TotalVulnerabilities
+	s.Critical -= r.CriticalCount
+	s.High -= r.HighCount
+	s.Moderate -= r.ModerateCount
+	s.Low -= r.LowCount
+	s.Info -= r.InfoCount
+}
+
+// setTrendPoint sets the counts of day, the last day of trend or the one after it
+func setTrendPoint(trend []models.TrendPoint, day time.Time, summary models.Summary) []models.TrendPoint {
+	if n := len(trend); n > 0 && trend[n-1].Day.Equal(day) {
+		trend[n-1].Summary = summary
+		return trend
+	}
+	return append(trend, models.TrendPoint{Day: day, Summary: summary})
+}
+
+// startOfDay returns the local midnight starting the day of t
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
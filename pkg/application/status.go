@@ -0,0 +1,128 @@
+package application
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// BuildStatusPage builds the status page of the enabled apps at now from the latest
+// result of each of their auditors: red with critical or high findings, yellow with
+// moderate or low findings or without an audit in the last StatusPageStaleDays, green
+// otherwise. With POLICY_FILE, the policies set the colour instead: red when a policy
+// that fails is violated, yellow when one that warns is. Only the colour and last audit
+// date of each app are kept.
+func (a *Application) BuildStatusPage(ctx context.Context, now time.Time) (*models.StatusPage, error) {
+	apps := make(map[string]bool, len(a.Config.Apps))
+	for _, app := range a.Config.Apps {
+		if app.Enabled {
+			apps[app.Name] = true
+		}
+	}
+
+	results, err := a.Store.AuditResults()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit results: %w", err)
+	}
+
+	summaries := make(map[string]*models.Summary, len(apps))
+	lastAudit := make(map[string]time.Time, len(apps))
+	for _, r := range latestResults(results, apps, now) {
+		if summaries[r.AppName] == nil {
+			summaries[r.AppName] = &models.Summary{}
+		}
+		addToSummary(summaries[r.AppName], r)
+		if r.CreatedAt.After(lastAudit[r.AppName]) {
+			lastAudit[r.AppName] = r.CreatedAt
+		}
+	}
+
+	violations, err := a.EvaluatePolicies(ctx, "", now)
+	if err != nil {
+		return nil, err
+	}
+	violated := make(map[string]string, len(violations)) // app -> most severe action
+	for _, v := range violations {
+		if violated[v.AppName] != models.PolicyActionFail {
+			violated[v.AppName] = v.Action
+		}
+	}
+
+	page := &models.StatusPage{
+		Title:       a.Config.Settings.StatusPageTitle,
+		GeneratedAt: now,
+		Apps:        []models.StatusPageApp{},
+		Counts:      map[string]int{models.StatusGreen: 0, models.StatusYellow: 0, models.StatusRed: 0},
+	}
+	staleAfter := time.Duration(a.Config.Settings.StatusPageStaleDays) * 24 * time.Hour
+	for name := range apps {
+		status := models.StatusPageApp{Name: name, Status: models.StatusGreen}
+		if last, ok := lastAudit[name]; ok {
+			status.LastAuditAt = &last
+			status.Stale = staleAfter > 0 && now.Sub(last) > staleAfter
+		} else {
+			status.Stale = true
+		}
+
+		summary := cmp.Or(summaries[name], &models.Summary{})
+		switch {
+		case a.Policies != nil:
+			if violated[name] == models.PolicyActionFail {
+				status.Status = models.StatusRed
+			} else if violated[name] == models.PolicyActionWarn || status.Stale {
+				status.Status = models.StatusYellow
+			}
+		case summary.Critical > 0 || summary.High > 0:
+			status.Status = models.StatusRed
+		case summary.Moderate > 0 || summary.Low > 0 || status.Stale:
+			status.Status = models.StatusYellow
+		}
+
+		page.Apps = append(page.Apps, status)
+		page.Counts[status.Status]++
+	}
+
+	// Worst first, so the apps needing attention are at the top of the wallboard
+	rank := map[string]int{models.StatusRed: 0, models.StatusYellow: 1, models.StatusGreen: 2}
+	slices.SortFunc(page.Apps, func(x, y models.StatusPageApp) int {
+		return cmp.Or(cmp.Compare(rank[x.Status], rank[y.Status]), cmp.Compare(x.Name, y.Name))
+	})
+
+	return page, nil
+}
+
+// GenerateStatusPage writes the status page to dir, or to StatusPageDir or the status
+// directory of the report output directory when empty. Returns the written file paths.
+func (a *Application) GenerateStatusPage(ctx context.Context, dir string) ([]string, error) {
+	page, err := a.BuildStatusPage(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := a.ReporterManager.SaveStatusPage(page, cmp.Or(dir, a.Config.Settings.StatusPageDir))
+	if err != nil {
+		return files, err
+	}
+
+	helpers.Logger(ctx).Infof("Status page generated apps=%d red=%d yellow=%d green=%d",
+		len(page.Apps), page.Counts[models.StatusRed], page.Counts[models.StatusYellow], page.Counts[models.StatusGreen])
+
+	return files, nil
+}
+
+// maybeGenerateStatusPage refreshes the status page at the end of a run, so the
+// wallboard reflects the latest audits
+func (a *Application) maybeGenerateStatusPage(ctx context.Context) {
+	if !a.Config.Settings.StatusPageEnabled {
+		return
+	}
+
+	if _, err := a.GenerateStatusPage(ctx, ""); err != nil {
+		helpers.Logger(ctx).Errorf("Failed to generate status page: %v", err)
+	}
+}
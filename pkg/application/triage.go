@@ -0,0 +1,23 @@
+package application
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// NotifyTriage posts the summary of a triage session to the app's Telegram topic,
+// keeping the topic it was sent to
+func (a *Application) NotifyTriage(ctx context.Context, summary *models.TriageSummary) error {
+	appConfig, err := a.Config.GetApp(summary.AppName)
+	if err != nil || appConfig == nil {
+		return fmt.Errorf("app not found: %s", summary.AppName)
+	}
+
+	notifyResult, err := a.NotifierManager.NotifyTriage(ctx, summary, appConfig.Notifications)
+	if !a.Config.DryRun {
+		a.saveNotificationTargets(ctx, *appConfig, notifyResult)
+	}
+	return err
+}
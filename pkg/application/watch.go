@@ -0,0 +1,214 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/auditor"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/store"
+)
+
+// advisoryWatchSettingPrefix prefixes the settings holding, per ecosystem, the
+// modification time of the newest advisory the watcher has checked
+const advisoryWatchSettingPrefix = "advisory_watch_since_"
+
+// AdvisoryWatcher polls the OSV.dev advisory feed and re-audits the apps whose
+// recorded packages a new or modified advisory affects, instead of waiting for
+// the next scheduled run. Packages are recorded from the apps' lockfiles after
+// every audit; apps that were never audited are not watched.
+type AdvisoryWatcher struct {
+	cfg   *config.Config
+	store store.Store
+	feed  *auditor.OSVAuditor
+}
+
+// NewAdvisoryWatcher creates a new AdvisoryWatcher reading recorded packages and
+// its position in the feed from st
+func NewAdvisoryWatcher(cfg *config.Config, st store.Store) *AdvisoryWatcher {
+	return &AdvisoryWatcher{
+		cfg:   cfg,
+		store: st,
+		feed:  auditor.NewOSVAuditor(cfg.Settings.OSVCacheDir, cfg.Settings.OSVCacheTTL),
+	}
+}
+
+// Watch polls the feed every interval until ctx is cancelled. Poll errors are
+// logged and retried at the next interval. The notifier drill runs between polls
+// when due (NOTIFIER_DRILL_ENABLED).
+func (w *AdvisoryWatcher) Watch(ctx context.Context, interval time.Duration) error {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Watching the OSV.dev advisory feed every %s ecosystems=%v", interval, auditor.WatchedEcosystems)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.Poll(ctx); err != nil && ctx.Err() == nil {
+			log.Errorf("Advisory watch poll failed: %v", err)
+		}
+		w.maybeDrillNotifiers(ctx)
+
+		select {
+		case <-ctx.Done():
+			log.Info("Advisory watch stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll checks the feed once and re-audits the affected apps. Returns the names of the
+// apps re-audited. The first poll of an ecosystem only records the feed's position.
+func (w *AdvisoryWatcher) Poll(ctx context.Context) ([]string, error) {
+	log := helpers.Logger(ctx)
+
+	matches := make(map[string][]string) // app name -> advisory IDs
+	positions := make(map[string]time.Time)
+	var errs []error
+
+	for _, ecosystem := range auditor.WatchedEcosystems {
+		key := advisoryWatchSettingPrefix + ecosystem
+
+		since := time.Now().UTC()
+		value, started, err := w.store.Setting(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		if started {
+			if since, err = time.Parse(time.RFC3339, value); err != nil {
+				log.Warnf("Invalid advisory watch position %s=%q; starting from now", key, value)
+				since, started = time.Now().UTC(), false
+			}
+		}
+
+		advisories, latest, err := w.feed.FeedAdvisories(ctx, ecosystem, since)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", ecosystem, err))
+			continue
+		}
+		positions[key] = latest
+
+		if !started {
+			log.Infof("Advisory watch started for %s; advisories modified after %s are checked",
+				ecosystem, latest.Format(time.RFC3339))
+			continue
+		}
+
+		for _, advisory := range advisories {
+			names := advisory.PackageNames(ecosystem)
+			if len(names) == 0 {
+				continue
+			}
+			packages, err := w.store.AppPackages(ecosystem, names)
+			if err != nil {
+				return nil, fmt.Errorf("failed to look up packages: %w", err)
+			}
+			for _, pkg := range packages {
+				if !advisory.Affects(pkg) || slices.Contains(matches[pkg.AppName], advisory.ID) {
+					continue
+				}
+				log.Warnf("Advisory %s affects app=%s package=%s@%s lockfile=%s: %s",
+					advisory.ID, pkg.AppName, pkg.Name, pkg.Version, pkg.Lockfile, advisory.Summary)
+				matches[pkg.AppName] = append(matches[pkg.AppName], advisory.ID)
+			}
+		}
+	}
+
+	reaudited, err := w.reauditApps(ctx, matches)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	// The position only moves once the affected apps were re-audited, so a watcher
+	// stopped in between checks the same advisories again
+	for key, latest := range positions {
+		if err := w.store.SaveSetting(key, latest.UTC().Format(time.RFC3339)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to save %s: %w", key, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return reaudited, fmt.Errorf("advisory watch errors: %v", errs)
+	}
+	return reaudited, nil
+}
+
+// reauditApps runs a targeted audit of each enabled, unpaused app in matches, which
+// notifies the app's recipients like a scheduled run
+func (w *AdvisoryWatcher) reauditApps(ctx context.Context, matches map[string][]string) ([]string, error) {
+	log := helpers.Logger(ctx)
+
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	apps, err := w.store.Apps()
+	if err != nil {
+		return nil, fmt.Errorf("failed to query apps: %w", err)
+	}
+
+	var reaudited []string
+	var errs []error
+	now := time.Now()
+	for _, app := range apps {
+		advisoryIDs, ok := matches[app.Name]
+		if !ok {
+			continue
+		}
+		if !app.Enabled || app.IsPaused(now) {
+			log.Infof("Skipping re-audit of disabled or paused app=%s advisories=%v", app.Name, advisoryIDs)
+			continue
+		}
+
+		log.Infof("Re-auditing app=%s for advisories=%v", app.Name, advisoryIDs)
+		if err := w.reaudit(ctx, app.Name, advisoryIDs); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", app.Name, err))
+		}
+		reaudited = append(reaudited, app.Name)
+	}
+
+	if len(errs) > 0 {
+		return reaudited, fmt.Errorf("re-audits failed: %v", errs)
+	}
+	return reaudited, nil
+}
+
+// reaudit runs an audit of one app, as `run --app <name>` does
+func (w *AdvisoryWatcher) reaudit(ctx context.Context, appName string, advisoryIDs []string) error {
+	cfg := *w.cfg
+	cfg.TargetApp = appName
+	cfg.RunReason = "advisories " + strings.Join(advisoryIDs, ",")
+
+	app, err := New(&cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer app.Close()
+
+	return app.Run(ctx)
+}
+
+// saveInstalledPackages records the packages of the app's lockfiles for the advisory watcher
+func (a *Application) saveInstalledPackages(ctx context.Context, appConfig models.AppConfig) {
+	log := helpers.Logger(ctx)
+
+	packages, err := auditor.InstalledPackages(appConfig)
+	if err != nil {
+		log.Warnf("Failed to read installed packages for the advisory watcher app=%s error=%v", appConfig.Name, err)
+		return
+	}
+
+	if err := a.Store.SaveAppPackages(appConfig.Name, packages); err != nil {
+		log.Warnf("Failed to save installed packages app=%s error=%v", appConfig.Name, err)
+		return
+	}
+
+	log.Debugf("Recorded %d installed packages for app=%s", len(packages), appConfig.Name)
+}
@@ -0,0 +1,112 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Audit workspace modes
+const (
+	WorkspaceOff  = "off"
+	WorkspaceCopy = "copy"
+	WorkspaceBind = "bind"
+)
+
+// prepareWorkspace returns the directory the auditors of an app run in and a cleanup
+// function. With AUDIT_WORKSPACE=copy the app is copied to a private temp directory,
+// with bind it is bind-mounted read-only there (Linux, requires root), so audit
+// commands cannot modify the app. Every call gets its own directory, so concurrent
+// audits of apps sharing a path do not interfere.
+func (a *Application) prepareWorkspace(ctx context.Context, app models.AppConfig) (string, func(), error) {
+	log := helpers.Logger(ctx)
+	settings := a.Config.Settings
+	noop := func() {}
+
+	mode := settings.AuditWorkspace
+	if mode == "" || mode == WorkspaceOff {
+		return app.Path, noop, nil
+	}
+	if mode != WorkspaceCopy && mode != WorkspaceBind {
+		return "", noop, fmt.Errorf("unknown audit workspace mode %q (use %s, %s or %s)",
+			mode, WorkspaceOff, WorkspaceCopy, WorkspaceBind)
+	}
+
+	if settings.AuditWorkspaceDir != "" {
+		if err := os.MkdirAll(settings.AuditWorkspaceDir, 0755); err != nil {
+			return "", noop, fmt.Errorf("failed to create workspace directory: %w", err)
+		}
+	}
+
+	dir, err := os.MkdirTemp(settings.AuditWorkspaceDir, "audit-checks-"+workspaceName(app.Name)+"-")
+	if err != nil {
+		return "", noop, fmt.Errorf("failed to create workspace: %w", err)
+	}
+
+	if mode == WorkspaceCopy {
+		if err := helpers.CopyDir(app.Path, dir, settings.AuditWorkspaceExclude); err != nil {
+			os.RemoveAll(dir)
+			return "", noop, fmt.Errorf("failed to copy app to workspace: %w", err)
+		}
+
+		log.Debugf("Workspace copied app=%s path=%s workspace=%s", app.Name, app.Path, dir)
+		return dir, func() {
+			if err := os.RemoveAll(dir); err != nil {
+				log.Warnf("Failed to remove workspace=%s: %v", dir, err)
+			}
+		}, nil
+	}
+
+	// A bind mount can only be made read-only by remounting it
+	if err := runMount("mount", "--bind", app.Path, dir); err != nil {
+		os.Remove(dir)
+		return "", noop, err
+	}
+	unmount := func() {
+		if err := runMount("umount", dir); err != nil {
+			log.Warnf("Failed to unmount workspace=%s: %v", dir, err)
+			return
+		}
+		os.Remove(dir)
+	}
+	if err := runMount("mount", "-o", "remount,bind,ro", dir); err != nil {
+		unmount()
+		return "", noop, err
+	}
+
+	log.Debugf("Workspace mounted read-only app=%s path=%s workspace=%s", app.Name, app.Path, dir)
+	return dir, unmount, nil
+}
+
+// runMount runs mount or umount, returning its error output on failure
+func runMount(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" {
+			errMsg = err.Error()
+		}
+		return fmt.Errorf("%s %s failed: %s", name, strings.Join(args, " "), errMsg)
+	}
+	return nil
+}
+
+// workspaceName makes an app name safe to use in a directory name
+func workspaceName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator || r == ' ' {
+			return '_'
+		}
+		return r
+	}, name)
+}
@@ -1,12 +1,19 @@
 package auditor
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 
+	"github.com/shadowbane/audit-checks/pkg/helpers"
 	"github.com/shadowbane/audit-checks/pkg/models"
 )
 
@@ -22,6 +29,9 @@ type Auditor interface {
 	Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error)
 }
 
+// Types lists the auditor names that can be used as an app type
+var Types = []string{"npm", "pnpm", "composer", "go", "cargo", "java", "dotnet", "pub", "helm", "terraform", "system", "osv", "policy", "outdated", "supplychain", "secrets", "php", "node"}
+
 // Registry manages available auditors
 type Registry struct {
 	auditors map[string]Auditor
@@ -182,6 +192,26 @@ func FileExists(path string) bool {
 	return err == nil
 }
 
+// ToolVersion runs the given binary with args (e.g., "npm --version") and
+// returns the first version number found in its output. Auditors record it in
+// their results, so results can be compared across hosts.
+// Returns an empty string if the version could not be determined.
+func ToolVersion(ctx context.Context, binary string, args ...string) string {
+	log := helpers.Logger(ctx)
+
+	cmd := exec.CommandContext(ctx, binary, args...)
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		log.Debugf("Failed to determine %s version: %v", binary, err)
+		return ""
+	}
+
+	return helpers.ExtractVersion(strings.TrimSpace(stdout.String()))
+}
+
 // JoinPath joins path components
 func JoinPath(base string, parts ...string) string {
 	return filepath.Join(append([]string{base}, parts...)...)
@@ -222,3 +252,133 @@ func FilterIgnored(vulns []models.Vulnerability, ignoreList []string) []models.V
 	}
 	return filtered
 }
+
+// IsIgnoredPath checks if a path relative to the app root falls under one of the
+// ignore path patterns. A pattern matches the path itself or any of its parent
+// directories and may contain glob characters (e.g. "examples", "packages/*/demo").
+func IsIgnoredPath(relPath string, patterns []string) bool {
+	relPath = strings.Trim(filepath.ToSlash(filepath.Clean(relPath)), "/")
+	if relPath == "" || relPath == "." {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		pattern = strings.Trim(filepath.ToSlash(filepath.Clean(pattern)), "/")
+		if pattern == "" || pattern == "." {
+			continue
+		}
+		for dir := relPath; dir != "." && dir != ""; dir = path.Dir(dir) {
+			if ok, _ := path.Match(pattern, dir); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IgnoredPaths checks if every path a finding originates from is ignored by the app.
+// Findings without paths are kept, as are findings also reached from a path that is not ignored.
+func IgnoredPaths(app models.AppConfig, paths ...string) bool {
+	if len(app.IgnorePaths) == 0 || len(paths) == 0 {
+		return false
+	}
+
+	for _, p := range paths {
+		if !IsIgnoredPath(appRelativePath(app.Path, p), app.IgnorePaths) {
+			return false
+		}
+	}
+	return true
+}
+
+// appRelativePath makes a path reported by a tool relative to the app root.
+// Relative paths are assumed to already be relative to the app root.
+func appRelativePath(appPath, p string) string {
+	if !filepath.IsAbs(p) {
+		return p
+	}
+	if abs, err := filepath.Abs(appPath); err == nil {
+		if rel, err := filepath.Rel(abs, p); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return p
+}
+
+// OptionKeys lists the tool options each auditor accepts, globally (e.g. NPM_AUDIT_LEVEL)
+// or per app as "<auditor>.<key>=<value>" (e.g. npm.audit-level=high)
+var OptionKeys = map[string][]string{
+	"npm":      {"audit-level", "omit", "registry", "before", "signatures"},
+	"composer": {"locked", "abandoned", "ignore-severity", "abandoned-severity"},
+	"php":      {"binary"},
+	"node":     {"binary"},
+}
+
+// ParseAppOptions parses per-app options ("npm.before=2024-06-01") into a map of
+// auditor name to options, rejecting unknown auditors and keys
+func ParseAppOptions(entries []string) (map[string]map[string]string, error) {
+	options := make(map[string]map[string]string)
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		name, option, dotted := strings.Cut(strings.TrimSpace(key), ".")
+		if !ok || !dotted || name == "" || option == "" {
+			return nil, fmt.Errorf("invalid audit option %q (use <auditor>.<key>=<value>, e.g. npm.audit-level=high)", entry)
+		}
+
+		keys, known := OptionKeys[name]
+		if !known {
+			return nil, fmt.Errorf("invalid audit option %q: auditor %s has no options", entry, name)
+		}
+		if !slices.Contains(keys, option) {
+			return nil, fmt.Errorf("invalid audit option %q: %s accepts %s", entry, name, strings.Join(keys, ", "))
+		}
+
+		if options[name] == nil {
+			options[name] = make(map[string]string)
+		}
+		options[name][option] = strings.TrimSpace(value)
+	}
+	return options, nil
+}
+
+// BoolOption parses a true/false option; an unset option is false
+func BoolOption(options map[string]string, key string) (bool, error) {
+	value := options[key]
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q (use true or false)", key, value)
+	}
+	return b, nil
+}
+
+// AppOptions returns the options an auditor runs with for an app: the global
+// defaults, overridden by the app's own options. An empty per-app value clears the default.
+func AppOptions(name string, defaults map[string]string, app models.AppConfig) (map[string]string, error) {
+	appOptions, err := ParseAppOptions(app.AuditOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make(map[string]string, len(defaults))
+	for k, v := range defaults {
+		if v != "" {
+			options[k] = v
+		}
+	}
+	for k, v := range appOptions[name] {
+		if v == "" {
+			delete(options, k)
+		} else {
+			options[k] = v
+		}
+	}
+	return options, nil
+}
@@ -0,0 +1,274 @@
+package auditor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+// CargoAuditor implements the Auditor interface for Rust projects using cargo-audit
+type CargoAuditor struct{}
+
+// NewCargoAuditor creates a new CargoAuditor
+func NewCargoAuditor() *CargoAuditor {
+	return &CargoAuditor{}
+}
+
+// Name returns "cargo"
+func (a *CargoAuditor) Name() string {
+	return "cargo"
+}
+
+// Detect checks for Cargo.lock
+func (a *CargoAuditor) Detect(path string) bool {
+	return FileExists(JoinPath(path, "Cargo.lock"))
+}
+
+// Audit runs cargo audit and parses the results
+func (a *CargoAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running cargo audit for app=%s path=%s", app.Name, app.Path)
+
+	// Check if cargo is available (cargo-audit is a cargo subcommand)
+	if _, err := exec.LookPath("cargo"); err != nil {
+		return nil, fmt.Errorf("cargo not found in PATH: %w", err)
+	}
+
+	// Getting the version also verifies that cargo-audit is installed
+	toolVersion := ToolVersion(ctx, "cargo", "audit", "--version")
+	if toolVersion == "" {
+		return nil, fmt.Errorf("cargo-audit not installed (install with 'cargo install cargo-audit --locked')")
+	}
+
+	// Check if Cargo.lock exists (cargo audit only reads the lockfile)
+	if !FileExists(JoinPath(app.Path, "Cargo.lock")) {
+		return nil, fmt.Errorf("Cargo.lock not found in %s (run 'cargo generate-lockfile')", app.Path)
+	}
+
+	// Run cargo audit
+	cmd := exec.CommandContext(ctx, "cargo", "audit", "--json")
+	cmd.Dir = app.Path
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// cargo audit returns exit code 1 when vulnerabilities are found,
+	// so a non-zero exit code is only an error if no report was produced
+	runErr := cmd.Run()
+	if runErr != nil {
+		if _, ok := runErr.(*exec.ExitError); !ok {
+			return nil, fmt.Errorf("failed to run cargo audit: %w", runErr)
+		}
+	}
+
+	output := stdout.String()
+	if strings.TrimSpace(output) == "" {
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" {
+			errMsg = "no output"
+		}
+		return nil, fmt.Errorf("cargo audit failed: %s", errMsg)
+	}
+
+	result, err := a.parseOutput(output, app)
+	if err != nil {
+		log.Debugf("cargo audit raw output: %s", output)
+		return nil, fmt.Errorf("failed to parse cargo audit output: %w", err)
+	}
+
+	result.RawOutput = output
+	result.AuditorType = a.Name()
+	result.ToolVersion = toolVersion
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("cargo audit completed for app=%s total=%d critical=%d high=%d",
+		app.Name,
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// cargoAuditOutput represents the cargo audit JSON output structure
+type cargoAuditOutput struct {
+	Vulnerabilities struct {
+		Found bool                 `json:"found"`
+		Count int                  `json:"count"`
+		List  []cargoVulnerability `json:"list"`
+	} `json:"vulnerabilities"`
+	Warnings map[string][]cargoWarning `json:"warnings"`
+}
+
+type cargoVulnerability struct {
+	Advisory cargoAdvisory `json:"advisory"`
+	Versions struct {
+		Patched    []string `json:"patched"`
+		Unaffected []string `json:"unaffected"`
+	} `json:"versions"`
+	Package cargoPackage `json:"package"`
+}
+
+// cargoWarning is an informational finding (unmaintained, unsound, yanked)
+type cargoWarning struct {
+	Kind     string         `json:"kind"`
+	Package  cargoPackage   `json:"package"`
+	Advisory *cargoAdvisory `json:"advisory"`
+	Versions *struct {
+		Patched []string `json:"patched"`
+	} `json:"versions"`
+}
+
+type cargoAdvisory struct {
+	ID            string   `json:"id"`
+	Package       string   `json:"package"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description"`
+	Date          string   `json:"date"`
+	Aliases       []string `json:"aliases"`
+	CVSS          string   `json:"cvss"`
+	Informational string   `json:"informational"`
+	URL           string   `json:"url"`
+}
+
+type cargoPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// parseOutput parses cargo audit JSON output
+func (a *CargoAuditor) parseOutput(output string, app models.AppConfig) (*models.AuditResult, error) {
+	var auditOutput cargoAuditOutput
+	if err := json.Unmarshal([]byte(output), &auditOutput); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+
+	for _, vuln := range auditOutput.Vulnerabilities.List {
+		result.Vulnerabilities = append(result.Vulnerabilities,
+			cargoToVulnerability(vuln.Advisory, vuln.Package, vuln.Versions.Patched, cargoSeverity(vuln.Advisory)))
+	}
+
+	// Warnings with an advisory (unmaintained, unsound) are RUSTSEC advisories too,
+	// but not vulnerabilities; they are kept as info so the severity threshold hides them by default
+	kinds := make([]string, 0, len(auditOutput.Warnings))
+	for kind := range auditOutput.Warnings {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	for _, kind := range kinds {
+		for _, w := range auditOutput.Warnings[kind] {
+			if w.Advisory == nil {
+				continue
+			}
+			var patched []string
+			if w.Versions != nil {
+				patched = w.Versions.Patched
+			}
+			vuln := cargoToVulnerability(*w.Advisory, w.Package, patched, models.SeverityInfo)
+			vuln.Title = fmt.Sprintf("[%s] %s", kind, vuln.Title)
+			result.Vulnerabilities = append(result.Vulnerabilities, vuln)
+		}
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	return result, nil
+}
+
+// cargoToVulnerability converts a RUSTSEC advisory to a Vulnerability
+func cargoToVulnerability(adv cargoAdvisory, pkg cargoPackage, patched []string, severity string) models.Vulnerability {
+	cveID := adv.ID
+	for _, alias := range adv.Aliases {
+		if strings.HasPrefix(alias, "CVE-") {
+			cveID = alias
+			break
+		}
+	}
+
+	url := adv.URL
+	if url == "" {
+		url = "https://rustsec.org/advisories/" + adv.ID
+	}
+
+	description := fmt.Sprintf("Advisory: %s.", adv.ID)
+	if adv.Description != "" {
+		description = fmt.Sprintf("%s %s", description, strings.TrimSpace(adv.Description))
+	}
+
+	patchedVersions := strings.Join(patched, ", ")
+
+	return models.Vulnerability{
+		PackageName:        pkg.Name,
+		Severity:           severity,
+		CVEID:              cveID,
+		Title:              adv.Title,
+		Description:        description,
+		Recommendation:     buildCargoRecommendation(pkg, patchedVersions),
+		VulnerableVersions: pkg.Version,
+		PatchedVersions:    patchedVersions,
+		URL:                url,
+	}
+}
+
+// cargoSeverity derives a severity from the advisory's CVSS v3 vector.
+// Many RUSTSEC advisories carry no CVSS score; those are treated as moderate.
+func cargoSeverity(adv cargoAdvisory) string {
+	if adv.CVSS == "" {
+		return models.SeverityModerate
+	}
+
+	score, err := helpers.CVSS3BaseScore(adv.CVSS)
+	if err != nil {
+		zap.S().Debugf("Could not score %s (%s): %v", adv.ID, adv.CVSS, err)
+		return models.SeverityModerate
+	}
+
+	return severityFromCVSSScore(score)
+}
+
+// severityFromCVSSScore maps a CVSS base score to a severity level
+func severityFromCVSSScore(score float64) string {
+	switch {
+	case score >= 9.0:
+		return models.SeverityCritical
+	case score >= 7.0:
+		return models.SeverityHigh
+	case score >= 4.0:
+		return models.SeverityModerate
+	case score > 0:
+		return models.SeverityLow
+	default:
+		return models.SeverityInfo
+	}
+}
+
+// buildCargoRecommendation creates a recommendation message for Rust crates
+func buildCargoRecommendation(pkg cargoPackage, patchedVersions string) string {
+	if patchedVersions == "" {
+		return fmt.Sprintf("No patched version of %s is available. Consider replacing the crate.", pkg.Name)
+	}
+
+	return fmt.Sprintf("Update %s (currently %s) to a version matching %s. Run 'cargo update -p %s' to update the lockfile.",
+		pkg.Name, pkg.Version, patchedVersions, pkg.Name)
+}
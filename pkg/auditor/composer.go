@@ -5,19 +5,40 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"os"
 	"os/exec"
+	"slices"
 	"strings"
 
+	"github.com/shadowbane/audit-checks/pkg/helpers"
 	"github.com/shadowbane/audit-checks/pkg/models"
-	"go.uber.org/zap"
+)
+
+// composerAbandonedPolicies are the values accepted by composer audit's --abandoned
+var composerAbandonedPolicies = []string{"ignore", "report", "fail"}
+
+// composerSeverities are the values accepted by composer audit's --ignore-severity
+var composerSeverities = []string{"low", "medium", "high", "critical"}
+
+// composerAbandonedID identifies abandoned-package findings, e.g. for ignore lists
+const composerAbandonedID = "ABANDONED"
+
+// composer audit exit code bits (Composer 2.7+)
+const (
+	composerExitVulnerable = 1
+	composerExitAbandoned  = 2
 )
 
 // ComposerAuditor implements the Auditor interface for Composer (PHP) projects
-type ComposerAuditor struct{}
+type ComposerAuditor struct {
+	options map[string]string // global composer audit options (locked, abandoned, ignore-severity, abandoned-severity)
+}
 
-// NewComposerAuditor creates a new ComposerAuditor
-func NewComposerAuditor() *ComposerAuditor {
-	return &ComposerAuditor{}
+// NewComposerAuditor creates a new ComposerAuditor. options are passed to composer audit as flags
+// and can be overridden per app with composer.<key>=<value> audit options.
+func NewComposerAuditor(options map[string]string) *ComposerAuditor {
+	return &ComposerAuditor{options: options}
 }
 
 // Name returns "composer"
@@ -32,25 +53,47 @@ func (a *ComposerAuditor) Detect(path string) bool {
 
 // Audit runs composer audit and parses the results
 func (a *ComposerAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
-	zap.S().Infof("Running composer audit for app=%s path=%s", app.Name, app.Path)
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running composer audit for app=%s path=%s", app.Name, app.Path)
 
 	// Check if composer is available
 	if _, err := exec.LookPath("composer"); err != nil {
 		return nil, fmt.Errorf("composer not found in PATH: %w", err)
 	}
 
+	toolVersion := ToolVersion(ctx, "composer", "--version", "--no-interaction")
+
 	// Check if composer.json exists (lock file is optional for newer composer versions)
 	if !FileExists(JoinPath(app.Path, "composer.json")) {
 		return nil, fmt.Errorf("composer.json not found in %s", app.Path)
 	}
 
-	// Warn if lock file is missing
+	options, err := AppOptions(a.Name(), a.options, app)
+	if err != nil {
+		return nil, err
+	}
+	args, err := composerAuditArgs(options)
+	if err != nil {
+		return nil, err
+	}
+	abandonedSeverity, err := composerAbandonedSeverity(options)
+	if err != nil {
+		return nil, err
+	}
+	locked := slices.Contains(args, "--locked")
+
+	// Warn if lock file is missing; a locked audit needs it
 	if !FileExists(JoinPath(app.Path, "composer.lock")) {
-		zap.S().Warnf("composer.lock not found in %s, auditing from composer.json only", app.Path)
+		if locked {
+			return nil, fmt.Errorf("composer.lock not found in %s (required by the locked option)", app.Path)
+		}
+		log.Warnf("composer.lock not found in %s, auditing from composer.json only", app.Path)
 	}
+	log.Debugf("composer audit args app=%s args=%v", app.Name, args)
 
 	// Run composer audit
-	cmd := exec.CommandContext(ctx, "composer", "audit", "--format=json", "--no-interaction")
+	cmd := exec.CommandContext(ctx, "composer", args...)
 	cmd.Dir = app.Path
 
 	var stdout, stderr bytes.Buffer
@@ -63,10 +106,11 @@ func (a *ComposerAuditor) Audit(ctx context.Context, app models.AppConfig) (*mod
 	//   1 = Vulnerabilities found (security advisories)
 	//   2 = Abandoned packages found (no security issues)
 	//   3 = Vulnerabilities found AND abandoned packages detected
-	err := cmd.Run()
+	exitCode := 0
+	err = cmd.Run()
 	if err != nil {
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode := exitErr.ExitCode()
+			exitCode = exitErr.ExitCode()
 			// Exit codes 1, 2, and 3 mean vulnerabilities and/or abandoned packages found, which is expected
 			if exitCode != 1 && exitCode != 2 && exitCode != 3 {
 				// Build error message from available output
@@ -86,29 +130,50 @@ func (a *ComposerAuditor) Audit(ctx context.Context, app models.AppConfig) (*mod
 
 	// Parse the output
 	output := stdout.String()
+
+	// With abandoned=fail, abandoned packages fail the audit like a policy violation
+	if exitCode&composerExitAbandoned != 0 && options["abandoned"] == "fail" {
+		abandoned := composerAbandonedPackages(output)
+		if len(abandoned) == 0 {
+			return nil, fmt.Errorf("composer audit found abandoned packages (abandoned=fail)")
+		}
+		return nil, fmt.Errorf("composer audit found %d abandoned package(s) (abandoned=fail): %s",
+			len(abandoned), strings.Join(abandoned, ", "))
+	}
+
+	// A clean result composer itself contradicts is a likely false negative
+	questionable := composerQuestionable(output, exitCode)
+	if questionable != "" {
+		log.Warnf("Questionable composer audit result for app=%s: %s", app.Name, questionable)
+	}
+
 	if strings.TrimSpace(output) == "" {
 		// No output likely means no vulnerabilities
-		zap.S().Debugf("composer audit returned empty output for app=%s", app.Name)
+		log.Debugf("composer audit returned empty output for app=%s", app.Name)
 		return &models.AuditResult{
 			Vulnerabilities: []models.Vulnerability{},
 			AuditorType:     a.Name(),
+			ToolVersion:     toolVersion,
 			AppName:         app.Name,
 			AppPath:         app.Path,
+			Questionable:    questionable,
 		}, nil
 	}
 
-	result, err := a.parseOutput(output, app)
+	result, err := a.parseOutput(output, app, abandonedSeverity)
 	if err != nil {
-		zap.S().Debugf("composer audit raw output: %s", output)
+		log.Debugf("composer audit raw output: %s", output)
 		return nil, fmt.Errorf("failed to parse composer audit output: %w", err)
 	}
 
 	result.RawOutput = output
+	result.Questionable = questionable
 	result.AuditorType = a.Name()
+	result.ToolVersion = toolVersion
 	result.AppName = app.Name
 	result.AppPath = app.Path
 
-	zap.S().Infof("composer audit completed for app=%s total=%d critical=%d high=%d",
+	log.Infof("composer audit completed for app=%s total=%d critical=%d high=%d",
 		app.Name,
 		result.TotalVulnerabilities,
 		result.CriticalCount,
@@ -118,6 +183,102 @@ func (a *ComposerAuditor) Audit(ctx context.Context, app models.AppConfig) (*mod
 	return result, nil
 }
 
+// composerAuditArgs builds the composer audit arguments for the configured options
+func composerAuditArgs(options map[string]string) ([]string, error) {
+	args := []string{"audit", "--format=json", "--no-interaction"}
+
+	// locked audits composer.lock instead of the installed vendor directory
+	locked, err := BoolOption(options, "locked")
+	if err != nil {
+		return nil, fmt.Errorf("composer: %w", err)
+	}
+	if locked {
+		args = append(args, "--locked")
+	}
+
+	if abandoned := options["abandoned"]; abandoned != "" {
+		if !slices.Contains(composerAbandonedPolicies, abandoned) {
+			return nil, fmt.Errorf("invalid composer abandoned %q (use %s)", abandoned, strings.Join(composerAbandonedPolicies, ", "))
+		}
+		args = append(args, "--abandoned="+abandoned)
+	}
+
+	// ignore-severity takes several severities separated by spaces, e.g. "low medium".
+	// Composer calls moderate "medium", so both are accepted.
+	for _, severity := range strings.Fields(options["ignore-severity"]) {
+		if severity == models.SeverityModerate {
+			severity = "medium"
+		}
+		if !slices.Contains(composerSeverities, severity) {
+			return nil, fmt.Errorf("invalid composer ignore-severity %q (use %s)", severity, strings.Join(composerSeverities, ", "))
+		}
+		args = append(args, "--ignore-severity="+severity)
+	}
+
+	return args, nil
+}
+
+// composerAbandonedSeverity returns the severity abandoned-package findings are reported with (default low)
+func composerAbandonedSeverity(options map[string]string) (string, error) {
+	severity := options["abandoned-severity"]
+	if severity == "" {
+		return models.SeverityLow, nil
+	}
+	if _, ok := models.SeverityOrder[severity]; !ok {
+		return "", fmt.Errorf("invalid composer abandoned-severity %q (use info, low, moderate, high or critical)", severity)
+	}
+	return severity, nil
+}
+
+// composerAbandonedPackages returns the abandoned packages listed in composer audit output
+func composerAbandonedPackages(output string) []string {
+	var auditOutput composerAuditOutput
+	if err := json.Unmarshal([]byte(output), &auditOutput); err != nil {
+		return nil
+	}
+
+	return slices.Sorted(maps.Keys(parseComposerAbandoned(auditOutput.Abandoned)))
+}
+
+// parseComposerAbandoned maps the abandoned packages of composer audit output to their
+// suggested replacement ("" when the maintainer named none)
+func parseComposerAbandoned(raw json.RawMessage) map[string]string {
+	// An empty list is [], otherwise a map of package to its replacement (or null)
+	var abandoned map[string]*string
+	if len(raw) == 0 || json.Unmarshal(raw, &abandoned) != nil {
+		return nil
+	}
+
+	replacements := make(map[string]string, len(abandoned))
+	for name, replacement := range abandoned {
+		if replacement != nil {
+			replacements[name] = *replacement
+		} else {
+			replacements[name] = ""
+		}
+	}
+	return replacements
+}
+
+// composerAbandonedFinding reports an abandoned package, which no longer receives security fixes
+func composerAbandonedFinding(name, replacement, severity string) models.Vulnerability {
+	recommendation := fmt.Sprintf("Replace %s with a maintained alternative", name)
+	if replacement != "" {
+		recommendation = fmt.Sprintf("Replace %s with %s, suggested by its maintainer: composer remove %s && composer require %s",
+			name, replacement, name, replacement)
+	}
+
+	return models.Vulnerability{
+		PackageName:    name,
+		Severity:       severity,
+		CVEID:          composerAbandonedID,
+		Title:          "Abandoned package",
+		Description:    fmt.Sprintf("%s is marked as abandoned on Packagist and will not receive security fixes.", name),
+		Recommendation: recommendation,
+		URL:            "https://packagist.org/packages/" + name,
+	}
+}
+
 // composerAuditOutput represents the composer audit JSON output structure
 type composerAuditOutput struct {
 	Advisories json.RawMessage `json:"advisories,omitempty"` // Can be [] or {} depending on content
@@ -142,8 +303,29 @@ type source struct {
 	Advisory string `json:"advisory,omitempty"`
 }
 
-// parseOutput parses composer audit JSON output
-func (a *ComposerAuditor) parseOutput(output string, app models.AppConfig) (*models.AuditResult, error) {
+// composerQuestionable returns why composer audit output may be a false negative: composer
+// exited with its vulnerabilities bit set without listing any advisories. Returns "" otherwise.
+func composerQuestionable(output string, exitCode int) string {
+	if exitCode&composerExitVulnerable == 0 {
+		return ""
+	}
+	if strings.TrimSpace(output) == "" {
+		return fmt.Sprintf("composer audit exited %d (vulnerabilities found) without any output", exitCode)
+	}
+
+	var auditOutput composerAuditOutput
+	if err := json.Unmarshal([]byte(output), &auditOutput); err != nil {
+		return ""
+	}
+	var advisories map[string]json.RawMessage
+	if json.Unmarshal(auditOutput.Advisories, &advisories) == nil && len(advisories) > 0 {
+		return ""
+	}
+	return fmt.Sprintf("composer audit exited %d (vulnerabilities found) but lists no advisories", exitCode)
+}
+
+// parseOutput parses composer audit JSON output. Abandoned packages are reported with abandonedSeverity.
+func (a *ComposerAuditor) parseOutput(output string, app models.AppConfig, abandonedSeverity string) (*models.AuditResult, error) {
 	// Handle empty output (no vulnerabilities)
 	if strings.TrimSpace(output) == "" || output == "{}" || output == "[]" {
 		return &models.AuditResult{
@@ -182,11 +364,20 @@ func (a *ComposerAuditor) parseOutput(output string, app models.AppConfig) (*mod
 		}
 	}
 
+	// Transitive packages get a conflict rule excluding the affected versions
+	direct := composerDirectDependencies(app.Path)
+
 	// Process advisories
 	for pkgName, advisories := range advisoriesMap {
 		for _, advisory := range advisories {
 			severity := determineSeverity(advisory)
-			recommendation := buildComposerRecommendation(pkgName, advisory)
+			transitive := direct != nil && !direct[strings.ToLower(pkgName)]
+			recommendation := buildComposerRecommendation(pkgName, advisory, transitive)
+
+			var fixSnippet string
+			if transitive && advisory.AffectedVersions != "" {
+				fixSnippet = fmt.Sprintf("\"conflict\": {\n  %q: %q\n}", pkgName, advisory.AffectedVersions)
+			}
 
 			vulnerability := models.Vulnerability{
 				PackageName:        pkgName,
@@ -198,12 +389,19 @@ func (a *ComposerAuditor) parseOutput(output string, app models.AppConfig) (*mod
 				VulnerableVersions: advisory.AffectedVersions,
 				PatchedVersions:    "", // Composer doesn't provide this directly
 				URL:                advisory.Link,
+				FixSnippet:         fixSnippet,
 			}
 
 			result.Vulnerabilities = append(result.Vulnerabilities, vulnerability)
 		}
 	}
 
+	// Process abandoned packages (absent with --abandoned=ignore)
+	abandoned := parseComposerAbandoned(auditOutput.Abandoned)
+	for _, name := range slices.Sorted(maps.Keys(abandoned)) {
+		result.Vulnerabilities = append(result.Vulnerabilities, composerAbandonedFinding(name, abandoned[name], abandonedSeverity))
+	}
+
 	// Filter ignored vulnerabilities
 	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
 
@@ -248,7 +446,7 @@ func determineSeverity(advisory composerAdvisory) string {
 }
 
 // buildComposerRecommendation creates a recommendation message for composer packages
-func buildComposerRecommendation(pkgName string, advisory composerAdvisory) string {
+func buildComposerRecommendation(pkgName string, advisory composerAdvisory, transitive bool) string {
 	var rec strings.Builder
 
 	rec.WriteString(fmt.Sprintf("Update %s to a patched version. ", pkgName))
@@ -257,9 +455,39 @@ func buildComposerRecommendation(pkgName string, advisory composerAdvisory) stri
 	rec.WriteString(pkgName)
 	rec.WriteString("' to update the package. ")
 
+	if transitive {
+		rec.WriteString("This is a transitive dependency: if the update does not reach a patched version, add the suggested ")
+		rec.WriteString("\"conflict\" rule to composer.json so Composer excludes the affected versions and reports the package that blocks the update. ")
+	}
+
 	if advisory.Link != "" {
 		rec.WriteString(fmt.Sprintf("See %s for more details.", advisory.Link))
 	}
 
 	return rec.String()
 }
+
+// composerDirectDependencies returns the packages required directly by the app's
+// composer.json, or nil when it cannot be read
+func composerDirectDependencies(dir string) map[string]bool {
+	content, err := os.ReadFile(JoinPath(dir, "composer.json"))
+	if err != nil {
+		return nil
+	}
+
+	var manifest struct {
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil
+	}
+
+	direct := make(map[string]bool)
+	for _, deps := range []map[string]string{manifest.Require, manifest.RequireDev} {
+		for name := range deps {
+			direct[strings.ToLower(name)] = true
+		}
+	}
+	return direct
+}
@@ -0,0 +1,205 @@
+package auditor
+
+import (
+	"cmp"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Recorded outputs of npm audit and composer audit, one per JSON variant the tool
+// versions in use print (conformance/<auditor>-<variant>.json), each with the summary
+// it must parse to (conformance/<auditor>-<variant>.golden.json). Both tools have
+// changed their JSON shape between releases; `audit-checks parse --check` catches a
+// parser that no longer reads a recorded variant before it reaches production.
+//
+//go:embed conformance/*.json
+var conformanceFiles embed.FS
+
+// goldenSuffix is the file name suffix of golden summaries
+const goldenSuffix = ".golden.json"
+
+// ParsedFinding is a finding as recorded in a golden summary
+type ParsedFinding struct {
+	Package  string `json:"package"`
+	Severity string `json:"severity"`
+	ID       string `json:"id,omitempty"`
+	Title    string `json:"title"`
+}
+
+// String formats the finding for drift reports
+func (f ParsedFinding) String() string {
+	id := f.ID
+	if id == "" {
+		id = "-"
+	}
+	return fmt.Sprintf("%s %s %s %q", f.Severity, f.Package, id, f.Title)
+}
+
+// ParseSummary is what a tool output parses to: its findings, or the parser's error.
+// Questionable is set when the output's own totals disagree with the parsed findings.
+type ParseSummary struct {
+	Error        string          `json:"error,omitempty"`
+	Questionable string          `json:"questionable,omitempty"`
+	Critical     int             `json:"critical"`
+	High         int             `json:"high"`
+	Moderate     int             `json:"moderate"`
+	Low          int             `json:"low"`
+	Info         int             `json:"info"`
+	Findings     []ParsedFinding `json:"findings"`
+}
+
+// ConformanceFixture is a recorded tool output and the summary it must parse to
+type ConformanceFixture struct {
+	Name    string // e.g. npm-v8
+	Auditor string // e.g. npm
+	Output  []byte
+	Golden  ParseSummary
+}
+
+// ConformanceResult is the outcome of parsing a recorded fixture
+type ConformanceResult struct {
+	Fixture ConformanceFixture
+	Got     ParseSummary
+	Drift   []string // differences from the golden summary, empty when it matches
+}
+
+// ParseFixture parses output as printed by the tool behind auditorName. App settings
+// (ignore lists, ignored paths, abandoned severity) are left at their defaults.
+func ParseFixture(auditorName string, output []byte) (*models.AuditResult, error) {
+	switch auditorName {
+	case "npm":
+		return (&NPMAuditor{}).parseOutput(string(output), models.AppConfig{})
+	case "composer":
+		abandonedSeverity, _ := composerAbandonedSeverity(nil)
+		return (&ComposerAuditor{}).parseOutput(string(output), models.AppConfig{}, abandonedSeverity)
+	default:
+		return nil, fmt.Errorf("no fixture parser for auditor %q (use npm or composer)", auditorName)
+	}
+}
+
+// SummarizeParse summarizes the result of ParseFixture, findings sorted by package and ID
+func SummarizeParse(result *models.AuditResult, err error) ParseSummary {
+	if err != nil {
+		return ParseSummary{Error: err.Error(), Findings: []ParsedFinding{}}
+	}
+
+	summary := ParseSummary{
+		Questionable: result.Questionable,
+		Critical:     result.CriticalCount,
+		High:         result.HighCount,
+		Moderate:     result.ModerateCount,
+		Low:          result.LowCount,
+		Info:         result.InfoCount,
+		Findings:     make([]ParsedFinding, 0, len(result.Vulnerabilities)),
+	}
+	for _, v := range result.Vulnerabilities {
+		summary.Findings = append(summary.Findings, ParsedFinding{
+			Package:  v.PackageName,
+			Severity: v.Severity,
+			ID:       v.CVEID,
+			Title:    v.Title,
+		})
+	}
+	slices.SortFunc(summary.Findings, func(a, b ParsedFinding) int {
+		return cmp.Or(cmp.Compare(a.Package, b.Package), cmp.Compare(a.ID, b.ID), cmp.Compare(a.Title, b.Title))
+	})
+	return summary
+}
+
+// ConformanceFixtures returns the recorded fixtures, sorted by name
+func ConformanceFixtures() ([]ConformanceFixture, error) {
+	entries, err := conformanceFiles.ReadDir("conformance")
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []ConformanceFixture
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasSuffix(name, goldenSuffix) {
+			continue
+		}
+		name = strings.TrimSuffix(name, ".json")
+
+		output, err := conformanceFiles.ReadFile(path.Join("conformance", name+".json"))
+		if err != nil {
+			return nil, err
+		}
+		golden, err := conformanceFiles.ReadFile(path.Join("conformance", name+goldenSuffix))
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s has no golden summary: %w", name, err)
+		}
+
+		fixture := ConformanceFixture{Name: name, Output: output}
+		fixture.Auditor, _, _ = strings.Cut(name, "-")
+		if err := json.Unmarshal(golden, &fixture.Golden); err != nil {
+			return nil, fmt.Errorf("invalid golden summary for %s: %w", name, err)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+	return fixtures, nil
+}
+
+// CheckConformance parses every recorded fixture and compares it with its golden summary
+func CheckConformance() ([]ConformanceResult, error) {
+	fixtures, err := ConformanceFixtures()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ConformanceResult, 0, len(fixtures))
+	for _, fixture := range fixtures {
+		got := SummarizeParse(ParseFixture(fixture.Auditor, fixture.Output))
+		results = append(results, ConformanceResult{
+			Fixture: fixture,
+			Got:     got,
+			Drift:   summaryDrift(fixture.Golden, got),
+		})
+	}
+	return results, nil
+}
+
+// summaryDrift lists how got differs from want
+func summaryDrift(want, got ParseSummary) []string {
+	var drift []string
+	if want.Error != got.Error {
+		drift = append(drift, fmt.Sprintf("error: want %q, got %q", want.Error, got.Error))
+	}
+	if want.Questionable != got.Questionable {
+		drift = append(drift, fmt.Sprintf("questionable: want %q, got %q", want.Questionable, got.Questionable))
+	}
+
+	counts := []struct {
+		severity  string
+		want, got int
+	}{
+		{models.SeverityCritical, want.Critical, got.Critical},
+		{models.SeverityHigh, want.High, got.High},
+		{models.SeverityModerate, want.Moderate, got.Moderate},
+		{models.SeverityLow, want.Low, got.Low},
+		{models.SeverityInfo, want.Info, got.Info},
+	}
+	for _, c := range counts {
+		if c.want != c.got {
+			drift = append(drift, fmt.Sprintf("%s count: want %d, got %d", c.severity, c.want, c.got))
+		}
+	}
+
+	for _, f := range want.Findings {
+		if !slices.Contains(got.Findings, f) {
+			drift = append(drift, "missing: "+f.String())
+		}
+	}
+	for _, f := range got.Findings {
+		if !slices.Contains(want.Findings, f) {
+			drift = append(drift, "unexpected: "+f.String())
+		}
+	}
+	return drift
+}
@@ -0,0 +1,286 @@
+package auditor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// dotnetProjectPatterns are the project and solution files dotnet list package accepts
+var dotnetProjectPatterns = []string{"*.sln", "*.slnx", "*.csproj", "*.fsproj", "*.vbproj"}
+
+// DotnetAuditor implements the Auditor interface for .NET projects using NuGet
+type DotnetAuditor struct{}
+
+// NewDotnetAuditor creates a new DotnetAuditor
+func NewDotnetAuditor() *DotnetAuditor {
+	return &DotnetAuditor{}
+}
+
+// Name returns "dotnet"
+func (a *DotnetAuditor) Name() string {
+	return "dotnet"
+}
+
+// Detect checks for a project/solution file or packages.lock.json
+func (a *DotnetAuditor) Detect(path string) bool {
+	return FileExists(JoinPath(path, "packages.lock.json")) || len(dotnetProjectFiles(path)) > 0
+}
+
+// Audit runs dotnet list package --vulnerable and parses the results
+func (a *DotnetAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running dotnet audit for app=%s path=%s", app.Name, app.Path)
+
+	// Check if dotnet is available
+	if _, err := exec.LookPath("dotnet"); err != nil {
+		return nil, fmt.Errorf("dotnet not found in PATH: %w", err)
+	}
+
+	// Record the SDK version so results can be compared across hosts
+	toolVersion := ToolVersion(ctx, "dotnet", "--version")
+
+	args := []string{"list"}
+	target, err := dotnetTarget(app.Path)
+	if err != nil {
+		return nil, err
+	}
+	if target != "" {
+		args = append(args, target)
+	}
+	// JSON output requires .NET SDK 7.0.200 or later
+	args = append(args, "package", "--vulnerable", "--include-transitive", "--format", "json")
+
+	cmd := exec.CommandContext(ctx, "dotnet", args...)
+	cmd.Dir = app.Path
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// Problems (e.g. missing restore) are reported in the JSON with a non-zero
+	// exit code, so a non-zero exit code is only an error if no report was produced
+	runErr := cmd.Run()
+	if runErr != nil {
+		if _, ok := runErr.(*exec.ExitError); !ok {
+			return nil, fmt.Errorf("failed to run dotnet list package: %w", runErr)
+		}
+	}
+
+	output := stdout.String()
+	if !strings.HasPrefix(strings.TrimSpace(output), "{") {
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" {
+			errMsg = strings.TrimSpace(output)
+		}
+		if errMsg == "" {
+			errMsg = "no output"
+		}
+		return nil, fmt.Errorf("dotnet list package failed: %s", errMsg)
+	}
+
+	result, err := a.parseOutput(output, app)
+	if err != nil {
+		log.Debugf("dotnet list package raw output: %s", output)
+		return nil, fmt.Errorf("failed to parse dotnet list package output: %w", err)
+	}
+
+	result.RawOutput = output
+	result.AuditorType = a.Name()
+	result.ToolVersion = toolVersion
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("dotnet audit completed for app=%s total=%d critical=%d high=%d",
+		app.Name,
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// dotnetListOutput represents the dotnet list package JSON output structure
+type dotnetListOutput struct {
+	Version  int `json:"version"`
+	Problems []struct {
+		Project string `json:"project"`
+		Level   string `json:"level"`
+		Text    string `json:"text"`
+	} `json:"problems"`
+	Projects []struct {
+		Path       string `json:"path"`
+		Frameworks []struct {
+			Framework          string          `json:"framework"`
+			TopLevelPackages   []dotnetPackage `json:"topLevelPackages"`
+			TransitivePackages []dotnetPackage `json:"transitivePackages"`
+		} `json:"frameworks"`
+	} `json:"projects"`
+}
+
+type dotnetPackage struct {
+	ID               string `json:"id"`
+	RequestedVersion string `json:"requestedVersion"`
+	ResolvedVersion  string `json:"resolvedVersion"`
+	Vulnerabilities  []struct {
+		Severity    string `json:"severity"`
+		AdvisoryURL string `json:"advisoryurl"`
+	} `json:"vulnerabilities"`
+}
+
+// parseOutput parses dotnet list package JSON output
+func (a *DotnetAuditor) parseOutput(output string, app models.AppConfig) (*models.AuditResult, error) {
+	var listOutput dotnetListOutput
+	if err := json.Unmarshal([]byte(output), &listOutput); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// Without project results, errors (e.g. no assets file) mean the audit did not run
+	if len(listOutput.Projects) == 0 {
+		var problems []string
+		for _, p := range listOutput.Problems {
+			if strings.EqualFold(p.Level, "error") {
+				problems = append(problems, p.Text)
+			}
+		}
+		if len(problems) > 0 {
+			return nil, fmt.Errorf("%s (run 'dotnet restore' first)", strings.Join(problems, "; "))
+		}
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+	seen := make(map[string]bool)
+
+	add := func(project string, pkg dotnetPackage, transitive bool) {
+		// Skip projects under ignored paths (e.g. samples)
+		if IgnoredPaths(app, project) {
+			return
+		}
+
+		for _, vuln := range pkg.Vulnerabilities {
+			// The same package is listed once per project and target framework
+			key := pkg.ID + "@" + pkg.ResolvedVersion + "|" + vuln.AdvisoryURL
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			advisoryID := vuln.AdvisoryURL
+			if i := strings.LastIndex(advisoryID, "/"); i >= 0 {
+				advisoryID = advisoryID[i+1:]
+			}
+
+			dependency := "direct"
+			if transitive {
+				dependency = "transitive"
+			}
+
+			result.Vulnerabilities = append(result.Vulnerabilities, models.Vulnerability{
+				PackageName: pkg.ID,
+				Severity:    dotnetSeverity(vuln.Severity),
+				CVEID:       advisoryID,
+				Title:       fmt.Sprintf("%s severity vulnerability in %s", vuln.Severity, pkg.ID),
+				Description: fmt.Sprintf("Advisory: %s. %s %s is a %s dependency of %s.",
+					advisoryID, pkg.ID, pkg.ResolvedVersion, dependency, filepath.Base(project)),
+				Recommendation:     buildDotnetRecommendation(pkg, project, transitive),
+				VulnerableVersions: pkg.ResolvedVersion,
+				URL:                vuln.AdvisoryURL,
+			})
+		}
+	}
+
+	for _, project := range listOutput.Projects {
+		for _, fw := range project.Frameworks {
+			for _, pkg := range fw.TopLevelPackages {
+				add(project.Path, pkg, false)
+			}
+			for _, pkg := range fw.TransitivePackages {
+				add(project.Path, pkg, true)
+			}
+		}
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	return result, nil
+}
+
+// dotnetSeverity normalizes NuGet severity levels (Low, Moderate, High, Critical)
+func dotnetSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return models.SeverityCritical
+	case "high":
+		return models.SeverityHigh
+	case "moderate", "medium":
+		return models.SeverityModerate
+	case "low":
+		return models.SeverityLow
+	default:
+		return models.SeverityInfo
+	}
+}
+
+// dotnetProjectFiles returns the project and solution files in dir
+func dotnetProjectFiles(dir string) []string {
+	var files []string
+	for _, pattern := range dotnetProjectPatterns {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		files = append(files, matches...)
+	}
+	return files
+}
+
+// dotnetTarget picks the solution or project to audit. dotnet refuses to guess
+// when a directory has several, so a single solution wins over project files.
+func dotnetTarget(dir string) (string, error) {
+	for _, patterns := range [][]string{{"*.sln", "*.slnx"}, {"*.csproj", "*.fsproj", "*.vbproj"}} {
+		var matches []string
+		for _, pattern := range patterns {
+			m, _ := filepath.Glob(filepath.Join(dir, pattern))
+			matches = append(matches, m...)
+		}
+		switch len(matches) {
+		case 0:
+			continue
+		case 1:
+			return filepath.Base(matches[0]), nil
+		default:
+			names := make([]string, 0, len(matches))
+			for _, m := range matches {
+				names = append(names, filepath.Base(m))
+			}
+			return "", fmt.Errorf("multiple .NET solution or project files in %s: %s",
+				dir, strings.Join(names, ", "))
+		}
+	}
+
+	// No project file: let dotnet report the problem
+	return "", nil
+}
+
+// buildDotnetRecommendation creates a recommendation message for NuGet packages
+func buildDotnetRecommendation(pkg dotnetPackage, project string, transitive bool) string {
+	if transitive {
+		return fmt.Sprintf("%s %s is a transitive dependency. Update the package that depends on it, or reference a patched version directly with 'dotnet add %s package %s'.",
+			pkg.ID, pkg.ResolvedVersion, filepath.Base(project), pkg.ID)
+	}
+
+	return fmt.Sprintf("Update %s (currently %s) to a patched version with 'dotnet add %s package %s'.",
+		pkg.ID, pkg.ResolvedVersion, filepath.Base(project), pkg.ID)
+}
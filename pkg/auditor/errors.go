@@ -0,0 +1,185 @@
+package auditor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// Failure kinds of an auditor error
+const (
+	FailureBinaryMissing = "binary_missing"
+	FailureAuth          = "auth"
+	FailureNetwork       = "network"
+	FailureParse         = "parse"
+	FailureTimeout       = "timeout"
+	FailureQuestionable  = "questionable"
+	FailureUnknown       = "unknown"
+)
+
+// failureMarkers are substrings of tool output that identify a failure kind
+// when the error carries no typed cause (most failures come from a tool's stderr)
+var failureMarkers = []struct {
+	kind    string
+	markers []string
+}{
+	{FailureBinaryMissing, []string{"not found in path", "no supported package manager found"}},
+	{FailureAuth, []string{
+		"e401", "e403", "401 unauthorized", "403 forbidden", "status code 401", "status code 403",
+		"authentication required", "authentication failed", "unauthorized", "invalid credentials",
+		"http-basic", "bad credentials", "could not authenticate",
+	}},
+	{FailureNetwork, []string{
+		"enotfound", "econnrefused", "econnreset", "etimedout", "eai_again", "getaddrinfo",
+		"no such host", "connection refused", "connection reset", "network is unreachable",
+		"could not resolve host", "tls handshake", "i/o timeout", "failed to download",
+		"request to https://", "unable to connect",
+	}},
+	{FailureTimeout, []string{"context deadline exceeded", "timed out", "signal: killed"}},
+	{FailureParse, []string{"failed to parse", "invalid character", "unexpected end of json"}},
+}
+
+// missingBinaryPattern extracts the binary name from "<binary> not found in PATH"
+var missingBinaryPattern = regexp.MustCompile(`(\S+) not found in PATH`)
+
+// AuditError is an auditor failure classified by kind, with a remediation hint
+type AuditError struct {
+	Auditor string
+	Kind    string
+	Hint    string
+	Err     error
+}
+
+// Error returns the underlying error message
+func (e *AuditError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *AuditError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether running the auditor again may succeed.
+// A missing binary, rejected credentials or unparsable output fail the same way every time.
+func (e *AuditError) Retryable() bool {
+	switch e.Kind {
+	case FailureBinaryMissing, FailureAuth, FailureParse:
+		return false
+	}
+	return true
+}
+
+// QuestionableError fails an auditor whose result failed a sanity check (strict mode),
+// with reason telling why the result may be a false negative
+func QuestionableError(auditorName, reason string) *AuditError {
+	return &AuditError{
+		Auditor: auditorName,
+		Kind:    FailureQuestionable,
+		Hint:    FailureHint(auditorName, FailureQuestionable, reason),
+		Err:     fmt.Errorf("questionable %s result: %s", auditorName, reason),
+	}
+}
+
+// Classify wraps an auditor error in an AuditError. Errors that are already classified are returned as-is.
+func Classify(auditorName string, err error) *AuditError {
+	var auditErr *AuditError
+	if errors.As(err, &auditErr) {
+		return auditErr
+	}
+
+	kind := classifyFailure(err)
+	return &AuditError{
+		Auditor: auditorName,
+		Kind:    kind,
+		Hint:    FailureHint(auditorName, kind, err.Error()),
+		Err:     err,
+	}
+}
+
+// classifyFailure determines the failure kind of an error, from its typed causes first, then its message
+func classifyFailure(err error) string {
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		return FailureBinaryMissing
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailureTimeout
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr):
+		return FailureParse
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return FailureTimeout
+		}
+		return FailureNetwork
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, group := range failureMarkers {
+		for _, marker := range group.markers {
+			if strings.Contains(msg, marker) {
+				return group.kind
+			}
+		}
+	}
+	return FailureUnknown
+}
+
+// FailureHint returns a remediation hint for a failure kind. msg is the error
+// message, used to name the missing binary.
+func FailureHint(auditorName, kind, msg string) string {
+	switch kind {
+	case FailureBinaryMissing:
+		binary := auditorName
+		if m := missingBinaryPattern.FindStringSubmatch(msg); m != nil {
+			binary = m[1]
+		}
+		hint := fmt.Sprintf("Install %s and make sure it is in the PATH of the user running audit-checks "+
+			"(cron and systemd use a minimal PATH)", binary)
+		if install, ok := installHints[binary]; ok {
+			hint += ": " + install
+		}
+		return hint
+	case FailureAuth:
+		return "The registry rejected the credentials: check the token in .npmrc, auth.json or the registry " +
+			"configuration of the user running audit-checks"
+	case FailureNetwork:
+		return "The advisory database or registry could not be reached: check DNS, proxy settings " +
+			"(HTTPS_PROXY) and outbound HTTPS access from this host"
+	case FailureParse:
+		return fmt.Sprintf("The %s output could not be read: the tool version may be unsupported; "+
+			"run it by hand in the app directory to see its output", auditorName)
+	case FailureQuestionable:
+		return fmt.Sprintf("The %s output contradicts itself, so findings may have been missed: run the audit by hand "+
+			"in the app directory and compare it with the report", auditorName)
+	case FailureTimeout:
+		return "The audit did not finish in time: retry later, or check whether the tool hangs on a prompt or a slow network"
+	}
+	return fmt.Sprintf("Run the %s audit by hand in the app directory to see the full error", auditorName)
+}
+
+// installHints tells how to install the binaries auditors depend on
+var installHints = map[string]string{
+	"npm":                 "install Node.js (https://nodejs.org)",
+	"pnpm":                "npm install -g pnpm",
+	"yarn":                "corepack enable",
+	"composer":            "see https://getcomposer.org/download/",
+	"govulncheck":         "go install golang.org/x/vuln/cmd/govulncheck@latest",
+	"cargo-audit":         "cargo install cargo-audit",
+	"cargo":               "install Rust (https://rustup.rs)",
+	"osv-scanner":         "see https://google.github.io/osv-scanner/installation/",
+	"dependency-check":    "see https://owasp.org/www-project-dependency-check/",
+	"dependency-check.sh": "see https://owasp.org/www-project-dependency-check/",
+	"dotnet":              "install the .NET SDK 7.0.200 or later",
+	"helm":                "see https://helm.sh/docs/intro/install/",
+	"trivy":               "see https://trivy.dev",
+	"debsecan":            "apt-get install debsecan",
+}
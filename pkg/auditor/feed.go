@@ -0,0 +1,191 @@
+package auditor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// osvFeedURL hosts the OSV.dev database exports. <ecosystem>/modified_id.csv lists
+// every advisory of an ecosystem as "<modified>,<id>", most recently modified first.
+const osvFeedURL = "https://osv-vulnerabilities.storage.googleapis.com"
+
+// maxFeedAdvisories bounds the advisories fetched per ecosystem and poll, so a watcher
+// that was stopped for a long time does not fetch the whole history when it restarts
+const maxFeedAdvisories = 500
+
+// WatchedEcosystems are the OSV ecosystems of the packages recorded by InstalledPackages
+var WatchedEcosystems = []string{osvEcosystemNpm, osvEcosystemPackagist}
+
+// Advisory is an advisory published or modified in the OSV.dev feed
+type Advisory struct {
+	ID       string
+	Aliases  []string
+	Summary  string
+	Modified time.Time
+	Affected []AdvisoryPackage
+}
+
+// AdvisoryPackage is a package affected by an advisory
+type AdvisoryPackage struct {
+	Ecosystem string
+	Name      string
+	Versions  []string // affected versions; empty when the advisory only lists ranges
+}
+
+// Affects returns true if the advisory affects the package version. Advisories that
+// only list version ranges affect every version of the package: the re-audit of the
+// app decides whether the installed version is vulnerable.
+func (a Advisory) Affects(pkg models.AppPackage) bool {
+	for _, affected := range a.Affected {
+		if affected.Ecosystem != pkg.Ecosystem || affected.Name != pkg.Name {
+			continue
+		}
+		if len(affected.Versions) == 0 || slices.Contains(affected.Versions, pkg.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+// PackageNames returns the names of the packages of ecosystem the advisory affects
+func (a Advisory) PackageNames(ecosystem string) []string {
+	var names []string
+	for _, affected := range a.Affected {
+		if affected.Ecosystem == ecosystem && !slices.Contains(names, affected.Name) {
+			names = append(names, affected.Name)
+		}
+	}
+	return names
+}
+
+// FeedAdvisories returns the advisories of an ecosystem modified after since, newest
+// first, and the modification time of the newest advisory in the feed, which is the
+// since of the next poll. At most maxFeedAdvisories advisories are returned.
+func (a *OSVAuditor) FeedAdvisories(ctx context.Context, ecosystem string, since time.Time) ([]Advisory, time.Time, error) {
+	log := helpers.Logger(ctx)
+
+	entries, latest, err := a.feedEntries(ctx, ecosystem, since)
+	if err != nil {
+		return nil, since, err
+	}
+
+	log.Debugf("osv feed: ecosystem=%s new_advisories=%d since=%s", ecosystem, len(entries), since.Format(time.RFC3339))
+
+	advisories := make([]Advisory, 0, len(entries))
+	for _, entry := range entries {
+		vuln, err := a.fetchVulnerability(ctx, entry)
+		if err != nil {
+			return nil, since, fmt.Errorf("failed to fetch advisory %s: %w", entry.ID, err)
+		}
+
+		advisory := newAdvisory(vuln)
+		advisory.Modified, _ = time.Parse(time.RFC3339, entry.Modified)
+		advisories = append(advisories, advisory)
+	}
+
+	return advisories, latest, nil
+}
+
+// LookupAdvisory returns an advisory by ID (GHSA, CVE, ...) from OSV.dev. The packages
+// of its GHSA aliases are added, so a CVE resolves to the npm and Packagist packages
+// of the GitHub advisory it is an alias of.
+func (a *OSVAuditor) LookupAdvisory(ctx context.Context, id string) (Advisory, error) {
+	var vuln osvVulnerability
+	if err := a.get(ctx, "/vulns/"+url.PathEscape(id), &vuln); err != nil {
+		return Advisory{}, err
+	}
+	advisory := newAdvisory(vuln)
+
+	for _, alias := range vuln.Aliases {
+		if !strings.HasPrefix(alias, "GHSA-") {
+			continue
+		}
+		var aliased osvVulnerability
+		if err := a.get(ctx, "/vulns/"+url.PathEscape(alias), &aliased); err != nil {
+			helpers.Logger(ctx).Debugf("Failed to fetch alias %s of %s: %v", alias, id, err)
+			continue
+		}
+		if advisory.Summary == "" {
+			advisory.Summary = aliased.Summary
+		}
+		advisory.Affected = append(advisory.Affected, newAdvisory(aliased).Affected...)
+	}
+
+	return advisory, nil
+}
+
+// newAdvisory converts an OSV advisory
+func newAdvisory(vuln osvVulnerability) Advisory {
+	advisory := Advisory{ID: vuln.ID, Aliases: vuln.Aliases, Summary: vuln.Summary}
+	advisory.Modified, _ = time.Parse(time.RFC3339, vuln.Modified)
+	for _, affected := range vuln.Affected {
+		advisory.Affected = append(advisory.Affected, AdvisoryPackage{
+			Ecosystem: affected.Package.Ecosystem,
+			Name:      affected.Package.Name,
+			Versions:  affected.Versions,
+		})
+	}
+	return advisory
+}
+
+// feedEntries reads the ecosystem's modified_id.csv up to the first advisory not
+// modified after since. Returns the newer entries and the newest modification time.
+func (a *OSVAuditor) feedEntries(ctx context.Context, ecosystem string, since time.Time) ([]osvVulnEntry, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.feedURL+"/"+url.PathEscape(ecosystem)+"/modified_id.csv", nil)
+	if err != nil {
+		return nil, since, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, since, fmt.Errorf("osv feed request failed: %w", err)
+	}
+	// The file is only read up to the last poll; the rest is discarded with the connection
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, since, fmt.Errorf("osv feed error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var entries []osvVulnEntry
+	latest := since
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		modifiedText, id, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ",")
+		if !ok {
+			continue
+		}
+		modified, err := time.Parse(time.RFC3339, modifiedText)
+		if err != nil {
+			continue
+		}
+		if !modified.After(since) {
+			break
+		}
+		if modified.After(latest) {
+			latest = modified
+		}
+		if len(entries) == maxFeedAdvisories {
+			helpers.Logger(ctx).Warnf("osv feed: more than %d advisories of %s were modified since %s; only the newest are checked",
+				maxFeedAdvisories, ecosystem, since.Format(time.RFC3339))
+			break
+		}
+		entries = append(entries, osvVulnEntry{ID: id, Modified: modifiedText})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, since, fmt.Errorf("failed to read osv feed: %w", err)
+	}
+
+	return entries, latest, nil
+}
@@ -0,0 +1,302 @@
+package auditor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// GoAuditor implements the Auditor interface for Go modules using govulncheck
+type GoAuditor struct{}
+
+// NewGoAuditor creates a new GoAuditor
+func NewGoAuditor() *GoAuditor {
+	return &GoAuditor{}
+}
+
+// Name returns "go"
+func (a *GoAuditor) Name() string {
+	return "go"
+}
+
+// Detect checks for go.mod
+func (a *GoAuditor) Detect(path string) bool {
+	return FileExists(JoinPath(path, "go.mod"))
+}
+
+// Audit runs govulncheck and parses the results
+func (a *GoAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running govulncheck for app=%s path=%s", app.Name, app.Path)
+
+	// Check if govulncheck is available
+	if _, err := exec.LookPath("govulncheck"); err != nil {
+		return nil, fmt.Errorf("govulncheck not found in PATH (install with 'go install golang.org/x/vuln/cmd/govulncheck@latest'): %w", err)
+	}
+
+	// Check if go.mod exists
+	if !FileExists(JoinPath(app.Path, "go.mod")) {
+		return nil, fmt.Errorf("go.mod not found in %s", app.Path)
+	}
+
+	// Run govulncheck
+	cmd := exec.CommandContext(ctx, "govulncheck", "-json", "./...")
+	cmd.Dir = app.Path
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// In JSON mode govulncheck exits 0 even when vulnerabilities are found,
+	// so any non-zero exit code is a real failure (e.g., the module does not build)
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			errMsg := strings.TrimSpace(stderr.String())
+			if errMsg == "" {
+				errMsg = fmt.Sprintf("exit code %d", exitErr.ExitCode())
+			}
+			return nil, fmt.Errorf("govulncheck failed (exit %d): %s", exitErr.ExitCode(), errMsg)
+		}
+		return nil, fmt.Errorf("failed to run govulncheck: %w", err)
+	}
+
+	output := stdout.String()
+	result, err := a.parseOutput(output, app)
+	if err != nil {
+		log.Debugf("govulncheck raw output: %s", output)
+		return nil, fmt.Errorf("failed to parse govulncheck output: %w", err)
+	}
+
+	result.RawOutput = output
+	result.AuditorType = a.Name()
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("govulncheck completed for app=%s total=%d critical=%d high=%d",
+		app.Name,
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// govulncheckMessage is a single message in the govulncheck JSON stream.
+// Exactly one of the fields is set per message.
+type govulncheckMessage struct {
+	Config   *govulncheckConfig  `json:"config,omitempty"`
+	OSV      *govulncheckOSV     `json:"osv,omitempty"`
+	Finding  *govulncheckFinding `json:"finding,omitempty"`
+	Progress json.RawMessage     `json:"progress,omitempty"`
+}
+
+type govulncheckConfig struct {
+	ScannerName    string `json:"scanner_name"`
+	ScannerVersion string `json:"scanner_version"`
+	GoVersion      string `json:"go_version"`
+}
+
+type govulncheckOSV struct {
+	ID               string   `json:"id"`
+	Aliases          []string `json:"aliases"`
+	Summary          string   `json:"summary"`
+	Details          string   `json:"details"`
+	DatabaseSpecific struct {
+		URL      string `json:"url"`
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type govulncheckFinding struct {
+	OSV          string             `json:"osv"`
+	FixedVersion string             `json:"fixed_version"`
+	Trace        []govulncheckFrame `json:"trace"`
+}
+
+type govulncheckFrame struct {
+	Module   string `json:"module"`
+	Version  string `json:"version"`
+	Package  string `json:"package"`
+	Function string `json:"function"`
+}
+
+// Reachability levels reported by govulncheck findings
+const (
+	goReachModule  = 1 // vulnerable module is required
+	goReachPackage = 2 // vulnerable package is imported
+	goReachSymbol  = 3 // vulnerable symbol is called
+)
+
+// goFinding aggregates the findings for one advisory in one module
+type goFinding struct {
+	osvID        string
+	module       string
+	version      string
+	fixedVersion string
+	reach        int
+}
+
+// parseOutput parses the streamed govulncheck JSON output
+func (a *GoAuditor) parseOutput(output string, app models.AppConfig) (*models.AuditResult, error) {
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+
+	osvs := make(map[string]*govulncheckOSV)
+	findings := make(map[string]*goFinding)
+	var order []string
+
+	decoder := json.NewDecoder(strings.NewReader(output))
+	for {
+		var msg govulncheckMessage
+		if err := decoder.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse JSON stream: %w", err)
+		}
+
+		switch {
+		case msg.Config != nil:
+			result.ToolVersion = strings.TrimPrefix(msg.Config.ScannerVersion, "v")
+		case msg.OSV != nil:
+			osvs[msg.OSV.ID] = msg.OSV
+		case msg.Finding != nil && len(msg.Finding.Trace) > 0:
+			frame := msg.Finding.Trace[0]
+			key := msg.Finding.OSV + "|" + frame.Module
+
+			f, ok := findings[key]
+			if !ok {
+				f = &goFinding{
+					osvID:        msg.Finding.OSV,
+					module:       frame.Module,
+					version:      frame.Version,
+					fixedVersion: msg.Finding.FixedVersion,
+				}
+				findings[key] = f
+				order = append(order, key)
+			}
+
+			// govulncheck emits one finding per scan level; keep the deepest
+			if reach := findingReach(msg.Finding.Trace); reach > f.reach {
+				f.reach = reach
+			}
+		}
+	}
+
+	for _, key := range order {
+		f := findings[key]
+		osv := osvs[f.osvID]
+		if osv == nil {
+			osv = &govulncheckOSV{ID: f.osvID}
+		}
+
+		vulnerableVersions := f.version
+		if f.fixedVersion != "" {
+			vulnerableVersions = fmt.Sprintf("< %s (using %s)", f.fixedVersion, f.version)
+		}
+
+		url := osv.DatabaseSpecific.URL
+		if url == "" {
+			url = "https://pkg.go.dev/vuln/" + osv.ID
+		}
+
+		result.Vulnerabilities = append(result.Vulnerabilities, models.Vulnerability{
+			PackageName:        f.module,
+			Severity:           goSeverity(osv, f.reach),
+			CVEID:              goCVEID(osv),
+			Title:              osv.Summary,
+			Description:        fmt.Sprintf("Advisory: %s. %s", osv.ID, goReachDescription(f.reach)),
+			Recommendation:     buildGoRecommendation(f),
+			VulnerableVersions: vulnerableVersions,
+			PatchedVersions:    f.fixedVersion,
+			URL:                url,
+		})
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	return result, nil
+}
+
+// findingReach returns the reachability level of a finding trace
+func findingReach(trace []govulncheckFrame) int {
+	frame := trace[0]
+	switch {
+	case frame.Function != "":
+		return goReachSymbol
+	case frame.Package != "":
+		return goReachPackage
+	default:
+		return goReachModule
+	}
+}
+
+// goSeverity maps a Go advisory to a severity level.
+// The Go vulnerability database does not assign severities, so an explicit
+// severity (when present) wins; otherwise reachability determines severity:
+// called symbols are high, imported packages moderate, required modules low.
+func goSeverity(osv *govulncheckOSV, reach int) string {
+	if osv.DatabaseSpecific.Severity != "" {
+		return normalizeSeverity(osv.DatabaseSpecific.Severity)
+	}
+
+	switch reach {
+	case goReachSymbol:
+		return models.SeverityHigh
+	case goReachPackage:
+		return models.SeverityModerate
+	default:
+		return models.SeverityLow
+	}
+}
+
+// goCVEID returns the first CVE alias of an advisory, falling back to the Go ID
+func goCVEID(osv *govulncheckOSV) string {
+	for _, alias := range osv.Aliases {
+		if strings.HasPrefix(alias, "CVE-") {
+			return alias
+		}
+	}
+	return osv.ID
+}
+
+// goReachDescription describes how the vulnerable code is reached
+func goReachDescription(reach int) string {
+	switch reach {
+	case goReachSymbol:
+		return "Vulnerable code is called by this module."
+	case goReachPackage:
+		return "Vulnerable package is imported, but the vulnerable code does not appear to be called."
+	default:
+		return "Vulnerable module is required, but the vulnerable package is not imported."
+	}
+}
+
+// buildGoRecommendation creates a recommendation message for Go modules
+func buildGoRecommendation(f *goFinding) string {
+	if f.fixedVersion == "" {
+		return fmt.Sprintf("No fixed version of %s is available yet. Consider replacing the dependency or mitigating the issue.", f.module)
+	}
+
+	if f.module == "stdlib" || f.module == "toolchain" {
+		return fmt.Sprintf("Upgrade the Go toolchain to %s or later and rebuild.", f.fixedVersion)
+	}
+
+	return fmt.Sprintf("Update %s to version %s. Run 'go get %s@%s && go mod tidy' to update the module.",
+		f.module, f.fixedVersion, f.module, f.fixedVersion)
+}
@@ -0,0 +1,350 @@
+package auditor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// helmChartPatterns are where charts are looked for, relative to the app path
+var helmChartPatterns = []string{"Chart.yaml", "*/Chart.yaml", "*/*/Chart.yaml"}
+
+// helmImagePattern matches the image of a container in rendered manifests
+var helmImagePattern = regexp.MustCompile(`(?m)^\s*(?:-\s+)?image:\s*["']?([^"'\s#]+)`)
+
+// HelmAuditor implements the Auditor interface for Helm charts. It renders each
+// chart with `helm template`, extracts the container images and scans every image with trivy.
+type HelmAuditor struct{}
+
+// NewHelmAuditor creates a new HelmAuditor
+func NewHelmAuditor() *HelmAuditor {
+	return &HelmAuditor{}
+}
+
+// Name returns "helm"
+func (a *HelmAuditor) Name() string {
+	return "helm"
+}
+
+// Detect checks for Chart.yaml in the app or its chart directories (e.g. charts/api/Chart.yaml)
+func (a *HelmAuditor) Detect(path string) bool {
+	return len(helmCharts(path)) > 0
+}
+
+// helmImageScan is the raw trivy output for one image, kept as the audit's raw output
+type helmImageScan struct {
+	Chart  string          `json:"chart"`
+	Image  string          `json:"image"`
+	Error  string          `json:"error,omitempty"`
+	Output json.RawMessage `json:"output,omitempty"`
+}
+
+// Audit renders the app's charts and scans their images
+func (a *HelmAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running helm audit for app=%s path=%s", app.Name, app.Path)
+
+	for _, bin := range []string{"helm", "trivy"} {
+		if _, err := exec.LookPath(bin); err != nil {
+			return nil, fmt.Errorf("%s not found in PATH: %w", bin, err)
+		}
+	}
+
+	var versions []string
+	if v := ToolVersion(ctx, "trivy", "--version"); v != "" {
+		versions = append(versions, "trivy "+v)
+	}
+	if v := ToolVersion(ctx, "helm", "version", "--short"); v != "" {
+		versions = append(versions, "helm "+v)
+	}
+
+	var charts []string
+	for _, chart := range helmCharts(app.Path) {
+		if !IgnoredPaths(app, chart) {
+			charts = append(charts, chart)
+		}
+	}
+	if len(charts) == 0 {
+		return nil, fmt.Errorf("no Chart.yaml found in %s", app.Path)
+	}
+
+	// Images used by several charts are scanned once
+	imageCharts := make(map[string][]string)
+	var images []string
+	for _, chart := range charts {
+		chartImages, err := helmChartImages(ctx, JoinPath(app.Path, chart))
+		if err != nil {
+			return nil, fmt.Errorf("chart %s: %w", chart, err)
+		}
+		log.Debugf("Chart images app=%s chart=%s images=%v", app.Name, chart, chartImages)
+
+		for _, image := range chartImages {
+			if _, ok := imageCharts[image]; !ok {
+				images = append(images, image)
+			}
+			imageCharts[image] = append(imageCharts[image], chart)
+		}
+	}
+	sort.Strings(images)
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+	findings := newHelmFindings()
+	var scans []helmImageScan
+	var failed []string
+
+	for _, image := range images {
+		scan := helmImageScan{Chart: strings.Join(imageCharts[image], ", "), Image: image}
+
+		output, err := runTrivyImage(ctx, image)
+		if err != nil {
+			// One unreachable image (e.g. a private registry) should not hide the others
+			log.Warnf("Failed to scan image app=%s image=%s: %v", app.Name, image, err)
+			scan.Error = err.Error()
+			scans = append(scans, scan)
+			failed = append(failed, image)
+			continue
+		}
+		scan.Output = json.RawMessage(output)
+		scans = append(scans, scan)
+
+		if err := findings.add(image, output); err != nil {
+			log.Debugf("trivy raw output: %s", output)
+			return nil, fmt.Errorf("failed to parse trivy output for %s: %w", image, err)
+		}
+	}
+
+	if len(images) > 0 && len(failed) == len(images) {
+		return nil, fmt.Errorf("failed to scan all %d image(s): %s", len(images), strings.Join(failed, ", "))
+	}
+
+	result.Vulnerabilities = findings.vulnerabilities()
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	rawOutput, _ := json.Marshal(scans)
+	result.RawOutput = string(rawOutput)
+	result.AuditorType = a.Name()
+	result.ToolVersion = strings.Join(versions, ", ")
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("helm audit completed for app=%s charts=%d images=%d failed=%d total=%d critical=%d high=%d",
+		app.Name,
+		len(charts),
+		len(images),
+		len(failed),
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// helmCharts returns the chart directories of an app, relative to the app path.
+// Charts nested in another chart (its subcharts) are rendered with their parent and skipped.
+func helmCharts(dir string) []string {
+	var charts []string
+	for _, pattern := range helmChartPatterns {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, match := range matches {
+			if rel, err := filepath.Rel(dir, filepath.Dir(match)); err == nil {
+				charts = append(charts, filepath.ToSlash(rel))
+			}
+		}
+	}
+	sort.Strings(charts)
+
+	var top []string
+	for _, chart := range charts {
+		nested := false
+		for _, parent := range top {
+			if parent == "." || strings.HasPrefix(chart, parent+"/") {
+				nested = true
+				break
+			}
+		}
+		if !nested {
+			top = append(top, chart)
+		}
+	}
+	return top
+}
+
+// helmChartImages renders a chart with its default values and returns the images it references
+func helmChartImages(ctx context.Context, chartDir string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "helm", "template", "audit-checks", ".")
+	cmd.Dir = chartDir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" {
+			errMsg = err.Error()
+		}
+		if strings.Contains(errMsg, "missing in charts/ directory") {
+			errMsg += " (run 'helm dependency build')"
+		}
+		return nil, fmt.Errorf("helm template failed: %s", errMsg)
+	}
+
+	return extractManifestImages(stdout.String()), nil
+}
+
+// extractManifestImages returns the unique container images referenced by Kubernetes manifests
+func extractManifestImages(manifests string) []string {
+	seen := make(map[string]bool)
+	var images []string
+
+	for _, match := range helmImagePattern.FindAllStringSubmatch(manifests, -1) {
+		image := match[1]
+		// Skip images rendered from empty values (e.g. ":" or "@sha256:...")
+		if image == "" || strings.HasPrefix(image, ":") || strings.HasPrefix(image, "@") {
+			continue
+		}
+		if !seen[image] {
+			seen[image] = true
+			images = append(images, image)
+		}
+	}
+
+	sort.Strings(images)
+	return images
+}
+
+// runTrivyImage scans an image with trivy and returns its JSON output
+func runTrivyImage(ctx context.Context, image string) (string, error) {
+	cmd := exec.CommandContext(ctx, "trivy", "image", "--format", "json", "--quiet", "--scanners", "vuln", image)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", fmt.Errorf("failed to run trivy: %w", err)
+		}
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" {
+			errMsg = err.Error()
+		}
+		return "", fmt.Errorf("trivy failed: %s", errMsg)
+	}
+
+	output := stdout.String()
+	if strings.TrimSpace(output) == "" {
+		return "", fmt.Errorf("trivy produced no output")
+	}
+
+	return output, nil
+}
+
+// trivyOutput represents the trivy JSON output structure
+type trivyOutput struct {
+	Results []struct {
+		Target          string `json:"Target"`
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+			Title            string `json:"Title"`
+			Description      string `json:"Description"`
+			PrimaryURL       string `json:"PrimaryURL"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// helmFindings aggregates trivy findings across images: a vulnerable package
+// shared by several images (e.g. a common base image) is reported once
+type helmFindings struct {
+	order  []string
+	vulns  map[string]*models.Vulnerability
+	images map[string][]string
+}
+
+func newHelmFindings() *helmFindings {
+	return &helmFindings{
+		vulns:  make(map[string]*models.Vulnerability),
+		images: make(map[string][]string),
+	}
+}
+
+// add parses the trivy output of an image
+func (f *helmFindings) add(image, output string) error {
+	var scan trivyOutput
+	if err := json.Unmarshal([]byte(output), &scan); err != nil {
+		return fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	for _, target := range scan.Results {
+		for _, vuln := range target.Vulnerabilities {
+			key := vuln.PkgName + "@" + vuln.InstalledVersion + "|" + vuln.VulnerabilityID
+			if _, ok := f.vulns[key]; !ok {
+				title := vuln.Title
+				if title == "" {
+					title = vuln.VulnerabilityID
+				}
+				f.order = append(f.order, key)
+				f.vulns[key] = &models.Vulnerability{
+					PackageName:        vuln.PkgName,
+					Severity:           normalizeSeverity(vuln.Severity),
+					CVEID:              vuln.VulnerabilityID,
+					Title:              title,
+					Description:        strings.TrimSpace(vuln.Description),
+					VulnerableVersions: vuln.InstalledVersion,
+					PatchedVersions:    vuln.FixedVersion,
+					URL:                vuln.PrimaryURL,
+				}
+			}
+			if images := f.images[key]; len(images) == 0 || images[len(images)-1] != image {
+				f.images[key] = append(images, image)
+			}
+		}
+	}
+
+	return nil
+}
+
+// vulnerabilities returns the aggregated findings, naming the affected images
+func (f *helmFindings) vulnerabilities() []models.Vulnerability {
+	vulns := make([]models.Vulnerability, 0, len(f.order))
+	for _, key := range f.order {
+		v := *f.vulns[key]
+		images := strings.Join(f.images[key], ", ")
+
+		v.Description = strings.TrimSpace(v.Description + "\n\nAffected images: " + images)
+		if v.PatchedVersions != "" {
+			v.Recommendation = fmt.Sprintf("Update %s to %s or later in %s: bump the image tag in the chart values, "+
+				"or rebuild the image on a patched base image.", v.PackageName, v.PatchedVersions, images)
+		} else {
+			v.Recommendation = fmt.Sprintf("No fixed version of %s is available yet; check whether a newer tag of %s "+
+				"or a different base image drops it.", v.PackageName, images)
+		}
+
+		vulns = append(vulns, v)
+	}
+	return vulns
+}
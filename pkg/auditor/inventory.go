@@ -0,0 +1,108 @@
+package auditor
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Dependencies returns the dependency inventory of the app's package-lock.json and
+// composer.lock, sorted by name and version: every installed package, whether or
+// not it is vulnerable. Direct dependencies are those listed in the manifests.
+func Dependencies(app models.AppConfig) ([]models.Dependency, error) {
+	packages, err := readLockfilePackages(app.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	npmDirect := npmDirectDependencies(app.Path)
+	composerDirect := composerDirectDependencies(app.Path)
+
+	deps := make([]models.Dependency, 0, len(packages))
+	for _, pkg := range packages {
+		dep := models.Dependency{
+			Ecosystem: pkg.Ecosystem,
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Scope:     models.DependencyScopeProd,
+			License:   pkg.License,
+			Lockfile:  pkg.Lockfile,
+			DependsOn: pkg.Requires,
+		}
+		if pkg.Dev {
+			dep.Scope = models.DependencyScopeDev
+		}
+
+		switch pkg.Ecosystem {
+		case osvEcosystemNpm:
+			// Other versions of a direct dependency are nested copies required by packages
+			dep.Direct = npmDirect[pkg.Name] && (len(pkg.Paths) == 0 || slices.Contains(pkg.Paths, "node_modules/"+pkg.Name))
+		case osvEcosystemPackagist:
+			dep.Direct = composerDirect[strings.ToLower(pkg.Name)]
+		}
+
+		deps = append(deps, dep)
+	}
+
+	return deps, nil
+}
+
+// AppVersion returns the version of the app's package.json or composer.json, or an
+// empty string when neither has one
+func AppVersion(app models.AppConfig) string {
+	for _, manifest := range []string{"package.json", "composer.json"} {
+		content, err := os.ReadFile(JoinPath(app.Path, manifest))
+		if err != nil {
+			continue
+		}
+
+		var fields struct {
+			Version string `json:"version"`
+		}
+		if json.Unmarshal(content, &fields) == nil && strings.TrimSpace(fields.Version) != "" {
+			return strings.TrimSpace(fields.Version)
+		}
+	}
+	return ""
+}
+
+// IsNoLockfile returns true if err tells that the app has no package-lock.json or composer.lock
+func IsNoLockfile(err error) bool {
+	return errors.Is(err, errNoLockfile)
+}
+
+// npmDirectDependencies returns the packages listed in the app's package.json,
+// or nil when it cannot be read
+func npmDirectDependencies(dir string) map[string]bool {
+	content, err := os.ReadFile(JoinPath(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+
+	var manifest struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil
+	}
+
+	direct := make(map[string]bool)
+	for _, deps := range []map[string]string{
+		manifest.Dependencies,
+		manifest.DevDependencies,
+		manifest.OptionalDependencies,
+		manifest.PeerDependencies,
+	} {
+		for name := range deps {
+			direct[name] = true
+		}
+	}
+	return direct
+}
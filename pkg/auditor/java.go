@@ -0,0 +1,387 @@
+package auditor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Java audit backends
+const (
+	JavaBackendOSVScanner      = "osv-scanner"
+	JavaBackendDependencyCheck = "dependency-check"
+)
+
+// JavaAuditor implements the Auditor interface for Maven and Gradle projects,
+// using either osv-scanner or OWASP dependency-check
+type JavaAuditor struct {
+	backend string
+}
+
+// NewJavaAuditor creates a new JavaAuditor using the given backend
+// (osv-scanner when empty)
+func NewJavaAuditor(backend string) *JavaAuditor {
+	if backend == "" {
+		backend = JavaBackendOSVScanner
+	}
+	return &JavaAuditor{backend: backend}
+}
+
+// Name returns "java"
+func (a *JavaAuditor) Name() string {
+	return "java"
+}
+
+// Detect checks for pom.xml or a Gradle build file
+func (a *JavaAuditor) Detect(path string) bool {
+	return FileExists(JoinPath(path, "pom.xml")) ||
+		FileExists(JoinPath(path, "build.gradle")) ||
+		FileExists(JoinPath(path, "build.gradle.kts"))
+}
+
+// Audit runs the configured backend and parses the results
+func (a *JavaAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running java audit (%s) for app=%s path=%s", a.backend, app.Name, app.Path)
+
+	var (
+		result      *models.AuditResult
+		output      string
+		toolVersion string
+		err         error
+	)
+
+	switch a.backend {
+	case JavaBackendOSVScanner:
+		result, output, toolVersion, err = a.auditOSVScanner(ctx, app)
+	case JavaBackendDependencyCheck:
+		result, output, toolVersion, err = a.auditDependencyCheck(ctx, app)
+	default:
+		return nil, fmt.Errorf("unknown java audit backend %q (use %s or %s)",
+			a.backend, JavaBackendOSVScanner, JavaBackendDependencyCheck)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	result.RawOutput = output
+	result.AuditorType = a.Name()
+	result.ToolVersion = toolVersion
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("java audit completed for app=%s total=%d critical=%d high=%d",
+		app.Name,
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// auditOSVScanner scans the project manifests and lockfiles with osv-scanner.
+// Maven projects are resolved from pom.xml; Gradle projects need a gradle.lockfile
+// or gradle/verification-metadata.xml.
+func (a *JavaAuditor) auditOSVScanner(ctx context.Context, app models.AppConfig) (*models.AuditResult, string, string, error) {
+	log := helpers.Logger(ctx)
+
+	if _, err := exec.LookPath("osv-scanner"); err != nil {
+		return nil, "", "", fmt.Errorf("osv-scanner not found in PATH: %w", err)
+	}
+
+	toolVersion := ""
+	if v := ToolVersion(ctx, "osv-scanner", "--version"); v != "" {
+		toolVersion = "osv-scanner " + v
+	}
+
+	output, err := runOSVScanner(ctx, app.Path)
+	if err != nil {
+		if !FileExists(JoinPath(app.Path, "pom.xml")) {
+			err = fmt.Errorf("%w (Gradle projects need dependency locking: run 'gradle dependencies --write-locks')", err)
+		}
+		return nil, "", "", err
+	}
+
+	// Only Maven packages belong to this auditor; npm or other lockfiles in
+	// the same tree are reported by their own auditors
+	include := func(source, ecosystem string) bool {
+		return ecosystem == "Maven" && !IgnoredPaths(app, source)
+	}
+
+	vulns, err := parseOSVScannerOutput(output, include, buildJavaRecommendation)
+	if err != nil {
+		log.Debugf("osv-scanner raw output: %s", output)
+		return nil, "", "", fmt.Errorf("failed to parse osv-scanner output: %w", err)
+	}
+
+	return &models.AuditResult{Vulnerabilities: vulns}, output, toolVersion, nil
+}
+
+// auditDependencyCheck scans the project with OWASP dependency-check. It analyzes
+// the project's artifacts, so the project should be built (e.g. 'mvn package') first.
+func (a *JavaAuditor) auditDependencyCheck(ctx context.Context, app models.AppConfig) (*models.AuditResult, string, string, error) {
+	log := helpers.Logger(ctx)
+
+	// The distribution ships dependency-check.sh; package managers install dependency-check
+	bin := ""
+	for _, name := range []string{"dependency-check", "dependency-check.sh"} {
+		if _, err := exec.LookPath(name); err == nil {
+			bin = name
+			break
+		}
+	}
+	if bin == "" {
+		return nil, "", "", fmt.Errorf("dependency-check not found in PATH")
+	}
+
+	toolVersion := ""
+	if v := ToolVersion(ctx, bin, "--version"); v != "" {
+		toolVersion = "dependency-check " + v
+	}
+
+	outDir, err := os.MkdirTemp("", "audit-checks-dependency-check-")
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to create report directory: %w", err)
+	}
+	defer os.RemoveAll(outDir)
+
+	cmd := exec.CommandContext(ctx, bin,
+		"--scan", app.Path,
+		"--format", "JSON",
+		"--out", outDir,
+		"--project", app.Name,
+	)
+	cmd.Dir = app.Path
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return nil, "", "", fmt.Errorf("failed to run dependency-check: %w", err)
+		}
+	}
+
+	data, err := os.ReadFile(JoinPath(outDir, "dependency-check-report.json"))
+	if err != nil {
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" {
+			errMsg = err.Error()
+		}
+		return nil, "", "", fmt.Errorf("dependency-check failed: %s", errMsg)
+	}
+
+	output := string(data)
+
+	result, err := a.parseDependencyCheckOutput(output, app)
+	if err != nil {
+		log.Debugf("dependency-check raw output: %s", output)
+		return nil, "", "", fmt.Errorf("failed to parse dependency-check output: %w", err)
+	}
+
+	return result, output, toolVersion, nil
+}
+
+// dependencyCheckOutput represents the dependency-check JSON report structure
+type dependencyCheckOutput struct {
+	Dependencies []struct {
+		FileName string `json:"fileName"`
+		FilePath string `json:"filePath"`
+		Packages []struct {
+			ID string `json:"id"`
+		} `json:"packages"`
+		Vulnerabilities []dependencyCheckVulnerability `json:"vulnerabilities"`
+	} `json:"dependencies"`
+}
+
+type dependencyCheckVulnerability struct {
+	Source      string `json:"source"`
+	Name        string `json:"name"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	CVSSv3      *struct {
+		BaseScore float64 `json:"baseScore"`
+	} `json:"cvssv3"`
+	References []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+	VulnerableSoftware []struct {
+		Software struct {
+			VulnerabilityIDMatched string `json:"vulnerabilityIdMatched"`
+			VersionEndExcluding    string `json:"versionEndExcluding"`
+		} `json:"software"`
+	} `json:"vulnerableSoftware"`
+}
+
+// parseDependencyCheckOutput parses the dependency-check JSON report
+func (a *JavaAuditor) parseDependencyCheckOutput(output string, app models.AppConfig) (*models.AuditResult, error) {
+	var report dependencyCheckOutput
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+	seen := make(map[string]bool)
+
+	for _, dep := range report.Dependencies {
+		if len(dep.Vulnerabilities) == 0 {
+			continue
+		}
+
+		// Dependencies resolved from a manifest are reported as "<manifest>?<coordinates>"
+		filePath, _, _ := strings.Cut(dep.FilePath, "?")
+		if filePath != "" && IgnoredPaths(app, filePath) {
+			continue
+		}
+
+		name, version := "", ""
+		for _, pkg := range dep.Packages {
+			if n, v, ok := parseMavenPURL(pkg.ID); ok {
+				name, version = n, v
+				break
+			}
+		}
+		if name == "" {
+			// Skip non-JVM findings (e.g. from the Node.js analyzers)
+			if !isJavaArchive(dep.FileName) {
+				continue
+			}
+			name = dep.FileName
+		}
+
+		for _, vuln := range dep.Vulnerabilities {
+			key := name + "@" + version + "|" + vuln.Name
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			result.Vulnerabilities = append(result.Vulnerabilities, dependencyCheckToVulnerability(vuln, name, version))
+		}
+	}
+
+	return result, nil
+}
+
+// dependencyCheckToVulnerability converts a dependency-check finding to a Vulnerability
+func dependencyCheckToVulnerability(vuln dependencyCheckVulnerability, name, version string) models.Vulnerability {
+	url := ""
+	if strings.HasPrefix(vuln.Name, "CVE-") {
+		url = "https://nvd.nist.gov/vuln/detail/" + vuln.Name
+	} else if len(vuln.References) > 0 {
+		url = vuln.References[0].URL
+	}
+
+	var fixed []string
+	for _, vs := range vuln.VulnerableSoftware {
+		if vs.Software.VulnerabilityIDMatched == "true" && vs.Software.VersionEndExcluding != "" {
+			fixed = append(fixed, vs.Software.VersionEndExcluding)
+		}
+	}
+
+	description := strings.TrimSpace(vuln.Description)
+	title := description
+	if i := strings.IndexAny(title, ".\n"); i > 0 {
+		title = title[:i]
+	}
+	if title == "" {
+		title = vuln.Name
+	}
+
+	return models.Vulnerability{
+		PackageName:        name,
+		Severity:           dependencyCheckSeverity(vuln),
+		CVEID:              vuln.Name,
+		Title:              title,
+		Description:        fmt.Sprintf("Source: %s. %s", vuln.Source, description),
+		Recommendation:     buildJavaRecommendation(name, version, fixed),
+		VulnerableVersions: version,
+		PatchedVersions:    strings.Join(fixed, ", "),
+		URL:                url,
+	}
+}
+
+// dependencyCheckSeverity normalizes dependency-check severities (NVD uses MEDIUM,
+// OSS Index uses MODERATE), falling back to the CVSS v3 score
+func dependencyCheckSeverity(vuln dependencyCheckVulnerability) string {
+	switch strings.ToLower(vuln.Severity) {
+	case "critical":
+		return models.SeverityCritical
+	case "high":
+		return models.SeverityHigh
+	case "medium", "moderate":
+		return models.SeverityModerate
+	case "low":
+		return models.SeverityLow
+	}
+
+	if vuln.CVSSv3 != nil {
+		return severityFromCVSSScore(vuln.CVSSv3.BaseScore)
+	}
+	return models.SeverityModerate
+}
+
+// parseMavenPURL extracts "groupId:artifactId" and the version from a Maven package URL
+// (e.g. "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1")
+func parseMavenPURL(purl string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(purl, "pkg:maven/")
+	if !ok {
+		return "", "", false
+	}
+
+	// Drop qualifiers and subpath
+	if i := strings.IndexAny(rest, "?#"); i >= 0 {
+		rest = rest[:i]
+	}
+
+	coords, version, _ := strings.Cut(rest, "@")
+	group, artifact, ok := strings.Cut(coords, "/")
+	if !ok || group == "" || artifact == "" {
+		return "", "", false
+	}
+
+	return group + ":" + artifact, version, true
+}
+
+// isJavaArchive reports whether the file name is a JVM archive
+func isJavaArchive(fileName string) bool {
+	lower := strings.ToLower(fileName)
+	for _, ext := range []string{".jar", ".war", ".ear"} {
+		if strings.HasSuffix(lower, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildJavaRecommendation creates a recommendation message for Maven/Gradle dependencies
+func buildJavaRecommendation(name, version string, fixed []string) string {
+	if len(fixed) == 0 {
+		return fmt.Sprintf("No fixed version of %s is known. Consider replacing the dependency.", name)
+	}
+
+	// Recommend the lowest fixed version above the current one
+	target := lowestFixedVersion(version, fixed)
+
+	return fmt.Sprintf("Update %s to %s or later in pom.xml/build.gradle (or via dependencyManagement/constraints for transitive dependencies).",
+		name, target)
+}
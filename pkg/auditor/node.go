@@ -0,0 +1,398 @@
+package auditor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Node.js rule IDs, reported in the CVE field so they can be added to an app's ignore list
+const (
+	NodeRuleEOL           = "NODE-EOL"
+	NodeRuleEOLSoon       = "NODE-EOL-SOON"
+	NodeRuleVulnerable    = "NODE-VULNERABLE"
+	NodeRuleConstraintEOL = "NODE-CONSTRAINT-EOL"
+)
+
+const (
+	// nodeReleasesURL lists every Node.js release, flagging security releases
+	nodeReleasesURL = "https://nodejs.org/dist/index.json"
+
+	// nodeReleasesCacheKey is the release index's entry in the OSV auditor's cache
+	nodeReleasesCacheKey = "node/index"
+
+	// nodeEOLWarning is how long before its end of life a Node.js major is reported
+	nodeEOLWarning = 90 * 24 * time.Hour
+)
+
+// nodeEOL is the end of life of each Node.js major (https://github.com/nodejs/Release).
+// Older majors are end-of-life; newer ones are treated as supported.
+var nodeEOL = map[int]string{
+	8:  "2019-12-31",
+	9:  "2018-06-30",
+	10: "2021-04-30",
+	11: "2019-06-01",
+	12: "2022-04-30",
+	13: "2020-06-01",
+	14: "2023-04-30",
+	15: "2021-06-01",
+	16: "2023-09-11",
+	17: "2022-06-01",
+	18: "2025-04-30",
+	19: "2023-06-01",
+	20: "2026-04-30",
+	21: "2024-06-01",
+	22: "2027-04-30",
+	23: "2025-06-01",
+	24: "2028-04-30",
+	25: "2026-06-01",
+	26: "2029-04-30",
+}
+
+// NodeAuditor implements the Auditor interface for the Node.js runtime. It flags an
+// end-of-life (or soon end-of-life) node binary, a node release older than a security
+// release of its major, and a package.json engines.node range that allows end-of-life
+// majors. Security releases come from the nodejs.org release index, cached with the
+// OSV auditor's cache; without a node binary only package.json is checked.
+type NodeAuditor struct {
+	autoDetect bool
+	osv        *OSVAuditor
+}
+
+// NewNodeAuditor creates a new NodeAuditor caching the release index through osv.
+// When autoDetect is false it only runs for apps whose type lists it explicitly.
+func NewNodeAuditor(autoDetect bool, osv *OSVAuditor) *NodeAuditor {
+	return &NodeAuditor{autoDetect: autoDetect, osv: osv}
+}
+
+// Name returns "node"
+func (a *NodeAuditor) Name() string {
+	return "node"
+}
+
+// Detect checks for package.json
+func (a *NodeAuditor) Detect(path string) bool {
+	if !a.autoDetect {
+		return false
+	}
+	return FileExists(JoinPath(path, "package.json"))
+}
+
+// nodeRelease is a release listed in the nodejs.org release index
+type nodeRelease struct {
+	Version  string `json:"version"` // e.g. v20.11.1
+	Date     string `json:"date"`
+	Security bool   `json:"security"`
+}
+
+// nodeReleasesCacheEntry is the cached release index
+type nodeReleasesCacheEntry struct {
+	FetchedAt time.Time     `json:"fetched_at"`
+	Releases  []nodeRelease `json:"releases"`
+}
+
+// Audit checks the app's node binary and package.json
+func (a *NodeAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running node audit for app=%s path=%s", app.Name, app.Path)
+
+	options, err := AppOptions("node", nil, app)
+	if err != nil {
+		return nil, err
+	}
+	binary := options["binary"]
+	if binary == "" {
+		binary = "node"
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+	now := time.Now()
+
+	// Installed node: a configured binary must exist, the default one is optional
+	installedMajor := 0
+	if _, err := exec.LookPath(binary); err != nil {
+		if options["binary"] != "" {
+			return nil, fmt.Errorf("%s not found in PATH: %w", binary, err)
+		}
+		log.Warnf("node not found in PATH, only checking package.json for app=%s", app.Name)
+	} else {
+		version := ToolVersion(ctx, binary, "--version")
+		if version == "" {
+			return nil, fmt.Errorf("%s --version did not report a version", binary)
+		}
+		result.ToolVersion = version
+		installedMajor, _ = nodeMajor(version)
+
+		finding, ok := checkNodeEOL(version, binary, now)
+		if ok {
+			result.Vulnerabilities = append(result.Vulnerabilities, finding)
+		}
+
+		// Security releases only matter for a major that still gets them
+		if !ok || finding.CVEID != NodeRuleEOL {
+			releases, err := a.releases(ctx)
+			if err != nil {
+				log.Warnf("Skipping node security release check for app=%s: %v", app.Name, err)
+			} else if finding, ok := checkNodeSecurityReleases(version, binary, releases); ok {
+				result.Vulnerabilities = append(result.Vulnerabilities, finding)
+			}
+		}
+	}
+
+	// package.json engines.node
+	content, err := os.ReadFile(JoinPath(app.Path, "package.json"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+	if err == nil {
+		finding, ok, err := checkNodeEngines(content, installedMajor, now)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result.Vulnerabilities = append(result.Vulnerabilities, finding)
+		}
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	rawOutput, _ := json.Marshal(result.Vulnerabilities)
+	result.RawOutput = string(rawOutput)
+	result.AuditorType = a.Name()
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("node audit completed for app=%s version=%s total=%d",
+		app.Name,
+		result.ToolVersion,
+		result.TotalVulnerabilities,
+	)
+
+	return result, nil
+}
+
+// releases returns the nodejs.org release index, from the cache when it is younger than the cache TTL
+func (a *NodeAuditor) releases(ctx context.Context) ([]nodeRelease, error) {
+	var entry nodeReleasesCacheEntry
+	if a.osv.readCache(nodeReleasesCacheKey, &entry) && time.Since(entry.FetchedAt) < a.osv.cacheTTL {
+		return entry.Releases, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, nodeReleasesURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := a.osv.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("nodejs.org request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("nodejs.org error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var releases []nodeRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to parse nodejs.org release index: %w", err)
+	}
+
+	a.osv.writeCache(nodeReleasesCacheKey, nodeReleasesCacheEntry{FetchedAt: time.Now(), Releases: releases})
+	return releases, nil
+}
+
+// checkNodeEOL reports an installed node whose major is end-of-life or ends within nodeEOLWarning
+func checkNodeEOL(version, binary string, now time.Time) (models.Vulnerability, bool) {
+	major, ok := nodeMajor(version)
+	if !ok {
+		return models.Vulnerability{}, false
+	}
+	eol, known := nodeMajorEOL(major)
+	if !known {
+		return models.Vulnerability{}, false
+	}
+
+	switch {
+	case now.After(eol):
+		return models.Vulnerability{
+			PackageName:        "node",
+			Severity:           models.SeverityHigh,
+			CVEID:              NodeRuleEOL,
+			Title:              fmt.Sprintf("Node.js %d is end-of-life", major),
+			Description:        fmt.Sprintf("Node.js %d stopped receiving security fixes on %s; vulnerabilities found since are not fixed. %s reports version %s.", major, eol.Format(time.DateOnly), binary, version),
+			Recommendation:     nodeUpgradeRecommendation(now),
+			VulnerableVersions: version,
+			PatchedVersions:    supportedNodeLTSMajors(now),
+			URL:                "https://nodejs.org/en/about/previous-releases",
+		}, true
+	case now.Add(nodeEOLWarning).After(eol):
+		return models.Vulnerability{
+			PackageName:        "node",
+			Severity:           models.SeverityModerate,
+			CVEID:              NodeRuleEOLSoon,
+			Title:              fmt.Sprintf("Node.js %d reaches end-of-life on %s", major, eol.Format(time.DateOnly)),
+			Description:        fmt.Sprintf("Node.js %d stops receiving security fixes on %s. %s reports version %s.", major, eol.Format(time.DateOnly), binary, version),
+			Recommendation:     nodeUpgradeRecommendation(now),
+			VulnerableVersions: version,
+			PatchedVersions:    supportedNodeLTSMajors(now),
+			URL:                "https://nodejs.org/en/about/previous-releases",
+		}, true
+	}
+	return models.Vulnerability{}, false
+}
+
+// checkNodeSecurityReleases reports an installed node older than a security release of its major
+func checkNodeSecurityReleases(version, binary string, releases []nodeRelease) (models.Vulnerability, bool) {
+	major, ok := nodeMajor(version)
+	if !ok {
+		return models.Vulnerability{}, false
+	}
+
+	var missed []string
+	latest := ""
+	for _, release := range releases {
+		if m, ok := nodeMajor(release.Version); !ok || m != major {
+			continue
+		}
+		if latest == "" || helpers.CompareVersions(release.Version, latest) > 0 {
+			latest = release.Version
+		}
+		if release.Security && helpers.CompareVersions(release.Version, version) > 0 {
+			missed = append(missed, release.Version)
+		}
+	}
+	if len(missed) == 0 {
+		return models.Vulnerability{}, false
+	}
+	slices.SortFunc(missed, helpers.CompareVersions)
+
+	return models.Vulnerability{
+		PackageName: "node",
+		Severity:    models.SeverityHigh,
+		CVEID:       NodeRuleVulnerable,
+		Title:       fmt.Sprintf("Node.js %s is missing %d security release(s)", strings.TrimPrefix(version, "v"), len(missed)),
+		Description: fmt.Sprintf("%s reports version %s; security releases %s of Node.js %d fix vulnerabilities in it.",
+			binary, version, strings.Join(missed, ", "), major),
+		Recommendation:     fmt.Sprintf("Upgrade node to %s, the latest Node.js %d release", latest, major),
+		VulnerableVersions: version,
+		PatchedVersions:    missed[0],
+		URL:                "https://nodejs.org/en/blog/vulnerability",
+	}, true
+}
+
+// checkNodeEngines reports a package.json engines.node range that allows an end-of-life
+// major. Ranges whose oldest major is the installed one are left to checkNodeEOL.
+func checkNodeEngines(content []byte, installedMajor int, now time.Time) (models.Vulnerability, bool, error) {
+	var manifest struct {
+		Engines map[string]any `json:"engines"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return models.Vulnerability{}, false, fmt.Errorf("failed to parse package.json: %w", err)
+	}
+
+	constraint, _ := manifest.Engines["node"].(string)
+	if constraint == "" {
+		return models.Vulnerability{}, false, nil
+	}
+	branch, ok := constraintMinimumBranch(constraint)
+	if !ok {
+		return models.Vulnerability{}, false, nil
+	}
+	major, _ := nodeMajor(branch)
+	if major == installedMajor {
+		return models.Vulnerability{}, false, nil
+	}
+	eol, known := nodeMajorEOL(major)
+	if !known || !now.After(eol) {
+		return models.Vulnerability{}, false, nil
+	}
+
+	return models.Vulnerability{
+		PackageName: "node",
+		Severity:    models.SeverityLow,
+		CVEID:       NodeRuleConstraintEOL,
+		Title:       fmt.Sprintf("package.json allows end-of-life Node.js %d", major),
+		Description: fmt.Sprintf("engines.node is %q, which allows Node.js %d (end-of-life since %s). The app can be "+
+			"deployed on a Node.js without security fixes.", constraint, major, eol.Format(time.DateOnly)),
+		Recommendation:     fmt.Sprintf("Raise the minimum Node.js version in package.json, e.g. \"node\": \">=%d\"", oldestSupportedNodeLTSMajor(now)),
+		VulnerableVersions: constraint,
+		URL:                "https://nodejs.org/en/about/previous-releases",
+	}, true, nil
+}
+
+// nodeMajor returns the major version of a node version ("v20.11.1" -> 20)
+func nodeMajor(version string) (int, bool) {
+	match := constraintVersionPart.FindStringSubmatch(version)
+	if match == nil {
+		return 0, false
+	}
+	major, err := strconv.Atoi(match[1])
+	return major, err == nil
+}
+
+// nodeMajorEOL returns the end of life of a Node.js major. Majors older than the
+// oldest known one ended before it; newer unknown majors are not known (false).
+func nodeMajorEOL(major int) (time.Time, bool) {
+	if date, ok := nodeEOL[major]; ok {
+		eol, err := time.Parse(time.DateOnly, date)
+		return eol, err == nil
+	}
+	if major < 8 {
+		eol, _ := time.Parse(time.DateOnly, nodeEOL[8])
+		return eol, true
+	}
+	return time.Time{}, false
+}
+
+// supportedNodeLTSMajorList returns the even (LTS) Node.js majors still receiving security fixes, oldest first
+func supportedNodeLTSMajorList(now time.Time) []int {
+	var majors []int
+	for major := range nodeEOL {
+		if eol, ok := nodeMajorEOL(major); ok && major%2 == 0 && now.Before(eol) {
+			majors = append(majors, major)
+		}
+	}
+	slices.Sort(majors)
+	return majors
+}
+
+// supportedNodeLTSMajors lists the supported LTS majors ("22, 24, 26")
+func supportedNodeLTSMajors(now time.Time) string {
+	var majors []string
+	for _, major := range supportedNodeLTSMajorList(now) {
+		majors = append(majors, strconv.Itoa(major))
+	}
+	return strings.Join(majors, ", ")
+}
+
+// oldestSupportedNodeLTSMajor returns the oldest LTS major still receiving security fixes
+func oldestSupportedNodeLTSMajor(now time.Time) int {
+	if majors := supportedNodeLTSMajorList(now); len(majors) > 0 {
+		return majors[0]
+	}
+	return 0
+}
+
+// nodeUpgradeRecommendation recommends a supported LTS major
+func nodeUpgradeRecommendation(now time.Time) string {
+	return fmt.Sprintf("Upgrade to a supported Node.js LTS release (%s) and update engines.node in package.json", supportedNodeLTSMajors(now))
+}
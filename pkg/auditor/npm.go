@@ -5,19 +5,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"os/exec"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/shadowbane/audit-checks/pkg/helpers"
 	"github.com/shadowbane/audit-checks/pkg/models"
-	"go.uber.org/zap"
 )
 
+// npmAuditLevels are the values accepted by npm's --audit-level
+var npmAuditLevels = []string{"info", "low", "moderate", "high", "critical", "none"}
+
+// npmOmitTypes are the dependency types accepted by npm's --omit
+var npmOmitTypes = []string{"dev", "optional", "peer"}
+
 // NPMAuditor implements the Auditor interface for npm projects
-type NPMAuditor struct{}
+type NPMAuditor struct {
+	options map[string]string // global npm audit options (audit-level, omit, registry, before, signatures)
+}
 
-// NewNPMAuditor creates a new NPMAuditor
-func NewNPMAuditor() *NPMAuditor {
-	return &NPMAuditor{}
+// NewNPMAuditor creates a new NPMAuditor. options are passed to npm audit as flags
+// and can be overridden per app with npm.<key>=<value> audit options.
+func NewNPMAuditor(options map[string]string) *NPMAuditor {
+	return &NPMAuditor{options: options}
 }
 
 // Name returns "npm"
@@ -25,20 +37,36 @@ func (a *NPMAuditor) Name() string {
 	return "npm"
 }
 
-// Detect checks for package.json or package-lock.json
+// Detect checks for package.json, package-lock.json or yarn.lock.
+// pnpm projects are left to the PnpmAuditor.
 func (a *NPMAuditor) Detect(path string) bool {
-	return FileExists(JoinPath(path, "package.json")) || FileExists(JoinPath(path, "package-lock.json"))
+	if isPnpmProject(path) && !FileExists(JoinPath(path, "package-lock.json")) {
+		return false
+	}
+
+	return FileExists(JoinPath(path, "package.json")) ||
+		FileExists(JoinPath(path, "package-lock.json")) ||
+		isYarnProject(path)
 }
 
-// Audit runs npm audit and parses the results
+// Audit runs npm audit (or yarn audit for yarn projects) and parses the results
 func (a *NPMAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
-	zap.S().Infof("Running npm audit for app=%s path=%s", app.Name, app.Path)
+	log := helpers.Logger(ctx)
+
+	// Yarn projects have no package-lock.json; npm audit would fail or audit the wrong tree
+	if isYarnProject(app.Path) {
+		return a.auditYarn(ctx, app)
+	}
+
+	log.Infof("Running npm audit for app=%s path=%s", app.Name, app.Path)
 
 	// Check if npm is available
 	if _, err := exec.LookPath("npm"); err != nil {
 		return nil, fmt.Errorf("npm not found in PATH: %w", err)
 	}
 
+	toolVersion := ToolVersion(ctx, "npm", "--version")
+
 	// Check if package.json exists
 	if !FileExists(JoinPath(app.Path, "package.json")) {
 		return nil, fmt.Errorf("package.json not found in %s", app.Path)
@@ -46,11 +74,21 @@ func (a *NPMAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.A
 
 	// Warn if lock file is missing (npm audit needs it, but will generate one)
 	if !FileExists(JoinPath(app.Path, "package-lock.json")) {
-		zap.S().Warnf("package-lock.json not found in %s, npm audit may fail or generate one", app.Path)
+		log.Warnf("package-lock.json not found in %s, npm audit may fail or generate one", app.Path)
+	}
+
+	options, err := AppOptions(a.Name(), a.options, app)
+	if err != nil {
+		return nil, err
+	}
+	args, err := npmAuditArgs(options)
+	if err != nil {
+		return nil, err
 	}
+	log.Debugf("npm audit args app=%s args=%v", app.Name, args)
 
 	// Run npm audit
-	cmd := exec.CommandContext(ctx, "npm", "audit", "--json")
+	cmd := exec.CommandContext(ctx, "npm", args...)
 	cmd.Dir = app.Path
 
 	var stdout, stderr bytes.Buffer
@@ -59,11 +97,12 @@ func (a *NPMAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.A
 
 	// npm audit returns non-zero exit code when vulnerabilities are found
 	// This is expected behavior, so we don't treat it as an error
-	err := cmd.Run()
+	exitCode := 0
+	err = cmd.Run()
 	if err != nil {
 		// Check if it's just because vulnerabilities were found (exit code 1)
 		if exitErr, ok := err.(*exec.ExitError); ok {
-			exitCode := exitErr.ExitCode()
+			exitCode = exitErr.ExitCode()
 			// npm audit returns 1 when vulnerabilities found, which is fine
 			if exitCode > 1 {
 				// Build error message from available output
@@ -82,30 +121,52 @@ func (a *NPMAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.A
 	}
 
 	// Parse the output
+	var result *models.AuditResult
 	output := stdout.String()
 	if strings.TrimSpace(output) == "" {
 		// No output likely means no vulnerabilities
-		zap.S().Debugf("npm audit returned empty output for app=%s", app.Name)
-		return &models.AuditResult{
+		log.Debugf("npm audit returned empty output for app=%s", app.Name)
+		result = &models.AuditResult{
 			Vulnerabilities: []models.Vulnerability{},
-			AuditorType:     a.Name(),
-			AppName:         app.Name,
-			AppPath:         app.Path,
-		}, nil
+		}
+	} else {
+		result, err = a.parseOutput(output, app)
+		if err != nil {
+			log.Debugf("npm audit raw output: %s", output)
+			return nil, fmt.Errorf("failed to parse npm audit output: %w", err)
+		}
 	}
 
-	result, err := a.parseOutput(output, app)
-	if err != nil {
-		zap.S().Debugf("npm audit raw output: %s", output)
-		return nil, fmt.Errorf("failed to parse npm audit output: %w", err)
+	// A result npm itself contradicts is a likely false negative
+	if result.Questionable == "" {
+		result.Questionable = npmQuestionable(output, exitCode)
+	}
+	if result.Questionable != "" {
+		log.Warnf("Questionable npm audit result for app=%s: %s", app.Name, result.Questionable)
+	}
+
+	// --audit-level only changes npm's exit code; apply it to the findings as well
+	if level := options["audit-level"]; level != "" && level != "none" {
+		result.Vulnerabilities = filterBelowSeverity(result.Vulnerabilities, level)
+		result.UpdateCounts()
+	}
+
+	// Optional supply-chain pass; its findings are informational and never fail the audit
+	if signatures, err := BoolOption(options, "signatures"); err != nil {
+		return nil, err
+	} else if signatures {
+		result.Vulnerabilities = append(result.Vulnerabilities, a.auditSignatures(ctx, app, options["registry"])...)
+		result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+		result.UpdateCounts()
 	}
 
 	result.RawOutput = output
 	result.AuditorType = a.Name()
+	result.ToolVersion = toolVersion
 	result.AppName = app.Name
 	result.AppPath = app.Path
 
-	zap.S().Infof("npm audit completed for app=%s total=%d critical=%d high=%d",
+	log.Infof("npm audit completed for app=%s total=%d critical=%d high=%d",
 		app.Name,
 		result.TotalVulnerabilities,
 		result.CriticalCount,
@@ -115,6 +176,147 @@ func (a *NPMAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.A
 	return result, nil
 }
 
+// npmSignaturesOutput represents the `npm audit signatures --json` output structure
+type npmSignaturesOutput struct {
+	Invalid []npmSignatureIssue `json:"invalid"`
+	Missing []npmSignatureIssue `json:"missing"`
+}
+
+type npmSignatureIssue struct {
+	Name     string `json:"name"`
+	Version  string `json:"version"`
+	Location string `json:"location"`
+	Registry string `json:"registry"`
+	Code     string `json:"code"`
+}
+
+// auditSignatures runs `npm audit signatures`, which verifies the registry signatures and
+// provenance attestations of the installed packages, and returns its issues as informational findings.
+// The pass needs node_modules; when it cannot run, a warning is logged and no findings are returned.
+func (a *NPMAuditor) auditSignatures(ctx context.Context, app models.AppConfig, registry string) []models.Vulnerability {
+	log := helpers.Logger(ctx)
+
+	args := []string{"audit", "signatures", "--json"}
+	if registry != "" {
+		args = append(args, "--registry="+registry)
+	}
+
+	cmd := exec.CommandContext(ctx, "npm", args...)
+	cmd.Dir = app.Path
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// Exit code 1 means invalid or missing signatures were found; the JSON output tells
+	runErr := cmd.Run()
+
+	var output npmSignaturesOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" && runErr != nil {
+			errMsg = runErr.Error()
+		}
+		log.Warnf("npm audit signatures failed for app=%s (are node_modules installed?): %s", app.Name, errMsg)
+		return nil
+	}
+
+	vulns := parseNpmSignatures(output, app)
+	log.Infof("npm audit signatures completed for app=%s invalid=%d missing=%d",
+		app.Name, len(output.Invalid), len(output.Missing))
+	return vulns
+}
+
+// parseNpmSignatures converts signature and attestation issues to informational findings
+func parseNpmSignatures(output npmSignaturesOutput, app models.AppConfig) []models.Vulnerability {
+	var vulns []models.Vulnerability
+
+	add := func(issue npmSignatureIssue, id, title, description string) {
+		if issue.Location != "" && IgnoredPaths(app, issue.Location) {
+			return
+		}
+		vulns = append(vulns, models.Vulnerability{
+			PackageName:        issue.Name,
+			Severity:           models.SeverityInfo,
+			CVEID:              id,
+			Title:              title,
+			Description:        description,
+			VulnerableVersions: issue.Version,
+			Recommendation: fmt.Sprintf("Reinstall %s@%s from the registry (npm ci) and compare its integrity with the "+
+				"lockfile; if the issue persists, report it to the registry before trusting the package.", issue.Name, issue.Version),
+		})
+	}
+
+	for _, issue := range output.Invalid {
+		if issue.Code == "EATTESTATIONVERIFY" {
+			add(issue, "PROVENANCE-INVALID", "Invalid provenance attestation",
+				fmt.Sprintf("The provenance attestation of %s@%s (%s) could not be verified against %s.",
+					issue.Name, issue.Version, issue.Location, issue.Registry))
+			continue
+		}
+		add(issue, "SIGNATURE-INVALID", "Invalid registry signature",
+			fmt.Sprintf("The registry signature of %s@%s (%s) does not match the signing keys of %s; the package may have been tampered with.",
+				issue.Name, issue.Version, issue.Location, issue.Registry))
+	}
+	for _, issue := range output.Missing {
+		add(issue, "SIGNATURE-MISSING", "Missing registry signature",
+			fmt.Sprintf("%s@%s (%s) has no signature although %s signs its packages.",
+				issue.Name, issue.Version, issue.Location, issue.Registry))
+	}
+
+	return vulns
+}
+
+// npmAuditArgs builds the npm audit arguments for the configured options
+func npmAuditArgs(options map[string]string) ([]string, error) {
+	args := []string{"audit", "--json"}
+
+	if level := options["audit-level"]; level != "" {
+		if !slices.Contains(npmAuditLevels, level) {
+			return nil, fmt.Errorf("invalid npm audit-level %q (use %s)", level, strings.Join(npmAuditLevels, ", "))
+		}
+		args = append(args, "--audit-level="+level)
+	}
+
+	// omit takes several types separated by spaces, e.g. "dev optional"
+	for _, omit := range strings.Fields(options["omit"]) {
+		if !slices.Contains(npmOmitTypes, omit) {
+			return nil, fmt.Errorf("invalid npm omit %q (use %s)", omit, strings.Join(npmOmitTypes, ", "))
+		}
+		args = append(args, "--omit="+omit)
+	}
+
+	if registry := options["registry"]; registry != "" {
+		if !strings.HasPrefix(registry, "http://") && !strings.HasPrefix(registry, "https://") {
+			return nil, fmt.Errorf("invalid npm registry %q (must be an http or https URL)", registry)
+		}
+		args = append(args, "--registry="+registry)
+	}
+
+	// before is checked here so a typo fails the audit instead of being ignored by npm
+	if before := options["before"]; before != "" {
+		if _, err := time.Parse("2006-01-02", before); err != nil {
+			if _, err := time.Parse(time.RFC3339, before); err != nil {
+				return nil, fmt.Errorf("invalid npm before %q (use a date like 2024-06-01 or RFC 3339)", before)
+			}
+		}
+		args = append(args, "--before="+before)
+	}
+
+	return args, nil
+}
+
+// filterBelowSeverity removes vulnerabilities below the given severity
+func filterBelowSeverity(vulns []models.Vulnerability, severity string) []models.Vulnerability {
+	filtered := make([]models.Vulnerability, 0, len(vulns))
+	for _, v := range vulns {
+		if models.MeetsSeverityThreshold(v.Severity, severity) {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
 // npmAuditOutput represents the npm audit JSON output structure
 type npmAuditOutput struct {
 	AuditReportVersion int                         `json:"auditReportVersion"`
@@ -122,6 +324,55 @@ type npmAuditOutput struct {
 	Metadata           npmMetadata                 `json:"metadata"`
 }
 
+// npmAuditHeader holds the keys telling a report apart from npm 6 output and errors
+type npmAuditHeader struct {
+	AuditReportVersion int             `json:"auditReportVersion"`
+	Advisories         json.RawMessage `json:"advisories"` // report version 1 (npm 6) only
+	Error              *npmError       `json:"error"`
+}
+
+// npmAuditV1Output is audit report version 1, printed by npm 6: one entry per advisory
+// instead of per package, with the fixes listed separately as actions
+type npmAuditV1Output struct {
+	Actions    []npmActionV1            `json:"actions"`
+	Advisories map[string]npmAdvisoryV1 `json:"advisories"`
+	Metadata   struct {
+		Vulnerabilities map[string]int `json:"vulnerabilities"` // vulnerable paths per severity
+	} `json:"metadata"`
+}
+
+type npmActionV1 struct {
+	Action   string `json:"action"` // install (direct dependency), update (transitive) or review (no fix)
+	Module   string `json:"module"`
+	Target   string `json:"target"`
+	IsMajor  bool   `json:"isMajor"`
+	Resolves []struct {
+		ID int `json:"id"`
+	} `json:"resolves"`
+}
+
+type npmAdvisoryV1 struct {
+	ID                 int      `json:"id"`
+	ModuleName         string   `json:"module_name"`
+	Title              string   `json:"title"`
+	Severity           string   `json:"severity"`
+	CVEs               []string `json:"cves"`
+	VulnerableVersions string   `json:"vulnerable_versions"`
+	PatchedVersions    string   `json:"patched_versions"`
+	URL                string   `json:"url"`
+	Findings           []struct {
+		Version string   `json:"version"`
+		Paths   []string `json:"paths"`
+	} `json:"findings"`
+}
+
+// npmError is the error npm 7+ prints as JSON instead of a report (e.g. ENOLOCK)
+type npmError struct {
+	Code    string `json:"code"`
+	Summary string `json:"summary"`
+	Detail  string `json:"detail"`
+}
+
 type npmVulnerability struct {
 	Name         string      `json:"name"`
 	Severity     string      `json:"severity"`
@@ -166,8 +417,82 @@ type npmMetadata struct {
 	} `json:"dependencies"`
 }
 
+// npmQuestionable returns why npm audit output may be a false negative given npm's exit
+// code: npm exited 1 (vulnerabilities found) without listing any. Returns "" otherwise.
+func npmQuestionable(output string, exitCode int) string {
+	if exitCode != 1 {
+		return ""
+	}
+	if strings.TrimSpace(output) == "" {
+		return "npm audit exited 1 (vulnerabilities found) without any output"
+	}
+
+	var listing struct {
+		Vulnerabilities map[string]json.RawMessage `json:"vulnerabilities"`
+		Advisories      map[string]json.RawMessage `json:"advisories"` // report version 1
+	}
+	if err := json.Unmarshal([]byte(output), &listing); err != nil {
+		return ""
+	}
+	if len(listing.Vulnerabilities) == 0 && len(listing.Advisories) == 0 {
+		return "npm audit exited 1 (vulnerabilities found) but lists no vulnerabilities"
+	}
+	return ""
+}
+
+// npmCountDiscrepancies cross-checks the vulnerabilities map against npm's own metadata
+// totals, per severity and overall. A mismatch means the report has entries (or a
+// severity) the parser does not account for, so findings may be missing.
+func npmCountDiscrepancies(report npmAuditOutput) []string {
+	listed := make(map[string]int)
+	for _, vuln := range report.Vulnerabilities {
+		listed[strings.ToLower(vuln.Severity)]++
+	}
+
+	totals := report.Metadata.Vulnerabilities
+	expected := []struct {
+		severity string
+		metadata int
+	}{
+		{"critical", totals.Critical},
+		{"high", totals.High},
+		{"moderate", totals.Moderate},
+		{"low", totals.Low},
+		{"info", totals.Info},
+	}
+
+	var discrepancies []string
+	for _, e := range expected {
+		if listed[e.severity] != e.metadata {
+			discrepancies = append(discrepancies, fmt.Sprintf("%s: metadata %d, listed %d", e.severity, e.metadata, listed[e.severity]))
+		}
+		delete(listed, e.severity)
+	}
+	for _, severity := range slices.Sorted(maps.Keys(listed)) {
+		discrepancies = append(discrepancies, fmt.Sprintf("unknown severity %q: listed %d", severity, listed[severity]))
+	}
+	if len(report.Vulnerabilities) != totals.Total {
+		discrepancies = append(discrepancies, fmt.Sprintf("total: metadata %d, listed %d", totals.Total, len(report.Vulnerabilities)))
+	}
+	return discrepancies
+}
+
 // parseOutput parses npm audit JSON output
 func (a *NPMAuditor) parseOutput(output string, app models.AppConfig) (*models.AuditResult, error) {
+	var header npmAuditHeader
+	if err := json.Unmarshal([]byte(output), &header); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// Neither an error nor a report version 1 has a "vulnerabilities" key, so they
+	// would otherwise parse as a clean audit
+	if header.Error != nil {
+		return nil, fmt.Errorf("npm audit error %s: %s", header.Error.Code, header.Error.Summary)
+	}
+	if header.AuditReportVersion < 2 && header.Advisories != nil {
+		return a.parseLegacyOutput(output, app)
+	}
+
 	var auditOutput npmAuditOutput
 	if err := json.Unmarshal([]byte(output), &auditOutput); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
@@ -179,8 +504,14 @@ func (a *NPMAuditor) parseOutput(output string, app models.AppConfig) (*models.A
 
 	// Process vulnerabilities
 	for pkgName, vuln := range auditOutput.Vulnerabilities {
+		// Skip packages only installed under ignored paths (e.g. examples/demo/node_modules)
+		if IgnoredPaths(app, vuln.Nodes...) {
+			continue
+		}
+
 		// Extract details from "via" field
 		var title, description, url, cveID, patchedVersions string
+		var advisoryRanges []string
 
 		for _, v := range vuln.Via {
 			// Via can be either a string (package name) or an object
@@ -202,8 +533,11 @@ func (a *NPMAuditor) parseOutput(output string, app models.AppConfig) (*models.A
 						}
 					}
 				}
-				if r, ok := via["range"].(string); ok && description == "" {
-					description = fmt.Sprintf("Vulnerable versions: %s", r)
+				if r, ok := via["range"].(string); ok {
+					advisoryRanges = append(advisoryRanges, r)
+					if description == "" {
+						description = fmt.Sprintf("Vulnerable versions: %s", r)
+					}
 				}
 			case string:
 				// This is just a reference to another package
@@ -222,8 +556,16 @@ func (a *NPMAuditor) parseOutput(output string, app models.AppConfig) (*models.A
 			patchedVersions = "Fix available (run npm audit fix)"
 		}
 
+		// Transitive packages without a direct fix can be pinned with an override
+		var overrideVersion, fixSnippet string
+		if !vuln.IsDirect && !npmDirectFixAvailable(vuln) {
+			if overrideVersion = npmPatchedVersion(advisoryRanges); overrideVersion != "" {
+				fixSnippet = fmt.Sprintf("\"overrides\": {\n  %q: %q\n}", pkgName, "^"+overrideVersion)
+			}
+		}
+
 		// Build recommendation
-		recommendation := buildNpmRecommendation(pkgName, vuln, patchedVersions)
+		recommendation := buildNpmRecommendation(pkgName, vuln, patchedVersions, overrideVersion)
 
 		vulnerability := models.Vulnerability{
 			PackageName:        pkgName,
@@ -235,6 +577,93 @@ func (a *NPMAuditor) parseOutput(output string, app models.AppConfig) (*models.A
 			VulnerableVersions: vuln.Range,
 			PatchedVersions:    patchedVersions,
 			URL:                url,
+			FixSnippet:         fixSnippet,
+		}
+
+		result.Vulnerabilities = append(result.Vulnerabilities, vulnerability)
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	// Cross-check against npm's own totals; a mismatch points at entries the parser missed
+	if discrepancies := npmCountDiscrepancies(auditOutput); len(discrepancies) > 0 {
+		result.Questionable = "npm audit metadata disagrees with the listed vulnerabilities (" +
+			strings.Join(discrepancies, "; ") + ")"
+	}
+
+	return result, nil
+}
+
+// parseLegacyOutput parses npm audit report version 1 (npm 6), one finding per advisory.
+// The report has dependency paths (mkdirp>minimist) instead of node_modules locations,
+// so ignore paths do not apply.
+func (a *NPMAuditor) parseLegacyOutput(output string, app models.AppConfig) (*models.AuditResult, error) {
+	var auditOutput npmAuditV1Output
+	if err := json.Unmarshal([]byte(output), &auditOutput); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// Fixes are listed per action, each resolving advisories by ID
+	actions := make(map[int]npmActionV1)
+	for _, action := range auditOutput.Actions {
+		for _, resolve := range action.Resolves {
+			if _, ok := actions[resolve.ID]; !ok || action.Action != "review" {
+				actions[resolve.ID] = action
+			}
+		}
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+
+	advisories := slices.Collect(maps.Values(auditOutput.Advisories))
+	slices.SortFunc(advisories, func(x, y npmAdvisoryV1) int { return x.ID - y.ID })
+
+	// Process advisories
+	for _, advisory := range advisories {
+		var installed, paths []string
+		direct := false
+		for _, finding := range advisory.Findings {
+			if !slices.Contains(installed, finding.Version) {
+				installed = append(installed, finding.Version)
+			}
+			for _, path := range finding.Paths {
+				paths = append(paths, path)
+				direct = direct || !strings.Contains(path, ">")
+			}
+		}
+
+		var cveID string
+		if len(advisory.CVEs) > 0 {
+			cveID = advisory.CVEs[0]
+		}
+
+		description := fmt.Sprintf("Vulnerable versions: %s", advisory.VulnerableVersions)
+		if len(installed) > 0 {
+			description += fmt.Sprintf(". Installed: %s via %s", strings.Join(installed, ", "), strings.Join(paths, ", "))
+		}
+
+		// "<0.0.0" means no version is patched
+		patchedVersions := advisory.PatchedVersions
+		if patchedVersions == "<0.0.0" {
+			patchedVersions = ""
+		}
+
+		vulnerability := models.Vulnerability{
+			PackageName:        advisory.ModuleName,
+			Severity:           normalizeSeverity(advisory.Severity),
+			CVEID:              cveID,
+			Title:              advisory.Title,
+			Description:        description,
+			Recommendation:     buildNpmLegacyRecommendation(advisory.ModuleName, actions[advisory.ID], patchedVersions, direct),
+			VulnerableVersions: advisory.VulnerableVersions,
+			PatchedVersions:    patchedVersions,
+			URL:                advisory.URL,
 		}
 
 		result.Vulnerabilities = append(result.Vulnerabilities, vulnerability)
@@ -246,18 +675,84 @@ func (a *NPMAuditor) parseOutput(output string, app models.AppConfig) (*models.A
 	// Update counts
 	result.UpdateCounts()
 
+	// Cross-check against npm's own totals; a mismatch points at advisories the parser missed
+	if discrepancies := npmLegacyCountDiscrepancies(auditOutput); len(discrepancies) > 0 {
+		result.Questionable = "npm audit metadata disagrees with the listed advisories (" +
+			strings.Join(discrepancies, "; ") + ")"
+	}
+
 	return result, nil
 }
 
+// npmLegacyCountDiscrepancies cross-checks the advisories of a report version 1 against its
+// metadata. The metadata counts vulnerable paths rather than advisories, so only a severity
+// listed on one side and missing on the other is a discrepancy.
+func npmLegacyCountDiscrepancies(report npmAuditV1Output) []string {
+	listed := make(map[string]int)
+	for _, advisory := range report.Advisories {
+		listed[strings.ToLower(advisory.Severity)]++
+	}
+
+	severities := slices.Sorted(maps.Keys(listed))
+	for severity := range report.Metadata.Vulnerabilities {
+		if _, ok := listed[severity]; !ok {
+			severities = append(severities, severity)
+		}
+	}
+	slices.Sort(severities)
+
+	var discrepancies []string
+	for _, severity := range severities {
+		paths := report.Metadata.Vulnerabilities[severity]
+		if (paths > 0) != (listed[severity] > 0) {
+			discrepancies = append(discrepancies, fmt.Sprintf("%s: metadata %d path(s), listed %d advisory(ies)", severity, paths, listed[severity]))
+		}
+	}
+	return discrepancies
+}
+
+// buildNpmLegacyRecommendation creates a recommendation message from an npm 6 audit action
+func buildNpmLegacyRecommendation(pkgName string, action npmActionV1, patchedVersions string, direct bool) string {
+	var rec strings.Builder
+
+	if patchedVersions != "" {
+		rec.WriteString(fmt.Sprintf("Update %s to version %s. ", pkgName, patchedVersions))
+	}
+
+	switch action.Action {
+	case "install":
+		rec.WriteString(fmt.Sprintf("Run 'npm install %s@%s'", action.Module, action.Target))
+		if action.IsMajor {
+			rec.WriteString(" (a breaking change)")
+		}
+		rec.WriteString(". ")
+	case "update":
+		rec.WriteString("Run 'npm audit fix' to automatically update. ")
+	default:
+		rec.WriteString("No automatic fix available. Manual intervention required. ")
+	}
+
+	if direct {
+		rec.WriteString("This is a direct dependency.")
+	} else {
+		rec.WriteString("This is a transitive dependency.")
+	}
+
+	return rec.String()
+}
+
 // buildNpmRecommendation creates a recommendation message
-func buildNpmRecommendation(pkgName string, vuln npmVulnerability, patchedVersions string) string {
+func buildNpmRecommendation(pkgName string, vuln npmVulnerability, patchedVersions, overrideVersion string) string {
 	var rec strings.Builder
 
 	if patchedVersions != "" {
 		rec.WriteString(fmt.Sprintf("Update %s to version %s. ", pkgName, patchedVersions))
 	}
 
-	if vuln.FixAvailable != nil && vuln.FixAvailable != false {
+	if overrideVersion != "" {
+		rec.WriteString(fmt.Sprintf("No direct fix available: pin %s to ^%s with the suggested \"overrides\" entry in package.json (npm 8.3+), then run 'npm install'. ",
+			pkgName, overrideVersion))
+	} else if vuln.FixAvailable != nil && vuln.FixAvailable != false {
 		rec.WriteString("Run 'npm audit fix' to automatically update. ")
 	} else {
 		rec.WriteString("No automatic fix available. Manual intervention required. ")
@@ -272,6 +767,44 @@ func buildNpmRecommendation(pkgName string, vuln npmVulnerability, patchedVersio
 	return rec.String()
 }
 
+// npmDirectFixAvailable reports whether npm can fix a vulnerability without a breaking
+// change, i.e. 'npm audit fix' works without --force
+func npmDirectFixAvailable(vuln npmVulnerability) bool {
+	switch fix := vuln.FixAvailable.(type) {
+	case bool:
+		return fix
+	case map[string]interface{}:
+		major, _ := fix["isSemVerMajor"].(bool)
+		return !major
+	default:
+		return false
+	}
+}
+
+// npmPatchedVersion returns the lowest version above all vulnerable ranges of an
+// advisory ("<4.17.21" gives 4.17.21), or "" when a range has no exclusive upper
+// bound ("<=1.2.3" or "*"), in which case no patched version is known
+func npmPatchedVersion(ranges []string) string {
+	patched := ""
+	for _, r := range ranges {
+		for _, alternative := range strings.Split(r, "||") {
+			upper := ""
+			for _, comparator := range strings.Fields(alternative) {
+				if v, ok := strings.CutPrefix(comparator, "<"); ok && !strings.HasPrefix(v, "=") {
+					upper = v
+				}
+			}
+			if upper == "" {
+				return ""
+			}
+			if patched == "" || helpers.CompareVersions(upper, patched) > 0 {
+				patched = upper
+			}
+		}
+	}
+	return patched
+}
+
 // normalizeSeverity normalizes severity strings to standard values
 func normalizeSeverity(severity string) string {
 	switch strings.ToLower(severity) {
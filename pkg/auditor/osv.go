@@ -0,0 +1,269 @@
+package auditor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// osvScannerNoPackagesExitCode is returned by osv-scanner when no supported manifest or lockfile was found
+const osvScannerNoPackagesExitCode = 128
+
+// osvScannerOutput represents the osv-scanner JSON output structure
+type osvScannerOutput struct {
+	Results []struct {
+		Source struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+		} `json:"source"`
+		Packages []osvPackageResult `json:"packages"`
+	} `json:"results"`
+}
+
+type osvPackageResult struct {
+	Package struct {
+		Name      string `json:"name"`
+		Version   string `json:"version"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Vulnerabilities []osvVulnerability `json:"vulnerabilities"`
+	Groups          []struct {
+		IDs         []string `json:"ids"`
+		MaxSeverity string   `json:"max_severity"`
+	} `json:"groups"`
+}
+
+type osvVulnerability struct {
+	ID       string   `json:"id"`
+	Modified string   `json:"modified"`
+	Aliases  []string `json:"aliases"`
+	Summary  string   `json:"summary"`
+	Details  string   `json:"details"`
+	Affected []struct {
+		Package struct {
+			Name      string `json:"name"`
+			Ecosystem string `json:"ecosystem"`
+		} `json:"package"`
+		Ranges []struct {
+			Type   string              `json:"type"`
+			Events []map[string]string `json:"events"`
+		} `json:"ranges"`
+		Versions []string `json:"versions"` // affected versions, enumerated by OSV.dev
+	} `json:"affected"`
+	Severity []struct {
+		Type  string `json:"type"`
+		Score string `json:"score"`
+	} `json:"severity"`
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+	References []struct {
+		Type string `json:"type"`
+		URL  string `json:"url"`
+	} `json:"references"`
+}
+
+// osvRecommender builds the recommendation for a vulnerable package from its fixed versions
+type osvRecommender func(name, version string, fixed []string) string
+
+// runOSVScanner runs osv-scanner recursively in dir and returns its JSON output.
+// osv-scanner exits with 1 when vulnerabilities are found, so that is not an error.
+func runOSVScanner(ctx context.Context, dir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "osv-scanner", "--format", "json", "--recursive", ".")
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return "", fmt.Errorf("failed to run osv-scanner: %w", err)
+		}
+		if exitErr.ExitCode() == osvScannerNoPackagesExitCode {
+			return "", fmt.Errorf("osv-scanner found no supported manifest or lockfile in %s", dir)
+		}
+	}
+
+	output := stdout.String()
+	if strings.TrimSpace(output) == "" {
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" {
+			errMsg = "no output"
+		}
+		return "", fmt.Errorf("osv-scanner failed: %s", errMsg)
+	}
+
+	return output, nil
+}
+
+// parseOSVScannerOutput converts osv-scanner JSON output into vulnerabilities.
+// Only packages whose source manifest and ecosystem pass include are kept.
+// Aliased advisories (e.g. a GHSA and its CVE) are reported once per package.
+func parseOSVScannerOutput(output string, include func(source, ecosystem string) bool, recommend osvRecommender) ([]models.Vulnerability, error) {
+	var scanOutput osvScannerOutput
+	if err := json.Unmarshal([]byte(output), &scanOutput); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	vulns := make([]models.Vulnerability, 0)
+	seen := make(map[string]bool)
+
+	for _, result := range scanOutput.Results {
+		for _, pkg := range result.Packages {
+			if !include(result.Source.Path, pkg.Package.Ecosystem) {
+				continue
+			}
+
+			for _, group := range osvGroups(pkg) {
+				vuln, ok := osvGroupVulnerability(pkg, group.ids)
+				if !ok {
+					continue
+				}
+
+				v := osvToVulnerability(vuln, pkg, group.maxSeverity, recommend)
+
+				// The same package can appear in several manifests of a multi-module project
+				key := v.PackageName + "@" + pkg.Package.Version + "|" + v.CVEID
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				vulns = append(vulns, v)
+			}
+		}
+	}
+
+	return vulns, nil
+}
+
+type osvGroup struct {
+	ids         []string
+	maxSeverity string
+}
+
+// osvGroups returns the alias groups of a package, or one group per
+// vulnerability when osv-scanner did not report any
+func osvGroups(pkg osvPackageResult) []osvGroup {
+	groups := make([]osvGroup, 0, len(pkg.Groups))
+	for _, g := range pkg.Groups {
+		groups = append(groups, osvGroup{ids: g.IDs, maxSeverity: g.MaxSeverity})
+	}
+	if len(groups) > 0 {
+		return groups
+	}
+
+	for _, v := range pkg.Vulnerabilities {
+		groups = append(groups, osvGroup{ids: []string{v.ID}})
+	}
+	return groups
+}
+
+// osvGroupVulnerability returns the first vulnerability of the package that belongs to the group
+func osvGroupVulnerability(pkg osvPackageResult, ids []string) (osvVulnerability, bool) {
+	for _, v := range pkg.Vulnerabilities {
+		for _, id := range ids {
+			if v.ID == id {
+				return v, true
+			}
+		}
+	}
+	return osvVulnerability{}, false
+}
+
+// osvToVulnerability converts an OSV advisory to a Vulnerability
+func osvToVulnerability(vuln osvVulnerability, pkg osvPackageResult, maxSeverity string, recommend osvRecommender) models.Vulnerability {
+	cveID := vuln.ID
+	if !strings.HasPrefix(cveID, "CVE-") {
+		for _, alias := range vuln.Aliases {
+			if strings.HasPrefix(alias, "CVE-") {
+				cveID = alias
+				break
+			}
+		}
+	}
+
+	url := "https://osv.dev/vulnerability/" + vuln.ID
+	for _, ref := range vuln.References {
+		if ref.Type == "ADVISORY" && ref.URL != "" {
+			url = ref.URL
+			break
+		}
+	}
+
+	title := vuln.Summary
+	if title == "" {
+		title = vuln.ID
+	}
+
+	description := fmt.Sprintf("Advisory: %s.", vuln.ID)
+	if vuln.Details != "" {
+		description = fmt.Sprintf("%s %s", description, strings.TrimSpace(vuln.Details))
+	}
+
+	fixed := osvFixedVersions(vuln, pkg.Package.Name)
+
+	return models.Vulnerability{
+		PackageName:        pkg.Package.Name,
+		Severity:           osvSeverity(vuln, maxSeverity),
+		CVEID:              cveID,
+		Title:              title,
+		Description:        description,
+		Recommendation:     recommend(pkg.Package.Name, pkg.Package.Version, fixed),
+		VulnerableVersions: pkg.Package.Version,
+		PatchedVersions:    strings.Join(fixed, ", "),
+		URL:                url,
+	}
+}
+
+// osvFixedVersions collects the versions that fix the vulnerability for the named package
+func osvFixedVersions(vuln osvVulnerability, name string) []string {
+	var fixed []string
+	seen := make(map[string]bool)
+
+	for _, affected := range vuln.Affected {
+		if affected.Package.Name != name {
+			continue
+		}
+		for _, r := range affected.Ranges {
+			for _, event := range r.Events {
+				if v := event["fixed"]; v != "" && !seen[v] {
+					seen[v] = true
+					fixed = append(fixed, v)
+				}
+			}
+		}
+	}
+
+	return fixed
+}
+
+// osvSeverity derives a severity from the group's highest CVSS score, falling
+// back to the GitHub advisory severity. Unscored advisories are treated as moderate.
+func osvSeverity(vuln osvVulnerability, maxSeverity string) string {
+	if score, err := strconv.ParseFloat(maxSeverity, 64); err == nil {
+		return severityFromCVSSScore(score)
+	}
+
+	switch strings.ToLower(vuln.DatabaseSpecific.Severity) {
+	case "critical":
+		return models.SeverityCritical
+	case "high":
+		return models.SeverityHigh
+	case "moderate", "medium":
+		return models.SeverityModerate
+	case "low":
+		return models.SeverityLow
+	default:
+		return models.SeverityModerate
+	}
+}
@@ -0,0 +1,794 @@
+package auditor
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	osvAPIURL = "https://api.osv.dev/v1"
+	// osvBatchSize is the maximum number of queries accepted by /v1/querybatch
+	osvBatchSize = 1000
+)
+
+// OSV ecosystems of the supported lockfiles
+const (
+	osvEcosystemNpm       = "npm"
+	osvEcosystemPackagist = "Packagist"
+	osvEcosystemPub       = "Pub"
+	osvEcosystemGo        = "Go"
+)
+
+// errNoLockfile is returned for apps without a package-lock.json or composer.lock
+var errNoLockfile = errors.New("no package-lock.json or composer.lock found")
+
+// OSVAuditor implements the Auditor interface by reading package-lock.json and
+// composer.lock directly and querying the OSV.dev API, so npm and composer do not
+// need to be installed. It is never auto-detected; add an app with --type osv.
+type OSVAuditor struct {
+	apiURL   string
+	feedURL  string
+	cacheDir string
+	cacheTTL time.Duration
+	client   *http.Client
+}
+
+// NewOSVAuditor creates a new OSVAuditor. Query results are cached in cacheDir
+// for cacheTTL; advisories are cached until OSV reports them as modified.
+// Caching is disabled when cacheDir is empty.
+func NewOSVAuditor(cacheDir string, cacheTTL time.Duration) *OSVAuditor {
+	return &OSVAuditor{
+		apiURL:   osvAPIURL,
+		feedURL:  osvFeedURL,
+		cacheDir: cacheDir,
+		cacheTTL: cacheTTL,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns "osv"
+func (a *OSVAuditor) Name() string {
+	return "osv"
+}
+
+// Detect always returns false: the app's native auditors are preferred, so the
+// OSV auditor must be selected explicitly
+func (a *OSVAuditor) Detect(path string) bool {
+	return false
+}
+
+// osvLockfilePackage is a package version read from a lockfile
+type osvLockfilePackage struct {
+	Name      string
+	Version   string
+	Ecosystem string
+	Dev       bool
+	License   string // SPDX expression, when the lockfile records it
+	Lockfile  string
+	Paths     []string // install paths, when the lockfile records them
+	Requires  []string // name@version of the packages it requires, when the lockfile records them
+}
+
+// Audit parses the app's lockfiles and looks up their packages on OSV.dev
+func (a *OSVAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running osv audit for app=%s path=%s", app.Name, app.Path)
+
+	packages, err := readLockfilePackages(app.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Skip packages only installed under ignored paths (e.g. examples/demo/node_modules)
+	kept := packages[:0]
+	for _, pkg := range packages {
+		if !IgnoredPaths(app, pkg.Paths...) {
+			kept = append(kept, pkg)
+		}
+	}
+	packages = kept
+
+	vulns, raw, err := a.lookupPackages(ctx, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: vulns,
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	rawOutput, _ := json.Marshal(raw)
+	result.RawOutput = string(rawOutput)
+	result.AuditorType = a.Name()
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("osv audit completed for app=%s packages=%d total=%d critical=%d high=%d",
+		app.Name,
+		len(packages),
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// lookupPackages queries OSV.dev for the packages and converts the advisories into
+// vulnerabilities. It also returns the advisories, which are kept as the raw output.
+func (a *OSVAuditor) lookupPackages(ctx context.Context, packages []osvLockfilePackage) ([]models.Vulnerability, []osvVulnerability, error) {
+	vulnIDs, err := a.queryPackages(ctx, packages)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vulns := make([]models.Vulnerability, 0)
+	var raw []osvVulnerability
+
+	for i, pkg := range packages {
+		// Aliased advisories (e.g. a GHSA and its CVE) are reported once per package
+		seen := make(map[string]bool)
+
+		for _, entry := range vulnIDs[i] {
+			if seen[entry.ID] {
+				continue
+			}
+
+			vuln, err := a.fetchVulnerability(ctx, entry)
+			if err != nil {
+				return nil, nil, err
+			}
+			raw = append(raw, vuln)
+
+			seen[vuln.ID] = true
+			for _, alias := range vuln.Aliases {
+				seen[alias] = true
+			}
+
+			var pkgResult osvPackageResult
+			pkgResult.Package.Name = pkg.Name
+			pkgResult.Package.Version = pkg.Version
+			pkgResult.Package.Ecosystem = pkg.Ecosystem
+
+			v := osvToVulnerability(vuln, pkgResult, osvMaxCVSSScore(vuln), osvLockfileRecommender(pkg))
+			if pkg.Dev {
+				v.Description += " (development dependency)"
+			}
+			vulns = append(vulns, v)
+		}
+	}
+
+	return vulns, raw, nil
+}
+
+// readLockfilePackages reads the packages of every supported lockfile in dir
+func readLockfilePackages(dir string) ([]osvLockfilePackage, error) {
+	var packages []osvLockfilePackage
+	found := false
+
+	for _, lockfile := range []struct {
+		name  string
+		parse func([]byte) ([]osvLockfilePackage, error)
+	}{
+		{"package-lock.json", parseNpmLockfile},
+		{"composer.lock", parseComposerLockfile},
+	} {
+		content, err := os.ReadFile(JoinPath(dir, lockfile.name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", lockfile.name, err)
+		}
+		found = true
+
+		pkgs, err := lockfile.parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", lockfile.name, err)
+		}
+		for i := range pkgs {
+			pkgs[i].Lockfile = lockfile.name
+		}
+		packages = append(packages, pkgs...)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("%w in %s", errNoLockfile, dir)
+	}
+
+	return packages, nil
+}
+
+// InstalledPackages returns the packages of the app's package-lock.json and composer.lock,
+// the inventory the advisory watcher matches new advisories against. Packages only
+// installed under ignored paths are left out; apps without these lockfiles have none.
+func InstalledPackages(app models.AppConfig) ([]models.AppPackage, error) {
+	packages, err := readLockfilePackages(app.Path)
+	if errors.Is(err, errNoLockfile) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var installed []models.AppPackage
+	for _, pkg := range packages {
+		key := pkg.Lockfile + "\x00" + pkg.Name + "\x00" + pkg.Version
+		if seen[key] || IgnoredPaths(app, pkg.Paths...) {
+			continue
+		}
+		seen[key] = true
+		installed = append(installed, models.AppPackage{
+			AppName:   app.Name,
+			Ecosystem: pkg.Ecosystem,
+			Name:      pkg.Name,
+			Version:   pkg.Version,
+			Lockfile:  pkg.Lockfile,
+		})
+	}
+	return installed, nil
+}
+
+// npmLockfile represents the parts of package-lock.json used by the auditor.
+// Version 2 and 3 lockfiles list packages by install path; version 1 nests dependencies.
+type npmLockfile struct {
+	LockfileVersion int                            `json:"lockfileVersion"`
+	Packages        map[string]npmLockfilePackage  `json:"packages"`
+	Dependencies    map[string]npmLockfileV1Module `json:"dependencies"`
+}
+
+type npmLockfilePackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Dev                  bool              `json:"dev"`
+	Link                 bool              `json:"link"`
+	HasInstallScript     bool              `json:"hasInstallScript"`
+	License              lockfileLicense   `json:"license"`
+	Dependencies         map[string]string `json:"dependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+type npmLockfileV1Module struct {
+	Version      string                         `json:"version"`
+	Dev          bool                           `json:"dev"`
+	Requires     map[string]string              `json:"requires"`
+	Dependencies map[string]npmLockfileV1Module `json:"dependencies"`
+}
+
+// parseNpmLockfile lists the installed packages of a package-lock.json, once per name and version
+func parseNpmLockfile(content []byte) ([]osvLockfilePackage, error) {
+	var lock npmLockfile
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	seen := make(map[string]int)
+	var packages []osvLockfilePackage
+	add := func(name, version, path string, dev bool, license string, requires []string) {
+		// Skip workspaces, links and git/file dependencies without a registry version
+		if !npmRegistryVersion(name, version) {
+			return
+		}
+		key := name + "@" + version
+		if i, ok := seen[key]; ok {
+			// A package installed several times is only a dev dependency if every copy is
+			packages[i].Dev = packages[i].Dev && dev
+			if path != "" {
+				packages[i].Paths = append(packages[i].Paths, path)
+			}
+			for _, req := range requires {
+				if !slices.Contains(packages[i].Requires, req) {
+					packages[i].Requires = append(packages[i].Requires, req)
+				}
+			}
+			return
+		}
+		seen[key] = len(packages)
+		pkg := osvLockfilePackage{Name: name, Version: version, Ecosystem: osvEcosystemNpm, Dev: dev, License: license, Requires: requires}
+		if path != "" {
+			pkg.Paths = []string{path}
+		}
+		packages = append(packages, pkg)
+	}
+
+	if len(lock.Packages) > 0 {
+		for path, pkg := range lock.Packages {
+			if path == "" || pkg.Link {
+				continue
+			}
+			name := npmPackageName(path, pkg)
+			if name == "" {
+				// Workspace package
+				continue
+			}
+
+			var requires []string
+			for _, deps := range []map[string]string{pkg.Dependencies, pkg.OptionalDependencies, pkg.PeerDependencies} {
+				for depName := range deps {
+					depPath := npmResolvePath(lock.Packages, path, depName)
+					if depPath == "" {
+						continue
+					}
+					dep := lock.Packages[depPath]
+					if ref := npmPackageName(depPath, dep) + "@" + dep.Version; npmRegistryVersion(depName, dep.Version) && !slices.Contains(requires, ref) {
+						requires = append(requires, ref)
+					}
+				}
+			}
+
+			add(name, pkg.Version, path, pkg.Dev, string(pkg.License), requires)
+		}
+	} else {
+		// Version 1 resolves requirements against the enclosing dependencies maps, innermost first
+		var walk func(deps map[string]npmLockfileV1Module, scopes []map[string]npmLockfileV1Module)
+		walk = func(deps map[string]npmLockfileV1Module, scopes []map[string]npmLockfileV1Module) {
+			for name, dep := range deps {
+				depScopes := append([]map[string]npmLockfileV1Module{dep.Dependencies}, scopes...)
+
+				var requires []string
+				for depName := range dep.Requires {
+					for _, scope := range depScopes {
+						if required, ok := scope[depName]; ok {
+							if npmRegistryVersion(depName, required.Version) {
+								requires = append(requires, depName+"@"+required.Version)
+							}
+							break
+						}
+					}
+				}
+
+				add(name, dep.Version, "", dep.Dev, "", requires)
+				walk(dep.Dependencies, depScopes)
+			}
+		}
+		walk(lock.Dependencies, []map[string]npmLockfileV1Module{lock.Dependencies})
+	}
+
+	for i := range packages {
+		slices.Sort(packages[i].Requires)
+	}
+	sortLockfilePackages(packages)
+	return packages, nil
+}
+
+// npmRegistryVersion returns true if version is a registry version rather than a git,
+// file or workspace reference
+func npmRegistryVersion(name, version string) bool {
+	return name != "" && version != "" && !strings.Contains(version, ":")
+}
+
+// npmPackageName returns the name of the package installed at path, or an empty
+// string for workspace packages outside node_modules
+func npmPackageName(path string, pkg npmLockfilePackage) string {
+	i := strings.LastIndex(path, "node_modules/")
+	if i < 0 {
+		return ""
+	}
+	if pkg.Name != "" {
+		return pkg.Name
+	}
+	return path[i+len("node_modules/"):]
+}
+
+// npmResolvePath returns the install path the package at from loads name from, following
+// Node's lookup through the enclosing node_modules directories, or an empty string
+func npmResolvePath(packages map[string]npmLockfilePackage, from, name string) string {
+	dir := from
+	for {
+		candidate := "node_modules/" + name
+		if dir != "" {
+			candidate = dir + "/" + candidate
+		}
+		if _, ok := packages[candidate]; ok {
+			return candidate
+		}
+		if dir == "" {
+			return ""
+		}
+		i := strings.LastIndex(dir, "node_modules/")
+		if i < 0 {
+			// Workspace packages fall back to the root node_modules
+			dir = ""
+			continue
+		}
+		dir = strings.TrimSuffix(dir[:i], "/")
+	}
+}
+
+// lockfileLicense is the license of a package-lock.json entry: an SPDX expression, or
+// the {"type": ...} object and arrays of old packages. Unknown forms are left empty
+// rather than failing the whole lockfile.
+type lockfileLicense string
+
+func (l *lockfileLicense) UnmarshalJSON(data []byte) error {
+	var expression string
+	if err := json.Unmarshal(data, &expression); err == nil {
+		*l = lockfileLicense(expression)
+		return nil
+	}
+
+	type licenseObject struct {
+		Type string `json:"type"`
+	}
+	var object licenseObject
+	if err := json.Unmarshal(data, &object); err == nil {
+		*l = lockfileLicense(object.Type)
+		return nil
+	}
+
+	var objects []licenseObject
+	if err := json.Unmarshal(data, &objects); err == nil {
+		types := make([]string, 0, len(objects))
+		for _, o := range objects {
+			if o.Type != "" {
+				types = append(types, o.Type)
+			}
+		}
+		*l = lockfileLicense(strings.Join(types, " OR "))
+	}
+	return nil
+}
+
+// composerLockfile represents the parts of composer.lock used by the auditor
+type composerLockfile struct {
+	Packages    []composerLockfilePackage `json:"packages"`
+	PackagesDev []composerLockfilePackage `json:"packages-dev"`
+}
+
+type composerLockfilePackage struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	License []string          `json:"license"`
+	Require map[string]string `json:"require"`
+}
+
+// parseComposerLockfile lists the packages of a composer.lock
+func parseComposerLockfile(content []byte) ([]osvLockfilePackage, error) {
+	var lock composerLockfile
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	// Composer installs a single version of each package
+	versions := make(map[string]string)
+	for _, pkg := range append(slices.Clone(lock.Packages), lock.PackagesDev...) {
+		if pkg.Name != "" && !strings.HasPrefix(pkg.Version, "dev-") {
+			versions[strings.ToLower(pkg.Name)] = pkg.Name + "@" + strings.TrimPrefix(pkg.Version, "v")
+		}
+	}
+
+	var packages []osvLockfilePackage
+	for _, group := range []struct {
+		packages []composerLockfilePackage
+		dev      bool
+	}{
+		{lock.Packages, false},
+		{lock.PackagesDev, true},
+	} {
+		for _, pkg := range group.packages {
+			// Branch aliases (dev-main) have no released version to look up
+			if pkg.Name == "" || strings.HasPrefix(pkg.Version, "dev-") {
+				continue
+			}
+
+			// Platform requirements (php, ext-*) are not packages
+			var requires []string
+			for name := range pkg.Require {
+				if ref, ok := versions[strings.ToLower(name)]; ok {
+					requires = append(requires, ref)
+				}
+			}
+			slices.Sort(requires)
+
+			packages = append(packages, osvLockfilePackage{
+				Name:      pkg.Name,
+				Version:   strings.TrimPrefix(pkg.Version, "v"),
+				Ecosystem: osvEcosystemPackagist,
+				Dev:       group.dev,
+				License:   strings.Join(pkg.License, " OR "),
+				Requires:  requires,
+			})
+		}
+	}
+
+	sortLockfilePackages(packages)
+	return packages, nil
+}
+
+// sortLockfilePackages sorts packages by name and version for stable output
+func sortLockfilePackages(packages []osvLockfilePackage) {
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].Name != packages[j].Name {
+			return packages[i].Name < packages[j].Name
+		}
+		return packages[i].Version < packages[j].Version
+	})
+}
+
+// osvVulnEntry is an advisory ID returned by a query, with its last modification time
+type osvVulnEntry struct {
+	ID       string `json:"id"`
+	Modified string `json:"modified"`
+}
+
+type osvQuery struct {
+	Package struct {
+		Name      string `json:"name"`
+		Ecosystem string `json:"ecosystem"`
+	} `json:"package"`
+	Version   string `json:"version"`
+	PageToken string `json:"page_token,omitempty"`
+}
+
+type osvBatchResponse struct {
+	Results []struct {
+		Vulns         []osvVulnEntry `json:"vulns"`
+		NextPageToken string         `json:"next_page_token"`
+	} `json:"results"`
+}
+
+// osvQueryCacheEntry is a cached query result
+type osvQueryCacheEntry struct {
+	QueriedAt time.Time      `json:"queried_at"`
+	Vulns     []osvVulnEntry `json:"vulns"`
+}
+
+// queryPackages returns the advisory IDs affecting each package (by index),
+// using cached query results that are younger than the cache TTL
+func (a *OSVAuditor) queryPackages(ctx context.Context, packages []osvLockfilePackage) ([][]osvVulnEntry, error) {
+	log := helpers.Logger(ctx)
+
+	results := make([][]osvVulnEntry, len(packages))
+
+	var pending []int
+	for i, pkg := range packages {
+		var entry osvQueryCacheEntry
+		if a.readCache(queryCacheKey(pkg), &entry) && time.Since(entry.QueriedAt) < a.cacheTTL {
+			results[i] = entry.Vulns
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	log.Debugf("osv: %d packages, %d cached, %d to query", len(packages), len(packages)-len(pending), len(pending))
+
+	for start := 0; start < len(pending); start += osvBatchSize {
+		batch := pending[start:min(start+osvBatchSize, len(pending))]
+
+		queries := make([]osvQuery, len(batch))
+		for j, i := range batch {
+			queries[j].Package.Name = packages[i].Name
+			queries[j].Package.Ecosystem = packages[i].Ecosystem
+			queries[j].Version = packages[i].Version
+		}
+
+		// Packages with many advisories are paginated; query the remaining pages until all are done
+		for len(queries) > 0 {
+			var resp osvBatchResponse
+			if err := a.post(ctx, "/querybatch", map[string]any{"queries": queries}, &resp); err != nil {
+				return nil, err
+			}
+			if len(resp.Results) != len(queries) {
+				return nil, fmt.Errorf("osv.dev returned %d results for %d queries", len(resp.Results), len(queries))
+			}
+
+			var next []osvQuery
+			var nextBatch []int
+			for j, r := range resp.Results {
+				i := batch[j]
+				results[i] = append(results[i], r.Vulns...)
+				if r.NextPageToken != "" {
+					q := queries[j]
+					q.PageToken = r.NextPageToken
+					next = append(next, q)
+					nextBatch = append(nextBatch, i)
+					continue
+				}
+				a.writeCache(queryCacheKey(packages[i]), osvQueryCacheEntry{QueriedAt: time.Now(), Vulns: results[i]})
+			}
+			queries, batch = next, nextBatch
+		}
+	}
+
+	return results, nil
+}
+
+// fetchVulnerability returns the full advisory, from the cache unless OSV reports a newer version
+func (a *OSVAuditor) fetchVulnerability(ctx context.Context, entry osvVulnEntry) (osvVulnerability, error) {
+	type cachedVuln struct {
+		Modified string           `json:"modified"`
+		Vuln     osvVulnerability `json:"vuln"`
+	}
+
+	key := "vulns/" + entry.ID
+	var cached cachedVuln
+	if a.readCache(key, &cached) && cached.Modified == entry.Modified {
+		return cached.Vuln, nil
+	}
+
+	var vuln osvVulnerability
+	if err := a.get(ctx, "/vulns/"+url.PathEscape(entry.ID), &vuln); err != nil {
+		return osvVulnerability{}, err
+	}
+
+	a.writeCache(key, cachedVuln{Modified: entry.Modified, Vuln: vuln})
+
+	return vuln, nil
+}
+
+// post sends a JSON request to the OSV API and decodes the response into out
+func (a *OSVAuditor) post(ctx context.Context, path string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.apiURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	return a.do(req, out)
+}
+
+// get sends a GET request to the OSV API and decodes the response into out
+func (a *OSVAuditor) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.apiURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	return a.do(req, out)
+}
+
+func (a *OSVAuditor) do(req *http.Request, out any) error {
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("osv.dev request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("osv.dev API error: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to parse osv.dev response: %w", err)
+	}
+
+	return nil
+}
+
+// queryCacheKey returns the cache key of a package query
+func queryCacheKey(pkg osvLockfilePackage) string {
+	sum := sha256.Sum256([]byte(pkg.Ecosystem + "\x00" + pkg.Name + "\x00" + pkg.Version))
+	return "queries/" + hex.EncodeToString(sum[:])
+}
+
+// readCache decodes the cache entry for key into out. Returns false if there is none.
+func (a *OSVAuditor) readCache(key string, out any) bool {
+	if a.cacheDir == "" {
+		return false
+	}
+
+	content, err := os.ReadFile(filepath.Join(a.cacheDir, key+".json"))
+	if err != nil {
+		return false
+	}
+
+	return json.Unmarshal(content, out) == nil
+}
+
+// writeCache stores value as the cache entry for key. Failures only disable caching.
+func (a *OSVAuditor) writeCache(key string, value any) {
+	if a.cacheDir == "" {
+		return
+	}
+
+	path := filepath.Join(a.cacheDir, key+".json")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		zap.S().Debugf("Failed to create osv cache directory: %v", err)
+		return
+	}
+
+	content, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		zap.S().Debugf("Failed to write osv cache entry %s: %v", key, err)
+	}
+}
+
+// osvMaxCVSSScore returns the highest CVSS v3 base score of an advisory as a string,
+// or an empty string when it has none (osvSeverity then uses the database severity)
+func osvMaxCVSSScore(vuln osvVulnerability) string {
+	best := -1.0
+	for _, s := range vuln.Severity {
+		if s.Type != "CVSS_V3" {
+			continue
+		}
+		if score, err := helpers.CVSS3BaseScore(s.Score); err == nil && score > best {
+			best = score
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	return strconv.FormatFloat(best, 'f', 1, 64)
+}
+
+// osvLockfileRecommender returns the recommendation builder for a lockfile package
+func osvLockfileRecommender(pkg osvLockfilePackage) osvRecommender {
+	return func(name, version string, fixed []string) string {
+		target := lowestFixedVersion(version, fixed)
+		if target == "" {
+			return fmt.Sprintf("No fixed version of %s is known. Consider replacing the package.", name)
+		}
+
+		if pkg.Ecosystem == osvEcosystemPackagist {
+			return fmt.Sprintf("Update %s to %s or later: run 'composer update %s' (or require ^%s if the constraint does not allow it).",
+				name, target, name, target)
+		}
+		if strings.HasSuffix(pkg.Lockfile, terraformLockfile) {
+			return fmt.Sprintf("Update provider %s to %s or later: raise its version constraint in required_providers and run 'terraform init -upgrade'.",
+				terraformProviderName(name), target)
+		}
+		if pkg.Ecosystem == osvEcosystemPub {
+			return fmt.Sprintf("Update %s to %s or later: run 'dart pub upgrade %s' (or raise the constraint in pubspec.yaml if it does not allow it).",
+				name, target, name)
+		}
+		return fmt.Sprintf("Update %s to %s or later: run 'npm update %s', or 'npm audit fix' where npm is available (use overrides for transitive dependencies).",
+			name, target, name)
+	}
+}
+
+// lowestFixedVersion returns the lowest fixed version above version
+// (or the highest fixed version when none is above it)
+func lowestFixedVersion(version string, fixed []string) string {
+	target := ""
+	for _, v := range fixed {
+		if version != "" && helpers.CompareVersions(v, version) <= 0 {
+			continue
+		}
+		if target == "" || helpers.CompareVersions(v, target) < 0 {
+			target = v
+		}
+	}
+	if target == "" && len(fixed) > 0 {
+		target = fixed[len(fixed)-1]
+	}
+	return target
+}
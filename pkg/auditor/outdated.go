@@ -0,0 +1,293 @@
+package auditor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// OutdatedRuleMajor identifies outdated-dependency findings, e.g. for ignore lists
+const OutdatedRuleMajor = "OUTDATED-MAJOR"
+
+// OutdatedAuditor implements the Auditor interface for outdated dependencies. It runs
+// `npm outdated` and `composer outdated` and reports direct dependencies that are one
+// or more major versions behind their latest release, as a preventive control: a
+// dependency far behind is slow and risky to patch when an advisory lands.
+type OutdatedAuditor struct {
+	autoDetect bool
+	severity   string
+}
+
+// NewOutdatedAuditor creates a new OutdatedAuditor reporting findings with severity.
+// When autoDetect is false it only runs for apps whose type lists it explicitly.
+func NewOutdatedAuditor(autoDetect bool, severity string) *OutdatedAuditor {
+	if severity == "" {
+		severity = models.SeverityInfo
+	}
+	return &OutdatedAuditor{autoDetect: autoDetect, severity: severity}
+}
+
+// Name returns "outdated"
+func (a *OutdatedAuditor) Name() string {
+	return "outdated"
+}
+
+// Detect checks for package.json or composer.json
+func (a *OutdatedAuditor) Detect(path string) bool {
+	if !a.autoDetect {
+		return false
+	}
+	return FileExists(JoinPath(path, "package.json")) || FileExists(JoinPath(path, "composer.json"))
+}
+
+// outdatedDependency is a dependency with its installed and latest versions
+type outdatedDependency struct {
+	name    string
+	current string
+	latest  string
+	dev     bool
+}
+
+// outdatedManager lists the outdated dependencies of one package manager
+type outdatedManager struct {
+	binary   string
+	manifest string
+	args     []string
+	parse    func(output []byte) ([]outdatedDependency, error)
+	upgrade  func(dep outdatedDependency) string
+}
+
+var outdatedManagers = []outdatedManager{
+	{"npm", "package.json", []string{"outdated", "--json", "--long"}, parseNpmOutdated, npmUpgradeCommand},
+	{"composer", "composer.json", []string{"outdated", "--direct", "--format=json", "--no-interaction"}, parseComposerOutdated, composerUpgradeCommand},
+}
+
+// Audit runs the outdated command of each package manager of the app
+func (a *OutdatedAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running outdated audit for app=%s path=%s", app.Name, app.Path)
+
+	if _, ok := models.SeverityOrder[a.severity]; !ok {
+		return nil, fmt.Errorf("invalid outdated severity %q (use info, low, moderate, high or critical)", a.severity)
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+	raw := make(map[string]json.RawMessage)
+	var versions []string
+	found := false
+
+	for _, manager := range outdatedManagers {
+		if !FileExists(JoinPath(app.Path, manager.manifest)) {
+			continue
+		}
+		found = true
+		if _, err := exec.LookPath(manager.binary); err != nil {
+			return nil, fmt.Errorf("%s not found in PATH: %w", manager.binary, err)
+		}
+		if version := ToolVersion(ctx, manager.binary, "--version"); version != "" {
+			versions = append(versions, manager.binary+" "+version)
+		}
+
+		cmd := exec.CommandContext(ctx, manager.binary, manager.args...)
+		cmd.Dir = app.Path
+
+		var stdout, stderr bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+
+		// npm outdated and composer outdated --strict exit non-zero when a dependency is outdated,
+		// so only a run without output is a failure
+		runErr := cmd.Run()
+		output := bytes.TrimSpace(stdout.Bytes())
+		if len(output) == 0 {
+			if runErr != nil {
+				errMsg := strings.TrimSpace(stderr.String())
+				if errMsg == "" {
+					errMsg = runErr.Error()
+				}
+				return nil, fmt.Errorf("%s outdated failed: %s", manager.binary, errMsg)
+			}
+			continue
+		}
+
+		deps, err := manager.parse(output)
+		if err != nil {
+			log.Debugf("%s outdated raw output: %s", manager.binary, output)
+			return nil, fmt.Errorf("failed to parse %s outdated output: %w", manager.binary, err)
+		}
+		raw[manager.binary] = json.RawMessage(output)
+
+		for _, dep := range deps {
+			behind := majorsBehind(dep.current, dep.latest)
+			if behind <= 0 {
+				continue
+			}
+			result.Vulnerabilities = append(result.Vulnerabilities, a.finding(manager, dep, behind))
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no package.json or composer.json found in %s", app.Path)
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	rawOutput, _ := json.Marshal(raw)
+	result.RawOutput = string(rawOutput)
+	result.AuditorType = a.Name()
+	result.ToolVersion = strings.Join(versions, ", ")
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("outdated audit completed for app=%s total=%d", app.Name, result.TotalVulnerabilities)
+
+	return result, nil
+}
+
+// finding reports a dependency that is behind its latest major version
+func (a *OutdatedAuditor) finding(manager outdatedManager, dep outdatedDependency, behind int) models.Vulnerability {
+	title := "1 major version behind"
+	if behind > 1 {
+		title = fmt.Sprintf("%d major versions behind", behind)
+	}
+
+	description := fmt.Sprintf("%s is at %s; the latest release is %s.", dep.name, dep.current, dep.latest)
+	if dep.dev {
+		description += " It is a development dependency."
+	}
+
+	return models.Vulnerability{
+		PackageName:        dep.name,
+		Severity:           a.severity,
+		CVEID:              OutdatedRuleMajor,
+		Title:              title,
+		Description:        description,
+		Recommendation:     "Plan the upgrade (check the changelog for breaking changes): " + manager.upgrade(dep),
+		VulnerableVersions: dep.current,
+		PatchedVersions:    dep.latest,
+	}
+}
+
+// npmOutdatedEntry is an entry of `npm outdated --json --long`
+type npmOutdatedEntry struct {
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+	Type    string `json:"type"`
+}
+
+// parseNpmOutdated parses `npm outdated --json --long` output. Packages installed in several
+// workspaces are listed as an array of entries; packages that are not installed have no current version.
+func parseNpmOutdated(output []byte) ([]outdatedDependency, error) {
+	var packages map[string]json.RawMessage
+	if err := json.Unmarshal(output, &packages); err != nil {
+		return nil, err
+	}
+
+	var deps []outdatedDependency
+	for name, raw := range packages {
+		var entries []npmOutdatedEntry
+		if err := json.Unmarshal(raw, &entries); err != nil {
+			var entry npmOutdatedEntry
+			if err := json.Unmarshal(raw, &entry); err != nil {
+				return nil, fmt.Errorf("package %s: %w", name, err)
+			}
+			entries = []npmOutdatedEntry{entry}
+		}
+
+		for _, entry := range entries {
+			if entry.Current == "" {
+				continue
+			}
+			deps = append(deps, outdatedDependency{
+				name:    name,
+				current: entry.Current,
+				latest:  entry.Latest,
+				dev:     entry.Type == "devDependencies",
+			})
+			break
+		}
+	}
+
+	sort.Slice(deps, func(i, j int) bool { return deps[i].name < deps[j].name })
+	return deps, nil
+}
+
+// composerOutdatedOutput is the `composer outdated --format=json` output
+type composerOutdatedOutput struct {
+	Installed []struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Latest  string `json:"latest"`
+	} `json:"installed"`
+}
+
+// parseComposerOutdated parses `composer outdated --direct --format=json` output
+func parseComposerOutdated(output []byte) ([]outdatedDependency, error) {
+	var parsed composerOutdatedOutput
+	if err := json.Unmarshal(output, &parsed); err != nil {
+		return nil, err
+	}
+
+	deps := make([]outdatedDependency, 0, len(parsed.Installed))
+	for _, pkg := range parsed.Installed {
+		deps = append(deps, outdatedDependency{
+			name:    pkg.Name,
+			current: pkg.Version,
+			latest:  pkg.Latest,
+		})
+	}
+	return deps, nil
+}
+
+// npmUpgradeCommand returns the command upgrading an npm dependency to its latest version
+func npmUpgradeCommand(dep outdatedDependency) string {
+	if dep.dev {
+		return fmt.Sprintf("npm install --save-dev %s@%s", dep.name, dep.latest)
+	}
+	return fmt.Sprintf("npm install %s@%s", dep.name, dep.latest)
+}
+
+// composerUpgradeCommand returns the command upgrading a Composer dependency to its latest major version
+func composerUpgradeCommand(dep outdatedDependency) string {
+	return fmt.Sprintf("composer require %s:^%s -W", dep.name, strings.TrimPrefix(dep.latest, "v"))
+}
+
+// majorsBehind returns how many major versions current is behind latest, or 0 when
+// either is not a release version (e.g. a branch such as dev-master)
+func majorsBehind(current, latest string) int {
+	currentMajor, ok := majorVersion(current)
+	if !ok {
+		return 0
+	}
+	latestMajor, ok := majorVersion(latest)
+	if !ok {
+		return 0
+	}
+	return latestMajor - currentMajor
+}
+
+// majorVersion returns the major version number of a version such as "v2.1.0"
+func majorVersion(version string) (int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	major, _, _ := strings.Cut(version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,447 @@
+package auditor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// PHP rule IDs, reported in the CVE field so they can be added to an app's ignore list
+const (
+	PHPRuleEOL           = "PHP-EOL"
+	PHPRuleEOLSoon       = "PHP-EOL-SOON"
+	PHPRuleConstraintEOL = "PHP-CONSTRAINT-EOL"
+	PHPRuleExtension     = "PHP-EXTENSION"
+)
+
+// phpEOLWarning is how long before its end of life a PHP branch is reported
+const phpEOLWarning = 90 * 24 * time.Hour
+
+// phpEOL is the end of security support of each PHP branch (https://www.php.net/supported-versions.php).
+// Older branches are end-of-life; newer ones are treated as supported.
+var phpEOL = map[string]string{
+	"5.6": "2018-12-31",
+	"7.0": "2019-01-10",
+	"7.1": "2019-12-01",
+	"7.2": "2020-11-30",
+	"7.3": "2021-12-06",
+	"7.4": "2022-11-28",
+	"8.0": "2023-11-26",
+	"8.1": "2025-12-31",
+	"8.2": "2026-12-31",
+	"8.3": "2027-12-31",
+	"8.4": "2028-12-31",
+	"8.5": "2029-12-31",
+}
+
+// opensslEOL is the end of support of each OpenSSL branch (https://openssl-library.org/policies/releasestrat/)
+var opensslEOL = map[string]string{
+	"1.0.2": "2019-12-31",
+	"1.1.0": "2019-09-11",
+	"1.1.1": "2023-09-11",
+	"3.0":   "2026-09-07",
+	"3.1":   "2025-03-14",
+	"3.2":   "2025-11-23",
+	"3.3":   "2026-04-09",
+	"3.4":   "2026-10-22",
+	"3.5":   "2030-04-08",
+	"3.6":   "2026-11-01",
+}
+
+// phpInsecureExtensions are extensions that are unmaintained, with the severity and reason reported when loaded
+var phpInsecureExtensions = map[string]struct {
+	severity string
+	reason   string
+}{
+	"mcrypt": {models.SeverityHigh, "wraps libmcrypt, abandoned since 2007 and removed from PHP in 7.2; use openssl or sodium"},
+	"xmlrpc": {models.SeverityModerate, "unmaintained since it was moved out of PHP in 8.0 and has known parser vulnerabilities"},
+}
+
+// phpRuntimeScript prints the running PHP's version, extensions and OpenSSL library version as JSON
+const phpRuntimeScript = `$e = get_loaded_extensions();
+echo json_encode(['version' => PHP_VERSION, 'extensions' => array_combine($e, array_map('phpversion', $e)),
+    'openssl' => defined('OPENSSL_VERSION_TEXT') ? OPENSSL_VERSION_TEXT : '']);`
+
+var (
+	versionBranchPattern  = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+	constraintVersionPart = regexp.MustCompile(`(\d+)(?:\.(\d+))?`)
+)
+
+// PHPAuditor implements the Auditor interface for the PHP runtime. It flags an
+// end-of-life (or soon end-of-life) PHP binary, composer.json platform requirements
+// that target or allow end-of-life PHP, and insecure extensions: unmaintained ones
+// and OpenSSL libraries past their end of life. Without a PHP binary only composer.json
+// is checked.
+type PHPAuditor struct {
+	autoDetect bool
+}
+
+// NewPHPAuditor creates a new PHPAuditor. When autoDetect is false it only runs for
+// apps whose type lists it explicitly.
+func NewPHPAuditor(autoDetect bool) *PHPAuditor {
+	return &PHPAuditor{autoDetect: autoDetect}
+}
+
+// Name returns "php"
+func (a *PHPAuditor) Name() string {
+	return "php"
+}
+
+// Detect checks for composer.json
+func (a *PHPAuditor) Detect(path string) bool {
+	if !a.autoDetect {
+		return false
+	}
+	return FileExists(JoinPath(path, "composer.json"))
+}
+
+// phpRuntime is what the PHP binary reports about itself
+type phpRuntime struct {
+	Version    string         `json:"version"`
+	Extensions map[string]any `json:"extensions"` // name -> version, or false when the extension has none
+	OpenSSL    string         `json:"openssl"`
+}
+
+// Audit checks the app's PHP binary and composer.json
+func (a *PHPAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running php audit for app=%s path=%s", app.Name, app.Path)
+
+	options, err := AppOptions("php", nil, app)
+	if err != nil {
+		return nil, err
+	}
+	binary := options["binary"]
+	if binary == "" {
+		binary = "php"
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+	now := time.Now()
+
+	// Running PHP: a configured binary must exist, the default one is optional
+	var runtime *phpRuntime
+	if _, err := exec.LookPath(binary); err != nil {
+		if options["binary"] != "" {
+			return nil, fmt.Errorf("%s not found in PATH: %w", binary, err)
+		}
+		log.Warnf("php not found in PATH, only checking composer.json for app=%s", app.Name)
+	} else {
+		runtime, err = a.inspectRuntime(ctx, binary, app.Path)
+		if err != nil {
+			return nil, err
+		}
+		result.ToolVersion = runtime.Version
+		result.Vulnerabilities = append(result.Vulnerabilities, checkPHPRuntime(runtime, binary, now)...)
+	}
+
+	// composer.json platform requirements
+	content, err := os.ReadFile(JoinPath(app.Path, "composer.json"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read composer.json: %w", err)
+	}
+	if err == nil {
+		runtimeBranch := ""
+		if runtime != nil {
+			runtimeBranch, _ = versionBranch(runtime.Version)
+		}
+		findings, err := checkComposerPlatform(content, runtimeBranch, now)
+		if err != nil {
+			return nil, err
+		}
+		result.Vulnerabilities = append(result.Vulnerabilities, findings...)
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	rawOutput, _ := json.Marshal(map[string]any{"runtime": runtime, "findings": result.Vulnerabilities})
+	result.RawOutput = string(rawOutput)
+	result.AuditorType = a.Name()
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("php audit completed for app=%s version=%s total=%d",
+		app.Name,
+		result.ToolVersion,
+		result.TotalVulnerabilities,
+	)
+
+	return result, nil
+}
+
+// inspectRuntime asks the PHP binary for its version and extensions
+func (a *PHPAuditor) inspectRuntime(ctx context.Context, binary, dir string) (*phpRuntime, error) {
+	cmd := exec.CommandContext(ctx, binary, "-r", phpRuntimeScript)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return nil, fmt.Errorf("%s failed: %s", binary, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("failed to run %s: %w", binary, err)
+	}
+
+	var runtime phpRuntime
+	if err := json.Unmarshal(output, &runtime); err != nil {
+		return nil, fmt.Errorf("failed to parse %s output: %w", binary, err)
+	}
+	if runtime.Version == "" {
+		return nil, fmt.Errorf("%s did not report its version", binary)
+	}
+	return &runtime, nil
+}
+
+// checkPHPRuntime reports an end-of-life PHP version and insecure extensions
+func checkPHPRuntime(runtime *phpRuntime, binary string, now time.Time) []models.Vulnerability {
+	var findings []models.Vulnerability
+
+	if branch, ok := versionBranch(runtime.Version); ok {
+		eol, known := phpBranchEOL(branch)
+		switch {
+		case known && now.After(eol):
+			findings = append(findings, models.Vulnerability{
+				PackageName:        "php",
+				Severity:           models.SeverityHigh,
+				CVEID:              PHPRuleEOL,
+				Title:              fmt.Sprintf("PHP %s is end-of-life", branch),
+				Description:        fmt.Sprintf("PHP %s stopped receiving security fixes on %s; vulnerabilities found since are not fixed. %s reports version %s.", branch, eol.Format(time.DateOnly), binary, runtime.Version),
+				Recommendation:     phpUpgradeRecommendation(now),
+				VulnerableVersions: runtime.Version,
+				PatchedVersions:    supportedPHPBranches(now),
+				URL:                "https://www.php.net/supported-versions.php",
+			})
+		case known && now.Add(phpEOLWarning).After(eol):
+			findings = append(findings, models.Vulnerability{
+				PackageName:        "php",
+				Severity:           models.SeverityModerate,
+				CVEID:              PHPRuleEOLSoon,
+				Title:              fmt.Sprintf("PHP %s reaches end-of-life on %s", branch, eol.Format(time.DateOnly)),
+				Description:        fmt.Sprintf("PHP %s stops receiving security fixes on %s. %s reports version %s.", branch, eol.Format(time.DateOnly), binary, runtime.Version),
+				Recommendation:     phpUpgradeRecommendation(now),
+				VulnerableVersions: runtime.Version,
+				PatchedVersions:    supportedPHPBranches(now),
+				URL:                "https://www.php.net/supported-versions.php",
+			})
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(runtime.Extensions)) {
+		insecure, ok := phpInsecureExtensions[strings.ToLower(name)]
+		if !ok {
+			continue
+		}
+		version, _ := runtime.Extensions[name].(string)
+		findings = append(findings, models.Vulnerability{
+			PackageName:        "ext-" + strings.ToLower(name),
+			Severity:           insecure.severity,
+			CVEID:              PHPRuleExtension,
+			Title:              fmt.Sprintf("Insecure PHP extension %s is loaded", name),
+			Description:        fmt.Sprintf("The %s extension %s.", name, insecure.reason),
+			Recommendation:     fmt.Sprintf("Remove the code depending on %s and disable the extension in php.ini", name),
+			VulnerableVersions: version,
+		})
+	}
+
+	if finding, ok := checkOpenSSL(runtime.OpenSSL, now); ok {
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// checkOpenSSL reports an OpenSSL library ("OpenSSL 1.1.1f  31 Mar 2020") past its end of life
+func checkOpenSSL(versionText string, now time.Time) (models.Vulnerability, bool) {
+	match := versionBranchPattern.FindStringSubmatch(versionText)
+	if match == nil || !strings.Contains(versionText, "OpenSSL") {
+		return models.Vulnerability{}, false
+	}
+	branch := match[1] + "." + match[2]
+	if match[1] == "1" {
+		branch += "." + match[3]
+	}
+	eolDate, ok := opensslEOL[branch]
+	if !ok {
+		return models.Vulnerability{}, false
+	}
+	eol, _ := time.Parse(time.DateOnly, eolDate)
+	if !now.After(eol) {
+		return models.Vulnerability{}, false
+	}
+
+	return models.Vulnerability{
+		PackageName: "ext-openssl",
+		Severity:    models.SeverityModerate,
+		CVEID:       PHPRuleExtension,
+		Title:       fmt.Sprintf("PHP openssl extension uses end-of-life OpenSSL %s", branch),
+		Description: fmt.Sprintf("PHP is linked against %s; OpenSSL %s stopped receiving public security fixes on %s. "+
+			"Distributions with extended support (e.g. RHEL, Ubuntu Pro) may still backport fixes.", strings.TrimSpace(versionText), branch, eolDate),
+		Recommendation:     "Upgrade the OS packages providing OpenSSL, or move to a PHP build linked against a supported OpenSSL release",
+		VulnerableVersions: match[0],
+		URL:                "https://openssl-library.org/policies/releasestrat/",
+	}, true
+}
+
+// checkComposerPlatform reports a config.platform.php version that is end-of-life
+// (unless it is the runtime's branch, already reported) and a require.php constraint
+// that still allows end-of-life branches
+func checkComposerPlatform(content []byte, runtimeBranch string, now time.Time) ([]models.Vulnerability, error) {
+	var manifest struct {
+		Require map[string]string `json:"require"`
+		Config  struct {
+			Platform map[string]any `json:"platform"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse composer.json: %w", err)
+	}
+
+	var findings []models.Vulnerability
+
+	// config.platform.php is the PHP version Composer resolves dependencies for
+	if platform, ok := manifest.Config.Platform["php"].(string); ok {
+		if branch, ok := versionBranch(platform); ok && branch != runtimeBranch {
+			if eol, known := phpBranchEOL(branch); known && now.After(eol) {
+				findings = append(findings, models.Vulnerability{
+					PackageName: "php",
+					Severity:    models.SeverityHigh,
+					CVEID:       PHPRuleEOL,
+					Title:       fmt.Sprintf("composer.json targets end-of-life PHP %s", branch),
+					Description: fmt.Sprintf("config.platform.php is %s: dependencies are resolved for PHP %s, which stopped "+
+						"receiving security fixes on %s.", platform, branch, eol.Format(time.DateOnly)),
+					Recommendation:     phpUpgradeRecommendation(now) + ", then raise config.platform.php to match",
+					VulnerableVersions: platform,
+					PatchedVersions:    supportedPHPBranches(now),
+					URL:                "https://www.php.net/supported-versions.php",
+				})
+			}
+		}
+	}
+
+	// require.php allowing end-of-life branches lets dependencies keep supporting them
+	if constraint := manifest.Require["php"]; constraint != "" {
+		if branch, ok := constraintMinimumBranch(constraint); ok {
+			if eol, known := phpBranchEOL(branch); known && now.After(eol) {
+				oldest := oldestSupportedPHPBranch(now)
+				findings = append(findings, models.Vulnerability{
+					PackageName: "php",
+					Severity:    models.SeverityLow,
+					CVEID:       PHPRuleConstraintEOL,
+					Title:       fmt.Sprintf("composer.json allows end-of-life PHP %s", branch),
+					Description: fmt.Sprintf("require.php is %q, which allows PHP %s (end-of-life since %s). The app can be "+
+						"deployed on, and its dependencies resolved for, a PHP without security fixes.", constraint, branch, eol.Format(time.DateOnly)),
+					Recommendation:     fmt.Sprintf("Raise the minimum PHP version in composer.json, e.g. \"php\": \"^%s\"", oldest),
+					VulnerableVersions: constraint,
+					URL:                "https://www.php.net/supported-versions.php",
+				})
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// versionBranch returns the major.minor branch of a version ("8.1.2-1ubuntu2" -> "8.1")
+func versionBranch(version string) (string, bool) {
+	match := versionBranchPattern.FindStringSubmatch(version)
+	if match == nil {
+		return "", false
+	}
+	return match[1] + "." + match[2], true
+}
+
+// constraintMinimumBranch returns the oldest major.minor branch a Composer constraint
+// allows ("^7.4|^8.0" -> "7.4"). Constraints with an unbounded alternative ("*") have none.
+func constraintMinimumBranch(constraint string) (string, bool) {
+	var best [2]int
+	found := false
+	for _, alternative := range strings.Split(constraint, "|") {
+		alternative = strings.TrimSpace(alternative)
+		if alternative == "" {
+			continue
+		}
+		match := constraintVersionPart.FindStringSubmatch(alternative)
+		if match == nil {
+			return "", false
+		}
+		major, _ := strconv.Atoi(match[1])
+		minor, _ := strconv.Atoi(match[2])
+		if !found || major < best[0] || (major == best[0] && minor < best[1]) {
+			best, found = [2]int{major, minor}, true
+		}
+	}
+	if !found {
+		return "", false
+	}
+	return fmt.Sprintf("%d.%d", best[0], best[1]), true
+}
+
+// phpBranchEOL returns the end of life of a PHP branch. Branches older than the
+// oldest known one ended before it; newer unknown branches are not known (false).
+func phpBranchEOL(branch string) (time.Time, bool) {
+	if date, ok := phpEOL[branch]; ok {
+		eol, err := time.Parse(time.DateOnly, date)
+		return eol, err == nil
+	}
+	if compareBranches(branch, "5.6") < 0 {
+		eol, _ := time.Parse(time.DateOnly, phpEOL["5.6"])
+		return eol, true
+	}
+	return time.Time{}, false
+}
+
+// supportedPHPBranches lists the PHP branches still receiving security fixes ("8.2, 8.3, 8.4, 8.5")
+func supportedPHPBranches(now time.Time) string {
+	var branches []string
+	for branch, date := range phpEOL {
+		if eol, err := time.Parse(time.DateOnly, date); err == nil && now.Before(eol) {
+			branches = append(branches, branch)
+		}
+	}
+	slices.SortFunc(branches, compareBranches)
+	return strings.Join(branches, ", ")
+}
+
+// oldestSupportedPHPBranch returns the oldest PHP branch still receiving security fixes
+func oldestSupportedPHPBranch(now time.Time) string {
+	branches := strings.Split(supportedPHPBranches(now), ", ")
+	return branches[0]
+}
+
+// phpUpgradeRecommendation recommends the newest PHP branch
+func phpUpgradeRecommendation(now time.Time) string {
+	branches := strings.Split(supportedPHPBranches(now), ", ")
+	newest := branches[len(branches)-1]
+	eol, _ := phpBranchEOL(newest)
+	return fmt.Sprintf("Upgrade to PHP %s (security fixes until %s) and update require.php in composer.json", newest, eol.Format(time.DateOnly))
+}
+
+// compareBranches compares two major.minor branches numerically
+func compareBranches(a, b string) int {
+	aMajor, aMinor, _ := strings.Cut(a, ".")
+	bMajor, bMinor, _ := strings.Cut(b, ".")
+	x, _ := strconv.Atoi(aMajor)
+	y, _ := strconv.Atoi(bMajor)
+	if x != y {
+		return x - y
+	}
+	x, _ = strconv.Atoi(aMinor)
+	y, _ = strconv.Atoi(bMinor)
+	return x - y
+}
@@ -0,0 +1,222 @@
+package auditor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// PnpmAuditor implements the Auditor interface for pnpm projects and workspaces
+type PnpmAuditor struct{}
+
+// NewPnpmAuditor creates a new PnpmAuditor
+func NewPnpmAuditor() *PnpmAuditor {
+	return &PnpmAuditor{}
+}
+
+// Name returns "pnpm"
+func (a *PnpmAuditor) Name() string {
+	return "pnpm"
+}
+
+// Detect checks for pnpm-lock.yaml
+func (a *PnpmAuditor) Detect(path string) bool {
+	return isPnpmProject(path)
+}
+
+// isPnpmProject returns true if the app is managed by pnpm
+func isPnpmProject(path string) bool {
+	return FileExists(JoinPath(path, "pnpm-lock.yaml"))
+}
+
+// Audit runs pnpm audit and parses the results.
+// pnpm audits the whole lockfile, so in a workspace root every workspace
+// package is covered by a single run.
+func (a *PnpmAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running pnpm audit for app=%s path=%s", app.Name, app.Path)
+
+	// Check if pnpm is available
+	if _, err := exec.LookPath("pnpm"); err != nil {
+		return nil, fmt.Errorf("pnpm not found in PATH: %w", err)
+	}
+
+	toolVersion := ToolVersion(ctx, "pnpm", "--version")
+
+	// Check if pnpm-lock.yaml exists (pnpm audit requires it)
+	if !isPnpmProject(app.Path) {
+		return nil, fmt.Errorf("pnpm-lock.yaml not found in %s", app.Path)
+	}
+
+	if FileExists(JoinPath(app.Path, "pnpm-workspace.yaml")) {
+		log.Debugf("pnpm workspace detected for app=%s, auditing all workspace packages", app.Name)
+	}
+
+	// Run pnpm audit
+	cmd := exec.CommandContext(ctx, "pnpm", "audit", "--json")
+	cmd.Dir = app.Path
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// pnpm audit exits 1 both when vulnerabilities are found and on some errors
+	// (e.g., a missing lockfile), so failures are detected from the JSON output
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok {
+			return nil, fmt.Errorf("failed to run pnpm audit: %w", err)
+		}
+	}
+
+	output := stdout.String()
+	result, err := a.parseOutput(output, app)
+	if err != nil {
+		log.Debugf("pnpm audit raw output: %s", output)
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" {
+			errMsg = err.Error()
+		}
+		return nil, fmt.Errorf("pnpm audit failed: %s", errMsg)
+	}
+
+	result.RawOutput = output
+	result.AuditorType = a.Name()
+	result.ToolVersion = toolVersion
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("pnpm audit completed for app=%s total=%d critical=%d high=%d",
+		app.Name,
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// pnpmAuditOutput represents the pnpm audit JSON output structure
+type pnpmAuditOutput struct {
+	Advisories map[string]npmAdvisory `json:"advisories"`
+	Error      *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// parseOutput parses pnpm audit JSON output
+func (a *PnpmAuditor) parseOutput(output string, app models.AppConfig) (*models.AuditResult, error) {
+	if strings.TrimSpace(output) == "" {
+		return nil, fmt.Errorf("empty output")
+	}
+
+	var auditOutput pnpmAuditOutput
+	if err := json.Unmarshal([]byte(output), &auditOutput); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	if auditOutput.Error != nil {
+		return nil, fmt.Errorf("%s: %s", auditOutput.Error.Code, auditOutput.Error.Message)
+	}
+
+	if auditOutput.Advisories == nil {
+		return nil, fmt.Errorf("unexpected output: no advisories field")
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+
+	// Sort advisory IDs for stable output
+	ids := make([]string, 0, len(auditOutput.Advisories))
+	for id := range auditOutput.Advisories {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		adv := auditOutput.Advisories[id]
+		workspaces := pnpmWorkspaces(adv)
+
+		// Skip advisories only pulled in by workspace packages under ignored paths
+		if IgnoredPaths(app, workspaces...) {
+			continue
+		}
+
+		cveID := ""
+		if len(adv.CVEs) > 0 {
+			cveID = adv.CVEs[0]
+		}
+
+		description := adv.Overview
+		if description == "" {
+			description = fmt.Sprintf("Vulnerable versions: %s", adv.VulnerableVersions)
+		}
+		// Only name importers when the project is a workspace (more than the root package)
+		if len(workspaces) > 1 || (len(workspaces) == 1 && workspaces[0] != "(root)") {
+			description += fmt.Sprintf(" Affected workspace packages: %s.", strings.Join(workspaces, ", "))
+		}
+
+		result.Vulnerabilities = append(result.Vulnerabilities, models.Vulnerability{
+			PackageName:        adv.ModuleName,
+			Severity:           normalizeSeverity(adv.Severity),
+			CVEID:              cveID,
+			Title:              adv.Title,
+			Description:        strings.TrimSpace(description),
+			Recommendation:     buildPnpmRecommendation(adv),
+			VulnerableVersions: adv.VulnerableVersions,
+			PatchedVersions:    adv.PatchedVersions,
+			URL:                adv.URL,
+		})
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	return result, nil
+}
+
+// pnpmWorkspaces returns the workspace packages (importers) that pull in an advisory.
+// Finding paths start with the importer, e.g. "packages__web>next>postcss" or ".>lodash".
+func pnpmWorkspaces(adv npmAdvisory) []string {
+	seen := make(map[string]bool)
+	var workspaces []string
+
+	for _, finding := range adv.Findings {
+		for _, path := range finding.Paths {
+			importer, _, _ := strings.Cut(path, ">")
+			if importer == "" || importer == "." {
+				importer = "(root)"
+			}
+			importer = strings.ReplaceAll(importer, "__", "/")
+			if !seen[importer] {
+				seen[importer] = true
+				workspaces = append(workspaces, importer)
+			}
+		}
+	}
+
+	sort.Strings(workspaces)
+	return workspaces
+}
+
+// buildPnpmRecommendation creates a recommendation message for pnpm projects
+func buildPnpmRecommendation(adv npmAdvisory) string {
+	if adv.PatchedVersions == "" || adv.PatchedVersions == "<0.0.0" {
+		return fmt.Sprintf("No patched version of %s is available. Consider replacing the dependency.", adv.ModuleName)
+	}
+
+	return fmt.Sprintf("Update %s to version %s. Run 'pnpm update -r %s', or 'pnpm audit --fix' to add overrides for transitive dependencies.",
+		adv.ModuleName, adv.PatchedVersions, adv.ModuleName)
+}
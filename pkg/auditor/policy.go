@@ -0,0 +1,389 @@
+package auditor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Pinning policy rule IDs, reported in the CVE field so they can be added to an app's ignore list
+const (
+	PolicyRuleWildcard  = "POLICY-WILDCARD"
+	PolicyRuleTag       = "POLICY-TAG"
+	PolicyRuleBranch    = "POLICY-BRANCH"
+	PolicyRuleUnbounded = "POLICY-UNBOUNDED"
+)
+
+// PolicyAuditor implements the Auditor interface for dependency pinning policy checks.
+// It flags loose constraints in package.json and composer.json: wildcards, npm dist-tags
+// (latest), branches (dev-master, git dependencies) and ranges without an upper bound.
+type PolicyAuditor struct {
+	autoDetect bool
+}
+
+// NewPolicyAuditor creates a new PolicyAuditor. When autoDetect is false the
+// policy only runs for apps whose type lists it explicitly.
+func NewPolicyAuditor(autoDetect bool) *PolicyAuditor {
+	return &PolicyAuditor{autoDetect: autoDetect}
+}
+
+// Name returns "policy"
+func (a *PolicyAuditor) Name() string {
+	return "policy"
+}
+
+// Detect checks for package.json or composer.json
+func (a *PolicyAuditor) Detect(path string) bool {
+	if !a.autoDetect {
+		return false
+	}
+	return FileExists(JoinPath(path, "package.json")) || FileExists(JoinPath(path, "composer.json"))
+}
+
+// policyManifest is a manifest checked by the pinning policy
+type policyManifest struct {
+	file     string
+	lockfile string
+	check    func(content []byte, locked map[string]string) ([]models.Vulnerability, error)
+	locked   func(content []byte) map[string]string
+}
+
+var policyManifests = []policyManifest{
+	{"package.json", "package-lock.json", checkPackageJSON, npmLockedVersions},
+	{"composer.json", "composer.lock", checkComposerJSON, composerLockedVersions},
+}
+
+// Audit checks the app's manifests against the pinning policy
+func (a *PolicyAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running policy audit for app=%s path=%s", app.Name, app.Path)
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+	found := false
+
+	for _, manifest := range policyManifests {
+		content, err := os.ReadFile(JoinPath(app.Path, manifest.file))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", manifest.file, err)
+		}
+		found = true
+
+		// The locked version, when known, is suggested as the version to pin
+		locked := map[string]string{}
+		if lockContent, err := os.ReadFile(JoinPath(app.Path, manifest.lockfile)); err == nil {
+			locked = manifest.locked(lockContent)
+		}
+
+		vulns, err := manifest.check(content, locked)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", manifest.file, err)
+		}
+		result.Vulnerabilities = append(result.Vulnerabilities, vulns...)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("no package.json or composer.json found in %s", app.Path)
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	rawOutput, _ := json.Marshal(result.Vulnerabilities)
+	result.RawOutput = string(rawOutput)
+	result.AuditorType = a.Name()
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("policy audit completed for app=%s total=%d critical=%d high=%d",
+		app.Name,
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// policyDependencyGroup is a dependency section of a manifest
+type policyDependencyGroup struct {
+	name string
+	deps map[string]string
+	dev  bool
+}
+
+// checkPackageJSON checks the dependencies of a package.json.
+// peerDependencies are meant to be loose and are not checked.
+func checkPackageJSON(content []byte, locked map[string]string) ([]models.Vulnerability, error) {
+	var manifest struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	return checkDependencyGroups("package.json", []policyDependencyGroup{
+		{"dependencies", manifest.Dependencies, false},
+		{"devDependencies", manifest.DevDependencies, true},
+		{"optionalDependencies", manifest.OptionalDependencies, false},
+	}, npmConstraintRule, locked), nil
+}
+
+// checkComposerJSON checks the require and require-dev sections of a composer.json
+func checkComposerJSON(content []byte, locked map[string]string) ([]models.Vulnerability, error) {
+	var manifest struct {
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	return checkDependencyGroups("composer.json", []policyDependencyGroup{
+		{"require", manifest.Require, false},
+		{"require-dev", manifest.RequireDev, true},
+	}, composerConstraintRule, locked), nil
+}
+
+// checkDependencyGroups applies a constraint rule to every dependency, sorted by name for stable output
+func checkDependencyGroups(file string, groups []policyDependencyGroup, rule func(name, constraint string) string, locked map[string]string) []models.Vulnerability {
+	vulns := make([]models.Vulnerability, 0)
+
+	for _, group := range groups {
+		names := make([]string, 0, len(group.deps))
+		for name := range group.deps {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			constraint := group.deps[name]
+			ruleID := rule(name, constraint)
+			if ruleID == "" {
+				continue
+			}
+			vulns = append(vulns, policyFinding(file, group, name, constraint, ruleID, locked[name]))
+		}
+	}
+
+	return vulns
+}
+
+// policyFinding converts a loose constraint into a finding.
+// Branches, tags and wildcards are moderate; unbounded ranges and dev dependencies are low.
+func policyFinding(file string, group policyDependencyGroup, name, constraint, ruleID, lockedVersion string) models.Vulnerability {
+	var title, severity string
+	switch ruleID {
+	case PolicyRuleWildcard:
+		title, severity = "Wildcard version constraint", models.SeverityModerate
+	case PolicyRuleTag:
+		title, severity = "Dist-tag version constraint", models.SeverityModerate
+	case PolicyRuleBranch:
+		title, severity = "Branch version constraint", models.SeverityModerate
+	default:
+		title, severity = "Version constraint without upper bound", models.SeverityLow
+	}
+	if group.dev {
+		severity = models.SeverityLow
+	}
+
+	recommendation := fmt.Sprintf("Constrain %s in %s to a release range", name, file)
+	if lockedVersion != "" {
+		recommendation += fmt.Sprintf(", e.g. ^%s (the locked version)", strings.TrimPrefix(lockedVersion, "v"))
+	}
+
+	return models.Vulnerability{
+		PackageName: name,
+		Severity:    severity,
+		CVEID:       ruleID,
+		Title:       title,
+		Description: fmt.Sprintf("%s requires %s %q in %s, so installs can pick up any new release without review.",
+			file, name, constraint, group.name),
+		Recommendation:     recommendation,
+		VulnerableVersions: constraint,
+	}
+}
+
+var (
+	// npmDistTag matches a dist-tag such as latest or next (but not a v-prefixed version)
+	npmDistTag = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._-]*$`)
+	// npmGitHubShorthand matches "user/repo", optionally followed by a commit-ish
+	npmGitHubShorthand = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+(#.*)?$`)
+	// npmPinnedRef matches a git commit-ish that does not move: a commit hash, a version tag or a semver range
+	npmPinnedRef     = regexp.MustCompile(`^([0-9a-f]{7,40}|v?\d.*|semver:.+)$`)
+	npmVersionPrefix = regexp.MustCompile(`^v?\d`)
+)
+
+// npmConstraintRule returns the policy rule violated by an npm dependency constraint, or ""
+func npmConstraintRule(name, constraint string) string {
+	constraint = strings.TrimSpace(constraint)
+
+	// Aliases (npm:pkg@range) are checked by their range
+	if rest, ok := strings.CutPrefix(constraint, "npm:"); ok {
+		if i := strings.LastIndex(rest, "@"); i > 0 {
+			return npmConstraintRule(name, rest[i+1:])
+		}
+		return PolicyRuleWildcard
+	}
+
+	// Local packages are part of the project
+	for _, prefix := range []string{"file:", "link:", "workspace:", "portal:"} {
+		if strings.HasPrefix(constraint, prefix) {
+			return ""
+		}
+	}
+
+	// Git dependencies follow a branch unless they name a commit, a tag or a semver range
+	isGit := npmGitHubShorthand.MatchString(constraint)
+	for _, prefix := range []string{"git:", "git+", "github:", "gitlab:", "bitbucket:", "gist:"} {
+		if strings.HasPrefix(constraint, prefix) {
+			isGit = true
+		}
+	}
+	if isGit {
+		if _, ref, ok := strings.Cut(constraint, "#"); ok && npmPinnedRef.MatchString(ref) {
+			return ""
+		}
+		return PolicyRuleBranch
+	}
+
+	// Tarball URLs point at a fixed file
+	if strings.HasPrefix(constraint, "http:") || strings.HasPrefix(constraint, "https:") {
+		return ""
+	}
+
+	if isWildcardConstraint(constraint) {
+		return PolicyRuleWildcard
+	}
+	if npmDistTag.MatchString(constraint) && !npmVersionPrefix.MatchString(constraint) {
+		return PolicyRuleTag
+	}
+	if isUnboundedConstraint(constraint) {
+		return PolicyRuleUnbounded
+	}
+	return ""
+}
+
+// composerConstraintRule returns the policy rule violated by a Composer constraint, or "".
+// Platform requirements (php, ext-*, lib-*) have no vendor prefix and are not checked.
+func composerConstraintRule(name, constraint string) string {
+	if !strings.Contains(name, "/") {
+		return ""
+	}
+
+	constraint = strings.TrimSpace(constraint)
+
+	for _, alternative := range splitConstraintAlternatives(constraint) {
+		// Strip a stability flag (^1.0@beta); "*@dev" and "@dev" alone stay wildcards
+		version, _, _ := strings.Cut(alternative, "@")
+		version = strings.TrimSpace(version)
+
+		if strings.HasPrefix(version, "dev-") || strings.HasSuffix(version, "-dev") {
+			return PolicyRuleBranch
+		}
+	}
+
+	if isWildcardConstraint(strings.Split(constraint, "@")[0]) {
+		return PolicyRuleWildcard
+	}
+	if isUnboundedConstraint(constraint) {
+		return PolicyRuleUnbounded
+	}
+	return ""
+}
+
+// isWildcardConstraint reports whether any alternative of a constraint accepts every version
+// ("", "*", "x", "*.*")
+func isWildcardConstraint(constraint string) bool {
+	alternatives := splitConstraintAlternatives(constraint)
+	if len(alternatives) == 0 {
+		return true
+	}
+	for _, alternative := range alternatives {
+		if strings.Trim(alternative, "*xX. ") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnboundedConstraint reports whether any alternative of a constraint has a lower
+// bound only (">=1.0"), accepting every future major version
+func isUnboundedConstraint(constraint string) bool {
+	for _, alternative := range splitConstraintAlternatives(constraint) {
+		if strings.Contains(alternative, ">") && !strings.Contains(alternative, "<") {
+			return true
+		}
+	}
+	return false
+}
+
+// splitConstraintAlternatives splits a constraint on "||" (npm and Composer) and "|" (Composer)
+func splitConstraintAlternatives(constraint string) []string {
+	var alternatives []string
+	for _, part := range strings.FieldsFunc(constraint, func(r rune) bool { return r == '|' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			alternatives = append(alternatives, part)
+		}
+	}
+	return alternatives
+}
+
+// npmLockedVersions returns the installed version of each top-level package in a package-lock.json
+func npmLockedVersions(content []byte) map[string]string {
+	versions := make(map[string]string)
+
+	var lock npmLockfile
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return versions
+	}
+
+	for path, pkg := range lock.Packages {
+		if name, ok := strings.CutPrefix(path, "node_modules/"); ok && !strings.Contains(name, "/node_modules/") {
+			versions[name] = pkg.Version
+		}
+	}
+	for name, dep := range lock.Dependencies {
+		if _, ok := versions[name]; !ok {
+			versions[name] = dep.Version
+		}
+	}
+
+	return versions
+}
+
+// composerLockedVersions returns the locked version of each package in a composer.lock
+func composerLockedVersions(content []byte) map[string]string {
+	versions := make(map[string]string)
+
+	var lock composerLockfile
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return versions
+	}
+
+	for _, pkg := range append(lock.Packages, lock.PackagesDev...) {
+		// Branch checkouts have no release to suggest
+		if !strings.HasPrefix(pkg.Version, "dev-") {
+			versions[pkg.Name] = pkg.Version
+		}
+	}
+
+	return versions
+}
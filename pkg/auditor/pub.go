@@ -0,0 +1,161 @@
+package auditor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// PubAuditor implements the Auditor interface for Dart and Flutter projects.
+// Dart has no audit command, so pubspec.lock is read directly and its hosted
+// packages are looked up on OSV.dev (sharing the OSV auditor's client and cache).
+type PubAuditor struct {
+	osv *OSVAuditor
+}
+
+// NewPubAuditor creates a new PubAuditor that queries OSV.dev through osv
+func NewPubAuditor(osv *OSVAuditor) *PubAuditor {
+	return &PubAuditor{osv: osv}
+}
+
+// Name returns "pub"
+func (a *PubAuditor) Name() string {
+	return "pub"
+}
+
+// Detect checks for pubspec.lock
+func (a *PubAuditor) Detect(path string) bool {
+	return FileExists(JoinPath(path, "pubspec.lock"))
+}
+
+// Audit reads pubspec.lock and looks up its packages on OSV.dev
+func (a *PubAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running pub audit for app=%s path=%s", app.Name, app.Path)
+
+	content, err := os.ReadFile(JoinPath(app.Path, "pubspec.lock"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("pubspec.lock not found in %s (run 'dart pub get' or 'flutter pub get')", app.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pubspec.lock: %w", err)
+	}
+
+	packages, err := parsePubspecLock(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pubspec.lock: %w", err)
+	}
+
+	vulns, raw, err := a.osv.lookupPackages(ctx, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: vulns,
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	rawOutput, _ := json.Marshal(raw)
+	result.RawOutput = string(rawOutput)
+	result.AuditorType = a.Name()
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("pub audit completed for app=%s packages=%d total=%d critical=%d high=%d",
+		app.Name,
+		len(packages),
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// parsePubspecLock lists the hosted packages of a pubspec.lock. The file is
+// generated by pub in a fixed layout, so it is read line by line:
+//
+//	packages:
+//	  http:
+//	    dependency: "direct main"
+//	    source: hosted
+//	    version: "1.2.0"
+//
+// SDK, path and git packages are skipped: OSV only knows pub.dev releases.
+func parsePubspecLock(content []byte) ([]osvLockfilePackage, error) {
+	var packages []osvLockfilePackage
+	inPackages := false
+	found := false
+
+	var name, version, source, dependency string
+	flush := func() {
+		if name != "" && version != "" && source == "hosted" {
+			packages = append(packages, osvLockfilePackage{
+				Name:      name,
+				Version:   version,
+				Ecosystem: osvEcosystemPub,
+				Dev:       dependency == "direct dev",
+				Lockfile:  "pubspec.lock",
+			})
+		}
+		name, version, source, dependency = "", "", "", ""
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		key, value, _ := strings.Cut(strings.TrimSpace(line), ":")
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+
+		switch {
+		case indent == 0:
+			flush()
+			inPackages = key == "packages"
+			found = found || inPackages
+		case !inPackages:
+			continue
+		case indent == 2:
+			flush()
+			name = strings.Trim(key, `"'`)
+		case indent == 4:
+			switch key {
+			case "version":
+				version = value
+			case "source":
+				source = value
+			case "dependency":
+				dependency = value
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	if !found {
+		return nil, fmt.Errorf("no packages section")
+	}
+
+	sortLockfilePackages(packages)
+	return packages, nil
+}
@@ -0,0 +1,331 @@
+package auditor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// SecretRulePrefix prefixes the secret rule IDs (e.g. SECRET-AWS-ACCESS-KEY), reported
+// in the CVE field so they can be added to an app's ignore list
+const SecretRulePrefix = "SECRET-"
+
+const (
+	// secretMaxFileSize is the size above which files are not scanned (bundles, dumps, assets)
+	secretMaxFileSize = 1 << 20
+
+	// secretAllowMarker on a line suppresses its findings, as in gitleaks
+	secretAllowMarker = "gitleaks:allow"
+)
+
+// secretRule is a kind of credential found by a regular expression. The secret is the
+// first group of the pattern. Rules with a minimum entropy match generic assignments
+// and only report values random enough to be generated credentials.
+type secretRule struct {
+	id          string
+	description string
+	pattern     *regexp.Regexp
+	minEntropy  float64
+}
+
+var secretRules = []secretRule{
+	{"PRIVATE-KEY", "Private key",
+		regexp.MustCompile(`(-----BEGIN (?:RSA |EC |DSA |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----)`), 0},
+	{"AWS-ACCESS-KEY", "AWS access key ID",
+		regexp.MustCompile(`\b((?:AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16})\b`), 0},
+	{"AWS-SECRET-KEY", "AWS secret access key",
+		regexp.MustCompile(`(?i)aws_?secret_?(?:access_?)?key['"]?\s*(?:[:=]|=>)\s*['"]?([A-Za-z0-9/+=]{40})\b`), 0},
+	{"GITHUB-TOKEN", "GitHub token",
+		regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`), 0},
+	{"GITLAB-TOKEN", "GitLab access token",
+		regexp.MustCompile(`\b(glpat-[A-Za-z0-9_\-]{20})\b`), 0},
+	{"SLACK-TOKEN", "Slack token",
+		regexp.MustCompile(`\b(xox[abprs]-[A-Za-z0-9\-]{10,})\b`), 0},
+	{"SLACK-WEBHOOK", "Slack webhook URL",
+		regexp.MustCompile(`(https://hooks\.slack\.com/services/T[A-Za-z0-9_]+/B[A-Za-z0-9_]+/[A-Za-z0-9_]+)`), 0},
+	{"STRIPE-KEY", "Stripe live key",
+		regexp.MustCompile(`\b((?:sk|rk)_live_[A-Za-z0-9]{24,})\b`), 0},
+	{"GOOGLE-API-KEY", "Google API key",
+		regexp.MustCompile(`\b(AIza[A-Za-z0-9_\-]{35})\b`), 0},
+	{"SENDGRID-KEY", "SendGrid API key",
+		regexp.MustCompile(`\b(SG\.[A-Za-z0-9_\-]{22}\.[A-Za-z0-9_\-]{43})\b`), 0},
+	{"NPM-TOKEN", "npm access token",
+		regexp.MustCompile(`\b(npm_[A-Za-z0-9]{36})\b`), 0},
+	{"TELEGRAM-BOT-TOKEN", "Telegram bot token",
+		regexp.MustCompile(`\b([0-9]{8,10}:AA[A-Za-z0-9_\-]{33})\b`), 0},
+	// Quoted values assigned to credential-like keys in code and config (password: "...", 'api_key' => '...')
+	{"GENERIC", "Credential assigned in code",
+		regexp.MustCompile(`(?i)(?:api[_\-]?key|secret|token|passw(?:or)?d|credentials?)[A-Za-z0-9_\-]*['"]?\s*(?:[:=]|=>)\s*['"]([A-Za-z0-9/+_=\-.]{16,})['"]`), 3.5},
+	// Unquoted dotenv values (DB_PASSWORD=...)
+	{"GENERIC", "Credential assigned in an environment file",
+		regexp.MustCompile(`^\s*(?:export\s+)?[A-Z0-9_]*(?:KEY|SECRET|TOKEN|PASSWORD|PASSWD)[A-Z0-9_]*\s*=\s*['"]?([^\s'"#]{16,})`), 3.5},
+}
+
+// secretPlaceholders are substrings of documentation values that are not secrets
+var secretPlaceholders = []string{"example", "xxxxx", "changeme", "change_me", "your", "placeholder", "dummy", "sample", "redacted", "123456", "abcdef", "****", "<", "${", "{{"}
+
+// secretSkippedDirs are directories never scanned when the app is not a git repository
+var secretSkippedDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// secretSkippedFiles are generated files full of hashes that look like secrets
+var secretSkippedFiles = map[string]bool{
+	"package-lock.json": true, "npm-shrinkwrap.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"composer.lock": true, "go.sum": true, "Cargo.lock": true, "packages.lock.json": true, "pubspec.lock": true,
+}
+
+// SecretsAuditor implements the Auditor interface for committed credentials. It scans
+// the app's files with gitleaks-style rules: patterns of well-known token formats, and
+// credential-like assignments whose value has high entropy. In a git repository only
+// tracked files are scanned; otherwise local environment files (.env, .env.local),
+// which are where deployed credentials belong, are skipped.
+type SecretsAuditor struct {
+	autoDetect bool
+}
+
+// NewSecretsAuditor creates a new SecretsAuditor. When autoDetect is false it only
+// runs for apps whose type lists it explicitly.
+func NewSecretsAuditor(autoDetect bool) *SecretsAuditor {
+	return &SecretsAuditor{autoDetect: autoDetect}
+}
+
+// Name returns "secrets"
+func (a *SecretsAuditor) Name() string {
+	return "secrets"
+}
+
+// Detect checks for a git repository
+func (a *SecretsAuditor) Detect(path string) bool {
+	if !a.autoDetect {
+		return false
+	}
+	return FileExists(JoinPath(path, ".git"))
+}
+
+// Audit scans the app's files for credentials
+func (a *SecretsAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running secrets audit for app=%s path=%s", app.Name, app.Path)
+
+	files, tracked, err := secretScanFiles(ctx, app.Path)
+	if err != nil {
+		return nil, err
+	}
+	if !tracked {
+		log.Debugf("Not a git repository, scanning all files except local environment files for app=%s", app.Name)
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+
+	scanned := 0
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if IsIgnoredPath(file, app.IgnorePaths) || secretSkippedFiles[filepath.Base(file)] {
+			continue
+		}
+
+		findings, ok, err := scanFileForSecrets(app.Path, file)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			scanned++
+		}
+		result.Vulnerabilities = append(result.Vulnerabilities, findings...)
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	rawOutput, _ := json.Marshal(result.Vulnerabilities)
+	result.RawOutput = string(rawOutput)
+	result.AuditorType = a.Name()
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("secrets audit completed for app=%s files=%d git=%t total=%d",
+		app.Name,
+		scanned,
+		tracked,
+		result.TotalVulnerabilities,
+	)
+
+	return result, nil
+}
+
+// secretScanFiles returns the files to scan, relative to root: the files tracked by git
+// when root is a git repository (tracked is then true), or every file outside the
+// skipped directories and local environment files
+func secretScanFiles(ctx context.Context, root string) (files []string, tracked bool, err error) {
+	if FileExists(JoinPath(root, ".git")) {
+		if _, err := exec.LookPath("git"); err == nil {
+			cmd := exec.CommandContext(ctx, "git", "-C", root, "ls-files", "-z")
+			output, err := cmd.Output()
+			if err != nil {
+				return nil, false, fmt.Errorf("git ls-files failed: %w", err)
+			}
+			for _, file := range strings.Split(string(output), "\x00") {
+				if file != "" {
+					files = append(files, filepath.FromSlash(file))
+				}
+			}
+			return files, true, nil
+		}
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && secretSkippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || isLocalEnvFile(d.Name()) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to list files in %s: %w", root, err)
+	}
+	return files, false, nil
+}
+
+// isLocalEnvFile reports whether name is an environment file holding the deployment's
+// own credentials (.env, .env.local, .env.production.local), not a committed template
+func isLocalEnvFile(name string) bool {
+	return name == ".env" || (strings.HasPrefix(name, ".env.") && strings.HasSuffix(name, ".local"))
+}
+
+// scanFileForSecrets returns the secrets found in file (relative to root). ok is false
+// when the file was skipped: missing (deleted but tracked), too large or binary.
+func scanFileForSecrets(root, file string) (findings []models.Vulnerability, ok bool, err error) {
+	path := filepath.Join(root, file)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if !info.Mode().IsRegular() || info.Size() > secretMaxFileSize {
+		return nil, false, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	if bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0 {
+		return nil, false, nil
+	}
+
+	file = filepath.ToSlash(file)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), secretMaxFileSize)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		if strings.Contains(line, secretAllowMarker) {
+			continue
+		}
+
+		// Each rule is reported once per line, and a secret only by the first (most specific) rule matching it
+		reportedRules, reportedSecrets := make(map[string]bool), make(map[string]bool)
+		for _, rule := range secretRules {
+			if reportedRules[rule.id] {
+				continue
+			}
+			for _, match := range rule.pattern.FindAllStringSubmatch(line, -1) {
+				secret := match[1]
+				if reportedSecrets[secret] || isSecretPlaceholder(secret) ||
+					(rule.minEntropy > 0 && shannonEntropy(secret) < rule.minEntropy) {
+					continue
+				}
+				findings = append(findings, secretFinding(file, lineNo, rule, secret))
+				reportedRules[rule.id], reportedSecrets[secret] = true, true
+				break
+			}
+		}
+	}
+	return findings, true, nil
+}
+
+// isSecretPlaceholder reports whether a matched value is a documentation placeholder
+func isSecretPlaceholder(value string) bool {
+	lower := strings.ToLower(value)
+	for _, placeholder := range secretPlaceholders {
+		if strings.Contains(lower, placeholder) {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// redactSecret keeps enough of a secret to recognise it, and never the whole value
+func redactSecret(secret string) string {
+	if strings.HasPrefix(secret, "-----BEGIN") {
+		return secret
+	}
+	return secret[:min(4, len(secret)/4)] + "****"
+}
+
+// secretFinding reports a credential found on a line of file. The secret itself is
+// redacted: findings end up in reports, notifications and the database.
+func secretFinding(file string, line int, rule secretRule, secret string) models.Vulnerability {
+	return models.Vulnerability{
+		PackageName: file,
+		Severity:    models.SeverityCritical,
+		CVEID:       SecretRulePrefix + rule.id,
+		Title:       fmt.Sprintf("%s in %s:%d", rule.description, file, line),
+		Description: fmt.Sprintf("%s committed on line %d of %s (%s). Anyone with access to the repository "+
+			"or a copy of it can use it.", rule.description, line, file, redactSecret(secret)),
+		Recommendation: fmt.Sprintf("Revoke and rotate the credential, then remove it from %s and from the repository "+
+			"history (e.g. git filter-repo) and read it from the environment instead. If it is not a secret, "+
+			"add %q in a comment on the line", file, secretAllowMarker),
+		VulnerableVersions: fmt.Sprintf("line %d", line),
+	}
+}
@@ -0,0 +1,386 @@
+package auditor
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Supply-chain rule IDs, reported in the CVE field so they can be added to an app's ignore list
+const (
+	SupplyChainRuleTyposquat     = "SUPPLY-CHAIN-TYPOSQUAT"
+	SupplyChainRuleInstallScript = "SUPPLY-CHAIN-INSTALL-SCRIPT"
+)
+
+const (
+	npmRegistryURL = "https://registry.npmjs.org"
+
+	// supplyChainRecentVersions is how many releases back an added install script counts as recent
+	supplyChainRecentVersions = 3
+
+	// typosquatMinLength is the shortest popular name matched one edit away; shorter
+	// names have too many legitimate neighbours (ms, qs, ws)
+	typosquatMinLength = 5
+)
+
+//go:embed npm_popular.txt
+var npmPopularList string
+
+// npmPopularPackages is the popular-package corpus typosquats are compared against, npmPopularNames its names in order
+var (
+	npmPopularPackages = parsePackageList(npmPopularList)
+	npmPopularNames    = slices.Sorted(maps.Keys(npmPopularPackages))
+)
+
+// errPackageNotFound is returned by the registry for packages it does not host
+var errPackageNotFound = errors.New("package not found")
+
+// SupplyChainAuditor implements the Auditor interface with supply-chain heuristics
+// for npm packages. It reads package-lock.json and flags installed packages whose
+// name is one typo away from a popular package, and packages whose installed
+// version added an install script that earlier releases did not have (a common
+// sign of a hijacked maintainer account).
+type SupplyChainAuditor struct {
+	autoDetect bool
+	npmOptions map[string]string
+	client     *http.Client
+}
+
+// NewSupplyChainAuditor creates a new SupplyChainAuditor. The registry is read from
+// the npm registry option (npmOptions, or the app's npm.registry). When autoDetect is
+// false it only runs for apps whose type lists it explicitly.
+func NewSupplyChainAuditor(autoDetect bool, npmOptions map[string]string) *SupplyChainAuditor {
+	return &SupplyChainAuditor{
+		autoDetect: autoDetect,
+		npmOptions: npmOptions,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns "supplychain"
+func (a *SupplyChainAuditor) Name() string {
+	return "supplychain"
+}
+
+// Detect checks for package-lock.json
+func (a *SupplyChainAuditor) Detect(path string) bool {
+	if !a.autoDetect {
+		return false
+	}
+	return FileExists(JoinPath(path, "package-lock.json"))
+}
+
+// installScriptPackage is an installed package version that runs an install script
+type installScriptPackage struct {
+	name    string
+	version string
+}
+
+// Audit checks the packages of the app's package-lock.json
+func (a *SupplyChainAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running supplychain audit for app=%s path=%s", app.Name, app.Path)
+
+	content, err := os.ReadFile(JoinPath(app.Path, "package-lock.json"))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("package-lock.json not found in %s (run 'npm install')", app.Path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package-lock.json: %w", err)
+	}
+
+	packages, err := parseNpmLockfile(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse package-lock.json: %w", err)
+	}
+
+	options, err := AppOptions("npm", a.npmOptions, app)
+	if err != nil {
+		return nil, err
+	}
+	registry := strings.TrimSuffix(options["registry"], "/")
+	if registry == "" {
+		registry = npmRegistryURL
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+
+	// Typosquats: the name is checked once, with every installed version
+	versions := make(map[string][]string)
+	for _, pkg := range packages {
+		if !IgnoredPaths(app, pkg.Paths...) {
+			versions[pkg.Name] = append(versions[pkg.Name], pkg.Version)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(versions)) {
+		if popular, reason := typosquatTarget(name); popular != "" {
+			result.Vulnerabilities = append(result.Vulnerabilities,
+				typosquatFinding(name, strings.Join(versions[name], ", "), popular, reason))
+		}
+	}
+
+	// Install scripts, recorded by version 2 and 3 lockfiles
+	scripts := installScriptPackages(content, app)
+	for _, pkg := range scripts {
+		packument, err := a.fetchPackument(ctx, registry, pkg.name)
+		if errors.Is(err, errPackageNotFound) {
+			log.Debugf("Skipping install script check for package=%s: not on registry=%s", pkg.name, registry)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if added, previous := installScriptAdded(packument, pkg.version); added != "" {
+			result.Vulnerabilities = append(result.Vulnerabilities, installScriptFinding(pkg, added, previous))
+		}
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	rawOutput, _ := json.Marshal(result.Vulnerabilities)
+	result.RawOutput = string(rawOutput)
+	result.AuditorType = a.Name()
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("supplychain audit completed for app=%s packages=%d install_scripts=%d total=%d",
+		app.Name,
+		len(versions),
+		len(scripts),
+		result.TotalVulnerabilities,
+	)
+
+	return result, nil
+}
+
+// typosquatTarget returns the popular package a name imitates and how, or "" when
+// the name is popular itself or not close to any popular name
+func typosquatTarget(name string) (string, string) {
+	if npmPopularPackages[name] {
+		return "", ""
+	}
+
+	normalized := normalizePackageName(name)
+	for _, popular := range npmPopularNames {
+		if normalizePackageName(popular) == normalized {
+			return popular, "only separators or case differ"
+		}
+		if len(popular) >= typosquatMinLength && editDistance(name, popular) == 1 {
+			return popular, "one character differs"
+		}
+	}
+	return "", ""
+}
+
+// normalizePackageName lowercases a package name and removes its separators
+func normalizePackageName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || r == '.' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
+
+// editDistance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and adjacent transpositions
+func editDistance(a, b string) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+// typosquatFinding reports a package whose name imitates a popular package
+func typosquatFinding(name, versions, popular, reason string) models.Vulnerability {
+	return models.Vulnerability{
+		PackageName: name,
+		Severity:    models.SeverityHigh,
+		CVEID:       SupplyChainRuleTyposquat,
+		Title:       fmt.Sprintf("Possible typosquat of %s", popular),
+		Description: fmt.Sprintf("%s looks like the popular package %s (%s). Typosquats imitate popular names "+
+			"to be installed by mistake and often run malicious install scripts.", name, popular, reason),
+		Recommendation: fmt.Sprintf("Check that %s is the intended dependency; if %s was meant: npm uninstall %s && npm install %s",
+			name, popular, name, popular),
+		VulnerableVersions: versions,
+		URL:                "https://www.npmjs.com/package/" + name,
+	}
+}
+
+// installScriptPackages returns the packages of a version 2 or 3 package-lock.json
+// that run an install script, once per name and version
+func installScriptPackages(content []byte, app models.AppConfig) []installScriptPackage {
+	var lock npmLockfile
+	if err := json.Unmarshal(content, &lock); err != nil {
+		return nil
+	}
+
+	seen := make(map[installScriptPackage]bool)
+	var packages []installScriptPackage
+	for path, pkg := range lock.Packages {
+		i := strings.LastIndex(path, "node_modules/")
+		if !pkg.HasInstallScript || pkg.Link || i < 0 || IgnoredPaths(app, path) {
+			continue
+		}
+		name := pkg.Name
+		if name == "" {
+			name = path[i+len("node_modules/"):]
+		}
+		key := installScriptPackage{name: name, version: pkg.Version}
+		if !seen[key] {
+			seen[key] = true
+			packages = append(packages, key)
+		}
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		if packages[i].name != packages[j].name {
+			return packages[i].name < packages[j].name
+		}
+		return packages[i].version < packages[j].version
+	})
+	return packages
+}
+
+// npmPackument is the abbreviated registry metadata of a package
+type npmPackument struct {
+	Versions map[string]struct {
+		HasInstallScript bool `json:"hasInstallScript"`
+	} `json:"versions"`
+}
+
+// fetchPackument reads the abbreviated metadata of a package from the registry
+func (a *SupplyChainAuditor) fetchPackument(ctx context.Context, registry, name string) (*npmPackument, error) {
+	endpoint := registry + "/" + url.PathEscape(name)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.npm.install-v1+json")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("npm registry request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errPackageNotFound
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("npm registry error for %s: status %d: %s", name, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var packument npmPackument
+	if err := json.NewDecoder(resp.Body).Decode(&packument); err != nil {
+		return nil, fmt.Errorf("failed to parse npm registry response for %s: %w", name, err)
+	}
+	return &packument, nil
+}
+
+// installScriptAdded returns the release that added the install script of version and
+// the last release before it without one, when that happened within the last
+// supplyChainRecentVersions releases. Pre-releases are skipped unless version is one.
+func installScriptAdded(packument *npmPackument, version string) (string, string) {
+	prerelease := strings.Contains(version, "-")
+	var releases []string
+	for v := range packument.Versions {
+		if v == version || prerelease || !strings.Contains(v, "-") {
+			releases = append(releases, v)
+		}
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if c := helpers.CompareVersions(releases[i], releases[j]); c != 0 {
+			return c < 0
+		}
+		return releases[i] < releases[j]
+	})
+
+	current := -1
+	for i, v := range releases {
+		if v == version {
+			current = i
+			break
+		}
+	}
+	if current < 0 {
+		return "", ""
+	}
+
+	for i := current; i > 0 && current-i < supplyChainRecentVersions; i-- {
+		if !packument.Versions[releases[i-1]].HasInstallScript {
+			return releases[i], releases[i-1]
+		}
+	}
+	return "", ""
+}
+
+// installScriptFinding reports a package whose install script was added recently
+func installScriptFinding(pkg installScriptPackage, added, previous string) models.Vulnerability {
+	return models.Vulnerability{
+		PackageName: pkg.name,
+		Severity:    models.SeverityHigh,
+		CVEID:       SupplyChainRuleInstallScript,
+		Title:       fmt.Sprintf("Install script added in %s", added),
+		Description: fmt.Sprintf("%s@%s runs an install script (preinstall, install or postinstall) that %s did not have. "+
+			"An install script appearing in a new release is a common sign of a compromised package.", pkg.name, pkg.version, previous),
+		Recommendation: fmt.Sprintf("Review the install script of %s@%s before trusting it; until then pin %s or install with --ignore-scripts",
+			pkg.name, added, previous),
+		VulnerableVersions: pkg.version,
+		URL:                "https://www.npmjs.com/package/" + pkg.name + "?activeTab=versions",
+	}
+}
+
+// parsePackageList parses a list of package names, one per line; blank lines and # comments are skipped
+func parsePackageList(list string) map[string]bool {
+	packages := make(map[string]bool)
+	for _, line := range strings.Split(list, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			packages[line] = true
+		}
+	}
+	return packages
+}
@@ -0,0 +1,315 @@
+package auditor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// System audit backends
+const (
+	SystemBackendAuto     = "auto"
+	SystemBackendDnf      = "dnf"
+	SystemBackendDebsecan = "debsecan"
+	SystemBackendApt      = "apt"
+)
+
+// SystemAuditor implements the Auditor interface for the host's OS packages.
+// It is never auto-detected; add an app with --type system to audit the host.
+type SystemAuditor struct {
+	backend string
+}
+
+// NewSystemAuditor creates a new SystemAuditor using the given backend
+// (auto-detected when empty or "auto")
+func NewSystemAuditor(backend string) *SystemAuditor {
+	if backend == "" {
+		backend = SystemBackendAuto
+	}
+	return &SystemAuditor{backend: backend}
+}
+
+// Name returns "system"
+func (a *SystemAuditor) Name() string {
+	return "system"
+}
+
+// Detect always returns false: the host is not a project, so it must be selected explicitly
+func (a *SystemAuditor) Detect(path string) bool {
+	return false
+}
+
+// Audit lists pending security updates for the host's packages
+func (a *SystemAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	backend, err := a.resolveBackend()
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Running system audit (%s) for app=%s", backend, app.Name)
+
+	var (
+		args        []string
+		parse       func(string) []models.Vulnerability
+		toolVersion string
+	)
+
+	switch backend {
+	case SystemBackendDnf:
+		args = []string{"updateinfo", "list", "--security", "--available"}
+		parse = parseDnfUpdateinfo
+		if v := ToolVersion(ctx, "dnf", "--version"); v != "" {
+			toolVersion = "dnf " + v
+		}
+	case SystemBackendDebsecan:
+		// debsecan has no --version flag
+		parse = parseDebsecan
+	case SystemBackendApt:
+		args = []string{"list", "--upgradable"}
+		parse = parseAptUpgradable
+		if v := ToolVersion(ctx, "apt", "--version"); v != "" {
+			toolVersion = "apt " + v
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, backend, args...)
+	// Untranslated output is required for parsing
+	cmd.Env = append(cmd.Environ(), "LC_ALL=C")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" {
+			errMsg = err.Error()
+		}
+		return nil, fmt.Errorf("%s failed: %s", backend, errMsg)
+	}
+
+	output := stdout.String()
+
+	result := &models.AuditResult{
+		Vulnerabilities: parse(output),
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	result.RawOutput = output
+	result.AuditorType = a.Name()
+	result.ToolVersion = toolVersion
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("system audit completed for app=%s total=%d critical=%d high=%d",
+		app.Name,
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// resolveBackend returns the configured backend, or the first one available on the host
+func (a *SystemAuditor) resolveBackend() (string, error) {
+	switch a.backend {
+	case SystemBackendDnf, SystemBackendDebsecan, SystemBackendApt:
+		if _, err := exec.LookPath(a.backend); err != nil {
+			return "", fmt.Errorf("%s not found in PATH: %w", a.backend, err)
+		}
+		return a.backend, nil
+	case SystemBackendAuto:
+		// debsecan knows CVE urgencies, so it is preferred over apt on Debian
+		for _, backend := range []string{SystemBackendDnf, SystemBackendDebsecan, SystemBackendApt} {
+			if _, err := exec.LookPath(backend); err == nil {
+				return backend, nil
+			}
+		}
+		return "", fmt.Errorf("no supported package manager found (dnf, debsecan or apt)")
+	default:
+		return "", fmt.Errorf("unknown system audit backend %q (use auto, dnf, debsecan or apt)", a.backend)
+	}
+}
+
+// parseDnfUpdateinfo parses `dnf updateinfo list --security` output. dnf4 prints
+// "ADVISORY SEVERITY/Sec. NEVRA"; dnf5 prints "ADVISORY security SEVERITY NEVRA ISSUED".
+func parseDnfUpdateinfo(output string) []models.Vulnerability {
+	vulns := make([]models.Vulnerability, 0)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+
+		advisory, severity, nevra := fields[0], "", ""
+		switch {
+		case strings.HasSuffix(fields[1], "/Sec."):
+			severity, nevra = strings.TrimSuffix(fields[1], "/Sec."), fields[2]
+		case fields[1] == "security" && len(fields) >= 4:
+			severity, nevra = fields[2], fields[3]
+		default:
+			// Headers, metadata messages and non-security advisories
+			continue
+		}
+
+		name, version := splitNEVRA(nevra)
+		vulns = append(vulns, models.Vulnerability{
+			PackageName:     name,
+			Severity:        dnfSeverity(severity),
+			CVEID:           advisory,
+			Title:           fmt.Sprintf("%s security update for %s", severity, name),
+			Description:     fmt.Sprintf("Advisory: %s. Fixed in %s.", advisory, nevra),
+			Recommendation:  fmt.Sprintf("Run 'dnf upgrade --advisory=%s' (or 'dnf upgrade --security').", advisory),
+			PatchedVersions: version,
+		})
+	}
+
+	return vulns
+}
+
+// dnfSeverity maps Red Hat severity ratings to severity levels
+func dnfSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case "critical":
+		return models.SeverityCritical
+	case "important":
+		return models.SeverityHigh
+	case "moderate":
+		return models.SeverityModerate
+	case "low":
+		return models.SeverityLow
+	default:
+		// Advisories without a rating are still security fixes
+		return models.SeverityModerate
+	}
+}
+
+// splitNEVRA splits "name-[epoch:]version-release.arch" into the name and "[epoch:]version-release"
+func splitNEVRA(nevra string) (string, string) {
+	s := nevra
+	if i := strings.LastIndex(s, "."); i > 0 {
+		s = s[:i]
+	}
+
+	releaseIdx := strings.LastIndex(s, "-")
+	if releaseIdx <= 0 {
+		return nevra, ""
+	}
+	versionIdx := strings.LastIndex(s[:releaseIdx], "-")
+	if versionIdx <= 0 {
+		return nevra, ""
+	}
+
+	return s[:versionIdx], s[versionIdx+1:]
+}
+
+// debsecanLinePattern matches debsecan summary lines,
+// e.g. "CVE-2023-0286 openssl (remotely exploitable, fixed, high urgency)"
+var debsecanLinePattern = regexp.MustCompile(`^(\S+)\s+(\S+)(?:\s+\((.*)\))?$`)
+
+// parseDebsecan parses debsecan summary output. Vulnerabilities without a fix
+// are reported as info, since there is nothing to install yet.
+func parseDebsecan(output string) []models.Vulnerability {
+	vulns := make([]models.Vulnerability, 0)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		m := debsecanLinePattern.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		id, pkg := m[1], m[2]
+
+		var fixed, remote bool
+		severity := models.SeverityLow
+		for _, attr := range strings.Split(m[3], ",") {
+			switch strings.TrimSpace(attr) {
+			case "fixed":
+				fixed = true
+			case "remotely exploitable":
+				remote = true
+			case "high urgency":
+				severity = models.SeverityHigh
+			case "medium urgency":
+				severity = models.SeverityModerate
+			}
+		}
+
+		recommendation := fmt.Sprintf("Run 'apt-get install --only-upgrade %s'.", pkg)
+		if !fixed {
+			severity = models.SeverityInfo
+			recommendation = "No fixed package is available yet."
+		}
+
+		description := fmt.Sprintf("%s affects the installed %s package.", id, pkg)
+		if remote {
+			description += " It is remotely exploitable."
+		}
+
+		url := "https://security-tracker.debian.org/tracker/" + id
+
+		vulns = append(vulns, models.Vulnerability{
+			PackageName:    pkg,
+			Severity:       severity,
+			CVEID:          id,
+			Title:          fmt.Sprintf("%s in %s", id, pkg),
+			Description:    description,
+			Recommendation: recommendation,
+			URL:            url,
+		})
+	}
+
+	return vulns
+}
+
+// aptUpgradablePattern matches `apt list --upgradable` lines,
+// e.g. "openssl/jammy-security 3.0.2-0ubuntu1.15 amd64 [upgradable from: 3.0.2-0ubuntu1.14]"
+var aptUpgradablePattern = regexp.MustCompile(`^(\S+?)/(\S+)\s+(\S+)\s+\S+\s+\[upgradable from: ([^\]]+)\]`)
+
+// parseAptUpgradable parses `apt list --upgradable` output, keeping only updates from a
+// security origin. apt does not rate updates, so each one is reported as moderate.
+func parseAptUpgradable(output string) []models.Vulnerability {
+	vulns := make([]models.Vulnerability, 0)
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		m := aptUpgradablePattern.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		pkg, origins, candidate, installed := m[1], m[2], m[3], m[4]
+
+		if !strings.Contains(origins, "-security") {
+			continue
+		}
+
+		vulns = append(vulns, models.Vulnerability{
+			PackageName:        pkg,
+			Severity:           models.SeverityModerate,
+			Title:              fmt.Sprintf("Security update available for %s", pkg),
+			Description:        fmt.Sprintf("%s %s can be upgraded to %s from %s.", pkg, installed, candidate, origins),
+			Recommendation:     fmt.Sprintf("Run 'apt-get install --only-upgrade %s'.", pkg),
+			VulnerableVersions: installed,
+			PatchedVersions:    candidate,
+		})
+	}
+
+	return vulns
+}
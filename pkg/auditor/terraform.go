@@ -0,0 +1,213 @@
+package auditor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+// terraformLockfile is the dependency lock file written by terraform init
+const terraformLockfile = ".terraform.lock.hcl"
+
+// terraformLockfilePatterns are where lock files are looked for, relative to the app path,
+// so a repository with one root module per environment (e.g. envs/prod) is a single app
+var terraformLockfilePatterns = []string{terraformLockfile, "*/" + terraformLockfile, "*/*/" + terraformLockfile}
+
+// terraformRegistries are the public registries whose providers are published from GitHub
+var terraformRegistries = []string{"registry.terraform.io", "registry.opentofu.org"}
+
+// TerraformAuditor implements the Auditor interface for Terraform and OpenTofu
+// configurations. Providers are Go programs published from
+// github.com/<namespace>/terraform-provider-<type>, so the versions locked in
+// .terraform.lock.hcl are looked up on OSV.dev as Go modules (sharing the OSV auditor's client and cache).
+type TerraformAuditor struct {
+	osv *OSVAuditor
+}
+
+// NewTerraformAuditor creates a new TerraformAuditor that queries OSV.dev through osv
+func NewTerraformAuditor(osv *OSVAuditor) *TerraformAuditor {
+	return &TerraformAuditor{osv: osv}
+}
+
+// Name returns "terraform"
+func (a *TerraformAuditor) Name() string {
+	return "terraform"
+}
+
+// Detect checks for .terraform.lock.hcl in the app or its root module directories
+func (a *TerraformAuditor) Detect(path string) bool {
+	return len(terraformLockfiles(path)) > 0
+}
+
+// Audit reads the app's lock files and looks up the locked providers on OSV.dev
+func (a *TerraformAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	log.Infof("Running terraform audit for app=%s path=%s", app.Name, app.Path)
+
+	var lockfiles []string
+	for _, lockfile := range terraformLockfiles(app.Path) {
+		if !IgnoredPaths(app, filepath.Dir(lockfile)) {
+			lockfiles = append(lockfiles, lockfile)
+		}
+	}
+	if len(lockfiles) == 0 {
+		return nil, fmt.Errorf("%s not found in %s (run 'terraform init')", terraformLockfile, app.Path)
+	}
+
+	// Root modules usually share providers; each provider version is looked up once
+	seen := make(map[string]bool)
+	var packages []osvLockfilePackage
+	for _, lockfile := range lockfiles {
+		content, err := os.ReadFile(JoinPath(app.Path, lockfile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", lockfile, err)
+		}
+
+		providers, err := parseTerraformLockfile(content, lockfile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", lockfile, err)
+		}
+		for _, pkg := range providers {
+			if key := pkg.Name + "@" + pkg.Version; !seen[key] {
+				seen[key] = true
+				packages = append(packages, pkg)
+			}
+		}
+	}
+	sortLockfilePackages(packages)
+
+	vulns, raw, err := a.osv.lookupPackages(ctx, packages)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.AuditResult{
+		Vulnerabilities: vulns,
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	rawOutput, _ := json.Marshal(raw)
+	result.RawOutput = string(rawOutput)
+	result.AuditorType = a.Name()
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("terraform audit completed for app=%s lockfiles=%d providers=%d total=%d critical=%d high=%d",
+		app.Name,
+		len(lockfiles),
+		len(packages),
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// terraformLockfiles returns the lock files of an app, relative to the app path.
+// The .terraform directories written by terraform init are skipped.
+func terraformLockfiles(dir string) []string {
+	var lockfiles []string
+	for _, pattern := range terraformLockfilePatterns {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		for _, match := range matches {
+			rel, err := filepath.Rel(dir, match)
+			if err != nil || strings.Contains(filepath.ToSlash(rel), ".terraform/") {
+				continue
+			}
+			lockfiles = append(lockfiles, filepath.ToSlash(rel))
+		}
+	}
+	sort.Strings(lockfiles)
+	return lockfiles
+}
+
+// parseTerraformLockfile lists the providers of a .terraform.lock.hcl as Go modules.
+// The file is generated by terraform in a fixed layout, so it is read line by line:
+//
+//	provider "registry.terraform.io/hashicorp/aws" {
+//	  version     = "5.31.0"
+//	  constraints = "~> 5.0"
+//	  hashes = [ ... ]
+//	}
+//
+// Providers from private registries are skipped: they have no public source to match advisories against.
+func parseTerraformLockfile(content []byte, lockfile string) ([]osvLockfilePackage, error) {
+	var packages []osvLockfilePackage
+	var address string
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "provider "):
+			address = strings.Trim(strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(line, "provider "), "{")), `"`)
+		case line == "}":
+			address = ""
+		case address != "" && strings.HasPrefix(line, "version"):
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(key) != "version" {
+				continue
+			}
+			version := strings.Trim(strings.TrimSpace(value), `"`)
+
+			module := terraformProviderModule(address)
+			if module == "" {
+				zap.S().Debugf("Skipping provider from a private registry provider=%s lockfile=%s", address, lockfile)
+				continue
+			}
+			packages = append(packages, osvLockfilePackage{
+				Name:      module,
+				Version:   version,
+				Ecosystem: osvEcosystemGo,
+				Lockfile:  lockfile,
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return packages, nil
+}
+
+// terraformProviderModule maps a provider address (registry.terraform.io/hashicorp/aws)
+// to the Go module it is built from (github.com/hashicorp/terraform-provider-aws)
+func terraformProviderModule(address string) string {
+	parts := strings.Split(strings.ToLower(address), "/")
+	if len(parts) != 3 {
+		return ""
+	}
+	for _, registry := range terraformRegistries {
+		if parts[0] == registry {
+			return "github.com/" + parts[1] + "/terraform-provider-" + parts[2]
+		}
+	}
+	return ""
+}
+
+// terraformProviderName returns the short provider name (hashicorp/aws) of a provider module
+func terraformProviderName(module string) string {
+	namespace, repo, ok := strings.Cut(strings.TrimPrefix(module, "github.com/"), "/")
+	if !ok {
+		return module
+	}
+	return namespace + "/" + strings.TrimPrefix(repo, "terraform-provider-")
+}
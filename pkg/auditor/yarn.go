@@ -0,0 +1,309 @@
+package auditor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// isYarnProject returns true if the app is managed by yarn rather than npm
+func isYarnProject(path string) bool {
+	return FileExists(JoinPath(path, "yarn.lock"))
+}
+
+// auditYarn runs yarn's audit command and parses the results.
+// Yarn 2+ (berry) uses `yarn npm audit`, yarn 1 (classic) uses `yarn audit`.
+func (a *NPMAuditor) auditYarn(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	log := helpers.Logger(ctx)
+
+	// Check if yarn is available
+	if _, err := exec.LookPath("yarn"); err != nil {
+		return nil, fmt.Errorf("yarn.lock found but yarn not found in PATH: %w", err)
+	}
+
+	// yarn --version must run inside the app so that a project-pinned yarnPath is honored
+	version := yarnVersion(ctx, app.Path)
+	if version == "" {
+		return nil, fmt.Errorf("failed to determine yarn version in %s", app.Path)
+	}
+
+	berry := helpers.CompareVersions(version, "2") >= 0
+
+	var args []string
+	if berry {
+		// --all includes every workspace, --recursive includes transitive dependencies
+		args = []string{"npm", "audit", "--all", "--recursive", "--json"}
+	} else {
+		args = []string{"audit", "--json"}
+	}
+
+	log.Infof("Running yarn %s for app=%s path=%s yarn=%s", strings.Join(args, " "), app.Name, app.Path, version)
+
+	cmd := exec.CommandContext(ctx, "yarn", args...)
+	cmd.Dir = app.Path
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	// yarn classic exits with a bitmask of the severities found (1=info ... 16=critical)
+	// and berry exits 1 when vulnerabilities are found, so a non-zero exit code alone
+	// is not an error. Failures are detected from the output instead.
+	runErr := cmd.Run()
+	if runErr != nil {
+		if _, ok := runErr.(*exec.ExitError); !ok {
+			return nil, fmt.Errorf("failed to run yarn audit: %w", runErr)
+		}
+	}
+
+	output := stdout.String()
+	result, reported, err := parseYarnOutput(output, berry, app)
+	if err != nil || (runErr != nil && !reported) {
+		errMsg := strings.TrimSpace(stderr.String())
+		if errMsg == "" && err != nil {
+			errMsg = err.Error()
+		}
+		if errMsg == "" {
+			errMsg = strings.TrimSpace(output)
+		}
+		log.Debugf("yarn audit raw output: %s", output)
+		return nil, fmt.Errorf("yarn audit failed: %s", errMsg)
+	}
+
+	result.RawOutput = output
+	result.AuditorType = a.Name()
+	result.ToolVersion = "yarn " + version
+	result.AppName = app.Name
+	result.AppPath = app.Path
+
+	log.Infof("yarn audit completed for app=%s total=%d critical=%d high=%d",
+		app.Name,
+		result.TotalVulnerabilities,
+		result.CriticalCount,
+		result.HighCount,
+	)
+
+	return result, nil
+}
+
+// yarnVersion returns the yarn version used in the given directory
+func yarnVersion(ctx context.Context, dir string) string {
+	log := helpers.Logger(ctx)
+
+	cmd := exec.CommandContext(ctx, "yarn", "--version")
+	cmd.Dir = dir
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+
+	if err := cmd.Run(); err != nil {
+		log.Debugf("Failed to determine yarn version: %v", err)
+		return ""
+	}
+
+	return helpers.ExtractVersion(strings.TrimSpace(stdout.String()))
+}
+
+// yarnMessage is a single line of yarn's line-delimited JSON output.
+// Classic emits {"type": ..., "data": ...} lines; berry 4 emits one
+// {"value": ..., "children": ...} line per package; berry 2/3 emit a single
+// npm-registry-style object with an "advisories" map.
+type yarnMessage struct {
+	Type       string                 `json:"type"`
+	Data       json.RawMessage        `json:"data"`
+	Value      string                 `json:"value"`
+	Children   *yarnBerryChildren     `json:"children"`
+	Advisories map[string]npmAdvisory `json:"advisories"`
+}
+
+// npmAdvisory is an npm registry advisory as reported by yarn classic, yarn berry 2/3 and pnpm
+type npmAdvisory struct {
+	ID                 int      `json:"id"`
+	ModuleName         string   `json:"module_name"`
+	Severity           string   `json:"severity"`
+	Title              string   `json:"title"`
+	URL                string   `json:"url"`
+	CVEs               []string `json:"cves"`
+	VulnerableVersions string   `json:"vulnerable_versions"`
+	PatchedVersions    string   `json:"patched_versions"`
+	Recommendation     string   `json:"recommendation"`
+	Overview           string   `json:"overview"`
+	Findings           []struct {
+		Version string   `json:"version"`
+		Paths   []string `json:"paths"`
+	} `json:"findings"`
+}
+
+// yarnClassicAdvisory is the data of a classic "auditAdvisory" line
+type yarnClassicAdvisory struct {
+	Resolution struct {
+		ID   int    `json:"id"`
+		Path string `json:"path"`
+		Dev  bool   `json:"dev"`
+	} `json:"resolution"`
+	Advisory npmAdvisory `json:"advisory"`
+}
+
+// yarnBerryChildren is the per-package detail of a berry 4 audit line
+type yarnBerryChildren struct {
+	ID                 any      `json:"ID"`
+	Issue              string   `json:"Issue"`
+	URL                string   `json:"URL"`
+	Severity           string   `json:"Severity"`
+	VulnerableVersions string   `json:"Vulnerable Versions"`
+	TreeVersions       []string `json:"Tree Versions"`
+	Dependents         []string `json:"Dependents"`
+}
+
+// parseYarnOutput parses yarn audit JSON output (classic or berry).
+// reported is true when yarn produced audit data (advisories or a summary),
+// which distinguishes "no vulnerabilities" from "yarn failed before auditing".
+func parseYarnOutput(output string, berry bool, app models.AppConfig) (result *models.AuditResult, reported bool, err error) {
+	result = &models.AuditResult{
+		Vulnerabilities: make([]models.Vulnerability, 0),
+	}
+
+	// Classic reports one auditAdvisory line per dependency path, so dedupe by advisory ID
+	seen := make(map[string]bool)
+	add := func(key string, vuln models.Vulnerability) {
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		reported = true
+		result.Vulnerabilities = append(result.Vulnerabilities, vuln)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		// Berry may interleave plain-text status lines (e.g. "➤ YN0000: ...")
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var msg yarnMessage
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			return nil, false, fmt.Errorf("failed to parse JSON line: %w", err)
+		}
+
+		switch {
+		case msg.Type == "auditAdvisory":
+			var data yarnClassicAdvisory
+			if err := json.Unmarshal(msg.Data, &data); err != nil {
+				return nil, false, fmt.Errorf("failed to parse auditAdvisory: %w", err)
+			}
+			add(strconv.Itoa(data.Advisory.ID), yarnAdvisoryToVulnerability(data.Advisory, berry))
+
+		case msg.Type == "auditSummary":
+			reported = true
+
+		case msg.Type == "error":
+			var text string
+			_ = json.Unmarshal(msg.Data, &text)
+			return nil, false, fmt.Errorf("%s", text)
+
+		case msg.Advisories != nil:
+			reported = true
+			ids := make([]string, 0, len(msg.Advisories))
+			for id := range msg.Advisories {
+				ids = append(ids, id)
+			}
+			sort.Strings(ids)
+			for _, id := range ids {
+				add(id, yarnAdvisoryToVulnerability(msg.Advisories[id], berry))
+			}
+
+		case msg.Children != nil:
+			key := fmt.Sprintf("%v|%s", msg.Children.ID, msg.Value)
+			add(key, yarnBerryToVulnerability(msg.Value, msg.Children))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, false, fmt.Errorf("failed to read output: %w", err)
+	}
+
+	// Filter ignored vulnerabilities
+	result.Vulnerabilities = FilterIgnored(result.Vulnerabilities, app.IgnoreList)
+
+	// Update counts
+	result.UpdateCounts()
+
+	return result, reported, nil
+}
+
+// yarnAdvisoryToVulnerability converts an npm registry advisory to a Vulnerability
+func yarnAdvisoryToVulnerability(adv npmAdvisory, berry bool) models.Vulnerability {
+	cveID := ""
+	if len(adv.CVEs) > 0 {
+		cveID = adv.CVEs[0]
+	}
+
+	description := adv.Overview
+	if description == "" {
+		description = fmt.Sprintf("Vulnerable versions: %s", adv.VulnerableVersions)
+	}
+
+	return models.Vulnerability{
+		PackageName:        adv.ModuleName,
+		Severity:           normalizeSeverity(adv.Severity),
+		CVEID:              cveID,
+		Title:              adv.Title,
+		Description:        description,
+		Recommendation:     buildYarnRecommendation(adv.ModuleName, adv.PatchedVersions, berry),
+		VulnerableVersions: adv.VulnerableVersions,
+		PatchedVersions:    adv.PatchedVersions,
+		URL:                adv.URL,
+	}
+}
+
+// yarnBerryToVulnerability converts a berry 4 audit line to a Vulnerability
+func yarnBerryToVulnerability(pkgName string, c *yarnBerryChildren) models.Vulnerability {
+	description := fmt.Sprintf("Vulnerable versions: %s", c.VulnerableVersions)
+	if len(c.TreeVersions) > 0 {
+		description += fmt.Sprintf(". Installed: %s", strings.Join(c.TreeVersions, ", "))
+	}
+	if len(c.Dependents) > 0 {
+		description += fmt.Sprintf(". Required by: %s", strings.Join(c.Dependents, ", "))
+	}
+
+	return models.Vulnerability{
+		PackageName:        pkgName,
+		Severity:           normalizeSeverity(c.Severity),
+		Title:              c.Issue,
+		Description:        description,
+		Recommendation:     buildYarnRecommendation(pkgName, "", true),
+		VulnerableVersions: c.VulnerableVersions,
+		URL:                c.URL,
+	}
+}
+
+// buildYarnRecommendation creates a recommendation message for yarn projects
+func buildYarnRecommendation(pkgName, patchedVersions string, berry bool) string {
+	var rec strings.Builder
+
+	if patchedVersions != "" && patchedVersions != "<0.0.0" {
+		rec.WriteString(fmt.Sprintf("Update %s to version %s. ", pkgName, patchedVersions))
+	} else if patchedVersions == "<0.0.0" {
+		rec.WriteString(fmt.Sprintf("No patched version of %s is available. ", pkgName))
+	}
+
+	if berry {
+		rec.WriteString(fmt.Sprintf("Run 'yarn up -R %s' to update it wherever it is used.", pkgName))
+	} else {
+		rec.WriteString(fmt.Sprintf("Run 'yarn upgrade %s', or add a \"resolutions\" entry for transitive dependencies.", pkgName))
+	}
+
+	return rec.String()
+}
@@ -0,0 +1,91 @@
+// Package buildinfo describes the build of the running binary: the release version and
+// build details set by main.go (from the release workflow's -ldflags), completed by what
+// the Go toolchain embeds, such as the git commit and whether the tree was dirty.
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Info is the build information of the binary
+type Info struct {
+	Version   string   `json:"version"`
+	Commit    string   `json:"commit,omitempty"`
+	Dirty     bool     `json:"dirty"`
+	BuildTime string   `json:"build_time"`
+	GoVersion string   `json:"go_version"`
+	OS        string   `json:"os"`
+	Arch      string   `json:"arch"`
+	Features  []string `json:"features"` // enabled features and integrations, added by the caller from the configuration
+}
+
+// build holds the values set by main.go
+var build = Info{Version: "dev", BuildTime: "unknown"}
+
+// Set records the build details passed by main.go; empty and "unknown" values are
+// completed from the toolchain's build information
+func Set(version, commit, buildTime, buildOS, buildArch string) {
+	build = Info{
+		Version:   version,
+		Commit:    commit,
+		BuildTime: buildTime,
+		OS:        buildOS,
+		Arch:      buildArch,
+	}
+}
+
+// Version returns the release version ("dev" for local builds)
+func Version() string {
+	return build.Version
+}
+
+// Get returns the build information, without features
+func Get() Info {
+	info := build
+	info.GoVersion = runtime.Version()
+	if info.OS == "" || info.OS == "unknown" {
+		info.OS = runtime.GOOS
+	}
+	if info.Arch == "" || info.Arch == "unknown" {
+		info.Arch = runtime.GOARCH
+	}
+
+	// go build embeds the VCS state when built in a git checkout (unless -buildvcs=false)
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = setting.Value
+				}
+			case "vcs.modified":
+				info.Dirty = setting.Value == "true"
+			}
+		}
+	}
+
+	return info
+}
+
+// ShortCommit returns the first 12 characters of the commit, with "-dirty" when the
+// tree had uncommitted changes
+func (i Info) ShortCommit() string {
+	commit := i.Commit
+	if len(commit) > 12 {
+		commit = commit[:12]
+	}
+	if i.Dirty && commit != "" {
+		commit += "-dirty"
+	}
+	return commit
+}
+
+// String identifies the build in reports, e.g. "audit-checks v1.2.0 (3f2a9c1d0b7e)"
+func (i Info) String() string {
+	if commit := i.ShortCommit(); commit != "" {
+		return fmt.Sprintf("audit-checks %s (%s)", i.Version, commit)
+	}
+	return "audit-checks " + i.Version
+}
@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// recordActivity adds an entry to the activity log. Failures are logged but do
+// not fail the command, since the change itself has already been made.
+func recordActivity(db *gorm.DB, cfg *config.Config, action, appName, details string) {
+	entry := &models.ActivityLog{
+		Operator: cfg.Operator,
+		Source:   models.ActivitySourceCLI,
+		Action:   action,
+		AppName:  appName,
+		Details:  details,
+	}
+	if err := db.Create(entry).Error; err != nil {
+		zap.S().Warnf("Failed to record activity action=%s app=%s: %v", action, appName, err)
+		return
+	}
+
+	zap.S().Debugf("Activity recorded operator=%s action=%s app=%s", cfg.Operator, action, appName)
+}
+
+// extractOperatorFlag removes a global --operator flag from args and returns its value
+func extractOperatorFlag(args []string) ([]string, string) {
+	var operator string
+	rest := make([]string, 0, len(args))
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if value, ok := strings.CutPrefix(arg, "--operator="); ok {
+			operator = value
+			continue
+		}
+		if arg == "--operator" && i+1 < len(args) {
+			operator = args[i+1]
+			i++
+			continue
+		}
+		rest = append(rest, arg)
+	}
+
+	return rest, operator
+}
+
+// RunActivity lists the activity log
+func RunActivity(args []string) error {
+	fs := flag.NewFlagSet("activity", flag.ExitOnError)
+	appName := fs.String("app", "", "Only show activity for this app")
+	// --operator is the global flag, so the filter uses a different name
+	operator := fs.String("by", "", "Only show activity by this operator")
+	limit := fs.Int("limit", 50, "Number of entries to show")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	// Connect to database
+	db, err := getDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	query := db.Order("created_at DESC").Limit(*limit)
+	if *appName != "" {
+		query = query.Where("app_name = ?", *appName)
+	}
+	if *operator != "" {
+		query = query.Where("operator = ?", *operator)
+	}
+
+	var entries []models.ActivityLog
+	if err := query.Find(&entries).Error; err != nil {
+		return fmt.Errorf("failed to list activity: %w", err)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No activity recorded.")
+		return nil
+	}
+
+	// Calculate dynamic column widths
+	maxOperatorLen := 8 // minimum "OPERATOR" header length
+	for _, e := range entries {
+		if len(e.Operator) > maxOperatorLen {
+			maxOperatorLen = len(e.Operator)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%-19s  %-*s  %-6s  %-18s  %s\n", "TIME", maxOperatorLen, "OPERATOR", "SOURCE", "ACTION", "APP / DETAILS")
+	fmt.Println(strings.Repeat("-", 19+2+maxOperatorLen+2+6+2+18+2+40))
+
+	for _, e := range entries {
+		target := e.AppName
+		if e.Details != "" {
+			target = strings.TrimSpace(target + " " + e.Details)
+		}
+		fmt.Printf("%-19s  %-*s  %-6s  %-18s  %s\n",
+			e.CreatedAt.Local().Format("2006-01-02 15:04:05"), maxOperatorLen, e.Operator, e.Source, e.Action, target)
+	}
+	fmt.Println()
+
+	return nil
+}
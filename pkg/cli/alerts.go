@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/reporter"
+)
+
+// RunAlerts runs the alerts command: prints the recommended Prometheus alerting rules
+// over the metrics of serve, with thresholds from the config
+func RunAlerts(args []string) error {
+	fs := flag.NewFlagSet("alerts", flag.ExitOnError)
+	output := fs.String("output", "", "File to write the rules to (default: stdout)")
+	job := fs.String("job", "audit-checks", "Prometheus job scraping the metrics of serve")
+	_ = fs.Parse(args)
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	rules, err := reporter.GenerateAlertRules(alertRuleOptions(cfg, *job))
+	if err != nil {
+		return fmt.Errorf("failed to generate alerting rules: %w", err)
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(rules)
+		return err
+	}
+	if err := os.WriteFile(*output, rules, 0644); err != nil {
+		return fmt.Errorf("failed to write alerting rules: %w", err)
+	}
+	fmt.Println(*output)
+	return nil
+}
+
+// alertRuleOptions derives the thresholds of the alerting rules from the config: apps
+// are stale after STATUS_PAGE_STALE_DAYS, or twice the longest adaptive interval, and
+// vulnerabilities alert from SEVERITY_THRESHOLD
+func alertRuleOptions(cfg *config.Config, job string) reporter.AlertRuleOptions {
+	staleAfter := time.Duration(cfg.Settings.StatusPageStaleDays) * 24 * time.Hour
+	if cfg.Settings.AdaptiveSchedule {
+		staleAfter = max(staleAfter, 2*cfg.Settings.ScheduleMaxInterval)
+	}
+
+	var severities []string
+	for _, severity := range []string{models.SeverityCritical, models.SeverityHigh, models.SeverityModerate, models.SeverityLow, models.SeverityInfo} {
+		if cfg.ShouldNotify(severity) {
+			severities = append(severities, severity)
+		}
+	}
+
+	return reporter.AlertRuleOptions{
+		Job:                 job,
+		StaleAfter:          staleAfter,
+		Severities:          severities,
+		NotificationRetries: cfg.IsJobQueueEnabled(),
+		EmailWebhook:        cfg.ResendWebhookSecret != "",
+	}
+}
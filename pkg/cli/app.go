@@ -1,16 +1,25 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/glebarez/sqlite"
+	"github.com/shadowbane/audit-checks/pkg/application"
+	"github.com/shadowbane/audit-checks/pkg/auditor"
 	"github.com/shadowbane/audit-checks/pkg/config"
 	"github.com/shadowbane/audit-checks/pkg/config/dblogger"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
 	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/store"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
@@ -35,10 +44,16 @@ func RunApp(args []string) error {
 		return runAppList(subargs)
 	case "remove", "rm":
 		return runAppRemove(subargs)
+	case "purge-data":
+		return runAppPurgeData(subargs)
 	case "enable":
 		return runAppEnable(subargs)
 	case "disable":
 		return runAppDisable(subargs)
+	case "pause":
+		return runAppPause(subargs)
+	case "resume":
+		return runAppResume(subargs)
 	case "show":
 		return runAppShow(subargs)
 	case "scan":
@@ -64,31 +79,97 @@ Subcommands:
   add          Add a new app to audit
   edit, update Edit an existing app
   list, ls     List all configured apps
-  show         Show details of a specific app
+  show         Show details of a specific app, and its findings, history, ignores and notifications
   remove, rm   Remove an app
+  purge-data   Delete an app's history, reports and logs created before a date
   enable       Enable an app
   disable      Disable an app
+  pause        Skip an app until a given time, then re-enable it automatically
+  resume       Resume a paused app now
   scan         Scan a directory for Laravel apps and add them
 
 Add Flags:
-  --name        App name (required)
-  --path        App path (required)
-  --type        App type: auto, npm, composer, or "npm,composer" for both (default: auto)
-  --email       Email notifications (comma-separated)
-  --telegram    Enable Telegram notifications (bool)
-  --ignore      Ignore list (comma-separated CVEs or packages)
+  --name          App name (required)
+  --path          App path (required)
+  --type          App type: auto, npm, pnpm, composer, go, cargo, java, dotnet, pub, helm, terraform, system, osv, policy, outdated, supplychain, secrets, php, node, or "npm,composer" for both (default: auto)
+  --email         Email notifications (comma-separated)
+  --telegram      Enable Telegram notifications (bool)
+  --discord       Enable Discord notifications (bool)
+  --discord-channel  Discord channel ID for this app (bot only; default: a thread in DISCORD_CHANNEL_ID)
+  --mattermost    Enable Mattermost notifications (bool)
+  --mattermost-channel  Mattermost channel for this app: an ID for the bot, a name for the webhook (default: MATTERMOST_CHANNEL_ID)
+  --webhook       Webhook URLs receiving this app's reports as JSON (comma-separated)
+  --pagerduty     Open PagerDuty incidents for critical findings (bool)
+  --opsgenie      Create Opsgenie alerts for findings (bool)
+  --ntfy          Enable ntfy push notifications (bool)
+  --ntfy-topic    ntfy topic for this app (default: <NTFY_TOPIC_PREFIX>-<name>)
+  --gotify        Enable Gotify push notifications (bool)
+  --zulip         Enable Zulip notifications (bool)
+  --zulip-topic   Zulip topic for this app in ZULIP_STREAM (default: the app name)
+  --gitlab        Keep a GitLab issue open while the app has vulnerabilities (bool)
+  --gitlab-project  GitLab project (ID or group/project) for this app's issue (default: GITLAB_PROJECT)
+  --ignore        Ignore list (comma-separated CVEs or packages)
+  --ignore-paths  Ignore findings from these app-relative paths (comma-separated, e.g. examples,vendor/*)
+  --mute          Severities left out of notifications but still recorded and reported (comma-separated, e.g. low,moderate)
+  --options       Auditor options overriding the global ones (comma-separated, e.g. npm.before=2024-06-01)
+  --ai            Analyze the app's findings with Gemini when GEMINI_ENABLED (bool, default: true)
+  --ai-model      Gemini model for this app, e.g. gemini-2.5-pro (default: GEMINI_MODEL)
+  --owner         Team owning the app; runs share MAX_CONCURRENT fairly between owners (see OWNER_WEIGHTS)
+  --tags          Tags of the app for the policies of POLICY_FILE (comma-separated, e.g. prod,pci)
+  --retention-days  Days of history kept for this app (default: RETENTION_DAYS)
 
 Edit Flags:
-  --name        New app name (rename the app)
-  --path        New app path
-  --type        New app type: auto, npm, composer, or "npm,composer" for both
-  --email       Email notifications (comma-separated, use "" to clear)
-  --telegram    Enable/disable Telegram notifications (bool)
-  --ignore      Ignore list (comma-separated, use "" to clear)
+  --name          New app name (rename the app)
+  --path          New app path
+  --type          New app type: auto, npm, pnpm, composer, go, cargo, java, dotnet, pub, helm, terraform, system, osv, policy, outdated, supplychain, secrets, php, node, or "npm,composer" for both
+  --email         Email notifications (comma-separated, use "" to clear)
+  --telegram      Enable/disable Telegram notifications (bool)
+  --discord       Enable/disable Discord notifications (bool)
+  --discord-channel  Discord channel ID for this app (use "" to post to its thread again)
+  --mattermost    Enable/disable Mattermost notifications (bool)
+  --mattermost-channel  Mattermost channel for this app (use "" for the default channel again)
+  --webhook       Webhook URLs (comma-separated, use "" to clear)
+  --pagerduty     Enable/disable PagerDuty incidents for critical findings (bool)
+  --opsgenie      Enable/disable Opsgenie alerts for findings (bool)
+  --ntfy          Enable/disable ntfy push notifications (bool)
+  --ntfy-topic    ntfy topic for this app (use "" for <NTFY_TOPIC_PREFIX>-<name> again)
+  --gotify        Enable/disable Gotify push notifications (bool)
+  --zulip         Enable/disable Zulip notifications (bool)
+  --zulip-topic   Zulip topic for this app (use "" for the app name again)
+  --gitlab        Enable/disable the app's GitLab issue (bool)
+  --gitlab-project  GitLab project for this app's issue (use "" for GITLAB_PROJECT again)
+  --ignore        Ignore list (comma-separated, use "" to clear)
+  --ignore-paths  Ignored paths (comma-separated, use "" to clear)
+  --mute          Severities left out of notifications (comma-separated, use "" to notify all again)
+  --options       Auditor options (comma-separated <auditor>.<key>=<value>, use "" to clear)
+  --ai            Enable/disable the Gemini analysis of the app's findings (bool)
+  --ai-model      Gemini model for this app (use "" for GEMINI_MODEL again)
+  --owner         Team owning the app (use "" for none)
+  --tags          Tags of the app (comma-separated, use "" to clear)
+  --retention-days  Days of history kept for this app (use 0 for RETENTION_DAYS again)
+
+Show Flags:
+  --vulns         Open findings of the latest audit of each auditor, with when they were first seen
+  --history <n>   The last n audits, and the findings earlier audits reported that are no longer open
+  --ignores       Ignore list, ignored paths and muted severities, with the findings they match
+  --notifications Channels, when the app was last notified and which channels failed, last email status
+                  and queued notification retries
+  --all           All of the above (--history 10 unless set)
+  --json          Print the app and the sections asked for as JSON
+
+Purge-data Flags:
+  --before      Delete what was created before this date (2006-01-02), local time
+                (2006-01-02 15:04) or RFC 3339 (required)
+  --dry-run     Only list what would be deleted
+  --yes         Do not prompt for confirmation
+
+Pause Flags:
+  --until       When to resume: a duration (72h, 3d, 2w), a date (2006-01-02),
+                a local time (2006-01-02 15:04) or RFC 3339 (required)
 
 Scan Flags:
   --path        Directory to scan for Laravel apps (required)
-  --type        App type for added apps: auto, npm, composer (default: auto)
+  --type        App type for added apps: auto, npm, pnpm, composer, go, cargo, java, dotnet, pub, helm, terraform, system, osv, policy, outdated, supplychain, secrets, php, node (default: auto)
   --all         Add all found apps without prompting
 
 Examples:
@@ -98,17 +179,33 @@ Examples:
   audit-checks app edit myapp --name newname      # Rename an app
   audit-checks app edit myapp --type composer     # Change app type
   audit-checks app edit myapp --telegram=false    # Disable Telegram
+  audit-checks app edit myapp --discord --discord-channel 123456789012345678  # Discord, own channel
+  audit-checks app edit myapp --mattermost --mattermost-channel security  # Mattermost webhook, own channel
+  audit-checks app edit myapp --zulip --zulip-topic "myapp (prod)"  # Zulip, own topic
+  audit-checks app edit myapp --gitlab --gitlab-project acme/myapp  # GitLab issue in the app's project
+  audit-checks app edit myapp --options npm.omit=dev  # Audit production dependencies only
+  audit-checks app edit payments --ai-model gemini-2.5-pro  # Better analysis for a critical app
+  audit-checks app edit intranet --ai=false       # No AI analysis (and cost) for a low-risk app
+  audit-checks app edit checkout --owner payments  # Audited in turn with the other teams' apps
+  audit-checks app edit checkout --tags prod,pci  # Held to the policies of production apps
+  audit-checks app edit tenant-a --retention-days 365  # Keep a year of tenant-a's history
   audit-checks app list                           # List all apps
   audit-checks app show myapp                     # Show app details
+  audit-checks app show myapp --vulns --history 5 # Open findings and the last five audits
+  audit-checks app show myapp --all --json        # Configuration and security state as JSON
   audit-checks app remove myapp                   # Remove an app
+  audit-checks app purge-data tenant-a --before 2026-01-01 --dry-run  # What offboarding would delete
   audit-checks app enable myapp                   # Enable an app
   audit-checks app disable myapp                  # Disable an app
+  audit-checks app pause myapp --until 3d         # Skip myapp for three days
+  audit-checks app pause myapp --until 2026-03-01 # Skip myapp until March 1st
+  audit-checks app resume myapp                   # Resume a paused app now
   audit-checks app scan --path /var/www           # Scan and select apps to add
-  audit-checks app scan --path /var/www --all     # Add all discovered apps
-`)
+  audit-checks app scan --path /var/www --all     # Add all discovered apps`)
 }
 
-// getDB returns a database connection
+// getDB returns a database connection with migrations applied, so commands
+// work against databases created by older versions
 func getDB(cfg *config.Config) (*gorm.DB, error) {
 	gormConfig := &gorm.Config{
 		Logger: &dblogger.ZapLogger{
@@ -121,7 +218,16 @@ func getDB(cfg *config.Config) (*gorm.DB, error) {
 		},
 	}
 
-	return gorm.Open(sqlite.Open(cfg.DBSQLitePath), gormConfig)
+	db, err := gorm.Open(sqlite.Open(cfg.DBSQLitePath), gormConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Migrate(db); err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return db, nil
 }
 
 func runAppAdd(args []string) error {
@@ -129,10 +235,32 @@ func runAppAdd(args []string) error {
 
 	name := fs.String("name", "", "App name")
 	path := fs.String("path", "", "App path")
-	appType := fs.String("type", "auto", "App type: auto, npm, composer")
+	appType := fs.String("type", "auto", "App type: auto, npm, pnpm, composer, go, cargo, java, dotnet, pub, helm, terraform, system, osv, policy, outdated, supplychain, secrets, php, node")
 	email := fs.String("email", "", "Email notifications (comma-separated)")
 	telegram := fs.Bool("telegram", false, "Enable Telegram notifications")
+	discord := fs.Bool("discord", false, "Enable Discord notifications")
+	discordChannel := fs.String("discord-channel", "", "Discord channel ID for this app (bot only)")
+	mattermost := fs.Bool("mattermost", false, "Enable Mattermost notifications")
+	mattermostChannel := fs.String("mattermost-channel", "", "Mattermost channel for this app (an ID for the bot, a name for the webhook)")
+	webhook := fs.String("webhook", "", "Webhook URLs receiving this app's reports as JSON (comma-separated)")
+	pagerDuty := fs.Bool("pagerduty", false, "Open PagerDuty incidents for critical findings")
+	opsgenie := fs.Bool("opsgenie", false, "Create Opsgenie alerts for findings")
+	ntfy := fs.Bool("ntfy", false, "Enable ntfy push notifications")
+	ntfyTopic := fs.String("ntfy-topic", "", "ntfy topic for this app")
+	gotify := fs.Bool("gotify", false, "Enable Gotify push notifications")
+	zulip := fs.Bool("zulip", false, "Enable Zulip notifications")
+	zulipTopic := fs.String("zulip-topic", "", "Zulip topic for this app")
+	gitlab := fs.Bool("gitlab", false, "Keep a GitLab issue open while the app has vulnerabilities")
+	gitlabProject := fs.String("gitlab-project", "", "GitLab project for this app's issue")
 	ignore := fs.String("ignore", "", "Ignore list (comma-separated)")
+	ignorePaths := fs.String("ignore-paths", "", "Ignore findings from these app-relative paths (comma-separated)")
+	mute := fs.String("mute", "", "Severities left out of notifications (comma-separated)")
+	options := fs.String("options", "", "Auditor options overriding the global ones (comma-separated <auditor>.<key>=<value>)")
+	ai := fs.Bool("ai", true, "Analyze the app's findings with Gemini")
+	aiModel := fs.String("ai-model", "", "Gemini model for this app (default: GEMINI_MODEL)")
+	owner := fs.String("owner", "", "Team owning the app")
+	tags := fs.String("tags", "", "Tags of the app (comma-separated)")
+	retentionDays := fs.Int("retention-days", 0, "Days of history kept for this app (default: RETENTION_DAYS)")
 
 	_ = fs.Parse(args)
 
@@ -163,13 +291,37 @@ func runAppAdd(args []string) error {
 	}
 
 	// Parse notifications
-	var emailNotifications, ignoreList []string
+	var emailNotifications, ignoreList, ignorePathList []string
 	if *email != "" {
 		emailNotifications = splitAndTrim(*email)
+		if err := validateEmails(emailNotifications); err != nil {
+			return err
+		}
 	}
 	if *ignore != "" {
 		ignoreList = splitAndTrim(*ignore)
 	}
+	if *ignorePaths != "" {
+		ignorePathList = splitAndTrim(*ignorePaths)
+	}
+	optionList := splitAndTrim(*options)
+	if _, err := auditor.ParseAppOptions(optionList); err != nil {
+		return err
+	}
+	mutedSeverities, err := parseSeverities(*mute)
+	if err != nil {
+		return err
+	}
+	webhookURLs := splitAndTrim(*webhook)
+	if err := validateWebhookURLs(webhookURLs); err != nil {
+		return err
+	}
+	if err := validateAIModel(*aiModel); err != nil {
+		return err
+	}
+	if *retentionDays < 0 {
+		return fmt.Errorf("--retention-days must not be negative")
+	}
 
 	// Connect to database
 	db, err := getDB(cfg)
@@ -196,7 +348,29 @@ func runAppAdd(args []string) error {
 		Type:               *appType,
 		EmailNotifications: emailNotifications,
 		TelegramEnabled:    *telegram,
+		DiscordEnabled:     *discord,
+		DiscordChannelID:   strings.TrimSpace(*discordChannel),
+		MattermostEnabled:  *mattermost,
+		MattermostChannel:  strings.TrimSpace(*mattermostChannel),
+		WebhookURLs:        webhookURLs,
+		PagerDutyEnabled:   *pagerDuty,
+		OpsgenieEnabled:    *opsgenie,
+		NtfyEnabled:        *ntfy,
+		NtfyTopic:          strings.TrimSpace(*ntfyTopic),
+		GotifyEnabled:      *gotify,
+		ZulipEnabled:       *zulip,
+		ZulipTopic:         strings.TrimSpace(*zulipTopic),
+		GitLabEnabled:      *gitlab,
+		GitLabProject:      strings.TrimSpace(*gitlabProject),
 		IgnoreList:         ignoreList,
+		IgnorePaths:        ignorePathList,
+		MutedSeverities:    mutedSeverities,
+		AuditOptions:       optionList,
+		AIDisabled:         !*ai,
+		AIModel:            strings.TrimSpace(*aiModel),
+		Owner:              strings.TrimSpace(*owner),
+		Tags:               splitAndTrim(*tags),
+		RetentionDays:      *retentionDays,
 		Enabled:            true,
 	}
 
@@ -204,8 +378,10 @@ func runAppAdd(args []string) error {
 		return fmt.Errorf("failed to create app: %w", err)
 	}
 
-	zap.S().Infof("App created: %s (ID: %s)", *name, app.ID)
+	zap.S().Infof("App created: %s (ID: %s) operator=%s", *name, app.ID, cfg.Operator)
+	recordActivity(db, cfg, models.ActivityAppAdded, *name, "path="+*path)
 	fmt.Printf("App '%s' added successfully!\n", *name)
+	warnSuppressedEmails(db, app.EmailNotifications)
 
 	return nil
 }
@@ -251,10 +427,13 @@ func runAppList(args []string) error {
 	fmt.Printf("%-*s  %-10s  %-8s  %s\n", maxNameLen, "NAME", "TYPE", "STATUS", "PATH")
 	fmt.Println(strings.Repeat("-", maxNameLen+2+10+2+8+2+50))
 
+	now := time.Now()
 	for _, app := range apps {
 		status := "enabled"
 		if !app.Enabled {
 			status = "disabled"
+		} else if app.IsPaused(now) {
+			status = "paused"
 		}
 		fmt.Printf("%-*s  %-10s  %-8s  %s\n", maxNameLen, app.Name, app.Type, status, app.Path)
 	}
@@ -265,10 +444,25 @@ func runAppList(args []string) error {
 }
 
 func runAppShow(args []string) error {
-	if len(args) == 0 {
+	name, flagArgs := extractAppName(args)
+
+	fs := flag.NewFlagSet("app show", flag.ExitOnError)
+	vulns := fs.Bool("vulns", false, "Show the open findings of the latest audits")
+	history := fs.Int("history", 0, "Show the last n audits and the resolved findings")
+	ignores := fs.Bool("ignores", false, "Show the ignore entries and the findings they match")
+	notifications := fs.Bool("notifications", false, "Show the outcome of the last notification")
+	all := fs.Bool("all", false, "Show every section")
+	jsonOutput := fs.Bool("json", false, "Print the app and its sections as JSON")
+	_ = fs.Parse(flagArgs)
+
+	if name == "" {
 		return fmt.Errorf("app name is required")
 	}
-	name := args[0]
+
+	opts := appShowOptions{Vulns: *vulns, History: *history, Ignores: *ignores, Notifications: *notifications}
+	if *all {
+		opts = appShowOptions{Vulns: true, History: max(opts.History, appShowHistoryLimit), Ignores: true, Notifications: true}
+	}
 
 	// Load config (initializes logger)
 	cfg := config.Get()
@@ -291,16 +485,29 @@ func runAppShow(args []string) error {
 		return fmt.Errorf("app '%s' not found", name)
 	}
 
-	status := "enabled"
-	if !app.Enabled {
-		status = "disabled"
+	state, err := loadAppState(cfg, db, app, opts)
+	if err != nil {
+		return err
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(state)
 	}
+	status := state.Status
 
 	fmt.Println()
 	fmt.Printf("Name:      %s\n", app.Name)
 	fmt.Printf("ID:        %s\n", app.ID)
 	fmt.Printf("Path:      %s\n", app.Path)
 	fmt.Printf("Type:      %s\n", app.Type)
+	if app.Owner != "" {
+		fmt.Printf("Owner:     %s\n", app.Owner)
+	}
+	if len(app.Tags) > 0 {
+		fmt.Printf("Tags:      %s\n", strings.Join(app.Tags, ", "))
+	}
 	fmt.Printf("Status:    %s\n", status)
 	fmt.Printf("Created:   %s\n", app.CreatedAt.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Updated:   %s\n", app.UpdatedAt.Format("2006-01-02 15:04:05"))
@@ -308,13 +515,101 @@ func runAppShow(args []string) error {
 	if len(app.EmailNotifications) > 0 {
 		fmt.Printf("Email:     %s\n", strings.Join(app.EmailNotifications, ", "))
 	}
+	for _, s := range emailSuppressions(db, app.EmailNotifications) {
+		fmt.Printf("Suppressed: %s (%s %s", s.Address, s.Source, s.CreatedAt.Local().Format("2006-01-02 15:04"))
+		if s.Reason != "" {
+			fmt.Printf(": %s", s.Reason)
+		}
+		fmt.Println(")")
+	}
+	for _, bounce := range appEmailBounces(db, app) {
+		fmt.Printf("Bounced:   %s (%s %s", bounce.Recipient, bounce.Status, bounce.At.Local().Format("2006-01-02 15:04"))
+		if bounce.Reason != "" {
+			fmt.Printf(": %s", bounce.Reason)
+		}
+		fmt.Println(")")
+	}
 	fmt.Printf("Telegram:  %t\n", app.TelegramEnabled)
 	if app.TelegramTopicID > 0 {
 		fmt.Printf("Topic ID:  %d\n", app.TelegramTopicID)
 	}
+	fmt.Printf("Discord:   %t\n", app.DiscordEnabled)
+	if app.DiscordChannelID != "" {
+		fmt.Printf("Channel:   %s\n", app.DiscordChannelID)
+	}
+	if app.DiscordThreadID != "" {
+		fmt.Printf("Thread ID: %s\n", app.DiscordThreadID)
+	}
+	fmt.Printf("Mattermost: %t\n", app.MattermostEnabled)
+	if app.MattermostChannel != "" {
+		fmt.Printf("Channel:   %s\n", app.MattermostChannel)
+	}
+	for _, webhookURL := range app.WebhookURLs {
+		fmt.Printf("Webhook:   %s\n", webhookURL)
+	}
+	fmt.Printf("PagerDuty: %t\n", app.PagerDutyEnabled)
+	if app.PagerDutyDedupKey != "" {
+		fmt.Printf("Incident:  %s\n", app.PagerDutyDedupKey)
+	}
+	fmt.Printf("Opsgenie:  %t\n", app.OpsgenieEnabled)
+	for _, alias := range app.OpsgenieAliases {
+		fmt.Printf("Alert:     %s\n", alias)
+	}
+	fmt.Printf("ntfy:      %t\n", app.NtfyEnabled)
+	if app.NtfyTopic != "" {
+		fmt.Printf("Topic:     %s\n", app.NtfyTopic)
+	}
+	fmt.Printf("Gotify:    %t\n", app.GotifyEnabled)
+	fmt.Printf("Zulip:     %t\n", app.ZulipEnabled)
+	if app.ZulipTopic != "" {
+		fmt.Printf("Topic:     %s\n", app.ZulipTopic)
+	}
+	fmt.Printf("GitLab:    %t\n", app.GitLabEnabled)
+	if app.GitLabProject != "" {
+		fmt.Printf("Project:   %s\n", app.GitLabProject)
+	}
+	if app.GitLabIssue != "" {
+		fmt.Printf("Issue:     %s\n", app.GitLabIssue)
+	}
 	if len(app.IgnoreList) > 0 {
 		fmt.Printf("Ignore:    %s\n", strings.Join(app.IgnoreList, ", "))
 	}
+	if len(app.IgnorePaths) > 0 {
+		fmt.Printf("Ign paths: %s\n", strings.Join(app.IgnorePaths, ", "))
+	}
+	if len(app.MutedSeverities) > 0 {
+		fmt.Printf("Muted:     %s (not notified)\n", strings.Join(app.MutedSeverities, ", "))
+	}
+	if len(app.AuditOptions) > 0 {
+		fmt.Printf("Options:   %s\n", strings.Join(app.AuditOptions, ", "))
+	}
+	switch {
+	case app.AIDisabled:
+		fmt.Println("AI:        disabled")
+	case app.AIModel != "":
+		fmt.Printf("AI model:  %s\n", app.AIModel)
+	}
+	if app.RetentionDays > 0 {
+		fmt.Printf("Retention: %d days\n", app.RetentionDays)
+	}
+
+	now := time.Now()
+	if state.Findings != nil {
+		fmt.Println()
+		printAppFindings(*state.Findings, now)
+	}
+	if state.History != nil {
+		fmt.Println()
+		printAppHistory(state.History)
+	}
+	if state.Ignores != nil {
+		fmt.Println()
+		printAppIgnores(*state.Ignores)
+	}
+	if state.Notifications != nil {
+		fmt.Println()
+		printAppNotifications(state.Notifications)
+	}
 
 	fmt.Println()
 
@@ -359,12 +654,129 @@ func runAppRemove(args []string) error {
 		return fmt.Errorf("failed to remove app: %w", err)
 	}
 
-	zap.S().Infof("App removed: %s", name)
+	zap.S().Infof("App removed: %s operator=%s", name, cfg.Operator)
+	recordActivity(db, cfg, models.ActivityAppRemoved, name, "")
 	fmt.Printf("App '%s' removed successfully.\n", name)
 
 	return nil
 }
 
+func runAppPurgeData(args []string) error {
+	name, flagArgs := extractAppName(args)
+
+	fs := flag.NewFlagSet("app purge-data", flag.ExitOnError)
+	before := fs.String("before", "", "Delete what was created before this date or time")
+	dryRun := fs.Bool("dry-run", false, "Only list what would be deleted")
+	yes := fs.Bool("yes", false, "Do not prompt for confirmation")
+	_ = fs.Parse(flagArgs)
+
+	if name == "" {
+		return fmt.Errorf("app name is required: audit-checks app purge-data <app> --before <date>")
+	}
+	if *before == "" {
+		return fmt.Errorf("--before is required (e.g. --before 2026-01-01)")
+	}
+	cutoff, err := helpers.ParseTime(*before, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid --before: %w", err)
+	}
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	// Connect to database
+	db, err := getDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	// Check if app exists
+	var app models.App
+	if err := db.Where("name = ?", name).First(&app).Error; err != nil {
+		return fmt.Errorf("app '%s' not found", name)
+	}
+
+	prompt := fmt.Sprintf("Delete the history, reports and logs of '%s' created before %s?", name, cutoff.Format("2006-01-02 15:04 MST"))
+	if !*dryRun && !*yes && !PromptYesNo(prompt, false) {
+		fmt.Println("Cancelled.")
+		return nil
+	}
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+	a, err := application.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer a.Close()
+
+	purge := &models.DataPurge{AppName: name, Before: cutoff, DryRun: *dryRun}
+	purgeErr := a.PurgeAppData(context.Background(), purge)
+
+	verb := "Deleted"
+	if *dryRun {
+		verb = "Would delete"
+	}
+	fmt.Printf("%s from '%s', created before %s:\n", verb, name, cutoff.Format(time.RFC3339))
+	fmt.Printf("  Audit results:    %d (%d findings, %d raw outputs)\n", purge.AuditResults, purge.Vulnerabilities, purge.RawOutputs)
+	fmt.Printf("  Run events:       %d\n", purge.RunEvents)
+	fmt.Printf("  Email deliveries: %d\n", purge.EmailDeliveries)
+	fmt.Printf("  Cached analyses:  %d\n", purge.AIAnalyses)
+	fmt.Printf("  Packages:         %d\n", purge.AppPackages)
+	fmt.Printf("  Triages:          %d\n", purge.Triages)
+	fmt.Printf("  Activity entries: %d\n", purge.Activities)
+	fmt.Printf("  Finished jobs:    %d\n", purge.Jobs)
+	fmt.Printf("  Suppressions:     %d\n", purge.EmailSuppressions)
+	if purge.NotificationState {
+		fmt.Println("  Notification state (Telegram topic, Discord thread, PagerDuty incident, Opsgenie alerts, GitLab issue)")
+	}
+	fmt.Printf("  Files:            %d\n", len(purge.Files))
+	for _, file := range purge.Files {
+		fmt.Printf("    %s\n", file)
+	}
+	if len(purge.RedactedFiles) > 0 {
+		fmt.Printf("  Lines of '%s' in %d run logs shared with other apps:\n", name, len(purge.RedactedFiles))
+		for _, file := range purge.RedactedFiles {
+			fmt.Printf("    %s\n", file)
+		}
+	}
+
+	if len(purge.KeptFiles) > 0 || purge.KeptJobs > 0 || purge.KeptNotificationState {
+		fmt.Println("Kept:")
+	}
+	if len(purge.KeptFiles) > 0 {
+		fmt.Printf("  %d files other results still refer to:\n", len(purge.KeptFiles))
+		for _, file := range purge.KeptFiles {
+			fmt.Printf("    %s\n", file)
+		}
+	}
+	if purge.KeptJobs > 0 {
+		fmt.Printf("  %d queued or running jobs, left to finish\n", purge.KeptJobs)
+	}
+	if purge.KeptNotificationState {
+		fmt.Println("  The notification state, the app having been notified since")
+	}
+
+	if !*dryRun && !purge.Empty() {
+		details := fmt.Sprintf("before=%s results=%d vulnerabilities=%d events=%d emails=%d packages=%d triages=%d "+
+			"activities=%d jobs=%d suppressions=%d notification_state=%t files=%d redacted_files=%d",
+			cutoff.UTC().Format(time.RFC3339), purge.AuditResults, purge.Vulnerabilities, purge.RunEvents, purge.EmailDeliveries,
+			purge.AppPackages, purge.Triages, purge.Activities, purge.Jobs, purge.EmailSuppressions, purge.NotificationState,
+			len(purge.Files), len(purge.RedactedFiles))
+		zap.S().Infof("App data purged: %s %s operator=%s", name, details, cfg.Operator)
+		recordActivity(db, cfg, models.ActivityAppDataPurged, name, details)
+	}
+
+	return purgeErr
+}
+
 func runAppEnable(args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("app name is required")
@@ -395,7 +807,8 @@ func runAppEnable(args []string) error {
 		return fmt.Errorf("app '%s' not found", name)
 	}
 
-	zap.S().Infof("App enabled: %s", name)
+	zap.S().Infof("App enabled: %s operator=%s", name, cfg.Operator)
+	recordActivity(db, cfg, models.ActivityAppEnabled, name, "")
 	fmt.Printf("App '%s' enabled.\n", name)
 
 	return nil
@@ -431,12 +844,101 @@ func runAppDisable(args []string) error {
 		return fmt.Errorf("app '%s' not found", name)
 	}
 
-	zap.S().Infof("App disabled: %s", name)
+	zap.S().Infof("App disabled: %s operator=%s", name, cfg.Operator)
+	recordActivity(db, cfg, models.ActivityAppDisabled, name, "")
 	fmt.Printf("App '%s' disabled.\n", name)
 
 	return nil
 }
 
+func runAppPause(args []string) error {
+	name, flagArgs := extractAppName(args)
+	if name == "" {
+		return fmt.Errorf("app name is required")
+	}
+
+	fs := flag.NewFlagSet("app pause", flag.ExitOnError)
+	until := fs.String("until", "", "When to resume (duration, date or time)")
+	if err := fs.Parse(flagArgs); err != nil {
+		return err
+	}
+
+	if *until == "" {
+		return fmt.Errorf("--until is required (e.g. --until 3d or --until 2006-01-02)")
+	}
+	pausedUntil, err := helpers.ParseUntil(*until, time.Now())
+	if err != nil {
+		return fmt.Errorf("invalid --until: %w", err)
+	}
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	// Connect to database
+	db, err := getDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	// Update app
+	result := db.Model(&models.App{}).Where("name = ?", name).Update("paused_until", pausedUntil.UTC())
+	if result.Error != nil {
+		return fmt.Errorf("failed to pause app: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("app '%s' not found", name)
+	}
+
+	zap.S().Infof("App paused: %s until=%s operator=%s", name, pausedUntil.Format(time.RFC3339), cfg.Operator)
+	recordActivity(db, cfg, models.ActivityAppPaused, name, "until="+pausedUntil.UTC().Format(time.RFC3339))
+	fmt.Printf("App '%s' paused until %s.\n", name, pausedUntil.Format("2006-01-02 15:04 MST"))
+
+	return nil
+}
+
+func runAppResume(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("app name is required")
+	}
+	name := args[0]
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	// Connect to database
+	db, err := getDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	// Update app
+	result := db.Model(&models.App{}).Where("name = ?", name).Update("paused_until", nil)
+	if result.Error != nil {
+		return fmt.Errorf("failed to resume app: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("app '%s' not found", name)
+	}
+
+	zap.S().Infof("App resumed: %s operator=%s", name, cfg.Operator)
+	recordActivity(db, cfg, models.ActivityAppResumed, name, "")
+	fmt.Printf("App '%s' resumed.\n", name)
+
+	return nil
+}
+
 func runAppEdit(args []string) error {
 	// Extract app name first (first non-flag argument)
 	name, flagArgs := extractAppName(args)
@@ -448,10 +950,32 @@ func runAppEdit(args []string) error {
 
 	newName := fs.String("name", "", "New app name")
 	path := fs.String("path", "", "New app path")
-	appType := fs.String("type", "", "New app type: auto, npm, composer")
+	appType := fs.String("type", "", "New app type: auto, npm, pnpm, composer, go, cargo, java, dotnet, pub, helm, terraform, system, osv, policy, outdated, supplychain, secrets, php, node")
 	email := fs.String("email", "", "Email notifications (comma-separated, use \"\" to clear)")
 	telegram := fs.Bool("telegram", false, "Enable/disable Telegram notifications")
+	discord := fs.Bool("discord", false, "Enable/disable Discord notifications")
+	discordChannel := fs.String("discord-channel", "", "Discord channel ID for this app (use \"\" to post to its thread again)")
+	mattermost := fs.Bool("mattermost", false, "Enable/disable Mattermost notifications")
+	mattermostChannel := fs.String("mattermost-channel", "", "Mattermost channel for this app (use \"\" for the default channel again)")
+	webhook := fs.String("webhook", "", "Webhook URLs (comma-separated, use \"\" to clear)")
+	pagerDuty := fs.Bool("pagerduty", false, "Enable/disable PagerDuty incidents for critical findings")
+	opsgenie := fs.Bool("opsgenie", false, "Enable/disable Opsgenie alerts for findings")
+	ntfy := fs.Bool("ntfy", false, "Enable/disable ntfy push notifications")
+	ntfyTopic := fs.String("ntfy-topic", "", "ntfy topic for this app (use \"\" for the default topic again)")
+	gotify := fs.Bool("gotify", false, "Enable/disable Gotify push notifications")
+	zulip := fs.Bool("zulip", false, "Enable/disable Zulip notifications")
+	zulipTopic := fs.String("zulip-topic", "", "Zulip topic for this app (use \"\" for the app name again)")
+	gitlab := fs.Bool("gitlab", false, "Enable/disable the app's GitLab issue")
+	gitlabProject := fs.String("gitlab-project", "", "GitLab project for this app's issue (use \"\" for GITLAB_PROJECT again)")
 	ignore := fs.String("ignore", "", "Ignore list (comma-separated, use \"\" to clear)")
+	ignorePaths := fs.String("ignore-paths", "", "Ignored paths (comma-separated, use \"\" to clear)")
+	mute := fs.String("mute", "", "Severities left out of notifications (comma-separated, use \"\" to notify all again)")
+	options := fs.String("options", "", "Auditor options (comma-separated <auditor>.<key>=<value>, use \"\" to clear)")
+	ai := fs.Bool("ai", true, "Enable/disable the Gemini analysis of the app's findings")
+	aiModel := fs.String("ai-model", "", "Gemini model for this app (use \"\" for GEMINI_MODEL again)")
+	owner := fs.String("owner", "", "Team owning the app (use \"\" for none)")
+	tags := fs.String("tags", "", "Tags of the app (comma-separated, use \"\" to clear)")
+	retentionDays := fs.Int("retention-days", 0, "Days of history kept for this app (use 0 for RETENTION_DAYS again)")
 
 	_ = fs.Parse(flagArgs)
 
@@ -514,7 +1038,11 @@ func runAppEdit(args []string) error {
 		if *email == "" {
 			app.EmailNotifications = []string{}
 		} else {
-			app.EmailNotifications = splitAndTrim(*email)
+			emails := splitAndTrim(*email)
+			if err := validateEmails(emails); err != nil {
+				return err
+			}
+			app.EmailNotifications = emails
 		}
 		changes = append(changes, "email")
 	}
@@ -525,6 +1053,89 @@ func runAppEdit(args []string) error {
 		changes = append(changes, "telegram")
 	}
 
+	// Update Discord settings if flags were explicitly set
+	if isFlagSet(fs, "discord") {
+		app.DiscordEnabled = *discord
+		changes = append(changes, "discord")
+	}
+	if isFlagSet(fs, "discord-channel") {
+		app.DiscordChannelID = strings.TrimSpace(*discordChannel)
+		changes = append(changes, "discord-channel")
+	}
+
+	// Update Mattermost settings if flags were explicitly set
+	if isFlagSet(fs, "mattermost") {
+		app.MattermostEnabled = *mattermost
+		changes = append(changes, "mattermost")
+	}
+	if isFlagSet(fs, "mattermost-channel") {
+		app.MattermostChannel = strings.TrimSpace(*mattermostChannel)
+		changes = append(changes, "mattermost-channel")
+	}
+
+	// Update webhook URLs if flag was explicitly set
+	if isFlagSet(fs, "webhook") {
+		webhookURLs := splitAndTrim(*webhook)
+		if err := validateWebhookURLs(webhookURLs); err != nil {
+			return err
+		}
+		if webhookURLs == nil {
+			webhookURLs = []string{}
+		}
+		app.WebhookURLs = webhookURLs
+		changes = append(changes, "webhook")
+	}
+
+	// Update PagerDuty enabled if flag was explicitly set
+	if isFlagSet(fs, "pagerduty") {
+		app.PagerDutyEnabled = *pagerDuty
+		changes = append(changes, "pagerduty")
+	}
+
+	// Update Opsgenie enabled if flag was explicitly set
+	if isFlagSet(fs, "opsgenie") {
+		app.OpsgenieEnabled = *opsgenie
+		changes = append(changes, "opsgenie")
+	}
+
+	// Update ntfy enabled if flag was explicitly set
+	if isFlagSet(fs, "ntfy") {
+		app.NtfyEnabled = *ntfy
+		changes = append(changes, "ntfy")
+	}
+
+	// Update ntfy topic if flag was explicitly set
+	if isFlagSet(fs, "ntfy-topic") {
+		app.NtfyTopic = strings.TrimSpace(*ntfyTopic)
+		changes = append(changes, "ntfy-topic")
+	}
+
+	// Update Gotify enabled if flag was explicitly set
+	if isFlagSet(fs, "gotify") {
+		app.GotifyEnabled = *gotify
+		changes = append(changes, "gotify")
+	}
+
+	// Update Zulip settings if flags were explicitly set
+	if isFlagSet(fs, "zulip") {
+		app.ZulipEnabled = *zulip
+		changes = append(changes, "zulip")
+	}
+	if isFlagSet(fs, "zulip-topic") {
+		app.ZulipTopic = strings.TrimSpace(*zulipTopic)
+		changes = append(changes, "zulip-topic")
+	}
+
+	// Update GitLab settings if flags were explicitly set
+	if isFlagSet(fs, "gitlab") {
+		app.GitLabEnabled = *gitlab
+		changes = append(changes, "gitlab")
+	}
+	if isFlagSet(fs, "gitlab-project") {
+		app.GitLabProject = strings.TrimSpace(*gitlabProject)
+		changes = append(changes, "gitlab-project")
+	}
+
 	// Update ignore list if flag was explicitly set
 	if isFlagSet(fs, "ignore") {
 		if *ignore == "" {
@@ -535,8 +1146,79 @@ func runAppEdit(args []string) error {
 		changes = append(changes, "ignore")
 	}
 
+	// Update ignored paths if flag was explicitly set
+	if isFlagSet(fs, "ignore-paths") {
+		if *ignorePaths == "" {
+			app.IgnorePaths = []string{}
+		} else {
+			app.IgnorePaths = splitAndTrim(*ignorePaths)
+		}
+		changes = append(changes, "ignore-paths")
+	}
+
+	// Update muted severities if flag was explicitly set
+	if isFlagSet(fs, "mute") {
+		mutedSeverities, err := parseSeverities(*mute)
+		if err != nil {
+			return err
+		}
+		app.MutedSeverities = mutedSeverities
+		if app.MutedSeverities == nil {
+			app.MutedSeverities = []string{}
+		}
+		changes = append(changes, "mute")
+	}
+
+	// Update auditor options if flag was explicitly set
+	if isFlagSet(fs, "options") {
+		optionList := splitAndTrim(*options)
+		if _, err := auditor.ParseAppOptions(optionList); err != nil {
+			return err
+		}
+		app.AuditOptions = optionList
+		if app.AuditOptions == nil {
+			app.AuditOptions = []string{}
+		}
+		changes = append(changes, "options")
+	}
+
+	if isFlagSet(fs, "ai") {
+		app.AIDisabled = !*ai
+		changes = append(changes, "ai")
+	}
+
+	if isFlagSet(fs, "ai-model") {
+		if err := validateAIModel(*aiModel); err != nil {
+			return err
+		}
+		app.AIModel = strings.TrimSpace(*aiModel)
+		changes = append(changes, "ai-model")
+	}
+
+	if isFlagSet(fs, "owner") {
+		app.Owner = strings.TrimSpace(*owner)
+		changes = append(changes, "owner")
+	}
+
+	if isFlagSet(fs, "tags") {
+		if *tags == "" {
+			app.Tags = []string{}
+		} else {
+			app.Tags = splitAndTrim(*tags)
+		}
+		changes = append(changes, "tags")
+	}
+
+	if isFlagSet(fs, "retention-days") {
+		if *retentionDays < 0 {
+			return fmt.Errorf("--retention-days must not be negative")
+		}
+		app.RetentionDays = *retentionDays
+		changes = append(changes, "retention-days")
+	}
+
 	if len(changes) == 0 {
-		fmt.Println("No changes specified. Use flags like --name, --type, --path, --email, --telegram, --ignore")
+		fmt.Println("No changes specified. Use flags like --name, --type, --path, --email, --telegram, --discord, --mattermost, --webhook, --pagerduty, --opsgenie, --ntfy, --ntfy-topic, --gotify, --zulip, --zulip-topic, --gitlab, --gitlab-project, --ignore, --ignore-paths, --mute, --options, --ai, --ai-model, --owner, --tags, --retention-days")
 		return nil
 	}
 
@@ -545,12 +1227,16 @@ func runAppEdit(args []string) error {
 		return fmt.Errorf("failed to update app: %w", err)
 	}
 
-	zap.S().Infof("App updated: %s (changed: %s)", oldName, strings.Join(changes, ", "))
+	zap.S().Infof("App updated: %s (changed: %s) operator=%s", oldName, strings.Join(changes, ", "), cfg.Operator)
+	recordActivity(db, cfg, models.ActivityAppEdited, app.Name, "changed: "+strings.Join(changes, ", "))
 	if oldName != app.Name {
 		fmt.Printf("App '%s' renamed to '%s' and updated (changed: %s).\n", oldName, app.Name, strings.Join(changes, ", "))
 	} else {
 		fmt.Printf("App '%s' updated successfully (changed: %s).\n", app.Name, strings.Join(changes, ", "))
 	}
+	if slices.Contains(changes, "email") {
+		warnSuppressedEmails(db, app.EmailNotifications)
+	}
 
 	return nil
 }
@@ -593,14 +1279,62 @@ func extractAppName(args []string) (string, []string) {
 	return name, flagArgs
 }
 
+// validateEmails checks that email addresses are well-formed, so a typo in a
+// comma-separated list fails instead of leaving a recipient that never gets emails
+func validateEmails(emails []string) error {
+	for _, email := range emails {
+		if err := helpers.ValidateEmail(email); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateAIModel checks that a Gemini model name is a single word, e.g. gemini-2.5-pro
+func validateAIModel(model string) error {
+	model = strings.TrimSpace(model)
+	if strings.ContainsFunc(model, unicode.IsSpace) || strings.ContainsAny(model, "?#") {
+		return fmt.Errorf("invalid Gemini model %q (e.g. gemini-2.5-flash, gemini-2.5-pro)", model)
+	}
+	return nil
+}
+
+// validateWebhookURLs checks that webhook URLs are absolute http(s) URLs
+func validateWebhookURLs(urls []string) error {
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook URL %q: must be an http(s) URL", raw)
+		}
+	}
+	return nil
+}
+
+// parseSeverities parses a comma-separated list of severities, e.g. "low,moderate"
+func parseSeverities(value string) ([]string, error) {
+	var severities []string
+	for _, severity := range splitAndTrim(strings.ToLower(value)) {
+		if _, ok := models.SeverityOrder[severity]; !ok {
+			return nil, fmt.Errorf("invalid severity %q: must be critical, high, moderate, low or info", severity)
+		}
+		if !slices.Contains(severities, severity) {
+			severities = append(severities, severity)
+		}
+	}
+	return severities, nil
+}
+
 // validateTypes validates app type(s) - supports comma-separated like "npm,composer"
 func validateTypes(typeStr string) error {
-	validTypes := map[string]bool{"auto": true, "npm": true, "composer": true}
+	validTypes := map[string]bool{"auto": true}
+	for _, t := range auditor.Types {
+		validTypes[t] = true
+	}
 
 	types := splitAndTrim(typeStr)
 	for _, t := range types {
 		if !validTypes[t] {
-			return fmt.Errorf("invalid type: %s (must be auto, npm, composer, or comma-separated combination)", t)
+			return fmt.Errorf("invalid type: %s (must be auto, %s, or comma-separated combination)", t, strings.Join(auditor.Types, ", "))
 		}
 	}
 
@@ -0,0 +1,492 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/auditor"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/jobs"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/store"
+	"gorm.io/gorm"
+)
+
+// appShowHistoryLimit is the default number of audits listed by `app show --history`
+const appShowHistoryLimit = 10
+
+// Ignore entry types
+const (
+	appIgnoreAdvisory = "advisory" // a CVE or package of the ignore list
+	appIgnorePath     = "path"
+	appIgnoreSeverity = "severity" // muted: recorded and reported, not notified
+)
+
+// appState is an app's configuration with the security state asked for with the flags
+// of `app show`. Sections not asked for are left out.
+type appState struct {
+	models.App
+	Status        string            `json:"status"`
+	Findings      *[]appFinding     `json:"findings,omitempty"`
+	History       *appHistory       `json:"history,omitempty"`
+	Ignores       *[]appIgnore      `json:"ignores,omitempty"`
+	Notifications *appNotifications `json:"notifications,omitempty"`
+}
+
+// appFinding is an open finding of the latest audit of one of the app's auditors
+type appFinding struct {
+	AuditorType string `json:"auditor_type"`
+	models.Vulnerability
+}
+
+// appHistory is the recent audits of an app and the findings no longer open
+type appHistory struct {
+	Audits   []models.AuditResult `json:"audits"`   // newest first
+	Resolved []appResolvedFinding `json:"resolved"` // latest sighting first
+}
+
+// appResolvedFinding is a finding reported by earlier audits of an app but not by the
+// latest audit of its auditor
+type appResolvedFinding struct {
+	AuditorType string    `json:"auditor_type"`
+	PackageName string    `json:"package_name"`
+	Severity    string    `json:"severity"`
+	CVEID       string    `json:"cve_id,omitempty"`
+	Title       string    `json:"title"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+}
+
+// appIgnore is an entry of an app's ignore list, ignored paths or muted severities,
+// with the findings it matches: the findings an advisory matched before it was ignored,
+// or the open findings of a muted severity. Findings of ignored paths are not recorded.
+type appIgnore struct {
+	Type     string     `json:"type"` // advisory, path or severity
+	Value    string     `json:"value"`
+	Matches  *int       `json:"matches,omitempty"`
+	LastSeen *time.Time `json:"last_seen,omitempty"` // last audit reporting a finding of an advisory
+}
+
+// appNotifications is the outcome of an app's last notification
+type appNotifications struct {
+	Channels       []string                  `json:"channels"` // sending the app's findings
+	LastNotifiedAt *time.Time                `json:"last_notified_at,omitempty"`
+	Failed         []string                  `json:"failed,omitempty"`     // channels that failed to send the last notification
+	LastEmail      *models.EmailDelivery     `json:"last_email,omitempty"` // with its delivery status, with Resend
+	Bounced        []models.EmailBounce      `json:"bounced,omitempty"`
+	Suppressed     []models.EmailSuppression `json:"suppressed,omitempty"`
+	Retries        []models.Job              `json:"retries,omitempty"` // queued or running notification retries
+}
+
+// appShowOptions are the sections of the security state shown by `app show`
+type appShowOptions struct {
+	Vulns         bool
+	History       int // audits listed, none when 0
+	Ignores       bool
+	Notifications bool
+}
+
+// appAudits is the audit history of an app: its results oldest first and the
+// identifying fields of every finding they reported
+type appAudits struct {
+	results []models.AuditResult
+	vulns   []models.Vulnerability
+}
+
+// loadAppAudits reads the audit history of an app
+func loadAppAudits(db *gorm.DB, appName string) (*appAudits, error) {
+	var audits appAudits
+	if err := db.Select("id", "auditor_type", "created_at").
+		Where("app_name = ?", appName).
+		Order("created_at").
+		Find(&audits.results).Error; err != nil {
+		return nil, fmt.Errorf("failed to query audit results: %w", err)
+	}
+
+	if err := db.Select("audit_result_id", "package_name", "severity", "cve_id", "title").
+		Where("audit_result_id IN (?)", db.Model(&models.AuditResult{}).Select("id").Where("app_name = ?", appName)).
+		Find(&audits.vulns).Error; err != nil {
+		return nil, fmt.Errorf("failed to query vulnerabilities: %w", err)
+	}
+
+	return &audits, nil
+}
+
+// latest returns the latest result of each of the app's auditors, by ID
+func (h *appAudits) latest() map[string]models.AuditResult {
+	byAuditor := make(map[string]models.AuditResult)
+	for _, r := range h.results {
+		byAuditor[r.AuditorType] = r
+	}
+	latest := make(map[string]models.AuditResult, len(byAuditor))
+	for _, r := range byAuditor {
+		latest[r.ID] = r
+	}
+	return latest
+}
+
+// loadAppState reads the configuration of an app and the sections of its security
+// state asked for in opts
+func loadAppState(cfg *config.Config, db *gorm.DB, app models.App, opts appShowOptions) (*appState, error) {
+	now := time.Now()
+	state := &appState{App: app, Status: appStatus(app, now)}
+
+	var audits *appAudits
+	if opts.Vulns || opts.History > 0 || opts.Ignores {
+		var err error
+		if audits, err = loadAppAudits(db, app.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.Vulns || opts.Ignores {
+		findings, err := appOpenFindings(db, audits, now)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Vulns {
+			state.Findings = &findings
+		}
+		if opts.Ignores {
+			ignores := appIgnores(app, audits, findings)
+			state.Ignores = &ignores
+		}
+	}
+
+	if opts.History > 0 {
+		recent, err := store.NewGormStore(db).RecentAuditResults(app.Name, opts.History)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query audit results: %w", err)
+		}
+		state.History = &appHistory{Audits: recent, Resolved: appResolvedFindings(audits)}
+	}
+
+	if opts.Notifications {
+		state.Notifications = appNotificationStatus(cfg, db, app)
+	}
+
+	return state, nil
+}
+
+// appStatus returns "enabled", "disabled" or "paused until <time>"
+func appStatus(app models.App, now time.Time) string {
+	switch {
+	case !app.Enabled:
+		return "disabled"
+	case app.IsPaused(now):
+		return fmt.Sprintf("paused until %s", app.PausedUntil.Local().Format("2006-01-02 15:04"))
+	}
+	return "enabled"
+}
+
+// appOpenFindings returns the findings of the latest audit of each of the app's
+// auditors, by severity
+func appOpenFindings(db *gorm.DB, audits *appAudits, now time.Time) ([]appFinding, error) {
+	latest := audits.latest()
+	if len(latest) == 0 {
+		return []appFinding{}, nil
+	}
+
+	ids := make([]string, 0, len(latest))
+	for id := range latest {
+		ids = append(ids, id)
+	}
+	var vulns []models.Vulnerability
+	if err := db.Where("audit_result_id IN ?", ids).Find(&vulns).Error; err != nil {
+		return nil, fmt.Errorf("failed to query vulnerabilities: %w", err)
+	}
+	models.SortVulnerabilities(vulns, models.IssueOrderSeverity, now)
+
+	findings := make([]appFinding, 0, len(vulns))
+	for _, v := range vulns {
+		findings = append(findings, appFinding{AuditorType: latest[v.AuditResultID].AuditorType, Vulnerability: v})
+	}
+	return findings, nil
+}
+
+// appResolvedFindings returns the findings reported by earlier audits of an app but
+// not by the latest audit of the same auditor, last sighting first
+func appResolvedFindings(audits *appAudits) []appResolvedFinding {
+	latest := audits.latest()
+	results := make(map[string]models.AuditResult, len(audits.results))
+	for _, r := range audits.results {
+		results[r.ID] = r
+	}
+
+	open := make(map[[2]string]bool)
+	for _, v := range audits.vulns {
+		if r, ok := latest[v.AuditResultID]; ok {
+			open[[2]string{r.AuditorType, v.FindingKey()}] = true
+		}
+	}
+
+	byKey := make(map[[2]string]*appResolvedFinding)
+	for _, v := range audits.vulns {
+		r, ok := results[v.AuditResultID]
+		if !ok {
+			continue
+		}
+		key := [2]string{r.AuditorType, v.FindingKey()}
+		if open[key] {
+			continue
+		}
+		f, ok := byKey[key]
+		if !ok {
+			f = &appResolvedFinding{AuditorType: r.AuditorType, FirstSeen: r.CreatedAt}
+			byKey[key] = f
+		}
+		if r.CreatedAt.Before(f.FirstSeen) {
+			f.FirstSeen = r.CreatedAt
+		}
+		if !r.CreatedAt.Before(f.LastSeen) {
+			f.LastSeen = r.CreatedAt
+			f.PackageName, f.Severity, f.CVEID, f.Title = v.PackageName, v.Severity, v.CVEID, v.Title
+		}
+	}
+
+	resolved := make([]appResolvedFinding, 0, len(byKey))
+	for _, f := range byKey {
+		resolved = append(resolved, *f)
+	}
+	slices.SortFunc(resolved, func(a, b appResolvedFinding) int {
+		return cmp.Or(
+			b.LastSeen.Compare(a.LastSeen),
+			models.SeverityOrder[b.Severity]-models.SeverityOrder[a.Severity],
+			cmp.Compare(a.PackageName, b.PackageName),
+			cmp.Compare(a.CVEID, b.CVEID),
+			cmp.Compare(a.Title, b.Title),
+		)
+	})
+	return resolved
+}
+
+// appIgnores returns the ignore entries of an app with the findings they match
+func appIgnores(app models.App, audits *appAudits, findings []appFinding) []appIgnore {
+	results := make(map[string]models.AuditResult, len(audits.results))
+	for _, r := range audits.results {
+		results[r.ID] = r
+	}
+
+	ignores := make([]appIgnore, 0, len(app.IgnoreList)+len(app.IgnorePaths)+len(app.MutedSeverities))
+	for _, entry := range app.IgnoreList {
+		matched := make(map[[2]string]bool)
+		ignore := appIgnore{Type: appIgnoreAdvisory, Value: entry}
+		for _, v := range audits.vulns {
+			r, ok := results[v.AuditResultID]
+			if !ok || !auditor.IsIgnored(v, []string{entry}) {
+				continue
+			}
+			matched[[2]string{r.AuditorType, v.FindingKey()}] = true
+			if ignore.LastSeen == nil || r.CreatedAt.After(*ignore.LastSeen) {
+				createdAt := r.CreatedAt
+				ignore.LastSeen = &createdAt
+			}
+		}
+		matches := len(matched)
+		ignore.Matches = &matches
+		ignores = append(ignores, ignore)
+	}
+	for _, path := range app.IgnorePaths {
+		ignores = append(ignores, appIgnore{Type: appIgnorePath, Value: path})
+	}
+	for _, severity := range app.MutedSeverities {
+		matches := 0
+		for _, f := range findings {
+			if f.Severity == severity {
+				matches++
+			}
+		}
+		ignores = append(ignores, appIgnore{Type: appIgnoreSeverity, Value: severity, Matches: &matches})
+	}
+	return ignores
+}
+
+// appNotificationStatus returns the outcome of an app's last notification: the
+// channels that failed, its last email's delivery status and pending retries
+func appNotificationStatus(cfg *config.Config, db *gorm.DB, app models.App) *appNotifications {
+	status := &appNotifications{
+		Channels:       appNotificationChannels(app),
+		LastNotifiedAt: app.LastNotifiedAt,
+		Failed:         app.LastNotifyFailed,
+		Bounced:        appEmailBounces(db, app),
+		Suppressed:     emailSuppressions(db, app.EmailNotifications),
+	}
+
+	var email models.EmailDelivery
+	if err := db.Where("app_name = ?", app.Name).Order("created_at DESC").Limit(1).Find(&email).Error; err == nil && email.ID != "" {
+		status.LastEmail = &email
+	}
+
+	if cfg.IsJobQueueEnabled() {
+		if queue, err := jobs.Open(cfg, db); err == nil {
+			defer queue.Close()
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			retries, _, err := queue.List(ctx, jobs.Filter{
+				Statuses: []string{models.JobQueued, models.JobRunning},
+				Type:     models.JobTypeNotify,
+				AppName:  app.Name,
+			})
+			if err == nil {
+				for i := range retries {
+					retries[i].Payload = ""
+				}
+				status.Retries = retries
+			}
+		}
+	}
+
+	return status
+}
+
+// appNotificationChannels returns the channels an app's findings are sent on
+func appNotificationChannels(app models.App) []string {
+	var channels []string
+	for _, c := range []struct {
+		name    string
+		enabled bool
+	}{
+		{"email", len(app.EmailNotifications) > 0},
+		{"telegram", app.TelegramEnabled},
+		{"discord", app.DiscordEnabled},
+		{"mattermost", app.MattermostEnabled},
+		{"webhook", len(app.WebhookURLs) > 0},
+		{"ntfy", app.NtfyEnabled},
+		{"gotify", app.GotifyEnabled},
+		{"zulip", app.ZulipEnabled},
+	} {
+		if c.enabled {
+			channels = append(channels, c.name)
+		}
+	}
+	return channels
+}
+
+// printAppFindings prints the open findings of an app
+func printAppFindings(findings []appFinding, now time.Time) {
+	fmt.Printf("Open findings (%d):\n", len(findings))
+	if len(findings) == 0 {
+		fmt.Println("  None.")
+		return
+	}
+
+	maxPackageLen, maxIDLen, maxAuditorLen := len("PACKAGE"), len("ID"), len("AUDITOR")
+	for _, f := range findings {
+		maxPackageLen = max(maxPackageLen, len(f.PackageName))
+		maxIDLen = max(maxIDLen, len(findingID(f.CVEID, f.Title)))
+		maxAuditorLen = max(maxAuditorLen, len(f.AuditorType))
+	}
+
+	fmt.Printf("  %-8s  %-*s  %-*s  %-*s  %s\n", "SEVERITY", maxPackageLen, "PACKAGE", maxIDLen, "ID", maxAuditorLen, "AUDITOR", "FIRST SEEN")
+	for _, f := range findings {
+		firstSeen := f.FirstSeenLabel(now)
+		if firstSeen == "" {
+			firstSeen = "-"
+		}
+		fmt.Printf("  %-8s  %-*s  %-*s  %-*s  %s\n", f.Severity, maxPackageLen, f.PackageName,
+			maxIDLen, findingID(f.CVEID, f.Title), maxAuditorLen, f.AuditorType, firstSeen)
+	}
+}
+
+// printAppHistory prints the recent audits of an app and its resolved findings
+func printAppHistory(history *appHistory) {
+	fmt.Printf("Recent audits (%d):\n", len(history.Audits))
+	if len(history.Audits) == 0 {
+		fmt.Println("  None.")
+	} else {
+		maxAuditorLen := len("AUDITOR")
+		for _, r := range history.Audits {
+			maxAuditorLen = max(maxAuditorLen, len(r.AuditorType))
+		}
+		fmt.Printf("  %-16s  %-*s  %5s  %8s  %4s  %8s  %3s\n", "DATE", maxAuditorLen, "AUDITOR", "TOTAL", "CRITICAL", "HIGH", "MODERATE", "LOW")
+		for _, r := range history.Audits {
+			fmt.Printf("  %-16s  %-*s  %5d  %8d  %4d  %8d  %3d\n", r.CreatedAt.Local().Format("2006-01-02 15:04"),
+				maxAuditorLen, r.AuditorType, r.TotalVulnerabilities, r.CriticalCount, r.HighCount, r.ModerateCount, r.LowCount)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Resolved findings (%d):\n", len(history.Resolved))
+	if len(history.Resolved) == 0 {
+		fmt.Println("  None.")
+		return
+	}
+	maxPackageLen, maxIDLen := len("PACKAGE"), len("ID")
+	for _, f := range history.Resolved {
+		maxPackageLen = max(maxPackageLen, len(f.PackageName))
+		maxIDLen = max(maxIDLen, len(findingID(f.CVEID, f.Title)))
+	}
+	fmt.Printf("  %-8s  %-*s  %-*s  %-10s  %s\n", "SEVERITY", maxPackageLen, "PACKAGE", maxIDLen, "ID", "FIRST SEEN", "LAST SEEN")
+	for _, f := range history.Resolved {
+		fmt.Printf("  %-8s  %-*s  %-*s  %-10s  %s\n", f.Severity, maxPackageLen, f.PackageName, maxIDLen, findingID(f.CVEID, f.Title),
+			f.FirstSeen.Local().Format("2006-01-02"), f.LastSeen.Local().Format("2006-01-02"))
+	}
+}
+
+// printAppIgnores prints the ignore entries of an app
+func printAppIgnores(ignores []appIgnore) {
+	fmt.Printf("Ignore entries (%d):\n", len(ignores))
+	if len(ignores) == 0 {
+		fmt.Println("  None.")
+		return
+	}
+
+	maxValueLen := len("ENTRY")
+	for _, ignore := range ignores {
+		maxValueLen = max(maxValueLen, len(ignore.Value))
+	}
+	fmt.Printf("  %-8s  %-*s  %s\n", "TYPE", maxValueLen, "ENTRY", "MATCHES")
+	for _, ignore := range ignores {
+		var matches string
+		switch {
+		case ignore.Type == appIgnorePath:
+			matches = "-"
+		case ignore.Type == appIgnoreSeverity:
+			matches = fmt.Sprintf("%d open findings not notified", *ignore.Matches)
+		case *ignore.Matches == 0:
+			matches = "never reported"
+		default:
+			matches = fmt.Sprintf("%d findings, last seen %s", *ignore.Matches, ignore.LastSeen.Local().Format("2006-01-02"))
+		}
+		fmt.Printf("  %-8s  %-*s  %s\n", ignore.Type, maxValueLen, ignore.Value, matches)
+	}
+}
+
+// printAppNotifications prints the outcome of an app's last notification
+func printAppNotifications(status *appNotifications) {
+	fmt.Println("Notifications:")
+	if len(status.Channels) == 0 {
+		fmt.Println("  Channels:   none")
+	} else {
+		fmt.Printf("  Channels:   %s\n", strings.Join(status.Channels, ", "))
+	}
+	if status.LastNotifiedAt == nil {
+		fmt.Println("  Last sent:  never")
+	} else {
+		result := "all channels sent"
+		if len(status.Failed) > 0 {
+			result = "failed: " + strings.Join(status.Failed, ", ")
+		}
+		fmt.Printf("  Last sent:  %s (%s)\n", status.LastNotifiedAt.Local().Format("2006-01-02 15:04"), result)
+	}
+	if status.LastEmail != nil {
+		fmt.Printf("  Last email: %s %s \"%s\"\n", status.LastEmail.CreatedAt.Local().Format("2006-01-02 15:04"),
+			status.LastEmail.Status, status.LastEmail.Subject)
+	}
+	for _, job := range status.Retries {
+		fmt.Printf("  Retry:      job %s %s, attempt %d/%d, next at %s\n", job.ID, job.Status,
+			job.Attempts, job.MaxAttempts, job.RunAt.Local().Format("2006-01-02 15:04"))
+	}
+}
+
+// findingID returns a finding's CVE, or title without one
+func findingID(cveID, title string) string {
+	if cveID != "" {
+		return cveID
+	}
+	return title
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/application"
+	"github.com/shadowbane/audit-checks/pkg/auditor"
+	"github.com/shadowbane/audit-checks/pkg/config"
+)
+
+// broadcastEcosystems maps the --ecosystem values to OSV ecosystems
+var broadcastEcosystems = map[string]string{
+	"npm":      "npm",
+	"composer": "Packagist",
+}
+
+// RunBroadcast runs the broadcast command: finds the apps whose lockfiles install a
+// package affected by a vulnerability and notifies their owners
+func RunBroadcast(args []string) error {
+	fs := flag.NewFlagSet("broadcast", flag.ExitOnError)
+
+	cve := fs.String("cve", "", "Vulnerability ID (CVE, GHSA, ...) the notice is about (required)")
+	packages := fs.String("package", "", "Comma-separated affected packages (default: looked up on OSV.dev)")
+	versions := fs.String("versions", "", "Comma-separated affected versions of --package (default: every version)")
+	ecosystem := fs.String("ecosystem", "", "Ecosystem of --package: npm or composer (default: both)")
+	message := fs.String("message", "", "Text added to the notice, e.g. the remediation plan")
+	dryRun := fs.Bool("dry-run", false, "List the exposed apps without notifying them")
+	yes := fs.Bool("yes", false, "Do not prompt for confirmation")
+	fs.BoolVar(yes, "y", false, "Do not prompt for confirmation (shorthand)")
+
+	_ = fs.Parse(args)
+
+	if *cve == "" {
+		return fmt.Errorf("--cve is required")
+	}
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+	cfg.DryRun = *dryRun
+
+	ctx := context.Background()
+
+	var advisory auditor.Advisory
+	if *packages == "" {
+		lookup := auditor.NewOSVAuditor(cfg.Settings.OSVCacheDir, cfg.Settings.OSVCacheTTL)
+		var err error
+		if advisory, err = lookup.LookupAdvisory(ctx, *cve); err != nil {
+			return fmt.Errorf("failed to look up %s on OSV.dev (use --package to name the affected packages): %w", *cve, err)
+		}
+	} else {
+		var err error
+		if advisory, err = broadcastAdvisory(*cve, *packages, *versions, *ecosystem); err != nil {
+			return err
+		}
+	}
+
+	var affected []string
+	for _, eco := range auditor.WatchedEcosystems {
+		for _, name := range advisory.PackageNames(eco) {
+			affected = append(affected, fmt.Sprintf("%s (%s)", name, eco))
+		}
+	}
+	if len(affected) == 0 {
+		return fmt.Errorf("%s affects no npm or Packagist packages; use --package to name them", *cve)
+	}
+	fmt.Printf("%s affects: %s\n", *cve, strings.Join(affected, ", "))
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	app, err := application.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer app.Close()
+
+	notices, err := app.FindExposures(ctx, advisory)
+	if err != nil {
+		return err
+	}
+
+	if len(notices) == 0 {
+		fmt.Println("No app installs an affected package.")
+		return nil
+	}
+
+	maxNameLen := len("APP")
+	for _, notice := range notices {
+		maxNameLen = max(maxNameLen, len(notice.AppName))
+	}
+
+	fmt.Println()
+	fmt.Printf("%-*s  %s\n", maxNameLen, "APP", "EXPOSED PACKAGES")
+	fmt.Println(strings.Repeat("-", maxNameLen+2+40))
+	for _, notice := range notices {
+		exposed := make([]string, len(notice.Packages))
+		for i, pkg := range notice.Packages {
+			exposed[i] = fmt.Sprintf("%s@%s (%s)", pkg.Name, pkg.Version, pkg.Lockfile)
+		}
+		fmt.Printf("%-*s  %s\n", maxNameLen, notice.AppName, strings.Join(exposed, ", "))
+	}
+	fmt.Println()
+
+	if !*dryRun && !*yes {
+		if !PromptYesNo(fmt.Sprintf("Notify the owners of %d app(s)?", len(notices)), false) {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	notified, err := app.NotifyExposures(ctx, notices, *message)
+	if *dryRun {
+		fmt.Printf("Dry run: would notify %d app(s)\n", len(notified))
+	} else {
+		fmt.Printf("Notified %d app(s)\n", len(notified))
+	}
+	return err
+}
+
+// broadcastAdvisory builds the advisory of the packages named on the command line
+func broadcastAdvisory(id, packages, versions, ecosystem string) (auditor.Advisory, error) {
+	ecosystems := auditor.WatchedEcosystems
+	if ecosystem != "" {
+		eco, ok := broadcastEcosystems[strings.ToLower(ecosystem)]
+		if !ok {
+			return auditor.Advisory{}, fmt.Errorf("invalid --ecosystem %q: use npm or composer", ecosystem)
+		}
+		ecosystems = []string{eco}
+	}
+
+	advisory := auditor.Advisory{ID: id}
+	for _, name := range splitAndTrim(packages) {
+		for _, eco := range ecosystems {
+			advisory.Affected = append(advisory.Affected, auditor.AdvisoryPackage{
+				Ecosystem: eco,
+				Name:      name,
+				Versions:  splitAndTrim(versions),
+			})
+		}
+	}
+	if len(advisory.Affected) == 0 {
+		return auditor.Advisory{}, fmt.Errorf("--package is empty")
+	}
+	return advisory, nil
+}
@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/application"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/exporter"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// RunCheck audits a working copy for git hooks and other developer workflows: no
+// database, notifications or reports, results cached by a hash of the dependency
+// files. Exits 1 if a finding meets the threshold, 2 if an auditor failed.
+func RunCheck(args []string) error {
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	path := fs.String("path", ".", "Directory to audit")
+	appType := fs.String("type", "auto", "Auditor types, comma-separated, or auto")
+	threshold := fs.String("threshold", "", "Lowest severity failing the check (default: SEVERITY_THRESHOLD)")
+	ignore := fs.String("ignore", "", "CVEs or package names to ignore, comma-separated")
+	quiet := fs.Bool("quiet", false, "Print nothing unless the check fails")
+	noCache := fs.Bool("no-cache", false, "Audit even if the dependency files are unchanged")
+	base := fs.String("base", "", "Only fail on findings this commit or branch does not have")
+	githubComment := fs.Bool("github-comment", false, "Post the findings a GitHub pull request introduces as a sticky comment")
+	verbose := fs.Bool("verbose", false, "Print the auditors' logs")
+	fs.BoolVar(verbose, "v", false, "Print the auditors' logs (shorthand)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Auditor logs would drown the findings in a hook's output
+	switch {
+	case *verbose:
+		_ = os.Setenv("LOG_LEVEL", "debug")
+	case *quiet:
+		_ = os.Setenv("LOG_LEVEL", "error")
+	default:
+		_ = os.Setenv("LOG_LEVEL", "warn")
+	}
+
+	cfg := config.Get()
+
+	if *threshold == "" {
+		*threshold = cfg.Settings.SeverityThreshold
+	}
+	*threshold = strings.ToLower(*threshold)
+	if _, ok := models.SeverityOrder[*threshold]; !ok {
+		checkError(fmt.Errorf("invalid --threshold %q (expected critical, high, moderate, low or info)", *threshold))
+	}
+	if err := validateTypes(*appType); err != nil {
+		checkError(err)
+	}
+
+	var ignoreList []string
+	for _, item := range strings.Split(*ignore, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			ignoreList = append(ignoreList, item)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	opts := application.CheckOptions{
+		Path:       *path,
+		Type:       *appType,
+		Threshold:  *threshold,
+		IgnoreList: ignoreList,
+		NoCache:    *noCache,
+	}
+
+	// In a pull request workflow, the base comes from the event (GitHub Actions' variables)
+	var pr *exporter.GitHubPullRequest
+	if *githubComment {
+		token, eventPath := os.Getenv("GITHUB_TOKEN"), os.Getenv("GITHUB_EVENT_PATH")
+		if token == "" || eventPath == "" || os.Getenv("GITHUB_REPOSITORY") == "" {
+			checkError(fmt.Errorf("--github-comment requires GITHUB_TOKEN, GITHUB_REPOSITORY and GITHUB_EVENT_PATH (set by GitHub Actions)"))
+		}
+		var err error
+		if pr, err = exporter.ReadGitHubEvent(eventPath, os.Getenv("GITHUB_REPOSITORY")); err != nil {
+			checkError(err)
+		}
+		if *base == "" {
+			*base = pr.BaseSHA
+		}
+	}
+
+	started := time.Now()
+	var result *application.CheckResult
+	findings, compared := []application.CheckFinding(nil), ""
+	if *base != "" {
+		diff, err := application.CheckPullRequest(ctx, cfg.Settings, opts, *base)
+		if err != nil {
+			checkError(err)
+		}
+		label := *base
+		if pr != nil && *base == pr.BaseSHA {
+			label = pr.BaseRef
+		}
+		result, findings, compared = diff.CheckResult, diff.Introduced, " new since "+label
+
+		if pr != nil {
+			commentPullRequest(ctx, pr, diff, *threshold)
+		}
+	} else {
+		var err error
+		if result, err = application.Check(ctx, cfg.Settings, opts); err != nil {
+			checkError(err)
+		}
+		findings = result.Findings
+	}
+
+	failed := slices.Sorted(maps.Keys(result.Failures))
+	for _, name := range failed {
+		fmt.Fprintf(os.Stderr, "audit-checks: %s failed: %v\n", name, result.Failures[name])
+	}
+
+	if len(findings) > 0 {
+		for _, v := range findings {
+			id := v.CVEID
+			if id == "" {
+				id = "-"
+			}
+			fmt.Printf("%-9s %-30s %-20s %s\n", v.Severity, v.PackageName, id, v.Title)
+		}
+		fmt.Printf("audit-checks: %s%s at or above %s\n", checkFindingCounts(findings), compared, *threshold)
+		os.Exit(1)
+	}
+
+	if len(failed) > 0 {
+		os.Exit(2)
+	}
+
+	if !*quiet {
+		took := time.Since(started).Round(time.Millisecond)
+		if result.Cached && *base == "" {
+			fmt.Printf("audit-checks: no findings%s at or above %s (%s, cached, %s)\n", compared, *threshold, strings.Join(result.Auditors, ", "), took)
+		} else {
+			fmt.Printf("audit-checks: no findings%s at or above %s (%s, %s)\n", compared, *threshold, strings.Join(result.Auditors, ", "), took)
+		}
+	}
+
+	return nil
+}
+
+// commentPullRequest posts the check of a pull request as its sticky comment, or edits
+// the comment of an earlier check. Clean pull requests are not commented on unless they
+// already were. A failure is only reported: the exit code stays that of the check.
+func commentPullRequest(ctx context.Context, pr *exporter.GitHubPullRequest, diff *application.CheckDiff, threshold string) {
+	commenter := exporter.NewGitHubCommenter(os.Getenv("GITHUB_API_URL"), os.Getenv("GITHUB_TOKEN"))
+
+	commentID, err := commenter.FindComment(ctx, pr)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "audit-checks: failed to comment on pull request #%d: %v\n", pr.Number, err)
+		return
+	}
+	if commentID == 0 && len(diff.Introduced) == 0 && len(diff.Failures) == 0 {
+		return
+	}
+
+	if _, err := commenter.Comment(ctx, pr, commentID, diff.Comment(threshold)); err != nil {
+		fmt.Fprintf(os.Stderr, "audit-checks: failed to comment on pull request #%d: %v\n", pr.Number, err)
+	}
+}
+
+// checkError exits with the error code: exit 1 is kept for findings, which hooks may
+// tell apart from a check that could not run
+func checkError(err error) {
+	fmt.Fprintf(os.Stderr, "audit-checks: %v\n", err)
+	os.Exit(2)
+}
+
+// checkFindingCounts describes findings by severity, e.g. "3 findings (1 critical, 2 high)"
+func checkFindingCounts(findings []application.CheckFinding) string {
+	counts := make(map[string]int)
+	for _, v := range findings {
+		counts[v.Severity]++
+	}
+
+	var parts []string
+	for _, severity := range []string{models.SeverityCritical, models.SeverityHigh, models.SeverityModerate, models.SeverityLow, models.SeverityInfo} {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+
+	noun := "findings"
+	if len(findings) == 1 {
+		noun = "finding"
+	}
+	return fmt.Sprintf("%d %s (%s)", len(findings), noun, strings.Join(parts, ", "))
+}
@@ -2,11 +2,14 @@ package cli
 
 import (
 	"bufio"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/shadowbane/audit-checks/pkg/buildinfo"
+	"github.com/shadowbane/audit-checks/pkg/config"
 	"go.uber.org/zap"
 )
 
@@ -44,6 +47,13 @@ func (c *CLI) ParseCommand() (cmd string, args []string) {
 
 // Run executes the CLI
 func (c *CLI) Run() error {
+	// --operator is accepted by every command; it is read from the environment like AUDIT_OPERATOR
+	var operator string
+	c.args, operator = extractOperatorFlag(c.args)
+	if operator != "" {
+		_ = os.Setenv("AUDIT_OPERATOR", operator)
+	}
+
 	cmd, args := c.ParseCommand()
 
 	switch cmd {
@@ -51,14 +61,49 @@ func (c *CLI) Run() error {
 		return RunSetup(args)
 	case "run":
 		return RunAudit(args)
+	case "check":
+		return RunCheck(args)
 	case "app":
 		return RunApp(args)
+	case "self-update":
+		return RunSelfUpdate(args)
+	case "serve":
+		return RunServe(args)
+	case "watch":
+		return RunWatch(args)
+	case "broadcast":
+		return RunBroadcast(args)
+	case "drill":
+		return RunDrill(args)
+	case "activity":
+		return RunActivity(args)
+	case "suppressions":
+		return RunSuppressions(args)
+	case "policy":
+		return RunPolicy(args)
+	case "triage":
+		return RunTriage(args)
+	case "jobs":
+		return RunJobs(args)
+	case "doctor":
+		return RunDoctor(args)
+	case "alerts":
+		return RunAlerts(args)
+	case "report":
+		return RunReport(args)
+	case "deps":
+		return RunDeps(args)
+	case "parse":
+		return RunParse(args)
+	case "install":
+		return RunInstall(args)
+	case "uninstall":
+		return RunUninstall(args)
 	case "help", "-h", "--help":
 		c.PrintHelp()
 		return nil
 	case "version", "-v", "--version":
-		c.PrintVersion()
-		return nil
+		return c.RunVersion(args)
 	default:
 		fmt.Printf("Unknown command: %s\n\n", cmd)
 		c.PrintHelp()
@@ -72,84 +117,401 @@ func (c *CLI) PrintHelp() {
 	//fmt.Printf("audit-checks version %s (built %s)\n", Version, BuildTime)
 	c.PrintVersion()
 	fmt.Println("")
-	fmt.Println(`Security audit tool for npm and composer projects
+	fmt.Println(`Security audit tool for npm, pnpm, composer, Go, Rust, Java and .NET projects and host OS packages
+(or npm/composer lockfiles via OSV.dev, without the package managers)
 
 Usage:
   audit-checks [command] [flags]
 
 Commands:
   run           Run security audit on configured apps (default)
-  setup         Initialize database and configuration
-  app           Manage apps (add, list, remove, enable, disable)
+  check         Audit a working copy without the database, for git hooks (exit code driven, cached)
+  setup         Initialize database and configure notifications/AI (guided)
+  app           Manage apps (add, list, remove, enable, disable, pause)
+  self-update   Update to the latest release (checksum-verified)
+  serve         Serve the REST API (OpenAPI spec at /api/v1/openapi.yaml)
+  watch         Watch the OSV.dev advisory feed and re-audit apps a new advisory affects (daemon)
+  broadcast     Notify the owners of apps whose lockfiles install a package affected by a CVE
+  drill         Send a labeled test message on every notification channel to check they still work
+  activity      Show who changed apps and triggered runs
+  suppressions  List, add or remove email addresses no longer sent to (bounced, spam complaints)
+  triage        Go through an app's findings one by one: acknowledge, ignore, snooze or assign each
+  jobs          List the audits and notification retries queued or run by serve (JOB_QUEUE)
+  doctor        Check that apps can be audited (paths, tools, recent failures, Telegram bot rights)
+  alerts        Print recommended Prometheus alerting rules for the metrics of serve (/metrics)
+  policy        Evaluate or validate the pass/fail policies of POLICY_FILE (policy-as-code)
+  report        Generate the executive report (trends, SLA compliance, top offenders), per-app scorecards
+                or a static HTML dashboard site of the audit history
+  deps          Show an app's dependency inventory (name, version, scope, license) from its lockfiles,
+                or export it as an SPDX SBOM
+  parse         Parse a recorded npm/composer audit output, or check the parsers against all of them
+  install       Schedule audits via cron or systemd
+  uninstall     Remove the cron entry and/or systemd units
   help          Show this help message
   version       Show version information
 
+Global Flags:
+  --operator        Name recorded in the activity log (default: AUDIT_OPERATOR, sudo user or OS user)
+
 Run Flags:
   --app, -a         Run audit for specific app only
   --dry-run         Run without sending notifications
   --verbose, -v     Enable verbose logging
   --report-only     Generate reports without notifications
   --json-output     Output results as JSON to stdout
+  --adaptive        Only audit apps that are due by the adaptive schedule
+  --strict          Fail auditors whose output fails a sanity check instead of marking it questionable
+  --canary N        Canary run: audit N random apps, only notifying auditor failures
+  --gitlab-report F Write a GitLab Dependency Scanning report of the run to file F
+  --no-ai-cache     Analyze every result again instead of reusing cached AI analyses
+
+Check Flags:
+  --path            Directory to audit (default: current directory)
+  --threshold       Lowest severity failing the check (default: SEVERITY_THRESHOLD)
+  --type            Auditor types, comma-separated, or auto (default: auto)
+  --ignore          CVEs or package names to ignore, comma-separated
+  --quiet           Print nothing unless the check fails
+  --no-cache        Audit even if the dependency files are unchanged
+  --base            Only fail on findings this commit or branch does not have (e.g. origin/main)
+  --github-comment  Comment the findings a pull request introduces on it (GitHub Actions, implies its base)
+  --verbose, -v     Print the auditors' logs
+
+Setup Flags:
+  --configure       Only run the notifications & AI wizard (writes .env)
+
+Activity Flags:
+  --app             Only show activity for this app
+  --by              Only show activity by this operator
+  --limit           Number of entries to show (default: 50)
+
+Doctor Flags:
+  --app             Only check this app (also when disabled)
+
+Alerts Flags:
+  --output          File to write the rules to (default: stdout)
+  --job             Prometheus job scraping the metrics of serve (default: audit-checks)
+
+Parse Flags:
+  --fixture         Tool output to parse: a file, - for stdin, or a recorded fixture name
+  --auditor         Parser to use: npm or composer (default: from the recorded fixture)
+  --json            Print the parse summary as JSON (the golden file format)
+  --check           Check every recorded fixture against its golden summary
+  --list            List the recorded fixtures
+
+Serve Flags:
+  --listen          Address to listen on (default: API_LISTEN or 127.0.0.1:8080)
+
+Watch Flags:
+  --interval        How often to poll the advisory feed (default: ADVISORY_WATCH_INTERVAL or 15m)
+  --once            Poll once and exit
+  --dry-run         Re-audit without sending notifications
+  --verbose, -v     Enable verbose logging
+
+Broadcast Flags:
+  --cve             Vulnerability ID (CVE, GHSA, ...) the notice is about (required)
+  --package         Comma-separated affected packages (default: looked up on OSV.dev)
+  --versions        Comma-separated affected versions of --package (default: every version)
+  --ecosystem       Ecosystem of --package: npm or composer (default: both)
+  --message         Text added to the notice, e.g. the remediation plan
+  --dry-run         List the exposed apps without notifying them
+  --yes, -y         Do not prompt for confirmation
+
+Triage Flags (audit-checks triage <app>):
+  --all             Also go through findings already acknowledged, ignored or snoozed
+  --severity        Only go through findings of this severity or higher
+  --snooze-days     Days a finding is snoozed for when none are given (default: 7)
+  --telegram        Post a summary of the decisions to the app's Telegram topic
+
+Drill Flags:
+  --channels        Comma-separated channels to test (default: NOTIFIER_DRILL_CHANNELS)
+  --dry-run         List the channels that would be tested without sending
+
+Install Flags:
+  --cron            Install a crontab entry with this schedule (e.g. "0 2 * * *")
+  --systemd         Install a systemd service and timer
+  --on-calendar     systemd OnCalendar expression (default: *-*-* 02:00:00)
+  --user            Use systemd user units instead of system-wide units
+  --workdir         Directory containing .env (default: current directory)
+  --args            Extra arguments for 'run' (e.g. "--report-only")
+  --log             Log file for cron output (default: <workdir>/storage/logs/cron.log)
+  --print           Print the generated entry/units without installing
+
+Uninstall Flags:
+  --cron            Remove the crontab entry
+  --systemd         Remove the systemd units (both are removed if neither is given)
+  --user            Remove systemd user units
+
+Version Flags:
+  --json            Print the build information (commit, Go version) and enabled features as JSON
+
+Self-Update Flags:
+  --check           Only check for a new version
+  --force           Reinstall even if already up to date
+  --yes, -y         Do not prompt for confirmation
+
+Report Subcommands:
+  report executive  Write the executive report to <REPORT_OUTPUT_DIR>/executive/ and email it
+                    (--days <n>, --no-email, --dry-run)
+  report scorecards Write per-app scorecards for service catalogs to <REPORT_OUTPUT_DIR>/scorecards/
+                    (--format yaml,json)
+  report site       Write a static HTML dashboard of the audit history to <REPORT_OUTPUT_DIR>/site/
+                    (--output <dir>)
+  report status     Write a sanitized status page of the apps to <REPORT_OUTPUT_DIR>/status/
+                    (--output <dir>)
+  report feed       Write the Atom feed of new findings to <REPORT_OUTPUT_DIR>/feed.atom
+                    (--output <file>)
+
+Deps Subcommands:
+  deps list <app>   List the app's installed packages (--direct-only, --json)
+  deps sbom <app>   Export the app's SPDX 2.3 or CycloneDX 1.5 JSON SBOM (--format, --output <file>)
 
 App Subcommands:
   app add           Add a new app to audit
   app list          List all configured apps
+  app show <app>    Show an app's configuration, and its security state
+                    (--vulns, --history <n>, --ignores, --notifications, --all, --json)
   app remove        Remove an app
+  app purge-data    Delete an app's history created before a date
+                    (--before, --dry-run, --yes)
   app enable        Enable an app
   app disable       Disable an app
+  app pause         Skip an app until a given time (--until)
+  app resume        Resume a paused app
 
 Examples:
   audit-checks                          # Run audit for all enabled apps
   audit-checks run --app myapp          # Run audit for specific app
-  audit-checks setup                    # Initialize database
+  audit-checks check --path . --threshold high --quiet  # Gate a git pre-commit or pre-push hook
+  audit-checks check --base origin/main # Only fail on the findings this branch introduces
+  audit-checks setup                    # Initialize database and run the setup wizard
+  audit-checks setup --configure        # Reconfigure Telegram, Discord, email and Gemini
   audit-checks app add                  # Add a new app interactively
   audit-checks app add --name myapp --path /path/to/app --type npm
   audit-checks app list                 # List all apps
   audit-checks app remove myapp         # Remove an app
+  audit-checks app purge-data myapp --before 2026-01-01 --dry-run  # What purging would delete
   audit-checks app enable myapp         # Enable an app
   audit-checks app disable myapp        # Disable an app
+  audit-checks app pause myapp --until 3d  # Skip an app for three days
+  audit-checks self-update --check      # Check for a new release
+  audit-checks install --cron "0 2 * * *"  # Run daily at 02:00 via cron
+  audit-checks install --cron "0 * * * *" --args "--adaptive"  # Check hourly, audit apps when due
+  audit-checks run --canary 3           # Audit 3 random apps to catch broken tools early
+  audit-checks run --gitlab-report gl-dependency-scanning-report.json  # GitLab Security Dashboard
+  sudo audit-checks install --systemd   # Run daily via a systemd timer
+  audit-checks serve --listen :8080     # Serve the REST API
+  audit-checks jobs --status failed     # Which queued audits and notification retries failed?
+  audit-checks broadcast --cve CVE-2021-23337 --package lodash --dry-run  # Who installs lodash?
+  audit-checks drill                    # Check that every notification channel still works
+  audit-checks --operator alice app disable myapp  # Attribute a change on a shared account
+  audit-checks activity --app myapp     # Show who changed myapp
+  audit-checks suppressions remove ops@example.com  # Email ops@example.com again after a bounce
+  audit-checks doctor                   # Find apps whose audits cannot run, and why
+  audit-checks alerts > /etc/prometheus/rules/audit-checks.yml  # Alert on stale audits and criticals
+  audit-checks report executive         # Write and email the weekly executive report
+  audit-checks report scorecards        # Write a Backstage-ready scorecard per app
+  audit-checks report site              # Render the audit history as a static website
+  audit-checks report status            # Green/yellow/red wallboard without vulnerability details
+  audit-checks report feed              # Subscribe to new findings from a feed reader
+  audit-checks deps list myapp --direct-only  # What does myapp depend on?
+  audit-checks deps sbom myapp --output myapp.spdx.json  # SPDX SBOM for a customer
+  npm audit --json | audit-checks parse --auditor npm --fixture -  # Debug the npm parser
+  audit-checks parse --check            # Check the parsers against every recorded tool output
+  audit-checks policy check --file policies.yaml  # Which apps violate the new policies?
 
 Environment Variables:
   APP_ENV               Application environment (default: production)
   LOG_LEVEL             Log level: debug, info, warn, error (default: info)
   LOG_DIRECTORY         Log files directory (default: ./storage/logs)
+  RUN_LOG_ENABLED       Also write each run's log to LOG_DIRECTORY/runs/<run_id>.log (default: false)
+  RAW_OUTPUT_MAX_SIZE   KB of raw auditor output stored per result, the rest in LOG_DIRECTORY/raw (default: 512)
   DB_SQLITE_PATH        SQLite database path (default: ./storage/audit.db)
   RESEND_API_KEY        Resend API key for email notifications
   RESEND_FROM_EMAIL     From email address for notifications
+  RESEND_WEBHOOK_SECRET Signing secret of the Resend webhook tracking delivery status ('serve')
+  EMAIL_PROVIDER        Email provider: resend, smtp (default: resend)
+  SMTP_HOST             SMTP server host (with EMAIL_PROVIDER=smtp)
+  SMTP_PORT             SMTP server port (default: 587)
+  SMTP_USERNAME         SMTP username (no authentication when empty)
+  SMTP_PASSWORD         SMTP password
+  SMTP_TLS              SMTP security: starttls, tls, none (default: starttls)
+  SMTP_FROM_EMAIL       From email address for SMTP emails
   TELEGRAM_BOT_TOKEN    Telegram bot token
   TELEGRAM_ENABLED      Enable Telegram notifications (default: false)
+  DISCORD_BOT_TOKEN     Discord bot token (with DISCORD_CHANNEL_ID, the default channel)
+  DISCORD_WEBHOOK_URL   Discord webhook URL, used when no bot is configured
+  DISCORD_ENABLED       Enable Discord notifications (default: false)
+  MATTERMOST_URL        Mattermost server URL (with MATTERMOST_BOT_TOKEN and MATTERMOST_CHANNEL_ID)
+  MATTERMOST_BOT_TOKEN  Mattermost bot access token
+  MATTERMOST_CHANNEL_ID Default Mattermost channel ID of the bot
+  MATTERMOST_WEBHOOK_URL Mattermost incoming webhook URL, used when no bot is configured
+  MATTERMOST_ENABLED    Enable Mattermost notifications (default: false)
+  WEBHOOK_URLS          Comma-separated URLs receiving every app's reports as JSON
+  WEBHOOK_SECRET        Secret signing webhook requests (X-Audit-Signature-256)
+  WEBHOOK_ENABLED       Enable outbound webhooks (default: false)
+  PAGERDUTY_ROUTING_KEY PagerDuty Events API v2 integration key
+  PAGERDUTY_ENABLED     Open PagerDuty incidents for critical findings (default: false)
+  OPSGENIE_API_KEY      Opsgenie API integration key
+  OPSGENIE_API_URL      Opsgenie API (default: https://api.opsgenie.com)
+  OPSGENIE_PRIORITIES   Alert priority per severity (default: critical=P1,high=P2,moderate=P3,low=P4,info=P5)
+  OPSGENIE_ENABLED      Create Opsgenie alerts for findings (default: false)
+  NTFY_SERVER_URL       ntfy server (default: https://ntfy.sh)
+  NTFY_TOKEN            ntfy access token for protected topics
+  NTFY_TOPIC_PREFIX     Topic prefix of apps without their own topic (default: audit-checks)
+  NTFY_PRIORITIES       Message priority per severity (default: critical=5,high=4,moderate=3,low=2,info=1)
+  NTFY_REPORT_URL       URL serving REPORT_OUTPUT_DIR, for buttons opening the reports
+  NTFY_ENABLED          Enable ntfy push notifications (default: false)
+  GOTIFY_URL            Self-hosted Gotify server
+  GOTIFY_TOKEN          Gotify application token the messages are pushed with
+  GOTIFY_PRIORITIES     Message priority per severity, 0-10 (default: critical=10,high=8,moderate=5,low=3,info=1)
+  GOTIFY_ENABLED        Enable Gotify push notifications (default: false)
+  DESKTOP_ENABLED       Show notifications on the machine running the audit (default: false)
+  DESKTOP_EVENT_SOURCE  Source of the Windows Event Log entries (default: audit-checks)
+  ZULIP_URL             Zulip server URL (organization URL, e.g. https://chat.example.com)
+  ZULIP_BOT_EMAIL       Email address of the Zulip bot posting the messages
+  ZULIP_API_KEY         API key of the Zulip bot
+  ZULIP_STREAM          Zulip stream with a topic per app (default: security)
+  ZULIP_ENABLED         Enable Zulip notifications (default: false)
+  GITLAB_URL            GitLab instance (default: https://gitlab.com)
+  GITLAB_TOKEN          GitLab access token with the api scope
+  GITLAB_PROJECT        GitLab project (ID or group/project) of the apps' issues
+  GITLAB_LABELS         Labels of the GitLab issues (default: security)
+  GITLAB_ENABLED        Keep a GitLab issue per app with vulnerabilities (default: false)
+  DEFECTDOJO_URL        DefectDojo instance the audit results are imported into
+  DEFECTDOJO_API_KEY    DefectDojo API v2 key
+  DEFECTDOJO_PRODUCT_TYPE  Product type of the apps' products (default: audit-checks)
+  DEFECTDOJO_ENGAGEMENT Engagement of each app's product (default: audit-checks)
+  DEFECTDOJO_ENABLED    Import every audit result into DefectDojo (default: false)
+  DEPENDENCYTRACK_URL   Dependency-Track instance the apps' CycloneDX SBOMs are uploaded to
+  DEPENDENCYTRACK_API_KEY  Dependency-Track API key (BOM_UPLOAD, PROJECT_CREATION_UPLOAD)
+  DEPENDENCYTRACK_PROJECT_VERSION  Project version of apps without a manifest version (default: latest)
+  DEPENDENCYTRACK_TAGS  Tags of the created projects (comma-separated, default: audit-checks)
+  DEPENDENCYTRACK_ENABLED  Upload each app's SBOM after its audit (default: false)
+  CONFLUENCE_URL        Confluence base URL a page per app is published to (e.g. https://example.atlassian.net/wiki)
+  CONFLUENCE_USER       Email of the API token's account (Cloud; empty for a personal access token)
+  CONFLUENCE_API_TOKEN  Confluence API token or personal access token
+  CONFLUENCE_SPACE      Key of the space of the pages
+  CONFLUENCE_PARENT_PAGE_ID  Page the app pages are created under (default: the space's root)
+  CONFLUENCE_TITLE      Title of the app pages, {app} replaced (default: Security audit: {app})
+  CONFLUENCE_ENABLED    Publish each app's page after its audit (default: false)
+  AI_ANALYZERS          AI analyzers tried in order, the next when one fails (default: gemini)
   GEMINI_API_KEY        Google Gemini API key
   GEMINI_ENABLED        Enable Gemini AI analysis (default: false)
-  GEMINI_MODEL          Gemini model to use (default: gemini-2.5-flash)
+  GEMINI_MODEL          Default Gemini model, per app with app edit --ai-model (default: gemini-2.5-flash)
+  GEMINI_FLEET_ANALYSIS Analyze all apps' findings together for the summary and executive reports (default: false)
+  GEMINI_MAX_ADVISORIES Advisories listed in the prompt of an audit, the rest counted (default: 50, 0 for all)
+  AI_CACHE_TTL          How long an analysis is reused by audits with the same findings (default: 168h, 0 disables)
+  POLICY_FILE           YAML policies evaluated after each run; they replace SEVERITY_THRESHOLD for the exit code
   SEVERITY_THRESHOLD    Minimum severity to report: critical, high, moderate, low (default: moderate)
-  REPORT_FORMATS        Comma-separated report formats: json, markdown (default: json,markdown)
+  ISSUE_ORDER           Order of findings in reports and notifications: severity, age, risk (default: severity)
+  REPORT_FORMATS        Comma-separated report formats: json, markdown, html, cyclonedx, junit, xlsx (default: json,markdown)
+  COMBINED_REPORT_FORMATS  Formats of one report per app, attached to notifications: json, markdown, html
   REPORT_OUTPUT_DIR     Report output directory (default: ./storage/reports)
+  REPORT_FILENAME_TEMPLATE  Report file names from {app}, {auditor}, {run_id}, {date} (default: {app}-{auditor}-{date})
+  REPORT_TIMESTAMP_FORMAT   Go time layout of {date}, in UTC (default: 2006-01-02-150405)
+  RETENTION_DAYS        Days of history kept per app, per app with app edit --retention-days (default: 0, forever)
   MAX_CONCURRENT        Maximum concurrent audits (default: 3)
+  OWNER_WEIGHTS         Apps started per turn by owner, e.g. payments=3 (default: 1 each)
   RETRY_ATTEMPTS        Number of retry attempts on failure (default: 3)
-`)
+  MIN_TOOL_VERSIONS     Minimum auditor tool versions, e.g. npm=9.0.0,pnpm=8.0.0 (warns if older)
+  JAVA_AUDIT_BACKEND    Java auditor backend: osv-scanner, dependency-check (default: osv-scanner)
+  SYSTEM_AUDIT_BACKEND  Host package auditor backend: auto, dnf, debsecan, apt (default: auto)
+  OSV_CACHE_DIR         Cache for OSV.dev responses of the osv, pub and terraform auditors and the Node.js release index; empty disables (default: ./storage/cache/osv)
+  OSV_CACHE_TTL         How long cached package queries are reused (default: 24h)
+  CHECK_CACHE_DIR       Cache of 'check' results by dependency file hash (default: <user cache dir>/audit-checks/check)
+  CHECK_CACHE_TTL       How long 'check' reuses the results of unchanged dependency files (default: 24h)
+  ADVISORY_WATCH_INTERVAL  How often 'watch' polls the OSV.dev advisory feed (default: 15m)
+  NOTIFIER_DRILL_ENABLED   Send a labeled test message on every channel from 'watch' (default: false)
+  NOTIFIER_DRILL_INTERVAL  How often 'watch' runs the notifier drill (default: 720h)
+  NOTIFIER_DRILL_CHANNELS  Channels the drill tests (default: email,telegram,discord,mattermost,webhook,ntfy,gotify,zulip,opsgenie,pagerduty,desktop)
+  NOTIFIER_DRILL_EMAILS    Recipients of the test email (default: every app's recipients)
+  PINNING_POLICY_ENABLED Check package.json/composer.json for loose constraints in auto-detected apps (default: true)
+  OUTDATED_AUDIT_ENABLED Report dependencies a major version behind in auto-detected apps (default: false)
+  OUTDATED_AUDIT_SEVERITY Severity of outdated findings (default: info)
+  SUPPLY_CHAIN_AUDIT_ENABLED Flag typosquats and new install scripts in auto-detected npm apps (default: false)
+  SECRETS_AUDIT_ENABLED Scan auto-detected git repositories for committed credentials (default: false)
+  PHP_EOL_AUDIT_ENABLED Flag end-of-life PHP and insecure extensions in auto-detected Composer apps (default: true)
+  NODE_EOL_AUDIT_ENABLED Flag end-of-life and unpatched Node.js in auto-detected npm apps (default: true)
+  ADAPTIVE_SCHEDULE     Always use the adaptive schedule for run (default: false)
+  STRICT_MODE           Always fail auditors with a questionable result, as run --strict (default: false)
+  SCHEDULE_MIN_INTERVAL  Interval for apps with criticals or changed dependencies (default: 6h)
+  SCHEDULE_BASE_INTERVAL Interval for apps without critical/high findings (default: 24h)
+  SCHEDULE_MAX_INTERVAL  Longest interval for apps with clean runs (default: 168h)
+  AUDIT_WORKSPACE       Run auditors in a snapshot of the app: off, copy, bind (default: off)
+  AUDIT_WORKSPACE_DIR   Directory for workspace snapshots (default: system temp directory)
+  AUDIT_WORKSPACE_EXCLUDE Directory names not copied in copy mode (default: node_modules,.git)
+  NPM_AUDIT_LEVEL       npm audit --audit-level; lower findings are dropped (info, low, moderate, high, critical, none)
+  NPM_AUDIT_OMIT        npm audit --omit: dev, optional, peer (comma-separated)
+  NPM_AUDIT_REGISTRY    npm audit --registry
+  NPM_AUDIT_BEFORE      npm audit --before, e.g. 2024-06-01 for point-in-time audits
+  NPM_AUDIT_SIGNATURES  Also run npm audit signatures; issues are info findings (default: false)
+  COMPOSER_AUDIT_LOCKED composer audit --locked: audit composer.lock only (default: false)
+  COMPOSER_AUDIT_ABANDONED composer audit --abandoned: ignore, report, fail
+  COMPOSER_AUDIT_IGNORE_SEVERITY composer audit --ignore-severity (comma-separated)
+  COMPOSER_AUDIT_ABANDONED_SEVERITY Severity of abandoned-package findings (default: low)
+  EXECUTIVE_REPORT_ENABLED Generate and email the executive report weekly during run (default: false)
+  EXECUTIVE_REPORT_EMAILS  Comma-separated executive report recipients
+  SLA_DAYS              Days to fix per severity (default: critical=7,high=30,moderate=90,low=180)
+  SCORECARDS_ENABLED    Refresh the per-app scorecards after every run (default: false)
+  SCORECARD_FORMATS     Scorecard formats: yaml, json (default: yaml)
+  STATUS_PAGE_ENABLED   Refresh the status page after every run (default: false)
+  STATUS_PAGE_DIR       Status page directory (default: <REPORT_OUTPUT_DIR>/status)
+  STATUS_PAGE_TITLE     Heading of the status page (default: Security Status)
+  STATUS_PAGE_STALE_DAYS Days without an audit that turn an app yellow (default: 7)
+  FEED_ENABLED          Rewrite the Atom feed after every run and serve it (default: false)
+  FEED_PATH             Atom feed file (default: <REPORT_OUTPUT_DIR>/feed.atom)
+  FEED_TITLE            Title of the feed (default: Security findings)
+  FEED_URL              Public address of the feed
+  FEED_DAYS             Days of new findings in the feed (default: 14)
+  FEED_TOKEN            Token accepted as ?token= on /api/v1/feed
+  UPDATE_CHECK_ENABLED  Check GitHub for new releases in 'version' and 'run' (default: true)
+  API_LISTEN            Address for 'serve' (default: 127.0.0.1:8080)
+  API_TOKEN             Bearer token required by the API (no auth if empty)
+  JOB_QUEUE             Job queue of 'serve': off, embedded or redis (default: off)
+  REDIS_URL             Redis server of JOB_QUEUE=redis (default: redis://127.0.0.1:6379/0)
+  JOB_WORKERS           Number of jobs 'serve' runs at the same time (default: 1)
+  JOB_SCHEDULE_INTERVAL Queue an audit of every app this often, e.g. 6h (default: 0, never)
+  AUDIT_OPERATOR        Operator name for the activity log (default: sudo user or OS user)`)
 }
 
 // PrintVersion prints version information
 func (c *CLI) PrintVersion() {
-	fmt.Printf("audit-checks version %s\n", Version)
-	fmt.Printf("  Built:    %s\n", BuildTime)
-	fmt.Printf("  OS/Arch:  %s/%s\n", BuildOS, BuildArch)
+	info := buildinfo.Get()
+	fmt.Printf("audit-checks version %s\n", info.Version)
+	if commit := info.ShortCommit(); commit != "" {
+		fmt.Printf("  Commit:   %s\n", commit)
+	}
+	fmt.Printf("  Built:    %s\n", info.BuildTime)
+	fmt.Printf("  Go:       %s\n", info.GoVersion)
+	fmt.Printf("  OS/Arch:  %s/%s\n", info.OS, info.Arch)
 }
 
-// Version and build information (set by main.go)
-var (
-	Version   = "dev"
-	BuildTime = "unknown"
-	BuildOS   = "unknown"
-	BuildArch = "unknown"
-)
+// RunVersion runs the version command: the build information, with the enabled
+// features as JSON with --json
+func (c *CLI) RunVersion(args []string) error {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	jsonOutput := fs.Bool("json", false, "Print the build information and enabled features as JSON")
+	_ = fs.Parse(args)
+
+	cfg := config.Get()
+
+	if *jsonOutput {
+		info := buildinfo.Get()
+		info.Features = cfg.EnabledFeatures()
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(info)
+	}
+
+	c.PrintVersion()
+	printUpdateNotice(cfg)
+	return nil
+}
 
-// SetVersion sets the version information
-func SetVersion(version, buildTime, buildOS, buildArch string) {
-	Version = version
-	BuildTime = buildTime
-	BuildOS = buildOS
-	BuildArch = buildArch
+// SetVersion sets the build information (from main.go)
+func SetVersion(version, commit, buildTime, buildOS, buildArch string) {
+	buildinfo.Set(version, commit, buildTime, buildOS, buildArch)
 }
 
 // Helper functions for interactive prompts
@@ -225,7 +587,7 @@ func PromptSelect(message string, options []string, defaultIndex int) int {
 }
 
 // ParseRunFlags parses flags for the run command
-func ParseRunFlags(args []string) (targetApp string, dryRun bool, verbose bool, reportOnly bool, jsonOutput bool) {
+func ParseRunFlags(args []string) (targetApp string, dryRun bool, verbose bool, reportOnly bool, jsonOutput bool, adaptive bool, strict bool, canary int, gitlabReport string, noAICache bool) {
 	fs := flag.NewFlagSet("run", flag.ExitOnError)
 
 	fs.StringVar(&targetApp, "app", "", "Run audit for specific app only")
@@ -235,6 +597,11 @@ func ParseRunFlags(args []string) (targetApp string, dryRun bool, verbose bool,
 	verboseShort := fs.Bool("v", false, "Enable verbose logging (shorthand)")
 	fs.BoolVar(&reportOnly, "report-only", false, "Generate reports without notifications")
 	fs.BoolVar(&jsonOutput, "json-output", false, "Output results as JSON to stdout")
+	fs.BoolVar(&adaptive, "adaptive", false, "Only audit apps that are due (adaptive schedule)")
+	fs.BoolVar(&strict, "strict", false, "Fail auditors whose result fails a sanity check instead of marking it questionable")
+	fs.IntVar(&canary, "canary", 0, "Only audit a random sample of this many apps, notifying auditor failures only")
+	fs.StringVar(&gitlabReport, "gitlab-report", "", "Write a GitLab Dependency Scanning report of the run to this file")
+	fs.BoolVar(&noAICache, "no-ai-cache", false, "Analyze every result again instead of reusing cached AI analyses")
 
 	_ = fs.Parse(args)
 
@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/auditor"
+	"github.com/shadowbane/audit-checks/pkg/buildinfo"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/reporter"
+)
+
+// RunDeps runs the deps subcommands
+func RunDeps(args []string) error {
+	if len(args) == 0 {
+		printDepsHelp()
+		return nil
+	}
+
+	subcmd := args[0]
+	subargs := args[1:]
+
+	switch subcmd {
+	case "list":
+		return runDepsList(subargs)
+	case "sbom":
+		return runDepsSBOM(subargs)
+	case "help":
+		printDepsHelp()
+		return nil
+	default:
+		fmt.Printf("Unknown deps subcommand: %s\n\n", subcmd)
+		printDepsHelp()
+		os.Exit(1)
+		return nil
+	}
+}
+
+func printDepsHelp() {
+	fmt.Print(`deps - Show the dependency inventory of an app
+
+Usage:
+  audit-checks deps [subcommand] <app> [flags]
+
+Subcommands:
+  list         List the packages installed by the app's package-lock.json and composer.lock
+  sbom         Export the inventory as an SPDX 2.3 or CycloneDX 1.5 JSON SBOM
+
+List Flags:
+  --direct-only     Only list the dependencies named in package.json or composer.json
+  --json            Print the inventory as JSON
+
+SBOM Flags:
+  --format <format> spdx or cyclonedx (default: spdx)
+  --output <file>   Write the SBOM to a file instead of stdout
+
+Every installed package is listed with its version, scope (prod or dev), whether it
+is a direct dependency and its license, whether or not it is vulnerable. The SBOM
+also records which packages depend on which, as the lockfiles resolve them.
+
+Examples:
+  audit-checks deps list myapp
+  audit-checks deps list myapp --direct-only --json
+  audit-checks deps sbom myapp --output myapp.spdx.json
+  audit-checks deps sbom myapp --format cyclonedx --output myapp.cdx.json
+`)
+}
+
+func runDepsList(args []string) error {
+	name, flagArgs := extractAppName(args)
+
+	fs := flag.NewFlagSet("deps list", flag.ExitOnError)
+	directOnly := fs.Bool("direct-only", false, "Only list direct dependencies")
+	jsonOutput := fs.Bool("json", false, "Print the inventory as JSON")
+	_ = fs.Parse(flagArgs)
+
+	if name == "" {
+		return fmt.Errorf("app name is required")
+	}
+
+	_, deps, err := loadDependencies(name)
+	if err != nil {
+		return err
+	}
+
+	if *directOnly {
+		direct := deps[:0]
+		for _, dep := range deps {
+			if dep.Direct {
+				direct = append(direct, dep)
+			}
+		}
+		deps = direct
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(deps)
+	}
+
+	if len(deps) == 0 {
+		fmt.Println("No dependencies found.")
+		return nil
+	}
+
+	maxNameLen, maxVersionLen := len("NAME"), len("VERSION")
+	for _, dep := range deps {
+		maxNameLen = max(maxNameLen, len(dep.Name))
+		maxVersionLen = max(maxVersionLen, len(dep.Version))
+	}
+
+	fmt.Println()
+	fmt.Printf("%-*s  %-*s  %-5s  %-6s  %-9s  %s\n", maxNameLen, "NAME", maxVersionLen, "VERSION", "SCOPE", "DIRECT", "ECOSYSTEM", "LICENSE")
+	fmt.Println(strings.Repeat("-", maxNameLen+2+maxVersionLen+2+5+2+6+2+9+2+12))
+
+	for _, dep := range deps {
+		direct := "no"
+		if dep.Direct {
+			direct = "yes"
+		}
+		license := dep.License
+		if license == "" {
+			license = "-"
+		}
+		fmt.Printf("%-*s  %-*s  %-5s  %-6s  %-9s  %s\n", maxNameLen, dep.Name, maxVersionLen, dep.Version, dep.Scope, direct, dep.Ecosystem, license)
+	}
+
+	fmt.Printf("\nTotal: %d dependencies\n", len(deps))
+
+	return nil
+}
+
+func runDepsSBOM(args []string) error {
+	name, flagArgs := extractAppName(args)
+
+	fs := flag.NewFlagSet("deps sbom", flag.ExitOnError)
+	format := fs.String("format", "spdx", "SBOM format: spdx or cyclonedx")
+	output := fs.String("output", "", "Write the SBOM to a file")
+	_ = fs.Parse(flagArgs)
+
+	if name == "" {
+		return fmt.Errorf("app name is required")
+	}
+	if *format != "spdx" && *format != "cyclonedx" {
+		return fmt.Errorf("unknown SBOM format %q (use spdx or cyclonedx)", *format)
+	}
+
+	app, deps, err := loadDependencies(name)
+	if err != nil {
+		return err
+	}
+
+	var sbom []byte
+	if *format == "cyclonedx" {
+		sbom, err = reporter.GenerateCycloneDX(name, auditor.AppVersion(app), deps, buildinfo.Version(), time.Now())
+	} else {
+		sbom, err = reporter.GenerateSPDX(name, deps, buildinfo.Version(), time.Now())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to generate SBOM: %w", err)
+	}
+
+	if *output == "" {
+		fmt.Println(string(sbom))
+		return nil
+	}
+
+	if err := os.WriteFile(*output, append(sbom, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write SBOM: %w", err)
+	}
+	fmt.Printf("SBOM of %d packages written to %s\n", len(deps), *output)
+	return nil
+}
+
+// loadDependencies returns a configured app and its dependency inventory
+func loadDependencies(name string) (models.AppConfig, []models.Dependency, error) {
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	// Connect to database
+	db, err := getDB(cfg)
+	if err != nil {
+		return models.AppConfig{}, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	var app models.App
+	if err := db.Where("name = ?", name).First(&app).Error; err != nil {
+		return models.AppConfig{}, nil, fmt.Errorf("app '%s' not found", name)
+	}
+
+	appConfig := app.ToAppConfig()
+	deps, err := auditor.Dependencies(appConfig)
+	if auditor.IsNoLockfile(err) {
+		return models.AppConfig{}, nil, fmt.Errorf("app '%s' has no package-lock.json or composer.lock in %s", name, app.Path)
+	}
+	if err != nil {
+		return models.AppConfig{}, nil, fmt.Errorf("failed to read dependencies: %w", err)
+	}
+
+	return appConfig, deps, nil
+}
@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/application"
+	"github.com/shadowbane/audit-checks/pkg/auditor"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// doctorFailureWindow is how far back doctor looks for auditor failures
+const doctorFailureWindow = 7 * 24 * time.Hour
+
+// emailBounceWindow is how far back the email bounces of an app are looked for
+const emailBounceWindow = 90 * 24 * time.Hour
+
+// RunDoctor checks that every enabled app can be audited: its path exists, its
+// auditors' tools are installed, and its auditors did not fail in recent runs. It also
+// reports the app's email addresses that bounced, as recorded from Resend's webhooks. When
+// the apps notify through Telegram, it also checks the bot's rights in the group.
+func RunDoctor(args []string) error {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	appName := fs.String("app", "", "Only check this app")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	// Connect to database
+	db, err := getDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	query := db.Where("enabled = ?", true).Order("name")
+	if *appName != "" {
+		query = db.Where("name = ?", *appName)
+	}
+	var apps []models.App
+	if err := query.Find(&apps).Error; err != nil {
+		return fmt.Errorf("failed to query apps: %w", err)
+	}
+	if len(apps) == 0 {
+		if *appName != "" {
+			return fmt.Errorf("app '%s' not found", *appName)
+		}
+		fmt.Println("No enabled apps. Use 'audit-checks app add' to add apps.")
+		return nil
+	}
+
+	registry := application.NewAuditorRegistry(cfg.Settings)
+	failures := latestAuditorFailures(db, time.Now().Add(-doctorFailureWindow))
+	problems := 0
+
+	if cfg.IsTelegramEnabled() && slices.ContainsFunc(apps, func(app models.App) bool { return app.TelegramEnabled }) {
+		problems += doctorTelegram(cfg)
+	}
+
+	for _, app := range apps {
+		appConfig := app.ToAppConfig()
+		fmt.Printf("\n%s (%s)\n", app.Name, app.Path)
+
+		for _, email := range app.EmailNotifications {
+			if err := helpers.ValidateEmail(email); err != nil {
+				problems++
+				printDoctorProblem("email", err.Error()+": emails are not sent to it", "Fix the address with 'audit-checks app edit "+app.Name+" --email <addresses>'")
+			}
+		}
+		suppressions := emailSuppressions(db, app.EmailNotifications)
+		suppressed := models.SuppressedEmails(suppressions)
+		for _, s := range suppressions {
+			problems++
+			msg := fmt.Sprintf("%s is suppressed (%s on %s)", s.Address, s.Source, s.CreatedAt.Local().Format("2006-01-02 15:04"))
+			if s.Reason != "" {
+				msg += ": " + s.Reason
+			}
+			printDoctorProblem("email", msg, "Remove the address with 'audit-checks app edit "+app.Name+" --email <addresses>', or send to it again with 'audit-checks suppressions remove "+s.Address+"'")
+		}
+		for _, bounce := range appEmailBounces(db, app) {
+			if _, ok := suppressed[strings.ToLower(bounce.Recipient)]; ok {
+				continue
+			}
+			problems++
+			msg := fmt.Sprintf("%s %s on %s", bounce.Recipient, bounce.Status, bounce.At.Local().Format("2006-01-02 15:04"))
+			if bounce.Reason != "" {
+				msg += ": " + bounce.Reason
+			}
+			printDoctorProblem("email", msg, "Fix or remove the address with 'audit-checks app edit "+app.Name+" --email <addresses>'")
+		}
+
+		if _, err := os.Stat(app.Path); err != nil {
+			problems++
+			printDoctorProblem("path", err.Error(), "Fix the path with 'audit-checks app edit "+app.Name+" --path <path>'")
+			continue
+		}
+
+		auditors, err := registry.GetAuditorsForApp(appConfig)
+		if err != nil {
+			problems++
+			printDoctorProblem("type", err.Error(), "Set the app type with 'audit-checks app edit "+app.Name+" --type <type>'")
+			continue
+		}
+
+		for _, aud := range auditors {
+			// A missing tool explains any recent failure of the auditor
+			missing := missingBinaries(aud.Name(), cfg.Settings, app.Path)
+			for _, bin := range missing {
+				problems++
+				msg := bin + " not found in PATH"
+				printDoctorProblem(aud.Name(), msg, auditor.FailureHint(aud.Name(), auditor.FailureBinaryMissing, msg))
+			}
+			if len(missing) > 0 {
+				continue
+			}
+
+			if event, ok := failures[app.Name+"|"+aud.Name()]; ok {
+				problems++
+				kind, hint := event.FailureKind, event.Hint
+				if kind == "" {
+					// Failures recorded before classification existed
+					failure := auditor.Classify(aud.Name(), errors.New(event.Message))
+					kind, hint = failure.Kind, failure.Hint
+				}
+				printDoctorProblem(aud.Name(),
+					fmt.Sprintf("failed on %s (%s): %s", event.CreatedAt.Local().Format("2006-01-02 15:04"), kind, event.Message),
+					hint)
+				continue
+			}
+
+			fmt.Printf("  ok    %s\n", aud.Name())
+		}
+	}
+
+	fmt.Println()
+	if problems > 0 {
+		return fmt.Errorf("doctor found %d problem(s)", problems)
+	}
+	fmt.Println("No problems found.")
+	return nil
+}
+
+// doctorTelegram checks that the Telegram bot can create topics and post reports in
+// the group, and returns the number of problems found
+func doctorTelegram(cfg *config.Config) int {
+	fmt.Printf("\nTelegram (group %d)\n", cfg.TelegramGroupID)
+
+	check, err := notifier.CheckTelegram(cfg.TelegramBotToken, cfg.TelegramGroupID)
+	if err != nil {
+		printDoctorProblem("telegram", err.Error(), "Check TELEGRAM_BOT_TOKEN and TELEGRAM_GROUP_ID, e.g. with 'audit-checks setup --configure'")
+		return 1
+	}
+
+	problems := check.Problems()
+	for _, problem := range problems {
+		printDoctorProblem("telegram", problem.Message, problem.Hint)
+	}
+	if len(problems) == 0 {
+		fmt.Printf("  ok    telegram: @%s can post to %s\n", check.BotUsername, check.ChatTitle)
+	}
+	return len(problems)
+}
+
+// printDoctorProblem prints a problem and how to fix it
+func printDoctorProblem(subject, message, hint string) {
+	fmt.Printf("  FAIL  %s: %s\n", subject, message)
+	fmt.Printf("        hint: %s\n", hint)
+}
+
+// latestAuditorFailures returns, per "app|auditor", the failure event of auditors
+// whose most recent run since `since` failed. Auditors that recovered are left out.
+func latestAuditorFailures(db *gorm.DB, since time.Time) map[string]models.RunEvent {
+	var events []models.RunEvent
+	err := db.Where("type IN ? AND created_at >= ?",
+		[]string{models.EventAuditorCompleted, models.EventAuditorFailed}, since).
+		Order("id DESC").Find(&events).Error
+	if err != nil {
+		zap.S().Warnf("Failed to read run events: %v", err)
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	failures := make(map[string]models.RunEvent)
+	for _, event := range events {
+		key := event.AppName + "|" + event.AuditorType
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if event.Type == models.EventAuditorFailed {
+			failures[key] = event
+		}
+	}
+	return failures
+}
+
+// appEmailBounces returns the email addresses of an app whose latest email, within
+// emailBounceWindow, bounced or was marked as spam
+func appEmailBounces(db *gorm.DB, app models.App) []models.EmailBounce {
+	if len(app.EmailNotifications) == 0 {
+		return nil
+	}
+
+	var deliveries []models.EmailDelivery
+	err := db.Where("app_name = ? AND created_at >= ?", app.Name, time.Now().Add(-emailBounceWindow)).
+		Find(&deliveries).Error
+	if err != nil {
+		zap.S().Warnf("Failed to read email deliveries: %v", err)
+		return nil
+	}
+
+	// Addresses removed from the app since they bounced are fixed
+	var bounces []models.EmailBounce
+	for _, bounce := range models.BouncedRecipients(deliveries) {
+		if slices.ContainsFunc(app.EmailNotifications, func(email string) bool { return strings.EqualFold(email, bounce.Recipient) }) {
+			bounces = append(bounces, bounce)
+		}
+	}
+	return bounces
+}
+
+// missingBinaries returns the tools an auditor needs that are not in PATH.
+// Alternatives (e.g. the system backends) count as found when any of them is installed.
+func missingBinaries(auditorName string, settings config.Settings, appPath string) []string {
+	var required [][]string
+	switch auditorName {
+	case "npm":
+		if auditor.FileExists(auditor.JoinPath(appPath, "yarn.lock")) {
+			required = [][]string{{"yarn"}}
+		} else {
+			required = [][]string{{"npm"}}
+		}
+	case "pnpm", "composer", "dotnet":
+		required = [][]string{{auditorName}}
+	case "go":
+		required = [][]string{{"govulncheck"}}
+	case "cargo":
+		required = [][]string{{"cargo"}, {"cargo-audit"}}
+	case "java":
+		if settings.JavaAuditBackend == auditor.JavaBackendDependencyCheck {
+			required = [][]string{{"dependency-check", "dependency-check.sh"}}
+		} else {
+			required = [][]string{{"osv-scanner"}}
+		}
+	case "helm":
+		required = [][]string{{"helm"}, {"trivy"}}
+	case "outdated":
+		if auditor.FileExists(auditor.JoinPath(appPath, "package.json")) {
+			required = append(required, []string{"npm"})
+		}
+		if auditor.FileExists(auditor.JoinPath(appPath, "composer.json")) {
+			required = append(required, []string{"composer"})
+		}
+	case "system":
+		if settings.SystemAuditBackend == "" || settings.SystemAuditBackend == auditor.SystemBackendAuto {
+			required = [][]string{{auditor.SystemBackendDnf, auditor.SystemBackendDebsecan, auditor.SystemBackendApt}}
+		} else {
+			required = [][]string{{settings.SystemAuditBackend}}
+		}
+	}
+
+	var missing []string
+	for _, alternatives := range required {
+		found := false
+		for _, bin := range alternatives {
+			if _, err := exec.LookPath(bin); err == nil {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, strings.Join(alternatives, " or "))
+		}
+	}
+	return missing
+}
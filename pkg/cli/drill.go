@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/shadowbane/audit-checks/pkg/application"
+	"github.com/shadowbane/audit-checks/pkg/config"
+)
+
+// RunDrill runs the drill command: sends the notifier drill now, as `watch` does every
+// NOTIFIER_DRILL_INTERVAL
+func RunDrill(args []string) error {
+	fs := flag.NewFlagSet("drill", flag.ExitOnError)
+
+	channels := fs.String("channels", "", "Comma-separated channels to test (default: NOTIFIER_DRILL_CHANNELS)")
+	dryRun := fs.Bool("dry-run", false, "List the channels that would be tested without sending")
+
+	_ = fs.Parse(args)
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+	cfg.DryRun = *dryRun
+
+	drillChannels := cfg.Settings.NotifierDrillChannels
+	if *channels != "" {
+		drillChannels = nil
+		for _, channel := range strings.Split(*channels, ",") {
+			if channel = strings.ToLower(strings.TrimSpace(channel)); channel != "" {
+				drillChannels = append(drillChannels, channel)
+			}
+		}
+	}
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	app, err := application.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer app.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	results, err := app.DrillNotifiers(ctx, drillChannels)
+	if len(results) == 0 {
+		fmt.Println("No enabled notification channel to test")
+		return err
+	}
+
+	for _, result := range results {
+		status := "ok"
+		if *dryRun {
+			status = "would be tested"
+		}
+		if result.Error != nil {
+			status = "FAILED: " + result.Error.Error()
+		}
+		fmt.Printf("%-10s %s\n", result.Channel, status)
+	}
+	return err
+}
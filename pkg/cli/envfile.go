@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envValue is a single KEY=value pair to write to the .env file
+type envValue struct {
+	Key   string
+	Value string
+}
+
+// writeEnvFile sets the given keys in an .env file, preserving comments, ordering
+// and unrelated keys. Existing keys are updated in place; new keys are appended.
+// The previous file is kept as <path>.bak.
+func writeEnvFile(path string, values []envValue) error {
+	var lines []string
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := writePrivateFile(path+".bak", existing); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		lines = strings.Split(strings.TrimRight(string(existing), "\n"), "\n")
+	case os.IsNotExist(err):
+		lines = nil
+	default:
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	written := make(map[string]bool)
+	for i, line := range lines {
+		key, ok := envLineKey(line)
+		if !ok {
+			continue
+		}
+		for _, v := range values {
+			if v.Key == key {
+				lines[i] = v.Key + "=" + quoteEnvValue(v.Value)
+				written[key] = true
+				break
+			}
+		}
+	}
+
+	var appended bool
+	for _, v := range values {
+		if written[v.Key] {
+			continue
+		}
+		if !appended {
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, "# Added by audit-checks setup")
+			appended = true
+		}
+		lines = append(lines, v.Key+"="+quoteEnvValue(v.Value))
+		written[v.Key] = true
+	}
+
+	// .env holds API keys and tokens, so keep it private
+	if err := writePrivateFile(path, []byte(strings.Join(lines, "\n")+"\n")); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// writePrivateFile writes data to a file readable by its owner only. The mode of
+// os.WriteFile only applies to new files, so an existing file is made private
+// before the data is written into it.
+func writePrivateFile(path string, data []byte) error {
+	if err := os.Chmod(path, 0600); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// envLineKey returns the key of a KEY=value line (ignoring comments and blanks)
+func envLineKey(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", false
+	}
+	line = strings.TrimPrefix(line, "export ")
+
+	key, _, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", false
+	}
+	return strings.TrimSpace(key), true
+}
+
+// quoteEnvValue quotes a value if it contains whitespace or special characters
+func quoteEnvValue(value string) string {
+	if value == "" || !strings.ContainsAny(value, " \t#\"'$\\") {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteEnvFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(path, []byte("# Audit Checks\nAPP_ENV=production\nTELEGRAM_BOT_TOKEN=old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	values := []envValue{{Key: "TELEGRAM_BOT_TOKEN", Value: "123:secret"}, {Key: "GEMINI_API_KEY", Value: "a b"}}
+	if err := writeEnvFile(path, values); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Audit Checks\nAPP_ENV=production\nTELEGRAM_BOT_TOKEN=123:secret\n\n# Added by audit-checks setup\nGEMINI_API_KEY=\"a b\"\n"
+	if string(content) != want {
+		t.Errorf(".env = %q, want %q", content, want)
+	}
+
+	// An existing world-readable .env is made private, as is its backup
+	for _, name := range []string{path, path + ".bak"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mode := info.Mode().Perm(); mode != 0600 {
+			t.Errorf("%s mode = %o, want 600", filepath.Base(name), mode)
+		}
+	}
+}
@@ -0,0 +1,482 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+const (
+	// cronMarker tags the crontab line managed by install/uninstall
+	cronMarker = "# audit-checks (managed)"
+
+	// systemdUnitName is the base name of the generated service and timer units
+	systemdUnitName = "audit-checks"
+)
+
+// installOptions holds the resolved settings shared by cron and systemd installs
+type installOptions struct {
+	Binary     string // Absolute path to the audit-checks binary
+	WorkDir    string // Working directory (where .env and storage/ live)
+	Path       string // PATH for the scheduled job (npm/composer must be resolvable)
+	RunArgs    string // Extra arguments for `audit-checks run`
+	LogFile    string // Log file for cron output
+	Schedule   string // Cron schedule
+	OnCalendar string // systemd OnCalendar expression
+	UserUnit   bool   // Install as a systemd user unit
+}
+
+// RunInstall runs the install command
+func RunInstall(args []string) error {
+	fs := flag.NewFlagSet("install", flag.ExitOnError)
+
+	cronSchedule := fs.String("cron", "", "Install a crontab entry with this schedule (e.g. \"0 2 * * *\")")
+	systemd := fs.Bool("systemd", false, "Install a systemd service and timer")
+	onCalendar := fs.String("on-calendar", "*-*-* 02:00:00", "systemd timer OnCalendar expression")
+	userUnit := fs.Bool("user", false, "Install systemd units for the current user instead of system-wide")
+	workDir := fs.String("workdir", "", "Working directory containing .env (default: current directory)")
+	runArgs := fs.String("args", "", "Extra arguments passed to 'audit-checks run'")
+	logFile := fs.String("log", "", "Log file for cron output (default: <workdir>/storage/logs/cron.log)")
+	printOnly := fs.Bool("print", false, "Print the generated entry/units without installing")
+
+	_ = fs.Parse(args)
+
+	if *cronSchedule == "" && !*systemd {
+		return fmt.Errorf("specify --cron \"<schedule>\" or --systemd")
+	}
+
+	opts, err := resolveInstallOptions(*workDir, *runArgs, *logFile)
+	if err != nil {
+		return err
+	}
+	opts.Schedule = *cronSchedule
+	opts.OnCalendar = *onCalendar
+	opts.UserUnit = *userUnit
+
+	if *cronSchedule != "" {
+		if err := validateCronSchedule(*cronSchedule); err != nil {
+			return err
+		}
+		if err := installCron(opts, *printOnly); err != nil {
+			return err
+		}
+	}
+
+	if *systemd {
+		if err := installSystemd(opts, *printOnly); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RunUninstall runs the uninstall command
+func RunUninstall(args []string) error {
+	fs := flag.NewFlagSet("uninstall", flag.ExitOnError)
+
+	cron := fs.Bool("cron", false, "Remove the managed crontab entry")
+	systemd := fs.Bool("systemd", false, "Remove the systemd service and timer")
+	userUnit := fs.Bool("user", false, "Remove systemd user units instead of system-wide units")
+
+	_ = fs.Parse(args)
+
+	// Remove both when nothing specific was requested
+	if !*cron && !*systemd {
+		*cron = true
+		*systemd = true
+	}
+
+	var errs []string
+
+	if *cron {
+		if err := uninstallCron(); err != nil {
+			errs = append(errs, fmt.Sprintf("cron: %v", err))
+		}
+	}
+
+	if *systemd {
+		if err := uninstallSystemd(*userUnit); err != nil {
+			errs = append(errs, fmt.Sprintf("systemd: %v", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("uninstall failed: %s", strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// resolveInstallOptions resolves absolute paths and captures the current PATH
+func resolveInstallOptions(workDir, runArgs, logFile string) (*installOptions, error) {
+	binary, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate audit-checks binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(binary); err == nil {
+		binary = resolved
+	}
+
+	if workDir == "" {
+		workDir, err = os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get working directory: %w", err)
+		}
+	}
+	workDir, err = filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workdir: %w", err)
+	}
+	if info, err := os.Stat(workDir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("workdir does not exist: %s", workDir)
+	}
+
+	if _, err := os.Stat(filepath.Join(workDir, ".env")); err != nil {
+		fmt.Printf("Warning: no .env found in %s; the scheduled run will use defaults and OS environment only.\n", workDir)
+	}
+
+	if logFile == "" {
+		logFile = filepath.Join(workDir, "storage", "logs", "cron.log")
+	}
+
+	// Cron and systemd start jobs with a minimal PATH, so npm/composer would not be found.
+	// Capture the PATH of the installing shell so the scheduled run sees the same tools.
+	path := os.Getenv("PATH")
+	if path == "" {
+		path = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+	}
+
+	return &installOptions{
+		Binary:  binary,
+		WorkDir: workDir,
+		Path:    path,
+		RunArgs: strings.TrimSpace(runArgs),
+		LogFile: logFile,
+	}, nil
+}
+
+// validateCronSchedule checks that a schedule has 5 fields or is a predefined macro
+func validateCronSchedule(schedule string) error {
+	if strings.HasPrefix(schedule, "@") {
+		switch schedule {
+		case "@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly":
+			return nil
+		}
+		return fmt.Errorf("invalid cron macro: %s", schedule)
+	}
+
+	if len(strings.Fields(schedule)) != 5 {
+		return fmt.Errorf("invalid cron schedule %q: expected 5 fields (minute hour day month weekday)", schedule)
+	}
+
+	return nil
+}
+
+// shellQuote quotes a string for safe use in a POSIX shell command
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// buildCronLine builds the managed crontab line
+func buildCronLine(opts *installOptions) string {
+	command := fmt.Sprintf("cd %s && PATH=%s AUDIT_OPERATOR=cron %s run",
+		shellQuote(opts.WorkDir),
+		shellQuote(opts.Path),
+		shellQuote(opts.Binary),
+	)
+	if opts.RunArgs != "" {
+		command += " " + opts.RunArgs
+	}
+	command += fmt.Sprintf(" >> %s 2>&1", shellQuote(opts.LogFile))
+
+	// An unescaped % in a crontab command is treated as a newline
+	command = strings.ReplaceAll(command, "%", `\%`)
+
+	return fmt.Sprintf("%s %s %s", opts.Schedule, command, cronMarker)
+}
+
+// readCrontab returns the current user's crontab lines (empty if none exists)
+func readCrontab() ([]string, error) {
+	if _, err := exec.LookPath("crontab"); err != nil {
+		return nil, fmt.Errorf("crontab not found in PATH: %w", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("crontab", "-l")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		// "no crontab for <user>" is not an error for our purposes
+		if strings.Contains(strings.ToLower(stderr.String()), "no crontab") {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read crontab: %s", strings.TrimSpace(stderr.String()))
+	}
+
+	content := strings.TrimRight(stdout.String(), "\n")
+	if content == "" {
+		return nil, nil
+	}
+	return strings.Split(content, "\n"), nil
+}
+
+// writeCrontab replaces the current user's crontab
+func writeCrontab(lines []string) error {
+	content := strings.Join(lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("crontab", "-")
+	cmd.Stdin = strings.NewReader(content)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to write crontab: %s", strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// removeManagedCronLines filters out lines written by install
+func removeManagedCronLines(lines []string) ([]string, int) {
+	var kept []string
+	removed := 0
+	for _, line := range lines {
+		if strings.HasSuffix(strings.TrimSpace(line), cronMarker) {
+			removed++
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return kept, removed
+}
+
+// installCron adds (or replaces) the managed crontab entry
+func installCron(opts *installOptions, printOnly bool) error {
+	line := buildCronLine(opts)
+
+	if printOnly {
+		fmt.Println(line)
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(opts.LogFile), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	lines, err := readCrontab()
+	if err != nil {
+		return err
+	}
+
+	lines, removed := removeManagedCronLines(lines)
+	lines = append(lines, line)
+
+	if err := writeCrontab(lines); err != nil {
+		return err
+	}
+
+	if removed > 0 {
+		fmt.Println("Replaced existing audit-checks crontab entry:")
+	} else {
+		fmt.Println("Installed audit-checks crontab entry:")
+	}
+	fmt.Printf("  %s\n", line)
+
+	return nil
+}
+
+// uninstallCron removes the managed crontab entry
+func uninstallCron() error {
+	lines, err := readCrontab()
+	if err != nil {
+		return err
+	}
+
+	lines, removed := removeManagedCronLines(lines)
+	if removed == 0 {
+		fmt.Println("No audit-checks crontab entry found.")
+		return nil
+	}
+
+	if err := writeCrontab(lines); err != nil {
+		return err
+	}
+
+	fmt.Println("Removed audit-checks crontab entry.")
+	return nil
+}
+
+// systemdEscape escapes the % specifiers that systemd expands in unit settings
+func systemdEscape(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
+
+// systemdQuote double-quotes a systemd setting value, so that spaces, quotes,
+// backslashes and % specifiers are taken literally
+func systemdQuote(s string) string {
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+	return `"` + systemdEscape(s) + `"`
+}
+
+// systemdExecQuote quotes a word of an ExecStart command line, where systemd also
+// expands $ variables
+func systemdExecQuote(s string) string {
+	return systemdQuote(strings.ReplaceAll(s, "$", "$$"))
+}
+
+// systemdServiceTemplate is the oneshot service that runs the audit. The extra run
+// arguments are split by systemd with the same quoting rules as a shell.
+var systemdServiceTemplate = template.Must(template.New("service").Funcs(template.FuncMap{
+	"systemdEscape":    systemdEscape,
+	"systemdQuote":     systemdQuote,
+	"systemdExecQuote": systemdExecQuote,
+}).Parse(`[Unit]
+Description=Audit Checks security audit
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=oneshot
+WorkingDirectory={{systemdEscape .WorkDir}}
+Environment={{systemdQuote (print "PATH=" .Path)}}
+Environment="AUDIT_OPERATOR=systemd"
+ExecStart={{systemdExecQuote .Binary}} run{{if .RunArgs}} {{systemdEscape .RunArgs}}{{end}}
+# Exit code 1 means vulnerabilities were found, which is not a service failure
+SuccessExitStatus=1
+`))
+
+// systemdTimerTemplate schedules the service
+var systemdTimerTemplate = template.Must(template.New("timer").Parse(`[Unit]
+Description=Run Audit Checks security audit on a schedule
+
+[Timer]
+OnCalendar={{.OnCalendar}}
+Persistent=true
+RandomizedDelaySec=5m
+
+[Install]
+WantedBy=timers.target
+`))
+
+// systemdUnitDir returns the directory for system or user units
+func systemdUnitDir(userUnit bool) (string, error) {
+	if !userUnit {
+		return "/etc/systemd/system", nil
+	}
+
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(configDir, "systemd", "user"), nil
+}
+
+// systemctl runs systemctl with --user when needed
+func systemctl(userUnit bool, args ...string) error {
+	if userUnit {
+		args = append([]string{"--user"}, args...)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.Command("systemctl", args...)
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("systemctl %s failed: %s", strings.Join(args, " "), strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// installSystemd writes the service and timer units and enables the timer
+func installSystemd(opts *installOptions, printOnly bool) error {
+	var service, timer bytes.Buffer
+	if err := systemdServiceTemplate.Execute(&service, opts); err != nil {
+		return fmt.Errorf("failed to render service unit: %w", err)
+	}
+	if err := systemdTimerTemplate.Execute(&timer, opts); err != nil {
+		return fmt.Errorf("failed to render timer unit: %w", err)
+	}
+
+	if printOnly {
+		fmt.Printf("# %s.service\n%s\n# %s.timer\n%s", systemdUnitName, service.String(), systemdUnitName, timer.String())
+		return nil
+	}
+
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return fmt.Errorf("systemctl not found in PATH: %w", err)
+	}
+
+	unitDir, err := systemdUnitDir(opts.UserUnit)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(unitDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", unitDir, err)
+	}
+
+	servicePath := filepath.Join(unitDir, systemdUnitName+".service")
+	timerPath := filepath.Join(unitDir, systemdUnitName+".timer")
+
+	if err := os.WriteFile(servicePath, service.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s (try sudo or --user): %w", servicePath, err)
+	}
+	if err := os.WriteFile(timerPath, timer.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", timerPath, err)
+	}
+
+	if err := systemctl(opts.UserUnit, "daemon-reload"); err != nil {
+		return err
+	}
+	if err := systemctl(opts.UserUnit, "enable", "--now", systemdUnitName+".timer"); err != nil {
+		return err
+	}
+
+	fmt.Printf("Installed %s\n", servicePath)
+	fmt.Printf("Installed %s\n", timerPath)
+	fmt.Printf("Timer enabled (OnCalendar=%s).\n", opts.OnCalendar)
+
+	return nil
+}
+
+// uninstallSystemd disables the timer and removes both units
+func uninstallSystemd(userUnit bool) error {
+	unitDir, err := systemdUnitDir(userUnit)
+	if err != nil {
+		return err
+	}
+
+	servicePath := filepath.Join(unitDir, systemdUnitName+".service")
+	timerPath := filepath.Join(unitDir, systemdUnitName+".timer")
+
+	if _, err := os.Stat(timerPath); os.IsNotExist(err) {
+		if _, err := os.Stat(servicePath); os.IsNotExist(err) {
+			fmt.Printf("No audit-checks systemd units found in %s.\n", unitDir)
+			return nil
+		}
+	}
+
+	// Ignore errors here: the timer may already be disabled
+	_ = systemctl(userUnit, "disable", "--now", systemdUnitName+".timer")
+
+	for _, p := range []string{timerPath, servicePath} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", p, err)
+		}
+	}
+
+	if err := systemctl(userUnit, "daemon-reload"); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed audit-checks systemd units from %s.\n", unitDir)
+	return nil
+}
@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/jobs"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// RunJobs shows the jobs of the serve mode's job queue (JOB_QUEUE)
+func RunJobs(args []string) error {
+	subcmd := "list"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcmd, args = args[0], args[1:]
+	}
+
+	switch subcmd {
+	case "list", "ls":
+		return runJobsList(args)
+	case "help":
+		printJobsHelp()
+		return nil
+	default:
+		fmt.Printf("Unknown jobs subcommand: %s\n\n", subcmd)
+		printJobsHelp()
+		os.Exit(1)
+		return nil
+	}
+}
+
+func printJobsHelp() {
+	fmt.Print(`jobs - Show the jobs of the serve mode's job queue
+
+With JOB_QUEUE set to embedded or redis, 'serve' runs the audits triggered through the
+API or by JOB_SCHEDULE_INTERVAL, and the retries of failed notifications, as jobs.
+
+Usage:
+  audit-checks jobs [subcommand] [flags]
+
+Subcommands:
+  list              List the jobs, newest first (default)
+
+List Flags:
+  --status          Comma-separated statuses: queued, running, succeeded, failed
+  --type            Job type: audit or notify
+  --app             Only show the jobs of this app
+  --limit           Number of jobs to show (default: 50)
+  --json            Print the jobs as JSON
+
+Examples:
+  audit-checks jobs
+  audit-checks jobs list --status queued,running
+  audit-checks jobs list --type notify --status failed --json
+`)
+}
+
+func runJobsList(args []string) error {
+	fs := flag.NewFlagSet("jobs list", flag.ExitOnError)
+	status := fs.String("status", "", "Comma-separated statuses: queued, running, succeeded, failed")
+	jobType := fs.String("type", "", "Job type: audit or notify")
+	appName := fs.String("app", "", "Only show the jobs of this app")
+	limit := fs.Int("limit", 50, "Number of jobs to show")
+	jsonOutput := fs.Bool("json", false, "Print the jobs as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+	if !cfg.IsJobQueueEnabled() {
+		return fmt.Errorf("the job queue is off: set JOB_QUEUE to embedded or redis")
+	}
+
+	// Connect to database
+	db, err := getDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	queue, err := jobs.Open(cfg, db)
+	if err != nil {
+		return fmt.Errorf("failed to open job queue: %w", err)
+	}
+	defer queue.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := jobs.Filter{Type: *jobType, AppName: *appName, Limit: *limit}
+	for _, s := range strings.Split(*status, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			filter.Statuses = append(filter.Statuses, s)
+		}
+	}
+	list, total, err := queue.List(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to list jobs: %w", err)
+	}
+
+	// Payloads carry whole reports
+	for i := range list {
+		list[i].Payload = ""
+	}
+
+	if *jsonOutput {
+		if list == nil {
+			list = []models.Job{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(list)
+	}
+
+	if len(list) == 0 {
+		fmt.Println("No jobs.")
+		return nil
+	}
+
+	// Calculate dynamic column widths
+	maxAppLen := 3 // minimum "APP" header length
+	for _, job := range list {
+		if len(job.AppName) > maxAppLen {
+			maxAppLen = len(job.AppName)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%-26s  %-6s  %-*s  %-9s  %-8s  %-8s  %-16s  %s\n",
+		"ID", "TYPE", maxAppLen, "APP", "STATUS", "SOURCE", "ATTEMPTS", "CREATED", "ERROR")
+	fmt.Println(strings.Repeat("-", 26+2+6+2+maxAppLen+2+9+2+8+2+8+2+16+2+30))
+
+	for _, job := range list {
+		app := job.AppName
+		if app == "" {
+			app = "*"
+		}
+		errMsg := job.Error
+		if len(errMsg) > 80 {
+			errMsg = errMsg[:77] + "..."
+		}
+		if job.Status == models.JobQueued && job.RunAt.After(time.Now()) {
+			errMsg = strings.TrimSpace(fmt.Sprintf("retry at %s %s", job.RunAt.Local().Format("15:04:05"), errMsg))
+		}
+		fmt.Printf("%-26s  %-6s  %-*s  %-9s  %-8s  %-8s  %-16s  %s\n",
+			job.ID, job.Type, maxAppLen, app, job.Status, job.Source,
+			fmt.Sprintf("%d/%d", job.Attempts, job.MaxAttempts),
+			job.CreatedAt.Local().Format("2006-01-02 15:04"), errMsg)
+	}
+	fmt.Println()
+
+	if total > int64(len(list)) {
+		fmt.Printf("Showing %d of %d jobs (--limit)\n\n", len(list), total)
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/shadowbane/audit-checks/pkg/auditor"
+)
+
+// RunParse runs an auditor's parser on a tool output without auditing anything, or
+// checks the parsers against the recorded outputs of every supported tool version.
+func RunParse(args []string) error {
+	fs := flag.NewFlagSet("parse", flag.ExitOnError)
+	auditorName := fs.String("auditor", "", "Parser to use: npm or composer (default: from a recorded fixture's name)")
+	fixture := fs.String("fixture", "", "Tool output to parse: a file, - for stdin, or a recorded fixture name")
+	asJSON := fs.Bool("json", false, "Print the parse summary as JSON (the golden file format)")
+	check := fs.Bool("check", false, "Check every recorded fixture against its golden summary")
+	list := fs.Bool("list", false, "List the recorded fixtures")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	switch {
+	case *check:
+		return runParseCheck()
+	case *list:
+		return runParseList()
+	case *fixture == "":
+		return fmt.Errorf("--fixture, --check or --list is required")
+	}
+
+	output, recorded, err := readParseFixture(*fixture)
+	if err != nil {
+		return err
+	}
+	name := *auditorName
+	if name == "" {
+		if recorded == nil {
+			return fmt.Errorf("--auditor is required to parse %s", *fixture)
+		}
+		name = recorded.Auditor
+	}
+
+	summary := auditor.SummarizeParse(auditor.ParseFixture(name, output))
+	if *asJSON {
+		data, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if summary.Error != "" {
+		return fmt.Errorf("%s parser failed: %s", name, summary.Error)
+	}
+	fmt.Printf("%s: %d finding(s) (critical %d, high %d, moderate %d, low %d, info %d)\n",
+		name, len(summary.Findings), summary.Critical, summary.High, summary.Moderate, summary.Low, summary.Info)
+	if summary.Questionable != "" {
+		fmt.Printf("  questionable: %s\n", summary.Questionable)
+	}
+	for _, f := range summary.Findings {
+		id := f.ID
+		if id == "" {
+			id = "-"
+		}
+		fmt.Printf("  %-9s %-30s %-16s %s\n", f.Severity, f.Package, id, f.Title)
+	}
+	return nil
+}
+
+// readParseFixture reads the tool output to parse. A name that is not a file is looked
+// up among the recorded fixtures, which are returned as well.
+func readParseFixture(name string) ([]byte, *auditor.ConformanceFixture, error) {
+	if name == "-" {
+		output, err := io.ReadAll(os.Stdin)
+		return output, nil, err
+	}
+
+	output, err := os.ReadFile(name)
+	if err == nil {
+		return output, nil, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, nil, err
+	}
+
+	fixtures, ferr := auditor.ConformanceFixtures()
+	if ferr != nil {
+		return nil, nil, ferr
+	}
+	for _, fixture := range fixtures {
+		if fixture.Name == name {
+			return fixture.Output, &fixture, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%s is neither a file nor a recorded fixture (see 'audit-checks parse --list')", name)
+}
+
+// runParseCheck parses every recorded fixture and reports drift from the golden summaries
+func runParseCheck() error {
+	results, err := auditor.CheckConformance()
+	if err != nil {
+		return err
+	}
+
+	drifted := 0
+	for _, result := range results {
+		if len(result.Drift) == 0 {
+			outcome := fmt.Sprintf("%d finding(s)", len(result.Got.Findings))
+			if result.Got.Error != "" {
+				outcome = "rejected: " + result.Got.Error
+			}
+			fmt.Printf("  ok    %-22s %s\n", result.Fixture.Name, outcome)
+			continue
+		}
+
+		drifted++
+		fmt.Printf("  FAIL  %s\n", result.Fixture.Name)
+		for _, line := range result.Drift {
+			fmt.Printf("        %s\n", line)
+		}
+	}
+
+	fmt.Println()
+	if drifted > 0 {
+		return fmt.Errorf("%d of %d fixture(s) no longer parse to their golden summary", drifted, len(results))
+	}
+	fmt.Printf("All %d fixtures parse to their golden summary.\n", len(results))
+	return nil
+}
+
+// runParseList lists the recorded fixtures
+func runParseList() error {
+	fixtures, err := auditor.ConformanceFixtures()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%-22s %-10s %s\n", "FIXTURE", "AUDITOR", "GOLDEN")
+	for _, fixture := range fixtures {
+		golden := fmt.Sprintf("%d finding(s)", len(fixture.Golden.Findings))
+		if fixture.Golden.Error != "" {
+			golden = "error: " + fixture.Golden.Error
+		}
+		fmt.Printf("%-22s %-10s %s\n", fixture.Name, fixture.Auditor, golden)
+	}
+	return nil
+}
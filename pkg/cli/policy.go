@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/application"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/policy"
+)
+
+// policySubjectsShown is the number of subjects of a violation printed by `policy check`
+const policySubjectsShown = 10
+
+// RunPolicy handles the policy command and its subcommands
+func RunPolicy(args []string) error {
+	subcmd := "check"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		subcmd, args = args[0], args[1:]
+	}
+
+	switch subcmd {
+	case "check":
+		return runPolicyCheck(args)
+	case "validate":
+		return runPolicyValidate(args)
+	case "help":
+		printPolicyHelp()
+		return nil
+	default:
+		fmt.Printf("Unknown policy subcommand: %s\n\n", subcmd)
+		printPolicyHelp()
+		os.Exit(1)
+		return nil
+	}
+}
+
+func printPolicyHelp() {
+	fmt.Print(`policy - Evaluate the pass/fail policies of POLICY_FILE
+
+Policies deny findings, dependencies or apps with an expression, e.g. critical
+findings open for more than a week in apps tagged prod. With POLICY_FILE, run
+evaluates them after each audit: violations are notified, fail the run (exit 1)
+instead of the severity threshold, and set the colours of the status page.
+
+Usage:
+  audit-checks policy [subcommand] [flags]
+
+Subcommands:
+  check             Evaluate the policies on the latest results of the enabled apps (default)
+  validate [file]   Check that a policy file is valid (default: POLICY_FILE)
+
+Check Flags:
+  --app, -a         Only evaluate this app
+  --file            Policy file to evaluate instead of POLICY_FILE, e.g. before deploying it
+  --json            Print the violations as JSON
+
+Check exits 1 if a policy whose action is fail is violated, 2 on errors.
+
+Examples:
+  audit-checks policy validate policies.yaml
+  audit-checks policy check --file policies.yaml
+  audit-checks policy check --app checkout --json
+`)
+}
+
+func runPolicyCheck(args []string) error {
+	fs := flag.NewFlagSet("policy check", flag.ExitOnError)
+	appName := fs.String("app", "", "Only evaluate this app")
+	fs.StringVar(appName, "a", "", "Only evaluate this app (shorthand)")
+	file := fs.String("file", "", "Policy file to evaluate instead of POLICY_FILE")
+	jsonOutput := fs.Bool("json", false, "Print the violations as JSON")
+	_ = fs.Parse(args)
+
+	cfg := config.Get()
+	if *file != "" {
+		cfg.Settings.PolicyFile = *file
+	}
+	if cfg.Settings.PolicyFile == "" {
+		policyError(fmt.Errorf("no policy file: set POLICY_FILE or use --file"))
+	}
+
+	app, err := application.New(cfg)
+	if err != nil {
+		policyError(fmt.Errorf("failed to initialize application: %w", err))
+	}
+	defer app.Close()
+
+	if *appName != "" {
+		if found, _ := cfg.GetApp(*appName); found == nil {
+			policyError(fmt.Errorf("app not found: %s", *appName))
+		}
+	}
+
+	violations, err := app.EvaluatePolicies(context.Background(), *appName, time.Now())
+	if err != nil {
+		policyError(err)
+	}
+	failing := slices.ContainsFunc(violations, models.PolicyViolation.Failing)
+
+	if *jsonOutput {
+		if violations == nil {
+			violations = []models.PolicyViolation{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(violations); err != nil {
+			return err
+		}
+	} else {
+		for _, v := range violations {
+			fmt.Printf("%-4s  %s: %s\n", strings.ToUpper(v.Action), v.AppName, v.Policy)
+			if v.Description != "" {
+				fmt.Printf("      %s\n", v.Description)
+			}
+			if v.Error != "" {
+				fmt.Printf("      could not be evaluated: %s\n", v.Error)
+			}
+			for i, subject := range v.Subjects {
+				if i == policySubjectsShown {
+					fmt.Printf("      ... and %d more\n", len(v.Subjects)-policySubjectsShown)
+					break
+				}
+				fmt.Printf("      - %s\n", subject)
+			}
+		}
+		if len(violations) == 0 {
+			fmt.Printf("No policy violated (%d policies)\n", len(app.Policies.Policies))
+		} else {
+			fmt.Printf("%d violation(s) of %d policies\n", len(violations), len(app.Policies.Policies))
+		}
+	}
+
+	if failing {
+		os.Exit(1)
+	}
+	return nil
+}
+
+func runPolicyValidate(args []string) error {
+	fs := flag.NewFlagSet("policy validate", flag.ExitOnError)
+	_ = fs.Parse(args)
+
+	file := fs.Arg(0)
+	if file == "" {
+		file = config.Get().Settings.PolicyFile
+	}
+	if file == "" {
+		policyError(fmt.Errorf("no policy file: set POLICY_FILE or pass the file"))
+	}
+
+	set, err := policy.Load(file)
+	if err != nil {
+		policyError(err)
+	}
+
+	fmt.Printf("%s: %d policies\n", file, len(set.Policies))
+	for _, p := range set.Policies {
+		fmt.Printf("  %-30s %-10s %s\n", p.Name, p.Scope, p.Action)
+	}
+	return nil
+}
+
+// policyError exits with code 2, keeping 1 for violations
+func policyError(err error) {
+	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	os.Exit(2)
+}
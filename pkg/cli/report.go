@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/application"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/reporter"
+)
+
+// RunReport runs the report subcommands
+func RunReport(args []string) error {
+	if len(args) == 0 {
+		printReportHelp()
+		return nil
+	}
+
+	subcmd := args[0]
+	subargs := args[1:]
+
+	switch subcmd {
+	case "executive":
+		return runReportExecutive(subargs)
+	case "scorecards":
+		return runReportScorecards(subargs)
+	case "site":
+		return runReportSite(subargs)
+	case "status":
+		return runReportStatus(subargs)
+	case "feed":
+		return runReportFeed(subargs)
+	case "help":
+		printReportHelp()
+		return nil
+	default:
+		fmt.Printf("Unknown report subcommand: %s\n\n", subcmd)
+		printReportHelp()
+		os.Exit(1)
+		return nil
+	}
+}
+
+func printReportHelp() {
+	fmt.Print(`report - Generate reports from the audit history
+
+Usage:
+  audit-checks report [subcommand] [flags]
+
+Subcommands:
+  executive    Fleet-wide report: trends, SLA compliance, top offenders and new advisories
+  scorecards   Per-app security scorecards for Backstage or another service catalog
+  site         Static HTML dashboard of the audit history: apps, trends and latest findings
+  status       Sanitized status page for wallboards: a colour and last audit date per app
+  feed         Atom feed of the findings first seen in each run, for feed readers
+
+Executive Flags:
+  --days <n>        Days covered by the report (default: 7)
+  --no-email        Only write the report files, do not email them
+  --dry-run         Write the report files, log instead of emailing
+
+Scorecards Flags:
+  --format <list>   Comma-separated formats: yaml, json (default: SCORECARD_FORMATS)
+
+Site Flags:
+  --output <dir>    Directory to write the site to (default: <REPORT_OUTPUT_DIR>/site)
+
+Status Flags:
+  --output <dir>    Directory to write the status page to
+                    (default: STATUS_PAGE_DIR, or <REPORT_OUTPUT_DIR>/status)
+
+Feed Flags:
+  --output <file>   File to write the feed to
+                    (default: FEED_PATH, or <REPORT_OUTPUT_DIR>/feed.atom)
+
+The report is written as Markdown and HTML to <REPORT_OUTPUT_DIR>/executive/ and
+emailed to EXECUTIVE_REPORT_EMAILS. With EXECUTIVE_REPORT_ENABLED=true, 'run'
+generates and emails it once a week.
+
+Scorecards are written to <REPORT_OUTPUT_DIR>/scorecards/<app>.<format> with the
+last audit date, open vulnerability counts and SLA status of each app. With
+SCORECARDS_ENABLED=true, 'run' refreshes them after every run.
+
+The site is an index.html of the apps with the fleet's trend, and a page per app
+(apps/<app>.html) with its trend chart and latest findings. It has no scripts or
+external assets, so it can be published as-is to a web server or an S3 bucket.
+
+The status page (index.html and status.json) only shows each enabled app as red
+(critical or high findings), yellow (moderate or low findings, or no audit in
+STATUS_PAGE_STALE_DAYS) or green, with its last audit date: no counts, packages
+or CVEs. With STATUS_PAGE_ENABLED=true, 'run' refreshes it after every run.
+
+The feed has an entry per run and app with the findings first seen in it over the
+last FEED_DAYS days. With FEED_ENABLED=true, 'run' refreshes it after every run and
+'serve' serves it at /api/v1/feed.
+
+Examples:
+  audit-checks report executive
+  audit-checks report executive --days 30 --no-email
+  audit-checks report scorecards --format yaml,json
+  audit-checks report site --output /var/www/security
+  audit-checks report status --output /var/www/status
+  audit-checks report feed --output /var/www/security/feed.atom
+`)
+}
+
+func runReportExecutive(args []string) error {
+	fs := flag.NewFlagSet("report executive", flag.ExitOnError)
+	days := fs.Int("days", 7, "Days covered by the report")
+	noEmail := fs.Bool("no-email", false, "Do not email the report")
+	dryRun := fs.Bool("dry-run", false, "Log instead of emailing the report")
+	_ = fs.Parse(args)
+
+	if *days <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	// Load configuration
+	cfg := config.Get()
+	cfg.DryRun = *dryRun
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	app, err := application.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer app.Close()
+
+	period := time.Duration(*days) * 24 * time.Hour
+	files, err := app.GenerateExecutiveReport(context.Background(), period, !*noEmail)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		fmt.Println(file)
+	}
+
+	return nil
+}
+
+func runReportSite(args []string) error {
+	fs := flag.NewFlagSet("report site", flag.ExitOnError)
+	output := fs.String("output", "", "Directory to write the site to")
+	_ = fs.Parse(args)
+
+	// Load configuration
+	cfg := config.Get()
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	app, err := application.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer app.Close()
+
+	files, err := app.GenerateSite(context.Background(), *output)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(files[0])
+
+	return nil
+}
+
+func runReportStatus(args []string) error {
+	fs := flag.NewFlagSet("report status", flag.ExitOnError)
+	output := fs.String("output", "", "Directory to write the status page to")
+	_ = fs.Parse(args)
+
+	// Load configuration
+	cfg := config.Get()
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	app, err := application.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer app.Close()
+
+	files, err := app.GenerateStatusPage(context.Background(), *output)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		fmt.Println(file)
+	}
+
+	return nil
+}
+
+func runReportFeed(args []string) error {
+	fs := flag.NewFlagSet("report feed", flag.ExitOnError)
+	output := fs.String("output", "", "File to write the feed to")
+	_ = fs.Parse(args)
+
+	// Load configuration
+	cfg := config.Get()
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	app, err := application.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer app.Close()
+
+	file, err := app.GenerateFeed(context.Background(), *output)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(file)
+
+	return nil
+}
+
+func runReportScorecards(args []string) error {
+	fs := flag.NewFlagSet("report scorecards", flag.ExitOnError)
+	format := fs.String("format", "", "Comma-separated formats: yaml, json")
+	_ = fs.Parse(args)
+
+	// Load configuration
+	cfg := config.Get()
+
+	formats := cfg.Settings.ScorecardFormats
+	if *format != "" {
+		formats = splitAndTrim(strings.ToLower(*format))
+	}
+	for _, f := range formats {
+		if !slices.Contains(reporter.ScorecardFormats, f) {
+			return fmt.Errorf("unknown scorecard format %q (expected yaml or json)", f)
+		}
+	}
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	app, err := application.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer app.Close()
+
+	files, err := app.GenerateScorecards(context.Background(), formats)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		fmt.Println(file)
+	}
+
+	return nil
+}
@@ -14,7 +14,7 @@ import (
 // RunAudit runs the audit command
 func RunAudit(args []string) error {
 	// Parse flags
-	targetApp, dryRun, verbose, reportOnly, jsonOutput := ParseRunFlags(args)
+	targetApp, dryRun, verbose, reportOnly, jsonOutput, adaptive, strict, canary, gitlabReport, noAICache := ParseRunFlags(args)
 
 	// Set verbose logging if requested
 	if verbose {
@@ -30,6 +30,19 @@ func RunAudit(args []string) error {
 	cfg.Verbose = verbose
 	cfg.ReportOnly = reportOnly
 	cfg.JSONOutput = jsonOutput
+	cfg.GitLabReportPath = gitlabReport
+	cfg.NoAICache = noAICache
+	if canary > 0 && targetApp != "" {
+		zap.S().Warnf("Ignoring --canary: --app %s is audited on its own", targetApp)
+		canary = 0
+	}
+	cfg.CanarySize = canary
+	if adaptive {
+		cfg.Settings.AdaptiveSchedule = true
+	}
+	if strict {
+		cfg.Settings.StrictMode = true
+	}
 
 	// Ensure directories exist
 	if err := cfg.EnsureDirectories(); err != nil {
@@ -55,16 +68,27 @@ func RunAudit(args []string) error {
 		zap.S().Fatalf("Failed to initialize application: %v", err)
 	}
 	defer app.Close()
+	application.CheckTelegramPermissions(cfg)
 
 	// Run audit
-	if err := app.Run(ctx); err != nil {
-		zap.S().Errorf("Audit error: %v", err)
+	runErr := app.Run(ctx)
+
+	// Let the operator know if a newer release is available (rate-limited)
+	logUpdateNotice(cfg, app.Store)
+
+	if runErr != nil {
+		zap.S().Errorf("Audit error: %v", runErr)
 		os.Exit(2)
 	}
 
-	// Exit with appropriate code
-	if app.HasVulnerabilities() {
-		os.Exit(1) // Vulnerabilities found
+	// Exit with appropriate code; canary runs only fail on auditor failures, and with
+	// POLICY_FILE the policies decide instead of the severity threshold
+	failed := app.HasVulnerabilities()
+	if app.Policies != nil {
+		failed = app.HasPolicyFailures()
+	}
+	if failed && cfg.CanarySize == 0 {
+		os.Exit(1) // Vulnerabilities found or a policy violated
 	}
 
 	return nil
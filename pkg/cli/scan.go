@@ -29,7 +29,7 @@ func runAppScan(args []string) error {
 	fs := flag.NewFlagSet("app scan", flag.ExitOnError)
 
 	scanPath := fs.String("path", "", "Directory to scan for Laravel apps (required)")
-	appType := fs.String("type", "auto", "App type for added apps: auto, npm, composer")
+	appType := fs.String("type", "auto", "App type for added apps: auto, npm, composer, go")
 	addAll := fs.Bool("all", false, "Add all found apps without prompting")
 
 	_ = fs.Parse(args)
@@ -128,7 +128,7 @@ func runAppScan(args []string) error {
 		selectedApps[i] = apps[idx]
 	}
 
-	added, err := addAppsToDatabase(db, selectedApps, *appType)
+	added, err := addAppsToDatabase(db, cfg, selectedApps, *appType)
 	if err != nil {
 		return fmt.Errorf("failed to add apps: %w", err)
 	}
@@ -345,7 +345,7 @@ func promptAppSelection(apps []LaravelApp) ([]int, error) {
 }
 
 // addAppsToDatabase adds selected apps to the database
-func addAppsToDatabase(db *gorm.DB, apps []LaravelApp, appType string) (int, error) {
+func addAppsToDatabase(db *gorm.DB, cfg *config.Config, apps []LaravelApp, appType string) (int, error) {
 	fmt.Printf("\nAdding %d apps...\n", len(apps))
 
 	var added int
@@ -373,7 +373,8 @@ func addAppsToDatabase(db *gorm.DB, apps []LaravelApp, appType string) (int, err
 			continue
 		}
 
-		zap.S().Infof("App created via scan: %s (ID: %s)", finalName, newApp.ID)
+		zap.S().Infof("App created via scan: %s (ID: %s) operator=%s", finalName, newApp.ID, cfg.Operator)
+		recordActivity(db, cfg, models.ActivityAppAdded, finalName, "path="+app.Path+" (scan)")
 		fmt.Printf("  + Added: %s\n", finalName)
 		added++
 	}
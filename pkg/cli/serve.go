@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/shadowbane/audit-checks/pkg/api"
+	"github.com/shadowbane/audit-checks/pkg/application"
+	"github.com/shadowbane/audit-checks/pkg/buildinfo"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/jobs"
+	"go.uber.org/zap"
+)
+
+// RunServe runs the serve command
+func RunServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+
+	listen := fs.String("listen", "", "Address to listen on (default: API_LISTEN or 127.0.0.1:8080)")
+
+	_ = fs.Parse(args)
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	addr := cfg.APIListen
+	if *listen != "" {
+		addr = *listen
+	}
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	db, err := getDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	if cfg.APIToken == "" {
+		zap.S().Warn("API_TOKEN is not set; the API is served without authentication")
+	}
+
+	build := buildinfo.Get()
+	build.Features = cfg.EnabledFeatures()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	server := api.NewServer(db, cfg.APIToken, build)
+	if cfg.ResendWebhookSecret != "" {
+		server.SetResendWebhookSecret(cfg.ResendWebhookSecret)
+		zap.S().Info("Recording email delivery status from Resend webhooks at /api/v1/webhooks/resend")
+	}
+
+	if cfg.Settings.FeedEnabled {
+		server.SetFeed(cfg.Settings.FeedPath, cfg.Settings.FeedToken)
+		zap.S().Infof("Serving the Atom feed %s at /api/v1/feed", cfg.Settings.FeedPath)
+	}
+
+	if cfg.IsJobQueueEnabled() {
+		queue, err := jobs.Open(cfg, db)
+		if err != nil {
+			return fmt.Errorf("failed to open job queue: %w", err)
+		}
+		defer queue.Close()
+
+		// The jobs left running by a server that was killed run again. The claims of a
+		// Redis queue shared by servers are leases instead, whose jobs are queued again
+		// once they expire.
+		if embedded, ok := queue.(*jobs.EmbeddedQueue); ok {
+			recovered, err := embedded.Recover(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to recover jobs: %w", err)
+			}
+			if recovered > 0 {
+				zap.S().Warnf("Queued %d interrupted job(s) again", recovered)
+			}
+		}
+
+		application.CheckTelegramPermissions(cfg)
+		worker := jobs.NewWorker(queue)
+		application.RegisterJobHandlers(worker, cfg, queue)
+
+		// Stopped before the queue is closed, also when the API server fails to start
+		var wg sync.WaitGroup
+		defer func() {
+			stop()
+			wg.Wait()
+		}()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			worker.Run(ctx, cfg.JobWorkers)
+		}()
+
+		if cfg.JobScheduleInterval > 0 {
+			go application.ScheduleAudits(ctx, queue, cfg.JobScheduleInterval)
+		}
+
+		server.SetJobQueue(queue)
+		zap.S().Infof("Running audits and notification retries from the %s job queue", cfg.JobQueue)
+	}
+
+	return server.ListenAndServe(ctx, addr)
+}
@@ -1,27 +1,43 @@
 package cli
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/glebarez/sqlite"
+	"github.com/shadowbane/audit-checks/pkg/auditor"
 	"github.com/shadowbane/audit-checks/pkg/config"
 	"github.com/shadowbane/audit-checks/pkg/config/dblogger"
 	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/store"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
 )
 
+// envFile is the .env file read by config.Get and written by the setup wizard
+const envFile = ".env"
+
 // RunSetup runs the setup command
 func RunSetup(args []string) error {
+	fs := flag.NewFlagSet("setup", flag.ExitOnError)
+
+	configureOnly := fs.Bool("configure", false, "Only configure notifications and AI (skip database and apps)")
+
+	_ = fs.Parse(args)
+
 	fmt.Println("=== Audit Checks Setup ===")
 	fmt.Println()
 
 	// Load config (initializes logger)
 	cfg := config.Get()
 
+	if *configureOnly {
+		return configureInteractive(cfg, envFile)
+	}
+
 	// Ensure directories exist
 	if err := cfg.EnsureDirectories(); err != nil {
 		return fmt.Errorf("failed to create directories: %w", err)
@@ -62,7 +78,7 @@ func RunSetup(args []string) error {
 
 	// Run migrations
 	fmt.Println("Running database migrations...")
-	if err := db.AutoMigrate(models.AllModels()...); err != nil {
+	if err := store.Migrate(db); err != nil {
 		return fmt.Errorf("failed to run migrations: %w", err)
 	}
 	fmt.Println("Migrations completed successfully.")
@@ -75,6 +91,15 @@ func RunSetup(args []string) error {
 
 	fmt.Println()
 
+	// Offer to configure notifications and AI, by default on first run
+	_, envErr := os.Stat(envFile)
+	if PromptYesNo("Would you like to configure notifications and AI now?", !dbExists || os.IsNotExist(envErr)) {
+		if err := configureInteractive(cfg, envFile); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+
 	// Offer to add an app if database is new
 	if !dbExists {
 		if PromptYesNo("Would you like to add an app to audit now?", true) {
@@ -87,7 +112,7 @@ func RunSetup(args []string) error {
 	fmt.Println()
 	fmt.Println("Next steps:")
 	fmt.Println("  1. Add apps to audit: audit-checks app add")
-	fmt.Println("  2. Configure environment variables in .env")
+	fmt.Println("  2. Configure notifications and AI: audit-checks setup --configure")
 	fmt.Println("  3. Run audits: audit-checks run")
 	fmt.Println()
 
@@ -117,7 +142,7 @@ func addAppInteractive(cfg *config.Config) error {
 	}
 
 	// Select type
-	typeOptions := []string{"auto (detect automatically)", "npm", "composer"}
+	typeOptions := append([]string{"auto (detect automatically)"}, auditor.Types...)
 	typeIndex := PromptSelect("Select app type", typeOptions, 0)
 	appType := "auto"
 	if typeIndex > 0 {
@@ -132,12 +157,22 @@ func addAppInteractive(cfg *config.Config) error {
 			for _, e := range splitAndTrim(email) {
 				emailNotifications = append(emailNotifications, e)
 			}
+			if err := validateEmails(emailNotifications); err != nil {
+				return err
+			}
 		}
 	}
 
 	// Telegram notifications
 	telegramEnabled := PromptYesNo("Enable Telegram notifications?", false)
 
+	// Discord notifications
+	discordEnabled := PromptYesNo("Enable Discord notifications?", false)
+	var discordChannelID string
+	if discordEnabled {
+		discordChannelID = Prompt("Discord channel ID (empty for a thread in the default channel): ")
+	}
+
 	// Create app in database
 	gormConfig := &gorm.Config{
 		Logger: &dblogger.ZapLogger{
@@ -167,6 +202,8 @@ func addAppInteractive(cfg *config.Config) error {
 		Type:               appType,
 		EmailNotifications: emailNotifications,
 		TelegramEnabled:    telegramEnabled,
+		DiscordEnabled:     discordEnabled,
+		DiscordChannelID:   discordChannelID,
 		Enabled:            true,
 	}
 
@@ -174,7 +211,8 @@ func addAppInteractive(cfg *config.Config) error {
 		return fmt.Errorf("failed to create app: %w", err)
 	}
 
-	zap.S().Infof("App created: %s (ID: %s)", name, app.ID)
+	zap.S().Infof("App created: %s (ID: %s) operator=%s", name, app.ID, cfg.Operator)
+	recordActivity(db, cfg, models.ActivityAppAdded, name, "path="+path+" (setup)")
 	fmt.Printf("\nApp '%s' added successfully!\n", name)
 
 	// Ask if user wants to add another
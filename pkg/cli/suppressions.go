@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RunSuppressions manages the email suppression list: addresses no longer sent to
+// because their emails bounced or were marked as spam, or suppressed by hand
+func RunSuppressions(args []string) error {
+	subcmd := "list"
+	if len(args) > 0 {
+		subcmd, args = args[0], args[1:]
+	}
+
+	switch subcmd {
+	case "list", "ls":
+		return runSuppressionsList(args)
+	case "add":
+		return runSuppressionsAdd(args)
+	case "remove", "rm":
+		return runSuppressionsRemove(args)
+	case "help":
+		printSuppressionsHelp()
+		return nil
+	default:
+		fmt.Printf("Unknown suppressions subcommand: %s\n\n", subcmd)
+		printSuppressionsHelp()
+		os.Exit(1)
+		return nil
+	}
+}
+
+func printSuppressionsHelp() {
+	fmt.Print(`suppressions - Manage the email suppression list
+
+Emails are not sent to suppressed addresses. Resend webhooks (RESEND_WEBHOOK_SECRET)
+suppress the addresses that bounce permanently or mark the emails as spam.
+
+Usage:
+  audit-checks suppressions [subcommand] [flags]
+
+Subcommands:
+  list              List the suppressed addresses (default)
+  add <address>     Suppress an address
+  remove <address>  Send to an address again, e.g. once its mailbox is fixed
+
+Add Flags:
+  --reason          Why the address is suppressed
+
+Examples:
+  audit-checks suppressions
+  audit-checks suppressions add former-dev@example.com --reason "left the company"
+  audit-checks suppressions remove ops@example.com
+`)
+}
+
+func runSuppressionsList(args []string) error {
+	fs := flag.NewFlagSet("suppressions list", flag.ExitOnError)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	// Connect to database
+	db, err := getDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	var suppressions []models.EmailSuppression
+	if err := db.Order("created_at DESC").Find(&suppressions).Error; err != nil {
+		return fmt.Errorf("failed to list suppressions: %w", err)
+	}
+
+	if len(suppressions) == 0 {
+		fmt.Println("No suppressed email addresses.")
+		return nil
+	}
+
+	// Calculate dynamic column widths
+	maxAddressLen := 7 // minimum "ADDRESS" header length
+	for _, s := range suppressions {
+		if len(s.Address) > maxAddressLen {
+			maxAddressLen = len(s.Address)
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%-*s  %-10s  %-16s  %s\n", maxAddressLen, "ADDRESS", "SOURCE", "SINCE", "APP / REASON")
+	fmt.Println(strings.Repeat("-", maxAddressLen+2+10+2+16+2+40))
+
+	for _, s := range suppressions {
+		detail := s.AppName
+		if s.Reason != "" {
+			detail = strings.TrimSpace(detail + " " + s.Reason)
+		}
+		fmt.Printf("%-*s  %-10s  %-16s  %s\n",
+			maxAddressLen, s.Address, s.Source, s.CreatedAt.Local().Format("2006-01-02 15:04"), detail)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+func runSuppressionsAdd(args []string) error {
+	address, flagArgs := extractAppName(args)
+
+	fs := flag.NewFlagSet("suppressions add", flag.ExitOnError)
+	reason := fs.String("reason", "", "Why the address is suppressed")
+	_ = fs.Parse(flagArgs)
+
+	if address == "" {
+		return fmt.Errorf("email address is required: audit-checks suppressions add <address>")
+	}
+	if err := helpers.ValidateEmail(address); err != nil {
+		return err
+	}
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	// Connect to database
+	db, err := getDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	suppression := models.EmailSuppression{
+		Address:   strings.ToLower(address),
+		Source:    models.SuppressionManual,
+		Reason:    strings.TrimSpace(*reason),
+		CreatedAt: time.Now(),
+	}
+	if err := db.Save(&suppression).Error; err != nil {
+		return fmt.Errorf("failed to suppress %s: %w", address, err)
+	}
+
+	zap.S().Infof("Email suppressed address=%s operator=%s", suppression.Address, cfg.Operator)
+	recordActivity(db, cfg, models.ActivityEmailSuppressed, "", suppression.Address)
+	fmt.Printf("Emails are no longer sent to %s.\n", suppression.Address)
+
+	return nil
+}
+
+func runSuppressionsRemove(args []string) error {
+	if len(args) == 0 || args[0] == "" {
+		return fmt.Errorf("email address is required: audit-checks suppressions remove <address>")
+	}
+	address := strings.ToLower(args[0])
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	// Connect to database
+	db, err := getDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	result := db.Where("address = ?", address).Delete(&models.EmailSuppression{})
+	if result.Error != nil {
+		return fmt.Errorf("failed to remove suppression: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%s is not suppressed", address)
+	}
+
+	zap.S().Infof("Email suppression removed address=%s operator=%s", address, cfg.Operator)
+	recordActivity(db, cfg, models.ActivityEmailUnsuppressed, "", address)
+	fmt.Printf("Emails are sent to %s again.\n", address)
+
+	return nil
+}
+
+// emailSuppressions returns the suppressions of the given addresses
+func emailSuppressions(db *gorm.DB, emails []string) []models.EmailSuppression {
+	if len(emails) == 0 {
+		return nil
+	}
+
+	addresses := make([]string, len(emails))
+	for i, email := range emails {
+		addresses[i] = strings.ToLower(email)
+	}
+
+	var suppressions []models.EmailSuppression
+	err := db.Where("address IN ?", addresses).Order("address").Find(&suppressions).Error
+	if err != nil {
+		zap.S().Warnf("Failed to read email suppressions: %v", err)
+		return nil
+	}
+	return suppressions
+}
+
+// warnSuppressedEmails prints a warning for each suppressed address among emails
+func warnSuppressedEmails(db *gorm.DB, emails []string) {
+	for _, s := range emailSuppressions(db, emails) {
+		fmt.Printf("Warning: %s is suppressed (%s on %s); emails are not sent to it until 'audit-checks suppressions remove %s'.\n",
+			s.Address, s.Source, s.CreatedAt.Local().Format("2006-01-02"), s.Address)
+	}
+}
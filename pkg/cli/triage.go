@@ -0,0 +1,344 @@
+package cli
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/application"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/store"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// triageSnoozeDays is the default number of days a finding is snoozed for
+const triageSnoozeDays = 7
+
+// triageKeys is the help line of the triage prompt
+const triageKeys = "[a]ck  [i]gnore  [s]nooze [days]  [@] assign <name>  [n]ext  [p]rev  [q]uit"
+
+// triageSession is the state of `triage`: the findings of an app being gone through
+// and the decisions made on them
+type triageSession struct {
+	cfg        *config.Config
+	db         *gorm.DB
+	app        *models.App
+	snoozeDays int
+	now        time.Time
+	input      *bufio.Reader
+	triages    map[[2]string]models.FindingTriage // by auditor and finding key
+	decided    [][2]string                        // findings decided on in the session, in order
+}
+
+// RunTriage runs the triage command: goes through the open findings of an app one by
+// one, acknowledging, ignoring, snoozing or assigning each of them
+func RunTriage(args []string) error {
+	name, flagArgs := extractAppName(args)
+
+	fs := flag.NewFlagSet("triage", flag.ExitOnError)
+	all := fs.Bool("all", false, "Also go through the findings already acknowledged, ignored or snoozed")
+	severity := fs.String("severity", "", "Only go through findings of this severity or higher")
+	snoozeDays := fs.Int("snooze-days", triageSnoozeDays, "Days a finding is snoozed for when none are given")
+	telegram := fs.Bool("telegram", false, "Post a summary of the decisions to the app's Telegram topic")
+	_ = fs.Parse(flagArgs)
+
+	if name == "" {
+		return fmt.Errorf("app name is required: audit-checks triage <app>")
+	}
+	if *severity != "" {
+		if _, ok := models.SeverityOrder[strings.ToLower(*severity)]; !ok {
+			return fmt.Errorf("invalid --severity %q: use critical, high, moderate, low or info", *severity)
+		}
+	}
+	if *snoozeDays < 1 {
+		return fmt.Errorf("--snooze-days must be at least 1")
+	}
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+
+	// Connect to database
+	db, err := getDB(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer func() {
+		sqlDB, _ := db.DB()
+		if sqlDB != nil {
+			sqlDB.Close()
+		}
+	}()
+
+	// Get app
+	var app models.App
+	if err := db.Where("name = ?", name).First(&app).Error; err != nil {
+		return fmt.Errorf("app '%s' not found", name)
+	}
+
+	session := &triageSession{
+		cfg:        cfg,
+		db:         db,
+		app:        &app,
+		snoozeDays: *snoozeDays,
+		now:        time.Now(),
+		input:      bufio.NewReader(os.Stdin),
+		triages:    make(map[[2]string]models.FindingTriage),
+	}
+
+	audits, err := loadAppAudits(db, app.Name)
+	if err != nil {
+		return err
+	}
+	findings, err := appOpenFindings(db, audits, session.now)
+	if err != nil {
+		return err
+	}
+	triages, err := store.NewGormStore(db).FindingTriages(app.Name)
+	if err != nil {
+		return fmt.Errorf("failed to query triages: %w", err)
+	}
+	for _, t := range triages {
+		session.triages[[2]string{t.AuditorType, t.FindingKey()}] = t
+	}
+
+	var queue []appFinding
+	for _, f := range findings {
+		if *severity != "" && !models.MeetsSeverityThreshold(f.Severity, strings.ToLower(*severity)) {
+			continue
+		}
+		if t, ok := session.triages[triageKey(f)]; ok && t.IsTriaged(session.now) && !*all {
+			continue
+		}
+		queue = append(queue, f)
+	}
+
+	if len(queue) == 0 {
+		fmt.Printf("No findings of %s to triage.\n", app.Name)
+		return nil
+	}
+
+	fmt.Printf("Triage of %s: %d findings\n", app.Name, len(queue))
+	fmt.Println(triageKeys)
+
+	for i := 0; i < len(queue); {
+		f := queue[i]
+		fmt.Println()
+		session.printFinding(f, i, len(queue))
+
+		line, ok := session.prompt("> ")
+		if !ok {
+			break
+		}
+		command, arg, _ := strings.Cut(line, " ")
+		arg = strings.TrimSpace(arg)
+		if value, ok := strings.CutPrefix(command, "@"); ok && value != "" {
+			command, arg = "@", value
+		}
+
+		switch strings.ToLower(command) {
+		case "", "n", "next":
+			i++
+		case "p", "prev":
+			i = max(i-1, 0)
+		case "q", "quit":
+			i = len(queue)
+		case "a", "ack":
+			if session.decide(f, func(t *models.FindingTriage) {
+				t.Status, t.SnoozedUntil = models.TriageAcknowledged, nil
+			}) {
+				i++
+			}
+		case "i", "ignore":
+			if session.ignore(f) {
+				i++
+			}
+		case "s", "snooze":
+			days := session.snoozeDays
+			if arg != "" {
+				if days, err = strconv.Atoi(arg); err != nil || days < 1 {
+					fmt.Println("Snooze for a number of days, e.g. s 14")
+					continue
+				}
+			}
+			until := session.now.AddDate(0, 0, days)
+			if session.decide(f, func(t *models.FindingTriage) {
+				t.Status, t.SnoozedUntil = models.TriageSnoozed, &until
+			}) {
+				i++
+			}
+		case "@", "assign":
+			if arg == "" {
+				arg, _ = session.prompt("Assign to (- to unassign): ")
+			}
+			if arg == "" {
+				continue
+			}
+			if arg == "-" {
+				arg = ""
+			}
+			session.decide(f, func(t *models.FindingTriage) { t.Assignee = arg })
+		default:
+			fmt.Println(triageKeys)
+		}
+	}
+
+	summary := session.summary(queue)
+	fmt.Println()
+	fmt.Printf("Triaged %d findings of %s, %d left to triage.\n", len(summary.Decisions), app.Name, summary.Remaining)
+
+	if !*telegram || len(summary.Decisions) == 0 {
+		return nil
+	}
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+	a, err := application.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize application: %w", err)
+	}
+	defer a.Close()
+
+	if err := a.NotifyTriage(context.Background(), summary); err != nil {
+		return fmt.Errorf("failed to post the triage summary: %w", err)
+	}
+	fmt.Printf("Triage summary posted to the Telegram topic of %s.\n", app.Name)
+	return nil
+}
+
+// prompt reads a line of input. Returns false at the end of the input.
+func (s *triageSession) prompt(message string) (string, bool) {
+	fmt.Print(message)
+	line, err := s.input.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		fmt.Println()
+		return "", false
+	}
+	return strings.TrimSpace(line), true
+}
+
+// triageKey identifies a finding across the audits of its app and auditor
+func triageKey(f appFinding) [2]string {
+	return [2]string{f.AuditorType, f.FindingKey()}
+}
+
+// printFinding prints the finding at position i of n with its current triage
+func (s *triageSession) printFinding(f appFinding, i, n int) {
+	fmt.Printf("[%d/%d] %s  %s  (%s)\n", i+1, n, strings.ToUpper(f.Severity), f.PackageName, f.AuditorType)
+	fmt.Printf("  %s\n", f.Title)
+	if f.CVEID != "" {
+		fmt.Printf("  ID:         %s\n", f.CVEID)
+	}
+	if f.VulnerableVersions != "" || f.PatchedVersions != "" {
+		fmt.Printf("  Versions:   %s (fixed in %s)\n", cmp.Or(f.VulnerableVersions, "unknown"), cmp.Or(f.PatchedVersions, "unknown"))
+	}
+	if firstSeen := f.FirstSeenLabel(s.now); firstSeen != "" {
+		fmt.Printf("  First seen: %s\n", firstSeen)
+	}
+	if f.URL != "" {
+		fmt.Printf("  Advisory:   %s\n", f.URL)
+	}
+	if t, ok := s.triages[triageKey(f)]; ok {
+		fmt.Printf("  Triage:     %s\n", triageLabel(t))
+	}
+}
+
+// triageLabel describes the decision made on a finding
+func triageLabel(t models.FindingTriage) string {
+	var parts []string
+	if t.Status == models.TriageSnoozed {
+		parts = append(parts, "snoozed until "+t.SnoozedUntil.Local().Format("2006-01-02"))
+	} else if t.Status != "" {
+		parts = append(parts, t.Status)
+	}
+	if t.Assignee != "" {
+		parts = append(parts, "assigned to "+t.Assignee)
+	}
+	if t.Operator != "" {
+		parts = append(parts, "by "+t.Operator)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// decide applies change to the triage of a finding and saves it. Returns false if it
+// could not be saved.
+func (s *triageSession) decide(f appFinding, change func(t *models.FindingTriage)) bool {
+	key := triageKey(f)
+	t, ok := s.triages[key]
+	if !ok {
+		t = models.NewFindingTriage(s.app.Name, f.AuditorType, f.Vulnerability)
+	}
+	change(&t)
+	t.Operator = s.cfg.Operator
+
+	if err := s.db.Save(&t).Error; err != nil {
+		fmt.Printf("Failed to save the triage: %v\n", err)
+		return false
+	}
+	s.triages[key] = t
+	if !slices.Contains(s.decided, key) {
+		s.decided = append(s.decided, key)
+	}
+
+	details := fmt.Sprintf("auditor=%s package=%s finding=%s", t.AuditorType, t.PackageName, t.FindingID)
+	if t.Status != "" {
+		details += " status=" + t.Status
+	}
+	if t.Assignee != "" {
+		details += " assignee=" + t.Assignee
+	}
+	zap.S().Infof("Finding triaged app=%s %s operator=%s", s.app.Name, details, s.cfg.Operator)
+	recordActivity(s.db, s.cfg, models.ActivityFindingTriaged, s.app.Name, details)
+	return true
+}
+
+// ignore adds a finding's CVE, or its package without one, to the app's ignore list
+// and records it as ignored. Returns false if nothing was ignored.
+func (s *triageSession) ignore(f appFinding) bool {
+	entry := f.CVEID
+	if entry == "" {
+		answer, _ := s.prompt(fmt.Sprintf("The finding has no CVE: ignore every finding of %s? (y/N): ", f.PackageName))
+		if answer = strings.ToLower(answer); answer != "y" && answer != "yes" {
+			return false
+		}
+		entry = f.PackageName
+	}
+
+	if !slices.Contains(s.app.IgnoreList, entry) {
+		ignoreList := append(slices.Clone(s.app.IgnoreList), entry)
+		if err := s.db.Model(s.app).Update("ignore_list", ignoreList).Error; err != nil {
+			fmt.Printf("Failed to update the ignore list: %v\n", err)
+			return false
+		}
+		s.app.IgnoreList = ignoreList
+	}
+
+	return s.decide(f, func(t *models.FindingTriage) {
+		t.Status, t.SnoozedUntil = models.TriageIgnored, nil
+	})
+}
+
+// summary returns the decisions made in the session, with the findings of queue left
+// without one
+func (s *triageSession) summary(queue []appFinding) *models.TriageSummary {
+	summary := &models.TriageSummary{AppName: s.app.Name, Operator: s.cfg.Operator, Decisions: []models.FindingTriage{}}
+	for _, key := range s.decided {
+		summary.Decisions = append(summary.Decisions, s.triages[key])
+	}
+	for _, f := range queue {
+		if t, ok := s.triages[triageKey(f)]; !ok || !t.IsTriaged(s.now) {
+			summary.Remaining++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/buildinfo"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/store"
+	"github.com/shadowbane/audit-checks/pkg/updater"
+	"go.uber.org/zap"
+)
+
+const (
+	// updateCheckSettingKey stores the time of the last background update check
+	updateCheckSettingKey = "update_check_last"
+
+	// updateCheckInterval is how often background checks contact GitHub
+	updateCheckInterval = 24 * time.Hour
+
+	// updateCheckTimeout bounds background checks so they never delay a run noticeably
+	updateCheckTimeout = 5 * time.Second
+)
+
+// RunSelfUpdate runs the self-update command
+func RunSelfUpdate(args []string) error {
+	fs := flag.NewFlagSet("self-update", flag.ExitOnError)
+
+	checkOnly := fs.Bool("check", false, "Only check for a new version, do not install")
+	force := fs.Bool("force", false, "Reinstall even if already on the latest version")
+	yes := fs.Bool("yes", false, "Do not prompt for confirmation")
+	yesShort := fs.Bool("y", false, "Do not prompt for confirmation (shorthand)")
+
+	_ = fs.Parse(args)
+
+	// Load config (initializes logger)
+	config.Get()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	u := updater.New(buildinfo.Version())
+
+	fmt.Printf("Current version: %s\n", buildinfo.Version())
+
+	release, err := u.LatestRelease(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check for updates: %w", err)
+	}
+
+	fmt.Printf("Latest version:  %s\n", release.TagName)
+
+	if !u.IsNewer(release) && !*force {
+		if u.IsDevBuild() {
+			fmt.Println("\nThis is a development build; use --force to install the latest release.")
+		} else {
+			fmt.Println("\nYou are running the latest version.")
+		}
+		return nil
+	}
+
+	if *checkOnly {
+		fmt.Printf("\nA new version is available: %s\n", release.HTMLURL)
+		return nil
+	}
+
+	if !*yes && !*yesShort {
+		if !PromptYesNo(fmt.Sprintf("\nInstall %s (%s)?", release.TagName, updater.AssetName()), true) {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	path, err := u.Update(ctx, release)
+	if err != nil {
+		return fmt.Errorf("self-update failed: %w", err)
+	}
+
+	fmt.Printf("\nUpdated %s to %s (checksum verified).\n", path, release.TagName)
+
+	return nil
+}
+
+// printUpdateNotice prints a short notice if a newer release is available.
+// Errors are ignored so that `version` works offline.
+func printUpdateNotice(cfg *config.Config) {
+	if !cfg.UpdateCheckEnabled {
+		return
+	}
+
+	u := updater.New(buildinfo.Version())
+	if u.IsDevBuild() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+
+	release, err := u.CheckForUpdate(ctx)
+	if err != nil || release == nil {
+		return
+	}
+
+	fmt.Printf("\nA new version is available: %s (%s)\n", release.TagName, release.HTMLURL)
+	fmt.Println("Run 'audit-checks self-update' to upgrade.")
+}
+
+// logUpdateNotice logs a notice if a newer release is available.
+// GitHub is contacted at most once per updateCheckInterval; the last check
+// time is stored in the settings table so that cron runs stay quiet.
+func logUpdateNotice(cfg *config.Config, st store.Store) {
+	if !cfg.UpdateCheckEnabled {
+		return
+	}
+
+	u := updater.New(buildinfo.Version())
+	if u.IsDevBuild() {
+		return
+	}
+
+	if value, ok, err := st.Setting(updateCheckSettingKey); err == nil && ok {
+		if last, err := time.Parse(time.RFC3339, value); err == nil && time.Since(last) < updateCheckInterval {
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+
+	release, err := u.CheckForUpdate(ctx)
+	if err != nil {
+		zap.S().Debugf("Update check failed: %v", err)
+		return
+	}
+
+	if err := st.SaveSetting(updateCheckSettingKey, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		zap.S().Debugf("Failed to save update check time: %v", err)
+	}
+
+	if release != nil {
+		zap.S().Infof("A new version of audit-checks is available: %s (running %s). Run 'audit-checks self-update' to upgrade.",
+			release.TagName,
+			buildinfo.Version(),
+		)
+	}
+}
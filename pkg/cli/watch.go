@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/shadowbane/audit-checks/pkg/application"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/store"
+)
+
+// RunWatch runs the watch command: a daemon polling the OSV.dev advisory feed and
+// re-auditing the apps a new advisory affects
+func RunWatch(args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+
+	interval := fs.Duration("interval", 0, "How often to poll the advisory feed (default: ADVISORY_WATCH_INTERVAL or 15m)")
+	once := fs.Bool("once", false, "Poll once and exit")
+	dryRun := fs.Bool("dry-run", false, "Re-audit without sending notifications")
+	verbose := fs.Bool("verbose", false, "Enable verbose logging")
+	fs.BoolVar(verbose, "v", false, "Enable verbose logging (shorthand)")
+
+	_ = fs.Parse(args)
+
+	if *verbose {
+		_ = os.Setenv("LOG_LEVEL", "debug")
+	}
+
+	// Load config (initializes logger)
+	cfg := config.Get()
+	cfg.DryRun = *dryRun
+	cfg.Verbose = *verbose
+
+	if *interval <= 0 {
+		*interval = cfg.Settings.AdvisoryWatchInterval
+	}
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	st, err := store.OpenSQLite(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer st.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	application.CheckTelegramPermissions(cfg)
+	watcher := application.NewAdvisoryWatcher(cfg, st)
+
+	if *once {
+		reaudited, err := watcher.Poll(ctx)
+		fmt.Printf("Re-audited %d app(s)\n", len(reaudited))
+		return err
+	}
+
+	return watcher.Watch(ctx, *interval)
+}
@@ -0,0 +1,304 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/analyzer"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// wizardTestTimeout bounds each test request made by the setup wizard
+const wizardTestTimeout = 30 * time.Second
+
+// configureInteractive walks through Telegram, Discord, email and Gemini configuration,
+// test-fires each configured integration, and writes the result to envPath
+func configureInteractive(cfg *config.Config, envPath string) error {
+	var values []envValue
+
+	fmt.Println()
+	fmt.Println("=== Notifications & AI ===")
+
+	if PromptYesNo("\nConfigure Telegram notifications?", cfg.TelegramEnabled) {
+		values = append(values, configureTelegram(cfg)...)
+	}
+
+	if PromptYesNo("\nConfigure Discord notifications?", cfg.DiscordEnabled) {
+		values = append(values, configureDiscord(cfg)...)
+	}
+
+	if PromptYesNo("\nConfigure email notifications (Resend)?", cfg.ResendAPIKey != "") {
+		values = append(values, configureEmail(cfg)...)
+	}
+
+	if PromptYesNo("\nConfigure AI analysis (Gemini)?", cfg.GeminiEnabled) {
+		values = append(values, configureGemini(cfg)...)
+	}
+
+	if len(values) == 0 {
+		fmt.Println("\nNo changes to write.")
+		return nil
+	}
+
+	fmt.Printf("\nThe following settings will be written to %s:\n", envPath)
+	for _, v := range values {
+		fmt.Printf("  %s=%s\n", v.Key, maskSecret(v.Key, v.Value))
+	}
+
+	if !PromptYesNo("Write these settings?", true) {
+		fmt.Println("Settings not saved.")
+		return nil
+	}
+
+	if err := writeEnvFile(envPath, values); err != nil {
+		return err
+	}
+
+	fmt.Printf("Settings saved to %s.\n", envPath)
+	return nil
+}
+
+// configureTelegram prompts for Telegram settings and verifies the bot can manage forum topics
+func configureTelegram(cfg *config.Config) []envValue {
+	fmt.Println("Create a bot via @BotFather, add it to a forum group as admin with 'Manage Topics'.")
+
+	token := promptSecret("Bot token", cfg.TelegramBotToken)
+	if token == "" || !strings.Contains(token, ":") {
+		fmt.Println("Invalid bot token (expected <id>:<secret>); skipping Telegram.")
+		return nil
+	}
+
+	defaultGroup := ""
+	if cfg.TelegramGroupID != 0 {
+		defaultGroup = strconv.FormatInt(cfg.TelegramGroupID, 10)
+	}
+	groupID, err := strconv.ParseInt(PromptWithDefault("Group ID (e.g. -1001234567890)", defaultGroup), 10, 64)
+	if err != nil || groupID == 0 {
+		fmt.Println("Invalid group ID; skipping Telegram.")
+		return nil
+	}
+
+	fmt.Println("Checking bot and group...")
+	check, err := notifier.CheckTelegram(token, groupID)
+	if check != nil && check.BotUsername != "" {
+		fmt.Printf("  Bot:    @%s\n", check.BotUsername)
+	}
+	if check != nil && check.ChatTitle != "" {
+		fmt.Printf("  Group:  %s (%s)\n", check.ChatTitle, check.ChatType)
+		fmt.Printf("  Forum:  %s\n", yesNo(check.IsForum))
+	}
+	if check != nil && check.MemberStatus != "" {
+		fmt.Printf("  Bot is: %s, can manage topics: %s, send messages: %s, send files: %s\n", check.MemberStatus,
+			yesNo(check.CanManageTopics || check.MemberStatus == "creator"), yesNo(check.CanSendMessages), yesNo(check.CanSendDocuments))
+	}
+
+	switch {
+	case err != nil:
+		fmt.Printf("Telegram check failed: %v\n", err)
+		if !PromptYesNo("Save Telegram settings anyway?", false) {
+			return nil
+		}
+	case !check.Ready():
+		for _, problem := range check.Problems() {
+			fmt.Printf("Warning: %s. %s.\n", problem.Message, problem.Hint)
+		}
+	default:
+		fmt.Println("Telegram looks good.")
+	}
+
+	if err == nil && PromptYesNo("Send a test message to the group?", true) {
+		tg, err := notifier.NewTelegramNotifier(token, groupID, true)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), wizardTestTimeout)
+			err = tg.SendTestMessage(ctx, "Audit Checks: Telegram notifications are configured.")
+			cancel()
+		}
+		if err != nil {
+			fmt.Printf("Test message failed: %v\n", err)
+		} else {
+			fmt.Println("Test message sent.")
+		}
+	}
+
+	return []envValue{
+		{Key: "TELEGRAM_BOT_TOKEN", Value: token},
+		{Key: "TELEGRAM_GROUP_ID", Value: strconv.FormatInt(groupID, 10)},
+		{Key: "TELEGRAM_ENABLED", Value: "true"},
+	}
+}
+
+// configureDiscord prompts for a Discord bot or webhook and optionally sends a test message
+func configureDiscord(cfg *config.Config) []envValue {
+	fmt.Println("A bot posts one thread per app (or to an app's own channel); a webhook posts to its channel,")
+	fmt.Println("one post per app in forum channels.")
+
+	var token, channelID, webhookURL string
+	if PromptYesNo("Use a bot (recommended)?", cfg.DiscordBotToken != "" || cfg.DiscordWebhookURL == "") {
+		fmt.Println("Create a bot at https://discord.com/developers/applications and invite it with the")
+		fmt.Println("'Send Messages', 'Create Public Threads', 'Send Messages in Threads' and 'Attach Files' permissions.")
+		token = promptSecret("Bot token", cfg.DiscordBotToken)
+		channelID = PromptWithDefault("Default channel ID (e.g. 123456789012345678)", cfg.DiscordChannelID)
+		if token == "" || channelID == "" {
+			fmt.Println("Bot token and channel ID are required; skipping Discord.")
+			return nil
+		}
+	} else {
+		webhookURL = promptSecret("Webhook URL", cfg.DiscordWebhookURL)
+		if !strings.HasPrefix(webhookURL, "https://") {
+			fmt.Println("Invalid webhook URL; skipping Discord.")
+			return nil
+		}
+	}
+
+	if PromptYesNo("Send a test message?", true) {
+		discord := notifier.NewDiscordNotifier(token, channelID, webhookURL, true)
+		ctx, cancel := context.WithTimeout(context.Background(), wizardTestTimeout)
+		err := discord.SendTestMessage(ctx, "Audit Checks: Discord notifications are configured.")
+		cancel()
+		if err != nil {
+			fmt.Printf("Test message failed: %v\n", err)
+			if !PromptYesNo("Save Discord settings anyway?", false) {
+				return nil
+			}
+		} else {
+			fmt.Println("Test message sent.")
+		}
+	}
+
+	return []envValue{
+		{Key: "DISCORD_BOT_TOKEN", Value: token},
+		{Key: "DISCORD_CHANNEL_ID", Value: channelID},
+		{Key: "DISCORD_WEBHOOK_URL", Value: webhookURL},
+		{Key: "DISCORD_ENABLED", Value: "true"},
+	}
+}
+
+// configureEmail prompts for Resend settings and optionally sends a test email
+func configureEmail(cfg *config.Config) []envValue {
+	fmt.Println("Get an API key from https://resend.com (the sender domain must be verified there).")
+
+	apiKey := promptSecret("Resend API key", cfg.ResendAPIKey)
+	if apiKey == "" {
+		fmt.Println("API key is required; skipping email.")
+		return nil
+	}
+	if !strings.HasPrefix(apiKey, "re_") {
+		fmt.Println("Warning: Resend API keys usually start with 're_'.")
+	}
+
+	from := PromptWithDefault("From address", cfg.ResendFromEmail)
+	if _, err := mail.ParseAddress(from); err != nil {
+		fmt.Printf("Invalid from address %q; skipping email.\n", from)
+		return nil
+	}
+
+	values := []envValue{
+		{Key: "RESEND_API_KEY", Value: apiKey},
+		{Key: "RESEND_FROM_EMAIL", Value: from},
+	}
+
+	if !PromptYesNo("Send a test email?", true) {
+		return values
+	}
+
+	to := PromptWithDefault("Send test email to", "")
+	if _, err := mail.ParseAddress(to); err != nil {
+		fmt.Printf("Invalid address %q; test email not sent.\n", to)
+		return values
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), wizardTestTimeout)
+	defer cancel()
+
+	if err := notifier.NewEmailNotifier(apiKey, from).SendTestEmail(ctx, []string{to}); err != nil {
+		fmt.Printf("Test email failed: %v\n", err)
+		if !PromptYesNo("Save email settings anyway?", false) {
+			return nil
+		}
+	} else {
+		fmt.Printf("Test email sent to %s.\n", to)
+	}
+
+	return values
+}
+
+// configureGemini prompts for Gemini settings and verifies the key and model
+func configureGemini(cfg *config.Config) []envValue {
+	fmt.Println("Get an API key from https://aistudio.google.com/app/apikey")
+
+	apiKey := promptSecret("Gemini API key", cfg.GeminiAPIKey)
+	if apiKey == "" {
+		fmt.Println("API key is required; skipping Gemini.")
+		return nil
+	}
+
+	model := PromptWithDefault("Model", cfg.GeminiModel)
+
+	fmt.Println("Checking Gemini...")
+	ctx, cancel := context.WithTimeout(context.Background(), wizardTestTimeout)
+	defer cancel()
+
+	g, err := analyzer.NewGeminiAnalyzer(ctx, apiKey, model, true)
+	if err == nil {
+		err = g.Ping(ctx)
+		g.Close()
+	}
+	if err != nil {
+		fmt.Printf("Gemini check failed: %v\n", err)
+		if !PromptYesNo("Save Gemini settings anyway?", false) {
+			return nil
+		}
+	} else {
+		fmt.Println("Gemini looks good.")
+	}
+
+	return []envValue{
+		{Key: "GEMINI_API_KEY", Value: apiKey},
+		{Key: "GEMINI_MODEL", Value: model},
+		{Key: "GEMINI_ENABLED", Value: "true"},
+	}
+}
+
+// promptSecret asks for a secret, showing only a masked version of the current value
+func promptSecret(message, current string) string {
+	if current != "" {
+		message = fmt.Sprintf("%s [%s]: ", message, maskValue(current))
+	} else {
+		message = message + ": "
+	}
+
+	input := Prompt(message)
+	if input == "" {
+		return current
+	}
+	return input
+}
+
+// maskSecret masks values of keys that hold credentials
+func maskSecret(key, value string) string {
+	if strings.HasSuffix(key, "_TOKEN") || strings.HasSuffix(key, "_KEY") || strings.HasSuffix(key, "_WEBHOOK_URL") {
+		return maskValue(value)
+	}
+	return value
+}
+
+// maskValue keeps only the last 4 characters of a secret
+func maskValue(value string) string {
+	if len(value) <= 8 {
+		return strings.Repeat("*", len(value))
+	}
+	return strings.Repeat("*", 8) + value[len(value)-4:]
+}
+
+// yesNo formats a bool for display
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
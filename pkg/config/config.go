@@ -2,20 +2,34 @@ package config
 
 import (
 	"os"
+	"os/user"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/shadowbane/audit-checks/pkg/models"
 	"github.com/shadowbane/go-logger"
 	"github.com/spf13/viper"
 )
 
+// Job queues (JOB_QUEUE)
+const (
+	JobQueueOff      = "off"
+	JobQueueEmbedded = "embedded"
+	JobQueueRedis    = "redis"
+)
+
 // Config holds all application configuration (from environment variables only)
 type Config struct {
 	// Environment variables
 	AppEnv           string
 	LogLevel         string
 	LogDirectory     string
+	LogMaxAge        int  // days log files are kept
+	RunLogEnabled    bool // write each run's log lines to <LogDirectory>/runs/<run_id>.log as well
+	RawOutputMaxSize int  // KB of an auditor's raw output stored with its result, the rest in <LogDirectory>/raw; 0 for no limit
 	DBSQLitePath     string
 	DBLogLevel       string
 	ResendAPIKey     string
@@ -24,8 +38,132 @@ type Config struct {
 	TelegramGroupID  int64
 	TelegramEnabled  bool
 	GeminiAPIKey     string
-	GeminiEnabled    bool
-	GeminiModel      string
+
+	// Email is sent through Resend, or an SMTP server when EmailProvider is smtp
+	EmailProvider string // resend or smtp
+	SMTPHost      string
+	SMTPPort      int
+	SMTPUsername  string
+	SMTPPassword  string
+	SMTPTLS       string // starttls, tls or none
+	SMTPFromEmail string
+
+	// Signing secret of the Resend webhook reporting the delivery status of the emails sent;
+	// when set, the emails sent through Resend are recorded to track it
+	ResendWebhookSecret string
+
+	// Discord notifications through a bot (token and default channel) or a webhook
+	DiscordEnabled    bool
+	DiscordBotToken   string
+	DiscordChannelID  string
+	DiscordWebhookURL string
+
+	// Mattermost notifications through a bot (server URL, access token and default channel) or an incoming webhook
+	MattermostEnabled    bool
+	MattermostURL        string
+	MattermostBotToken   string
+	MattermostChannelID  string
+	MattermostWebhookURL string
+
+	// Outbound webhooks receiving reports as JSON: WebhookURLs get every app's, apps can add their own
+	WebhookEnabled bool
+	WebhookURLs    []string
+	WebhookSecret  string // signs the requests (X-Audit-Signature-256) when set
+
+	// PagerDuty incidents for critical findings, through an Events API v2 integration
+	PagerDutyEnabled    bool
+	PagerDutyRoutingKey string
+
+	// Opsgenie alerts per app and auditor, through the Alert API
+	OpsgenieEnabled    bool
+	OpsgenieAPIKey     string
+	OpsgenieAPIURL     string            // https://api.eu.opsgenie.com for EU accounts
+	OpsgeniePriorities map[string]string // severity -> P1..P5
+
+	// ntfy push notifications, to a topic per app on ntfy.sh or a self-hosted server
+	NtfyEnabled     bool
+	NtfyServerURL   string
+	NtfyToken       string         // access token for protected topics
+	NtfyTopicPrefix string         // apps without their own topic publish to <prefix>-<app>
+	NtfyPriorities  map[string]int // severity -> 1..5
+	NtfyReportURL   string         // where REPORT_OUTPUT_DIR is served, for buttons opening the reports
+
+	// Gotify push notifications, to a self-hosted server
+	GotifyEnabled    bool
+	GotifyURL        string
+	GotifyToken      string         // application token the messages are pushed with
+	GotifyPriorities map[string]int // severity -> 0..10
+
+	// Desktop notifications of every app on the machine running the audit: macOS
+	// notification center, Windows Event Log or notify-send
+	DesktopEnabled     bool
+	DesktopEventSource string // source of the Windows Event Log entries
+
+	// Zulip notifications through a bot, to a stream with a topic per app
+	ZulipEnabled  bool
+	ZulipURL      string
+	ZulipBotEmail string
+	ZulipAPIKey   string
+	ZulipStream   string
+
+	// GitLab issues, one per app with vulnerabilities, in a project on gitlab.com or a self-managed instance
+	GitLabEnabled bool
+	GitLabURL     string
+	GitLabToken   string   // personal, project or group access token with the api scope
+	GitLabProject string   // ID or path of the project, for apps without their own
+	GitLabLabels  []string // labels of the issues, next to severity::<highest>
+
+	// DefectDojo, which every audit result is imported into, in a product per app
+	DefectDojoEnabled     bool
+	DefectDojoURL         string
+	DefectDojoAPIKey      string // API v2 key of a user allowed to import scans and create products
+	DefectDojoProductType string // of the products created for the apps
+	DefectDojoEngagement  string // of each app's product the results are imported into
+
+	// Dependency-Track, which each app's CycloneDX SBOM is uploaded to after its audit
+	DependencyTrackEnabled        bool
+	DependencyTrackURL            string
+	DependencyTrackAPIKey         string   // of a team with the BOM_UPLOAD and PROJECT_CREATION_UPLOAD permissions
+	DependencyTrackProjectVersion string   // of apps whose manifest has no version
+	DependencyTrackTags           []string // of the projects
+
+	// Confluence, where a page per app shows its latest report after each audit
+	ConfluenceEnabled      bool
+	ConfluenceURL          string // of the wiki, e.g. https://example.atlassian.net/wiki
+	ConfluenceUser         string // email of the API token's account on Cloud; empty for a personal access token
+	ConfluenceAPIToken     string
+	ConfluenceSpace        string // key of the space the pages are in
+	ConfluenceParentPageID string // of the page the app pages are created under, at the space's root when empty
+	ConfluenceTitle        string // of the app pages, with {app} replaced by the app's name
+
+	// AIAnalyzers are the AI analyzers tried in order, the next one taking over when one fails
+	AIAnalyzers []string
+
+	GeminiEnabled       bool
+	GeminiModel         string
+	GeminiFleetAnalysis bool // also analyze the findings of all apps together, for the summary and executive reports
+	GeminiMaxAdvisories int  // listed in the prompt of an audit result, the others are only counted; 0 for all
+
+	// AICacheTTL is how long the AI analysis of a result is reused by later audits with
+	// the same findings; 0 disables the cache
+	AICacheTTL time.Duration
+
+	// UpdateCheckEnabled enables the new-version notice in `version` and `run`
+	UpdateCheckEnabled bool
+
+	// Operator is who runs the command, recorded in the activity log
+	// (AUDIT_OPERATOR or --operator, then the sudo user, then the OS user)
+	Operator string
+
+	// API server (`serve` command)
+	APIListen string
+	APIToken  string
+
+	// Job queue of `serve`: off, embedded (jobs in the database) or redis
+	JobQueue            string
+	RedisURL            string
+	JobWorkers          int
+	JobScheduleInterval time.Duration // how often every app due is audited; 0 for never
 
 	// Settings (from env vars with defaults)
 	Settings Settings
@@ -36,6 +174,20 @@ type Config struct {
 	Verbose    bool
 	ReportOnly bool
 	JSONOutput bool
+	CanarySize int  // audit a random sample of this many apps (run --canary)
+	NoAICache  bool // analyze every result again, refreshing the cached analyses (run --no-ai-cache)
+
+	// GitLabReportPath is where the run writes a GitLab Dependency Scanning report of its
+	// results (run --gitlab-report)
+	GitLabReportPath string
+
+	// RunReason explains why a run was started other than by hand, e.g. the advisories
+	// that triggered a re-audit by `watch`; it is recorded in the activity log
+	RunReason string
+
+	// RunSource is where the run was started from, recorded in the activity log: cli
+	// (default), or the source of the audit job running it, api or schedule
+	RunSource string
 
 	// Apps loaded from database (populated by application)
 	Apps []models.AppConfig
@@ -43,11 +195,101 @@ type Config struct {
 
 // Settings holds the settings (from env vars with defaults)
 type Settings struct {
-	SeverityThreshold string
-	ReportFormats     []string
-	ReportOutputDir   string
-	MaxConcurrent     int
-	RetryAttempts     int
+	SeverityThreshold    string
+	IssueOrder           string // severity, age or risk: order of the findings in reports and notifications
+	ReportFormats        []string
+	CombinedFormats      []string // json, markdown, html: one file per app attached to notifications instead of the reports of each auditor
+	ReportOutputDir      string
+	ReportFilename       string // template of the report file names, e.g. {app}-{auditor}-{date}
+	ReportTimestamp      string // Go layout of {date} in report file names
+	MaxConcurrent        int
+	OwnerWeights         map[string]int // owner -> apps started per turn when owners share MAX_CONCURRENT
+	RetryAttempts        int
+	MinToolVersions      map[string]string // auditor name -> minimum tool version
+	JavaAuditBackend     string            // osv-scanner or dependency-check
+	SystemAuditBackend   string            // auto, dnf, debsecan or apt
+	AdaptiveSchedule     bool              // only audit apps whose interval has elapsed
+	StrictMode           bool              // fail auditors whose result fails a sanity check
+	PolicyFile           string            // pass/fail policies replacing the severity threshold for the exit code and status page
+	ScheduleMinInterval  time.Duration     // apps with criticals or changed dependencies
+	ScheduleBaseInterval time.Duration     // apps with no critical/high findings
+	ScheduleMaxInterval  time.Duration     // cap for apps with consecutive clean runs
+
+	// Executive report: generated weekly by run, emailed as a digest
+	ExecutiveReportEnabled bool
+	ExecutiveReportEmails  []string
+	SLADays                map[string]int // severity -> days to remediate
+
+	// Scorecards: per-app files for service catalogs, refreshed by run when enabled
+	ScorecardsEnabled bool
+	ScorecardFormats  []string // yaml, json
+
+	// Atom feed of new findings, rewritten by run when enabled and served by serve
+	FeedEnabled bool
+	FeedPath    string // default <REPORT_OUTPUT_DIR>/feed.atom
+	FeedTitle   string
+	FeedURL     string // where the feed is published, for its self link
+	FeedDays    int    // days of new findings in the feed
+	FeedToken   string // accepted as ?token= by serve's feed endpoint, for feed readers
+
+	// History retention: days of audit results, reports and logs kept per app, 0 keeps
+	// them forever; apps may have their own (app edit --retention-days)
+	RetentionDays int
+
+	// Status page: sanitized wallboard of the apps, refreshed by run when enabled
+	StatusPageEnabled   bool
+	StatusPageDir       string // empty for <REPORT_OUTPUT_DIR>/status
+	StatusPageTitle     string
+	StatusPageStaleDays int // days after which an app's last audit turns it yellow
+
+	// OSV lockfile auditor: local cache of OSV.dev responses
+	OSVCacheDir string
+	OSVCacheTTL time.Duration
+
+	// Developer check (`check`): results cached by a hash of the app's dependency files
+	CheckCacheDir string // empty for <user cache dir>/audit-checks/check
+	CheckCacheTTL time.Duration
+
+	// AdvisoryWatchInterval is how often `watch` polls the OSV.dev advisory feed
+	AdvisoryWatchInterval time.Duration
+
+	// Notifier drill: a labeled test message on every channel, sent by `watch` every interval
+	NotifierDrillEnabled  bool
+	NotifierDrillInterval time.Duration
+	NotifierDrillChannels []string // channels to test (default: all)
+	NotifierDrillEmails   []string // test email recipients (default: every app's recipients)
+
+	// PinningPolicyEnabled auto-detects the policy auditor for npm and Composer apps
+	PinningPolicyEnabled bool
+
+	// Outdated auditor: auto-detected for npm and Composer apps when enabled
+	OutdatedAuditEnabled  bool
+	OutdatedAuditSeverity string // severity of dependencies a major version or more behind
+
+	// SupplyChainAuditEnabled auto-detects the supply-chain heuristics (typosquats, new install scripts) for npm apps
+	SupplyChainAuditEnabled bool
+
+	// SecretsAuditEnabled auto-detects the secret scan (committed credentials) for apps that are git repositories
+	SecretsAuditEnabled bool
+
+	// PHPEOLAuditEnabled auto-detects the PHP runtime checks (EOL version, insecure extensions) for Composer apps
+	PHPEOLAuditEnabled bool
+
+	// NodeEOLAuditEnabled auto-detects the Node.js runtime checks (EOL, missed security releases) for npm apps
+	NodeEOLAuditEnabled bool
+
+	// Audit workspace: auditors run against a snapshot of the app path
+	AuditWorkspace        string   // off, copy or bind
+	AuditWorkspaceDir     string   // where snapshots are created (system temp directory when empty)
+	AuditWorkspaceExclude []string // directory names not copied in copy mode
+
+	// NPMAuditOptions are passed to npm audit as flags (audit-level, omit, registry, before);
+	// signatures adds an `npm audit signatures` pass
+	NPMAuditOptions map[string]string
+
+	// ComposerAuditOptions are passed to composer audit as flags (locked, abandoned, ignore-severity);
+	// abandoned-severity is the severity abandoned packages are reported with
+	ComposerAuditOptions map[string]string
 }
 
 // Get loads configuration from environment variables
@@ -104,38 +346,294 @@ func (c *Config) loadEnvVars() {
 	viper.SetDefault("APP_ENV", "production")
 	viper.SetDefault("LOG_LEVEL", "info")
 	viper.SetDefault("LOG_DIRECTORY", "./storage/logs")
+	viper.SetDefault("LOG_MAX_AGE", 30)
+	viper.SetDefault("RUN_LOG_ENABLED", false)
+	viper.SetDefault("RAW_OUTPUT_MAX_SIZE", 512)
 	viper.SetDefault("DB_SQLITE_PATH", "./storage/audit.db")
 	viper.SetDefault("DB_LOG_LEVEL", "warn")
+	viper.SetDefault("EMAIL_PROVIDER", "resend")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("SMTP_TLS", "starttls")
 	viper.SetDefault("TELEGRAM_ENABLED", false)
 	viper.SetDefault("TELEGRAM_GROUP_ID", 0)
+	viper.SetDefault("DISCORD_ENABLED", false)
+	viper.SetDefault("MATTERMOST_ENABLED", false)
+	viper.SetDefault("WEBHOOK_ENABLED", false)
+	viper.SetDefault("PAGERDUTY_ENABLED", false)
+	viper.SetDefault("OPSGENIE_ENABLED", false)
+	viper.SetDefault("OPSGENIE_API_URL", "https://api.opsgenie.com")
+	viper.SetDefault("OPSGENIE_PRIORITIES", "critical=P1,high=P2,moderate=P3,low=P4,info=P5")
+	viper.SetDefault("NTFY_ENABLED", false)
+	viper.SetDefault("NTFY_SERVER_URL", "https://ntfy.sh")
+	viper.SetDefault("NTFY_TOPIC_PREFIX", "audit-checks")
+	viper.SetDefault("NTFY_PRIORITIES", "critical=5,high=4,moderate=3,low=2,info=1")
+	viper.SetDefault("GOTIFY_ENABLED", false)
+	viper.SetDefault("GOTIFY_PRIORITIES", "critical=10,high=8,moderate=5,low=3,info=1")
+	viper.SetDefault("DESKTOP_ENABLED", false)
+	viper.SetDefault("DESKTOP_EVENT_SOURCE", "audit-checks")
+	viper.SetDefault("ZULIP_ENABLED", false)
+	viper.SetDefault("ZULIP_STREAM", "security")
+	viper.SetDefault("GITLAB_ENABLED", false)
+	viper.SetDefault("GITLAB_URL", "https://gitlab.com")
+	viper.SetDefault("GITLAB_LABELS", "security")
+	viper.SetDefault("DEFECTDOJO_ENABLED", false)
+	viper.SetDefault("DEFECTDOJO_PRODUCT_TYPE", "audit-checks")
+	viper.SetDefault("DEFECTDOJO_ENGAGEMENT", "audit-checks")
+	viper.SetDefault("DEPENDENCYTRACK_ENABLED", false)
+	viper.SetDefault("DEPENDENCYTRACK_PROJECT_VERSION", "latest")
+	viper.SetDefault("DEPENDENCYTRACK_TAGS", "audit-checks")
+	viper.SetDefault("CONFLUENCE_ENABLED", false)
+	viper.SetDefault("CONFLUENCE_TITLE", "Security audit: {app}")
+	viper.SetDefault("AI_ANALYZERS", "gemini")
 	viper.SetDefault("GEMINI_ENABLED", false)
 	viper.SetDefault("GEMINI_MODEL", "gemini-2.5-flash")
+	viper.SetDefault("GEMINI_FLEET_ANALYSIS", false)
+	viper.SetDefault("GEMINI_MAX_ADVISORIES", 50)
+	viper.SetDefault("AI_CACHE_TTL", "168h")
+	viper.SetDefault("JOB_QUEUE", JobQueueOff)
+	viper.SetDefault("REDIS_URL", "redis://127.0.0.1:6379/0")
+	viper.SetDefault("JOB_WORKERS", 1)
+	viper.SetDefault("JOB_SCHEDULE_INTERVAL", "0")
 	viper.SetDefault("SEVERITY_THRESHOLD", models.SeverityModerate)
+	viper.SetDefault("ISSUE_ORDER", models.IssueOrderSeverity)
 	viper.SetDefault("REPORT_OUTPUT_DIR", "./storage/reports")
+	viper.SetDefault("REPORT_FILENAME_TEMPLATE", "{app}-{auditor}-{date}")
+	viper.SetDefault("REPORT_TIMESTAMP_FORMAT", "2006-01-02-150405")
 	viper.SetDefault("MAX_CONCURRENT", 3)
+	viper.SetDefault("OWNER_WEIGHTS", "")
 	viper.SetDefault("RETRY_ATTEMPTS", 3)
 	viper.SetDefault("REPORT_FORMATS", "json,markdown")
+	viper.SetDefault("UPDATE_CHECK_ENABLED", true)
+	viper.SetDefault("API_LISTEN", "127.0.0.1:8080")
+	viper.SetDefault("JAVA_AUDIT_BACKEND", "osv-scanner")
+	viper.SetDefault("SYSTEM_AUDIT_BACKEND", "auto")
+	viper.SetDefault("ADAPTIVE_SCHEDULE", false)
+	viper.SetDefault("STRICT_MODE", false)
+	viper.SetDefault("SCHEDULE_MIN_INTERVAL", "6h")
+	viper.SetDefault("SCHEDULE_BASE_INTERVAL", "24h")
+	viper.SetDefault("SCHEDULE_MAX_INTERVAL", "168h")
+	viper.SetDefault("EXECUTIVE_REPORT_ENABLED", false)
+	viper.SetDefault("SLA_DAYS", "critical=7,high=30,moderate=90,low=180")
+	viper.SetDefault("SCORECARDS_ENABLED", false)
+	viper.SetDefault("SCORECARD_FORMATS", "yaml")
+	viper.SetDefault("RETENTION_DAYS", 0)
+	viper.SetDefault("FEED_ENABLED", false)
+	viper.SetDefault("FEED_PATH", "")
+	viper.SetDefault("FEED_TITLE", "Security findings")
+	viper.SetDefault("FEED_URL", "")
+	viper.SetDefault("FEED_DAYS", 14)
+	viper.SetDefault("FEED_TOKEN", "")
+	viper.SetDefault("STATUS_PAGE_ENABLED", false)
+	viper.SetDefault("STATUS_PAGE_DIR", "")
+	viper.SetDefault("STATUS_PAGE_TITLE", "Security Status")
+	viper.SetDefault("STATUS_PAGE_STALE_DAYS", 7)
+	viper.SetDefault("OSV_CACHE_DIR", "./storage/cache/osv")
+	viper.SetDefault("OSV_CACHE_TTL", "24h")
+	viper.SetDefault("CHECK_CACHE_DIR", "")
+	viper.SetDefault("CHECK_CACHE_TTL", "24h")
+	viper.SetDefault("ADVISORY_WATCH_INTERVAL", "15m")
+	viper.SetDefault("NOTIFIER_DRILL_ENABLED", false)
+	viper.SetDefault("NOTIFIER_DRILL_INTERVAL", "720h")
+	viper.SetDefault("NOTIFIER_DRILL_CHANNELS", "email,telegram,discord,mattermost,webhook,ntfy,gotify,zulip,opsgenie,pagerduty,desktop")
+	viper.SetDefault("PINNING_POLICY_ENABLED", true)
+	viper.SetDefault("OUTDATED_AUDIT_ENABLED", false)
+	viper.SetDefault("OUTDATED_AUDIT_SEVERITY", models.SeverityInfo)
+	viper.SetDefault("SUPPLY_CHAIN_AUDIT_ENABLED", false)
+	viper.SetDefault("SECRETS_AUDIT_ENABLED", false)
+	viper.SetDefault("PHP_EOL_AUDIT_ENABLED", true)
+	viper.SetDefault("NODE_EOL_AUDIT_ENABLED", true)
+	viper.SetDefault("AUDIT_WORKSPACE", "off")
+	viper.SetDefault("AUDIT_WORKSPACE_EXCLUDE", "node_modules,.git")
 
 	// Load from Viper (OS env > .env > defaults)
 	c.AppEnv = viper.GetString("APP_ENV")
 	c.LogLevel = viper.GetString("LOG_LEVEL")
 	c.LogDirectory = viper.GetString("LOG_DIRECTORY")
+	c.LogMaxAge = viper.GetInt("LOG_MAX_AGE")
+	c.RunLogEnabled = viper.GetBool("RUN_LOG_ENABLED")
+	c.RawOutputMaxSize = viper.GetInt("RAW_OUTPUT_MAX_SIZE")
 	c.DBSQLitePath = viper.GetString("DB_SQLITE_PATH")
 	c.DBLogLevel = viper.GetString("DB_LOG_LEVEL")
 	c.ResendAPIKey = viper.GetString("RESEND_API_KEY")
 	c.ResendFromEmail = viper.GetString("RESEND_FROM_EMAIL")
+	c.ResendWebhookSecret = viper.GetString("RESEND_WEBHOOK_SECRET")
+	c.EmailProvider = strings.ToLower(strings.TrimSpace(viper.GetString("EMAIL_PROVIDER")))
+	c.SMTPHost = viper.GetString("SMTP_HOST")
+	c.SMTPPort = viper.GetInt("SMTP_PORT")
+	c.SMTPUsername = viper.GetString("SMTP_USERNAME")
+	c.SMTPPassword = viper.GetString("SMTP_PASSWORD")
+	c.SMTPTLS = strings.ToLower(strings.TrimSpace(viper.GetString("SMTP_TLS")))
+	c.SMTPFromEmail = viper.GetString("SMTP_FROM_EMAIL")
 	c.TelegramBotToken = viper.GetString("TELEGRAM_BOT_TOKEN")
 	c.TelegramGroupID = viper.GetInt64("TELEGRAM_GROUP_ID")
 	c.TelegramEnabled = viper.GetBool("TELEGRAM_ENABLED")
+	c.DiscordEnabled = viper.GetBool("DISCORD_ENABLED")
+	c.DiscordBotToken = viper.GetString("DISCORD_BOT_TOKEN")
+	c.DiscordChannelID = viper.GetString("DISCORD_CHANNEL_ID")
+	c.DiscordWebhookURL = viper.GetString("DISCORD_WEBHOOK_URL")
+	c.MattermostEnabled = viper.GetBool("MATTERMOST_ENABLED")
+	c.MattermostURL = viper.GetString("MATTERMOST_URL")
+	c.MattermostBotToken = viper.GetString("MATTERMOST_BOT_TOKEN")
+	c.MattermostChannelID = viper.GetString("MATTERMOST_CHANNEL_ID")
+	c.MattermostWebhookURL = viper.GetString("MATTERMOST_WEBHOOK_URL")
+	c.WebhookEnabled = viper.GetBool("WEBHOOK_ENABLED")
+	for _, url := range strings.Split(viper.GetString("WEBHOOK_URLS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			c.WebhookURLs = append(c.WebhookURLs, url)
+		}
+	}
+	c.WebhookSecret = viper.GetString("WEBHOOK_SECRET")
+	c.PagerDutyEnabled = viper.GetBool("PAGERDUTY_ENABLED")
+	c.PagerDutyRoutingKey = viper.GetString("PAGERDUTY_ROUTING_KEY")
+	c.OpsgenieEnabled = viper.GetBool("OPSGENIE_ENABLED")
+	c.OpsgenieAPIKey = viper.GetString("OPSGENIE_API_KEY")
+	c.OpsgenieAPIURL = viper.GetString("OPSGENIE_API_URL")
+	// Parse priorities per severity (e.g., "critical=P1,high=P2"); invalid values are ignored
+	c.OpsgeniePriorities = make(map[string]string)
+	for severity, priority := range parseKeyValueList(viper.GetString("OPSGENIE_PRIORITIES")) {
+		if priority = strings.ToUpper(priority); len(priority) == 2 && priority[0] == 'P' && priority[1] >= '1' && priority[1] <= '5' {
+			c.OpsgeniePriorities[strings.ToLower(severity)] = priority
+		}
+	}
+	c.NtfyEnabled = viper.GetBool("NTFY_ENABLED")
+	c.NtfyServerURL = viper.GetString("NTFY_SERVER_URL")
+	c.NtfyToken = viper.GetString("NTFY_TOKEN")
+	c.NtfyTopicPrefix = viper.GetString("NTFY_TOPIC_PREFIX")
+	c.NtfyReportURL = viper.GetString("NTFY_REPORT_URL")
+	// Parse priorities per severity (e.g., "critical=5,high=4"); invalid values are ignored
+	c.NtfyPriorities = make(map[string]int)
+	for severity, priority := range parseKeyValueList(viper.GetString("NTFY_PRIORITIES")) {
+		if p, err := strconv.Atoi(priority); err == nil && p >= 1 && p <= 5 {
+			c.NtfyPriorities[strings.ToLower(severity)] = p
+		}
+	}
+	c.GotifyEnabled = viper.GetBool("GOTIFY_ENABLED")
+	c.GotifyURL = viper.GetString("GOTIFY_URL")
+	c.GotifyToken = viper.GetString("GOTIFY_TOKEN")
+	// Parse priorities per severity (e.g., "critical=10,high=8"); invalid values are ignored
+	c.GotifyPriorities = make(map[string]int)
+	for severity, priority := range parseKeyValueList(viper.GetString("GOTIFY_PRIORITIES")) {
+		if p, err := strconv.Atoi(priority); err == nil && p >= 0 && p <= 10 {
+			c.GotifyPriorities[strings.ToLower(severity)] = p
+		}
+	}
+	c.DesktopEnabled = viper.GetBool("DESKTOP_ENABLED")
+	c.DesktopEventSource = viper.GetString("DESKTOP_EVENT_SOURCE")
+	c.ZulipEnabled = viper.GetBool("ZULIP_ENABLED")
+	c.ZulipURL = viper.GetString("ZULIP_URL")
+	c.ZulipBotEmail = viper.GetString("ZULIP_BOT_EMAIL")
+	c.ZulipAPIKey = viper.GetString("ZULIP_API_KEY")
+	c.ZulipStream = viper.GetString("ZULIP_STREAM")
+	c.GitLabEnabled = viper.GetBool("GITLAB_ENABLED")
+	c.GitLabURL = viper.GetString("GITLAB_URL")
+	c.GitLabToken = viper.GetString("GITLAB_TOKEN")
+	c.GitLabProject = viper.GetString("GITLAB_PROJECT")
+	for _, label := range strings.Split(viper.GetString("GITLAB_LABELS"), ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			c.GitLabLabels = append(c.GitLabLabels, label)
+		}
+	}
+	c.DefectDojoEnabled = viper.GetBool("DEFECTDOJO_ENABLED")
+	c.DefectDojoURL = viper.GetString("DEFECTDOJO_URL")
+	c.DefectDojoAPIKey = viper.GetString("DEFECTDOJO_API_KEY")
+	c.DefectDojoProductType = viper.GetString("DEFECTDOJO_PRODUCT_TYPE")
+	c.DefectDojoEngagement = viper.GetString("DEFECTDOJO_ENGAGEMENT")
+	c.DependencyTrackEnabled = viper.GetBool("DEPENDENCYTRACK_ENABLED")
+	c.DependencyTrackURL = viper.GetString("DEPENDENCYTRACK_URL")
+	c.DependencyTrackAPIKey = viper.GetString("DEPENDENCYTRACK_API_KEY")
+	c.DependencyTrackProjectVersion = viper.GetString("DEPENDENCYTRACK_PROJECT_VERSION")
+	for _, tag := range strings.Split(viper.GetString("DEPENDENCYTRACK_TAGS"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			c.DependencyTrackTags = append(c.DependencyTrackTags, tag)
+		}
+	}
+	c.ConfluenceEnabled = viper.GetBool("CONFLUENCE_ENABLED")
+	c.ConfluenceURL = viper.GetString("CONFLUENCE_URL")
+	c.ConfluenceUser = viper.GetString("CONFLUENCE_USER")
+	c.ConfluenceAPIToken = viper.GetString("CONFLUENCE_API_TOKEN")
+	c.ConfluenceSpace = viper.GetString("CONFLUENCE_SPACE")
+	c.ConfluenceParentPageID = viper.GetString("CONFLUENCE_PARENT_PAGE_ID")
+	c.ConfluenceTitle = viper.GetString("CONFLUENCE_TITLE")
 	c.GeminiAPIKey = viper.GetString("GEMINI_API_KEY")
 	c.GeminiEnabled = viper.GetBool("GEMINI_ENABLED")
+	for _, name := range strings.Split(viper.GetString("AI_ANALYZERS"), ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			c.AIAnalyzers = append(c.AIAnalyzers, name)
+		}
+	}
 	c.GeminiModel = viper.GetString("GEMINI_MODEL")
+	c.GeminiFleetAnalysis = viper.GetBool("GEMINI_FLEET_ANALYSIS")
+	c.GeminiMaxAdvisories = max(viper.GetInt("GEMINI_MAX_ADVISORIES"), 0)
+	c.AICacheTTL = max(viper.GetDuration("AI_CACHE_TTL"), 0)
+	c.UpdateCheckEnabled = viper.GetBool("UPDATE_CHECK_ENABLED")
+	c.APIListen = viper.GetString("API_LISTEN")
+	c.APIToken = viper.GetString("API_TOKEN")
+	c.JobQueue = strings.ToLower(strings.TrimSpace(viper.GetString("JOB_QUEUE")))
+	c.RedisURL = viper.GetString("REDIS_URL")
+	c.JobWorkers = viper.GetInt("JOB_WORKERS")
+	c.JobScheduleInterval = viper.GetDuration("JOB_SCHEDULE_INTERVAL")
+	c.Operator = resolveOperator()
 
 	// Settings from Viper
 	c.Settings.SeverityThreshold = viper.GetString("SEVERITY_THRESHOLD")
+	c.Settings.IssueOrder = strings.ToLower(strings.TrimSpace(viper.GetString("ISSUE_ORDER")))
 	c.Settings.ReportOutputDir = viper.GetString("REPORT_OUTPUT_DIR")
+	c.Settings.ReportFilename = strings.TrimSpace(viper.GetString("REPORT_FILENAME_TEMPLATE"))
+	c.Settings.ReportTimestamp = strings.TrimSpace(viper.GetString("REPORT_TIMESTAMP_FORMAT"))
 	c.Settings.MaxConcurrent = viper.GetInt("MAX_CONCURRENT")
 	c.Settings.RetryAttempts = viper.GetInt("RETRY_ATTEMPTS")
+	c.Settings.JavaAuditBackend = strings.ToLower(strings.TrimSpace(viper.GetString("JAVA_AUDIT_BACKEND")))
+	c.Settings.SystemAuditBackend = strings.ToLower(strings.TrimSpace(viper.GetString("SYSTEM_AUDIT_BACKEND")))
+	c.Settings.AdaptiveSchedule = viper.GetBool("ADAPTIVE_SCHEDULE")
+	c.Settings.StrictMode = viper.GetBool("STRICT_MODE")
+	c.Settings.PolicyFile = strings.TrimSpace(viper.GetString("POLICY_FILE"))
+	c.Settings.ScheduleMinInterval = viper.GetDuration("SCHEDULE_MIN_INTERVAL")
+	c.Settings.ScheduleBaseInterval = viper.GetDuration("SCHEDULE_BASE_INTERVAL")
+	c.Settings.ScheduleMaxInterval = viper.GetDuration("SCHEDULE_MAX_INTERVAL")
+	c.Settings.OSVCacheDir = viper.GetString("OSV_CACHE_DIR")
+	c.Settings.OSVCacheTTL = viper.GetDuration("OSV_CACHE_TTL")
+	c.Settings.CheckCacheDir = viper.GetString("CHECK_CACHE_DIR")
+	c.Settings.CheckCacheTTL = viper.GetDuration("CHECK_CACHE_TTL")
+	c.Settings.AdvisoryWatchInterval = viper.GetDuration("ADVISORY_WATCH_INTERVAL")
+	c.Settings.NotifierDrillEnabled = viper.GetBool("NOTIFIER_DRILL_ENABLED")
+	c.Settings.NotifierDrillInterval = viper.GetDuration("NOTIFIER_DRILL_INTERVAL")
+	for _, channel := range strings.Split(viper.GetString("NOTIFIER_DRILL_CHANNELS"), ",") {
+		if channel = strings.ToLower(strings.TrimSpace(channel)); channel != "" {
+			c.Settings.NotifierDrillChannels = append(c.Settings.NotifierDrillChannels, channel)
+		}
+	}
+	for _, email := range strings.Split(viper.GetString("NOTIFIER_DRILL_EMAILS"), ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			c.Settings.NotifierDrillEmails = append(c.Settings.NotifierDrillEmails, email)
+		}
+	}
+	c.Settings.PinningPolicyEnabled = viper.GetBool("PINNING_POLICY_ENABLED")
+	c.Settings.OutdatedAuditEnabled = viper.GetBool("OUTDATED_AUDIT_ENABLED")
+	c.Settings.OutdatedAuditSeverity = strings.ToLower(strings.TrimSpace(viper.GetString("OUTDATED_AUDIT_SEVERITY")))
+	c.Settings.SupplyChainAuditEnabled = viper.GetBool("SUPPLY_CHAIN_AUDIT_ENABLED")
+	c.Settings.SecretsAuditEnabled = viper.GetBool("SECRETS_AUDIT_ENABLED")
+	c.Settings.PHPEOLAuditEnabled = viper.GetBool("PHP_EOL_AUDIT_ENABLED")
+	c.Settings.NodeEOLAuditEnabled = viper.GetBool("NODE_EOL_AUDIT_ENABLED")
+	c.Settings.AuditWorkspace = strings.ToLower(strings.TrimSpace(viper.GetString("AUDIT_WORKSPACE")))
+	c.Settings.AuditWorkspaceDir = viper.GetString("AUDIT_WORKSPACE_DIR")
+	for _, name := range strings.Split(viper.GetString("AUDIT_WORKSPACE_EXCLUDE"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			c.Settings.AuditWorkspaceExclude = append(c.Settings.AuditWorkspaceExclude, name)
+		}
+	}
+	c.Settings.NPMAuditOptions = map[string]string{
+		"audit-level": strings.ToLower(strings.TrimSpace(viper.GetString("NPM_AUDIT_LEVEL"))),
+		"omit":        strings.ToLower(strings.ReplaceAll(viper.GetString("NPM_AUDIT_OMIT"), ",", " ")),
+		"registry":    strings.TrimSpace(viper.GetString("NPM_AUDIT_REGISTRY")),
+		"before":      strings.TrimSpace(viper.GetString("NPM_AUDIT_BEFORE")),
+		"signatures":  strings.ToLower(strings.TrimSpace(viper.GetString("NPM_AUDIT_SIGNATURES"))),
+	}
+	c.Settings.ComposerAuditOptions = map[string]string{
+		"locked":             strings.ToLower(strings.TrimSpace(viper.GetString("COMPOSER_AUDIT_LOCKED"))),
+		"abandoned":          strings.ToLower(strings.TrimSpace(viper.GetString("COMPOSER_AUDIT_ABANDONED"))),
+		"ignore-severity":    strings.ToLower(strings.ReplaceAll(viper.GetString("COMPOSER_AUDIT_IGNORE_SEVERITY"), ",", " ")),
+		"abandoned-severity": strings.ToLower(strings.TrimSpace(viper.GetString("COMPOSER_AUDIT_ABANDONED_SEVERITY"))),
+	}
 
 	// Parse report formats
 	formats := viper.GetString("REPORT_FORMATS")
@@ -143,14 +641,124 @@ func (c *Config) loadEnvVars() {
 	for i, f := range c.Settings.ReportFormats {
 		c.Settings.ReportFormats[i] = strings.TrimSpace(f)
 	}
+
+	// Combined reports replace the report files of each auditor in notifications
+	for _, format := range strings.Split(viper.GetString("COMBINED_REPORT_FORMATS"), ",") {
+		if format = strings.ToLower(strings.TrimSpace(format)); format != "" {
+			c.Settings.CombinedFormats = append(c.Settings.CombinedFormats, format)
+		}
+	}
+
+	// Parse minimum tool versions (e.g., "npm=9.0.0,composer=2.6.0")
+	c.Settings.MinToolVersions = parseKeyValueList(viper.GetString("MIN_TOOL_VERSIONS"))
+
+	// Executive report
+	c.Settings.ExecutiveReportEnabled = viper.GetBool("EXECUTIVE_REPORT_ENABLED")
+	for _, email := range strings.Split(viper.GetString("EXECUTIVE_REPORT_EMAILS"), ",") {
+		if email = strings.TrimSpace(email); email != "" {
+			c.Settings.ExecutiveReportEmails = append(c.Settings.ExecutiveReportEmails, email)
+		}
+	}
+
+	// Parse owner weights (e.g., "payments=3,platform=2"); invalid values are ignored
+	c.Settings.OwnerWeights = make(map[string]int)
+	for owner, weight := range parseKeyValueList(viper.GetString("OWNER_WEIGHTS")) {
+		if n, err := strconv.Atoi(weight); err == nil && n > 0 {
+			c.Settings.OwnerWeights[owner] = n
+		}
+	}
+
+	// Parse SLA days per severity (e.g., "critical=7,high=30"); invalid values are ignored
+	c.Settings.SLADays = make(map[string]int)
+	for severity, days := range parseKeyValueList(viper.GetString("SLA_DAYS")) {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			c.Settings.SLADays[strings.ToLower(severity)] = n
+		}
+	}
+
+	// Scorecards
+	c.Settings.ScorecardsEnabled = viper.GetBool("SCORECARDS_ENABLED")
+	for _, format := range strings.Split(viper.GetString("SCORECARD_FORMATS"), ",") {
+		if format = strings.ToLower(strings.TrimSpace(format)); format != "" {
+			c.Settings.ScorecardFormats = append(c.Settings.ScorecardFormats, format)
+		}
+	}
+
+	// Atom feed
+	c.Settings.FeedEnabled = viper.GetBool("FEED_ENABLED")
+	c.Settings.FeedPath = strings.TrimSpace(viper.GetString("FEED_PATH"))
+	c.Settings.FeedTitle = viper.GetString("FEED_TITLE")
+	c.Settings.FeedURL = strings.TrimSpace(viper.GetString("FEED_URL"))
+	c.Settings.FeedDays = viper.GetInt("FEED_DAYS")
+	if c.Settings.FeedDays <= 0 {
+		c.Settings.FeedDays = 14
+	}
+	c.Settings.FeedToken = viper.GetString("FEED_TOKEN")
+
+	// History retention
+	c.Settings.RetentionDays = max(viper.GetInt("RETENTION_DAYS"), 0)
+
+	// Status page
+	c.Settings.StatusPageEnabled = viper.GetBool("STATUS_PAGE_ENABLED")
+	c.Settings.StatusPageDir = viper.GetString("STATUS_PAGE_DIR")
+	c.Settings.StatusPageTitle = viper.GetString("STATUS_PAGE_TITLE")
+	c.Settings.StatusPageStaleDays = viper.GetInt("STATUS_PAGE_STALE_DAYS")
+	if c.Settings.StatusPageStaleDays <= 0 {
+		c.Settings.StatusPageStaleDays = 7
+	}
+}
+
+// resolveOperator determines who is running the command. On shared service
+// accounts, the user who invoked sudo is more useful than the account itself.
+func resolveOperator() string {
+	if operator := strings.TrimSpace(viper.GetString("AUDIT_OPERATOR")); operator != "" {
+		return operator
+	}
+	if sudoUser := os.Getenv("SUDO_USER"); sudoUser != "" {
+		return sudoUser
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if username := os.Getenv("USER"); username != "" {
+		return username
+	}
+	return "unknown"
+}
+
+// parseKeyValueList parses a comma-separated list of key=value pairs into a map
+func parseKeyValueList(s string) map[string]string {
+	result := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key != "" && value != "" {
+			result[key] = value
+		}
+	}
+	return result
 }
 
 // setDefaults sets default values for settings
 func (c *Config) setDefaults() {
+	if c.RawOutputMaxSize < 0 {
+		c.RawOutputMaxSize = 512
+	}
+
 	if c.Settings.SeverityThreshold == "" {
 		c.Settings.SeverityThreshold = models.SeverityModerate
 	}
 
+	switch c.Settings.IssueOrder {
+	case models.IssueOrderSeverity, models.IssueOrderAge, models.IssueOrderRisk:
+	default:
+		c.Settings.IssueOrder = models.IssueOrderSeverity
+	}
+
 	if len(c.Settings.ReportFormats) == 0 {
 		c.Settings.ReportFormats = []string{"json", "markdown"}
 	}
@@ -159,6 +767,32 @@ func (c *Config) setDefaults() {
 		c.Settings.ReportOutputDir = "./storage/reports"
 	}
 
+	// Report file names stay inside REPORT_OUTPUT_DIR
+	if c.Settings.ReportFilename == "" || filepath.IsAbs(c.Settings.ReportFilename) ||
+		slices.Contains(strings.Split(filepath.ToSlash(c.Settings.ReportFilename), "/"), "..") {
+		c.Settings.ReportFilename = "{app}-{auditor}-{date}"
+	}
+
+	if c.Settings.ReportTimestamp == "" {
+		c.Settings.ReportTimestamp = "2006-01-02-150405"
+	}
+
+	if c.Settings.FeedPath == "" {
+		c.Settings.FeedPath = filepath.Join(c.Settings.ReportOutputDir, "feed.atom")
+	}
+
+	// The check runs in developers' working copies: its cache stays out of them
+	if c.Settings.CheckCacheDir == "" {
+		c.Settings.CheckCacheDir = "./storage/cache/check"
+		if dir, err := os.UserCacheDir(); err == nil {
+			c.Settings.CheckCacheDir = filepath.Join(dir, "audit-checks", "check")
+		}
+	}
+
+	if c.Settings.CheckCacheTTL <= 0 {
+		c.Settings.CheckCacheTTL = 24 * time.Hour
+	}
+
 	if c.Settings.MaxConcurrent <= 0 {
 		c.Settings.MaxConcurrent = 3
 	}
@@ -166,6 +800,36 @@ func (c *Config) setDefaults() {
 	if c.Settings.RetryAttempts <= 0 {
 		c.Settings.RetryAttempts = 3
 	}
+
+	switch c.JobQueue {
+	case JobQueueEmbedded, JobQueueRedis:
+	default:
+		c.JobQueue = JobQueueOff
+	}
+
+	if c.JobWorkers <= 0 {
+		c.JobWorkers = 1
+	}
+
+	if c.Settings.AdvisoryWatchInterval <= 0 {
+		c.Settings.AdvisoryWatchInterval = 15 * time.Minute
+	}
+
+	if c.Settings.NotifierDrillInterval <= 0 {
+		c.Settings.NotifierDrillInterval = 30 * 24 * time.Hour
+	}
+
+	if c.Settings.ScheduleMinInterval <= 0 {
+		c.Settings.ScheduleMinInterval = 6 * time.Hour
+	}
+
+	if c.Settings.ScheduleMaxInterval < c.Settings.ScheduleMinInterval {
+		c.Settings.ScheduleMaxInterval = max(7*24*time.Hour, c.Settings.ScheduleMinInterval)
+	}
+
+	// Keep the base interval within the bounds
+	c.Settings.ScheduleBaseInterval = min(max(c.Settings.ScheduleBaseInterval, c.Settings.ScheduleMinInterval),
+		c.Settings.ScheduleMaxInterval)
 }
 
 // EnsureDirectories creates necessary directories
@@ -194,11 +858,12 @@ func (c *Config) SetApps(apps []models.AppConfig) {
 	c.Apps = apps
 }
 
-// GetEnabledApps returns only the enabled apps
+// GetEnabledApps returns only the enabled apps that are not paused
 func (c *Config) GetEnabledApps() []models.AppConfig {
+	now := time.Now()
 	var enabled []models.AppConfig
 	for _, app := range c.Apps {
-		if app.Enabled {
+		if app.Enabled && !app.IsPaused(now) {
 			enabled = append(enabled, app)
 		}
 	}
@@ -220,6 +885,11 @@ func (c *Config) ShouldNotify(severity string) bool {
 	return models.MeetsSeverityThreshold(severity, c.Settings.SeverityThreshold)
 }
 
+// MinToolVersion returns the configured minimum tool version for an auditor (empty if not set)
+func (c *Config) MinToolVersion(auditorName string) string {
+	return c.Settings.MinToolVersions[auditorName]
+}
+
 // IsGeminiEnabled returns true if Gemini is enabled and API key is set
 func (c *Config) IsGeminiEnabled() bool {
 	return c.GeminiEnabled && c.GeminiAPIKey != ""
@@ -227,6 +897,9 @@ func (c *Config) IsGeminiEnabled() bool {
 
 // IsEmailEnabled returns true if email notifications are configured
 func (c *Config) IsEmailEnabled() bool {
+	if c.EmailProvider == "smtp" {
+		return c.SMTPHost != "" && c.SMTPFromEmail != ""
+	}
 	return c.ResendAPIKey != "" && c.ResendFromEmail != ""
 }
 
@@ -235,6 +908,131 @@ func (c *Config) IsTelegramEnabled() bool {
 	return c.TelegramEnabled && c.TelegramBotToken != "" && c.TelegramGroupID != 0
 }
 
+// IsWebhookEnabled returns true if outbound webhooks are enabled. Without WEBHOOK_URLS,
+// only apps with their own webhook URLs are sent anything.
+func (c *Config) IsWebhookEnabled() bool {
+	return c.WebhookEnabled
+}
+
+// IsPagerDutyEnabled returns true if PagerDuty incidents are configured
+func (c *Config) IsPagerDutyEnabled() bool {
+	return c.PagerDutyEnabled && c.PagerDutyRoutingKey != ""
+}
+
+// IsOpsgenieEnabled returns true if Opsgenie alerts are configured
+func (c *Config) IsOpsgenieEnabled() bool {
+	return c.OpsgenieEnabled && c.OpsgenieAPIKey != ""
+}
+
+// IsNtfyEnabled returns true if ntfy notifications are configured
+func (c *Config) IsNtfyEnabled() bool {
+	return c.NtfyEnabled && c.NtfyServerURL != ""
+}
+
+// IsGotifyEnabled returns true if Gotify notifications are configured
+func (c *Config) IsGotifyEnabled() bool {
+	return c.GotifyEnabled && c.GotifyURL != "" && c.GotifyToken != ""
+}
+
+// IsZulipEnabled returns true if Zulip notifications are configured
+func (c *Config) IsZulipEnabled() bool {
+	return c.ZulipEnabled && c.ZulipURL != "" && c.ZulipBotEmail != "" && c.ZulipAPIKey != "" && c.ZulipStream != ""
+}
+
+// IsGitLabEnabled returns true if GitLab issues are configured. Without GITLAB_PROJECT,
+// only apps with their own project get issues.
+func (c *Config) IsGitLabEnabled() bool {
+	return c.GitLabEnabled && c.GitLabURL != "" && c.GitLabToken != ""
+}
+
+// IsDefectDojoEnabled returns true if audit results are imported into DefectDojo
+func (c *Config) IsDefectDojoEnabled() bool {
+	return c.DefectDojoEnabled && c.DefectDojoURL != "" && c.DefectDojoAPIKey != "" &&
+		c.DefectDojoProductType != "" && c.DefectDojoEngagement != ""
+}
+
+// IsDependencyTrackEnabled returns true if the apps' SBOMs are uploaded to Dependency-Track
+func (c *Config) IsDependencyTrackEnabled() bool {
+	return c.DependencyTrackEnabled && c.DependencyTrackURL != "" && c.DependencyTrackAPIKey != "" &&
+		c.DependencyTrackProjectVersion != ""
+}
+
+// IsConfluenceEnabled returns true if a Confluence page is published per app
+func (c *Config) IsConfluenceEnabled() bool {
+	return c.ConfluenceEnabled && c.ConfluenceURL != "" && c.ConfluenceAPIToken != "" && c.ConfluenceSpace != "" &&
+		strings.Contains(c.ConfluenceTitle, "{app}")
+}
+
+// IsJobQueueEnabled returns true if `serve` runs audits and notification retries as jobs
+func (c *Config) IsJobQueueEnabled() bool {
+	return c.JobQueue == JobQueueEmbedded || (c.JobQueue == JobQueueRedis && c.RedisURL != "")
+}
+
+// IsDiscordEnabled returns true if Discord notifications are configured
+func (c *Config) IsDiscordEnabled() bool {
+	return c.DiscordEnabled && (c.DiscordWebhookURL != "" || (c.DiscordBotToken != "" && c.DiscordChannelID != ""))
+}
+
+// IsMattermostEnabled returns true if Mattermost notifications are configured
+func (c *Config) IsMattermostEnabled() bool {
+	return c.MattermostEnabled && (c.MattermostWebhookURL != "" ||
+		(c.MattermostURL != "" && c.MattermostBotToken != "" && c.MattermostChannelID != ""))
+}
+
+// EnabledFeatures returns the optional integrations and settings that are enabled,
+// e.g. to tell which features the build that produced a report ran with
+func (c *Config) EnabledFeatures() []string {
+	features := []struct {
+		name    string
+		enabled bool
+	}{
+		{"email", c.IsEmailEnabled()},
+		{"telegram", c.IsTelegramEnabled()},
+		{"discord", c.IsDiscordEnabled()},
+		{"mattermost", c.IsMattermostEnabled()},
+		{"webhook", c.IsWebhookEnabled()},
+		{"pagerduty", c.IsPagerDutyEnabled()},
+		{"opsgenie", c.IsOpsgenieEnabled()},
+		{"ntfy", c.IsNtfyEnabled()},
+		{"gotify", c.IsGotifyEnabled()},
+		{"zulip", c.IsZulipEnabled()},
+		{"desktop", c.DesktopEnabled},
+		{"gitlab", c.IsGitLabEnabled()},
+		{"defectdojo", c.IsDefectDojoEnabled()},
+		{"dependency-track", c.IsDependencyTrackEnabled()},
+		{"confluence", c.IsConfluenceEnabled()},
+		{"job-queue", c.IsJobQueueEnabled()},
+		{"gemini", c.IsGeminiEnabled()},
+		{"gemini-fleet-analysis", c.IsGeminiEnabled() && c.GeminiFleetAnalysis},
+		{"run-log", c.RunLogEnabled},
+		{"adaptive-schedule", c.Settings.AdaptiveSchedule},
+		{"strict-mode", c.Settings.StrictMode},
+		{"policies", c.Settings.PolicyFile != ""},
+		{"executive-report", c.Settings.ExecutiveReportEnabled},
+		{"scorecards", c.Settings.ScorecardsEnabled},
+		{"status-page", c.Settings.StatusPageEnabled},
+		{"retention", c.Settings.RetentionDays > 0},
+		{"feed", c.Settings.FeedEnabled},
+		{"notifier-drill", c.Settings.NotifierDrillEnabled},
+		{"pinning-policy", c.Settings.PinningPolicyEnabled},
+		{"outdated-audit", c.Settings.OutdatedAuditEnabled},
+		{"supply-chain-audit", c.Settings.SupplyChainAuditEnabled},
+		{"secrets-audit", c.Settings.SecretsAuditEnabled},
+		{"php-eol-audit", c.Settings.PHPEOLAuditEnabled},
+		{"node-eol-audit", c.Settings.NodeEOLAuditEnabled},
+		{"audit-workspace", c.Settings.AuditWorkspace != "" && c.Settings.AuditWorkspace != "off"},
+		{"update-check", c.UpdateCheckEnabled},
+	}
+
+	enabled := []string{}
+	for _, feature := range features {
+		if feature.enabled {
+			enabled = append(enabled, feature.name)
+		}
+	}
+	return enabled
+}
+
 // IsDevelopment returns true if running in development environment
 func (c *Config) IsDevelopment() bool {
 	return c.AppEnv == "development" || c.AppEnv == "dev" || c.AppEnv == "local"
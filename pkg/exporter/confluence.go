@@ -0,0 +1,197 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ConfluenceExporter publishes a page per app to a Confluence space, replacing its
+// content after each audit so the wiki shows the app's current security posture. Pages
+// are found by title, and created under the parent page the first time.
+type ConfluenceExporter struct {
+	baseURL      string
+	user         string // empty for a personal access token (Server and Data Center)
+	token        string
+	space        string
+	parentPageID string
+	title        string // with {app} replaced by the app's name
+	client       *http.Client
+}
+
+// NewConfluenceExporter creates a new ConfluenceExporter publishing to space, under the
+// page parentPageID when not empty, pages titled title with {app} replaced by the app's
+// name. With a user, token is the user's API token (Cloud); without, a personal access
+// token (Server and Data Center).
+func NewConfluenceExporter(baseURL, user, token, space, parentPageID, title string) *ConfluenceExporter {
+	return &ConfluenceExporter{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		user:         user,
+		token:        token,
+		space:        space,
+		parentPageID: parentPageID,
+		title:        title,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// ConfluencePage is a page published to Confluence
+type ConfluencePage struct {
+	ID      string
+	Title   string
+	Version int
+	Created bool // by this publication
+}
+
+// confluenceContent is a page of the content API
+type confluenceContent struct {
+	ID        string               `json:"id,omitempty"`
+	Type      string               `json:"type"`
+	Title     string               `json:"title"`
+	Space     confluenceSpace      `json:"space"`
+	Ancestors []confluenceAncestor `json:"ancestors,omitempty"`
+	Version   *confluenceVersion   `json:"version,omitempty"`
+	Body      confluenceBody       `json:"body"`
+}
+
+type confluenceSpace struct {
+	Key string `json:"key"`
+}
+
+type confluenceAncestor struct {
+	ID string `json:"id"`
+}
+
+type confluenceVersion struct {
+	Number  int    `json:"number"`
+	Message string `json:"message,omitempty"`
+}
+
+type confluenceBody struct {
+	Storage struct {
+		Value          string `json:"value"`
+		Representation string `json:"representation"`
+	} `json:"storage"`
+}
+
+// PageTitle returns the title of an app's page
+func (e *ConfluenceExporter) PageTitle(appName string) string {
+	return strings.ReplaceAll(e.title, "{app}", appName)
+}
+
+// Publish creates or updates the page of an app with body, in Confluence's storage
+// format (XHTML)
+func (e *ConfluenceExporter) Publish(ctx context.Context, appName string, body []byte) (*ConfluencePage, error) {
+	title := e.PageTitle(appName)
+	existing, err := e.findPage(ctx, title)
+	if err != nil {
+		return nil, err
+	}
+
+	content := confluenceContent{
+		Type:  "page",
+		Title: title,
+		Space: confluenceSpace{Key: e.space},
+	}
+	content.Body.Storage.Value = string(body)
+	content.Body.Storage.Representation = "storage"
+
+	method, path := http.MethodPost, "/rest/api/content"
+	page := &ConfluencePage{Title: title, Version: 1, Created: true}
+	if existing != nil {
+		method, path = http.MethodPut, "/rest/api/content/"+url.PathEscape(existing.ID)
+		content.ID = existing.ID
+		content.Version = &confluenceVersion{Number: existing.Version + 1, Message: "Updated by audit-checks"}
+		page = &ConfluencePage{Title: title, Version: existing.Version + 1}
+	} else if e.parentPageID != "" {
+		content.Ancestors = []confluenceAncestor{{ID: e.parentPageID}}
+	}
+
+	var saved struct {
+		ID string `json:"id"`
+	}
+	if err := e.do(ctx, method, path, content, &saved); err != nil {
+		return nil, err
+	}
+	page.ID = saved.ID
+	return page, nil
+}
+
+// findPage returns the ID and version of the page titled title in the space, or nil
+// when there is none
+func (e *ConfluenceExporter) findPage(ctx context.Context, title string) (*ConfluencePage, error) {
+	query := url.Values{}
+	query.Set("spaceKey", e.space)
+	query.Set("title", title)
+	query.Set("type", "page")
+	query.Set("expand", "version")
+
+	var found struct {
+		Results []struct {
+			ID      string `json:"id"`
+			Version struct {
+				Number int `json:"number"`
+			} `json:"version"`
+		} `json:"results"`
+	}
+	if err := e.do(ctx, http.MethodGet, "/rest/api/content?"+query.Encode(), nil, &found); err != nil {
+		return nil, fmt.Errorf("failed to look up page %q: %w", title, err)
+	}
+	if len(found.Results) == 0 {
+		return nil, nil
+	}
+	return &ConfluencePage{ID: found.Results[0].ID, Version: found.Results[0].Version.Number}, nil
+}
+
+// do sends a request to the REST API and decodes its response into out
+func (e *ConfluenceExporter) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, e.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if e.user != "" {
+		req.SetBasicAuth(e.user, e.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+e.token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if message := strings.TrimSpace(string(respBody)); message != "" {
+			return fmt.Errorf("confluence API error: status %d: %s", resp.StatusCode, message)
+		}
+		return fmt.Errorf("confluence API error: status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// Package exporter imports audit results and SBOMs into the vulnerability management
+// tools a security team triages in, next to the findings of their other scanners
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// DefectDojoScanType is the DefectDojo parser the audit results are imported with
+const DefectDojoScanType = "Generic Findings Import"
+
+// defectDojoSeverities maps vulnerability severities to DefectDojo's
+var defectDojoSeverities = map[string]string{
+	models.SeverityCritical: "Critical",
+	models.SeverityHigh:     "High",
+	models.SeverityModerate: "Medium",
+	models.SeverityLow:      "Low",
+	models.SeverityInfo:     "Info",
+}
+
+// DefectDojoExporter imports each audit result as a scan into DefectDojo, through its
+// import-scan API. Every app has a product, created with its engagement by the first
+// import, and every auditor a service within it: an import closes the findings of the
+// previous import of its auditor that are gone, and leaves the other auditors' alone.
+type DefectDojoExporter struct {
+	serverURL   string
+	apiKey      string
+	productType string
+	engagement  string
+	client      *http.Client
+}
+
+// DefectDojoImport identifies where DefectDojo imported an audit result
+type DefectDojoImport struct {
+	ProductID    int `json:"product_id"`
+	EngagementID int `json:"engagement_id"`
+	TestID       int `json:"test_id"`
+}
+
+// NewDefectDojoExporter creates a new DefectDojoExporter importing into the engagement
+// named engagement of the apps' products, of type productType
+func NewDefectDojoExporter(serverURL, apiKey, productType, engagement string) *DefectDojoExporter {
+	return &DefectDojoExporter{
+		serverURL:   strings.TrimSuffix(serverURL, "/"),
+		apiKey:      apiKey,
+		productType: productType,
+		engagement:  engagement,
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// defectDojoFindings is a Generic Findings Import report
+type defectDojoFindings struct {
+	Findings []defectDojoFinding `json:"findings"`
+}
+
+// defectDojoFinding is a finding of a Generic Findings Import report
+type defectDojoFinding struct {
+	Title            string `json:"title"`
+	Description      string `json:"description"`
+	Severity         string `json:"severity"`
+	Mitigation       string `json:"mitigation,omitempty"`
+	References       string `json:"references,omitempty"`
+	ComponentName    string `json:"component_name,omitempty"`
+	CVE              string `json:"cve,omitempty"`
+	UniqueIDFromTool string `json:"unique_id_from_tool"`
+	Date             string `json:"date"`
+	Active           bool   `json:"active"`
+	Verified         bool   `json:"verified"`
+}
+
+// Export imports an audit result into the product of its app, also when it has no
+// findings so that those fixed since the previous import are closed
+func (e *DefectDojoExporter) Export(ctx context.Context, result *models.AuditResult) (*DefectDojoImport, error) {
+	scanDate := result.CreatedAt
+	if scanDate.IsZero() {
+		scanDate = time.Now()
+	}
+
+	report, err := json.Marshal(buildDefectDojoFindings(result, scanDate))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal findings: %w", err)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	fields := [][2]string{
+		{"scan_type", DefectDojoScanType},
+		{"product_type_name", e.productType},
+		{"product_name", result.AppName},
+		{"engagement_name", e.engagement},
+		{"auto_create_context", "true"},
+		{"test_title", "audit-checks " + result.AuditorType},
+		{"service", result.AuditorType},
+		{"scan_date", scanDate.Format(time.DateOnly)},
+		{"minimum_severity", "Info"},
+		{"active", "true"},
+		{"verified", "false"},
+		{"close_old_findings", "true"},
+		{"deduplication_on_engagement", "true"},
+		{"build_id", result.RunID},
+		{"version", result.ToolVersion},
+		{"tags", "audit-checks"},
+	}
+	for _, field := range fields {
+		if field[1] == "" {
+			continue
+		}
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return nil, fmt.Errorf("failed to write form: %w", err)
+		}
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="audit-checks-%s.json"`, result.AuditorType))
+	header.Set("Content-Type", "application/json")
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write form: %w", err)
+	}
+	if _, err := part.Write(report); err != nil {
+		return nil, fmt.Errorf("failed to write form: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write form: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.serverURL+"/api/v2/import-scan/", &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Authorization", "Token "+e.apiKey)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if message := strings.TrimSpace(string(respBody)); message != "" {
+			return nil, fmt.Errorf("defectdojo API error: status %d: %s", resp.StatusCode, message)
+		}
+		return nil, fmt.Errorf("defectdojo API error: status %d", resp.StatusCode)
+	}
+
+	var imported DefectDojoImport
+	if err := json.NewDecoder(resp.Body).Decode(&imported); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &imported, nil
+}
+
+// buildDefectDojoFindings converts the vulnerabilities of an audit result to findings,
+// dated from when they were first seen
+func buildDefectDojoFindings(result *models.AuditResult, scanDate time.Time) defectDojoFindings {
+	report := defectDojoFindings{Findings: []defectDojoFinding{}}
+
+	for _, v := range result.Vulnerabilities {
+		name := v.Title
+		if name == "" {
+			name = v.CVEID
+		}
+		date := scanDate
+		if v.FirstSeenAt != nil {
+			date = *v.FirstSeenAt
+		}
+
+		finding := defectDojoFinding{
+			Title:         fmt.Sprintf("%s: %s", v.PackageName, name),
+			Description:   defectDojoDescription(result, v),
+			Severity:      defectDojoSeverity(v.Severity),
+			References:    v.URL,
+			ComponentName: v.PackageName,
+			// Stable across imports, so DefectDojo recognizes the finding it already has
+			UniqueIDFromTool: result.AuditorType + ":" + strings.ReplaceAll(v.FindingKey(), "\x00", ":"),
+			Date:             date.Format(time.DateOnly),
+			Active:           true,
+		}
+		if strings.HasPrefix(v.CVEID, "CVE-") || strings.HasPrefix(v.CVEID, "GHSA-") {
+			finding.CVE = v.CVEID
+		}
+		switch {
+		case v.Recommendation != "":
+			finding.Mitigation = v.Recommendation
+		case v.PatchedVersions != "":
+			finding.Mitigation = fmt.Sprintf("Upgrade %s to %s", v.PackageName, v.PatchedVersions)
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+	return report
+}
+
+// defectDojoDescription describes a vulnerability in Markdown. It only holds what stays
+// the same across audits, since DefectDojo may deduplicate findings on it.
+func defectDojoDescription(result *models.AuditResult, v models.Vulnerability) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "**Package:** %s\n\n", v.PackageName)
+	if v.CVEID != "" {
+		fmt.Fprintf(&b, "**Advisory:** %s\n\n", v.CVEID)
+	}
+	if v.VulnerableVersions != "" {
+		fmt.Fprintf(&b, "**Vulnerable versions:** %s\n\n", v.VulnerableVersions)
+	}
+	if v.PatchedVersions != "" {
+		fmt.Fprintf(&b, "**Patched versions:** %s\n\n", v.PatchedVersions)
+	}
+	fmt.Fprintf(&b, "**Found by:** audit-checks %s auditor in %s\n", result.AuditorType, result.AppPath)
+	if v.Description != "" {
+		fmt.Fprintf(&b, "\n%s\n", v.Description)
+	}
+	return b.String()
+}
+
+// defectDojoSeverity returns the DefectDojo severity of a vulnerability severity
+func defectDojoSeverity(severity string) string {
+	if s, ok := defectDojoSeverities[severity]; ok {
+		return s
+	}
+	return "Info"
+}
@@ -0,0 +1,100 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DependencyTrackExporter uploads the CycloneDX SBOM of each app to Dependency-Track,
+// which keeps watching the app's components for new vulnerabilities between audits.
+// Every app version is a project, created with its tags by its first upload and marked
+// as the app's latest version.
+type DependencyTrackExporter struct {
+	serverURL string
+	apiKey    string
+	tags      []string
+	client    *http.Client
+}
+
+// NewDependencyTrackExporter creates a new DependencyTrackExporter tagging the projects
+// it creates with tags
+func NewDependencyTrackExporter(serverURL, apiKey string, tags []string) *DependencyTrackExporter {
+	return &DependencyTrackExporter{
+		serverURL: strings.TrimSuffix(serverURL, "/"),
+		apiKey:    apiKey,
+		tags:      tags,
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// dependencyTrackBOMRequest is the body of PUT /api/v1/bom
+type dependencyTrackBOMRequest struct {
+	ProjectName    string               `json:"projectName"`
+	ProjectVersion string               `json:"projectVersion"`
+	ProjectTags    []dependencyTrackTag `json:"projectTags,omitempty"`
+	AutoCreate     bool                 `json:"autoCreate"`
+	IsLatest       bool                 `json:"isLatest"`
+	BOM            string               `json:"bom"` // base64
+}
+
+type dependencyTrackTag struct {
+	Name string `json:"name"`
+}
+
+// Upload uploads the SBOM of version of an app to its project. It returns the token of
+// the upload, which Dependency-Track processes in the background.
+func (e *DependencyTrackExporter) Upload(ctx context.Context, appName, version string, bom []byte) (string, error) {
+	request := dependencyTrackBOMRequest{
+		ProjectName:    appName,
+		ProjectVersion: version,
+		AutoCreate:     true,
+		IsLatest:       true,
+		BOM:            base64.StdEncoding.EncodeToString(bom),
+	}
+	for _, tag := range e.tags {
+		request.ProjectTags = append(request.ProjectTags, dependencyTrackTag{Name: tag})
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, e.serverURL+"/api/v1/bom", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", e.apiKey)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if message := strings.TrimSpace(string(respBody)); message != "" {
+			return "", fmt.Errorf("dependency-track API error: status %d: %s", resp.StatusCode, message)
+		}
+		return "", fmt.Errorf("dependency-track API error: status %d", resp.StatusCode)
+	}
+
+	var uploaded struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&uploaded); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	return uploaded.Token, nil
+}
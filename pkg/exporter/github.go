@@ -0,0 +1,168 @@
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// GitHubCommentMarker starts the pull request comment of audit-checks, to find it again
+const GitHubCommentMarker = "<!-- audit-checks -->"
+
+// GitHubPullRequest is the pull request a GitHub Actions workflow runs for
+type GitHubPullRequest struct {
+	Repository string // owner/name
+	Number     int
+	BaseRef    string // branch the pull request merges into
+	BaseSHA    string
+}
+
+// ReadGitHubEvent reads the pull request of the event that triggered a GitHub Actions
+// workflow, from the event payload at path (GITHUB_EVENT_PATH). Returns an error for
+// events without a pull request.
+func ReadGitHubEvent(path, repository string) (*GitHubPullRequest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read event: %w", err)
+	}
+
+	var event struct {
+		PullRequest *struct {
+			Number int `json:"number"`
+			Base   struct {
+				Ref string `json:"ref"`
+				SHA string `json:"sha"`
+			} `json:"base"`
+		} `json:"pull_request"`
+	}
+	if err := json.Unmarshal(content, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode event: %w", err)
+	}
+	if event.PullRequest == nil || event.PullRequest.Number == 0 {
+		return nil, fmt.Errorf("the workflow was not triggered by a pull request")
+	}
+
+	return &GitHubPullRequest{
+		Repository: repository,
+		Number:     event.PullRequest.Number,
+		BaseRef:    event.PullRequest.Base.Ref,
+		BaseSHA:    event.PullRequest.Base.SHA,
+	}, nil
+}
+
+// GitHubCommenter keeps a single sticky comment of audit-checks on a pull request:
+// created by the first check, and edited by later ones instead of adding more
+type GitHubCommenter struct {
+	apiURL string
+	token  string
+	client *http.Client
+}
+
+// NewGitHubCommenter creates a new GitHubCommenter commenting through the REST API at
+// apiURL (GITHUB_API_URL, https://api.github.com on github.com) with token
+func NewGitHubCommenter(apiURL, token string) *GitHubCommenter {
+	if apiURL == "" {
+		apiURL = "https://api.github.com"
+	}
+	return &GitHubCommenter{
+		apiURL: strings.TrimSuffix(apiURL, "/"),
+		token:  token,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// githubComment is an issue comment of the REST API
+type githubComment struct {
+	ID   int64  `json:"id,omitempty"`
+	Body string `json:"body"`
+}
+
+// FindComment returns the ID of the pull request's audit-checks comment, or 0 when
+// there is none
+func (c *GitHubCommenter) FindComment(ctx context.Context, pr *GitHubPullRequest) (int64, error) {
+	for page := 1; ; page++ {
+		var comments []githubComment
+		path := fmt.Sprintf("/repos/%s/issues/%d/comments?per_page=100&page=%d", pr.Repository, pr.Number, page)
+		if err := c.do(ctx, http.MethodGet, path, nil, &comments); err != nil {
+			return 0, fmt.Errorf("failed to list comments: %w", err)
+		}
+		for _, comment := range comments {
+			if strings.HasPrefix(comment.Body, GitHubCommentMarker) {
+				return comment.ID, nil
+			}
+		}
+		if len(comments) < 100 {
+			return 0, nil
+		}
+	}
+}
+
+// Comment creates the pull request's audit-checks comment with body, or replaces the
+// body of the existing one (commentID, from FindComment). Returns the comment's ID.
+func (c *GitHubCommenter) Comment(ctx context.Context, pr *GitHubPullRequest, commentID int64, body string) (int64, error) {
+	comment := githubComment{Body: GitHubCommentMarker + "\n" + body}
+
+	method, path := http.MethodPost, fmt.Sprintf("/repos/%s/issues/%d/comments", pr.Repository, pr.Number)
+	if commentID != 0 {
+		method, path = http.MethodPatch, fmt.Sprintf("/repos/%s/issues/comments/%d", pr.Repository, commentID)
+	}
+
+	var saved githubComment
+	if err := c.do(ctx, method, path, comment, &saved); err != nil {
+		return 0, fmt.Errorf("failed to save comment: %w", err)
+	}
+	return saved.ID, nil
+}
+
+// do sends a request to the REST API and decodes its response into out
+func (c *GitHubCommenter) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.apiURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var errResp struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Message != "" {
+			return fmt.Errorf("github API error: status %d: %s", resp.StatusCode, errResp.Message)
+		}
+		return fmt.Errorf("github API error: status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
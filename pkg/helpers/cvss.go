@@ -0,0 +1,106 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// cvss3Weights holds the CVSS v3.x metric weights used by the base score formula
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// CVSS3BaseScore computes the base score of a CVSS v3.0/v3.1 vector string
+// (e.g. "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H" scores 9.8)
+func CVSS3BaseScore(vector string) (float64, error) {
+	parts := strings.Split(vector, "/")
+	if len(parts) < 2 || (parts[0] != "CVSS:3.0" && parts[0] != "CVSS:3.1") {
+		return 0, fmt.Errorf("unsupported CVSS vector: %s", vector)
+	}
+
+	metrics := make(map[string]string)
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			return 0, fmt.Errorf("malformed CVSS metric %q", part)
+		}
+		metrics[key] = value
+	}
+
+	scopeChanged := metrics["S"] == "C"
+	if metrics["S"] != "U" && !scopeChanged {
+		return 0, fmt.Errorf("missing or invalid scope in CVSS vector: %s", vector)
+	}
+
+	weight := func(metric string) (float64, error) {
+		w, ok := cvss3Weights[metric][metrics[metric]]
+		if !ok {
+			return 0, fmt.Errorf("missing or invalid %s in CVSS vector: %s", metric, vector)
+		}
+		return w, nil
+	}
+
+	var values [6]float64
+	for i, metric := range []string{"AV", "AC", "UI", "C", "I", "A"} {
+		w, err := weight(metric)
+		if err != nil {
+			return 0, err
+		}
+		values[i] = w
+	}
+	av, ac, ui, c, i, a := values[0], values[1], values[2], values[3], values[4], values[5]
+
+	// Privileges Required depends on scope
+	var pr float64
+	switch metrics["PR"] {
+	case "N":
+		pr = 0.85
+	case "L":
+		pr = 0.62
+		if scopeChanged {
+			pr = 0.68
+		}
+	case "H":
+		pr = 0.27
+		if scopeChanged {
+			pr = 0.5
+		}
+	default:
+		return 0, fmt.Errorf("missing or invalid PR in CVSS vector: %s", vector)
+	}
+
+	iss := 1 - (1-c)*(1-i)*(1-a)
+
+	var impact float64
+	if scopeChanged {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+
+	if impact <= 0 {
+		return 0, nil
+	}
+
+	exploitability := 8.22 * av * ac * pr * ui
+
+	if scopeChanged {
+		return cvssRoundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return cvssRoundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// cvssRoundUp rounds up to one decimal as defined by the CVSS v3.1 specification
+func cvssRoundUp(x float64) float64 {
+	scaled := int64(math.Round(x * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return (math.Floor(float64(scaled)/10000) + 1) / 10
+}
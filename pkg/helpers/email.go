@@ -0,0 +1,23 @@
+package helpers
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// ValidateEmail checks that s is a bare email address (no display name) with a domain
+// that has a dot, catching typos such as "ops@example" or "ops.example.com" that a
+// comma-separated list would otherwise keep silently
+func ValidateEmail(s string) error {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Name != "" || addr.Address != s {
+		return fmt.Errorf("invalid email address %q", s)
+	}
+
+	_, domain, _ := strings.Cut(addr.Address, "@")
+	if !strings.Contains(domain, ".") || strings.HasPrefix(domain, ".") || strings.HasSuffix(domain, ".") || strings.Contains(domain, "..") {
+		return fmt.Errorf("invalid email address %q: domain %q is incomplete", s, domain)
+	}
+	return nil
+}
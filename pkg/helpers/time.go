@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseUntil parses a point in time given either as a duration from now
+// ("36h", "3d", "2w") or as an absolute time (RFC 3339, "2006-01-02 15:04"
+// or "2006-01-02", interpreted in the local time zone). The result must be in the future.
+func ParseUntil(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, fmt.Errorf("time is required")
+	}
+
+	t, err := parseUntil(s, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !t.After(now) {
+		return time.Time{}, fmt.Errorf("%s is not in the future", t.Format(time.RFC3339))
+	}
+
+	return t, nil
+}
+
+func parseUntil(s string, now time.Time) (time.Time, error) {
+	// Day and week units are not supported by time.ParseDuration
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			if count, err := strconv.Atoi(n); err == nil && count > 0 {
+				return now.Add(time.Duration(count) * unit), nil
+			}
+		}
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(d), nil
+	}
+
+	if t, ok := parseTime(s, now.Location()); ok {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q (use a duration like 72h or 3d, a date like 2006-01-02, or RFC 3339)", s)
+}
+
+// ParseTime parses an absolute time: RFC 3339, "2006-01-02 15:04" or "2006-01-02"
+// (the start of the day), interpreted in loc
+func ParseTime(s string, loc *time.Location) (time.Time, error) {
+	if t, ok := parseTime(strings.TrimSpace(s), loc); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (use a date like 2006-01-02, a time like 2006-01-02 15:04, or RFC 3339)", s)
+}
+
+func parseTime(s string, loc *time.Location) (time.Time, bool) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02T15:04", time.DateOnly} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// versionPattern matches the first dotted version number in a string (e.g., "Composer version 2.7.1")
+var versionPattern = regexp.MustCompile(`\d+(?:\.\d+)+`)
+
+// ExtractVersion returns the first dotted version number found in s, or an empty string
+func ExtractVersion(s string) string {
+	return versionPattern.FindString(s)
+}
+
+// CompareVersions compares two dotted version strings numerically.
+// Returns -1 if a < b, 0 if a == b, and 1 if a > b.
+// Missing components are treated as zero, so "2.4" equals "2.4.0".
+func CompareVersions(a, b string) int {
+	aParts := strings.Split(ExtractVersion(a), ".")
+	bParts := strings.Split(ExtractVersion(b), ".")
+
+	for i := 0; i < len(aParts) || i < len(bParts); i++ {
+		var av, bv int
+		if i < len(aParts) {
+			av, _ = strconv.Atoi(aParts[i])
+		}
+		if i < len(bParts) {
+			bv, _ = strconv.Atoi(bParts[i])
+		}
+		if av < bv {
+			return -1
+		}
+		if av > bv {
+			return 1
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,141 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"gorm.io/gorm"
+)
+
+// claimTries bounds the jobs a Claim tries to take from other workers' hands
+const claimTries = 5
+
+// EmbeddedQueue keeps the jobs in the jobs table of the audit database. It needs no
+// other service, but only the processes sharing the database share the queue.
+type EmbeddedQueue struct {
+	db *gorm.DB
+}
+
+// NewEmbeddedQueue creates a new EmbeddedQueue storing the jobs in db
+func NewEmbeddedQueue(db *gorm.DB) *EmbeddedQueue {
+	return &EmbeddedQueue{db: db}
+}
+
+// Enqueue adds a job
+func (q *EmbeddedQueue) Enqueue(ctx context.Context, job *models.Job) error {
+	newJob(job, helpers.MustNewULID(), time.Now())
+	return q.db.WithContext(ctx).Create(job).Error
+}
+
+// Claim marks the next due job as running and returns it. The job is only claimed if
+// it is still queued, so that two workers never run the same job.
+func (q *EmbeddedQueue) Claim(ctx context.Context) (*models.Job, error) {
+	db := q.db.WithContext(ctx)
+
+	for range claimTries {
+		now := time.Now()
+
+		var job models.Job
+		err := db.Where("status = ? AND julianday(run_at) <= julianday(?)", models.JobQueued, now).
+			Order("run_at, id").
+			First(&job).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		claim(&job, now)
+		result := db.Model(&models.Job{}).
+			Where("id = ? AND status = ?", job.ID, models.JobQueued).
+			Updates(map[string]any{
+				"status":     job.Status,
+				"attempts":   job.Attempts,
+				"started_at": job.StartedAt,
+			})
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 1 {
+			return &job, nil
+		}
+		// Claimed by another worker in between: try the next one
+	}
+	return nil, nil
+}
+
+// Update saves a claimed job
+func (q *EmbeddedQueue) Update(ctx context.Context, job *models.Job) error {
+	return q.db.WithContext(ctx).Save(job).Error
+}
+
+// Get returns a job by ID
+func (q *EmbeddedQueue) Get(ctx context.Context, id string) (*models.Job, error) {
+	var job models.Job
+	err := q.db.WithContext(ctx).Where("id = ?", id).First(&job).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// List returns the jobs matching filter, newest first
+func (q *EmbeddedQueue) List(ctx context.Context, filter Filter) ([]models.Job, int64, error) {
+	query := q.db.WithContext(ctx).Model(&models.Job{})
+	if len(filter.Statuses) > 0 {
+		query = query.Where("status IN ?", filter.Statuses)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.AppName != "" {
+		query = query.Where("app_name = ?", filter.AppName)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	query = query.Order("created_at DESC, id DESC").Offset(filter.Offset)
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var jobs []models.Job
+	if err := query.Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// Purge deletes the jobs that finished before cutoff
+func (q *EmbeddedQueue) Purge(ctx context.Context, cutoff time.Time) error {
+	return q.db.WithContext(ctx).
+		Where("status IN ? AND julianday(finished_at) < julianday(?)", []string{models.JobSucceeded, models.JobFailed}, cutoff).
+		Delete(&models.Job{}).Error
+}
+
+// Recover queues again the jobs left running, e.g. by a server that was killed, without
+// counting their interrupted attempt. Only call it when no worker of the database runs.
+func (q *EmbeddedQueue) Recover(ctx context.Context) (int64, error) {
+	result := q.db.WithContext(ctx).Model(&models.Job{}).
+		Where("status = ?", models.JobRunning).
+		Updates(map[string]any{
+			"status":   models.JobQueued,
+			"attempts": gorm.Expr("MAX(attempts - 1, 0)"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// Close does nothing: the database belongs to the caller
+func (q *EmbeddedQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"gorm.io/gorm"
+)
+
+// Retention is how long finished jobs are kept
+const Retention = 30 * 24 * time.Hour
+
+// ErrNotFound is returned for jobs that do not exist, or were purged
+var ErrNotFound = errors.New("job not found")
+
+// Queue stores the jobs of the serve mode's workers. A job is claimed by one worker
+// only, also when several servers share the queue.
+type Queue interface {
+	// Enqueue adds a job, due at its RunAt (now when zero), and sets its ID
+	Enqueue(ctx context.Context, job *models.Job) error
+
+	// Claim marks the next due job as running and returns it; nil when none is due
+	Claim(ctx context.Context) (*models.Job, error)
+
+	// Update saves a claimed job: a queued job is due again at its RunAt
+	Update(ctx context.Context, job *models.Job) error
+
+	// Get returns a job by ID
+	Get(ctx context.Context, id string) (*models.Job, error)
+
+	// List returns the jobs matching filter, newest first, and how many match in total
+	List(ctx context.Context, filter Filter) ([]models.Job, int64, error)
+
+	// Purge deletes the jobs that finished before cutoff
+	Purge(ctx context.Context, cutoff time.Time) error
+
+	// Close releases the queue's connection
+	Close() error
+}
+
+// Filter selects the jobs to list. Empty fields match every job.
+type Filter struct {
+	Statuses []string
+	Type     string
+	AppName  string
+	Offset   int
+	Limit    int // 0 for all
+}
+
+// Matches returns true if the filter selects job, ignoring Offset and Limit
+func (f Filter) Matches(job models.Job) bool {
+	if len(f.Statuses) > 0 && !slices.Contains(f.Statuses, job.Status) {
+		return false
+	}
+	if f.Type != "" && job.Type != f.Type {
+		return false
+	}
+	return f.AppName == "" || job.AppName == f.AppName
+}
+
+// Open opens the queue configured by JOB_QUEUE: jobs in db, or in Redis
+func Open(cfg *config.Config, db *gorm.DB) (Queue, error) {
+	switch cfg.JobQueue {
+	case config.JobQueueEmbedded:
+		return NewEmbeddedQueue(db), nil
+	case config.JobQueueRedis:
+		return NewRedisQueue(cfg.RedisURL)
+	default:
+		return nil, fmt.Errorf("the job queue is off: set JOB_QUEUE to embedded or redis")
+	}
+}
+
+// newJob fills in the fields of a job about to be enqueued
+func newJob(job *models.Job, id string, now time.Time) {
+	job.ID = id
+	job.Status = models.JobQueued
+	job.CreatedAt = now
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = 1
+	}
+}
+
+// claim marks a job as running for its next attempt
+func claim(job *models.Job, now time.Time) {
+	job.Status = models.JobRunning
+	job.Attempts++
+	job.StartedAt = &now
+}
@@ -0,0 +1,253 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Redis keys of the queue
+const (
+	redisKeyPrefix  = "audit-checks:jobs:"
+	redisJobKey     = redisKeyPrefix + "job:"    // + ID: the job as JSON
+	redisQueuedKey  = redisKeyPrefix + "queued"  // sorted set of queued IDs, by RunAt
+	redisRunningKey = redisKeyPrefix + "running" // sorted set of claimed IDs, by the deadline of their lease
+	redisIndexKey   = redisKeyPrefix + "index"   // sorted set of every ID, by CreatedAt
+)
+
+// redisJobsKept bounds the jobs listed, the oldest finished ones are deleted
+const redisJobsKept = 1000
+
+// redisLease is how long a claim lasts unless renewed. Workers renew it every
+// leaseRenewal while the job runs, so it only expires when its server is gone.
+const redisLease = 5 * time.Minute
+
+// redisClaimScript queues again the jobs whose lease expired, without counting their
+// interrupted attempt, then moves the next due job to the running set with a lease
+// and marks it as running. KEYS: queued, running. ARGV: now in milliseconds, deadline
+// of the lease in milliseconds, job key prefix, now in RFC 3339, claim tries.
+const redisClaimScript = `
+for _, id in ipairs(redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])) do
+	redis.call('ZREM', KEYS[2], id)
+	local data = redis.call('GET', ARGV[3] .. id)
+	if data then
+		local job = cjson.decode(data)
+		if job.status == 'running' then
+			job.status = 'queued'
+			job.attempts = math.max(job.attempts - 1, 0)
+			redis.call('SET', ARGV[3] .. id, cjson.encode(job))
+			redis.call('ZADD', KEYS[1], ARGV[1], id)
+		end
+	end
+end
+
+for _, id in ipairs(redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, ARGV[5])) do
+	redis.call('ZREM', KEYS[1], id)
+	local data = redis.call('GET', ARGV[3] .. id)
+	if data then
+		local job = cjson.decode(data)
+		job.status = 'running'
+		job.attempts = job.attempts + 1
+		job.started_at = ARGV[4]
+		data = cjson.encode(job)
+		redis.call('SET', ARGV[3] .. id, data)
+		redis.call('ZADD', KEYS[2], ARGV[2], id)
+		return data
+	end
+end
+return false
+`
+
+// redisTrimScript deletes the oldest finished jobs beyond the jobs kept, with their
+// index entries. Queued and running jobs stay until they finish. KEYS: index. ARGV:
+// jobs kept, job key prefix.
+const redisTrimScript = `
+for _, id in ipairs(redis.call('ZRANGE', KEYS[1], 0, -tonumber(ARGV[1]) - 1)) do
+	local data = redis.call('GET', ARGV[2] .. id)
+	local status = data and cjson.decode(data).status
+	if status ~= 'queued' and status ~= 'running' then
+		redis.call('DEL', ARGV[2] .. id)
+		redis.call('ZREM', KEYS[1], id)
+	end
+end
+return 0
+`
+
+// RedisQueue keeps the jobs in Redis, so that servers on several hosts share the
+// queue. Jobs are JSON strings; finished jobs expire after Retention. A claim is a
+// lease: the jobs of a server that was killed are queued again once it expires.
+type RedisQueue struct {
+	client *redisClient
+}
+
+// NewRedisQueue creates a new RedisQueue of the server at rawURL (REDIS_URL). The
+// connection is opened by the first command.
+func NewRedisQueue(rawURL string) (*RedisQueue, error) {
+	client, err := newRedisClient(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &RedisQueue{client: client}, nil
+}
+
+// Enqueue adds a job
+func (q *RedisQueue) Enqueue(ctx context.Context, job *models.Job) error {
+	newJob(job, helpers.MustNewULID(), time.Now())
+
+	if err := q.save(ctx, job); err != nil {
+		return err
+	}
+	if _, err := q.client.Do(ctx, "ZADD", redisIndexKey, score(job.CreatedAt), job.ID); err != nil {
+		return err
+	}
+	if _, err := q.client.Do(ctx, "EVAL", redisTrimScript, "1", redisIndexKey, strconv.Itoa(redisJobsKept), redisJobKey); err != nil {
+		return err
+	}
+	_, err := q.client.Do(ctx, "ZADD", redisQueuedKey, score(job.RunAt), job.ID)
+	return err
+}
+
+// Claim marks the next due job as running and returns it, first queuing again the
+// jobs whose lease expired. The claim runs as a script, so that two workers never run
+// the same job.
+func (q *RedisQueue) Claim(ctx context.Context) (*models.Job, error) {
+	now := time.Now()
+	reply, err := q.client.Do(ctx, "EVAL", redisClaimScript, "2", redisQueuedKey, redisRunningKey,
+		score(now), score(now.Add(redisLease)), redisJobKey, now.Format(time.RFC3339Nano), strconv.Itoa(claimTries))
+	if err != nil {
+		return nil, err
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return nil, nil
+	}
+
+	var job models.Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job: %w", err)
+	}
+	return &job, nil
+}
+
+// Renew extends the lease of a running job
+func (q *RedisQueue) Renew(ctx context.Context, job *models.Job) error {
+	_, err := q.client.Do(ctx, "ZADD", redisRunningKey, "XX", score(time.Now().Add(redisLease)), job.ID)
+	return err
+}
+
+// Update saves a claimed job, ending its lease
+func (q *RedisQueue) Update(ctx context.Context, job *models.Job) error {
+	if err := q.save(ctx, job); err != nil {
+		return err
+	}
+	if _, err := q.client.Do(ctx, "ZREM", redisRunningKey, job.ID); err != nil {
+		return err
+	}
+	if job.Status != models.JobQueued {
+		return nil
+	}
+	_, err := q.client.Do(ctx, "ZADD", redisQueuedKey, score(job.RunAt), job.ID)
+	return err
+}
+
+// Get returns a job by ID
+func (q *RedisQueue) Get(ctx context.Context, id string) (*models.Job, error) {
+	reply, err := q.client.Do(ctx, "GET", redisJobKey+id)
+	if err != nil {
+		return nil, err
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	var job models.Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return nil, fmt.Errorf("failed to decode job %s: %w", id, err)
+	}
+	return &job, nil
+}
+
+// List returns the jobs matching filter, newest first. Only the last redisJobsKept
+// jobs are listed.
+func (q *RedisQueue) List(ctx context.Context, filter Filter) ([]models.Job, int64, error) {
+	reply, err := q.client.Do(ctx, "ZREVRANGE", redisIndexKey, "0", "-1")
+	if err != nil {
+		return nil, 0, err
+	}
+	ids, _ := reply.([]any)
+	if len(ids) == 0 {
+		return nil, 0, nil
+	}
+
+	keys := make([]string, 0, len(ids)+1)
+	keys = append(keys, "MGET")
+	for _, id := range ids {
+		id, _ := id.(string)
+		keys = append(keys, redisJobKey+id)
+	}
+	reply, err = q.client.Do(ctx, keys...)
+	if err != nil {
+		return nil, 0, err
+	}
+	values, _ := reply.([]any)
+
+	var matching []models.Job
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue // expired
+		}
+		var job models.Job
+		if err := json.Unmarshal([]byte(data), &job); err != nil {
+			continue
+		}
+		if filter.Matches(job) {
+			matching = append(matching, job)
+		}
+	}
+
+	total := int64(len(matching))
+	matching = matching[min(filter.Offset, len(matching)):]
+	if filter.Limit > 0 && len(matching) > filter.Limit {
+		matching = matching[:filter.Limit]
+	}
+	return matching, total, nil
+}
+
+// Purge drops the jobs created before cutoff from the index; finished jobs expire by
+// themselves
+func (q *RedisQueue) Purge(ctx context.Context, cutoff time.Time) error {
+	_, err := q.client.Do(ctx, "ZREMRANGEBYSCORE", redisIndexKey, "-inf", "("+score(cutoff))
+	return err
+}
+
+// Close closes the connection to Redis
+func (q *RedisQueue) Close() error {
+	return q.client.Close()
+}
+
+// save stores a job, expiring after Retention once finished
+func (q *RedisQueue) save(ctx context.Context, job *models.Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job %s: %w", job.ID, err)
+	}
+
+	args := []string{"SET", redisJobKey + job.ID, string(data)}
+	if job.Finished() {
+		args = append(args, "EX", strconv.Itoa(int(Retention.Seconds())))
+	}
+	_, err = q.client.Do(ctx, args...)
+	return err
+}
+
+// score returns the sorted set score of t, in milliseconds
+func score(t time.Time) string {
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
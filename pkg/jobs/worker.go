@@ -0,0 +1,199 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// pollInterval is how often idle workers look for due jobs
+	pollInterval = 2 * time.Second
+	// retryDelay is the delay before the second attempt of a job, doubled for each
+	// further attempt up to maxRetryDelay
+	retryDelay    = time.Minute
+	maxRetryDelay = time.Hour
+	// leaseRenewal is how often the lease of a running job is renewed, for the queues
+	// whose claims are leases
+	leaseRenewal = time.Minute
+)
+
+// leaser is a queue whose claims expire unless renewed while the job runs
+type leaser interface {
+	// Renew extends the lease of a running job
+	Renew(ctx context.Context, job *models.Job) error
+}
+
+// Handler runs a job. It may change the job's Payload, e.g. to only retry what failed;
+// the job is saved with it.
+type Handler func(ctx context.Context, job *models.Job) error
+
+// Worker runs the jobs of a queue with the handler of their type
+type Worker struct {
+	queue    Queue
+	handlers map[string]Handler
+}
+
+// NewWorker creates a new Worker of queue
+func NewWorker(queue Queue) *Worker {
+	return &Worker{
+		queue:    queue,
+		handlers: make(map[string]Handler),
+	}
+}
+
+// Handle sets the handler of the jobs of a type
+func (w *Worker) Handle(jobType string, handler Handler) {
+	w.handlers[jobType] = handler
+}
+
+// Run runs jobs with n concurrent workers until ctx is cancelled. The jobs running
+// then are queued again, without counting their interrupted attempt.
+func (w *Worker) Run(ctx context.Context, n int) {
+	log := helpers.Logger(ctx)
+
+	if err := w.queue.Purge(ctx, time.Now().Add(-Retention)); err != nil {
+		log.Warnf("Failed to purge finished jobs: %v", err)
+	}
+
+	log.Infof("Running jobs workers=%d", n)
+
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+
+	log.Info("Job workers stopped")
+}
+
+// loop claims and runs due jobs until ctx is cancelled
+func (w *Worker) loop(ctx context.Context) {
+	log := helpers.Logger(ctx)
+
+	for ctx.Err() == nil {
+		job, err := w.queue.Claim(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Errorf("Failed to claim job: %v", err)
+		}
+		if job != nil {
+			w.run(ctx, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// run runs a claimed job and saves its outcome
+func (w *Worker) run(ctx context.Context, job *models.Job) {
+	log := helpers.Logger(ctx).With("job_id", job.ID)
+
+	log.Infof("Running job type=%s app=%s attempt=%d/%d", job.Type, job.AppName, job.Attempts, job.MaxAttempts)
+
+	stopRenewal := w.renewLease(ctx, job)
+	err := w.handle(helpers.WithLogger(ctx, log), job)
+	stopRenewal()
+
+	now := time.Now()
+	switch {
+	case err != nil && ctx.Err() != nil:
+		// Interrupted by the shutdown: run again by the next server
+		log.Warnf("Job interrupted type=%s app=%s; queued again", job.Type, job.AppName)
+		job.Status = models.JobQueued
+		job.Attempts--
+		job.RunAt = now
+	case err != nil && job.Attempts < job.MaxAttempts:
+		job.Status = models.JobQueued
+		job.Error = err.Error()
+		job.RunAt = now.Add(backoff(job.Attempts))
+		log.Warnf("Job failed type=%s app=%s attempt=%d/%d, retrying at %s: %v",
+			job.Type, job.AppName, job.Attempts, job.MaxAttempts, job.RunAt.Format(time.RFC3339), err)
+	case err != nil:
+		job.Status = models.JobFailed
+		job.Error = err.Error()
+		job.FinishedAt = &now
+		log.Errorf("Job failed type=%s app=%s attempts=%d: %v", job.Type, job.AppName, job.Attempts, err)
+	default:
+		job.Status = models.JobSucceeded
+		job.Error = ""
+		job.FinishedAt = &now
+		log.Infof("Job succeeded type=%s app=%s duration=%s", job.Type, job.AppName, now.Sub(*job.StartedAt).Round(time.Second))
+	}
+
+	// Saved even when ctx was cancelled, so the job is not left running
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	if err := w.queue.Update(saveCtx, job); err != nil {
+		log.Errorf("Failed to save job status=%s: %v", job.Status, err)
+	}
+}
+
+// renewLease renews the lease of a running job every leaseRenewal, if the queue's
+// claims are leases, until the returned function is called
+func (w *Worker) renewLease(ctx context.Context, job *models.Job) func() {
+	queue, ok := w.queue.(leaser)
+	if !ok {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(leaseRenewal)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := queue.Renew(ctx, job); err != nil && ctx.Err() == nil {
+					helpers.Logger(ctx).Warnf("Failed to renew the lease of job_id=%s: %v", job.ID, err)
+				}
+			}
+		}
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// handle runs the handler of a job, turning a panic into an error so that one bad job
+// does not stop the server
+func (w *Worker) handle(ctx context.Context, job *models.Job) (err error) {
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		return fmt.Errorf("no handler for job type %q", job.Type)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			zap.S().Errorf("Job panicked job_id=%s type=%s: %v", job.ID, job.Type, r)
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// backoff returns the delay before the attempt after attempt
+func backoff(attempt int) time.Duration {
+	delay := retryDelay
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
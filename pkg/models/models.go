@@ -1,9 +1,15 @@
 package models
 
 import (
+	"cmp"
+	"crypto/sha256"
 	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/shadowbane/audit-checks/pkg/helpers"
@@ -77,8 +83,37 @@ type App struct {
 	EmailNotifications StringArray `gorm:"type:text" json:"email_notifications"`
 	TelegramEnabled    bool        `gorm:"default:false" json:"telegram_enabled"`
 	TelegramTopicID    int         `gorm:"default:0" json:"telegram_topic_id"`
+	DiscordEnabled     bool        `gorm:"default:false" json:"discord_enabled"`
+	DiscordChannelID   string      `gorm:"size:32" json:"discord_channel_id"` // own channel instead of a thread in the default one
+	DiscordThreadID    string      `gorm:"size:32" json:"discord_thread_id"`
+	MattermostEnabled  bool        `gorm:"default:false" json:"mattermost_enabled"`
+	MattermostChannel  string      `gorm:"size:64" json:"mattermost_channel"` // own channel instead of MATTERMOST_CHANNEL_ID (a channel name with a webhook)
+	WebhookURLs        StringArray `gorm:"type:text" json:"webhook_urls"`     // receive the app's reports as JSON, in addition to WEBHOOK_URLS
+	PagerDutyEnabled   bool        `gorm:"column:pagerduty_enabled;default:false" json:"pagerduty_enabled"`
+	PagerDutyDedupKey  string      `gorm:"column:pagerduty_dedup_key;size:255" json:"pagerduty_dedup_key"` // of the open incident, empty when none
+	OpsgenieEnabled    bool        `gorm:"default:false" json:"opsgenie_enabled"`
+	OpsgenieAliases    StringArray `gorm:"type:text" json:"opsgenie_aliases"` // of the open alerts
+	NtfyEnabled        bool        `gorm:"default:false" json:"ntfy_enabled"`
+	NtfyTopic          string      `gorm:"size:64" json:"ntfy_topic"` // own topic instead of <NTFY_TOPIC_PREFIX>-<app>
+	GotifyEnabled      bool        `gorm:"default:false" json:"gotify_enabled"`
+	ZulipEnabled       bool        `gorm:"default:false" json:"zulip_enabled"`
+	ZulipTopic         string      `gorm:"size:64" json:"zulip_topic"` // own topic in ZULIP_STREAM instead of the app name
+	GitLabEnabled      bool        `gorm:"column:gitlab_enabled;default:false" json:"gitlab_enabled"`
+	GitLabProject      string      `gorm:"column:gitlab_project;size:255" json:"gitlab_project"` // own project instead of GITLAB_PROJECT
+	GitLabIssue        string      `gorm:"column:gitlab_issue;size:300" json:"gitlab_issue"`     // <project>#<iid> of the open issue, empty when none
+	LastNotifiedAt     *time.Time  `json:"last_notified_at"`                                     // when the app's findings were last notified
+	LastNotifyFailed   StringArray `gorm:"type:text" json:"last_notify_failed"`                  // the channels that failed to send the last notification
 	IgnoreList         StringArray `gorm:"type:text" json:"ignore_list"`
+	IgnorePaths        StringArray `gorm:"type:text" json:"ignore_paths"`
+	MutedSeverities    StringArray `gorm:"type:text" json:"muted_severities"`                   // left out of notifications, still recorded and reported
+	AuditOptions       StringArray `gorm:"type:text" json:"audit_options"`                      // <auditor>.<key>=<value>, e.g. npm.before=2024-06-01
+	AIDisabled         bool        `gorm:"column:ai_disabled;default:false" json:"ai_disabled"` // no AI analysis of the app's results
+	AIModel            string      `gorm:"column:ai_model;size:100" json:"ai_model"`            // own Gemini model instead of GEMINI_MODEL
+	RetentionDays      int         `gorm:"default:0" json:"retention_days"`                     // own history retention instead of RETENTION_DAYS
+	Owner              string      `gorm:"index;size:100" json:"owner"`                         // team owning the app, shares run concurrency fairly
+	Tags               StringArray `gorm:"type:text" json:"tags"`                               // e.g. prod or pci, for policies
 	Enabled            bool        `gorm:"default:true" json:"enabled"`
+	PausedUntil        *time.Time  `json:"paused_until"` // temporarily skipped until this time
 	CreatedAt          time.Time   `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt          time.Time   `gorm:"autoUpdateTime" json:"updated_at"`
 }
@@ -98,22 +133,81 @@ func (a *App) ToAppConfig() AppConfig {
 		Path: a.Path,
 		Type: a.Type,
 		Notifications: NotificationConfig{
-			Email:           a.EmailNotifications,
-			TelegramEnabled: a.TelegramEnabled,
-			TelegramTopicID: a.TelegramTopicID,
-			AppName:         a.Name,
+			Email:             a.EmailNotifications,
+			TelegramEnabled:   a.TelegramEnabled,
+			TelegramTopicID:   a.TelegramTopicID,
+			DiscordEnabled:    a.DiscordEnabled,
+			DiscordChannelID:  a.DiscordChannelID,
+			DiscordThreadID:   a.DiscordThreadID,
+			MattermostEnabled: a.MattermostEnabled,
+			MattermostChannel: a.MattermostChannel,
+			Webhooks:          a.WebhookURLs,
+			PagerDutyEnabled:  a.PagerDutyEnabled,
+			PagerDutyDedupKey: a.PagerDutyDedupKey,
+			OpsgenieEnabled:   a.OpsgenieEnabled,
+			OpsgenieAliases:   a.OpsgenieAliases,
+			NtfyEnabled:       a.NtfyEnabled,
+			NtfyTopic:         a.NtfyTopic,
+			GotifyEnabled:     a.GotifyEnabled,
+			ZulipEnabled:      a.ZulipEnabled,
+			ZulipTopic:        a.ZulipTopic,
+			GitLabEnabled:     a.GitLabEnabled,
+			GitLabProject:     a.GitLabProject,
+			GitLabIssue:       a.GitLabIssue,
+			MutedSeverities:   a.MutedSeverities,
+			AppName:           a.Name,
 		},
-		Enabled:    a.Enabled,
-		IgnoreList: a.IgnoreList,
+		Enabled:       a.Enabled,
+		PausedUntil:   a.PausedUntil,
+		IgnoreList:    a.IgnoreList,
+		IgnorePaths:   a.IgnorePaths,
+		AuditOptions:  a.AuditOptions,
+		AIDisabled:    a.AIDisabled,
+		AIModel:       a.AIModel,
+		Owner:         a.Owner,
+		Tags:          a.Tags,
+		RetentionDays: a.RetentionDays,
 	}
 }
 
+// IsPaused returns true if the app is paused at the given time
+func (a *App) IsPaused(now time.Time) bool {
+	return a.PausedUntil != nil && now.Before(*a.PausedUntil)
+}
+
+// HasNotificationState returns true if notifying the app stored state: its Telegram
+// topic, Discord thread, open PagerDuty incident, Opsgenie alerts or GitLab issue, or
+// the status of its last notification
+func (a *App) HasNotificationState() bool {
+	return a.TelegramTopicID != 0 || a.DiscordThreadID != "" || a.PagerDutyDedupKey != "" ||
+		len(a.OpsgenieAliases) > 0 || a.GitLabIssue != "" || a.LastNotifiedAt != nil || len(a.LastNotifyFailed) > 0
+}
+
 // NotificationConfig holds notification settings for an app
 type NotificationConfig struct {
-	Email           []string `json:"email"`
-	TelegramEnabled bool     `json:"telegram_enabled"`
-	TelegramTopicID int      `json:"telegram_topic_id"`
-	AppName         string   `json:"app_name"`
+	Email             []string `json:"email"`
+	TelegramEnabled   bool     `json:"telegram_enabled"`
+	TelegramTopicID   int      `json:"telegram_topic_id"`
+	DiscordEnabled    bool     `json:"discord_enabled"`
+	DiscordChannelID  string   `json:"discord_channel_id"`
+	DiscordThreadID   string   `json:"discord_thread_id"`
+	MattermostEnabled bool     `json:"mattermost_enabled"`
+	MattermostChannel string   `json:"mattermost_channel"`
+	Webhooks          []string `json:"webhooks"`
+	PagerDutyEnabled  bool     `json:"pagerduty_enabled"`
+	PagerDutyDedupKey string   `json:"pagerduty_dedup_key"`
+	OpsgenieEnabled   bool     `json:"opsgenie_enabled"`
+	OpsgenieAliases   []string `json:"opsgenie_aliases"`
+	NtfyEnabled       bool     `json:"ntfy_enabled"`
+	NtfyTopic         string   `json:"ntfy_topic"`
+	GotifyEnabled     bool     `json:"gotify_enabled"`
+	ZulipEnabled      bool     `json:"zulip_enabled"`
+	ZulipTopic        string   `json:"zulip_topic"`
+	GitLabEnabled     bool     `json:"gitlab_enabled"`
+	GitLabProject     string   `json:"gitlab_project"`
+	GitLabIssue       string   `json:"gitlab_issue"`
+	MutedSeverities   []string `json:"muted_severities"` // left out of notifications
+	AppName           string   `json:"app_name"`
 }
 
 // AppConfig represents configuration for an app to audit (in-memory)
@@ -123,7 +217,20 @@ type AppConfig struct {
 	Type          string             `json:"type"` // npm, composer, auto
 	Notifications NotificationConfig `json:"notifications"`
 	Enabled       bool               `json:"enabled"`
-	IgnoreList    []string           `json:"ignore_list,omitempty"` // CVEs or package names to ignore
+	PausedUntil   *time.Time         `json:"paused_until,omitempty"`
+	IgnoreList    []string           `json:"ignore_list,omitempty"`    // CVEs or package names to ignore
+	IgnorePaths   []string           `json:"ignore_paths,omitempty"`   // app-relative directories whose findings are ignored
+	AuditOptions  []string           `json:"audit_options,omitempty"`  // per-app auditor tool options, override the global ones
+	AIDisabled    bool               `json:"ai_disabled,omitempty"`    // no AI analysis of the app's results
+	AIModel       string             `json:"ai_model,omitempty"`       // own Gemini model instead of GEMINI_MODEL
+	Owner         string             `json:"owner,omitempty"`          // team owning the app
+	Tags          []string           `json:"tags,omitempty"`           // e.g. prod or pci, for policies
+	RetentionDays int                `json:"retention_days,omitempty"` // own history retention instead of RETENTION_DAYS
+}
+
+// IsPaused returns true if the app is paused at the given time
+func (a AppConfig) IsPaused(now time.Time) bool {
+	return a.PausedUntil != nil && now.Before(*a.PausedUntil)
 }
 
 // Setting represents a configuration setting stored in database
@@ -136,16 +243,24 @@ type Setting struct {
 // AuditResult represents a single audit run result (GORM model)
 type AuditResult struct {
 	ID                   string          `gorm:"primaryKey;size:26" json:"id"`
+	RunID                string          `gorm:"index;size:26" json:"run_id,omitempty"`
 	AppName              string          `gorm:"index;size:255" json:"app_name"`
 	AppPath              string          `gorm:"size:1024" json:"app_path"`
 	AuditorType          string          `gorm:"size:50" json:"auditor_type"`
+	ToolVersion          string          `gorm:"size:50" json:"tool_version,omitempty"`
 	TotalVulnerabilities int             `json:"total_vulnerabilities"`
 	CriticalCount        int             `json:"critical_count"`
 	HighCount            int             `json:"high_count"`
 	ModerateCount        int             `json:"moderate_count"`
 	LowCount             int             `json:"low_count"`
-	RawOutput            string          `gorm:"type:text" json:"raw_output,omitempty"`
+	InfoCount            int             `json:"info_count"`
+	RawOutput            string          `gorm:"-" json:"raw_output,omitempty"`                  // stored once per content, in raw_outputs
+	RawOutputHash        string          `gorm:"index;size:64" json:"raw_output_hash,omitempty"` // hash of the raw output in raw_outputs
+	RawOutputFile        string          `gorm:"size:1024" json:"raw_output_file,omitempty"`     // the whole raw output, when RawOutput was cut (RAW_OUTPUT_MAX_SIZE)
+	Questionable         string          `gorm:"type:text" json:"questionable,omitempty"`        // why the result may be a false negative
 	AISummary            string          `gorm:"type:text" json:"ai_summary,omitempty"`
+	AIProvenance         *AIProvenance   `gorm:"embedded;embeddedPrefix:ai_" json:"ai_provenance,omitempty"`
+	LogFile              string          `gorm:"size:1024" json:"log_file,omitempty"` // the run's log file (RUN_LOG_ENABLED)
 	CreatedAt            time.Time       `gorm:"autoCreateTime" json:"created_at"`
 	Vulnerabilities      []Vulnerability `gorm:"foreignKey:AuditResultID" json:"vulnerabilities,omitempty"`
 }
@@ -158,12 +273,37 @@ func (a *AuditResult) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// RawOutput is the raw output of an auditor, stored once however many audit results
+// have it: unchanged apps produce byte-identical output audit after audit
+type RawOutput struct {
+	Hash      string    `gorm:"primaryKey;size:64" json:"hash"` // RawOutputHash of Content
+	Content   string    `gorm:"type:text" json:"content"`
+	Size      int       `json:"size"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// CachedAIAnalysis is an AI analysis of an app's audit result, reused by the later
+// audits reporting the same findings until AI_CACHE_TTL
+type CachedAIAnalysis struct {
+	Key       string    `gorm:"primaryKey;size:64" json:"key"` // hash of the app, its analyzers and the result's findings
+	AppName   string    `gorm:"index;size:255" json:"app_name"`
+	Analysis  string    `gorm:"type:text" json:"analysis"` // the AIAnalysis, in JSON
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+// RawOutputHash returns the hash a raw output is stored under: its SHA-256, in hex
+func RawOutputHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
 // UpdateCounts updates the severity counts based on vulnerabilities
 func (a *AuditResult) UpdateCounts() {
 	a.CriticalCount = 0
 	a.HighCount = 0
 	a.ModerateCount = 0
 	a.LowCount = 0
+	a.InfoCount = 0
 	a.TotalVulnerabilities = len(a.Vulnerabilities)
 
 	for _, v := range a.Vulnerabilities {
@@ -176,6 +316,8 @@ func (a *AuditResult) UpdateCounts() {
 			a.ModerateCount++
 		case SeverityLow:
 			a.LowCount++
+		case SeverityInfo:
+			a.InfoCount++
 		}
 	}
 }
@@ -187,18 +329,20 @@ func (a *AuditResult) HasVulnerabilities() bool {
 
 // Vulnerability represents a single vulnerability (GORM model)
 type Vulnerability struct {
-	ID                 string    `gorm:"primaryKey;size:26" json:"id"`
-	AuditResultID      string    `gorm:"index;size:26" json:"audit_result_id"`
-	PackageName        string    `gorm:"size:255" json:"package_name"`
-	Severity           string    `gorm:"index;size:20" json:"severity"`
-	CVEID              string    `gorm:"column:cve_id;size:50" json:"cve_id,omitempty"`
-	Title              string    `gorm:"size:512" json:"title"`
-	Description        string    `gorm:"type:text" json:"description,omitempty"`
-	Recommendation     string    `gorm:"type:text" json:"recommendation,omitempty"`
-	VulnerableVersions string    `gorm:"column:vulnerable_versions;size:255" json:"vulnerable_versions,omitempty"`
-	PatchedVersions    string    `gorm:"size:255" json:"patched_versions,omitempty"`
-	URL                string    `gorm:"size:1024" json:"url,omitempty"`
-	CreatedAt          time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID                 string     `gorm:"primaryKey;size:26" json:"id"`
+	AuditResultID      string     `gorm:"index;size:26" json:"audit_result_id"`
+	PackageName        string     `gorm:"size:255" json:"package_name"`
+	Severity           string     `gorm:"index;size:20" json:"severity"`
+	CVEID              string     `gorm:"column:cve_id;size:50" json:"cve_id,omitempty"`
+	Title              string     `gorm:"size:512" json:"title"`
+	Description        string     `gorm:"type:text" json:"description,omitempty"`
+	Recommendation     string     `gorm:"type:text" json:"recommendation,omitempty"`
+	VulnerableVersions string     `gorm:"column:vulnerable_versions;size:255" json:"vulnerable_versions,omitempty"`
+	PatchedVersions    string     `gorm:"size:255" json:"patched_versions,omitempty"`
+	URL                string     `gorm:"size:1024" json:"url,omitempty"`
+	FixSnippet         string     `gorm:"type:text" json:"fix_snippet,omitempty"` // manifest snippet pinning a patched version
+	FirstSeenAt        *time.Time `json:"first_seen_at,omitempty"`                // first audit of the app and auditor that reported the finding
+	CreatedAt          time.Time  `gorm:"autoCreateTime" json:"created_at"`
 }
 
 // BeforeCreate hook to generate ULID
@@ -209,12 +353,161 @@ func (v *Vulnerability) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// FindingKey identifies the finding a vulnerability reports across the audits of an
+// app and auditor: its package and CVE, or title without one
+func (v Vulnerability) FindingKey() string {
+	id := v.CVEID
+	if id == "" {
+		id = v.Title
+	}
+	return v.PackageName + "\x00" + id
+}
+
+// AgeDays returns the number of whole days since the finding was first seen, or -1
+// when that is unknown
+func (v Vulnerability) AgeDays(now time.Time) int {
+	if v.FirstSeenAt == nil {
+		return -1
+	}
+	return max(int(now.Sub(*v.FirstSeenAt).Hours()/24), 0)
+}
+
+// FirstSeenAgo describes when the finding was first seen, e.g. "first seen 12 days
+// ago"; empty when unknown
+func (v Vulnerability) FirstSeenAgo(now time.Time) string {
+	if v.FirstSeenAt == nil {
+		return ""
+	}
+	return "first seen " + daysAgo(v.AgeDays(now))
+}
+
+// FirstSeenLabel returns the date the finding was first seen and how long ago, e.g.
+// "2026-03-02 (12 days ago)"; empty when unknown
+func (v Vulnerability) FirstSeenLabel(now time.Time) string {
+	if v.FirstSeenAt == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", v.FirstSeenAt.UTC().Format("2006-01-02"), daysAgo(v.AgeDays(now)))
+}
+
+// daysAgo describes a number of days in the past
+func daysAgo(days int) string {
+	switch days {
+	case 0:
+		return "today"
+	case 1:
+		return "1 day ago"
+	default:
+		return fmt.Sprintf("%d days ago", days)
+	}
+}
+
+// Orders of the findings in reports and notifications
+const (
+	IssueOrderSeverity = "severity" // most severe first
+	IssueOrderAge      = "age"      // oldest first
+	IssueOrderRisk     = "risk"     // highest risk score first
+)
+
+// RiskScore weighs a finding's severity by how long it has been open: the weight
+// doubles with each severity level (1 for info to 16 for critical) and grows by one
+// for every 30 days, so a high open for a month ranks with a fresh critical
+func (v Vulnerability) RiskScore(now time.Time) float64 {
+	weight := float64(int(1) << SeverityOrder[v.Severity])
+	return weight * (1 + float64(max(v.AgeDays(now), 0))/30)
+}
+
+// SortVulnerabilities sorts vulnerabilities in order (IssueOrderSeverity when unknown),
+// breaking ties by severity, then package, CVE and title, so that the same findings are
+// always listed in the same order whatever order the auditor's output had
+func SortVulnerabilities(vulns []Vulnerability, order string, now time.Time) {
+	bySeverity := func(a, b Vulnerability) int {
+		return cmp.Or(
+			SeverityOrder[b.Severity]-SeverityOrder[a.Severity],
+			cmp.Compare(a.PackageName, b.PackageName),
+			cmp.Compare(a.CVEID, b.CVEID),
+			cmp.Compare(a.Title, b.Title),
+		)
+	}
+
+	switch order {
+	case IssueOrderAge:
+		slices.SortStableFunc(vulns, func(a, b Vulnerability) int {
+			if c := cmp.Compare(b.AgeDays(now), a.AgeDays(now)); c != 0 {
+				return c
+			}
+			return bySeverity(a, b)
+		})
+	case IssueOrderRisk:
+		slices.SortStableFunc(vulns, func(a, b Vulnerability) int {
+			if c := cmp.Compare(b.RiskScore(now), a.RiskScore(now)); c != 0 {
+				return c
+			}
+			return bySeverity(a, b)
+		})
+	default:
+		slices.SortStableFunc(vulns, bySeverity)
+	}
+}
+
 // AIAnalysis represents the Gemini analysis response
 type AIAnalysis struct {
-	Summary        string   `json:"summary"`
-	Priority       []string `json:"priority"`
-	Remediation    []string `json:"remediation"`
-	RiskAssessment string   `json:"risk_assessment"`
+	Summary        string        `json:"summary"`
+	Priority       []string      `json:"priority"`
+	Remediation    []string      `json:"remediation"`
+	RiskAssessment string        `json:"risk_assessment"`
+	Provenance     *AIProvenance `json:"provenance,omitempty"`
+}
+
+// AIProvenance tells what produced an AI analysis and what it cost, so that the output
+// of models and prompts can be audited and compared
+type AIProvenance struct {
+	Provider      string `gorm:"size:50" json:"provider"` // e.g. gemini
+	Model         string `gorm:"size:100" json:"model"`
+	ModelVersion  string `gorm:"size:50" json:"model_version,omitempty"` // as reported by the provider
+	PromptVersion string `gorm:"size:20" json:"prompt_version"`
+	LatencyMs     int64  `json:"latency_ms"`
+	PromptTokens  int    `json:"prompt_tokens"`
+	OutputTokens  int    `json:"output_tokens"`
+	Fallback      bool   `json:"fallback,omitempty"` // the answer could not be parsed, a basic analysis was used
+	Cached        bool   `json:"cached,omitempty"`   // reused from an analysis of the same findings, at no cost
+}
+
+// String describes the provenance, e.g. "gemini-2.5-flash (gemini, prompt v2) in 2.1s,
+// 1520 prompt + 310 output tokens"
+func (p *AIProvenance) String() string {
+	model := p.Model
+	if p.ModelVersion != "" {
+		model += " " + p.ModelVersion
+	}
+	if p.Cached {
+		return fmt.Sprintf("%s (%s, prompt v%s), cached", model, p.Provider, p.PromptVersion)
+	}
+	s := fmt.Sprintf("%s (%s, prompt v%s) in %.1fs, %d prompt + %d output tokens",
+		model, p.Provider, p.PromptVersion, float64(p.LatencyMs)/1000, p.PromptTokens, p.OutputTokens)
+	if p.Fallback {
+		s += ", unparsable answer replaced by a basic analysis"
+	}
+	return s
+}
+
+// FleetAnalysis is the Gemini analysis of the findings of all apps together, showing
+// what the analyses of each app cannot: recurring themes and packages vulnerable in
+// many apps, fixed best by one campaign across the fleet
+type FleetAnalysis struct {
+	Summary          string            `json:"summary"`
+	Themes           []string          `json:"themes"`
+	SystemicPackages []SystemicPackage `json:"systemic_packages"`
+	Campaigns        []string          `json:"campaigns"` // most effective first
+	Provenance       *AIProvenance     `json:"provenance,omitempty"`
+}
+
+// SystemicPackage is a package vulnerable in several apps
+type SystemicPackage struct {
+	Package  string   `json:"package"`
+	Severity string   `json:"severity"` // highest across the apps
+	Apps     []string `json:"apps"`
+	Note     string   `json:"note,omitempty"` // why it matters, from Gemini
 }
 
 // Report represents a complete audit report
@@ -235,6 +528,7 @@ type Summary struct {
 	High     int `json:"high"`
 	Moderate int `json:"moderate"`
 	Low      int `json:"low"`
+	Info     int `json:"info"`
 }
 
 // NewReport creates a new Report from an AuditResult
@@ -258,16 +552,62 @@ func (r *Report) GetSummary() Summary {
 		High:     r.AuditResult.HighCount,
 		Moderate: r.AuditResult.ModerateCount,
 		Low:      r.AuditResult.LowCount,
+		Info:     r.AuditResult.InfoCount,
 	}
 }
 
 // CombinedAppReport represents combined audit results from multiple auditors for a single app
 type CombinedAppReport struct {
-	AppName     string    `json:"app_name"`
-	AppPath     string    `json:"app_path"`
-	Reports     []*Report `json:"reports"`
-	ReportFiles []string  `json:"report_files"`
-	GeneratedAt time.Time `json:"generated_at"`
+	RunID       string         `json:"run_id,omitempty"`
+	AppName     string         `json:"app_name"`
+	AppPath     string         `json:"app_path"`
+	Reports     []*Report      `json:"reports"`
+	ReportFiles []string       `json:"report_files"`
+	Failures    []AuditFailure `json:"failures,omitempty"`
+	IssueOrder  string         `json:"issue_order,omitempty"` // order of the top issues (IssueOrderSeverity when empty)
+	GeneratedAt time.Time      `json:"generated_at"`
+
+	// PolicyViolations are the policies of POLICY_FILE the app violates
+	PolicyViolations []PolicyViolation `json:"policy_violations,omitempty"`
+}
+
+// AuditFailure describes an auditor that failed for an app, with a remediation hint
+type AuditFailure struct {
+	AuditorType string `json:"auditor_type"`
+	Kind        string `json:"kind"` // binary_missing, auth, network, parse, timeout, questionable or unknown
+	Message     string `json:"message"`
+	Hint        string `json:"hint"`
+}
+
+// Policy actions: what a violated policy does
+const (
+	PolicyActionFail = "fail" // fails the run, notifies and turns the app red on the status page
+	PolicyActionWarn = "warn" // notifies and turns the app yellow on the status page
+)
+
+// PolicyViolation is a policy of POLICY_FILE an app violates, with what violates it
+type PolicyViolation struct {
+	Policy      string   `json:"policy"`
+	Description string   `json:"description,omitempty"`
+	Action      string   `json:"action"` // fail or warn
+	AppName     string   `json:"app_name"`
+	Subjects    []string `json:"subjects,omitempty"` // the findings or dependencies violating it; empty for app policies
+	Error       string   `json:"error,omitempty"`    // why the policy could not be evaluated on the app
+}
+
+// Failing returns true if the violation fails the run
+func (v PolicyViolation) Failing() bool {
+	return v.Action == PolicyActionFail
+}
+
+// ExposureNotice tells an app's owners that the app installs a package affected by a
+// vulnerability; sent by `broadcast` without waiting for an audit
+type ExposureNotice struct {
+	VulnerabilityID string       `json:"vulnerability_id"` // CVE or advisory ID
+	Summary         string       `json:"summary,omitempty"`
+	Message         string       `json:"message,omitempty"` // from the operator, e.g. the remediation plan
+	AppName         string       `json:"app_name"`
+	Packages        []AppPackage `json:"packages"` // the exposed package versions
 }
 
 // NewCombinedAppReport creates a new CombinedAppReport
@@ -297,10 +637,74 @@ func (c *CombinedAppReport) GetCombinedSummary() Summary {
 		summary.High += s.High
 		summary.Moderate += s.Moderate
 		summary.Low += s.Low
+		summary.Info += s.Info
 	}
 	return summary
 }
 
+// AddFailure records an auditor that failed
+func (c *CombinedAppReport) AddFailure(failure AuditFailure) {
+	c.Failures = append(c.Failures, failure)
+}
+
+// HasFailures returns true if any auditor failed
+func (c *CombinedAppReport) HasFailures() bool {
+	return len(c.Failures) > 0
+}
+
+// HasPolicyViolations returns true if the app violates a policy
+func (c *CombinedAppReport) HasPolicyViolations() bool {
+	return len(c.PolicyViolations) > 0
+}
+
+// WithoutSeverities returns a copy of the combined report without the vulnerabilities
+// of the given severities, with the counts updated, for notifying an app that mutes
+// them. The report files still list every finding.
+func (c *CombinedAppReport) WithoutSeverities(severities []string) *CombinedAppReport {
+	if len(severities) == 0 {
+		return c
+	}
+	return c.Without(func(_ string, v Vulnerability) bool { return slices.Contains(severities, v.Severity) })
+}
+
+// WithoutSnoozed returns a copy of the combined report without the findings snoozed at
+// now by triages, with the counts updated, for notifying the app
+func (c *CombinedAppReport) WithoutSnoozed(triages []FindingTriage, now time.Time) *CombinedAppReport {
+	snoozed := make(map[[2]string]bool)
+	for _, t := range triages {
+		if t.IsSnoozed(now) {
+			snoozed[[2]string{t.AuditorType, t.FindingKey()}] = true
+		}
+	}
+	if len(snoozed) == 0 {
+		return c
+	}
+	return c.Without(func(auditorType string, v Vulnerability) bool { return snoozed[[2]string{auditorType, v.FindingKey()}] })
+}
+
+// Without returns a copy of the combined report without the vulnerabilities drop
+// returns true for, given the auditor that reported them, with the counts updated
+func (c *CombinedAppReport) Without(drop func(auditorType string, v Vulnerability) bool) *CombinedAppReport {
+	filtered := *c
+	filtered.Reports = make([]*Report, 0, len(c.Reports))
+	for _, r := range c.Reports {
+		result := *r.AuditResult
+		result.Vulnerabilities = nil
+		for _, v := range r.AuditResult.Vulnerabilities {
+			if !drop(r.AuditResult.AuditorType, v) {
+				result.Vulnerabilities = append(result.Vulnerabilities, v)
+			}
+		}
+		result.UpdateCounts()
+
+		report := *r
+		report.AuditResult = &result
+		report.Vulnerabilities = result.Vulnerabilities
+		filtered.Reports = append(filtered.Reports, &report)
+	}
+	return &filtered
+}
+
 // HasVulnerabilities returns true if any report has vulnerabilities
 func (c *CombinedAppReport) HasVulnerabilities() bool {
 	for _, r := range c.Reports {
@@ -313,6 +717,7 @@ func (c *CombinedAppReport) HasVulnerabilities() bool {
 
 // AuditSummary represents a summary across all audited apps
 type AuditSummary struct {
+	RunID                string         `json:"run_id,omitempty"`
 	TotalApps            int            `json:"total_apps"`
 	AppsWithVulns        int            `json:"apps_with_vulnerabilities"`
 	TotalVulnerabilities int            `json:"total_vulnerabilities"`
@@ -320,12 +725,25 @@ type AuditSummary struct {
 	HighCount            int            `json:"high_count"`
 	ModerateCount        int            `json:"moderate_count"`
 	LowCount             int            `json:"low_count"`
+	InfoCount            int            `json:"info_count"`
 	Results              []*AuditResult `json:"results"`
+	AIAnalysis           *FleetAnalysis `json:"ai_analysis,omitempty"`
 	GeneratedAt          time.Time      `json:"generated_at"`
+
+	// PolicyViolations are the policies of POLICY_FILE the audited apps violate
+	PolicyViolations []PolicyViolation `json:"policy_violations,omitempty"`
+	// PolicyErrors counts the violations of policies that could not be evaluated
+	PolicyErrors int `json:"policy_errors,omitempty"`
 }
 
 // NewAuditSummary creates a summary from multiple audit results
 func NewAuditSummary(results []*AuditResult) *AuditSummary {
+	// Apps by name rather than in the order their audits completed
+	results = slices.Clone(results)
+	slices.SortStableFunc(results, func(a, b *AuditResult) int {
+		return cmp.Or(cmp.Compare(a.AppName, b.AppName), cmp.Compare(a.AuditorType, b.AuditorType))
+	})
+
 	summary := &AuditSummary{
 		TotalApps:   len(results),
 		Results:     results,
@@ -341,17 +759,583 @@ func NewAuditSummary(results []*AuditResult) *AuditSummary {
 		summary.HighCount += r.HighCount
 		summary.ModerateCount += r.ModerateCount
 		summary.LowCount += r.LowCount
+		summary.InfoCount += r.InfoCount
 	}
 
 	return summary
 }
 
+// ExecutiveReport is the fleet-wide report for management: trends, SLA compliance,
+// top offenders and notable new advisories over a period (usually a week)
+type ExecutiveReport struct {
+	PeriodStart   time.Time       `json:"period_start"`
+	PeriodEnd     time.Time       `json:"period_end"`
+	GeneratedAt   time.Time       `json:"generated_at"`
+	TotalApps     int             `json:"total_apps"`
+	AppsAudited   int             `json:"apps_audited"` // apps audited during the period
+	Audits        int             `json:"audits"`       // auditor runs during the period
+	Current       Summary         `json:"current"`      // open findings at the end of the period
+	Previous      Summary         `json:"previous"`     // open findings at the start of the period
+	SLA           []SLACompliance `json:"sla"`
+	TopOffenders  []AppRisk       `json:"top_offenders"`
+	NewAdvisories []Advisory      `json:"new_advisories"`
+	Overdue       []OpenFinding   `json:"overdue"`
+	AIAnalysis    *FleetAnalysis  `json:"ai_analysis,omitempty"` // of the open findings, with GEMINI_FLEET_ANALYSIS
+}
+
+// SLACompliance is the share of open findings of a severity that are younger than its SLA
+type SLACompliance struct {
+	Severity  string `json:"severity" yaml:"severity"`
+	SLADays   int    `json:"sla_days" yaml:"sla_days"`
+	Open      int    `json:"open" yaml:"open"`
+	WithinSLA int    `json:"within_sla" yaml:"within_sla"`
+	Overdue   int    `json:"overdue" yaml:"overdue"`
+}
+
+// Percent returns the compliance percentage (100 when nothing is open)
+func (s SLACompliance) Percent() float64 {
+	if s.Open == 0 {
+		return 100
+	}
+	return float64(s.WithinSLA) * 100 / float64(s.Open)
+}
+
+// AppRisk is an app's open findings at the end and start of a period
+type AppRisk struct {
+	AppName  string  `json:"app_name"`
+	Current  Summary `json:"current"`
+	Previous Summary `json:"previous"`
+}
+
+// Advisory is an advisory first seen during a period, with the apps it affects
+type Advisory struct {
+	ID          string    `json:"id"`
+	PackageName string    `json:"package_name"`
+	Severity    string    `json:"severity"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url,omitempty"`
+	Apps        []string  `json:"apps"`
+	FirstSeen   time.Time `json:"first_seen"`
+}
+
+// OpenFinding is a finding that is still open, with the time it was first seen
+type OpenFinding struct {
+	AppName     string    `json:"app_name"`
+	PackageName string    `json:"package_name"`
+	Severity    string    `json:"severity"`
+	CVEID       string    `json:"cve_id,omitempty"`
+	Title       string    `json:"title"`
+	FirstSeen   time.Time `json:"first_seen"`
+	AgeDays     int       `json:"age_days"`
+	SLADays     int       `json:"sla_days"`
+}
+
+// Scorecard is an app's security scorecard for service catalogs such as Backstage,
+// shaped like a catalog entity so catalog processors can ingest it as is
+type Scorecard struct {
+	APIVersion string            `json:"apiVersion" yaml:"apiVersion"`
+	Kind       string            `json:"kind" yaml:"kind"`
+	Metadata   ScorecardMetadata `json:"metadata" yaml:"metadata"`
+	Spec       ScorecardSpec     `json:"spec" yaml:"spec"`
+}
+
+// ScorecardMetadata identifies the app of a scorecard
+type ScorecardMetadata struct {
+	Name string `json:"name" yaml:"name"`
+}
+
+// ScorecardSpec is the security posture of an app from its latest audits
+type ScorecardSpec struct {
+	GeneratedAt     time.Time    `json:"generated_at" yaml:"generated_at"`
+	Status          string       `json:"status" yaml:"status"` // ok, vulnerable or not_audited
+	Enabled         bool         `json:"enabled" yaml:"enabled"`
+	Owners          []string     `json:"owners,omitempty" yaml:"owners,omitempty"` // the app's email recipients
+	LastAuditAt     *time.Time   `json:"last_audit_at,omitempty" yaml:"last_audit_at,omitempty"`
+	Auditors        []string     `json:"auditors,omitempty" yaml:"auditors,omitempty"`
+	Vulnerabilities Summary      `json:"vulnerabilities" yaml:"vulnerabilities"`
+	SLA             ScorecardSLA `json:"sla" yaml:"sla"`
+}
+
+// ScorecardSLA is an app's compliance with the remediation SLA (SLA_DAYS)
+type ScorecardSLA struct {
+	Status         string          `json:"status" yaml:"status"` // met or breached
+	Overdue        int             `json:"overdue" yaml:"overdue"`
+	OldestOpenDays int             `json:"oldest_open_days" yaml:"oldest_open_days"`
+	BySeverity     []SLACompliance `json:"by_severity" yaml:"by_severity"`
+}
+
+// Site is the audit history rendered as a static website (report site)
+type Site struct {
+	GeneratedAt time.Time
+	Apps        []SiteApp
+	Current     Summary      // open findings of every app
+	Trend       []TrendPoint // open findings of every app at the end of each day with audits
+}
+
+// SiteApp is the page of an app on the site
+type SiteApp struct {
+	Name        string
+	Owner       string
+	Enabled     bool
+	LastAuditAt *time.Time
+	Audits      int      // audit results in the history
+	Auditors    []string // of its latest audits
+	Current     Summary
+	Trend       []TrendPoint
+	Findings    []SiteFinding // of its latest audits, most severe and oldest first
+}
+
+// SiteFinding is an open finding of an app on the site
+type SiteFinding struct {
+	OpenFinding
+	AuditorType string
+	URL         string
+}
+
+// TrendPoint counts the open findings at the end of a day
+type TrendPoint struct {
+	Day     time.Time
+	Summary Summary
+}
+
+// Scorecard statuses
+const (
+	ScorecardStatusOK         = "ok"
+	ScorecardStatusVulnerable = "vulnerable"
+	ScorecardStatusNotAudited = "not_audited"
+	ScorecardSLAMet           = "met"
+	ScorecardSLABreached      = "breached"
+)
+
+// DataPurge is the deletion of an app's history created before a date (app purge-data,
+// RETENTION_DAYS): the options of the purge, and what it deleted
+type DataPurge struct {
+	AppName    string    `json:"app_name"`
+	Before     time.Time `json:"before"`
+	KeepLatest bool      `json:"keep_latest,omitempty"` // keep the latest result of each auditor and the state applying to it, for retention
+	DryRun     bool      `json:"dry_run,omitempty"`     // count what would be deleted, delete nothing
+
+	AuditResults      int64    `json:"audit_results"`
+	Vulnerabilities   int64    `json:"vulnerabilities"`
+	RawOutputs        int64    `json:"raw_outputs"` // stored outputs no remaining result has
+	RunEvents         int64    `json:"run_events"`
+	EmailDeliveries   int64    `json:"email_deliveries"`
+	AIAnalyses        int64    `json:"ai_analyses"`  // cached AI analyses
+	AppPackages       int64    `json:"app_packages"` // the package inventory of the app's last audit
+	Triages           int64    `json:"triages"`
+	Activities        int64    `json:"activities"` // activity log entries
+	Jobs              int64    `json:"jobs"`       // finished jobs, whose payloads hold reports
+	EmailSuppressions int64    `json:"email_suppressions"`
+	NotificationState bool     `json:"notification_state"`       // the app's Telegram topic, Discord thread, PagerDuty incident, Opsgenie alerts and GitLab issue were forgotten
+	Files             []string `json:"files"`                    // report, raw output and run log files deleted
+	RedactedFiles     []string `json:"redacted_files,omitempty"` // run logs shared with other apps, the app's lines removed
+
+	// What the purge keeps
+	KeptFiles             []string `json:"kept_files,omitempty"`              // raw output files other results still refer to, run logs the app's kept results refer to
+	KeptJobs              int64    `json:"kept_jobs,omitempty"`               // queued or running, left to finish
+	KeptNotificationState bool     `json:"kept_notification_state,omitempty"` // the app was notified since Before, or KeepLatest
+}
+
+// Empty returns true if the purge deleted nothing
+func (p *DataPurge) Empty() bool {
+	return p.AuditResults == 0 && p.RunEvents == 0 && p.EmailDeliveries == 0 && p.AIAnalyses == 0 &&
+		p.AppPackages == 0 && p.Triages == 0 && p.Activities == 0 && p.Jobs == 0 && p.EmailSuppressions == 0 &&
+		!p.NotificationState && len(p.Files) == 0 && len(p.RedactedFiles) == 0
+}
+
+// Feed is the Atom feed of the findings first seen in the latest audits (report feed)
+type Feed struct {
+	Title     string
+	URL       string // where the feed is published, empty when unknown
+	UpdatedAt time.Time
+	Entries   []FeedEntry // newest first
+}
+
+// FeedEntry is an app's findings first seen in a run
+type FeedEntry struct {
+	RunID     string // or the ID of the audit result, for audits made outside of a run
+	AppName   string
+	UpdatedAt time.Time // of the run's last audit of the app
+	Summary   Summary
+	Findings  []FeedFinding // most severe first
+}
+
+// FeedFinding is a finding first seen in a run
+type FeedFinding struct {
+	Vulnerability
+	AuditorType string
+}
+
+// Status page colours of an app
+const (
+	StatusGreen  = "green"  // no open findings above info
+	StatusYellow = "yellow" // moderate or low findings, or no recent audit
+	StatusRed    = "red"    // critical or high findings
+)
+
+// StatusPage is the sanitized status of the apps for a wallboard (report status): a
+// colour and the last audit date per app, without counts or vulnerability details
+type StatusPage struct {
+	Title       string          `json:"title"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	Apps        []StatusPageApp `json:"apps"`
+	Counts      map[string]int  `json:"counts"` // apps per colour
+}
+
+// StatusPageApp is the status of an app on the status page
+type StatusPageApp struct {
+	Name        string     `json:"name"`
+	Status      string     `json:"status"`
+	LastAuditAt *time.Time `json:"last_audit_at"`
+	Stale       bool       `json:"stale"` // not audited within STATUS_PAGE_STALE_DAYS
+}
+
+// Run event types
+const (
+	EventRunStarted       = "run.started"
+	EventAppStarted       = "app.started"
+	EventAuditorCompleted = "auditor.completed"
+	EventAuditorFailed    = "auditor.failed"
+	EventAppCompleted     = "app.completed"
+	EventRunCompleted     = "run.completed"
+)
+
+// RunEvent is a progress event recorded during a run and streamed by the API.
+// IDs are sequential so clients can resume a stream with Last-Event-ID.
+type RunEvent struct {
+	ID              uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	RunID           string    `gorm:"index;size:26" json:"run_id"`
+	Type            string    `gorm:"size:50" json:"type"`
+	AppName         string    `gorm:"size:255" json:"app_name,omitempty"`
+	AuditorType     string    `gorm:"size:50" json:"auditor_type,omitempty"`
+	AppsCompleted   int       `json:"apps_completed"`
+	AppsTotal       int       `json:"apps_total"`
+	Vulnerabilities int       `json:"vulnerabilities"`
+	Message         string    `gorm:"type:text" json:"message,omitempty"`
+	FailureKind     string    `gorm:"size:50" json:"failure_kind,omitempty"` // auditor.failed only
+	Hint            string    `gorm:"type:text" json:"hint,omitempty"`       // auditor.failed only
+	CreatedAt       time.Time `gorm:"index;autoCreateTime" json:"created_at"`
+}
+
+// Activity sources
+const (
+	ActivitySourceCLI      = "cli"
+	ActivitySourceAPI      = "api"
+	ActivitySourceSchedule = "schedule" // audit jobs queued by JOB_SCHEDULE_INTERVAL
+)
+
+// Activity actions
+const (
+	ActivityAppAdded          = "app.added"
+	ActivityAppEdited         = "app.edited"
+	ActivityAppRemoved        = "app.removed"
+	ActivityAppDataPurged     = "app.data_purged"
+	ActivityAppEnabled        = "app.enabled"
+	ActivityAppDisabled       = "app.disabled"
+	ActivityAppPaused         = "app.paused"
+	ActivityAppResumed        = "app.resumed"
+	ActivityRunStarted        = "run.started"
+	ActivityRunQueued         = "run.queued"
+	ActivityBroadcast         = "broadcast.sent"
+	ActivityDrill             = "notifier.drill"
+	ActivityEmailSuppressed   = "email.suppressed"
+	ActivityEmailUnsuppressed = "email.unsuppressed"
+	ActivityFindingTriaged    = "finding.triaged"
+)
+
+// ActivityLog records who changed an app or triggered a run (the audit trail)
+type ActivityLog struct {
+	ID        string    `gorm:"primaryKey;size:26" json:"id"`
+	Operator  string    `gorm:"index;size:255" json:"operator"`
+	Source    string    `gorm:"size:20" json:"source"` // cli, api
+	Action    string    `gorm:"index;size:50" json:"action"`
+	AppName   string    `gorm:"index;size:255" json:"app_name,omitempty"`
+	Details   string    `gorm:"type:text" json:"details,omitempty"`
+	CreatedAt time.Time `gorm:"index;autoCreateTime" json:"created_at"`
+}
+
+// BeforeCreate hook to generate ULID
+func (a *ActivityLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = helpers.MustNewULID()
+	}
+	return nil
+}
+
+// Dependency is an entry of an app's dependency inventory, read from its lockfiles
+type Dependency struct {
+	Ecosystem string   `json:"ecosystem"` // OSV ecosystem: npm, Packagist
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	Scope     string   `json:"scope"`             // prod or dev
+	Direct    bool     `json:"direct"`            // listed in package.json or composer.json
+	License   string   `json:"license,omitempty"` // SPDX expression, when the lockfile records it
+	Lockfile  string   `json:"lockfile"`
+	DependsOn []string `json:"depends_on,omitempty"` // name@version of the packages it requires
+}
+
+// Dependency scopes
+const (
+	DependencyScopeProd = "prod"
+	DependencyScopeDev  = "dev"
+)
+
+// AppPackage is a package version installed in an app, read from its lockfiles after
+// each audit so the advisory watcher can tell which apps a new advisory affects
+type AppPackage struct {
+	ID        string    `gorm:"primaryKey;size:26" json:"id"`
+	AppName   string    `gorm:"index;size:255" json:"app_name"`
+	Ecosystem string    `gorm:"index:idx_app_packages_lookup;size:50" json:"ecosystem"` // OSV ecosystem: npm, Packagist
+	Name      string    `gorm:"index:idx_app_packages_lookup;size:255" json:"name"`
+	Version   string    `gorm:"size:100" json:"version"`
+	Lockfile  string    `gorm:"size:255" json:"lockfile"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// BeforeCreate hook to generate ULID
+func (p *AppPackage) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == "" {
+		p.ID = helpers.MustNewULID()
+	}
+	return nil
+}
+
+// Email delivery statuses, from Resend's webhooks
+const (
+	EmailStatusSent      = "sent"
+	EmailStatusDelayed   = "delivery_delayed"
+	EmailStatusDelivered = "delivered"
+	EmailStatusBounced   = "bounced"
+	EmailStatusComplaint = "complained"
+)
+
+// emailStatusRank orders the delivery statuses, so an event arriving late does not
+// overwrite a later status
+var emailStatusRank = map[string]int{
+	EmailStatusSent:      0,
+	EmailStatusDelayed:   1,
+	EmailStatusDelivered: 2,
+	EmailStatusBounced:   3,
+	EmailStatusComplaint: 3,
+}
+
+// EmailDelivery is an email sent through Resend, with its delivery status kept up to
+// date by Resend's webhooks
+type EmailDelivery struct {
+	ID         string      `gorm:"primaryKey;size:64" json:"id"`             // Resend email ID
+	AppName    string      `gorm:"index;size:255" json:"app_name,omitempty"` // "" for emails not about one app, e.g. the executive report
+	Subject    string      `gorm:"size:500" json:"subject"`
+	Recipients StringArray `gorm:"type:text" json:"recipients"`
+	Status     string      `gorm:"index;size:20" json:"status"`
+	Bounced    StringArray `gorm:"type:text" json:"bounced,omitempty"` // the recipients a bounce or complaint is about
+	Reason     string      `gorm:"type:text" json:"reason,omitempty"`  // of the bounce
+	CreatedAt  time.Time   `gorm:"index" json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// Supersedes returns true if status is as late as the delivery's status or later
+func (d *EmailDelivery) Supersedes(status string) bool {
+	return emailStatusRank[status] >= emailStatusRank[d.Status]
+}
+
+// EmailBounce is a recipient whose latest email bounced or was marked as spam
+type EmailBounce struct {
+	Recipient string    `json:"recipient"`
+	Status    string    `json:"status"` // bounced or complained
+	Reason    string    `json:"reason,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+// BouncedRecipients returns the recipients whose latest email, among deliveries, bounced
+// or was marked as spam. A later email delivered to a recipient clears its bounce.
+func BouncedRecipients(deliveries []EmailDelivery) []EmailBounce {
+	sorted := slices.Clone(deliveries)
+	slices.SortStableFunc(sorted, func(a, b EmailDelivery) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	latest := make(map[string]*EmailBounce)
+	var order []string
+	for _, d := range sorted {
+		switch d.Status {
+		case EmailStatusDelivered:
+			for _, recipient := range d.Recipients {
+				delete(latest, strings.ToLower(recipient))
+			}
+		case EmailStatusBounced, EmailStatusComplaint:
+			bounced := d.Bounced
+			if len(bounced) == 0 {
+				bounced = d.Recipients
+			}
+			for _, recipient := range bounced {
+				key := strings.ToLower(recipient)
+				if !slices.Contains(order, key) {
+					order = append(order, key)
+				}
+				latest[key] = &EmailBounce{Recipient: recipient, Status: d.Status, Reason: d.Reason, At: d.UpdatedAt}
+			}
+		}
+	}
+
+	var bounces []EmailBounce
+	for _, key := range order {
+		if bounce, ok := latest[key]; ok {
+			bounces = append(bounces, *bounce)
+		}
+	}
+	return bounces
+}
+
+// Email suppression sources
+const (
+	SuppressionBounced   = "bounced"
+	SuppressionComplaint = "complained"
+	SuppressionManual    = "manual"
+)
+
+// EmailSuppression is an email address no longer sent to: its email bounced permanently
+// or was marked as spam, or it was suppressed by hand
+type EmailSuppression struct {
+	Address   string    `gorm:"primaryKey;size:320" json:"address"` // lowercase
+	Source    string    `gorm:"size:20" json:"source"`              // bounced, complained or manual
+	Reason    string    `gorm:"type:text" json:"reason,omitempty"`
+	AppName   string    `gorm:"size:255" json:"app_name,omitempty"` // of the email that bounced
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SuppressedEmails indexes suppressions by lowercase address
+func SuppressedEmails(suppressions []EmailSuppression) map[string]EmailSuppression {
+	index := make(map[string]EmailSuppression, len(suppressions))
+	for _, s := range suppressions {
+		index[strings.ToLower(s.Address)] = s
+	}
+	return index
+}
+
+// Job types
+const (
+	JobTypeAudit  = "audit"  // audit an app, or every app when AppName is empty
+	JobTypeNotify = "notify" // send an app's notifications again on the channels that failed
+)
+
+// Job statuses
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// Job sources
+const (
+	JobSourceAPI      = "api"
+	JobSourceSchedule = "schedule"
+	JobSourceRetry    = "retry"
+)
+
+// Job is work for the workers of `serve` (JOB_QUEUE): an audit triggered through the
+// API or by the schedule, or a notification retry. Failed attempts are retried with a
+// backoff until MaxAttempts.
+type Job struct {
+	ID          string     `gorm:"primaryKey;size:26" json:"id"`
+	Type        string     `gorm:"index;size:20" json:"type"`
+	AppName     string     `gorm:"index;size:255" json:"app_name,omitempty"`
+	Payload     string     `gorm:"type:text" json:"payload,omitempty"` // JSON, by type
+	Status      string     `gorm:"index;size:20" json:"status"`
+	Source      string     `gorm:"size:20" json:"source"` // api, schedule or retry
+	Operator    string     `gorm:"size:255" json:"operator,omitempty"`
+	Attempts    int        `json:"attempts"`
+	MaxAttempts int        `json:"max_attempts"`
+	Error       string     `gorm:"type:text" json:"error,omitempty"` // of the last attempt
+	RunAt       time.Time  `gorm:"index" json:"run_at"`              // not before
+	StartedAt   *time.Time `json:"started_at,omitempty"`             // of the last attempt
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+}
+
+// Finished returns true if the job succeeded or failed for good
+func (j *Job) Finished() bool {
+	return j.Status == JobSucceeded || j.Status == JobFailed
+}
+
+// Triage statuses of a finding
+const (
+	TriageAcknowledged = "acknowledged" // seen and being dealt with
+	TriageIgnored      = "ignored"      // added to the app's ignore list
+	TriageSnoozed      = "snoozed"      // left out of notifications until SnoozedUntil
+)
+
+// FindingTriage is the decision made on a finding of an app with `triage`. It applies
+// to every audit of the auditor reporting the finding again, identified by its package
+// and CVE, or title without one.
+type FindingTriage struct {
+	ID           string     `gorm:"primaryKey;size:26" json:"id"`
+	AppName      string     `gorm:"uniqueIndex:idx_finding_triage;size:255" json:"app_name"`
+	AuditorType  string     `gorm:"uniqueIndex:idx_finding_triage;size:50" json:"auditor_type"`
+	PackageName  string     `gorm:"uniqueIndex:idx_finding_triage;size:255" json:"package_name"`
+	FindingID    string     `gorm:"uniqueIndex:idx_finding_triage;size:500" json:"finding_id"` // CVE, or title without one
+	Status       string     `gorm:"size:20" json:"status,omitempty"`                           // acknowledged, ignored or snoozed
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	Assignee     string     `gorm:"size:255" json:"assignee,omitempty"`
+	Operator     string     `gorm:"size:255" json:"operator,omitempty"` // who made the last decision
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// BeforeCreate hook to generate ULID
+func (t *FindingTriage) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == "" {
+		t.ID = helpers.MustNewULID()
+	}
+	return nil
+}
+
+// NewFindingTriage returns the triage of a vulnerability reported by an auditor for an app
+func NewFindingTriage(appName, auditorType string, v Vulnerability) FindingTriage {
+	id := v.CVEID
+	if id == "" {
+		id = v.Title
+	}
+	return FindingTriage{AppName: appName, AuditorType: auditorType, PackageName: v.PackageName, FindingID: id}
+}
+
+// FindingKey returns the models.Vulnerability.FindingKey of the triaged finding
+func (t FindingTriage) FindingKey() string {
+	return t.PackageName + "\x00" + t.FindingID
+}
+
+// IsSnoozed returns true if the finding is snoozed at now
+func (t FindingTriage) IsSnoozed(now time.Time) bool {
+	return t.Status == TriageSnoozed && t.SnoozedUntil != nil && now.Before(*t.SnoozedUntil)
+}
+
+// IsTriaged returns true if the finding needs no decision at now: acknowledged, ignored,
+// or snoozed until later
+func (t FindingTriage) IsTriaged(now time.Time) bool {
+	return t.Status == TriageAcknowledged || t.Status == TriageIgnored || t.IsSnoozed(now)
+}
+
+// TriageSummary is the outcome of a triage session of an app, posted to its Telegram topic
+type TriageSummary struct {
+	AppName   string          `json:"app_name"`
+	Operator  string          `json:"operator,omitempty"`
+	Decisions []FindingTriage `json:"decisions"` // made in the session
+	Remaining int             `json:"remaining"` // findings left without a decision
+}
+
 // AllModels returns all models for auto-migration
 func AllModels() []interface{} {
 	return []interface{}{
 		&App{},
 		&Setting{},
 		&AuditResult{},
+		&RawOutput{},
+		&CachedAIAnalysis{},
 		&Vulnerability{},
+		&RunEvent{},
+		&ActivityLog{},
+		&AppPackage{},
+		&EmailDelivery{},
+		&EmailSuppression{},
+		&Job{},
+		&FindingTriage{},
 	}
 }
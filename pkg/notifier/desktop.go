@@ -0,0 +1,194 @@
+package notifier
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Event IDs of the Windows Event Log entries, for filters and scheduled tasks
+const (
+	DesktopEventFindings = 100 // an app has vulnerabilities
+	DesktopEventFailures = 200 // auditors of an app failed
+	DesktopEventPolicies = 300 // an app only violates policies
+	DesktopEventTest     = 900 // notifier drill
+)
+
+// desktopCommands are the tools showing the notifications on each OS
+var desktopCommands = map[string]string{
+	"darwin":  "osascript",
+	"windows": "eventcreate",
+	"linux":   "notify-send",
+}
+
+// DesktopNotifier shows a notification on the machine running the audit, for developers
+// auditing their projects on their workstation without a chat bot: in the notification
+// center on macOS (osascript), as an Application Event Log entry on Windows
+// (eventcreate) and through notify-send on Linux desktops.
+type DesktopNotifier struct {
+	goos    string
+	source  string // of the Windows Event Log entries
+	enabled bool
+	run     func(ctx context.Context, name string, args ...string) error
+}
+
+// NewDesktopNotifier creates a new DesktopNotifier writing Windows Event Log entries as
+// source. It is only enabled when the OS's tool is installed.
+func NewDesktopNotifier(source string, enabled bool) *DesktopNotifier {
+	n := &DesktopNotifier{
+		goos:   runtime.GOOS,
+		source: source,
+		run:    runDesktopCommand,
+	}
+	if command, ok := desktopCommands[n.goos]; ok && enabled {
+		_, err := exec.LookPath(command)
+		n.enabled = err == nil
+	}
+	return n
+}
+
+// Name returns "desktop"
+func (n *DesktopNotifier) Name() string {
+	return "desktop"
+}
+
+// Command returns the tool showing the notifications on this OS, empty when the OS is
+// not supported
+func (n *DesktopNotifier) Command() string {
+	return desktopCommands[n.goos]
+}
+
+// Enabled returns true if notifications can be shown on this OS
+func (n *DesktopNotifier) Enabled() bool {
+	return n.enabled
+}
+
+// Send shows a notification of a report
+func (n *DesktopNotifier) Send(ctx context.Context, report *models.Report, recipients []string) error {
+	combinedReport := &models.CombinedAppReport{
+		AppName:     report.AppName,
+		AppPath:     report.AppPath,
+		Reports:     []*models.Report{report},
+		GeneratedAt: report.GeneratedAt,
+	}
+	return n.SendCombined(ctx, combinedReport)
+}
+
+// SendCombined shows a notification of the combined report of an app: the counts and
+// highest severity, with the most severe findings in the Event Log entry on Windows
+func (n *DesktopNotifier) SendCombined(ctx context.Context, combinedReport *models.CombinedAppReport) error {
+	summary := combinedReport.GetCombinedSummary()
+
+	var vulns []models.Vulnerability
+	for _, report := range combinedReport.Reports {
+		vulns = append(vulns, report.Vulnerabilities...)
+	}
+	models.SortVulnerabilities(vulns, combinedReport.IssueOrder, time.Now())
+
+	title := "audit-checks: " + combinedReport.AppName
+	severity, event := models.SeverityModerate, DesktopEventFailures
+	var message string
+	if len(vulns) > 0 {
+		severity, event = highestSeverity(vulns), DesktopEventFindings
+		message = fmt.Sprintf("%d vulnerabilit%s (highest: %s)", summary.Total, pluralY(summary.Total), severity)
+		if problems := problemSummary(combinedReport); problems != "" {
+			message += ", " + problems
+		}
+	} else {
+		message = problemSummary(combinedReport)
+		if !combinedReport.HasFailures() {
+			event = DesktopEventPolicies
+		}
+	}
+
+	// The Event Log keeps the details the notification center has no room for
+	var details strings.Builder
+	fmt.Fprintf(&details, "%s\n\nPath: %s\n", message, combinedReport.AppPath)
+	for i, v := range vulns {
+		if i == 10 {
+			fmt.Fprintf(&details, "...and %d more\n", len(vulns)-i)
+			break
+		}
+		fmt.Fprintf(&details, "- %s %s: %s\n", strings.ToUpper(v.Severity), v.PackageName, cmp.Or(v.CVEID, v.Title))
+	}
+	for _, failure := range combinedReport.Failures {
+		fmt.Fprintf(&details, "- %s failed (%s): %s\n", failure.AuditorType, failure.Kind, truncateRunes(failure.Message, maxFailureMessageLength))
+	}
+	for _, v := range combinedReport.PolicyViolations {
+		fmt.Fprintf(&details, "- policy %s violated (%s): %s\n", v.Policy, v.Action, policyViolationDetail(v, 3))
+	}
+	if commands := combinedFixCommands(combinedReport); len(commands) > 0 {
+		fmt.Fprintf(&details, "\nRun %s to fix issues\n", strings.Join(commands, " and "))
+	}
+
+	return n.notify(ctx, title, message, strings.TrimSpace(details.String()), severity, event)
+}
+
+// SendTestMessage shows a plain notification, such as a notifier drill
+func (n *DesktopNotifier) SendTestMessage(ctx context.Context, title, text string) error {
+	if err := n.notify(ctx, title, text, title+"\n\n"+text, models.SeverityInfo, DesktopEventTest); err != nil {
+		return fmt.Errorf("failed to send test message: %w", err)
+	}
+	return nil
+}
+
+// notify shows message with title through the OS's tool; on Windows the Event Log
+// entry holds details instead, as an error for critical and high findings
+func (n *DesktopNotifier) notify(ctx context.Context, title, message, details, severity string, event int) error {
+	if !n.enabled {
+		return fmt.Errorf("desktop notifier is not enabled")
+	}
+
+	switch n.goos {
+	case "darwin":
+		// Passed as arguments, so the text needs no AppleScript quoting
+		return n.run(ctx, "osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, truncateRunes(message, 250))
+	case "windows":
+		kind := "INFORMATION"
+		switch severity {
+		case models.SeverityCritical, models.SeverityHigh:
+			kind = "ERROR"
+		case models.SeverityModerate, models.SeverityLow:
+			kind = "WARNING"
+		}
+		return n.run(ctx, "eventcreate",
+			"/L", "APPLICATION", "/T", kind, "/SO", n.source, "/ID", strconv.Itoa(event),
+			"/D", truncateRunes(details, 4000))
+	default:
+		urgency := "normal"
+		switch severity {
+		case models.SeverityCritical, models.SeverityHigh:
+			urgency = "critical"
+		case models.SeverityInfo:
+			urgency = "low"
+		}
+		return n.run(ctx, "notify-send", "--app-name=audit-checks", "--urgency="+urgency, title, truncateRunes(message, 250))
+	}
+}
+
+// runDesktopCommand runs a notification tool, with its output in the error when it fails
+func runDesktopCommand(ctx context.Context, name string, args ...string) error {
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(output.String()); message != "" {
+			return fmt.Errorf("%s failed: %w: %s", name, err, truncateRunes(message, 500))
+		}
+		return fmt.Errorf("%s failed: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,726 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// DiscordAPIEndpoint is the Discord REST API used by bots
+const DiscordAPIEndpoint = "https://discord.com/api/v10"
+
+// Embed colors by highest severity
+const (
+	discordColorCritical = 0xE74C3C // red
+	discordColorHigh     = 0xE67E22 // orange
+	discordColorModerate = 0xF1C40F // yellow
+	discordColorLow      = 0x2ECC71 // green
+	discordColorFailed   = 0x95A5A6 // grey
+)
+
+// Discord JSON error codes handled by the notifier
+const (
+	discordErrUnknownChannel    = 10003  // the thread (or channel) was deleted
+	discordErrForumNeedsThread  = 220001 // webhook messages in forum channels need a post
+	discordErrWebhookNotInForum = 220003 // webhooks can only create threads in forum channels
+)
+
+// Discord channel types that matter when creating a thread
+const (
+	discordChannelPublicThread = 11
+	discordChannelForum        = 15
+)
+
+const (
+	// discordMaxFiles is the number of attachments Discord accepts per message
+	discordMaxFiles = 10
+
+	// discordThreadArchive is how long a thread stays active without messages (one week, in minutes)
+	discordThreadArchive = 10080
+
+	// discordMaxRetryAfter caps how long a rate-limited request waits before its single retry
+	discordMaxRetryAfter = 30 * time.Second
+)
+
+// DiscordNotifier sends notifications to Discord as embeds colored by severity, through a
+// bot or a webhook. A bot posts to an app's own channel when the app has one, and otherwise
+// to one thread per app in the default channel (a post per app in forum channels). A
+// webhook posts to its channel, with one post per app when that channel is a forum.
+type DiscordNotifier struct {
+	botToken    string
+	channelID   string
+	webhookURL  string
+	endpoint    string
+	enabled     bool
+	client      *http.Client
+	forum       *bool             // whether channelID is a forum channel, looked up once
+	threadCache map[string]string // app name -> thread ID
+	cacheMu     sync.RWMutex
+}
+
+// NewDiscordNotifier creates a new DiscordNotifier. The bot (botToken and channelID) is
+// used when configured, the webhook otherwise.
+func NewDiscordNotifier(botToken, channelID, webhookURL string, enabled bool) *DiscordNotifier {
+	return NewDiscordNotifierWithEndpoint(botToken, channelID, webhookURL, enabled, DiscordAPIEndpoint)
+}
+
+// NewDiscordNotifierWithEndpoint creates a new DiscordNotifier using an API server other
+// than discord.com, e.g. a mock server in integration tests
+func NewDiscordNotifierWithEndpoint(botToken, channelID, webhookURL string, enabled bool, apiEndpoint string) *DiscordNotifier {
+	return &DiscordNotifier{
+		botToken:    botToken,
+		channelID:   channelID,
+		webhookURL:  webhookURL,
+		endpoint:    strings.TrimSuffix(apiEndpoint, "/"),
+		enabled:     enabled && (webhookURL != "" || (botToken != "" && channelID != "")),
+		client:      &http.Client{Timeout: 30 * time.Second},
+		threadCache: make(map[string]string),
+	}
+}
+
+// Name returns "discord"
+func (n *DiscordNotifier) Name() string {
+	return "discord"
+}
+
+// Enabled returns true if the notifier is configured
+func (n *DiscordNotifier) Enabled() bool {
+	return n.enabled
+}
+
+// Send implements Notifier interface but is not used for per-app threads.
+// Use SendCombinedToThread instead.
+func (n *DiscordNotifier) Send(ctx context.Context, report *models.Report, recipients []string) error {
+	return fmt.Errorf("discord notifier posts to per-app threads; use SendCombinedToThread instead")
+}
+
+// usesBot returns true if messages are posted by the bot rather than the webhook
+func (n *DiscordNotifier) usesBot() bool {
+	return n.botToken != "" && n.channelID != ""
+}
+
+// discordMessage is a message posted to a channel, thread or webhook
+type discordMessage struct {
+	Content         string                 `json:"content,omitempty"`
+	Embeds          []discordEmbed         `json:"embeds,omitempty"`
+	ThreadName      string                 `json:"thread_name,omitempty"` // webhooks: create a forum post
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
+
+// discordAllowedMentions keeps text from tool output or AI summaries from pinging anyone
+type discordAllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// discordNoMentions disables all mentions (an empty parse list; null is rejected)
+var discordNoMentions = discordAllowedMentions{Parse: []string{}}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Color       int                 `json:"color"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+	Footer      *discordEmbedFooter `json:"footer,omitempty"`
+	Timestamp   string              `json:"timestamp,omitempty"`
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline,omitempty"`
+}
+
+type discordEmbedFooter struct {
+	Text string `json:"text"`
+}
+
+// discordChannel is the part of a channel (or thread) object the notifier reads
+type discordChannel struct {
+	ID   string `json:"id"`
+	Type int    `json:"type"`
+}
+
+// discordSentMessage is the part of a created message the notifier reads
+type discordSentMessage struct {
+	ID        string `json:"id"`
+	ChannelID string `json:"channel_id"` // the thread ID for messages posted in a thread
+}
+
+// discordError is an error response of the Discord API
+type discordError struct {
+	Status     int
+	Code       int     `json:"code"`
+	Message    string  `json:"message"`
+	RetryAfter float64 `json:"retry_after"` // seconds, rate-limited requests only
+}
+
+// Error returns the Discord error message
+func (e *discordError) Error() string {
+	return fmt.Sprintf("discord API error (status %d, code %d): %s", e.Status, e.Code, e.Message)
+}
+
+// isDiscordError returns true if err is a Discord API error with the given JSON error code
+func isDiscordError(err error, code int) bool {
+	var discordErr *discordError
+	return errors.As(err, &discordErr) && discordErr.Code == code
+}
+
+// SendCombinedToThread sends a combined notification for an app. appChannelID, when set,
+// is the app's own channel (bot only) and receives the message directly. Otherwise the
+// message goes to the app's thread, which is created when existingThreadID is empty or
+// the thread was deleted. Returns the thread ID used so it can be persisted ("" when the
+// message was posted to a channel).
+func (n *DiscordNotifier) SendCombinedToThread(ctx context.Context, combinedReport *models.CombinedAppReport, appName, appChannelID, existingThreadID string) (string, error) {
+	log := helpers.Logger(ctx)
+
+	if !n.enabled {
+		return "", fmt.Errorf("discord notifier is not enabled")
+	}
+	if appName == "" {
+		return "", fmt.Errorf("app name is required for discord threads")
+	}
+
+	message := n.buildCombinedMessage(combinedReport)
+	files := combinedReport.ReportFiles
+
+	threadID, err := n.sendToApp(ctx, message, files, appName, appChannelID, existingThreadID)
+	if err != nil {
+		return threadID, err
+	}
+
+	log.Infof("Discord notification sent app=%s thread_id=%s auditors=%d files=%d",
+		appName,
+		threadID,
+		len(combinedReport.Reports),
+		len(files),
+	)
+	return threadID, nil
+}
+
+// SendExposureToThread sends a broadcast exposure notice for an app, routed like
+// SendCombinedToThread. Returns the thread ID used so it can be persisted.
+func (n *DiscordNotifier) SendExposureToThread(ctx context.Context, notice *models.ExposureNotice, appName, appChannelID, existingThreadID string) (string, error) {
+	if !n.enabled {
+		return "", fmt.Errorf("discord notifier is not enabled")
+	}
+	if appName == "" {
+		return "", fmt.Errorf("app name is required for discord threads")
+	}
+
+	threadID, err := n.sendToApp(ctx, discordExposureMessage(notice), nil, appName, appChannelID, existingThreadID)
+	if err != nil {
+		return threadID, err
+	}
+
+	helpers.Logger(ctx).Infof("Discord exposure notice sent app=%s thread_id=%s vulnerability=%s", appName, threadID, notice.VulnerabilityID)
+	return threadID, nil
+}
+
+// sendToApp posts a message to the app's channel, the app's thread, or through the webhook
+func (n *DiscordNotifier) sendToApp(ctx context.Context, message discordMessage, files []string, appName, appChannelID, existingThreadID string) (string, error) {
+	log := helpers.Logger(ctx)
+
+	if !n.usesBot() {
+		if appChannelID != "" {
+			log.Warnf("Discord channel %s of app=%s needs a bot token; posting through the webhook", appChannelID, appName)
+		}
+		return n.sendWebhook(ctx, message, files, appName, existingThreadID)
+	}
+
+	// The app's own channel gets the message directly, without a thread
+	if appChannelID != "" {
+		if _, err := n.postMessage(ctx, appChannelID, message, files); err != nil {
+			return "", fmt.Errorf("failed to send to channel %s: %w", appChannelID, err)
+		}
+		log.Debugf("Discord message posted to channel channel_id=%s app=%s", appChannelID, appName)
+		return "", nil
+	}
+
+	threadID, posted, err := n.getOrCreateThread(ctx, appName, existingThreadID, message, files)
+	if err != nil {
+		return "", fmt.Errorf("failed to get/create thread for app %s: %w", appName, err)
+	}
+
+	if !posted {
+		_, err = n.postMessage(ctx, threadID, message, files)
+		if isDiscordError(err, discordErrUnknownChannel) {
+			// The thread was deleted: start a new one for the app
+			log.Warnf("Discord thread %s appears to be deleted, creating new thread for app=%s", threadID, appName)
+			n.invalidateThreadCache(appName)
+			threadID, posted, err = n.getOrCreateThread(ctx, appName, "", message, files)
+			if err == nil && !posted {
+				_, err = n.postMessage(ctx, threadID, message, files)
+			}
+		}
+		if err != nil {
+			return threadID, fmt.Errorf("failed to send to thread %s: %w", threadID, err)
+		}
+	}
+
+	return threadID, nil
+}
+
+// getOrCreateThread returns the app's thread: existingThreadID (from the database), the
+// cached one, or a new thread. Threads in forum channels are created with their first
+// message, in which case posted is true.
+func (n *DiscordNotifier) getOrCreateThread(ctx context.Context, appName, existingThreadID string, message discordMessage, files []string) (threadID string, posted bool, err error) {
+	if existingThreadID != "" {
+		n.cacheMu.Lock()
+		n.threadCache[appName] = existingThreadID
+		n.cacheMu.Unlock()
+		return existingThreadID, false, nil
+	}
+
+	n.cacheMu.RLock()
+	threadID, ok := n.threadCache[appName]
+	n.cacheMu.RUnlock()
+	if ok {
+		return threadID, false, nil
+	}
+
+	forum, err := n.isForum(ctx)
+	if err != nil {
+		return "", false, err
+	}
+
+	var thread discordChannel
+	request := map[string]any{
+		"name":                  discordThreadName(appName),
+		"auto_archive_duration": discordThreadArchive,
+	}
+	if forum {
+		request["message"] = message
+		err = n.do(ctx, http.MethodPost, n.endpoint+"/channels/"+n.channelID+"/threads", request, files, &thread)
+	} else {
+		request["type"] = discordChannelPublicThread
+		err = n.do(ctx, http.MethodPost, n.endpoint+"/channels/"+n.channelID+"/threads", request, nil, &thread)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("failed to create thread: %w", err)
+	}
+	if thread.ID == "" {
+		return "", false, fmt.Errorf("discord returned no thread ID")
+	}
+
+	n.cacheMu.Lock()
+	n.threadCache[appName] = thread.ID
+	n.cacheMu.Unlock()
+	helpers.Logger(ctx).Infof("Created new Discord thread for app=%s thread_id=%s", appName, thread.ID)
+
+	return thread.ID, forum, nil
+}
+
+// isForum returns true if the default channel is a forum, where threads are posts
+func (n *DiscordNotifier) isForum(ctx context.Context) (bool, error) {
+	n.cacheMu.RLock()
+	forum := n.forum
+	n.cacheMu.RUnlock()
+	if forum != nil {
+		return *forum, nil
+	}
+
+	var channel discordChannel
+	if err := n.do(ctx, http.MethodGet, n.endpoint+"/channels/"+n.channelID, nil, nil, &channel); err != nil {
+		return false, fmt.Errorf("failed to get channel %s: %w", n.channelID, err)
+	}
+
+	isForum := channel.Type == discordChannelForum
+	n.cacheMu.Lock()
+	n.forum = &isForum
+	n.cacheMu.Unlock()
+	return isForum, nil
+}
+
+// postMessage posts a message with attachments to a channel or thread as the bot
+func (n *DiscordNotifier) postMessage(ctx context.Context, channelID string, message discordMessage, files []string) (*discordSentMessage, error) {
+	var sent discordSentMessage
+	if err := n.do(ctx, http.MethodPost, n.endpoint+"/channels/"+channelID+"/messages", message, files, &sent); err != nil {
+		return nil, err
+	}
+	return &sent, nil
+}
+
+// sendWebhook posts a message through the webhook, to the app's post when the webhook's
+// channel is a forum. Returns the post's thread ID ("" for other channels).
+func (n *DiscordNotifier) sendWebhook(ctx context.Context, message discordMessage, files []string, appName, existingThreadID string) (string, error) {
+	log := helpers.Logger(ctx)
+
+	if existingThreadID != "" {
+		_, err := n.executeWebhook(ctx, message, files, existingThreadID)
+		if err == nil {
+			log.Infof("Discord notification sent to thread thread_id=%s app=%s", existingThreadID, appName)
+			return existingThreadID, nil
+		}
+		if !isDiscordError(err, discordErrUnknownChannel) {
+			return existingThreadID, fmt.Errorf("failed to send to thread %s: %w", existingThreadID, err)
+		}
+		log.Warnf("Discord thread %s appears to be deleted, creating new post for app=%s", existingThreadID, appName)
+	}
+
+	// A forum post per app; other channels have no threads for webhooks
+	message.ThreadName = discordThreadName(appName)
+	sent, err := n.executeWebhook(ctx, message, files, "")
+	if isDiscordError(err, discordErrWebhookNotInForum) {
+		message.ThreadName = ""
+		if _, err := n.executeWebhook(ctx, message, files, ""); err != nil {
+			return "", fmt.Errorf("failed to send through webhook: %w", err)
+		}
+		log.Infof("Discord notification sent through webhook app=%s", appName)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to send through webhook: %w", err)
+	}
+
+	log.Infof("Discord notification sent to new post thread_id=%s app=%s", sent.ChannelID, appName)
+	return sent.ChannelID, nil
+}
+
+// executeWebhook posts a message through the webhook, into threadID when set
+func (n *DiscordNotifier) executeWebhook(ctx context.Context, message discordMessage, files []string, threadID string) (*discordSentMessage, error) {
+	webhookURL, err := url.Parse(n.webhookURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	query := webhookURL.Query()
+	query.Set("wait", "true") // return the created message
+	if threadID != "" {
+		query.Set("thread_id", threadID)
+	}
+	webhookURL.RawQuery = query.Encode()
+
+	var sent discordSentMessage
+	if err := n.do(ctx, http.MethodPost, webhookURL.String(), message, files, &sent); err != nil {
+		return nil, err
+	}
+	return &sent, nil
+}
+
+// do sends a request to the Discord API or webhook and decodes the response into out.
+// Files are attached as multipart form data next to the JSON payload. A rate-limited
+// request is retried once after the delay Discord asks for.
+func (n *DiscordNotifier) do(ctx context.Context, method, requestURL string, payload any, files []string, out any) error {
+	body, contentType, err := discordRequestBody(payload, files)
+	if err != nil {
+		return err
+	}
+
+	for attempt := 1; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if n.usesBot() && strings.HasPrefix(requestURL, n.endpoint+"/") {
+			req.Header.Set("Authorization", "Bot "+n.botToken)
+		}
+		req.Header.Set("User-Agent", "DiscordBot (https://github.com/shadowbane/audit-checks, 1.0)")
+
+		resp, err := n.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("discord request failed: %w", err)
+		}
+		respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read discord response: %w", err)
+		}
+
+		if resp.StatusCode < 300 {
+			if out == nil || len(respBody) == 0 {
+				return nil
+			}
+			if err := json.Unmarshal(respBody, out); err != nil {
+				return fmt.Errorf("failed to parse discord response: %w", err)
+			}
+			return nil
+		}
+
+		discordErr := &discordError{Status: resp.StatusCode}
+		if json.Unmarshal(respBody, discordErr) != nil || discordErr.Message == "" {
+			discordErr.Message = strings.TrimSpace(string(respBody))
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt > 1 {
+			return discordErr
+		}
+
+		wait := min(time.Duration(discordErr.RetryAfter*float64(time.Second)), discordMaxRetryAfter)
+		helpers.Logger(ctx).Warnf("Discord rate limit hit, retrying in %s", wait)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// discordRequestBody encodes payload as JSON, or as multipart form data (payload_json
+// plus files[n]) when there are files to attach
+func discordRequestBody(payload any, files []string) ([]byte, string, error) {
+	if payload == nil {
+		return nil, "", nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode discord payload: %w", err)
+	}
+	if len(files) == 0 {
+		return data, "application/json", nil
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("payload_json", string(data)); err != nil {
+		return nil, "", err
+	}
+	for i, path := range files[:min(len(files), discordMaxFiles)] {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read report %s: %w", path, err)
+		}
+		part, err := writer.CreateFormFile(fmt.Sprintf("files[%d]", i), filepath.Base(path))
+		if err != nil {
+			return nil, "", err
+		}
+		if _, err := part.Write(content); err != nil {
+			return nil, "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), writer.FormDataContentType(), nil
+}
+
+// invalidateThreadCache removes a thread from the cache
+func (n *DiscordNotifier) invalidateThreadCache(appName string) {
+	n.cacheMu.Lock()
+	defer n.cacheMu.Unlock()
+	delete(n.threadCache, appName)
+}
+
+// discordThreadName is the name of an app's thread
+func discordThreadName(appName string) string {
+	return truncateRunes(fmt.Sprintf("Security: %s", appName), 100)
+}
+
+// buildCombinedMessage creates the combined message: one embed colored by the highest severity
+func (n *DiscordNotifier) buildCombinedMessage(combinedReport *models.CombinedAppReport) discordMessage {
+	embed := discordEmbed{
+		Timestamp: combinedReport.GeneratedAt.UTC().Format(time.RFC3339),
+	}
+	if combinedReport.RunID != "" {
+		embed.Footer = &discordEmbedFooter{Text: "Run " + combinedReport.RunID}
+	}
+
+	// Only auditor failures or policy violations: there is no vulnerability summary to show
+	if !combinedReport.HasVulnerabilities() {
+		embed.Title = truncateRunes(fmt.Sprintf("%s: %s", problemTitle(combinedReport), combinedReport.AppName), 256)
+		embed.Color = discordColorFailed
+		embed.Fields = discordFailureFields(combinedReport.Failures)
+		embed.Fields = append(embed.Fields, discordPolicyFields(combinedReport.PolicyViolations)...)
+		return discordMessage{Embeds: []discordEmbed{embed}, AllowedMentions: discordNoMentions}
+	}
+
+	summary := combinedReport.GetCombinedSummary()
+	embed.Title = truncateRunes(fmt.Sprintf("Security Alert: %s", combinedReport.AppName), 256)
+	embed.Color = discordSeverityColor(summary)
+	embed.Fields = []discordEmbedField{
+		{Name: "Critical", Value: fmt.Sprint(summary.Critical), Inline: true},
+		{Name: "High", Value: fmt.Sprint(summary.High), Inline: true},
+		{Name: "Moderate", Value: fmt.Sprint(summary.Moderate), Inline: true},
+		{Name: "Low", Value: fmt.Sprint(summary.Low), Inline: true},
+		{Name: "Info", Value: fmt.Sprint(summary.Info), Inline: true},
+		{Name: "Total", Value: fmt.Sprint(summary.Total), Inline: true},
+	}
+
+	// Per-auditor breakdown
+	var breakdown []string
+	for _, report := range combinedReport.Reports {
+		if report.AuditResult.TotalVulnerabilities > 0 {
+			breakdown = append(breakdown, fmt.Sprintf("%s: %d vulnerabilities",
+				strings.ToUpper(report.AuditorType),
+				report.AuditResult.TotalVulnerabilities,
+			))
+		}
+	}
+	if len(breakdown) > 0 {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: "Breakdown by Package Manager", Value: discordFieldValue(strings.Join(breakdown, "\n"))})
+	}
+
+	// Top vulnerabilities across all auditors (limit to 5)
+	topVulns := collectTopVulnerabilities(combinedReport, 5)
+	if len(topVulns) > 0 {
+		var lines []string
+		for i, v := range topVulns {
+			line := fmt.Sprintf("%d. **%s** (%s)", i+1, discordEscape(v.PackageName), severityAndAge(v))
+			if v.CVEID != "" {
+				line += " " + discordEscape(v.CVEID)
+			}
+			lines = append(lines, line)
+		}
+		if total := summary.Total; total > len(topVulns) {
+			lines = append(lines, fmt.Sprintf("... and %d more", total-len(topVulns)))
+		}
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: "Top Issues", Value: discordFieldValue(strings.Join(lines, "\n"))})
+	}
+
+	// AI Summary if available (from any report)
+	for _, report := range combinedReport.Reports {
+		if report.AIAnalysis != nil && report.AIAnalysis.Summary != "" {
+			embed.Fields = append(embed.Fields, discordEmbedField{Name: "AI Summary", Value: discordFieldValue(report.AIAnalysis.Summary)})
+			break // Only include one AI summary
+		}
+	}
+
+	embed.Fields = append(embed.Fields, discordFailureFields(combinedReport.Failures)...)
+	embed.Fields = append(embed.Fields, discordPolicyFields(combinedReport.PolicyViolations)...)
+
+	if commands := combinedFixCommands(combinedReport); len(commands) > 0 {
+		embed.Description = fmt.Sprintf("Run %s to fix issues", strings.Join(commands, " and "))
+	}
+
+	return discordMessage{Embeds: []discordEmbed{embed}, AllowedMentions: discordNoMentions}
+}
+
+// discordExposureMessage creates the exposure notice: a red embed listing the affected packages
+func discordExposureMessage(notice *models.ExposureNotice) discordMessage {
+	var packages strings.Builder
+	for _, pkg := range notice.Packages {
+		fmt.Fprintf(&packages, "`%s@%s` (%s)\n", pkg.Name, pkg.Version, pkg.Lockfile)
+	}
+
+	embed := discordEmbed{
+		Title:       truncateRunes(fmt.Sprintf("🚨 %s: %s is exposed", notice.VulnerabilityID, notice.AppName), 256),
+		Description: truncateRunes(discordEscape(notice.Summary), 4096),
+		Color:       discordColorCritical,
+		Fields: []discordEmbedField{
+			{Name: "Affected packages", Value: discordFieldValue(strings.TrimSpace(packages.String()))},
+		},
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+	}
+	if notice.Message != "" {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: "Message", Value: discordFieldValue(notice.Message)})
+	}
+
+	return discordMessage{Embeds: []discordEmbed{embed}, AllowedMentions: discordNoMentions}
+}
+
+// discordFailureFields lists the auditors that failed with their remediation hints
+func discordFailureFields(failures []models.AuditFailure) []discordEmbedField {
+	fields := make([]discordEmbedField, 0, len(failures))
+	for _, f := range failures {
+		message := f.Message
+		if runes := []rune(message); len(runes) > maxFailureMessageLength {
+			message = string(runes[:maxFailureMessageLength]) + "..."
+		}
+		fields = append(fields, discordEmbedField{
+			Name:  truncateRunes(fmt.Sprintf("Failed: %s (%s)", strings.ToUpper(f.AuditorType), f.Kind), 256),
+			Value: discordFieldValue(fmt.Sprintf("%s\n*Hint: %s*", discordEscape(message), discordEscape(f.Hint))),
+		})
+	}
+	// An embed holds at most 25 fields; the summary fields take up to nine, the
+	// policy violations one
+	if len(fields) > 15 {
+		fields = fields[:15]
+	}
+	return fields
+}
+
+// discordPolicyFields lists the violated policies in one field
+func discordPolicyFields(violations []models.PolicyViolation) []discordEmbedField {
+	if len(violations) == 0 {
+		return nil
+	}
+
+	lines := make([]string, 0, len(violations))
+	for _, v := range violations {
+		lines = append(lines, fmt.Sprintf("**%s** (%s): %s",
+			discordEscape(v.Policy), strings.ToUpper(v.Action), discordEscape(policyViolationDetail(v, 3))))
+	}
+	return []discordEmbedField{{Name: "Policies Violated", Value: discordFieldValue(strings.Join(lines, "\n"))}}
+}
+
+// discordSeverityColor returns the embed color for the highest severity found
+func discordSeverityColor(summary models.Summary) int {
+	switch {
+	case summary.Critical > 0:
+		return discordColorCritical
+	case summary.High > 0:
+		return discordColorHigh
+	case summary.Moderate > 0:
+		return discordColorModerate
+	}
+	return discordColorLow
+}
+
+// discordFieldValue truncates a field value to the 1024 characters Discord allows
+func discordFieldValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return truncateRunes(s, 1024)
+}
+
+// discordEscape escapes Discord markdown characters
+func discordEscape(s string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"*", "\\*",
+		"_", "\\_",
+		"~", "\\~",
+		"`", "\\`",
+		"|", "\\|",
+		">", "\\>",
+	)
+	return replacer.Replace(s)
+}
+
+// truncateRunes shortens s to at most limit characters, ending with "..." when cut
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-3]) + "..."
+}
+
+// SendTestMessage sends a plain test message to the default channel or the webhook's channel
+func (n *DiscordNotifier) SendTestMessage(ctx context.Context, text string) error {
+	if !n.enabled {
+		return fmt.Errorf("discord notifier is not enabled")
+	}
+
+	message := discordMessage{Content: text, AllowedMentions: discordNoMentions}
+	var err error
+	if n.usesBot() {
+		_, err = n.postMessage(ctx, n.channelID, message, nil)
+	} else {
+		_, err = n.executeWebhook(ctx, message, nil, "")
+		if isDiscordError(err, discordErrForumNeedsThread) {
+			// Forum webhooks need a post to write to
+			message.ThreadName = "Audit Checks"
+			_, err = n.executeWebhook(ctx, message, nil, "")
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send test message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// DrillChannels are the channels a notifier drill can test, in the order they are tested
+var DrillChannels = []string{"email", "telegram", "discord", "mattermost", "webhook", "ntfy", "gotify", "zulip", "opsgenie", "pagerduty", "desktop"}
+
+// Aliases of the Opsgenie alerts and deduplication keys of the PagerDuty incidents
+// raised by notifier drills
+const (
+	drillAlias       = "audit-checks:notifier-drill"
+	drillFailedAlias = "audit-checks:notifier-drill-failed"
+)
+
+// DrillTargets are the destinations of a notifier drill on channels without a global one
+type DrillTargets struct {
+	Apps  []models.NotificationConfig // enabled apps: their recipients, webhook URLs and ntfy topics are tested
+	Email []string                    // recipients of the test email instead of the apps' recipients
+}
+
+// emails returns the recipients of the test email
+func (t DrillTargets) emails() []string {
+	if len(t.Email) > 0 {
+		return t.Email
+	}
+	var emails []string
+	for _, app := range t.Apps {
+		for _, email := range app.Email {
+			if !slices.Contains(emails, email) {
+				emails = append(emails, email)
+			}
+		}
+	}
+	return emails
+}
+
+// webhooks returns the apps' webhook URLs, tested next to the global ones
+func (t DrillTargets) webhooks() []string {
+	var urls []string
+	for _, app := range t.Apps {
+		for _, url := range app.Webhooks {
+			if !slices.Contains(urls, url) {
+				urls = append(urls, url)
+			}
+		}
+	}
+	return urls
+}
+
+// ntfyTopics returns the topics of the apps publishing to ntfy
+func (t DrillTargets) ntfyTopics(ntfy *NtfyNotifier) []string {
+	var topics []string
+	for _, app := range t.Apps {
+		if !app.NtfyEnabled {
+			continue
+		}
+		if topic := ntfy.Topic(app.AppName, app.NtfyTopic); !slices.Contains(topics, topic) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// DrillResult is the outcome of a notifier drill on one channel
+type DrillResult struct {
+	Channel string
+	Error   error // nil when the test message was accepted
+}
+
+// Drill sends a clearly labeled test message on each of channels whose notifier is
+// enabled, to check that the path to the recipients still works. The Opsgenie alert
+// and PagerDuty incident it raises, at the lowest priority, are closed right away.
+// Channels without destinations are skipped.
+func (m *Manager) Drill(ctx context.Context, channels []string, targets DrillTargets) []DrillResult {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	log := helpers.Logger(ctx)
+
+	title := "[Audit Checks] Notifier drill"
+	text := "This is a test of the Audit Checks notifications (notifier drill), not a security finding. " +
+		"No action is needed: receiving it means this channel still works."
+
+	var results []DrillResult
+	for _, channel := range DrillChannels {
+		if !slices.Contains(channels, channel) {
+			continue
+		}
+		n, ok := m.notifiers[channel]
+		if !ok || !n.Enabled() {
+			continue
+		}
+
+		if !m.hasDrillTargets(n, targets) {
+			log.Infof("Skipping notifier drill channel=%s: no app uses it", channel)
+			continue
+		}
+
+		if m.dryRun {
+			log.Infof("DRY RUN: Would send notifier drill channel=%s", channel)
+			results = append(results, DrillResult{Channel: channel})
+			continue
+		}
+
+		err := m.sendNotice(ctx, n, title, text, targets, false)
+		if err != nil {
+			log.Errorf("Notifier drill failed channel=%s error=%v", channel, err)
+		} else {
+			log.Infof("Notifier drill passed channel=%s", channel)
+		}
+		results = append(results, DrillResult{Channel: channel, Error: err})
+	}
+
+	return results
+}
+
+// DrillAlert raises a meta-alert about the failed channels of a drill through the
+// channels that passed it. The Opsgenie alert and PagerDuty incident stay open.
+func (m *Manager) DrillAlert(ctx context.Context, results []DrillResult, targets DrillTargets) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	log := helpers.Logger(ctx)
+
+	var failed []string
+	var passed []Notifier
+	for _, result := range results {
+		if result.Error != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", result.Channel, result.Error))
+		} else if n, ok := m.notifiers[result.Channel]; ok {
+			passed = append(passed, n)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if len(passed) == 0 {
+		return fmt.Errorf("no channel left to alert through")
+	}
+
+	title := fmt.Sprintf("[Audit Checks] Notifier drill failed on %d channel(s)", len(failed))
+	text := "The notifier drill could not deliver its test message. Security findings will not reach " +
+		"these channels until they are fixed (e.g. a revoked token or a removed bot):\n\n" + strings.Join(failed, "\n")
+
+	var errs []error
+	for _, n := range passed {
+		if m.dryRun {
+			log.Infof("DRY RUN: Would send notifier drill alert channel=%s", n.Name())
+			continue
+		}
+		if err := m.sendNotice(ctx, n, title, text, targets, true); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("notifier drill alert errors: %v", errs)
+	}
+	return nil
+}
+
+// hasDrillTargets reports whether a drill on n has destinations
+func (m *Manager) hasDrillTargets(n Notifier, targets DrillTargets) bool {
+	switch n := n.(type) {
+	case *EmailNotifier:
+		return len(targets.emails()) > 0
+	case *WebhookNotifier:
+		return n.HasTargets(targets.webhooks())
+	case *NtfyNotifier:
+		return len(targets.ntfyTopics(n)) > 0
+	}
+	return true
+}
+
+// sendNotice sends a plain message on one channel: a drill's test message, or with
+// alert its meta-alert
+func (m *Manager) sendNotice(ctx context.Context, n Notifier, title, text string, targets DrillTargets, alert bool) error {
+	switch n := n.(type) {
+	case *EmailNotifier:
+		return n.SendTestMessage(ctx, title, text, targets.emails())
+	case *TelegramNotifier:
+		return n.SendTestMessage(ctx, title+"\n\n"+text)
+	case *DiscordNotifier:
+		return n.SendTestMessage(ctx, "**"+title+"**\n"+text)
+	case *MattermostNotifier:
+		return n.SendTestMessage(ctx, "#### "+title+"\n"+text)
+	case *WebhookNotifier:
+		return n.SendTestMessage(ctx, title, text, targets.webhooks())
+	case *NtfyNotifier:
+		var errs []error
+		for _, topic := range targets.ntfyTopics(n) {
+			if err := n.SendTestMessage(ctx, topic, title, text); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", topic, err))
+			}
+		}
+		if len(errs) > 0 {
+			return fmt.Errorf("ntfy errors: %v", errs)
+		}
+		return nil
+	case *GotifyNotifier:
+		return n.SendTestMessage(ctx, title, text)
+	case *ZulipNotifier:
+		return n.SendTestMessage(ctx, "**"+title+"**\n"+text)
+	case *DesktopNotifier:
+		return n.SendTestMessage(ctx, title, text)
+	case *OpsgenieNotifier:
+		if alert {
+			return n.CreateNoticeAlert(ctx, drillFailedAlias, title, text, "P3")
+		}
+		if err := n.CreateNoticeAlert(ctx, drillAlias, title, text, "P5"); err != nil {
+			return err
+		}
+		return n.CloseAlert(ctx, drillAlias, "Notifier drill delivered")
+	case *PagerDutyNotifier:
+		if alert {
+			return n.TriggerNotice(ctx, drillFailedAlias, title, "warning")
+		}
+		if err := n.TriggerNotice(ctx, drillAlias, title, "info"); err != nil {
+			return err
+		}
+		return n.Resolve(ctx, drillAlias)
+	}
+	return fmt.Errorf("notifier drill is not supported by %s", n.Name())
+}
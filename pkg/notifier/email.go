@@ -5,40 +5,69 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"html"
 	"html/template"
 	"net/http"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/shadowbane/audit-checks/pkg/helpers"
 	"github.com/shadowbane/audit-checks/pkg/models"
 )
 
 const (
-	resendAPIURL = "https://api.resend.com/emails"
+	// ResendAPIURL is the Resend endpoint emails are sent to
+	ResendAPIURL = "https://api.resend.com/emails"
 )
 
-// EmailNotifier sends notifications via email using Resend API
+// EmailNotifier sends notifications via email using Resend API, or an SMTP server
+// when created by NewSMTPEmailNotifier
 type EmailNotifier struct {
-	apiKey    string
-	fromEmail string
-	enabled   bool
-	client    *http.Client
+	apiKey       string
+	fromEmail    string
+	enabled      bool
+	apiURL       string
+	smtp         *SMTPConfig
+	client       *http.Client
+	record       func(delivery *models.EmailDelivery)
+	suppressions func() ([]models.EmailSuppression, error)
 }
 
 // NewEmailNotifier creates a new EmailNotifier
 func NewEmailNotifier(apiKey, fromEmail string) *EmailNotifier {
+	return NewEmailNotifierWithURL(apiKey, fromEmail, ResendAPIURL)
+}
+
+// NewEmailNotifierWithURL creates a new EmailNotifier sending to a Resend-compatible
+// endpoint other than ResendAPIURL, e.g. a mock server in integration tests
+func NewEmailNotifierWithURL(apiKey, fromEmail, apiURL string) *EmailNotifier {
 	enabled := apiKey != "" && fromEmail != ""
 
 	return &EmailNotifier{
 		apiKey:    apiKey,
 		fromEmail: fromEmail,
 		enabled:   enabled,
+		apiURL:    apiURL,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
 }
 
+// RecordDeliveries calls record with each email sent through Resend, so that its
+// delivery status can be tracked from Resend's webhooks. SMTP emails are not recorded.
+func (n *EmailNotifier) RecordDeliveries(record func(delivery *models.EmailDelivery)) {
+	n.record = record
+}
+
+// SkipSuppressed makes the notifier leave out the addresses suppressions returns, e.g.
+// those whose emails bounced, instead of sending to them again
+func (n *EmailNotifier) SkipSuppressed(suppressions func() ([]models.EmailSuppression, error)) {
+	n.suppressions = suppressions
+}
+
 // Name returns "email"
 func (n *EmailNotifier) Name() string {
 	return "email"
@@ -65,21 +94,270 @@ func (n *EmailNotifier) Send(ctx context.Context, report *models.Report, recipie
 		return fmt.Errorf("failed to build email body: %w", err)
 	}
 
-	payload := resendPayload{
+	return n.deliver(ctx, emailMessage{
 		From:    n.fromEmail,
 		To:      recipients,
 		Subject: subject,
 		HTML:    htmlBody,
+		Text:    buildTextBody(report),
+		AppName: report.AppName,
+	})
+}
+
+// SendTestEmail sends a short test email to verify the Resend or SMTP configuration
+func (n *EmailNotifier) SendTestEmail(ctx context.Context, recipients []string) error {
+	return n.SendTestMessage(ctx, "[Audit Checks] Test email",
+		"This is a test email from Audit Checks. Email notifications are configured correctly.", recipients)
+}
+
+// SendTestMessage emails a plain text message, such as a notifier drill, with its
+// paragraphs and lines kept in the HTML part
+func (n *EmailNotifier) SendTestMessage(ctx context.Context, subject, text string, recipients []string) error {
+	if !n.enabled {
+		return fmt.Errorf("email notifier is not enabled")
+	}
+
+	preheader, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	data := noticeEmailData{
+		emailPage: emailPage{Title: subject, Preheader: truncateRunes(preheader, 100)},
+	}
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		data.Paragraphs = append(data.Paragraphs, strings.Split(paragraph, "\n"))
+	}
+
+	var buf bytes.Buffer
+	if err := emailTemplates.ExecuteTemplate(&buf, "notice", data); err != nil {
+		return fmt.Errorf("failed to build email body: %w", err)
+	}
+
+	return n.deliver(ctx, emailMessage{
+		From:    n.fromEmail,
+		To:      recipients,
+		Subject: subject,
+		HTML:    buf.String(),
+		Text:    strings.TrimRight(text, "\n") + "\n" + textFooter(""),
+	})
+}
+
+// SendFailures sends the auditors that failed for an app in run runID, with their remediation hints
+func (n *EmailNotifier) SendFailures(ctx context.Context, appName, runID string, failures []models.AuditFailure, recipients []string) error {
+	if !n.enabled {
+		return fmt.Errorf("email notifier is not enabled")
+	}
+
+	if len(recipients) == 0 || len(failures) == 0 {
+		return nil
+	}
+
+	data := failureEmailData{
+		emailPage: emailPage{
+			Title:     "Security audit failed: " + appName,
+			Preheader: fmt.Sprintf("%d auditor(s) could not complete for %s, so their findings are missing from this run", len(failures), appName),
+			RunID:     runID,
+		},
+		AppName:  appName,
+		Failures: failures,
+	}
+	var buf bytes.Buffer
+	if err := emailTemplates.ExecuteTemplate(&buf, "failures", data); err != nil {
+		return fmt.Errorf("failed to build email body: %w", err)
+	}
+
+	return n.deliver(ctx, emailMessage{
+		From:    n.fromEmail,
+		To:      recipients,
+		Subject: fmt.Sprintf("[FAILED] Security Audit: %s - %d auditor(s) failed", appName, len(failures)),
+		HTML:    buf.String(),
+		Text:    buildFailuresTextBody(appName, runID, failures),
+		AppName: appName,
+	})
+}
+
+// SendPolicyViolations sends the policies an app violates after run runID, with what violates them
+func (n *EmailNotifier) SendPolicyViolations(ctx context.Context, appName, runID string, violations []models.PolicyViolation, recipients []string) error {
+	if !n.enabled {
+		return fmt.Errorf("email notifier is not enabled")
+	}
+
+	if len(recipients) == 0 || len(violations) == 0 {
+		return nil
+	}
+
+	data := policyEmailData{
+		emailPage: emailPage{
+			Title:     "Security policy violated: " + appName,
+			Preheader: fmt.Sprintf("%s violates %d polic%s of your organization", appName, len(violations), pluralY(len(violations))),
+			RunID:     runID,
+		},
+		AppName:    appName,
+		Violations: violations,
+	}
+	var buf bytes.Buffer
+	if err := emailTemplates.ExecuteTemplate(&buf, "policies", data); err != nil {
+		return fmt.Errorf("failed to build email body: %w", err)
 	}
 
-	jsonData, err := json.Marshal(payload)
+	tag := "WARN"
+	if slices.ContainsFunc(violations, models.PolicyViolation.Failing) {
+		tag = "FAIL"
+	}
+	return n.deliver(ctx, emailMessage{
+		From:    n.fromEmail,
+		To:      recipients,
+		Subject: fmt.Sprintf("[POLICY %s] Security Audit: %s - %d polic%s violated", tag, appName, len(violations), pluralY(len(violations))),
+		HTML:    buf.String(),
+		Text:    buildPolicyTextBody(appName, runID, violations),
+		AppName: appName,
+	})
+}
+
+// SendExposure emails an app's owners that it installs packages affected by a vulnerability
+func (n *EmailNotifier) SendExposure(ctx context.Context, notice *models.ExposureNotice, recipients []string) error {
+	if !n.enabled {
+		return fmt.Errorf("email notifier is not enabled")
+	}
+
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	preheader := fmt.Sprintf("%s installs packages affected by %s", notice.AppName, notice.VulnerabilityID)
+	if len(notice.Packages) > 0 {
+		pkg := notice.Packages[0]
+		preheader = fmt.Sprintf("%s installs %s@%s", notice.AppName, pkg.Name, pkg.Version)
+		if len(notice.Packages) > 1 {
+			preheader += fmt.Sprintf(" and %d more", len(notice.Packages)-1)
+		}
+		preheader += " affected by " + notice.VulnerabilityID
+	}
+
+	data := exposureEmailData{
+		emailPage: emailPage{
+			Title:     fmt.Sprintf("%s: %s is exposed", notice.VulnerabilityID, notice.AppName),
+			Preheader: preheader,
+		},
+		Notice: notice,
+	}
+	var buf bytes.Buffer
+	if err := emailTemplates.ExecuteTemplate(&buf, "exposure", data); err != nil {
+		return fmt.Errorf("failed to build email body: %w", err)
+	}
+
+	return n.deliver(ctx, emailMessage{
+		From:    n.fromEmail,
+		To:      recipients,
+		Subject: fmt.Sprintf("[EXPOSED] %s: %s installs an affected package", notice.VulnerabilityID, notice.AppName),
+		HTML:    buf.String(),
+		Text:    buildExposureTextBody(notice),
+		AppName: notice.AppName,
+	})
+}
+
+// SendExecutiveReport sends the rendered executive report as the email digest, with
+// textBody (the Markdown report) as its plain-text alternative
+func (n *EmailNotifier) SendExecutiveReport(ctx context.Context, report *models.ExecutiveReport, htmlBody, textBody string, recipients []string) error {
+	if !n.enabled {
+		return fmt.Errorf("email notifier is not enabled")
+	}
+
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	subject := fmt.Sprintf("[Audit Checks] Security report %s to %s: %d critical, %d high open",
+		report.PeriodStart.Local().Format("2006-01-02"),
+		report.PeriodEnd.Local().Format("2006-01-02"),
+		report.Current.Critical,
+		report.Current.High,
+	)
+
+	return n.deliver(ctx, emailMessage{
+		From:    n.fromEmail,
+		To:      recipients,
+		Subject: subject,
+		HTML:    htmlBody,
+		Text:    textBody,
+	})
+}
+
+// deliver sends an email through the SMTP server, or the Resend API. An email without a
+// plain-text alternative gets one from its HTML body, so that mail gateways stripping
+// HTML and terminal clients still show its content.
+func (n *EmailNotifier) deliver(ctx context.Context, message emailMessage) error {
+	if strings.TrimSpace(message.Text) == "" {
+		message.Text = htmlToText(message.HTML)
+	}
+
+	to, err := n.recipients(ctx, message.To)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return err
 	}
+	message.To = to
 
-	req, err := http.NewRequestWithContext(ctx, "POST", resendAPIURL, bytes.NewBuffer(jsonData))
+	if n.smtp != nil {
+		return n.sendSMTP(ctx, message)
+	}
+
+	id, err := n.post(ctx, message)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return err
+	}
+	if n.record != nil && id != "" {
+		n.record(&models.EmailDelivery{
+			ID:         id,
+			AppName:    message.AppName,
+			Subject:    message.Subject,
+			Recipients: message.To,
+			Status:     models.EmailStatusSent,
+		})
+	}
+	return nil
+}
+
+// recipients returns the addresses of to an email is sent to: malformed addresses, which
+// would fail the whole email, and suppressed ones are left out with a warning
+func (n *EmailNotifier) recipients(ctx context.Context, to []string) ([]string, error) {
+	log := helpers.Logger(ctx)
+
+	suppressed := map[string]models.EmailSuppression{}
+	if n.suppressions != nil {
+		list, err := n.suppressions()
+		if err != nil {
+			// Sending to a suppressed address beats not sending at all
+			log.Warnf("Failed to read email suppressions: %v", err)
+		}
+		suppressed = models.SuppressedEmails(list)
+	}
+
+	var valid []string
+	for _, address := range to {
+		if err := helpers.ValidateEmail(address); err != nil {
+			log.Warnf("Email recipient skipped: %v", err)
+			continue
+		}
+		if s, ok := suppressed[strings.ToLower(address)]; ok {
+			log.Warnf("Email recipient skipped address=%s suppressed=%s since=%s", address, s.Source, s.CreatedAt.Format(time.DateOnly))
+			continue
+		}
+		valid = append(valid, address)
+	}
+
+	if len(valid) == 0 {
+		return nil, fmt.Errorf("no deliverable recipients among %s (malformed or suppressed)", strings.Join(to, ", "))
+	}
+	return valid, nil
+}
+
+// post sends an email to the Resend API and returns its ID
+func (n *EmailNotifier) post(ctx context.Context, message emailMessage) (string, error) {
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", n.apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+n.apiKey)
@@ -87,27 +365,36 @@ func (n *EmailNotifier) Send(ctx context.Context, report *models.Report, recipie
 
 	resp, err := n.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return "", fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		var errResp resendErrorResponse
 		if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil {
-			return fmt.Errorf("resend API error: %s", errResp.Message)
+			return "", fmt.Errorf("resend API error: %s", errResp.Message)
 		}
-		return fmt.Errorf("resend API error: status %d", resp.StatusCode)
+		return "", fmt.Errorf("resend API error: status %d", resp.StatusCode)
 	}
 
-	return nil
+	var sent resendSendResponse
+	_ = json.NewDecoder(resp.Body).Decode(&sent)
+	return sent.ID, nil
 }
 
-// resendPayload is the request payload for Resend API
-type resendPayload struct {
+// emailMessage is an email sent by EmailNotifier, in the shape of the Resend API request payload
+type emailMessage struct {
 	From    string   `json:"from"`
 	To      []string `json:"to"`
 	Subject string   `json:"subject"`
 	HTML    string   `json:"html"`
+	Text    string   `json:"text,omitempty"` // plain-text alternative, for clients that don't render HTML
+	AppName string   `json:"-"`              // the app the email is about, recorded with its delivery
+}
+
+// resendSendResponse is the response of the Resend API to a sent email
+type resendSendResponse struct {
+	ID string `json:"id"`
 }
 
 // resendErrorResponse is the error response from Resend API
@@ -136,107 +423,189 @@ func (n *EmailNotifier) buildSubject(report *models.Report) string {
 		severity, report.AppName, total)
 }
 
-// emailTemplate is the HTML template for email body
-var emailTemplate = template.Must(template.New("email").Funcs(template.FuncMap{
-	"upper": strings.ToUpper,
-	"severityColor": func(s string) string {
-		switch s {
-		case "critical":
-			return "#dc3545"
-		case "high":
-			return "#fd7e14"
-		case "moderate":
-			return "#ffc107"
-		case "low":
-			return "#28a745"
-		default:
-			return "#6c757d"
-		}
-	},
+// emailPage is what every email template's layout shows: the title, the preheader
+// shown after the subject in inbox lists, and the run ID in the footer
+type emailPage struct {
+	Title     string
+	Preheader string
+	RunID     string
+}
+
+// emailTemplates are the HTML templates of the emails, sharing the "head" and "foot"
+// layout: a single column up to 600px wide, with data tables stacking on phones and
+// colors following the reader's dark mode where the email client supports it
+var emailTemplates = template.Must(template.New("email").Funcs(template.FuncMap{
+	"upper":     strings.ToUpper,
+	"firstSeen": func(v models.Vulnerability) string { return v.FirstSeenLabel(time.Now()) },
+	// preheaderPad keeps clients from filling the preview after the preheader with the body
+	"preheaderPad": func() template.HTML { return template.HTML(strings.Repeat("&#847;&zwnj;&nbsp;", 40)) },
 }).Parse(`
-<!DOCTYPE html>
-<html>
+{{define "head"}}<!DOCTYPE html>
+<html lang="en">
 <head>
-    <meta charset="utf-8">
-    <style>
-        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; }
-        .container { max-width: 600px; margin: 0 auto; padding: 20px; }
-        .header { background: #f8f9fa; padding: 20px; border-radius: 8px; margin-bottom: 20px; }
-        .header h1 { margin: 0 0 10px 0; color: #212529; }
-        .summary { display: flex; gap: 10px; flex-wrap: wrap; margin: 20px 0; }
-        .severity-badge { padding: 8px 16px; border-radius: 4px; color: white; font-weight: bold; }
-        .critical { background: #dc3545; }
-        .high { background: #fd7e14; }
-        .moderate { background: #ffc107; color: #212529; }
-        .low { background: #28a745; }
-        table { width: 100%; border-collapse: collapse; margin: 20px 0; }
-        th, td { padding: 12px; text-align: left; border-bottom: 1px solid #dee2e6; }
-        th { background: #f8f9fa; }
-        .vuln-item { margin: 15px 0; padding: 15px; border: 1px solid #dee2e6; border-radius: 8px; }
-        .vuln-header { display: flex; justify-content: space-between; align-items: center; margin-bottom: 10px; }
-        .vuln-title { font-weight: bold; font-size: 16px; }
-        .ai-section { background: #e7f3ff; padding: 20px; border-radius: 8px; margin: 20px 0; }
-        .footer { text-align: center; color: #6c757d; font-size: 12px; margin-top: 30px; }
-    </style>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="color-scheme" content="light dark">
+<meta name="supported-color-schemes" content="light dark">
+<title>{{.Title}}</title>
+<style>
+    body { margin: 0; padding: 0; background: #f4f5f7; color: #212529; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; font-size: 15px; line-height: 1.5; -webkit-text-size-adjust: 100%; }
+    .preheader { display: none !important; visibility: hidden; opacity: 0; color: transparent; height: 0; width: 0; max-height: 0; max-width: 0; overflow: hidden; mso-hide: all; }
+    .wrapper { width: 100%; background: #f4f5f7; }
+    .container { width: 100%; max-width: 600px; margin: 0 auto; text-align: left; }
+    .card { background: #ffffff; border: 1px solid #dee2e6; border-radius: 8px; padding: 20px; margin: 0 0 16px 0; }
+    h1 { font-size: 22px; line-height: 1.3; margin: 0 0 8px 0; color: #212529; }
+    h2 { font-size: 18px; margin: 0 0 8px 0; color: #212529; }
+    p { margin: 0 0 8px 0; }
+    .muted { color: #6c757d; font-size: 13px; }
+    .badge { display: inline-block; padding: 4px 10px; margin: 0 6px 6px 0; border-radius: 4px; color: #ffffff; font-size: 13px; font-weight: bold; }
+    .critical { background: #dc3545; }
+    .high { background: #fd7e14; }
+    .moderate { background: #ffc107; color: #212529; }
+    .low { background: #28a745; }
+    .info { background: #6c757d; }
+    .sev-critical, .alert { border-left: 4px solid #dc3545; }
+    .sev-high { border-left: 4px solid #fd7e14; }
+    .sev-moderate { border-left: 4px solid #ffc107; }
+    .sev-low { border-left: 4px solid #28a745; }
+    .sev-info { border-left: 4px solid #6c757d; }
+    .ai { background: #e7f3ff; border-color: #b6d4fe; }
+    code, pre { font-family: SFMono-Regular, Menlo, Consolas, monospace; font-size: 13px; }
+    pre { white-space: pre-wrap; word-break: break-word; background: #f1f3f5; color: #212529; padding: 10px; border-radius: 4px; margin: 0 0 8px 0; }
+    .data { width: 100%; border-collapse: collapse; margin: 8px 0; }
+    .data th, .data td { padding: 8px; text-align: left; vertical-align: top; border-bottom: 1px solid #dee2e6; }
+    .data th { background: #f8f9fa; }
+    .footer { text-align: center; color: #6c757d; font-size: 12px; padding: 8px 16px 16px; }
+    @media (max-width: 620px) {
+        .wrapper td { padding: 0 !important; }
+        .card { border-radius: 0 !important; border-left-width: 0 !important; border-right-width: 0 !important; padding: 16px !important; margin-bottom: 8px !important; }
+        .sev-critical, .sev-high, .sev-moderate, .sev-low, .sev-info, .alert { border-left-width: 4px !important; }
+        h1 { font-size: 20px !important; }
+        .data thead { display: none !important; }
+        .data tr, .data td { display: block !important; width: auto !important; }
+        .data tr { border-bottom: 1px solid #dee2e6; padding: 6px 0; }
+        .data td { border-bottom: 0 !important; padding: 2px 0 !important; }
+        .data td[data-label]::before { content: attr(data-label) ": "; font-weight: bold; }
+    }
+    @media (prefers-color-scheme: dark) {
+        body, .wrapper { background: #121212 !important; color: #e4e6eb !important; }
+        .card { background: #1e1e1e !important; border-color: #3a3b3c !important; }
+        .ai { background: #172a45 !important; border-color: #2d4a72 !important; }
+        h1, h2 { color: #ffffff !important; }
+        .muted, .footer { color: #a8adb3 !important; }
+        pre { background: #2a2a2a !important; color: #e4e6eb !important; }
+        .data th { background: #2a2a2a !important; }
+        .data th, .data td, .data tr { border-color: #3a3b3c !important; }
+        a { color: #6ea8fe !important; }
+    }
+</style>
 </head>
 <body>
-    <div class="container">
-        <div class="header">
-            <h1>Security Audit Alert</h1>
-            <p><strong>App:</strong> {{.AppName}}</p>
-            <p><strong>Auditor:</strong> {{.AuditorType}}</p>
-            <p><strong>Date:</strong> {{.GeneratedAt}}</p>
-        </div>
-
-        <h2>Summary</h2>
-        <div class="summary">
-            {{if gt .Summary.Critical 0}}<span class="severity-badge critical">{{.Summary.Critical}} Critical</span>{{end}}
-            {{if gt .Summary.High 0}}<span class="severity-badge high">{{.Summary.High}} High</span>{{end}}
-            {{if gt .Summary.Moderate 0}}<span class="severity-badge moderate">{{.Summary.Moderate}} Moderate</span>{{end}}
-            {{if gt .Summary.Low 0}}<span class="severity-badge low">{{.Summary.Low}} Low</span>{{end}}
-        </div>
-        <p><strong>Total:</strong> {{.Summary.Total}} vulnerabilities</p>
-
-        {{if .AIAnalysis}}
-        <div class="ai-section">
-            <h3>AI Analysis</h3>
-            <p>{{.AIAnalysis.Summary}}</p>
-            {{if .AIAnalysis.Priority}}
-            <p><strong>Priority Fix Order:</strong></p>
-            <ol>
-            {{range .AIAnalysis.Priority}}
-                <li>{{.}}</li>
-            {{end}}
-            </ol>
-            {{end}}
-        </div>
-        {{end}}
-
-        <h2>Vulnerabilities</h2>
-        {{range .Vulnerabilities}}
-        <div class="vuln-item">
-            <div class="vuln-header">
-                <span class="vuln-title">{{.PackageName}}</span>
-                <span class="severity-badge" style="background: {{.Severity | severityColor}}">{{.Severity | upper}}</span>
-            </div>
-            <p><strong>{{.Title}}</strong></p>
-            {{if .CVEID}}<p><strong>CVE:</strong> {{.CVEID}}</p>{{end}}
-            {{if .VulnerableVersions}}<p><strong>Affected:</strong> {{.VulnerableVersions}}</p>{{end}}
-            {{if .PatchedVersions}}<p><strong>Fixed:</strong> {{.PatchedVersions}}</p>{{end}}
-            {{if .Recommendation}}<p><strong>Recommendation:</strong> {{.Recommendation}}</p>{{end}}
-        </div>
-        {{end}}
-
-        <div class="footer">
-            <p>Generated by Audit Checks</p>
-        </div>
-    </div>
+<div class="preheader">{{.Preheader}}{{preheaderPad}}</div>
+<table role="presentation" class="wrapper" width="100%" cellpadding="0" cellspacing="0" border="0"><tr><td align="center" style="padding: 16px 8px;">
+<div class="container">
+{{end}}
+
+{{define "foot"}}
+<div class="footer">Generated by Audit Checks{{if .RunID}} &middot; Run {{.RunID}}{{end}}</div>
+</div>
+</td></tr></table>
 </body>
 </html>
+{{end}}
+
+{{define "report"}}{{template "head" .}}
+<div class="card">
+    <h1>Security Audit Alert</h1>
+    <p class="muted"><strong>{{.AppName}}</strong> &middot; {{.AuditorType}} &middot; {{.GeneratedAt}}</p>
+    <p>
+    {{- if gt .Summary.Critical 0}}<span class="badge critical">{{.Summary.Critical}} Critical</span>{{end -}}
+    {{- if gt .Summary.High 0}}<span class="badge high">{{.Summary.High}} High</span>{{end -}}
+    {{- if gt .Summary.Moderate 0}}<span class="badge moderate">{{.Summary.Moderate}} Moderate</span>{{end -}}
+    {{- if gt .Summary.Low 0}}<span class="badge low">{{.Summary.Low}} Low</span>{{end -}}
+    {{- if gt .Summary.Info 0}}<span class="badge info">{{.Summary.Info}} Info</span>{{end -}}
+    </p>
+    <p><strong>Total:</strong> {{.Summary.Total}} vulnerabilities</p>
+</div>
+{{if .AIAnalysis}}
+<div class="card ai">
+    <h2>AI Analysis</h2>
+    <p>{{.AIAnalysis.Summary}}</p>
+    {{if .AIAnalysis.Priority}}
+    <p><strong>Priority Fix Order:</strong></p>
+    <ol>{{range .AIAnalysis.Priority}}<li>{{.}}</li>{{end}}</ol>
+    {{end}}
+</div>
+{{end}}
+{{range .Vulnerabilities}}
+<div class="card sev-{{.Severity}}">
+    <p><span class="badge {{.Severity}}">{{upper .Severity}}</span> <strong>{{.PackageName}}</strong></p>
+    <p><strong>{{.Title}}</strong></p>
+    {{if .CVEID}}<p><strong>CVE:</strong> {{.CVEID}}</p>{{end}}
+    {{with firstSeen .}}<p><strong>First seen:</strong> {{.}}</p>{{end}}
+    {{if .VulnerableVersions}}<p><strong>Affected:</strong> {{.VulnerableVersions}}</p>{{end}}
+    {{if .PatchedVersions}}<p><strong>Fixed:</strong> {{.PatchedVersions}}</p>{{end}}
+    {{if .Recommendation}}<p><strong>Recommendation:</strong> {{.Recommendation}}</p>{{end}}
+    {{if .FixSnippet}}<p><strong>Suggested fix:</strong></p><pre>{{.FixSnippet}}</pre>{{end}}
+</div>
+{{end}}
+{{template "foot" .}}{{end}}
+
+{{define "failures"}}{{template "head" .}}
+<div class="card alert">
+    <h1>Security audit failed: {{.AppName}}</h1>
+    <p>The following auditors could not complete, so their findings are missing from this run.</p>
+</div>
+{{range .Failures}}
+<div class="card alert">
+    <p><strong>{{upper .AuditorType}}</strong> <span class="muted">({{.Kind}})</span></p>
+    <pre>{{.Message}}</pre>
+    {{if .Hint}}<p><strong>Hint:</strong> {{.Hint}}</p>{{end}}
+</div>
+{{end}}
+{{template "foot" .}}{{end}}
+
+{{define "policies"}}{{template "head" .}}
+<div class="card alert">
+    <h1>Security policy violated: {{.AppName}}</h1>
+    <p>{{.AppName}} violates the following policies of your organization.</p>
+</div>
+{{range .Violations}}
+<div class="card alert">
+    <p><strong>{{.Policy}}</strong> <span class="muted">({{.Action}})</span></p>
+    {{if .Description}}<p>{{.Description}}</p>{{end}}
+    {{if .Error}}<p><strong>Could not be evaluated:</strong> {{.Error}}</p>{{end}}
+    {{if .Subjects}}<ul>{{range .Subjects}}<li><code>{{.}}</code></li>{{end}}</ul>{{end}}
+</div>
+{{end}}
+{{template "foot" .}}{{end}}
+
+{{define "exposure"}}{{template "head" .}}
+<div class="card alert">
+    <h1>{{.Notice.VulnerabilityID}}: {{.Notice.AppName}} is exposed</h1>
+    {{if .Notice.Summary}}<p><strong>{{.Notice.Summary}}</strong></p>{{end}}
+    <p>{{.Notice.AppName}} installs the following affected packages:</p>
+    <table class="data" cellpadding="0" cellspacing="0" border="0">
+    <thead><tr><th>Package</th><th>Version</th><th>Lockfile</th></tr></thead>
+    <tbody>
+    {{range .Notice.Packages}}<tr><td data-label="Package"><code>{{.Name}}</code></td><td data-label="Version">{{.Version}}</td><td data-label="Lockfile">{{.Lockfile}}</td></tr>
+    {{end}}</tbody>
+    </table>
+    {{if .Notice.Message}}<pre>{{.Notice.Message}}</pre>{{end}}
+</div>
+{{template "foot" .}}{{end}}
+
+{{define "notice"}}{{template "head" .}}
+<div class="card">
+    {{range .Paragraphs}}<p>{{range $i, $line := .}}{{if $i}}<br>{{end}}{{$line}}{{end}}</p>
+    {{end}}
+</div>
+{{template "foot" .}}{{end}}
 `))
 
-// emailData holds data for the email template
+// emailData holds data for the report template
 type emailData struct {
+	emailPage
 	AppName     string
 	AuditorType string
 	GeneratedAt string
@@ -246,14 +615,46 @@ type emailData struct {
 		High     int
 		Moderate int
 		Low      int
+		Info     int
 	}
 	Vulnerabilities []models.Vulnerability
 	AIAnalysis      *models.AIAnalysis
 }
 
+// failureEmailData holds data for the failures template
+type failureEmailData struct {
+	emailPage
+	AppName  string
+	Failures []models.AuditFailure
+}
+
+// policyEmailData holds data for the policies template
+type policyEmailData struct {
+	emailPage
+	AppName    string
+	Violations []models.PolicyViolation
+}
+
+// exposureEmailData holds data for the exposure template
+type exposureEmailData struct {
+	emailPage
+	Notice *models.ExposureNotice
+}
+
+// noticeEmailData holds data for the notice template: the lines of each paragraph
+type noticeEmailData struct {
+	emailPage
+	Paragraphs [][]string
+}
+
 // buildHTMLBody creates the HTML body for the email
 func (n *EmailNotifier) buildHTMLBody(report *models.Report) (string, error) {
 	data := emailData{
+		emailPage: emailPage{
+			Title:     "Security Audit Alert: " + report.AppName,
+			Preheader: fmt.Sprintf("%s in %s (%s)", severityCountsText(report.AuditResult), report.AppName, report.AuditorType),
+			RunID:     report.AuditResult.RunID,
+		},
 		AppName:         report.AppName,
 		AuditorType:     report.AuditorType,
 		GeneratedAt:     report.GeneratedAt.Format("2006-01-02 15:04:05 UTC"),
@@ -265,11 +666,171 @@ func (n *EmailNotifier) buildHTMLBody(report *models.Report) (string, error) {
 	data.Summary.High = report.AuditResult.HighCount
 	data.Summary.Moderate = report.AuditResult.ModerateCount
 	data.Summary.Low = report.AuditResult.LowCount
+	data.Summary.Info = report.AuditResult.InfoCount
 
 	var buf bytes.Buffer
-	if err := emailTemplate.Execute(&buf, data); err != nil {
+	if err := emailTemplates.ExecuteTemplate(&buf, "report", data); err != nil {
 		return "", fmt.Errorf("failed to execute template: %w", err)
 	}
 
 	return buf.String(), nil
 }
+
+// severityCountsText lists the non-zero severity counts of a result, e.g. "1 critical, 2 high"
+func severityCountsText(result *models.AuditResult) string {
+	var parts []string
+	for _, count := range []struct {
+		n        int
+		severity string
+	}{
+		{result.CriticalCount, models.SeverityCritical},
+		{result.HighCount, models.SeverityHigh},
+		{result.ModerateCount, models.SeverityModerate},
+		{result.LowCount, models.SeverityLow},
+		{result.InfoCount, models.SeverityInfo},
+	} {
+		if count.n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.severity))
+		}
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%d vulnerabilities", result.TotalVulnerabilities)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// buildTextBody creates the plain-text alternative of the report email
+func buildTextBody(report *models.Report) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Security Audit Alert: %s\n\n", report.AppName)
+	fmt.Fprintf(&sb, "Auditor: %s\n", report.AuditorType)
+	fmt.Fprintf(&sb, "Date: %s\n\n", report.GeneratedAt.Format("2006-01-02 15:04:05 UTC"))
+	fmt.Fprintf(&sb, "Summary: %s (%d total)\n", severityCountsText(report.AuditResult), report.AuditResult.TotalVulnerabilities)
+
+	if report.AIAnalysis != nil {
+		fmt.Fprintf(&sb, "\nAI Analysis\n%s\n", report.AIAnalysis.Summary)
+		if len(report.AIAnalysis.Priority) > 0 {
+			sb.WriteString("\nPriority fix order:\n")
+			for i, item := range report.AIAnalysis.Priority {
+				fmt.Fprintf(&sb, "%d. %s\n", i+1, item)
+			}
+		}
+	}
+
+	if len(report.Vulnerabilities) > 0 {
+		sb.WriteString("\nVulnerabilities\n")
+	}
+	for i, v := range report.Vulnerabilities {
+		fmt.Fprintf(&sb, "\n%d. [%s] %s: %s\n", i+1, strings.ToUpper(v.Severity), v.PackageName, v.Title)
+		for _, field := range []struct{ label, value string }{
+			{"CVE", v.CVEID},
+			{"First seen", v.FirstSeenLabel(time.Now())},
+			{"Affected", v.VulnerableVersions},
+			{"Fixed", v.PatchedVersions},
+			{"Recommendation", v.Recommendation},
+		} {
+			if field.value != "" {
+				fmt.Fprintf(&sb, "   %s: %s\n", field.label, field.value)
+			}
+		}
+		if v.FixSnippet != "" {
+			sb.WriteString("   Suggested fix:\n")
+			for _, line := range strings.Split(strings.TrimRight(v.FixSnippet, "\n"), "\n") {
+				fmt.Fprintf(&sb, "     %s\n", line)
+			}
+		}
+	}
+
+	sb.WriteString(textFooter(report.AuditResult.RunID))
+	return sb.String()
+}
+
+// buildFailuresTextBody creates the plain-text alternative of the failures email
+func buildFailuresTextBody(appName, runID string, failures []models.AuditFailure) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Security audit failed: %s\n\n", appName)
+	sb.WriteString("The following auditors could not complete, so their findings are missing from this run.\n")
+	for _, f := range failures {
+		fmt.Fprintf(&sb, "\n%s (%s)\n%s\n", strings.ToUpper(f.AuditorType), f.Kind, f.Message)
+		if f.Hint != "" {
+			fmt.Fprintf(&sb, "Hint: %s\n", f.Hint)
+		}
+	}
+	sb.WriteString(textFooter(runID))
+	return sb.String()
+}
+
+// buildPolicyTextBody creates the plain-text alternative of the policy violations email
+func buildPolicyTextBody(appName, runID string, violations []models.PolicyViolation) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Security policy violated: %s\n\n", appName)
+	fmt.Fprintf(&sb, "%s violates the following policies of your organization.\n", appName)
+	for _, v := range violations {
+		fmt.Fprintf(&sb, "\n%s (%s)\n", v.Policy, v.Action)
+		if v.Description != "" {
+			fmt.Fprintf(&sb, "%s\n", v.Description)
+		}
+		if v.Error != "" {
+			fmt.Fprintf(&sb, "Could not be evaluated: %s\n", v.Error)
+		}
+		for _, subject := range v.Subjects {
+			fmt.Fprintf(&sb, "- %s\n", subject)
+		}
+	}
+	sb.WriteString(textFooter(runID))
+	return sb.String()
+}
+
+// buildExposureTextBody creates the plain-text alternative of the exposure email
+func buildExposureTextBody(notice *models.ExposureNotice) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s is exposed\n\n", notice.VulnerabilityID, notice.AppName)
+	if notice.Summary != "" {
+		fmt.Fprintf(&sb, "%s\n\n", notice.Summary)
+	}
+	fmt.Fprintf(&sb, "%s installs the following affected packages:\n", notice.AppName)
+	for _, pkg := range notice.Packages {
+		fmt.Fprintf(&sb, "- %s@%s (%s)\n", pkg.Name, pkg.Version, pkg.Lockfile)
+	}
+	if notice.Message != "" {
+		fmt.Fprintf(&sb, "\n%s\n", notice.Message)
+	}
+	sb.WriteString(textFooter(""))
+	return sb.String()
+}
+
+// textFooter is the signature ending the plain-text alternatives, with the run ID if any
+func textFooter(runID string) string {
+	if runID != "" {
+		return "\n-- \nGenerated by Audit Checks, run " + runID + "\n"
+	}
+	return "\n-- \nGenerated by Audit Checks\n"
+}
+
+// Patterns of htmlToText
+var (
+	htmlHiddenPattern = regexp.MustCompile(`(?is)<(head|style|script|title)\b.*?</(head|style|script|title)>|<div class="preheader">.*?</div>|<!--.*?-->`)
+	htmlBreakPattern  = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|h[1-6]|li|tr|table|pre|ol|ul|blockquote)>`)
+	htmlItemPattern   = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	htmlCellPattern   = regexp.MustCompile(`(?i)</t[dh]>`)
+	htmlTagPattern    = regexp.MustCompile(`<[^>]*>`)
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText renders an HTML email body as plain text: the visible text, with line
+// breaks after blocks, list items as "- " lines and table cells separated by tabs
+func htmlToText(body string) string {
+	text := htmlHiddenPattern.ReplaceAllString(body, "")
+	text = htmlItemPattern.ReplaceAllString(text, "- ")
+	text = htmlCellPattern.ReplaceAllString(text, "\t")
+	text = htmlBreakPattern.ReplaceAllString(text, "\n")
+	text = htmlTagPattern.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	text = blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(text) + "\n"
+}
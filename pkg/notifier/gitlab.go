@@ -0,0 +1,360 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// gitlabMaxDescription is the number of characters of an issue description kept, well
+// below GitLab's limit of 1,048,576
+const gitlabMaxDescription = 100000
+
+// gitlabMaxFindings is the number of findings listed in an issue description
+const gitlabMaxFindings = 100
+
+// errGitLabIssueGone is returned when updating an issue that was closed or deleted
+var errGitLabIssueGone = errors.New("gitlab issue was closed or deleted")
+
+// GitLabNotifier keeps a confidential issue per app open in a GitLab project while the
+// app has vulnerabilities: created by the first run finding some, its title and
+// description updated with the findings of later runs, and closed once a run without
+// failures finds none. Issues go to the app's own project, or the default one.
+type GitLabNotifier struct {
+	serverURL string
+	token     string
+	project   string
+	labels    []string
+	enabled   bool
+	client    *http.Client
+}
+
+// NewGitLabNotifier creates a new GitLabNotifier creating issues in project (an ID or a
+// path like group/project) with labels, through the API of the instance at serverURL
+func NewGitLabNotifier(serverURL, token, project string, labels []string, enabled bool) *GitLabNotifier {
+	return &GitLabNotifier{
+		serverURL: strings.TrimSuffix(serverURL, "/"),
+		token:     token,
+		project:   project,
+		labels:    labels,
+		enabled:   enabled && serverURL != "" && token != "",
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns "gitlab"
+func (n *GitLabNotifier) Name() string {
+	return "gitlab"
+}
+
+// Enabled returns true if the notifier is configured
+func (n *GitLabNotifier) Enabled() bool {
+	return n.enabled
+}
+
+// Project returns the project of an app's issue: its own project, or the default one
+func (n *GitLabNotifier) Project(appProject string) string {
+	if appProject != "" {
+		return appProject
+	}
+	return n.project
+}
+
+// Send creates an issue for a report in the default project. Reports without
+// vulnerabilities are skipped.
+func (n *GitLabNotifier) Send(ctx context.Context, report *models.Report, recipients []string) error {
+	if len(report.Vulnerabilities) == 0 {
+		return nil
+	}
+	combinedReport := &models.CombinedAppReport{
+		AppName:     report.AppName,
+		AppPath:     report.AppPath,
+		Reports:     []*models.Report{report},
+		GeneratedAt: report.GeneratedAt,
+	}
+	_, err := n.CreateIssue(ctx, n.project, combinedReport)
+	return err
+}
+
+// GitLabIssueRef returns the reference of an issue stored with its app: <project>#<iid>
+func GitLabIssueRef(project string, iid int) string {
+	return fmt.Sprintf("%s#%d", project, iid)
+}
+
+// ParseGitLabIssueRef returns the project and IID of an issue reference; ok is false
+// when ref is not one
+func ParseGitLabIssueRef(ref string) (project string, iid int, ok bool) {
+	i := strings.LastIndex(ref, "#")
+	if i <= 0 {
+		return "", 0, false
+	}
+	iid, err := strconv.Atoi(ref[i+1:])
+	if err != nil || iid <= 0 {
+		return "", 0, false
+	}
+	return ref[:i], iid, true
+}
+
+// gitlabIssue is an issue created or edited through the Issues API
+type gitlabIssue struct {
+	Title        string `json:"title,omitempty"`
+	Description  string `json:"description,omitempty"`
+	Labels       string `json:"labels,omitempty"` // comma-separated
+	Confidential bool   `json:"confidential,omitempty"`
+	StateEvent   string `json:"state_event,omitempty"` // close or reopen
+}
+
+// gitlabIssueResponse is an issue returned by the Issues API
+type gitlabIssueResponse struct {
+	IID    int    `json:"iid"`
+	State  string `json:"state"` // opened or closed
+	WebURL string `json:"web_url"`
+}
+
+// gitlabErrorResponse is the error response of the GitLab API; message is a string or,
+// for validation errors, an object of messages per field
+type gitlabErrorResponse struct {
+	Message json.RawMessage `json:"message"`
+	Error   string          `json:"error"`
+}
+
+// CreateIssue creates the confidential issue of an app's combined report in project
+// and returns its IID
+func (n *GitLabNotifier) CreateIssue(ctx context.Context, project string, combinedReport *models.CombinedAppReport) (int, error) {
+	issue := n.buildIssue(combinedReport)
+	issue.Confidential = true
+
+	var created gitlabIssueResponse
+	if err := n.request(ctx, http.MethodPost, n.issuesPath(project), issue, &created); err != nil {
+		return 0, err
+	}
+	return created.IID, nil
+}
+
+// UpdateIssue replaces the title, description and labels of an app's open issue with
+// those of its latest combined report. Returns errGitLabIssueGone when the issue was
+// closed, e.g. by hand, or deleted.
+func (n *GitLabNotifier) UpdateIssue(ctx context.Context, project string, iid int, combinedReport *models.CombinedAppReport) error {
+	var updated gitlabIssueResponse
+	err := n.request(ctx, http.MethodPut, fmt.Sprintf("%s/%d", n.issuesPath(project), iid), n.buildIssue(combinedReport), &updated)
+	if err != nil {
+		return err
+	}
+	if updated.State == "closed" {
+		return errGitLabIssueGone
+	}
+	return nil
+}
+
+// CloseIssue comments on an issue with note, then closes it
+func (n *GitLabNotifier) CloseIssue(ctx context.Context, project string, iid int, note string) error {
+	path := fmt.Sprintf("%s/%d", n.issuesPath(project), iid)
+	if err := n.request(ctx, http.MethodPost, path+"/notes", map[string]string{"body": note}, nil); err != nil {
+		return err
+	}
+	return n.request(ctx, http.MethodPut, path, gitlabIssue{StateEvent: "close"}, nil)
+}
+
+// issuesPath returns the API path of a project's issues; paths like group/project are
+// URL-encoded as the API expects
+func (n *GitLabNotifier) issuesPath(project string) string {
+	return "/api/v4/projects/" + strings.ReplaceAll(url.PathEscape(project), "/", "%2F") + "/issues"
+}
+
+// buildIssue returns the title, description and labels of an app's issue
+func (n *GitLabNotifier) buildIssue(combinedReport *models.CombinedAppReport) gitlabIssue {
+	summary := combinedReport.GetCombinedSummary()
+
+	var vulns []models.Vulnerability
+	for _, report := range combinedReport.Reports {
+		vulns = append(vulns, report.Vulnerabilities...)
+	}
+	highest := highestSeverity(vulns)
+
+	labels := slices.Clone(n.labels)
+	labels = append(labels, "severity::"+highest)
+
+	return gitlabIssue{
+		Title: fmt.Sprintf("Security audit: %s - %d vulnerabilit%s (highest: %s)",
+			combinedReport.AppName, summary.Total, pluralY(summary.Total), highest),
+		Description: buildGitLabDescription(combinedReport, vulns),
+		Labels:      strings.Join(labels, ","),
+	}
+}
+
+// buildGitLabDescription renders the issue description: a table of the findings per
+// auditor, the findings themselves and the failed auditors
+func buildGitLabDescription(combinedReport *models.CombinedAppReport, vulns []models.Vulnerability) string {
+	var sb strings.Builder
+	summary := combinedReport.GetCombinedSummary()
+
+	fmt.Fprintf(&sb, "Vulnerabilities found in **%s** (`%s`) by audit-checks.\n\n",
+		gitlabEscape(combinedReport.AppName), gitlabCode(combinedReport.AppPath))
+
+	sb.WriteString("| Auditor | Critical | High | Moderate | Low | Info | Total |\n| :-- | --: | --: | --: | --: | --: | --: |\n")
+	for _, report := range combinedReport.Reports {
+		s := report.GetSummary()
+		fmt.Fprintf(&sb, "| %s | %d | %d | %d | %d | %d | %d |\n",
+			gitlabCell(report.AuditorType), s.Critical, s.High, s.Moderate, s.Low, s.Info, s.Total)
+	}
+	if len(combinedReport.Reports) > 1 {
+		fmt.Fprintf(&sb, "| **Total** | **%d** | **%d** | **%d** | **%d** | **%d** | **%d** |\n",
+			summary.Critical, summary.High, summary.Moderate, summary.Low, summary.Info, summary.Total)
+	}
+
+	// Most severe findings first, or the oldest or riskiest with the report's issue order
+	now := time.Now()
+	models.SortVulnerabilities(vulns, combinedReport.IssueOrder, now)
+	sb.WriteString("\n### Findings\n\n| Severity | Package | Vulnerability | Title | Patched | First Seen |\n| :-- | :-- | :-- | :-- | :-- | :-- |\n")
+	for i, v := range vulns {
+		if i == gitlabMaxFindings {
+			fmt.Fprintf(&sb, "\n_... and %d more_\n", len(vulns)-i)
+			break
+		}
+		id := gitlabCell(v.CVEID)
+		if v.CVEID != "" && v.URL != "" {
+			id = fmt.Sprintf("[%s](%s)", id, v.URL)
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s | %s |\n",
+			strings.ToUpper(v.Severity), gitlabCell(v.PackageName), id, gitlabCell(truncateRunes(v.Title, 150)),
+			gitlabCell(v.PatchedVersions), gitlabCell(v.FirstSeenLabel(now)))
+	}
+
+	if len(combinedReport.Failures) > 0 {
+		sb.WriteString("\n### Failed Auditors\n\nFindings of these auditors may be missing.\n\n| Auditor | Kind | Error | Hint |\n| :-- | :-- | :-- | :-- |\n")
+		for _, f := range combinedReport.Failures {
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s |\n",
+				gitlabCell(strings.ToUpper(f.AuditorType)), gitlabCell(f.Kind),
+				gitlabCell(truncateRunes(f.Message, maxFailureMessageLength)), gitlabCell(f.Hint))
+		}
+	}
+
+	if len(combinedReport.PolicyViolations) > 0 {
+		sb.WriteString("\n### Policies Violated\n\n| Policy | Action | Violated By |\n| :-- | :-- | :-- |\n")
+		for _, v := range combinedReport.PolicyViolations {
+			fmt.Fprintf(&sb, "| %s | %s | %s |\n",
+				gitlabCell(v.Policy), strings.ToUpper(v.Action), gitlabCell(policyViolationDetail(v, 10)))
+		}
+	}
+
+	// AI Summary if available (from any report)
+	for _, report := range combinedReport.Reports {
+		if report.AIAnalysis != nil && report.AIAnalysis.Summary != "" {
+			fmt.Fprintf(&sb, "\n### AI Summary\n\n%s\n", gitlabEscape(report.AIAnalysis.Summary))
+			break
+		}
+	}
+
+	if commands := combinedFixCommands(combinedReport); len(commands) > 0 {
+		fmt.Fprintf(&sb, "\nRun %s to fix issues.\n", strings.Join(commands, " and "))
+	}
+
+	sb.WriteString("\n---\n_This issue is updated by every audit and closed once the vulnerabilities are fixed.")
+	if combinedReport.RunID != "" {
+		fmt.Fprintf(&sb, " Run %s.", combinedReport.RunID)
+	}
+	sb.WriteString("_\n")
+
+	return truncateRunes(sb.String(), gitlabMaxDescription)
+}
+
+// gitlabEscape escapes GitLab Flavored Markdown characters, and the references (@user,
+// #issue, !merge request, ~label) GitLab would otherwise link and notify
+func gitlabEscape(s string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"*", "\\*",
+		"_", "\\_",
+		"~", "\\~",
+		"`", "\\`",
+		"|", "\\|",
+		"#", "\\#",
+		"@", "\\@",
+		"!", "\\!",
+		"[", "\\[",
+		"]", "\\]",
+		"<", "&lt;",
+		">", "&gt;",
+	)
+	return replacer.Replace(s)
+}
+
+// gitlabCell escapes a table cell, which must stay on one line
+func gitlabCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return "-"
+	}
+	return gitlabEscape(s)
+}
+
+// gitlabCode makes s safe inside an inline code span
+func gitlabCode(s string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(s), " "), "`", "'")
+}
+
+// request sends an API request with body as JSON, decoding the response into out when non-nil
+func (n *GitLabNotifier) request(ctx context.Context, method, path string, body, out any) error {
+	if !n.enabled {
+		return fmt.Errorf("gitlab notifier is not enabled")
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, n.serverURL+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("PRIVATE-TOKEN", n.token)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodPut {
+		return errGitLabIssueGone
+	}
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var errResp gitlabErrorResponse
+		if json.Unmarshal(respBody, &errResp) == nil {
+			var message string
+			if json.Unmarshal(errResp.Message, &message) != nil {
+				message = string(errResp.Message)
+			}
+			if message == "" {
+				message = errResp.Error
+			}
+			if message != "" {
+				return fmt.Errorf("gitlab API error: status %d: %s", resp.StatusCode, message)
+			}
+		}
+		return fmt.Errorf("gitlab API error: status %d", resp.StatusCode)
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// DefaultGotifyPriorities maps vulnerability severities to Gotify message priorities
+// (0 to 10; the Android app notifies silently below 4 and with sound from 8)
+var DefaultGotifyPriorities = map[string]int{
+	models.SeverityCritical: 10,
+	models.SeverityHigh:     8,
+	models.SeverityModerate: 5,
+	models.SeverityLow:      3,
+	models.SeverityInfo:     1,
+}
+
+// GotifyNotifier pushes a message per app to a self-hosted Gotify server, as the
+// application whose token it is configured with, with the priority of the highest
+// severity found. It needs no cloud service, for air-gapped environments.
+type GotifyNotifier struct {
+	serverURL  string
+	token      string
+	priorities map[string]int
+	enabled    bool
+	client     *http.Client
+}
+
+// NewGotifyNotifier creates a new GotifyNotifier; severities left out of priorities use
+// DefaultGotifyPriorities
+func NewGotifyNotifier(serverURL, token string, priorities map[string]int, enabled bool) *GotifyNotifier {
+	merged := make(map[string]int, len(DefaultGotifyPriorities))
+	for severity, priority := range DefaultGotifyPriorities {
+		merged[severity] = priority
+	}
+	for severity, priority := range priorities {
+		merged[severity] = priority
+	}
+
+	return &GotifyNotifier{
+		serverURL:  strings.TrimSuffix(serverURL, "/"),
+		token:      token,
+		priorities: merged,
+		enabled:    enabled && serverURL != "" && token != "",
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns "gotify"
+func (n *GotifyNotifier) Name() string {
+	return "gotify"
+}
+
+// Enabled returns true if the notifier is configured
+func (n *GotifyNotifier) Enabled() bool {
+	return n.enabled
+}
+
+// Priority returns the message priority of a severity
+func (n *GotifyNotifier) Priority(severity string) int {
+	if priority, ok := n.priorities[severity]; ok {
+		return priority
+	}
+	return 5
+}
+
+// gotifyMessage is a message created through the Gotify message API
+type gotifyMessage struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras,omitempty"`
+}
+
+// gotifyErrorResponse is the error response of a Gotify server
+type gotifyErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorCode        int    `json:"errorCode"`
+	ErrorDescription string `json:"errorDescription"`
+}
+
+// gotifyMarkdown are the extras rendering a message as Markdown in the Gotify clients
+var gotifyMarkdown = map[string]any{
+	"client::display": map[string]string{"contentType": "text/markdown"},
+}
+
+// Send pushes a report
+func (n *GotifyNotifier) Send(ctx context.Context, report *models.Report, recipients []string) error {
+	combinedReport := &models.CombinedAppReport{
+		AppName:     report.AppName,
+		AppPath:     report.AppPath,
+		Reports:     []*models.Report{report},
+		GeneratedAt: report.GeneratedAt,
+	}
+	return n.SendCombined(ctx, combinedReport)
+}
+
+// SendCombined pushes the combined report of an app: the findings per auditor, the
+// most severe ones and the failed auditors
+func (n *GotifyNotifier) SendCombined(ctx context.Context, combinedReport *models.CombinedAppReport) error {
+	summary := combinedReport.GetCombinedSummary()
+
+	var vulns []models.Vulnerability
+	var body strings.Builder
+	for _, report := range combinedReport.Reports {
+		if len(report.Vulnerabilities) == 0 {
+			continue
+		}
+		s := report.GetSummary()
+		fmt.Fprintf(&body, "- **%s**: %d critical, %d high, %d moderate, %d low, %d info\n",
+			gotifyEscape(report.AuditorType), s.Critical, s.High, s.Moderate, s.Low, s.Info)
+		vulns = append(vulns, report.Vulnerabilities...)
+	}
+	for _, failure := range combinedReport.Failures {
+		fmt.Fprintf(&body, "- **%s** failed (%s): %s\n",
+			gotifyEscape(failure.AuditorType), failure.Kind, gotifyEscape(truncateRunes(failure.Message, maxFailureMessageLength)))
+	}
+	for _, v := range combinedReport.PolicyViolations {
+		fmt.Fprintf(&body, "- policy **%s** violated (%s): %s\n",
+			gotifyEscape(v.Policy), v.Action, gotifyEscape(policyViolationDetail(v, 3)))
+	}
+
+	// Most severe findings first, or the oldest or riskiest with the report's issue order
+	models.SortVulnerabilities(vulns, combinedReport.IssueOrder, time.Now())
+	if len(vulns) > 0 {
+		body.WriteString("\n")
+	}
+	for i, v := range vulns {
+		if i == 10 {
+			fmt.Fprintf(&body, "\n_...and %d more_\n", len(vulns)-i)
+			break
+		}
+		id := v.CVEID
+		if id == "" {
+			id = v.Title
+		}
+		fmt.Fprintf(&body, "- `%s` %s: %s", strings.ToUpper(v.Severity), gotifyEscape(v.PackageName), gotifyEscape(id))
+		if ago := v.FirstSeenAgo(time.Now()); ago != "" {
+			fmt.Fprintf(&body, " _(%s)_", ago)
+		}
+		body.WriteString("\n")
+	}
+
+	if commands := combinedFixCommands(combinedReport); len(commands) > 0 {
+		fmt.Fprintf(&body, "\nRun %s to fix issues\n", strings.Join(commands, " and "))
+	}
+	if combinedReport.RunID != "" {
+		fmt.Fprintf(&body, "\n_Run %s_\n", combinedReport.RunID)
+	}
+
+	message := gotifyMessage{
+		Message:  truncateRunes(strings.TrimSpace(body.String()), 4000),
+		Priority: n.Priority(models.SeverityModerate),
+		Extras:   gotifyMarkdown,
+	}
+	if len(vulns) > 0 {
+		highest := highestSeverity(vulns)
+		message.Title = fmt.Sprintf("%s: %d vulnerabilit%s (highest: %s)", combinedReport.AppName, summary.Total, pluralY(summary.Total), highest)
+		message.Priority = n.Priority(highest)
+	} else {
+		message.Title = fmt.Sprintf("%s: %s", combinedReport.AppName, problemSummary(combinedReport))
+	}
+
+	return n.push(ctx, message)
+}
+
+// SendExposure pushes a broadcast exposure notice, at the priority of high findings
+func (n *GotifyNotifier) SendExposure(ctx context.Context, notice *models.ExposureNotice) error {
+	var body strings.Builder
+	if notice.Summary != "" {
+		fmt.Fprintf(&body, "%s\n\n", gotifyEscape(notice.Summary))
+	}
+	for _, pkg := range notice.Packages {
+		fmt.Fprintf(&body, "- %s@%s (%s)\n", gotifyEscape(pkg.Name), gotifyEscape(pkg.Version), gotifyEscape(pkg.Lockfile))
+	}
+	if notice.Message != "" {
+		fmt.Fprintf(&body, "\n%s\n", gotifyEscape(notice.Message))
+	}
+
+	return n.push(ctx, gotifyMessage{
+		Title:    fmt.Sprintf("%s: %s is exposed", notice.VulnerabilityID, notice.AppName),
+		Message:  truncateRunes(strings.TrimSpace(body.String()), 4000),
+		Priority: n.Priority(models.SeverityHigh),
+		Extras:   gotifyMarkdown,
+	})
+}
+
+// SendTestMessage pushes a plain message, such as a notifier drill, at the priority of
+// moderate findings
+func (n *GotifyNotifier) SendTestMessage(ctx context.Context, title, text string) error {
+	if err := n.push(ctx, gotifyMessage{
+		Title:    title,
+		Message:  truncateRunes(text, 4000),
+		Priority: n.Priority(models.SeverityModerate),
+	}); err != nil {
+		return fmt.Errorf("failed to send test message: %w", err)
+	}
+	return nil
+}
+
+// gotifyEscape escapes Markdown characters in text shown by the Gotify clients
+func gotifyEscape(s string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"*", "\\*",
+		"_", "\\_",
+		"`", "\\`",
+		"[", "\\[",
+		"]", "\\]",
+	)
+	return replacer.Replace(s)
+}
+
+// push creates a message as the application of the token
+func (n *GotifyNotifier) push(ctx context.Context, message gotifyMessage) error {
+	if !n.enabled {
+		return fmt.Errorf("gotify notifier is not enabled")
+	}
+
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.serverURL+"/message", bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", n.token)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var errResp gotifyErrorResponse
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.ErrorDescription != "" {
+			return fmt.Errorf("gotify API error: status %d: %s", resp.StatusCode, errResp.ErrorDescription)
+		}
+		return fmt.Errorf("gotify API error: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
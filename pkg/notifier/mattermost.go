@@ -0,0 +1,309 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// mattermostMaxMessage is the number of characters Mattermost accepts in a post
+const mattermostMaxMessage = 16383
+
+// MattermostNotifier posts notifications to Mattermost as Markdown messages with tables,
+// through a bot (server URL, access token and default channel ID) or an incoming
+// webhook. An app can route its notifications to its own channel: a channel ID for the
+// bot, or a channel name for the webhook, which must be allowed to override its channel.
+type MattermostNotifier struct {
+	serverURL  string
+	botToken   string
+	channelID  string
+	webhookURL string
+	enabled    bool
+	client     *http.Client
+}
+
+// NewMattermostNotifier creates a new MattermostNotifier. The bot (serverURL, botToken
+// and channelID) is used when configured, the webhook otherwise.
+func NewMattermostNotifier(serverURL, botToken, channelID, webhookURL string, enabled bool) *MattermostNotifier {
+	return &MattermostNotifier{
+		serverURL:  strings.TrimSuffix(serverURL, "/"),
+		botToken:   botToken,
+		channelID:  channelID,
+		webhookURL: webhookURL,
+		enabled:    enabled && (webhookURL != "" || (serverURL != "" && botToken != "" && channelID != "")),
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns "mattermost"
+func (n *MattermostNotifier) Name() string {
+	return "mattermost"
+}
+
+// Enabled returns true if the notifier is configured
+func (n *MattermostNotifier) Enabled() bool {
+	return n.enabled
+}
+
+// usesBot returns true if messages are posted by the bot rather than the webhook
+func (n *MattermostNotifier) usesBot() bool {
+	return n.serverURL != "" && n.botToken != "" && n.channelID != ""
+}
+
+// Send posts a report to the default channel
+func (n *MattermostNotifier) Send(ctx context.Context, report *models.Report, recipients []string) error {
+	combinedReport := &models.CombinedAppReport{
+		AppName:     report.AppName,
+		AppPath:     report.AppPath,
+		Reports:     []*models.Report{report},
+		GeneratedAt: report.GeneratedAt,
+	}
+	return n.SendCombined(ctx, combinedReport, "")
+}
+
+// SendCombined posts the combined report of an app to appChannel, or to the default
+// channel when empty
+func (n *MattermostNotifier) SendCombined(ctx context.Context, combinedReport *models.CombinedAppReport, appChannel string) error {
+	return n.post(ctx, buildMattermostMessage(combinedReport), appChannel)
+}
+
+// SendExposure posts a broadcast exposure notice to appChannel, or to the default
+// channel when empty
+func (n *MattermostNotifier) SendExposure(ctx context.Context, notice *models.ExposureNotice, appChannel string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "#### :rotating_light: %s: %s is exposed\n\n", mattermostEscape(notice.VulnerabilityID), mattermostEscape(notice.AppName))
+	if notice.Summary != "" {
+		fmt.Fprintf(&sb, "%s\n\n", mattermostEscape(notice.Summary))
+	}
+
+	sb.WriteString("| Package | Version | Lockfile |\n| :-- | :-- | :-- |\n")
+	for _, pkg := range notice.Packages {
+		fmt.Fprintf(&sb, "| %s | %s | %s |\n", mattermostCell(pkg.Name), mattermostCell(pkg.Version), mattermostCell(pkg.Lockfile))
+	}
+
+	if notice.Message != "" {
+		fmt.Fprintf(&sb, "\n%s\n", mattermostEscape(notice.Message))
+	}
+
+	return n.post(ctx, sb.String(), appChannel)
+}
+
+// SendTestMessage posts a plain message to the default channel
+func (n *MattermostNotifier) SendTestMessage(ctx context.Context, text string) error {
+	if err := n.post(ctx, text, ""); err != nil {
+		return fmt.Errorf("failed to send test message: %w", err)
+	}
+	return nil
+}
+
+// buildMattermostMessage renders the combined report: a table of the findings per
+// auditor, a table of the most severe ones, the failed auditors and violated policies
+func buildMattermostMessage(combinedReport *models.CombinedAppReport) string {
+	var sb strings.Builder
+
+	// Only auditor failures or policy violations: there is no vulnerability summary to show
+	if !combinedReport.HasVulnerabilities() {
+		fmt.Fprintf(&sb, "#### :x: %s: %s\n", problemTitle(combinedReport), mattermostEscape(combinedReport.AppName))
+		writeMattermostFailures(&sb, combinedReport.Failures)
+		writeMattermostPolicyViolations(&sb, combinedReport.PolicyViolations)
+		writeMattermostRunID(&sb, combinedReport.RunID)
+		return truncateRunes(sb.String(), mattermostMaxMessage)
+	}
+
+	summary := combinedReport.GetCombinedSummary()
+	emoji := ":warning:"
+	if summary.Critical > 0 || summary.High > 0 {
+		emoji = ":rotating_light:"
+	}
+	fmt.Fprintf(&sb, "#### %s Security Alert: %s\n\n", emoji, mattermostEscape(combinedReport.AppName))
+
+	sb.WriteString("| Auditor | Critical | High | Moderate | Low | Info | Total |\n| :-- | --: | --: | --: | --: | --: | --: |\n")
+	for _, report := range combinedReport.Reports {
+		s := report.GetSummary()
+		fmt.Fprintf(&sb, "| %s | %d | %d | %d | %d | %d | %d |\n",
+			mattermostCell(report.AuditorType), s.Critical, s.High, s.Moderate, s.Low, s.Info, s.Total)
+	}
+	if len(combinedReport.Reports) > 1 {
+		fmt.Fprintf(&sb, "| **Total** | **%d** | **%d** | **%d** | **%d** | **%d** | **%d** |\n",
+			summary.Critical, summary.High, summary.Moderate, summary.Low, summary.Info, summary.Total)
+	}
+
+	// Top vulnerabilities across all auditors (limit to 10)
+	topVulns := collectTopVulnerabilities(combinedReport, 10)
+	if len(topVulns) > 0 {
+		sb.WriteString("\n**Top Issues**\n\n| Severity | Package | Vulnerability | Title | First Seen |\n| :-- | :-- | :-- | :-- | :-- |\n")
+		for _, v := range topVulns {
+			id := v.CVEID
+			if id == "" {
+				id = "-"
+			}
+			fmt.Fprintf(&sb, "| %s | %s | %s | %s | %s |\n",
+				strings.ToUpper(v.Severity), mattermostCell(v.PackageName), mattermostCell(id), mattermostCell(truncateRunes(v.Title, 100)),
+				mattermostCell(v.FirstSeenLabel(time.Now())))
+		}
+		if summary.Total > len(topVulns) {
+			fmt.Fprintf(&sb, "\n_... and %d more_\n", summary.Total-len(topVulns))
+		}
+	}
+
+	// AI Summary if available (from any report)
+	for _, report := range combinedReport.Reports {
+		if report.AIAnalysis != nil && report.AIAnalysis.Summary != "" {
+			fmt.Fprintf(&sb, "\n**AI Summary**\n%s\n", mattermostEscape(report.AIAnalysis.Summary))
+			break // Only include one AI summary
+		}
+	}
+
+	writeMattermostFailures(&sb, combinedReport.Failures)
+	writeMattermostPolicyViolations(&sb, combinedReport.PolicyViolations)
+
+	if commands := combinedFixCommands(combinedReport); len(commands) > 0 {
+		fmt.Fprintf(&sb, "\nRun %s to fix issues\n", strings.Join(commands, " and "))
+	}
+
+	writeMattermostRunID(&sb, combinedReport.RunID)
+
+	return truncateRunes(sb.String(), mattermostMaxMessage)
+}
+
+// writeMattermostFailures writes a table of the auditors that failed with their remediation hints
+func writeMattermostFailures(sb *strings.Builder, failures []models.AuditFailure) {
+	if len(failures) == 0 {
+		return
+	}
+
+	sb.WriteString("\n**Failed Auditors**\n\n| Auditor | Kind | Error | Hint |\n| :-- | :-- | :-- | :-- |\n")
+	for _, f := range failures {
+		fmt.Fprintf(sb, "| %s | %s | %s | %s |\n",
+			mattermostCell(strings.ToUpper(f.AuditorType)),
+			mattermostCell(f.Kind),
+			mattermostCell(truncateRunes(f.Message, maxFailureMessageLength)),
+			mattermostCell(f.Hint),
+		)
+	}
+}
+
+// writeMattermostPolicyViolations writes a table of the violated policies with what violates them
+func writeMattermostPolicyViolations(sb *strings.Builder, violations []models.PolicyViolation) {
+	if len(violations) == 0 {
+		return
+	}
+
+	sb.WriteString("\n**Policies Violated**\n\n| Policy | Action | Violated By |\n| :-- | :-- | :-- |\n")
+	for _, v := range violations {
+		fmt.Fprintf(sb, "| %s | %s | %s |\n",
+			mattermostCell(v.Policy),
+			strings.ToUpper(v.Action),
+			mattermostCell(policyViolationDetail(v, 5)),
+		)
+	}
+}
+
+// writeMattermostRunID writes the run ID, so the message can be matched to the run's logs
+func writeMattermostRunID(sb *strings.Builder, runID string) {
+	if runID != "" {
+		fmt.Fprintf(sb, "\n_Run %s_\n", runID)
+	}
+}
+
+// mattermostEscape escapes Mattermost markdown characters
+func mattermostEscape(s string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"*", "\\*",
+		"_", "\\_",
+		"~", "\\~",
+		"`", "\\`",
+		"|", "\\|",
+		"#", "\\#",
+		"@", "\\@",
+	)
+	return replacer.Replace(s)
+}
+
+// mattermostCell escapes a table cell, which must stay on one line
+func mattermostCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return "-"
+	}
+	return mattermostEscape(s)
+}
+
+// mattermostPost is a post created by the bot
+type mattermostPost struct {
+	ChannelID string `json:"channel_id"`
+	Message   string `json:"message"`
+}
+
+// mattermostWebhookMessage is a message posted through an incoming webhook
+type mattermostWebhookMessage struct {
+	Text     string `json:"text"`
+	Channel  string `json:"channel,omitempty"` // channel name overriding the webhook's channel
+	Username string `json:"username,omitempty"`
+}
+
+// mattermostError is the error response of the Mattermost API
+type mattermostError struct {
+	ID         string `json:"id"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"status_code"`
+}
+
+// post creates a post in channel (the default channel when empty), as the bot or
+// through the webhook
+func (n *MattermostNotifier) post(ctx context.Context, message, channel string) error {
+	if !n.enabled {
+		return fmt.Errorf("mattermost notifier is not enabled")
+	}
+
+	var payload any
+	requestURL := n.webhookURL
+	if n.usesBot() {
+		if channel == "" {
+			channel = n.channelID
+		}
+		payload = mattermostPost{ChannelID: channel, Message: message}
+		requestURL = n.serverURL + "/api/v4/posts"
+	} else {
+		payload = mattermostWebhookMessage{Text: message, Channel: channel, Username: "Audit Checks"}
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, requestURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.usesBot() {
+		req.Header.Set("Authorization", "Bearer "+n.botToken)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var errResp mattermostError
+		if json.Unmarshal(body, &errResp) == nil && errResp.Message != "" {
+			return fmt.Errorf("mattermost API error: status %d: %s", resp.StatusCode, errResp.Message)
+		}
+		return fmt.Errorf("mattermost API error: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
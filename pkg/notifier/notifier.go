@@ -2,11 +2,13 @@ package notifier
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 
+	"github.com/shadowbane/audit-checks/pkg/helpers"
 	"github.com/shadowbane/audit-checks/pkg/models"
-	"go.uber.org/zap"
 )
 
 // Notifier defines the interface for notification senders
@@ -30,7 +32,9 @@ type Manager struct {
 
 // NotificationResult contains the result of sending notifications
 type NotificationResult struct {
-	TelegramTopicID int // The topic ID used/created (0 if not applicable)
+	TelegramTopicID int      // The topic ID used/created (0 if not applicable)
+	DiscordThreadID string   // The thread ID used/created ("" if not applicable)
+	Failed          []string // The channels that failed to send, e.g. "telegram"
 }
 
 // NewManager creates a new notification manager
@@ -85,6 +89,13 @@ func (m *Manager) NotifyAll(ctx context.Context, report *models.Report, config m
 		}
 	}
 
+	// Send webhook notifications
+	if webhook, ok := m.notifiers["webhook"].(*WebhookNotifier); ok && webhook.Enabled() && webhook.HasTargets(config.Webhooks) {
+		if err := m.send(ctx, webhook, report, config.Webhooks); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+
 	if len(errs) > 0 {
 		return result, fmt.Errorf("notification errors: %v", errs)
 	}
@@ -94,8 +105,10 @@ func (m *Manager) NotifyAll(ctx context.Context, report *models.Report, config m
 
 // send sends a notification, respecting dry-run mode
 func (m *Manager) send(ctx context.Context, notifier Notifier, report *models.Report, recipients []string) error {
+	log := helpers.Logger(ctx)
+
 	if m.dryRun {
-		zap.S().Infof("DRY RUN: Would send notification notifier=%s app=%s recipients=%v",
+		log.Infof("DRY RUN: Would send notification notifier=%s app=%s recipients=%v",
 			notifier.Name(),
 			report.AppName,
 			recipients,
@@ -103,14 +116,14 @@ func (m *Manager) send(ctx context.Context, notifier Notifier, report *models.Re
 		return nil
 	}
 
-	zap.S().Infof("Sending notification notifier=%s app=%s recipients=%d",
+	log.Infof("Sending notification notifier=%s app=%s recipients=%d",
 		notifier.Name(),
 		report.AppName,
 		len(recipients),
 	)
 
 	if err := notifier.Send(ctx, report, recipients); err != nil {
-		zap.S().Errorf("Failed to send notification notifier=%s app=%s error=%v",
+		log.Errorf("Failed to send notification notifier=%s app=%s error=%v",
 			notifier.Name(),
 			report.AppName,
 			err,
@@ -118,7 +131,7 @@ func (m *Manager) send(ctx context.Context, notifier Notifier, report *models.Re
 		return err
 	}
 
-	zap.S().Infof("Notification sent successfully notifier=%s app=%s",
+	log.Infof("Notification sent successfully notifier=%s app=%s",
 		notifier.Name(),
 		report.AppName,
 	)
@@ -129,25 +142,27 @@ func (m *Manager) send(ctx context.Context, notifier Notifier, report *models.Re
 // sendTelegram sends a Telegram notification to an app's forum topic.
 // Returns the topic ID used (existing or newly created).
 func (m *Manager) sendTelegram(ctx context.Context, tg *TelegramNotifier, report *models.Report, appName string, existingTopicID int) (int, error) {
+	log := helpers.Logger(ctx)
+
 	if m.dryRun {
-		zap.S().Infof("DRY RUN: Would send Telegram notification to forum topic app=%s",
+		log.Infof("DRY RUN: Would send Telegram notification to forum topic app=%s",
 			appName,
 		)
 		return existingTopicID, nil
 	}
 
-	zap.S().Infof("Sending Telegram notification to forum topic app=%s", appName)
+	log.Infof("Sending Telegram notification to forum topic app=%s", appName)
 
 	topicID, err := tg.SendToTopic(ctx, report, appName, existingTopicID)
 	if err != nil {
-		zap.S().Errorf("Failed to send Telegram notification app=%s error=%v",
+		log.Errorf("Failed to send Telegram notification app=%s error=%v",
 			appName,
 			err,
 		)
 		return topicID, err
 	}
 
-	zap.S().Infof("Telegram notification sent successfully app=%s topic_id=%d", appName, topicID)
+	log.Infof("Telegram notification sent successfully app=%s topic_id=%d", appName, topicID)
 
 	return topicID, nil
 }
@@ -181,37 +196,140 @@ func (m *Manager) EnabledNotifiers() []string {
 
 // NotifyAllCombined sends a combined notification for multiple audit results from a single app.
 // This is used when an app has both npm and composer auditors, sending ONE message with all results.
-// Returns NotificationResult with any created/used IDs that should be persisted.
+// Returns NotificationResult with any created/used IDs that should be persisted, and the
+// channels that failed.
 func (m *Manager) NotifyAllCombined(ctx context.Context, combinedReport *models.CombinedAppReport, config models.NotificationConfig) (*NotificationResult, error) {
+	return m.notifyCombined(ctx, combinedReport, config, nil)
+}
+
+// RetryCombined sends a combined notification again on the channels (e.g. "telegram")
+// that failed to send it
+func (m *Manager) RetryCombined(ctx context.Context, combinedReport *models.CombinedAppReport, config models.NotificationConfig, channels []string) (*NotificationResult, error) {
+	if len(channels) == 0 {
+		return &NotificationResult{}, nil
+	}
+	return m.notifyCombined(ctx, combinedReport, config, channels)
+}
+
+// notifyCombined sends a combined notification on the given channels, or on all of them
+// when channels is empty
+func (m *Manager) notifyCombined(ctx context.Context, combinedReport *models.CombinedAppReport, config models.NotificationConfig, channels []string) (*NotificationResult, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	var errs []error
 	result := &NotificationResult{}
 
+	want := func(channel string) bool {
+		return len(channels) == 0 || slices.Contains(channels, channel)
+	}
+	fail := func(channel string, err error) {
+		errs = append(errs, fmt.Errorf("%s: %w", channel, err))
+		if !slices.Contains(result.Failed, channel) {
+			result.Failed = append(result.Failed, channel)
+		}
+	}
+
 	// Send combined email notifications
-	if len(config.Email) > 0 {
+	if want("email") && len(config.Email) > 0 {
 		if emailNotifier, ok := m.notifiers["email"]; ok && emailNotifier.Enabled() {
 			// For email, send each report individually (email supports attachments natively)
-			for _, report := range combinedReport.Reports {
-				if err := m.send(ctx, emailNotifier, report, config.Email); err != nil {
-					errs = append(errs, fmt.Errorf("email: %w", err))
+			if combinedReport.HasVulnerabilities() {
+				for _, report := range combinedReport.Reports {
+					if err := m.send(ctx, emailNotifier, report, config.Email); err != nil {
+						fail("email", err)
+					}
+				}
+			}
+
+			if email, ok := emailNotifier.(*EmailNotifier); ok && combinedReport.HasFailures() {
+				if err := m.sendFailuresEmail(ctx, email, combinedReport, config.Email); err != nil {
+					fail("email", err)
+				}
+			}
+
+			if email, ok := emailNotifier.(*EmailNotifier); ok && combinedReport.HasPolicyViolations() {
+				if err := m.sendPolicyEmail(ctx, email, combinedReport, config.Email); err != nil {
+					fail("email", err)
 				}
 			}
 		}
 	}
 
 	// Send combined Telegram notification
-	if config.TelegramEnabled {
+	if want("telegram") && config.TelegramEnabled {
 		if tg, ok := m.notifiers["telegram"].(*TelegramNotifier); ok && tg.Enabled() {
 			topicID, err := m.sendCombinedTelegram(ctx, tg, combinedReport, config.AppName, config.TelegramTopicID)
 			if err != nil {
-				errs = append(errs, fmt.Errorf("telegram: %w", err))
+				fail("telegram", err)
 			}
 			result.TelegramTopicID = topicID
 		}
 	}
 
+	// Send combined Discord notification
+	if want("discord") && config.DiscordEnabled {
+		if discord, ok := m.notifiers["discord"].(*DiscordNotifier); ok && discord.Enabled() {
+			threadID, err := m.sendCombinedDiscord(ctx, discord, combinedReport, config)
+			if err != nil {
+				fail("discord", err)
+			}
+			result.DiscordThreadID = threadID
+		}
+	}
+
+	// Post the combined report to the app's Mattermost channel
+	if want("mattermost") && config.MattermostEnabled {
+		if mm, ok := m.notifiers["mattermost"].(*MattermostNotifier); ok && mm.Enabled() {
+			if err := m.sendCombinedMattermost(ctx, mm, combinedReport, config.MattermostChannel); err != nil {
+				fail("mattermost", err)
+			}
+		}
+	}
+
+	// Send the combined report to webhooks
+	if webhook, ok := m.notifiers["webhook"].(*WebhookNotifier); ok && want("webhook") && webhook.Enabled() && webhook.HasTargets(config.Webhooks) {
+		if err := m.sendCombinedWebhook(ctx, webhook, combinedReport, config.Webhooks); err != nil {
+			fail("webhook", err)
+		}
+	}
+
+	// Publish the combined report to the app's ntfy topic
+	if want("ntfy") && config.NtfyEnabled {
+		if ntfy, ok := m.notifiers["ntfy"].(*NtfyNotifier); ok && ntfy.Enabled() {
+			if err := m.sendCombinedNtfy(ctx, ntfy, combinedReport, ntfy.Topic(config.AppName, config.NtfyTopic)); err != nil {
+				fail("ntfy", err)
+			}
+		}
+	}
+
+	// Push the combined report to Gotify
+	if want("gotify") && config.GotifyEnabled {
+		if gotify, ok := m.notifiers["gotify"].(*GotifyNotifier); ok && gotify.Enabled() {
+			if err := m.sendCombinedGotify(ctx, gotify, combinedReport); err != nil {
+				fail("gotify", err)
+			}
+		}
+	}
+
+	// Post the combined report to the app's Zulip topic
+	if want("zulip") && config.ZulipEnabled {
+		if zulip, ok := m.notifiers["zulip"].(*ZulipNotifier); ok && zulip.Enabled() {
+			if err := m.sendCombinedZulip(ctx, zulip, combinedReport, zulip.Topic(config.AppName, config.ZulipTopic)); err != nil {
+				fail("zulip", err)
+			}
+		}
+	}
+
+	// Show the combined report on the desktop of the machine running the audit
+	if want("desktop") {
+		if desktop, ok := m.notifiers["desktop"].(*DesktopNotifier); ok && desktop.Enabled() {
+			if err := m.sendCombinedDesktop(ctx, desktop, combinedReport); err != nil {
+				fail("desktop", err)
+			}
+		}
+	}
+
 	if len(errs) > 0 {
 		return result, fmt.Errorf("notification errors: %v", errs)
 	}
@@ -219,11 +337,189 @@ func (m *Manager) NotifyAllCombined(ctx context.Context, combinedReport *models.
 	return result, nil
 }
 
+// sendCombinedWebhook POSTs the combined report of an app to the webhooks
+func (m *Manager) sendCombinedWebhook(ctx context.Context, webhook *WebhookNotifier, combinedReport *models.CombinedAppReport, appURLs []string) error {
+	log := helpers.Logger(ctx)
+
+	targets := webhook.targets(appURLs)
+	if m.dryRun {
+		log.Infof("DRY RUN: Would send combined report to webhooks app=%s webhooks=%d reports=%d",
+			combinedReport.AppName,
+			len(targets),
+			len(combinedReport.Reports),
+		)
+		return nil
+	}
+
+	if err := webhook.SendCombined(ctx, combinedReport, appURLs); err != nil {
+		log.Errorf("Failed to send combined report to webhooks app=%s error=%v", combinedReport.AppName, err)
+		return err
+	}
+
+	log.Infof("Combined report sent to webhooks app=%s webhooks=%d", combinedReport.AppName, len(targets))
+	return nil
+}
+
+// sendCombinedMattermost posts the combined report of an app to its Mattermost channel
+func (m *Manager) sendCombinedMattermost(ctx context.Context, mm *MattermostNotifier, combinedReport *models.CombinedAppReport, channel string) error {
+	log := helpers.Logger(ctx)
+
+	if m.dryRun {
+		log.Infof("DRY RUN: Would post combined report to Mattermost app=%s channel=%s reports=%d",
+			combinedReport.AppName,
+			channel,
+			len(combinedReport.Reports),
+		)
+		return nil
+	}
+
+	if err := mm.SendCombined(ctx, combinedReport, channel); err != nil {
+		log.Errorf("Failed to post combined report to Mattermost app=%s channel=%s error=%v", combinedReport.AppName, channel, err)
+		return err
+	}
+
+	log.Infof("Combined report posted to Mattermost app=%s channel=%s", combinedReport.AppName, channel)
+	return nil
+}
+
+// sendCombinedNtfy publishes the combined report of an app to its ntfy topic
+func (m *Manager) sendCombinedNtfy(ctx context.Context, ntfy *NtfyNotifier, combinedReport *models.CombinedAppReport, topic string) error {
+	log := helpers.Logger(ctx)
+
+	if m.dryRun {
+		log.Infof("DRY RUN: Would publish combined report to ntfy app=%s topic=%s reports=%d",
+			combinedReport.AppName,
+			topic,
+			len(combinedReport.Reports),
+		)
+		return nil
+	}
+
+	if err := ntfy.SendCombined(ctx, combinedReport, topic); err != nil {
+		log.Errorf("Failed to publish combined report to ntfy app=%s topic=%s error=%v", combinedReport.AppName, topic, err)
+		return err
+	}
+
+	log.Infof("Combined report published to ntfy app=%s topic=%s", combinedReport.AppName, topic)
+	return nil
+}
+
+// sendCombinedGotify pushes the combined report of an app to Gotify
+func (m *Manager) sendCombinedGotify(ctx context.Context, gotify *GotifyNotifier, combinedReport *models.CombinedAppReport) error {
+	log := helpers.Logger(ctx)
+
+	if m.dryRun {
+		log.Infof("DRY RUN: Would push combined report to Gotify app=%s reports=%d",
+			combinedReport.AppName,
+			len(combinedReport.Reports),
+		)
+		return nil
+	}
+
+	if err := gotify.SendCombined(ctx, combinedReport); err != nil {
+		log.Errorf("Failed to push combined report to Gotify app=%s error=%v", combinedReport.AppName, err)
+		return err
+	}
+
+	log.Infof("Combined report pushed to Gotify app=%s", combinedReport.AppName)
+	return nil
+}
+
+// sendCombinedZulip posts the combined report of an app to its Zulip topic
+func (m *Manager) sendCombinedZulip(ctx context.Context, zulip *ZulipNotifier, combinedReport *models.CombinedAppReport, topic string) error {
+	log := helpers.Logger(ctx)
+
+	if m.dryRun {
+		log.Infof("DRY RUN: Would post combined report to Zulip app=%s topic=%s reports=%d files=%d",
+			combinedReport.AppName,
+			topic,
+			len(combinedReport.Reports),
+			len(combinedReport.ReportFiles),
+		)
+		return nil
+	}
+
+	if err := zulip.SendCombined(ctx, combinedReport, topic); err != nil {
+		log.Errorf("Failed to post combined report to Zulip app=%s topic=%s error=%v", combinedReport.AppName, topic, err)
+		return err
+	}
+
+	log.Infof("Combined report posted to Zulip app=%s topic=%s", combinedReport.AppName, topic)
+	return nil
+}
+
+// sendCombinedDesktop shows the combined report of an app on the desktop
+func (m *Manager) sendCombinedDesktop(ctx context.Context, desktop *DesktopNotifier, combinedReport *models.CombinedAppReport) error {
+	log := helpers.Logger(ctx)
+
+	if m.dryRun {
+		log.Infof("DRY RUN: Would show combined report on the desktop app=%s reports=%d",
+			combinedReport.AppName,
+			len(combinedReport.Reports),
+		)
+		return nil
+	}
+
+	if err := desktop.SendCombined(ctx, combinedReport); err != nil {
+		log.Errorf("Failed to show combined report on the desktop app=%s error=%v", combinedReport.AppName, err)
+		return err
+	}
+
+	log.Infof("Combined report shown on the desktop app=%s", combinedReport.AppName)
+	return nil
+}
+
+// sendFailuresEmail emails the auditors that failed for an app
+func (m *Manager) sendFailuresEmail(ctx context.Context, email *EmailNotifier, combinedReport *models.CombinedAppReport, recipients []string) error {
+	log := helpers.Logger(ctx)
+
+	if m.dryRun {
+		log.Infof("DRY RUN: Would send failure email app=%s failures=%d recipients=%v",
+			combinedReport.AppName,
+			len(combinedReport.Failures),
+			recipients,
+		)
+		return nil
+	}
+
+	if err := email.SendFailures(ctx, combinedReport.AppName, combinedReport.RunID, combinedReport.Failures, recipients); err != nil {
+		log.Errorf("Failed to send failure email app=%s error=%v", combinedReport.AppName, err)
+		return err
+	}
+
+	log.Infof("Failure email sent app=%s failures=%d", combinedReport.AppName, len(combinedReport.Failures))
+	return nil
+}
+
+// sendPolicyEmail emails the policies an app violates
+func (m *Manager) sendPolicyEmail(ctx context.Context, email *EmailNotifier, combinedReport *models.CombinedAppReport, recipients []string) error {
+	log := helpers.Logger(ctx)
+
+	if m.dryRun {
+		log.Infof("DRY RUN: Would send policy email app=%s violations=%d recipients=%v",
+			combinedReport.AppName,
+			len(combinedReport.PolicyViolations),
+			recipients,
+		)
+		return nil
+	}
+
+	if err := email.SendPolicyViolations(ctx, combinedReport.AppName, combinedReport.RunID, combinedReport.PolicyViolations, recipients); err != nil {
+		log.Errorf("Failed to send policy email app=%s error=%v", combinedReport.AppName, err)
+		return err
+	}
+
+	log.Infof("Policy email sent app=%s violations=%d", combinedReport.AppName, len(combinedReport.PolicyViolations))
+	return nil
+}
+
 // sendCombinedTelegram sends a combined Telegram notification to an app's forum topic.
 // Returns the topic ID used (existing or newly created).
 func (m *Manager) sendCombinedTelegram(ctx context.Context, tg *TelegramNotifier, combinedReport *models.CombinedAppReport, appName string, existingTopicID int) (int, error) {
+	log := helpers.Logger(ctx)
+
 	if m.dryRun {
-		zap.S().Infof("DRY RUN: Would send combined Telegram notification to forum topic app=%s reports=%d files=%d",
+		log.Infof("DRY RUN: Would send combined Telegram notification to forum topic app=%s reports=%d files=%d",
 			appName,
 			len(combinedReport.Reports),
 			len(combinedReport.ReportFiles),
@@ -231,21 +527,419 @@ func (m *Manager) sendCombinedTelegram(ctx context.Context, tg *TelegramNotifier
 		return existingTopicID, nil
 	}
 
-	zap.S().Infof("Sending combined Telegram notification to forum topic app=%s reports=%d",
+	log.Infof("Sending combined Telegram notification to forum topic app=%s reports=%d",
 		appName,
 		len(combinedReport.Reports),
 	)
 
 	topicID, err := tg.SendCombinedToTopic(ctx, combinedReport, appName, existingTopicID)
 	if err != nil {
-		zap.S().Errorf("Failed to send combined Telegram notification app=%s error=%v",
+		log.Errorf("Failed to send combined Telegram notification app=%s error=%v",
 			appName,
 			err,
 		)
 		return topicID, err
 	}
 
-	zap.S().Infof("Combined Telegram notification sent successfully app=%s topic_id=%d", appName, topicID)
+	log.Infof("Combined Telegram notification sent successfully app=%s topic_id=%d", appName, topicID)
 
 	return topicID, nil
 }
+
+// sendCombinedDiscord sends a combined Discord notification to an app's channel or thread.
+// Returns the thread ID used (existing or newly created).
+func (m *Manager) sendCombinedDiscord(ctx context.Context, discord *DiscordNotifier, combinedReport *models.CombinedAppReport, config models.NotificationConfig) (string, error) {
+	log := helpers.Logger(ctx)
+
+	if m.dryRun {
+		log.Infof("DRY RUN: Would send combined Discord notification app=%s channel=%s reports=%d files=%d",
+			config.AppName,
+			config.DiscordChannelID,
+			len(combinedReport.Reports),
+			len(combinedReport.ReportFiles),
+		)
+		return config.DiscordThreadID, nil
+	}
+
+	log.Infof("Sending combined Discord notification app=%s reports=%d",
+		config.AppName,
+		len(combinedReport.Reports),
+	)
+
+	threadID, err := discord.SendCombinedToThread(ctx, combinedReport, config.AppName, config.DiscordChannelID, config.DiscordThreadID)
+	if err != nil {
+		log.Errorf("Failed to send combined Discord notification app=%s error=%v",
+			config.AppName,
+			err,
+		)
+		return threadID, err
+	}
+
+	log.Infof("Combined Discord notification sent successfully app=%s thread_id=%s", config.AppName, threadID)
+
+	return threadID, nil
+}
+
+// NotifyExposure sends a broadcast exposure notice for an app through every channel the
+// app is configured for. Returns the Telegram topic and Discord thread used so they can
+// be persisted.
+func (m *Manager) NotifyExposure(ctx context.Context, notice *models.ExposureNotice, config models.NotificationConfig) (*NotificationResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	log := helpers.Logger(ctx)
+
+	result := &NotificationResult{
+		TelegramTopicID: config.TelegramTopicID,
+		DiscordThreadID: config.DiscordThreadID,
+	}
+
+	if m.dryRun {
+		log.Infof("DRY RUN: Would send exposure notice app=%s vulnerability=%s packages=%d email=%v telegram=%t discord=%t mattermost=%t webhooks=%d ntfy=%t gotify=%t zulip=%t",
+			config.AppName,
+			notice.VulnerabilityID,
+			len(notice.Packages),
+			config.Email,
+			config.TelegramEnabled,
+			config.DiscordEnabled,
+			config.MattermostEnabled,
+			len(config.Webhooks),
+			config.NtfyEnabled,
+			config.GotifyEnabled,
+			config.ZulipEnabled,
+		)
+		return result, nil
+	}
+
+	var errs []error
+
+	if len(config.Email) > 0 {
+		if email, ok := m.notifiers["email"].(*EmailNotifier); ok && email.Enabled() {
+			if err := email.SendExposure(ctx, notice, config.Email); err != nil {
+				errs = append(errs, fmt.Errorf("email: %w", err))
+			}
+		}
+	}
+
+	if config.TelegramEnabled {
+		if tg, ok := m.notifiers["telegram"].(*TelegramNotifier); ok && tg.Enabled() {
+			topicID, err := tg.SendExposureToTopic(ctx, notice, config.AppName, config.TelegramTopicID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("telegram: %w", err))
+			}
+			result.TelegramTopicID = topicID
+		}
+	}
+
+	if config.DiscordEnabled {
+		if discord, ok := m.notifiers["discord"].(*DiscordNotifier); ok && discord.Enabled() {
+			threadID, err := discord.SendExposureToThread(ctx, notice, config.AppName, config.DiscordChannelID, config.DiscordThreadID)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("discord: %w", err))
+			}
+			result.DiscordThreadID = threadID
+		}
+	}
+
+	if config.MattermostEnabled {
+		if mm, ok := m.notifiers["mattermost"].(*MattermostNotifier); ok && mm.Enabled() {
+			if err := mm.SendExposure(ctx, notice, config.MattermostChannel); err != nil {
+				errs = append(errs, fmt.Errorf("mattermost: %w", err))
+			}
+		}
+	}
+
+	if webhook, ok := m.notifiers["webhook"].(*WebhookNotifier); ok && webhook.Enabled() && webhook.HasTargets(config.Webhooks) {
+		if err := webhook.SendExposure(ctx, notice, config.Webhooks); err != nil {
+			errs = append(errs, fmt.Errorf("webhook: %w", err))
+		}
+	}
+
+	if config.NtfyEnabled {
+		if ntfy, ok := m.notifiers["ntfy"].(*NtfyNotifier); ok && ntfy.Enabled() {
+			if err := ntfy.SendExposure(ctx, notice, ntfy.Topic(config.AppName, config.NtfyTopic)); err != nil {
+				errs = append(errs, fmt.Errorf("ntfy: %w", err))
+			}
+		}
+	}
+
+	if config.GotifyEnabled {
+		if gotify, ok := m.notifiers["gotify"].(*GotifyNotifier); ok && gotify.Enabled() {
+			if err := gotify.SendExposure(ctx, notice); err != nil {
+				errs = append(errs, fmt.Errorf("gotify: %w", err))
+			}
+		}
+	}
+
+	if config.ZulipEnabled {
+		if zulip, ok := m.notifiers["zulip"].(*ZulipNotifier); ok && zulip.Enabled() {
+			if err := zulip.SendExposure(ctx, notice, zulip.Topic(config.AppName, config.ZulipTopic)); err != nil {
+				errs = append(errs, fmt.Errorf("zulip: %w", err))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("notification errors: %v", errs)
+	}
+
+	return result, nil
+}
+
+// NotifyTriage posts the summary of a triage session to the app's Telegram topic, the
+// team's discussion of its findings. Other channels are not sent to.
+func (m *Manager) NotifyTriage(ctx context.Context, summary *models.TriageSummary, config models.NotificationConfig) (*NotificationResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := &NotificationResult{
+		TelegramTopicID: config.TelegramTopicID,
+		DiscordThreadID: config.DiscordThreadID,
+	}
+
+	tg, ok := m.notifiers["telegram"].(*TelegramNotifier)
+	if !ok || !tg.Enabled() || !config.TelegramEnabled {
+		return result, fmt.Errorf("telegram notifications are not enabled for %s", config.AppName)
+	}
+
+	if m.dryRun {
+		helpers.Logger(ctx).Infof("DRY RUN: Would send triage summary app=%s decisions=%d", config.AppName, len(summary.Decisions))
+		return result, nil
+	}
+
+	topicID, err := tg.SendTriageToTopic(ctx, summary, config.AppName, config.TelegramTopicID)
+	result.TelegramTopicID = topicID
+	if err != nil {
+		result.Failed = append(result.Failed, "telegram")
+		return result, fmt.Errorf("telegram: %w", err)
+	}
+	return result, nil
+}
+
+// SyncPagerDuty keeps the app's PagerDuty incident in line with the critical
+// vulnerabilities of its latest results: an incident is triggered when criticals are
+// found, replaced when their set changes and resolved once a run without failures
+// shows none. Returns the deduplication key of the app's open incident ("" if none),
+// which is persisted and passed back as config.PagerDutyDedupKey on the next run.
+func (m *Manager) SyncPagerDuty(ctx context.Context, combinedReport *models.CombinedAppReport, config models.NotificationConfig) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	log := helpers.Logger(ctx)
+	openKey := config.PagerDutyDedupKey
+
+	pd, ok := m.notifiers["pagerduty"].(*PagerDutyNotifier)
+	if !ok || !pd.Enabled() || !config.PagerDutyEnabled {
+		return openKey, nil
+	}
+
+	_, ids := CriticalVulnerabilities(combinedReport)
+
+	if len(ids) == 0 {
+		if openKey == "" {
+			return "", nil
+		}
+		// A failed auditor may hide criticals that are still there
+		if combinedReport.HasFailures() {
+			log.Infof("Keeping PagerDuty incident open app=%s dedup_key=%s: an auditor failed", config.AppName, openKey)
+			return openKey, nil
+		}
+		if m.dryRun {
+			log.Infof("DRY RUN: Would resolve PagerDuty incident app=%s dedup_key=%s", config.AppName, openKey)
+			return openKey, nil
+		}
+		if err := pd.Resolve(ctx, openKey); err != nil {
+			return openKey, err
+		}
+		log.Infof("PagerDuty incident resolved app=%s dedup_key=%s", config.AppName, openKey)
+		return "", nil
+	}
+
+	key := PagerDutyDedupKey(config.AppName, ids)
+	if key == openKey {
+		log.Debugf("PagerDuty incident already open app=%s dedup_key=%s", config.AppName, key)
+		return openKey, nil
+	}
+
+	if m.dryRun {
+		log.Infof("DRY RUN: Would trigger PagerDuty incident app=%s dedup_key=%s criticals=%v", config.AppName, key, ids)
+		return openKey, nil
+	}
+
+	if err := pd.Trigger(ctx, key, combinedReport); err != nil {
+		return openKey, err
+	}
+	log.Infof("PagerDuty incident triggered app=%s dedup_key=%s criticals=%d", config.AppName, key, len(ids))
+
+	// The set of criticals changed: the new incident replaces the old one
+	if openKey != "" {
+		if err := pd.Resolve(ctx, openKey); err != nil {
+			log.Warnf("Failed to resolve replaced PagerDuty incident app=%s dedup_key=%s: %v", config.AppName, openKey, err)
+		}
+	}
+
+	return key, nil
+}
+
+// SyncGitLab keeps the app's GitLab issue in line with its latest results: an issue is
+// created when vulnerabilities are found, updated with the findings of later runs (a new
+// one replacing it if it was closed by hand or deleted) and closed once a run without
+// failures shows none. Returns the reference of the app's open issue ("" if none),
+// which is persisted and passed back as config.GitLabIssue on the next run.
+func (m *Manager) SyncGitLab(ctx context.Context, combinedReport *models.CombinedAppReport, config models.NotificationConfig) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	log := helpers.Logger(ctx)
+	openRef := config.GitLabIssue
+
+	gl, ok := m.notifiers["gitlab"].(*GitLabNotifier)
+	if !ok || !gl.Enabled() || !config.GitLabEnabled {
+		return openRef, nil
+	}
+	project := gl.Project(config.GitLabProject)
+	if project == "" {
+		return openRef, fmt.Errorf("no GitLab project: set GITLAB_PROJECT or the app's --gitlab-project")
+	}
+	openProject, openIID, open := ParseGitLabIssueRef(openRef)
+
+	if !combinedReport.HasVulnerabilities() {
+		if !open {
+			return "", nil
+		}
+		// A failed auditor may hide vulnerabilities that are still there
+		if combinedReport.HasFailures() {
+			log.Infof("Keeping GitLab issue open app=%s issue=%s: an auditor failed", config.AppName, openRef)
+			return openRef, nil
+		}
+		if m.dryRun {
+			log.Infof("DRY RUN: Would close GitLab issue app=%s issue=%s", config.AppName, openRef)
+			return openRef, nil
+		}
+		if err := gl.CloseIssue(ctx, openProject, openIID, gitlabCloseNote(combinedReport.RunID)); err != nil && !errors.Is(err, errGitLabIssueGone) {
+			return openRef, err
+		}
+		log.Infof("GitLab issue closed app=%s issue=%s", config.AppName, openRef)
+		return "", nil
+	}
+
+	// Update the open issue, unless the app moved to another project
+	if open && openProject == project {
+		if m.dryRun {
+			log.Infof("DRY RUN: Would update GitLab issue app=%s issue=%s", config.AppName, openRef)
+			return openRef, nil
+		}
+		err := gl.UpdateIssue(ctx, openProject, openIID, combinedReport)
+		if err == nil {
+			log.Infof("GitLab issue updated app=%s issue=%s", config.AppName, openRef)
+			return openRef, nil
+		}
+		if !errors.Is(err, errGitLabIssueGone) {
+			return openRef, err
+		}
+		log.Infof("GitLab issue was closed or deleted app=%s issue=%s: creating a new one", config.AppName, openRef)
+		open = false
+	}
+
+	if m.dryRun {
+		log.Infof("DRY RUN: Would create GitLab issue app=%s project=%s", config.AppName, project)
+		return openRef, nil
+	}
+
+	iid, err := gl.CreateIssue(ctx, project, combinedReport)
+	if err != nil {
+		return openRef, err
+	}
+	ref := GitLabIssueRef(project, iid)
+	log.Infof("GitLab issue created app=%s issue=%s", config.AppName, ref)
+
+	// The issue in the app's previous project is replaced by the new one
+	if open {
+		if err := gl.CloseIssue(ctx, openProject, openIID, "Moved to "+ref); err != nil && !errors.Is(err, errGitLabIssueGone) {
+			log.Warnf("Failed to close replaced GitLab issue app=%s issue=%s: %v", config.AppName, openRef, err)
+		}
+	}
+
+	return ref, nil
+}
+
+// gitlabCloseNote is the comment closing an app's GitLab issue
+func gitlabCloseNote(runID string) string {
+	if runID == "" {
+		return "No vulnerabilities found by the latest audit."
+	}
+	return fmt.Sprintf("No vulnerabilities found by the latest audit (run %s).", runID)
+}
+
+// SyncOpsgenie creates an Opsgenie alert for each auditor of the app that found
+// vulnerabilities, every run, leaving deduplication to the alert alias. Alerts opened
+// by earlier runs are closed once their auditor runs without them; those of failed
+// auditors stay open. Returns the aliases of the app's open alerts, which are
+// persisted and passed back as config.OpsgenieAliases on the next run.
+func (m *Manager) SyncOpsgenie(ctx context.Context, combinedReport *models.CombinedAppReport, config models.NotificationConfig) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	log := helpers.Logger(ctx)
+
+	og, ok := m.notifiers["opsgenie"].(*OpsgenieNotifier)
+	if !ok || !og.Enabled() || !config.OpsgenieEnabled {
+		return config.OpsgenieAliases, nil
+	}
+
+	var errs []error
+	var open []string
+
+	for _, report := range combinedReport.Reports {
+		severity := HighestSeverity(report)
+		if severity == "" {
+			continue
+		}
+		alias := OpsgenieAlias(config.AppName, report.AuditorType, severity)
+
+		if m.dryRun {
+			log.Infof("DRY RUN: Would create Opsgenie alert app=%s auditor=%s alias=%s priority=%s",
+				config.AppName, report.AuditorType, alias, og.Priority(severity))
+			continue
+		}
+
+		if err := og.CreateAlert(ctx, report, combinedReport.RunID); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", report.AuditorType, err))
+			continue
+		}
+		log.Infof("Opsgenie alert created app=%s auditor=%s alias=%s", config.AppName, report.AuditorType, alias)
+		open = append(open, alias)
+	}
+
+	if m.dryRun {
+		return config.OpsgenieAliases, nil
+	}
+
+	failed := make(map[string]bool, len(combinedReport.Failures))
+	for _, failure := range combinedReport.Failures {
+		failed[failure.AuditorType] = true
+	}
+
+	for _, alias := range config.OpsgenieAliases {
+		if slices.Contains(open, alias) {
+			continue
+		}
+		// A failed auditor may hide vulnerabilities that are still there
+		if auditorType := opsgenieAliasAuditor(alias, config.AppName); failed[auditorType] {
+			log.Infof("Keeping Opsgenie alert open app=%s alias=%s: the auditor failed", config.AppName, alias)
+			open = append(open, alias)
+			continue
+		}
+		if err := og.CloseAlert(ctx, alias, "Not found by the latest audit"); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", alias, err))
+			open = append(open, alias)
+			continue
+		}
+		log.Infof("Opsgenie alert closed app=%s alias=%s", config.AppName, alias)
+	}
+
+	if len(errs) > 0 {
+		return open, fmt.Errorf("opsgenie errors: %v", errs)
+	}
+	return open, nil
+}
@@ -0,0 +1,342 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// NtfyServerURL is the public ntfy server; self-hosted servers work the same way
+const NtfyServerURL = "https://ntfy.sh"
+
+// DefaultNtfyPriorities maps vulnerability severities to ntfy message priorities
+// (1 min, 2 low, 3 default, 4 high, 5 urgent)
+var DefaultNtfyPriorities = map[string]int{
+	models.SeverityCritical: 5,
+	models.SeverityHigh:     4,
+	models.SeverityModerate: 3,
+	models.SeverityLow:      2,
+	models.SeverityInfo:     1,
+}
+
+// ntfyMaxActions is the number of action buttons ntfy shows on a notification
+const ntfyMaxActions = 3
+
+// ntfyTopicUnsafe matches the runs of characters not allowed in ntfy topic names
+var ntfyTopicUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// NtfyConfig is the ntfy server and topics NtfyNotifier publishes to
+type NtfyConfig struct {
+	ServerURL   string         // NtfyServerURL when empty
+	Token       string         // access token of protected topics
+	TopicPrefix string         // apps without their own topic publish to <prefix>-<app>
+	Priorities  map[string]int // severity -> priority; severities left out use DefaultNtfyPriorities
+	ReportURL   string         // where the report output directory is served; no links when empty
+	ReportDir   string         // the report output directory
+}
+
+// NtfyNotifier publishes a push notification per app to its ntfy topic, with the
+// priority of the highest severity found and buttons opening the reports, for
+// on-call phones
+type NtfyNotifier struct {
+	config  NtfyConfig
+	enabled bool
+	client  *http.Client
+}
+
+// NewNtfyNotifier creates a new NtfyNotifier
+func NewNtfyNotifier(config NtfyConfig, enabled bool) *NtfyNotifier {
+	if config.ServerURL == "" {
+		config.ServerURL = NtfyServerURL
+	}
+	config.ServerURL = strings.TrimSuffix(config.ServerURL, "/")
+	config.ReportURL = strings.TrimSuffix(config.ReportURL, "/")
+
+	priorities := make(map[string]int, len(DefaultNtfyPriorities))
+	for severity, priority := range DefaultNtfyPriorities {
+		priorities[severity] = priority
+	}
+	for severity, priority := range config.Priorities {
+		priorities[severity] = priority
+	}
+	config.Priorities = priorities
+
+	return &NtfyNotifier{
+		config:  config,
+		enabled: enabled,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns "ntfy"
+func (n *NtfyNotifier) Name() string {
+	return "ntfy"
+}
+
+// Enabled returns true if the notifier is enabled
+func (n *NtfyNotifier) Enabled() bool {
+	return n.enabled
+}
+
+// Topic returns the topic of an app: its own, or the prefix followed by the app name
+func (n *NtfyNotifier) Topic(appName, appTopic string) string {
+	if appTopic != "" {
+		return appTopic
+	}
+
+	topic := strings.Trim(ntfyTopicUnsafe.ReplaceAllString(appName, "-"), "-")
+	if n.config.TopicPrefix != "" {
+		topic = n.config.TopicPrefix + "-" + topic
+	}
+	if len(topic) > 64 {
+		topic = topic[:64]
+	}
+	return topic
+}
+
+// Priority returns the message priority of a severity
+func (n *NtfyNotifier) Priority(severity string) int {
+	if priority, ok := n.config.Priorities[severity]; ok {
+		return priority
+	}
+	return 3
+}
+
+// ntfyMessage is a message published as JSON to the server's root URL
+type ntfyMessage struct {
+	Topic    string       `json:"topic"`
+	Title    string       `json:"title"`
+	Message  string       `json:"message"`
+	Priority int          `json:"priority"`
+	Tags     []string     `json:"tags,omitempty"`
+	Click    string       `json:"click,omitempty"`
+	Actions  []ntfyAction `json:"actions,omitempty"`
+}
+
+// ntfyAction is a button of a notification
+type ntfyAction struct {
+	Action string `json:"action"` // view opens the URL
+	Label  string `json:"label"`
+	URL    string `json:"url"`
+}
+
+// ntfyErrorResponse is the error response of a ntfy server
+type ntfyErrorResponse struct {
+	Code  int    `json:"code"`
+	Error string `json:"error"`
+}
+
+// Send publishes a report to the topics in recipients
+func (n *NtfyNotifier) Send(ctx context.Context, report *models.Report, recipients []string) error {
+	combinedReport := &models.CombinedAppReport{
+		AppName:     report.AppName,
+		AppPath:     report.AppPath,
+		Reports:     []*models.Report{report},
+		GeneratedAt: report.GeneratedAt,
+	}
+	for _, topic := range recipients {
+		if err := n.SendCombined(ctx, combinedReport, topic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SendCombined publishes the combined report of an app to topic: the findings per
+// auditor, the most severe ones and the failed auditors
+func (n *NtfyNotifier) SendCombined(ctx context.Context, combinedReport *models.CombinedAppReport, topic string) error {
+	summary := combinedReport.GetCombinedSummary()
+
+	var vulns []models.Vulnerability
+	var body strings.Builder
+	for _, report := range combinedReport.Reports {
+		if len(report.Vulnerabilities) == 0 {
+			continue
+		}
+		s := report.GetSummary()
+		fmt.Fprintf(&body, "%s: %d critical, %d high, %d moderate, %d low, %d info\n", report.AuditorType, s.Critical, s.High, s.Moderate, s.Low, s.Info)
+		vulns = append(vulns, report.Vulnerabilities...)
+	}
+	for _, failure := range combinedReport.Failures {
+		fmt.Fprintf(&body, "%s failed (%s)\n", failure.AuditorType, failure.Kind)
+	}
+	for _, v := range combinedReport.PolicyViolations {
+		fmt.Fprintf(&body, "Policy %s violated (%s)\n", v.Policy, v.Action)
+	}
+
+	// Most severe findings first, or the oldest or riskiest with the report's issue order
+	models.SortVulnerabilities(vulns, combinedReport.IssueOrder, time.Now())
+	if len(vulns) > 0 {
+		body.WriteString("\n")
+	}
+	for i, v := range vulns {
+		if i == 5 {
+			fmt.Fprintf(&body, "...and %d more\n", len(vulns)-i)
+			break
+		}
+		id := v.CVEID
+		if id == "" {
+			id = v.Title
+		}
+		fmt.Fprintf(&body, "[%s] %s: %s", strings.ToUpper(v.Severity), v.PackageName, id)
+		if ago := v.FirstSeenAgo(time.Now()); ago != "" {
+			fmt.Fprintf(&body, " (%s)", ago)
+		}
+		body.WriteString("\n")
+	}
+
+	message := ntfyMessage{
+		Topic:    topic,
+		Message:  truncateRunes(strings.TrimSpace(body.String()), 4000),
+		Priority: n.Priority(models.SeverityModerate),
+		Tags:     []string{"warning", "audit-checks", combinedReport.AppName},
+	}
+
+	if len(vulns) > 0 {
+		highest := highestSeverity(vulns)
+		message.Title = fmt.Sprintf("%s: %d vulnerabilit%s (highest: %s)", combinedReport.AppName, summary.Total, pluralY(summary.Total), highest)
+		message.Priority = n.Priority(highest)
+		if highest == models.SeverityCritical || highest == models.SeverityHigh {
+			message.Tags[0] = "rotating_light"
+		}
+	} else {
+		message.Title = fmt.Sprintf("%s: %s", combinedReport.AppName, problemSummary(combinedReport))
+	}
+
+	message.Actions = n.reportActions(combinedReport)
+	if len(message.Actions) > 0 {
+		message.Click = message.Actions[0].URL
+	}
+
+	return n.publish(ctx, message)
+}
+
+// SendExposure publishes a broadcast exposure notice to topic, at high priority
+func (n *NtfyNotifier) SendExposure(ctx context.Context, notice *models.ExposureNotice, topic string) error {
+	var body strings.Builder
+	if notice.Summary != "" {
+		fmt.Fprintf(&body, "%s\n\n", notice.Summary)
+	}
+	for _, pkg := range notice.Packages {
+		fmt.Fprintf(&body, "%s@%s (%s)\n", pkg.Name, pkg.Version, pkg.Lockfile)
+	}
+	if notice.Message != "" {
+		fmt.Fprintf(&body, "\n%s\n", notice.Message)
+	}
+
+	return n.publish(ctx, ntfyMessage{
+		Topic:    topic,
+		Title:    fmt.Sprintf("%s: %s is exposed", notice.VulnerabilityID, notice.AppName),
+		Message:  truncateRunes(strings.TrimSpace(body.String()), 4000),
+		Priority: n.Priority(models.SeverityHigh),
+		Tags:     []string{"rotating_light", "audit-checks", notice.AppName},
+	})
+}
+
+// SendTestMessage publishes a plain message, such as a notifier drill, to topic at the
+// default priority
+func (n *NtfyNotifier) SendTestMessage(ctx context.Context, topic, title, text string) error {
+	return n.publish(ctx, ntfyMessage{
+		Topic:    topic,
+		Title:    title,
+		Message:  truncateRunes(text, 4000),
+		Priority: 3,
+		Tags:     []string{"test_tube", "audit-checks"},
+	})
+}
+
+// reportActions returns buttons opening each auditor's report under the report URL,
+// the Markdown report when there is one
+func (n *NtfyNotifier) reportActions(combinedReport *models.CombinedAppReport) []ntfyAction {
+	if n.config.ReportURL == "" {
+		return nil
+	}
+
+	var actions []ntfyAction
+	for _, report := range combinedReport.Reports {
+		prefix := combinedReport.AppName + "-" + report.AuditorType + "-"
+
+		var file string
+		for _, path := range combinedReport.ReportFiles {
+			if !strings.HasPrefix(filepath.Base(path), prefix) {
+				continue
+			}
+			if file == "" || filepath.Ext(path) == ".md" {
+				file = path
+			}
+		}
+
+		if link := n.reportLink(file); link != "" && len(actions) < ntfyMaxActions {
+			actions = append(actions, ntfyAction{Action: "view", Label: report.AuditorType + " report", URL: link})
+		}
+	}
+	return actions
+}
+
+// reportLink returns the URL of a report file under the report URL
+func (n *NtfyNotifier) reportLink(file string) string {
+	if file == "" {
+		return ""
+	}
+
+	rel, err := filepath.Rel(n.config.ReportDir, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return n.config.ReportURL + "/" + strings.Join(segments, "/")
+}
+
+// publish sends a message to the server
+func (n *NtfyNotifier) publish(ctx context.Context, message ntfyMessage) error {
+	if !n.enabled {
+		return fmt.Errorf("ntfy notifier is not enabled")
+	}
+
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.ServerURL+"/", bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.config.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.config.Token)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var errResp ntfyErrorResponse
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error != "" {
+			return fmt.Errorf("ntfy API error: status %d: %s", resp.StatusCode, errResp.Error)
+		}
+		return fmt.Errorf("ntfy API error: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// OpsgenieAPIURL is the Opsgenie API of the US instance; EU accounts use https://api.eu.opsgenie.com
+const OpsgenieAPIURL = "https://api.opsgenie.com"
+
+// DefaultOpsgeniePriorities maps vulnerability severities to Opsgenie alert priorities
+var DefaultOpsgeniePriorities = map[string]string{
+	models.SeverityCritical: "P1",
+	models.SeverityHigh:     "P2",
+	models.SeverityModerate: "P3",
+	models.SeverityLow:      "P4",
+	models.SeverityInfo:     "P5",
+}
+
+// OpsgenieNotifier creates an Opsgenie alert per app and auditor with vulnerabilities,
+// through the Alert API. Alerts are identified by an alias built from the app, the
+// auditor and the highest severity: Opsgenie deduplicates the alerts of later runs
+// into the open one, and a higher severity opens a new alert at a higher priority.
+type OpsgenieNotifier struct {
+	apiKey     string
+	apiURL     string
+	priorities map[string]string
+	enabled    bool
+	client     *http.Client
+}
+
+// NewOpsgenieNotifier creates a new OpsgenieNotifier. priorities maps severities to
+// priorities (P1 to P5); severities it leaves out use DefaultOpsgeniePriorities.
+func NewOpsgenieNotifier(apiKey, apiURL string, priorities map[string]string, enabled bool) *OpsgenieNotifier {
+	if apiURL == "" {
+		apiURL = OpsgenieAPIURL
+	}
+
+	merged := make(map[string]string, len(DefaultOpsgeniePriorities))
+	for severity, priority := range DefaultOpsgeniePriorities {
+		merged[severity] = priority
+	}
+	for severity, priority := range priorities {
+		merged[severity] = priority
+	}
+
+	return &OpsgenieNotifier{
+		apiKey:     apiKey,
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		priorities: merged,
+		enabled:    enabled && apiKey != "",
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns "opsgenie"
+func (n *OpsgenieNotifier) Name() string {
+	return "opsgenie"
+}
+
+// Enabled returns true if the notifier is configured
+func (n *OpsgenieNotifier) Enabled() bool {
+	return n.enabled
+}
+
+// Send creates (or, through its alias, deduplicates) the alert of a report. Reports
+// without vulnerabilities are skipped.
+func (n *OpsgenieNotifier) Send(ctx context.Context, report *models.Report, recipients []string) error {
+	if len(report.Vulnerabilities) == 0 {
+		return nil
+	}
+	return n.CreateAlert(ctx, report, "")
+}
+
+// opsgenieAlert is an Alert API create request
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+	Entity      string            `json:"entity,omitempty"`
+	Source      string            `json:"source"`
+	Priority    string            `json:"priority"`
+}
+
+// opsgenieResponse is the response to an Alert API request
+type opsgenieResponse struct {
+	Result    string `json:"result"`
+	Message   string `json:"message"`
+	RequestID string `json:"requestId"`
+}
+
+// HighestSeverity returns the highest severity of a report's vulnerabilities, or an
+// empty string when there are none
+func HighestSeverity(report *models.Report) string {
+	highest := ""
+	for _, v := range report.Vulnerabilities {
+		if highest == "" || models.SeverityOrder[v.Severity] > models.SeverityOrder[highest] {
+			highest = v.Severity
+		}
+	}
+	return highest
+}
+
+// OpsgenieAlias returns the alias of the alert of an app's auditor at a severity
+func OpsgenieAlias(appName, auditorType, severity string) string {
+	return fmt.Sprintf("audit-checks:%s:%s:%s", appName, auditorType, severity)
+}
+
+// Priority returns the alert priority of a severity
+func (n *OpsgenieNotifier) Priority(severity string) string {
+	if priority, ok := n.priorities[severity]; ok {
+		return priority
+	}
+	return "P3"
+}
+
+// CreateAlert creates the alert of a report with vulnerabilities, tagged with the app,
+// the auditor and the highest severity. Opsgenie only increases the count of an open
+// alert with the same alias.
+func (n *OpsgenieNotifier) CreateAlert(ctx context.Context, report *models.Report, runID string) error {
+	severity := HighestSeverity(report)
+	summary := report.GetSummary()
+
+	message := fmt.Sprintf("%s: %d vulnerabilit%s (highest: %s) found by %s",
+		report.AppName, summary.Total, pluralY(summary.Total), severity, report.AuditorType)
+
+	var description strings.Builder
+	for _, v := range report.Vulnerabilities {
+		id := v.CVEID
+		if id == "" {
+			id = "-"
+		}
+		fmt.Fprintf(&description, "[%s] %s %s: %s\n", strings.ToUpper(v.Severity), id, v.PackageName, v.Title)
+	}
+
+	details := map[string]string{
+		"app":      report.AppName,
+		"app_path": report.AppPath,
+		"auditor":  report.AuditorType,
+		"critical": fmt.Sprint(summary.Critical),
+		"high":     fmt.Sprint(summary.High),
+		"moderate": fmt.Sprint(summary.Moderate),
+		"low":      fmt.Sprint(summary.Low),
+		"info":     fmt.Sprint(summary.Info),
+	}
+	if runID != "" {
+		details["run_id"] = runID
+	}
+
+	alert := opsgenieAlert{
+		Message:     truncateRunes(message, 130),
+		Alias:       OpsgenieAlias(report.AppName, report.AuditorType, severity),
+		Description: truncateRunes(description.String(), 15000),
+		Tags:        []string{"audit-checks", "app:" + report.AppName, "auditor:" + report.AuditorType, "severity:" + severity},
+		Details:     details,
+		Entity:      report.AppName,
+		Source:      "audit-checks",
+		Priority:    n.Priority(severity),
+	}
+
+	return n.post(ctx, "/v2/alerts", alert)
+}
+
+// CreateNoticeAlert creates an alert not tied to a report, such as a notifier drill
+func (n *OpsgenieNotifier) CreateNoticeAlert(ctx context.Context, alias, message, description, priority string) error {
+	return n.post(ctx, "/v2/alerts", opsgenieAlert{
+		Message:     truncateRunes(message, 130),
+		Alias:       alias,
+		Description: truncateRunes(description, 15000),
+		Tags:        []string{"audit-checks"},
+		Source:      "audit-checks",
+		Priority:    priority,
+	})
+}
+
+// CloseAlert closes the alert with alias
+func (n *OpsgenieNotifier) CloseAlert(ctx context.Context, alias, note string) error {
+	path := "/v2/alerts/" + url.PathEscape(alias) + "/close?identifierType=alias"
+	return n.post(ctx, path, map[string]string{"source": "audit-checks", "note": note})
+}
+
+// post sends an Alert API request. Requests are processed asynchronously and accepted with 202.
+func (n *OpsgenieNotifier) post(ctx context.Context, path string, body any) error {
+	if !n.enabled {
+		return fmt.Errorf("opsgenie notifier is not enabled")
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.apiURL+path, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "GenieKey "+n.apiKey)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var errResp opsgenieResponse
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Message != "" {
+			return fmt.Errorf("opsgenie API error: status %d: %s", resp.StatusCode, errResp.Message)
+		}
+		return fmt.Errorf("opsgenie API error: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// opsgenieAliasAuditor returns the auditor of an alias built by OpsgenieAlias
+func opsgenieAliasAuditor(alias, appName string) string {
+	rest := strings.TrimPrefix(alias, "audit-checks:"+appName+":")
+	if i := strings.LastIndex(rest, ":"); i >= 0 {
+		return rest[:i]
+	}
+	return rest
+}
@@ -0,0 +1,238 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDuty event actions
+const (
+	pagerDutyTrigger = "trigger"
+	pagerDutyResolve = "resolve"
+)
+
+// PagerDutyNotifier opens a PagerDuty incident per app while critical vulnerabilities
+// are found, through the Events API v2. The incident's deduplication key is derived
+// from the app and its set of critical vulnerabilities, so a changed set opens a new
+// incident and the old one is resolved.
+type PagerDutyNotifier struct {
+	routingKey string
+	endpoint   string
+	enabled    bool
+	client     *http.Client
+}
+
+// NewPagerDutyNotifier creates a new PagerDutyNotifier sending events with the
+// integration's routing key
+func NewPagerDutyNotifier(routingKey string, enabled bool) *PagerDutyNotifier {
+	return NewPagerDutyNotifierWithEndpoint(routingKey, enabled, PagerDutyEventsURL)
+}
+
+// NewPagerDutyNotifierWithEndpoint creates a new PagerDutyNotifier sending events to an
+// endpoint other than PagerDutyEventsURL, e.g. a mock server in integration tests
+func NewPagerDutyNotifierWithEndpoint(routingKey string, enabled bool, endpoint string) *PagerDutyNotifier {
+	return &PagerDutyNotifier{
+		routingKey: routingKey,
+		endpoint:   endpoint,
+		enabled:    enabled && routingKey != "",
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns "pagerduty"
+func (n *PagerDutyNotifier) Name() string {
+	return "pagerduty"
+}
+
+// Enabled returns true if the notifier is configured
+func (n *PagerDutyNotifier) Enabled() bool {
+	return n.enabled
+}
+
+// Send implements Notifier interface but is not used: incidents follow the app's
+// combined results. Use Manager.SyncPagerDuty instead.
+func (n *PagerDutyNotifier) Send(ctx context.Context, report *models.Report, recipients []string) error {
+	return fmt.Errorf("pagerduty notifier follows combined results; use SyncPagerDuty instead")
+}
+
+// pagerDutyEvent is an Events API v2 event
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Client      string            `json:"client,omitempty"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"` // trigger only
+}
+
+type pagerDutyPayload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Component     string         `json:"component,omitempty"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+// pagerDutyResponse is the response to an event
+type pagerDutyResponse struct {
+	Status   string   `json:"status"`
+	Message  string   `json:"message"`
+	DedupKey string   `json:"dedup_key"`
+	Errors   []string `json:"errors"`
+}
+
+// CriticalVulnerabilities returns the critical vulnerabilities of a combined report and
+// their sorted, distinct IDs (CVE, or package and title when there is none)
+func CriticalVulnerabilities(combinedReport *models.CombinedAppReport) ([]models.Vulnerability, []string) {
+	var vulns []models.Vulnerability
+	var ids []string
+	for _, report := range combinedReport.Reports {
+		for _, v := range report.Vulnerabilities {
+			if v.Severity != models.SeverityCritical {
+				continue
+			}
+			vulns = append(vulns, v)
+			id := v.CVEID
+			if id == "" {
+				id = v.PackageName + ": " + v.Title
+			}
+			if !slices.Contains(ids, id) {
+				ids = append(ids, id)
+			}
+		}
+	}
+	slices.Sort(ids)
+	return vulns, ids
+}
+
+// PagerDutyDedupKey returns the deduplication key of the incident for an app's set of
+// critical vulnerability IDs (sorted)
+func PagerDutyDedupKey(appName string, ids []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(ids, "\n")))
+	return fmt.Sprintf("audit-checks/%s/%s", appName, hex.EncodeToString(sum[:8]))
+}
+
+// Trigger opens (or updates) the incident of an app's critical vulnerabilities
+func (n *PagerDutyNotifier) Trigger(ctx context.Context, dedupKey string, combinedReport *models.CombinedAppReport) error {
+	vulns, ids := CriticalVulnerabilities(combinedReport)
+
+	details := make([]string, 0, len(vulns))
+	for _, v := range vulns {
+		id := v.CVEID
+		if id == "" {
+			id = "-"
+		}
+		details = append(details, fmt.Sprintf("%s %s: %s", id, v.PackageName, v.Title))
+	}
+
+	customDetails := map[string]any{
+		"app_path":        combinedReport.AppPath,
+		"vulnerabilities": details,
+	}
+	if combinedReport.RunID != "" {
+		customDetails["run_id"] = combinedReport.RunID
+	}
+
+	summary := fmt.Sprintf("%d critical vulnerabilit%s in %s: %s",
+		len(ids), pluralY(len(ids)), combinedReport.AppName, strings.Join(ids, ", "))
+
+	return n.send(ctx, pagerDutyEvent{
+		RoutingKey:  n.routingKey,
+		EventAction: pagerDutyTrigger,
+		DedupKey:    dedupKey,
+		Client:      "audit-checks",
+		Payload: &pagerDutyPayload{
+			Summary:       truncateRunes(summary, 1024),
+			Source:        combinedReport.AppName,
+			Severity:      "critical",
+			Component:     combinedReport.AppName,
+			Class:         "vulnerability",
+			CustomDetails: customDetails,
+		},
+	})
+}
+
+// TriggerNotice opens (or updates) an incident not tied to a report, such as a notifier
+// drill, at severity (critical, error, warning or info)
+func (n *PagerDutyNotifier) TriggerNotice(ctx context.Context, dedupKey, summary, severity string) error {
+	return n.send(ctx, pagerDutyEvent{
+		RoutingKey:  n.routingKey,
+		EventAction: pagerDutyTrigger,
+		DedupKey:    dedupKey,
+		Client:      "audit-checks",
+		Payload: &pagerDutyPayload{
+			Summary:  truncateRunes(summary, 1024),
+			Source:   "audit-checks",
+			Severity: severity,
+			Class:    "notifier-drill",
+		},
+	})
+}
+
+// Resolve resolves the incident with dedupKey
+func (n *PagerDutyNotifier) Resolve(ctx context.Context, dedupKey string) error {
+	return n.send(ctx, pagerDutyEvent{
+		RoutingKey:  n.routingKey,
+		EventAction: pagerDutyResolve,
+		DedupKey:    dedupKey,
+	})
+}
+
+// send posts an event. The Events API accepts events with 202.
+func (n *PagerDutyNotifier) send(ctx context.Context, event pagerDutyEvent) error {
+	if !n.enabled {
+		return fmt.Errorf("pagerduty notifier is not enabled")
+	}
+
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		var errResp pagerDutyResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Message != "" {
+			return fmt.Errorf("pagerduty API error: status %d: %s %v", resp.StatusCode, errResp.Message, errResp.Errors)
+		}
+		return fmt.Errorf("pagerduty API error: status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// pluralY returns the "y"/"ies" ending for n items
+func pluralY(n int) string {
+	if n == 1 {
+		return "y"
+	}
+	return "ies"
+}
@@ -0,0 +1,217 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+)
+
+// SMTP connection security modes
+const (
+	SMTPTLSStartTLS = "starttls" // upgrade the connection with STARTTLS (usually port 587)
+	SMTPTLSImplicit = "tls"      // TLS from the start (usually port 465)
+	SMTPTLSNone     = "none"     // unencrypted, e.g. a relay on localhost
+)
+
+// SMTPConfig is an SMTP server emails are sent through instead of Resend
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // no authentication when empty
+	Password string
+	TLS      string // starttls (default), tls or none
+}
+
+// NewSMTPEmailNotifier creates an EmailNotifier sending through an SMTP server, with
+// the same emails as through Resend
+func NewSMTPEmailNotifier(smtpConfig SMTPConfig, fromEmail string) *EmailNotifier {
+	if smtpConfig.TLS == "" {
+		smtpConfig.TLS = SMTPTLSStartTLS
+	}
+
+	return &EmailNotifier{
+		fromEmail: fromEmail,
+		enabled:   smtpConfig.Host != "" && smtpConfig.Port > 0 && fromEmail != "",
+		smtp:      &smtpConfig,
+	}
+}
+
+// sendSMTP sends an email as a single message to all its recipients
+func (n *EmailNotifier) sendSMTP(ctx context.Context, message emailMessage) error {
+	from, err := mail.ParseAddress(message.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address %q: %w", message.From, err)
+	}
+	to := make([]*mail.Address, 0, len(message.To))
+	for _, recipient := range message.To {
+		address, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid recipient %q: %w", recipient, err)
+		}
+		to = append(to, address)
+	}
+
+	content, err := buildSMTPMessage(from, to, message, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to build message: %w", err)
+	}
+
+	client, err := n.dialSMTP(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM failed: %w", err)
+	}
+	for _, address := range to {
+		if err := client.Rcpt(address.Address); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s failed: %w", address.Address, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := w.Write(content); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected the message: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// dialSMTP connects to the SMTP server, secures the connection and authenticates
+func (n *EmailNotifier) dialSMTP(ctx context.Context) (*smtp.Client, error) {
+	cfg := n.smtp
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	tlsConfig := &tls.Config{ServerName: cfg.Host}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	var conn net.Conn
+	var err error
+	if cfg.TLS == SMTPTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	// net/smtp doesn't take a context: bound the whole exchange instead
+	deadline := time.Now().Add(30 * time.Second)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+
+	if cfg.TLS == SMTPTLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, fmt.Errorf("SMTP server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
+	}
+
+	// PlainAuth refuses to send credentials over an unencrypted connection, except to localhost
+	if cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+// buildSMTPMessage renders an email as a MIME message with a quoted-printable HTML body,
+// in a multipart/alternative message after its plain-text alternative if any
+func buildSMTPMessage(from *mail.Address, to []*mail.Address, message emailMessage, now time.Time) ([]byte, error) {
+	recipients := make([]string, len(to))
+	for i, address := range to {
+		recipients[i] = address.String()
+	}
+
+	domain := "localhost"
+	if i := strings.LastIndex(from.Address, "@"); i >= 0 {
+		domain = from.Address[i+1:]
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&buf, "Message-ID: <%s@%s>\r\n", helpers.MustNewULID(), domain)
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	if message.Text == "" {
+		buf.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, message.HTML); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	// Clients show the last alternative they can render, so the HTML part comes last
+	parts := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", message.Text},
+		{"text/html; charset=UTF-8", message.HTML},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeQuotedPrintable writes content quoted-printable encoded
+func writeQuotedPrintable(w io.Writer, content string) error {
+	body := quotedprintable.NewWriter(w)
+	if _, err := body.Write([]byte(content)); err != nil {
+		return err
+	}
+	return body.Close()
+}
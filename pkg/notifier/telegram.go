@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	tgbotapi "github.com/matterbridge/telegram-bot-api/v6"
+	"github.com/shadowbane/audit-checks/pkg/helpers"
 	"github.com/shadowbane/audit-checks/pkg/models"
 	"go.uber.org/zap"
 )
@@ -24,6 +26,13 @@ type TelegramNotifier struct {
 
 // NewTelegramNotifier creates a new TelegramNotifier
 func NewTelegramNotifier(botToken string, groupID int64, enabled bool) (*TelegramNotifier, error) {
+	return NewTelegramNotifierWithEndpoint(botToken, groupID, enabled, tgbotapi.APIEndpoint)
+}
+
+// NewTelegramNotifierWithEndpoint creates a new TelegramNotifier using a Bot API server
+// other than api.telegram.org, e.g. a local Bot API server or a mock server in integration tests.
+// apiEndpoint is formatted with the token and method, like tgbotapi.APIEndpoint.
+func NewTelegramNotifierWithEndpoint(botToken string, groupID int64, enabled bool, apiEndpoint string) (*TelegramNotifier, error) {
 	notifier := &TelegramNotifier{
 		botToken:   botToken,
 		groupID:    groupID,
@@ -32,7 +41,7 @@ func NewTelegramNotifier(botToken string, groupID int64, enabled bool) (*Telegra
 	}
 
 	if notifier.enabled {
-		bot, err := tgbotapi.NewBotAPI(botToken)
+		bot, err := tgbotapi.NewBotAPIWithAPIEndpoint(botToken, apiEndpoint)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create Telegram bot: %w", err)
 		}
@@ -63,6 +72,8 @@ func (n *TelegramNotifier) Send(ctx context.Context, report *models.Report, reci
 // If existingTopicID is 0, a new topic will be created.
 // Returns the topic ID used (existing or newly created) so it can be persisted.
 func (n *TelegramNotifier) SendToTopic(ctx context.Context, report *models.Report, appName string, existingTopicID int) (int, error) {
+	log := helpers.Logger(ctx)
+
 	if !n.enabled || n.bot == nil {
 		return 0, fmt.Errorf("telegram notifier is not enabled")
 	}
@@ -85,7 +96,7 @@ func (n *TelegramNotifier) SendToTopic(ctx context.Context, report *models.Repor
 
 	sentMsg, err := n.bot.Send(msg)
 	if err != nil {
-		zap.S().Errorf("Failed to send Telegram message with Markdown to topic topic_id=%d app=%s error=%v",
+		log.Errorf("Failed to send Telegram message with Markdown to topic topic_id=%d app=%s error=%v",
 			topicID,
 			appName,
 			err,
@@ -102,7 +113,7 @@ func (n *TelegramNotifier) SendToTopic(ctx context.Context, report *models.Repor
 	// Check if message went to the correct topic (not General)
 	// If topic was deleted, Telegram sends to General (thread_id=0) instead of the specified topic
 	if existingTopicID > 0 && sentMsg.MessageThreadID != topicID {
-		zap.S().Warnf("Topic %d appears to be deleted (message went to thread %d), creating new topic for app=%s",
+		log.Warnf("Topic %d appears to be deleted (message went to thread %d), creating new topic for app=%s",
 			topicID,
 			sentMsg.MessageThreadID,
 			appName,
@@ -113,7 +124,7 @@ func (n *TelegramNotifier) SendToTopic(ctx context.Context, report *models.Repor
 
 		newTopicID, err := n.createForumTopic(appName)
 		if err != nil {
-			zap.S().Errorf("Failed to create replacement topic for app=%s: %v", appName, err)
+			log.Errorf("Failed to create replacement topic for app=%s: %v", appName, err)
 			return 0, nil
 		}
 
@@ -132,11 +143,11 @@ func (n *TelegramNotifier) SendToTopic(ctx context.Context, report *models.Repor
 			n.bot.Send(msg)
 		}
 
-		zap.S().Infof("Created replacement topic for app=%s new_topic_id=%d", appName, newTopicID)
+		log.Infof("Created replacement topic for app=%s new_topic_id=%d", appName, newTopicID)
 		topicID = newTopicID
 	}
 
-	zap.S().Infof("Telegram notification sent to topic topic_id=%d app=%s", topicID, appName)
+	log.Infof("Telegram notification sent to topic topic_id=%d app=%s", topicID, appName)
 	return topicID, nil
 }
 
@@ -261,6 +272,9 @@ func (n *TelegramNotifier) buildMessage(report *models.Report) string {
 	if report.AuditResult.LowCount > 0 {
 		sb.WriteString(fmt.Sprintf("  - Low: %d\n", report.AuditResult.LowCount))
 	}
+	if report.AuditResult.InfoCount > 0 {
+		sb.WriteString(fmt.Sprintf("  - Info: %d\n", report.AuditResult.InfoCount))
+	}
 	sb.WriteString(fmt.Sprintf("  - *Total: %d*\n\n", report.AuditResult.TotalVulnerabilities))
 
 	// Top vulnerabilities (limit to 5)
@@ -275,7 +289,7 @@ func (n *TelegramNotifier) buildMessage(report *models.Report) string {
 			sb.WriteString(fmt.Sprintf("%d. %s (%s)\n",
 				i+1,
 				escapeMarkdown(v.PackageName),
-				strings.ToUpper(v.Severity),
+				severityAndAge(v),
 			))
 		}
 		if len(report.Vulnerabilities) > 5 {
@@ -296,6 +310,28 @@ func (n *TelegramNotifier) buildMessage(report *models.Report) string {
 		sb.WriteString("_Run `npm audit fix` to automatically fix issues_\n")
 	} else if report.AuditorType == "composer" {
 		sb.WriteString("_Run `composer update` to update packages_\n")
+	} else if report.AuditorType == "go" {
+		sb.WriteString("_Run `go get -u ./... && go mod tidy` to update modules_\n")
+	} else if report.AuditorType == "pnpm" {
+		sb.WriteString("_Run `pnpm audit --fix` to add overrides for vulnerable packages_\n")
+	} else if report.AuditorType == "cargo" {
+		sb.WriteString("_Run `cargo update` to update crates_\n")
+	} else if report.AuditorType == "java" {
+		sb.WriteString("_Update the affected dependencies in `pom.xml` or `build.gradle`_\n")
+	} else if report.AuditorType == "dotnet" {
+		sb.WriteString("_Run `dotnet list package --outdated` to find patched versions_\n")
+	} else if report.AuditorType == "pub" {
+		sb.WriteString("_Run `dart pub upgrade` (or `flutter pub upgrade`) to update packages_\n")
+	} else if report.AuditorType == "helm" {
+		sb.WriteString("_Bump the affected image tags in the chart values or rebuild the images_\n")
+	} else if report.AuditorType == "terraform" {
+		sb.WriteString("_Raise the provider versions in `required_providers` and run `terraform init -upgrade`_\n")
+	} else if report.AuditorType == "system" {
+		sb.WriteString("_Install pending security updates with `apt-get upgrade` or `dnf upgrade --security`_\n")
+	} else if report.AuditorType == "osv" {
+		sb.WriteString("_Update the affected packages and commit the refreshed lockfile_\n")
+	} else if report.AuditorType == "policy" {
+		sb.WriteString("_Replace wildcard, tag and branch constraints with release ranges_\n")
 	}
 
 	return sb.String()
@@ -313,6 +349,7 @@ func (n *TelegramNotifier) buildPlainMessage(report *models.Report) string {
 	sb.WriteString(fmt.Sprintf("  - High: %d\n", report.AuditResult.HighCount))
 	sb.WriteString(fmt.Sprintf("  - Moderate: %d\n", report.AuditResult.ModerateCount))
 	sb.WriteString(fmt.Sprintf("  - Low: %d\n", report.AuditResult.LowCount))
+	sb.WriteString(fmt.Sprintf("  - Info: %d\n", report.AuditResult.InfoCount))
 	sb.WriteString(fmt.Sprintf("  - Total: %d\n\n", report.AuditResult.TotalVulnerabilities))
 
 	if len(report.Vulnerabilities) > 0 {
@@ -326,7 +363,7 @@ func (n *TelegramNotifier) buildPlainMessage(report *models.Report) string {
 			sb.WriteString(fmt.Sprintf("%d. %s (%s)\n",
 				i+1,
 				v.PackageName,
-				strings.ToUpper(v.Severity),
+				severityAndAge(v),
 			))
 		}
 	}
@@ -377,6 +414,38 @@ func escapeMarkdown(s string) string {
 // If existingTopicID is 0, a new topic will be created.
 // Returns the topic ID used (existing or newly created) so it can be persisted.
 func (n *TelegramNotifier) SendCombinedToTopic(ctx context.Context, combinedReport *models.CombinedAppReport, appName string, existingTopicID int) (int, error) {
+	log := helpers.Logger(ctx)
+
+	if !n.enabled || n.bot == nil {
+		return 0, fmt.Errorf("telegram notifier is not enabled")
+	}
+
+	if appName == "" {
+		return 0, fmt.Errorf("app name is required for forum topic")
+	}
+
+	// Build combined message
+	message := n.buildCombinedMessage(combinedReport)
+	plainMessage := n.buildCombinedPlainMessage(combinedReport)
+
+	topicID, err := n.sendToAppTopic(ctx, appName, existingTopicID, message, plainMessage, combinedReport.ReportFiles)
+	if err != nil {
+		return topicID, err
+	}
+
+	log.Infof("Combined Telegram notification sent to topic topic_id=%d app=%s auditors=%d files=%d",
+		topicID,
+		appName,
+		len(combinedReport.Reports),
+		len(combinedReport.ReportFiles),
+	)
+
+	return topicID, nil
+}
+
+// SendExposureToTopic sends a broadcast exposure notice to the app's forum topic.
+// Returns the topic ID used so it can be persisted.
+func (n *TelegramNotifier) SendExposureToTopic(ctx context.Context, notice *models.ExposureNotice, appName string, existingTopicID int) (int, error) {
 	if !n.enabled || n.bot == nil {
 		return 0, fmt.Errorf("telegram notifier is not enabled")
 	}
@@ -385,26 +454,56 @@ func (n *TelegramNotifier) SendCombinedToTopic(ctx context.Context, combinedRepo
 		return 0, fmt.Errorf("app name is required for forum topic")
 	}
 
+	topicID, err := n.sendToAppTopic(ctx, appName, existingTopicID, telegramExposureMessage(notice, true), telegramExposureMessage(notice, false), nil)
+	if err != nil {
+		return topicID, err
+	}
+
+	helpers.Logger(ctx).Infof("Telegram exposure notice sent to topic topic_id=%d app=%s vulnerability=%s", topicID, appName, notice.VulnerabilityID)
+	return topicID, nil
+}
+
+// SendTriageToTopic sends the summary of a triage session to the app's forum topic.
+// Returns the topic ID used so it can be persisted.
+func (n *TelegramNotifier) SendTriageToTopic(ctx context.Context, summary *models.TriageSummary, appName string, existingTopicID int) (int, error) {
+	if !n.enabled || n.bot == nil {
+		return 0, fmt.Errorf("telegram notifier is not enabled")
+	}
+
+	if appName == "" {
+		return 0, fmt.Errorf("app name is required for forum topic")
+	}
+
+	topicID, err := n.sendToAppTopic(ctx, appName, existingTopicID, telegramTriageMessage(summary, true), telegramTriageMessage(summary, false), nil)
+	if err != nil {
+		return topicID, err
+	}
+
+	helpers.Logger(ctx).Infof("Telegram triage summary sent to topic topic_id=%d app=%s decisions=%d", topicID, appName, len(summary.Decisions))
+	return topicID, nil
+}
+
+// sendToAppTopic sends a message to the app's forum topic, creating the topic when
+// existingTopicID is 0 and replacing it when it was deleted. Returns the topic ID used.
+func (n *TelegramNotifier) sendToAppTopic(ctx context.Context, appName string, existingTopicID int, message, plainMessage string, filePaths []string) (int, error) {
+	log := helpers.Logger(ctx)
+
 	// Get or create the forum topic for this app
 	topicID, err := n.getOrCreateTopic(appName, existingTopicID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get/create topic for app %s: %w", appName, err)
 	}
 
-	// Build combined message
-	message := n.buildCombinedMessage(combinedReport)
-	plainMessage := n.buildCombinedPlainMessage(combinedReport)
-
 	// Send message with attachments
-	sentThreadID, err := n.sendMessageWithAttachments(topicID, message, plainMessage, combinedReport.ReportFiles)
+	sentThreadID, err := n.sendMessageWithAttachments(topicID, message, plainMessage, filePaths)
 	if err != nil {
-		return topicID, fmt.Errorf("failed to send combined message to topic %d: %w", topicID, err)
+		return topicID, fmt.Errorf("failed to send message to topic %d: %w", topicID, err)
 	}
 
 	// Check if message went to the correct topic (not General)
 	// If topic was deleted, Telegram sends to General (thread_id=0) instead of the specified topic
 	if existingTopicID > 0 && sentThreadID != topicID {
-		zap.S().Warnf("Topic %d appears to be deleted (message went to thread %d), creating new topic for app=%s",
+		log.Warnf("Topic %d appears to be deleted (message went to thread %d), creating new topic for app=%s",
 			topicID,
 			sentThreadID,
 			appName,
@@ -415,7 +514,7 @@ func (n *TelegramNotifier) SendCombinedToTopic(ctx context.Context, combinedRepo
 
 		newTopicID, err := n.createForumTopic(appName)
 		if err != nil {
-			zap.S().Errorf("Failed to create replacement topic for app=%s: %v", appName, err)
+			log.Errorf("Failed to create replacement topic for app=%s: %v", appName, err)
 			// Return 0 to force database update (clear the invalid topic ID)
 			return 0, nil
 		}
@@ -426,22 +525,15 @@ func (n *TelegramNotifier) SendCombinedToTopic(ctx context.Context, combinedRepo
 		n.cacheMu.Unlock()
 
 		// Resend to the new topic
-		_, err = n.sendMessageWithAttachments(newTopicID, message, plainMessage, combinedReport.ReportFiles)
+		_, err = n.sendMessageWithAttachments(newTopicID, message, plainMessage, filePaths)
 		if err != nil {
-			zap.S().Warnf("Failed to resend to new topic: %v", err)
+			log.Warnf("Failed to resend to new topic: %v", err)
 		}
 
-		zap.S().Infof("Created replacement topic for app=%s new_topic_id=%d", appName, newTopicID)
+		log.Infof("Created replacement topic for app=%s new_topic_id=%d", appName, newTopicID)
 		topicID = newTopicID
 	}
 
-	zap.S().Infof("Combined Telegram notification sent to topic topic_id=%d app=%s auditors=%d files=%d",
-		topicID,
-		appName,
-		len(combinedReport.Reports),
-		len(combinedReport.ReportFiles),
-	)
-
 	return topicID, nil
 }
 
@@ -524,12 +616,22 @@ func (n *TelegramNotifier) invalidateTopicCache(appName string) {
 func (n *TelegramNotifier) buildCombinedMessage(combinedReport *models.CombinedAppReport) string {
 	var sb strings.Builder
 
+	// Only auditor failures or policy violations: there is no vulnerability summary to show
+	if !combinedReport.HasVulnerabilities() {
+		sb.WriteString(fmt.Sprintf("\xE2\x9D\x8C *%s: %s*\n\n", problemTitle(combinedReport), combinedReport.AppName))
+		writeRunID(&sb, combinedReport.RunID, true)
+		writeFailures(&sb, combinedReport.Failures, true)
+		writePolicyViolations(&sb, combinedReport.PolicyViolations, true)
+		return sb.String()
+	}
+
 	// Calculate combined summary
 	summary := combinedReport.GetCombinedSummary()
 
 	// Header with emoji based on severity
 	emoji := n.getCombinedSeverityEmoji(summary)
 	sb.WriteString(fmt.Sprintf("%s *Security Alert: %s*\n\n", emoji, combinedReport.AppName))
+	writeRunID(&sb, combinedReport.RunID, true)
 
 	// Combined Summary
 	sb.WriteString("*Combined Vulnerabilities:*\n")
@@ -545,6 +647,9 @@ func (n *TelegramNotifier) buildCombinedMessage(combinedReport *models.CombinedA
 	if summary.Low > 0 {
 		sb.WriteString(fmt.Sprintf("  - Low: %d\n", summary.Low))
 	}
+	if summary.Info > 0 {
+		sb.WriteString(fmt.Sprintf("  - Info: %d\n", summary.Info))
+	}
 	sb.WriteString(fmt.Sprintf("  - *Total: %d*\n\n", summary.Total))
 
 	// Per-auditor breakdown
@@ -560,14 +665,14 @@ func (n *TelegramNotifier) buildCombinedMessage(combinedReport *models.CombinedA
 	sb.WriteString("\n")
 
 	// Top vulnerabilities across all auditors (limit to 5)
-	allVulns := n.collectTopVulnerabilities(combinedReport, 5)
+	allVulns := collectTopVulnerabilities(combinedReport, 5)
 	if len(allVulns) > 0 {
 		sb.WriteString("*Top Issues:*\n")
 		for i, v := range allVulns {
 			sb.WriteString(fmt.Sprintf("%d. %s (%s)\n",
 				i+1,
 				escapeMarkdown(v.PackageName),
-				strings.ToUpper(v.Severity),
+				severityAndAge(v),
 			))
 		}
 
@@ -592,16 +697,11 @@ func (n *TelegramNotifier) buildCombinedMessage(combinedReport *models.CombinedA
 		}
 	}
 
+	writeFailures(&sb, combinedReport.Failures, true)
+	writePolicyViolations(&sb, combinedReport.PolicyViolations, true)
+
 	// Quick fix suggestions
-	var fixCommands []string
-	for _, report := range combinedReport.Reports {
-		if report.AuditorType == "npm" {
-			fixCommands = append(fixCommands, "`npm audit fix`")
-		} else if report.AuditorType == "composer" {
-			fixCommands = append(fixCommands, "`composer update`")
-		}
-	}
-	if len(fixCommands) > 0 {
+	if fixCommands := combinedFixCommands(combinedReport); len(fixCommands) > 0 {
 		sb.WriteString(fmt.Sprintf("_Run %s to fix issues_", strings.Join(fixCommands, " and ")))
 	}
 
@@ -612,16 +712,26 @@ func (n *TelegramNotifier) buildCombinedMessage(combinedReport *models.CombinedA
 func (n *TelegramNotifier) buildCombinedPlainMessage(combinedReport *models.CombinedAppReport) string {
 	var sb strings.Builder
 
+	if !combinedReport.HasVulnerabilities() {
+		sb.WriteString(fmt.Sprintf("\xE2\x9D\x8C %s: %s\n\n", problemTitle(combinedReport), combinedReport.AppName))
+		writeRunID(&sb, combinedReport.RunID, false)
+		writeFailures(&sb, combinedReport.Failures, false)
+		writePolicyViolations(&sb, combinedReport.PolicyViolations, false)
+		return sb.String()
+	}
+
 	summary := combinedReport.GetCombinedSummary()
 	emoji := n.getCombinedSeverityEmoji(summary)
 
 	sb.WriteString(fmt.Sprintf("%s Security Alert: %s\n\n", emoji, combinedReport.AppName))
+	writeRunID(&sb, combinedReport.RunID, false)
 
 	sb.WriteString("Combined Vulnerabilities:\n")
 	sb.WriteString(fmt.Sprintf("  - Critical: %d\n", summary.Critical))
 	sb.WriteString(fmt.Sprintf("  - High: %d\n", summary.High))
 	sb.WriteString(fmt.Sprintf("  - Moderate: %d\n", summary.Moderate))
 	sb.WriteString(fmt.Sprintf("  - Low: %d\n", summary.Low))
+	sb.WriteString(fmt.Sprintf("  - Info: %d\n", summary.Info))
 	sb.WriteString(fmt.Sprintf("  - Total: %d\n\n", summary.Total))
 
 	sb.WriteString("Breakdown by Package Manager:\n")
@@ -634,37 +744,216 @@ func (n *TelegramNotifier) buildCombinedPlainMessage(combinedReport *models.Comb
 		}
 	}
 
-	allVulns := n.collectTopVulnerabilities(combinedReport, 5)
+	allVulns := collectTopVulnerabilities(combinedReport, 5)
 	if len(allVulns) > 0 {
 		sb.WriteString("\nTop Issues:\n")
 		for i, v := range allVulns {
 			sb.WriteString(fmt.Sprintf("%d. %s (%s)\n",
 				i+1,
 				v.PackageName,
-				strings.ToUpper(v.Severity),
+				severityAndAge(v),
 			))
 		}
 	}
 
+	if len(combinedReport.Failures) > 0 {
+		sb.WriteString("\n")
+		writeFailures(&sb, combinedReport.Failures, false)
+	}
+	if combinedReport.HasPolicyViolations() {
+		if len(combinedReport.Failures) == 0 {
+			sb.WriteString("\n")
+		}
+		writePolicyViolations(&sb, combinedReport.PolicyViolations, false)
+	}
+
 	return sb.String()
 }
 
-// collectTopVulnerabilities collects top N vulnerabilities sorted by severity
-func (n *TelegramNotifier) collectTopVulnerabilities(combinedReport *models.CombinedAppReport, limit int) []models.Vulnerability {
-	var allVulns []models.Vulnerability
+// telegramExposureMessage creates the exposure notice, with Markdown or as plain text
+func telegramExposureMessage(notice *models.ExposureNotice, markdown bool) string {
+	esc := func(s string) string { return s }
+	if markdown {
+		esc = escapeMarkdown
+	}
 
-	for _, report := range combinedReport.Reports {
-		allVulns = append(allVulns, report.Vulnerabilities...)
+	var sb strings.Builder
+	if markdown {
+		sb.WriteString(fmt.Sprintf("🚨 *%s: %s is exposed*\n\n", esc(notice.VulnerabilityID), esc(notice.AppName)))
+	} else {
+		sb.WriteString(fmt.Sprintf("🚨 %s: %s is exposed\n\n", notice.VulnerabilityID, notice.AppName))
+	}
+	if notice.Summary != "" {
+		sb.WriteString(esc(notice.Summary) + "\n\n")
+	}
+
+	sb.WriteString("Affected packages:\n")
+	for _, pkg := range notice.Packages {
+		sb.WriteString(esc(fmt.Sprintf("• %s@%s (%s)", pkg.Name, pkg.Version, pkg.Lockfile)) + "\n")
+	}
+
+	if notice.Message != "" {
+		sb.WriteString("\n" + esc(notice.Message) + "\n")
 	}
 
-	// Sort by severity (critical first)
-	for i := 0; i < len(allVulns)-1; i++ {
-		for j := i + 1; j < len(allVulns); j++ {
-			if models.SeverityOrder[allVulns[j].Severity] > models.SeverityOrder[allVulns[i].Severity] {
-				allVulns[i], allVulns[j] = allVulns[j], allVulns[i]
+	return sb.String()
+}
+
+// telegramTriageMessage creates the summary of a triage session, with Markdown or as plain text
+func telegramTriageMessage(summary *models.TriageSummary, markdown bool) string {
+	esc := func(s string) string { return s }
+	if markdown {
+		esc = escapeMarkdown
+	}
+
+	var sb strings.Builder
+	title := "Triage of " + summary.AppName
+	if summary.Operator != "" {
+		title += " by " + summary.Operator
+	}
+	if markdown {
+		sb.WriteString(fmt.Sprintf("🗂 *%s*\n\n", esc(title)))
+	} else {
+		sb.WriteString(fmt.Sprintf("🗂 %s\n\n", title))
+	}
+
+	for _, group := range []struct {
+		emoji, label string
+		match        func(models.FindingTriage) bool
+	}{
+		{"✅", "Acknowledged", func(t models.FindingTriage) bool { return t.Status == models.TriageAcknowledged }},
+		{"🔇", "Ignored", func(t models.FindingTriage) bool { return t.Status == models.TriageIgnored }},
+		{"💤", "Snoozed", func(t models.FindingTriage) bool { return t.Status == models.TriageSnoozed }},
+		{"👤", "Assigned", func(t models.FindingTriage) bool { return t.Status == "" && t.Assignee != "" }},
+	} {
+		var lines []string
+		for _, t := range summary.Decisions {
+			if !group.match(t) {
+				continue
+			}
+			line := fmt.Sprintf("• %s %s", t.PackageName, t.FindingID)
+			if t.Status == models.TriageSnoozed && t.SnoozedUntil != nil {
+				line += " until " + t.SnoozedUntil.Local().Format("2006-01-02")
 			}
+			if t.Assignee != "" {
+				line += " → " + t.Assignee
+			}
+			lines = append(lines, esc(line))
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s %s (%d):\n%s\n\n", group.emoji, group.label, len(lines), strings.Join(lines, "\n")))
+	}
+
+	if summary.Remaining > 0 {
+		sb.WriteString(fmt.Sprintf("%d findings left to triage\n", summary.Remaining))
+	} else {
+		sb.WriteString("Every open finding is triaged\n")
+	}
+
+	return sb.String()
+}
+
+// writeRunID writes the run ID, used to find the run's log lines and events
+func writeRunID(sb *strings.Builder, runID string, markdown bool) {
+	if runID == "" {
+		return
+	}
+	if markdown {
+		sb.WriteString(fmt.Sprintf("Run: `%s`\n\n", runID))
+	} else {
+		sb.WriteString(fmt.Sprintf("Run: %s\n\n", runID))
+	}
+}
+
+// maxFailureMessageLength keeps tool output from filling a Telegram message
+const maxFailureMessageLength = 200
+
+// writeFailures lists the auditors that failed with their remediation hints
+func writeFailures(sb *strings.Builder, failures []models.AuditFailure, markdown bool) {
+	if len(failures) == 0 {
+		return
+	}
+
+	if markdown {
+		sb.WriteString("*Failed Auditors:*\n")
+	} else {
+		sb.WriteString("Failed Auditors:\n")
+	}
+	for _, f := range failures {
+		message := f.Message
+		if runes := []rune(message); len(runes) > maxFailureMessageLength {
+			message = string(runes[:maxFailureMessageLength]) + "..."
+		}
+		if markdown {
+			sb.WriteString(fmt.Sprintf("  - %s (%s): %s\n    _Hint: %s_\n",
+				strings.ToUpper(f.AuditorType), escapeMarkdown(f.Kind), escapeMarkdown(message), escapeMarkdown(f.Hint)))
+		} else {
+			sb.WriteString(fmt.Sprintf("  - %s (%s): %s\n    Hint: %s\n",
+				strings.ToUpper(f.AuditorType), f.Kind, message, f.Hint))
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// writePolicyViolations lists the violated policies with what violates them
+func writePolicyViolations(sb *strings.Builder, violations []models.PolicyViolation, markdown bool) {
+	if len(violations) == 0 {
+		return
+	}
+
+	if markdown {
+		sb.WriteString("*Policies Violated:*\n")
+	} else {
+		sb.WriteString("Policies Violated:\n")
+	}
+	for _, v := range violations {
+		if markdown {
+			sb.WriteString(fmt.Sprintf("  - %s (%s): %s\n",
+				escapeMarkdown(v.Policy), strings.ToUpper(v.Action), escapeMarkdown(policyViolationDetail(v, 3))))
+		} else {
+			sb.WriteString(fmt.Sprintf("  - %s (%s): %s\n",
+				v.Policy, strings.ToUpper(v.Action), policyViolationDetail(v, 3)))
+		}
+	}
+	sb.WriteString("\n")
+}
+
+// combinedFixCommands returns the fix command of each auditor in a combined report,
+// formatted as Markdown code
+func combinedFixCommands(combinedReport *models.CombinedAppReport) []string {
+	var fixCommands []string
+	for _, report := range combinedReport.Reports {
+		if report.AuditorType == "npm" {
+			fixCommands = append(fixCommands, "`npm audit fix`")
+		} else if report.AuditorType == "composer" {
+			fixCommands = append(fixCommands, "`composer update`")
+		} else if report.AuditorType == "go" {
+			fixCommands = append(fixCommands, "`go get -u ./... && go mod tidy`")
+		} else if report.AuditorType == "pnpm" {
+			fixCommands = append(fixCommands, "`pnpm audit --fix`")
+		} else if report.AuditorType == "cargo" {
+			fixCommands = append(fixCommands, "`cargo update`")
+		} else if report.AuditorType == "pub" {
+			fixCommands = append(fixCommands, "`dart pub upgrade`")
+		} else if report.AuditorType == "terraform" {
+			fixCommands = append(fixCommands, "`terraform init -upgrade`")
 		}
 	}
+	return fixCommands
+}
+
+// collectTopVulnerabilities collects top N vulnerabilities in the report's issue order
+// (critical first by default)
+func collectTopVulnerabilities(combinedReport *models.CombinedAppReport, limit int) []models.Vulnerability {
+	var allVulns []models.Vulnerability
+
+	for _, report := range combinedReport.Reports {
+		allVulns = append(allVulns, report.Vulnerabilities...)
+	}
+
+	models.SortVulnerabilities(allVulns, combinedReport.IssueOrder, time.Now())
 
 	if len(allVulns) > limit {
 		return allVulns[:limit]
@@ -672,6 +961,66 @@ func (n *TelegramNotifier) collectTopVulnerabilities(combinedReport *models.Comb
 	return allVulns
 }
 
+// highestSeverity returns the highest severity among vulns
+func highestSeverity(vulns []models.Vulnerability) string {
+	highest := models.SeverityInfo
+	for _, v := range vulns {
+		if models.SeverityOrder[v.Severity] > models.SeverityOrder[highest] {
+			highest = v.Severity
+		}
+	}
+	return highest
+}
+
+// severityAndAge returns the severity of a top issue with when it was first seen,
+// e.g. "HIGH, first seen 12 days ago", so fresh and long-open findings tell apart
+func severityAndAge(v models.Vulnerability) string {
+	if ago := v.FirstSeenAgo(time.Now()); ago != "" {
+		return strings.ToUpper(v.Severity) + ", " + ago
+	}
+	return strings.ToUpper(v.Severity)
+}
+
+// problemTitle is the heading of a combined report without vulnerabilities to show:
+// auditors failed, or the app only violates policies
+func problemTitle(combinedReport *models.CombinedAppReport) string {
+	if combinedReport.HasFailures() {
+		return "Audit Failed"
+	}
+	return "Policy Violated"
+}
+
+// problemSummary counts the failed auditors and violated policies of a combined report,
+// e.g. "1 auditor(s) failed, 2 policies violated"; empty if there are none
+func problemSummary(combinedReport *models.CombinedAppReport) string {
+	var parts []string
+	if n := len(combinedReport.Failures); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d auditor(s) failed", n))
+	}
+	if n := len(combinedReport.PolicyViolations); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d polic%s violated", n, pluralY(n)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// policyViolationDetail describes what violates a policy: its first limit subjects, or
+// its description for app policies, or why it could not be evaluated
+func policyViolationDetail(v models.PolicyViolation, limit int) string {
+	if v.Error != "" {
+		return "could not be evaluated: " + v.Error
+	}
+	if len(v.Subjects) == 0 {
+		if v.Description != "" {
+			return v.Description
+		}
+		return "violated by the app"
+	}
+	if len(v.Subjects) <= limit {
+		return strings.Join(v.Subjects, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(v.Subjects[:limit], ", "), len(v.Subjects)-limit)
+}
+
 // getCombinedSeverityEmoji returns an emoji based on the combined severity
 func (n *TelegramNotifier) getCombinedSeverityEmoji(summary models.Summary) string {
 	if summary.Critical > 0 {
@@ -685,3 +1034,184 @@ func (n *TelegramNotifier) getCombinedSeverityEmoji(summary models.Summary) stri
 	}
 	return "\xF0\x9F\x9F\xA2" // Green circle
 }
+
+// TelegramCheck describes the bot and group as seen by the Telegram API
+type TelegramCheck struct {
+	BotUsername      string
+	ChatTitle        string
+	ChatType         string
+	IsForum          bool
+	MemberStatus     string // creator, administrator, member, restricted, left or kicked
+	CanManageTopics  bool
+	CanSendMessages  bool
+	CanSendDocuments bool
+}
+
+// TelegramProblem is something that keeps the bot from delivering notifications to
+// the group, with how to fix it
+type TelegramProblem struct {
+	Message string
+	Hint    string
+}
+
+// Problems returns what keeps the bot from creating a topic per app and posting
+// the reports in it, which Telegram would otherwise only report at the first send
+func (c *TelegramCheck) Problems() []TelegramProblem {
+	if c.MemberStatus == "left" || c.MemberStatus == "kicked" {
+		return []TelegramProblem{{
+			Message: "the bot is not a member of the group",
+			Hint:    "Add the bot to the group and promote it to admin with the 'Manage Topics' right",
+		}}
+	}
+
+	var problems []TelegramProblem
+	if !c.IsForum {
+		problems = append(problems, TelegramProblem{
+			Message: "topics are not enabled in the group",
+			Hint:    "Enable Topics in the group settings; audit-checks creates one topic per app",
+		})
+	}
+	switch {
+	case c.MemberStatus == "creator" || (c.MemberStatus == "administrator" && c.CanManageTopics):
+	case c.MemberStatus == "administrator":
+		problems = append(problems, TelegramProblem{
+			Message: "the bot is an admin without the 'Manage Topics' right",
+			Hint:    "Edit the bot's admin rights in the group and allow 'Manage Topics'",
+		})
+	default:
+		problems = append(problems, TelegramProblem{
+			Message: "the bot is not an admin of the group, so it cannot create topics",
+			Hint:    "Promote the bot to admin with the 'Manage Topics' right",
+		})
+	}
+	if !c.CanSendMessages {
+		problems = append(problems, TelegramProblem{
+			Message: "the bot cannot send messages",
+			Hint:    "Allow members to send messages, lift the bot's restrictions or promote it to admin",
+		})
+	} else if !c.CanSendDocuments {
+		problems = append(problems, TelegramProblem{
+			Message: "the bot cannot send files, so the reports cannot be attached",
+			Hint:    "Allow members to send files, lift the bot's restrictions or promote it to admin",
+		})
+	}
+	return problems
+}
+
+// Ready returns true if the bot can create forum topics in the group and post the reports in them
+func (c *TelegramCheck) Ready() bool {
+	return len(c.Problems()) == 0
+}
+
+// telegramPermissions holds the send rights of a restricted member, or of the
+// members of a chat by default
+type telegramPermissions struct {
+	CanSendMessages      bool  `json:"can_send_messages"`
+	CanSendDocuments     *bool `json:"can_send_documents"`      // Bot API 6.5 and later
+	CanSendMediaMessages bool  `json:"can_send_media_messages"` // before Bot API 6.5
+}
+
+// sendDocuments returns whether documents may be sent, from the right of the Bot API in use
+func (p telegramPermissions) sendDocuments() bool {
+	if p.CanSendDocuments != nil {
+		return *p.CanSendDocuments
+	}
+	return p.CanSendMediaMessages
+}
+
+// telegramChat holds the fields of getChat used by the check, some not exposed by tgbotapi.Chat
+type telegramChat struct {
+	Title       string               `json:"title"`
+	Type        string               `json:"type"`
+	IsForum     bool                 `json:"is_forum"`
+	Permissions *telegramPermissions `json:"permissions"`
+}
+
+// telegramMemberRights holds the admin and send rights not exposed by tgbotapi.ChatMember
+type telegramMemberRights struct {
+	telegramPermissions
+	Status          string `json:"status"`
+	CanManageTopics bool   `json:"can_manage_topics"`
+}
+
+// CheckTelegram verifies the bot token and inspects the group: whether it is a
+// forum (topics enabled) and whether the bot may manage topics, send messages and
+// send documents
+func CheckTelegram(botToken string, groupID int64) (*TelegramCheck, error) {
+	bot, err := tgbotapi.NewBotAPI(botToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bot token: %w", err)
+	}
+	return checkTelegramGroup(bot, groupID)
+}
+
+// Check inspects the group of the notifier like CheckTelegram, with its bot
+func (n *TelegramNotifier) Check() (*TelegramCheck, error) {
+	if !n.enabled || n.bot == nil {
+		return nil, fmt.Errorf("telegram notifier is not enabled")
+	}
+	return checkTelegramGroup(n.bot, n.groupID)
+}
+
+// checkTelegramGroup inspects the rights of bot in the group
+func checkTelegramGroup(bot *tgbotapi.BotAPI, groupID int64) (*TelegramCheck, error) {
+	check := &TelegramCheck{BotUsername: bot.Self.UserName}
+
+	resp, err := bot.Request(tgbotapi.ChatInfoConfig{ChatConfig: tgbotapi.ChatConfig{ChatID: groupID}})
+	if err != nil {
+		return check, fmt.Errorf("invalid group ID or bot is not a member of the group: %w", err)
+	}
+	var chat telegramChat
+	if err := json.Unmarshal(resp.Result, &chat); err != nil {
+		return check, fmt.Errorf("failed to parse group: %w", err)
+	}
+	check.ChatTitle = chat.Title
+	check.ChatType = chat.Type
+	check.IsForum = chat.IsForum
+
+	resp, err = bot.Request(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{
+			ChatID: groupID,
+			UserID: bot.Self.ID,
+		},
+	})
+	if err != nil {
+		return check, fmt.Errorf("failed to get bot permissions: %w", err)
+	}
+
+	var rights telegramMemberRights
+	if err := json.Unmarshal(resp.Result, &rights); err != nil {
+		return check, fmt.Errorf("failed to parse bot permissions: %w", err)
+	}
+	check.MemberStatus = rights.Status
+	check.CanManageTopics = rights.CanManageTopics
+
+	// Admins are not bound by the group's default permissions; restricted members
+	// have their own, other members the group's
+	switch rights.Status {
+	case "creator", "administrator":
+		check.CanSendMessages, check.CanSendDocuments = true, true
+	case "restricted":
+		check.CanSendMessages, check.CanSendDocuments = rights.CanSendMessages, rights.sendDocuments()
+	case "member":
+		check.CanSendMessages, check.CanSendDocuments = true, true
+		if chat.Permissions != nil {
+			check.CanSendMessages, check.CanSendDocuments = chat.Permissions.CanSendMessages, chat.Permissions.sendDocuments()
+		}
+	}
+
+	return check, nil
+}
+
+// SendTestMessage sends a plain test message to the group (the General topic in forums)
+func (n *TelegramNotifier) SendTestMessage(ctx context.Context, text string) error {
+	if !n.enabled || n.bot == nil {
+		return fmt.Errorf("telegram notifier is not enabled")
+	}
+
+	if _, err := n.bot.Send(tgbotapi.NewMessage(n.groupID, text)); err != nil {
+		return fmt.Errorf("failed to send test message: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Webhook request headers
+const (
+	// WebhookEventHeader names the payload: report, combined_report, exposure or test
+	WebhookEventHeader = "X-Audit-Event"
+
+	// WebhookDeliveryHeader is a unique ID per request, for deduplicating retries by the receiver
+	WebhookDeliveryHeader = "X-Audit-Delivery"
+
+	// WebhookSignatureHeader is "sha256=" followed by the hex HMAC-SHA256 of the body,
+	// keyed with WEBHOOK_SECRET. Only sent when a secret is configured.
+	WebhookSignatureHeader = "X-Audit-Signature-256"
+)
+
+// Webhook event names
+const (
+	WebhookEventReport         = "report"
+	WebhookEventCombinedReport = "combined_report"
+	WebhookEventExposure       = "exposure"
+	WebhookEventTest           = "test"
+)
+
+// webhookTestPayload is the payload of a test event, such as a notifier drill
+type webhookTestPayload struct {
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+// WebhookNotifier POSTs the JSON of reports to the global webhook URLs and to the
+// app's own URLs, so internal systems can consume audit results without a
+// first-class notifier. Requests are signed when a secret is configured.
+type WebhookNotifier struct {
+	urls    []string // receive the notifications of every app
+	secret  string
+	enabled bool
+	client  *http.Client
+}
+
+// NewWebhookNotifier creates a new WebhookNotifier. urls receive the notifications
+// of every app; apps can add their own URLs.
+func NewWebhookNotifier(urls []string, secret string, enabled bool) *WebhookNotifier {
+	return &WebhookNotifier{
+		urls:    urls,
+		secret:  secret,
+		enabled: enabled,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// Name returns "webhook"
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Enabled returns true if the notifier is enabled
+func (n *WebhookNotifier) Enabled() bool {
+	return n.enabled
+}
+
+// Send POSTs a report to the global URLs and to appURLs
+func (n *WebhookNotifier) Send(ctx context.Context, report *models.Report, appURLs []string) error {
+	return n.deliver(ctx, WebhookEventReport, report, appURLs)
+}
+
+// SendCombined POSTs the combined report of an app to the global URLs and to appURLs
+func (n *WebhookNotifier) SendCombined(ctx context.Context, combinedReport *models.CombinedAppReport, appURLs []string) error {
+	return n.deliver(ctx, WebhookEventCombinedReport, combinedReport, appURLs)
+}
+
+// SendExposure POSTs a broadcast exposure notice to the global URLs and to appURLs
+func (n *WebhookNotifier) SendExposure(ctx context.Context, notice *models.ExposureNotice, appURLs []string) error {
+	return n.deliver(ctx, WebhookEventExposure, notice, appURLs)
+}
+
+// SendTestMessage POSTs a test event to the global URLs and appURLs
+func (n *WebhookNotifier) SendTestMessage(ctx context.Context, title, message string, appURLs []string) error {
+	payload := webhookTestPayload{Title: title, Message: message, SentAt: time.Now().UTC()}
+	return n.deliver(ctx, WebhookEventTest, payload, appURLs)
+}
+
+// HasTargets returns true if a notification with appURLs would be delivered anywhere
+func (n *WebhookNotifier) HasTargets(appURLs []string) bool {
+	return len(n.targets(appURLs)) > 0
+}
+
+// targets returns the global URLs followed by appURLs, without duplicates
+func (n *WebhookNotifier) targets(appURLs []string) []string {
+	var targets []string
+	for _, url := range append(slices.Clone(n.urls), appURLs...) {
+		if url != "" && !slices.Contains(targets, url) {
+			targets = append(targets, url)
+		}
+	}
+	return targets
+}
+
+// deliver POSTs payload to every target. A failing URL does not stop the others.
+func (n *WebhookNotifier) deliver(ctx context.Context, event string, payload any, appURLs []string) error {
+	if !n.enabled {
+		return fmt.Errorf("webhook notifier is not enabled")
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	var errs []error
+	for _, url := range n.targets(appURLs) {
+		if err := n.post(ctx, url, event, body); err != nil {
+			helpers.Logger(ctx).Warnf("Webhook delivery failed url=%s event=%s error=%v", redactURL(url), event, err)
+			errs = append(errs, fmt.Errorf("%s: %w", redactURL(url), err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("webhook errors: %v", errs)
+	}
+	return nil
+}
+
+// post sends one signed request
+func (n *WebhookNotifier) post(ctx context.Context, url, event string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "audit-checks")
+	req.Header.Set(WebhookEventHeader, event)
+	req.Header.Set(WebhookDeliveryHeader, helpers.MustNewULID())
+	if n.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, WebhookSignature(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return nil
+}
+
+// WebhookSignature returns the X-Audit-Signature-256 value of body: receivers compute it
+// with the shared secret and compare it to the header in constant time
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// redactURL drops the query and user info of a webhook URL for logs, as they often carry tokens
+func redactURL(raw string) string {
+	if i := strings.IndexAny(raw, "?#"); i >= 0 {
+		raw = raw[:i]
+	}
+	if scheme, rest, ok := strings.Cut(raw, "://"); ok {
+		if at := strings.Index(rest, "@"); at >= 0 && at < strings.IndexByte(rest+"/", '/') {
+			rest = rest[at+1:]
+		}
+		raw = scheme + "://" + rest
+	}
+	return raw
+}
@@ -0,0 +1,351 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Zulip limits on messages and topic names
+const (
+	zulipMaxMessage = 10000
+	zulipMaxTopic   = 60
+)
+
+// zulipNoticeTopic is the topic of messages not about one app, such as notifier drills
+const zulipNoticeTopic = "Audit Checks"
+
+// ZulipNotifier posts notifications to a Zulip stream as a bot, like the Telegram forum
+// topics: one stream for security, with a topic per app (its name, or its own topic).
+// Messages use Zulip markdown, and the report files are uploaded and linked.
+type ZulipNotifier struct {
+	serverURL string
+	botEmail  string
+	apiKey    string
+	stream    string
+	enabled   bool
+	client    *http.Client
+}
+
+// NewZulipNotifier creates a new ZulipNotifier posting to stream as the bot with
+// botEmail and apiKey
+func NewZulipNotifier(serverURL, botEmail, apiKey, stream string, enabled bool) *ZulipNotifier {
+	return &ZulipNotifier{
+		serverURL: strings.TrimSuffix(serverURL, "/"),
+		botEmail:  botEmail,
+		apiKey:    apiKey,
+		stream:    stream,
+		enabled:   enabled && serverURL != "" && botEmail != "" && apiKey != "" && stream != "",
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns "zulip"
+func (n *ZulipNotifier) Name() string {
+	return "zulip"
+}
+
+// Enabled returns true if the notifier is configured
+func (n *ZulipNotifier) Enabled() bool {
+	return n.enabled
+}
+
+// Topic returns the topic of an app: its own topic, or its name
+func (n *ZulipNotifier) Topic(appName, appTopic string) string {
+	topic := strings.TrimSpace(appTopic)
+	if topic == "" {
+		topic = appName
+	}
+	return truncateRunes(topic, zulipMaxTopic)
+}
+
+// Send posts a report to the app's topic
+func (n *ZulipNotifier) Send(ctx context.Context, report *models.Report, recipients []string) error {
+	combinedReport := &models.CombinedAppReport{
+		AppName:     report.AppName,
+		AppPath:     report.AppPath,
+		Reports:     []*models.Report{report},
+		GeneratedAt: report.GeneratedAt,
+	}
+	return n.SendCombined(ctx, combinedReport, n.Topic(report.AppName, ""))
+}
+
+// SendCombined posts the combined report of an app to topic, with links to its report
+// files. A report that fails to upload is left out rather than failing the message.
+func (n *ZulipNotifier) SendCombined(ctx context.Context, combinedReport *models.CombinedAppReport, topic string) error {
+	log := helpers.Logger(ctx)
+
+	if !n.enabled {
+		return fmt.Errorf("zulip notifier is not enabled")
+	}
+
+	var links []string
+	for _, path := range combinedReport.ReportFiles {
+		uri, err := n.upload(ctx, path)
+		if err != nil {
+			log.Warnf("Failed to upload report to Zulip file=%s error=%v", path, err)
+			continue
+		}
+		links = append(links, fmt.Sprintf("[%s](%s)", zulipEscape(filepath.Base(path)), uri))
+	}
+
+	message := buildZulipMessage(combinedReport)
+	if len(links) > 0 {
+		message = truncateRunes(message, zulipMaxMessage-500) + "\n**Reports**: " + strings.Join(links, ", ") + "\n"
+	}
+
+	return n.post(ctx, topic, message)
+}
+
+// SendExposure posts a broadcast exposure notice to the app's topic
+func (n *ZulipNotifier) SendExposure(ctx context.Context, notice *models.ExposureNotice, topic string) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### :rotating_light: %s: %s is exposed\n\n", zulipEscape(notice.VulnerabilityID), zulipEscape(notice.AppName))
+	if notice.Summary != "" {
+		fmt.Fprintf(&sb, "%s\n\n", zulipEscape(notice.Summary))
+	}
+
+	sb.WriteString("| Package | Version | Lockfile |\n| :-- | :-- | :-- |\n")
+	for _, pkg := range notice.Packages {
+		fmt.Fprintf(&sb, "| %s | %s | %s |\n", zulipCell(pkg.Name), zulipCell(pkg.Version), zulipCell(pkg.Lockfile))
+	}
+
+	if notice.Message != "" {
+		fmt.Fprintf(&sb, "\n%s\n", zulipEscape(notice.Message))
+	}
+
+	return n.post(ctx, topic, truncateRunes(sb.String(), zulipMaxMessage))
+}
+
+// SendTestMessage posts a plain message, such as a notifier drill, to the "Audit
+// Checks" topic of the stream
+func (n *ZulipNotifier) SendTestMessage(ctx context.Context, text string) error {
+	if err := n.post(ctx, zulipNoticeTopic, truncateRunes(text, zulipMaxMessage)); err != nil {
+		return fmt.Errorf("failed to send test message: %w", err)
+	}
+	return nil
+}
+
+// buildZulipMessage renders the combined report: a table of the findings per auditor,
+// the most severe ones, the failed auditors and violated policies
+func buildZulipMessage(combinedReport *models.CombinedAppReport) string {
+	var sb strings.Builder
+
+	// Only auditor failures or policy violations: there is no vulnerability summary to show
+	if !combinedReport.HasVulnerabilities() {
+		fmt.Fprintf(&sb, "### :cross_mark: %s: %s\n", problemTitle(combinedReport), zulipEscape(combinedReport.AppName))
+		writeZulipFailures(&sb, combinedReport.Failures)
+		writeZulipPolicyViolations(&sb, combinedReport.PolicyViolations)
+		writeZulipRunID(&sb, combinedReport.RunID)
+		return truncateRunes(sb.String(), zulipMaxMessage)
+	}
+
+	summary := combinedReport.GetCombinedSummary()
+	emoji := ":warning:"
+	if summary.Critical > 0 || summary.High > 0 {
+		emoji = ":rotating_light:"
+	}
+	fmt.Fprintf(&sb, "### %s Security Alert: %s\n\n", emoji, zulipEscape(combinedReport.AppName))
+
+	sb.WriteString("| Auditor | Critical | High | Moderate | Low | Info | Total |\n| :-- | --: | --: | --: | --: | --: | --: |\n")
+	for _, report := range combinedReport.Reports {
+		s := report.GetSummary()
+		fmt.Fprintf(&sb, "| %s | %d | %d | %d | %d | %d | %d |\n",
+			zulipCell(report.AuditorType), s.Critical, s.High, s.Moderate, s.Low, s.Info, s.Total)
+	}
+	if len(combinedReport.Reports) > 1 {
+		fmt.Fprintf(&sb, "| **Total** | **%d** | **%d** | **%d** | **%d** | **%d** | **%d** |\n",
+			summary.Critical, summary.High, summary.Moderate, summary.Low, summary.Info, summary.Total)
+	}
+
+	// Top vulnerabilities across all auditors (limit to 10)
+	topVulns := collectTopVulnerabilities(combinedReport, 10)
+	if len(topVulns) > 0 {
+		sb.WriteString("\n**Top Issues**\n")
+		for _, v := range topVulns {
+			fmt.Fprintf(&sb, "- **%s** %s", zulipEscape(v.PackageName), zulipEscape(truncateRunes(v.Title, 100)))
+			if v.CVEID != "" {
+				fmt.Fprintf(&sb, " `%s`", strings.ReplaceAll(v.CVEID, "`", ""))
+			}
+			fmt.Fprintf(&sb, " (%s)\n", severityAndAge(v))
+		}
+		if summary.Total > len(topVulns) {
+			fmt.Fprintf(&sb, "\n*... and %d more*\n", summary.Total-len(topVulns))
+		}
+	}
+
+	// AI Summary if available (from any report), collapsed
+	for _, report := range combinedReport.Reports {
+		if report.AIAnalysis != nil && report.AIAnalysis.Summary != "" {
+			fmt.Fprintf(&sb, "\n```spoiler AI Summary\n%s\n```\n", zulipEscape(report.AIAnalysis.Summary))
+			break // Only include one AI summary
+		}
+	}
+
+	writeZulipFailures(&sb, combinedReport.Failures)
+	writeZulipPolicyViolations(&sb, combinedReport.PolicyViolations)
+
+	if commands := combinedFixCommands(combinedReport); len(commands) > 0 {
+		fmt.Fprintf(&sb, "\nRun %s to fix issues\n", strings.Join(commands, " and "))
+	}
+
+	writeZulipRunID(&sb, combinedReport.RunID)
+
+	return truncateRunes(sb.String(), zulipMaxMessage)
+}
+
+// writeZulipFailures writes a table of the auditors that failed with their remediation hints
+func writeZulipFailures(sb *strings.Builder, failures []models.AuditFailure) {
+	if len(failures) == 0 {
+		return
+	}
+
+	sb.WriteString("\n**Failed Auditors**\n\n| Auditor | Kind | Error | Hint |\n| :-- | :-- | :-- | :-- |\n")
+	for _, f := range failures {
+		fmt.Fprintf(sb, "| %s | %s | %s | %s |\n",
+			zulipCell(strings.ToUpper(f.AuditorType)),
+			zulipCell(f.Kind),
+			zulipCell(truncateRunes(f.Message, maxFailureMessageLength)),
+			zulipCell(f.Hint),
+		)
+	}
+}
+
+// writeZulipPolicyViolations writes a table of the violated policies with what violates them
+func writeZulipPolicyViolations(sb *strings.Builder, violations []models.PolicyViolation) {
+	if len(violations) == 0 {
+		return
+	}
+
+	sb.WriteString("\n**Policies Violated**\n\n| Policy | Action | Violated By |\n| :-- | :-- | :-- |\n")
+	for _, v := range violations {
+		fmt.Fprintf(sb, "| %s | %s | %s |\n",
+			zulipCell(v.Policy),
+			strings.ToUpper(v.Action),
+			zulipCell(policyViolationDetail(v, 5)),
+		)
+	}
+}
+
+// writeZulipRunID writes the run ID, so the message can be matched to the run's logs
+func writeZulipRunID(sb *strings.Builder, runID string) {
+	if runID != "" {
+		fmt.Fprintf(sb, "\n*Run %s*\n", runID)
+	}
+}
+
+// zulipEscape escapes Zulip markdown characters, and the @ and # starting mentions and
+// stream links
+func zulipEscape(s string) string {
+	replacer := strings.NewReplacer(
+		"\\", "\\\\",
+		"*", "\\*",
+		"_", "\\_",
+		"~", "\\~",
+		"`", "\\`",
+		"|", "\\|",
+		"#", "\\#",
+		"@", "\\@",
+		"$$", "\\$\\$",
+	)
+	return replacer.Replace(s)
+}
+
+// zulipCell escapes a table cell, which must stay on one line
+func zulipCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if s == "" {
+		return "-"
+	}
+	return zulipEscape(s)
+}
+
+// zulipResponse is the response of the Zulip API
+type zulipResponse struct {
+	Result string `json:"result"` // success or error
+	Msg    string `json:"msg"`
+	Code   string `json:"code"`
+	URI    string `json:"uri"` // of an uploaded file
+}
+
+// post sends a stream message to topic
+func (n *ZulipNotifier) post(ctx context.Context, topic, content string) error {
+	if !n.enabled {
+		return fmt.Errorf("zulip notifier is not enabled")
+	}
+
+	form := url.Values{
+		"type":    {"stream"},
+		"to":      {n.stream},
+		"topic":   {topic},
+		"content": {content},
+	}
+	_, err := n.do(ctx, "/api/v1/messages", strings.NewReader(form.Encode()), "application/x-www-form-urlencoded")
+	return err
+}
+
+// upload uploads a file and returns its URI, relative to the server, for links in messages
+func (n *ZulipNotifier) upload(ctx context.Context, path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read report %s: %w", path, err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(content); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	resp, err := n.do(ctx, "/api/v1/user_uploads", &body, writer.FormDataContentType())
+	if err != nil {
+		return "", err
+	}
+	return resp.URI, nil
+}
+
+// do sends a request to the Zulip API, authenticated as the bot
+func (n *ZulipNotifier) do(ctx context.Context, path string, body io.Reader, contentType string) (*zulipResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.serverURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.SetBasicAuth(n.botEmail, n.apiKey)
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result zulipResponse
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&result)
+	if resp.StatusCode >= 300 || result.Result != "success" {
+		if decodeErr == nil && result.Msg != "" {
+			return nil, fmt.Errorf("zulip API error: status %d: %s", resp.StatusCode, result.Msg)
+		}
+		return nil, fmt.Errorf("zulip API error: status %d", resp.StatusCode)
+	}
+
+	return &result, nil
+}
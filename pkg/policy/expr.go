@@ -0,0 +1,120 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/common/types"
+	"github.com/google/cel-go/ext"
+)
+
+// The expressions of policies are CEL (https://cel.dev), type-checked against the
+// variables of their scope: objects with the fields of their variable, whose values are
+// given as maps. They may use the standard functions and macros of CEL and its string
+// extensions (lowerAscii, split, ...).
+
+// Variable is a variable of expressions: an object of the type TypeName with Fields
+type Variable struct {
+	Name     string
+	TypeName string
+	Fields   map[string]*cel.Type
+}
+
+// Program is a compiled expression
+type Program struct {
+	source  string
+	program cel.Program
+}
+
+// String returns the source of the expression
+func (p *Program) String() string {
+	return p.source
+}
+
+// Eval evaluates the expression with the variables of vars, e.g. {"app": {...}}, each
+// a map of the fields of its Variable
+func (p *Program) Eval(vars map[string]any) (bool, error) {
+	value, _, err := p.program.Eval(vars)
+	if err != nil {
+		return false, err
+	}
+	b, ok := value.Value().(bool)
+	if !ok {
+		return false, fmt.Errorf("expression is %s, not a bool", value.Type().TypeName())
+	}
+	return b, nil
+}
+
+// Compile parses and checks an expression using variables, so that a misspelt name, a
+// comparison of a string with a number or an invalid constant pattern fails here
+// rather than when evaluated. The expression must be a bool.
+func Compile(source string, variables []Variable) (*Program, error) {
+	provider := &variableProvider{Provider: types.NewEmptyRegistry(), variables: map[string]Variable{}}
+	options := []cel.EnvOption{cel.CustomTypeProvider(provider), ext.Strings()}
+	for _, v := range variables {
+		provider.variables[v.TypeName] = v
+		options = append(options, cel.Variable(v.Name, cel.ObjectType(v.TypeName)))
+	}
+	env, err := cel.NewEnv(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(source)
+	if issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	if !ast.OutputType().IsExactType(cel.BoolType) {
+		return nil, fmt.Errorf("expression is %s, not a bool", ast.OutputType())
+	}
+	// Optimized, constant patterns are compiled here
+	program, err := env.Program(ast, cel.EvalOptions(cel.OptOptimize))
+	if err != nil {
+		return nil, err
+	}
+	return &Program{source: source, program: program}, nil
+}
+
+// variableProvider declares the types of the variables to the checker, and reads their
+// fields from the maps given to Eval
+type variableProvider struct {
+	types.Provider
+	variables map[string]Variable // by type name
+}
+
+// FindStructType returns the type of a variable, or a type of CEL
+func (p *variableProvider) FindStructType(typeName string) (*types.Type, bool) {
+	if _, ok := p.variables[typeName]; ok {
+		return types.NewTypeTypeWithParam(types.NewObjectType(typeName)), true
+	}
+	return p.Provider.FindStructType(typeName)
+}
+
+// FindStructFieldType returns the type of a field of a variable, reading it from maps
+func (p *variableProvider) FindStructFieldType(typeName, field string) (*types.FieldType, bool) {
+	v, ok := p.variables[typeName]
+	if !ok {
+		return p.Provider.FindStructFieldType(typeName, field)
+	}
+	fieldType, ok := v.Fields[field]
+	if !ok {
+		return nil, false
+	}
+
+	return &types.FieldType{
+		Type: fieldType,
+		IsSet: func(target any) bool {
+			fields, _ := target.(map[string]any)
+			_, ok := fields[field]
+			return ok
+		},
+		GetFrom: func(target any) (any, error) {
+			fields, _ := target.(map[string]any)
+			value, ok := fields[field]
+			if !ok {
+				return nil, fmt.Errorf("no such field %s.%s", v.Name, field)
+			}
+			return value, nil
+		},
+	}, true
+}
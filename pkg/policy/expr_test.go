@@ -0,0 +1,117 @@
+package policy
+
+import (
+	"strings"
+	"testing"
+)
+
+var testVariables = []Variable{appVariable, findingVariable}
+
+func testVars() map[string]any {
+	return map[string]any{
+		"app": map[string]any{
+			"name":            "shop",
+			"tags":            []string{"prod", "pci"},
+			"critical":        2,
+			"failed_auditors": []string{},
+		},
+		"finding": map[string]any{
+			"severity": "critical",
+			"age_days": 9,
+			"package":  "Lodash",
+			"cve":      "",
+			"title":    "CVE-2021-23337 in lodash",
+			"fixable":  true,
+		},
+	}
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{`"prod" in app.tags && finding.severity == "critical" && finding.age_days > 7`, true},
+		{`"prod" in app.tags && finding.severity == "critical" && finding.age_days > 30`, false},
+		{`!("internal" in app.tags)`, true},
+		{`app.critical >= 2 || false`, true},
+		{`finding.package.lowerAscii().startsWith('lod')`, true},
+		{`finding.title.matches(r'^CVE-\d+-\d+ ')`, true},
+		{`finding.title.matches('^CVE-\\d+')`, true},
+		{`finding.title.contains("lodash") && finding.title.endsWith("lodash")`, true},
+		{`app.tags.exists(t, t.matches("^pr"))`, true},
+		{`app.tags.all(t, t == "prod")`, false},
+		{`app.tags.exists(t, app.tags.exists(u, u != t))`, true},
+		{`size(app.failed_auditors) > 0`, false},
+		{`app.tags.size() == 2 && size("é") == 1`, true},
+		{`app.tags[1] == "pci"`, true},
+		{`finding.cve == "" && finding.fixable`, true},
+		{`finding.severity in ["critical", "high"]`, true},
+		{`has(app.owner) || app.name == "shop"`, true},
+		{`-finding.age_days < -8 && finding.age_days % 7 == 2 && finding.age_days / 3 == 3`, true},
+		{`"a" + "b" == "ab" && size(app.tags + ["x"]) == 3`, true},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.source, testVariables)
+		if err != nil {
+			t.Errorf("Compile(%s): %v", tt.source, err)
+			continue
+		}
+		got, err := p.Eval(testVars())
+		if err != nil {
+			t.Errorf("Eval(%s): %v", tt.source, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%s) = %v, want %v", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		err    string
+	}{
+		{`app.nme == "x"`, "undefined field 'nme'"},
+		{`dependency.name == "x"`, "undeclared reference to 'dependency'"},
+		{`app.tags.exists(t, u == 1)`, "undeclared reference to 'u'"},
+		{`app.name.foo()`, "undeclared reference to 'foo'"},
+		{`app.name > 3`, "no matching overload for '_>_'"},
+		{`app.name - 1 == 0`, "no matching overload for '_-_'"},
+		{`app.critical`, "expression is int, not a bool"},
+		{`app.name.matches("(")`, "missing closing )"},
+		{`finding.title.matches('CVE-\d+')`, "token recognition error"},
+		{`app.name ==`, "Syntax error"},
+	}
+	for _, tt := range tests {
+		_, err := Compile(tt.source, testVariables)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Compile(%s) error = %v, want %q", tt.source, err, tt.err)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		err    string
+	}{
+		{`app.tags[2] == "x"`, "index out of bounds"},
+		{`finding.age_days / 0 > 1`, "division by zero"},
+		{`finding.age_days % 0 > 1`, "modulus by zero"},
+		{`app.name.matches(app.name + "(")`, "missing closing )"},
+		{`app.owner == "x"`, "no such field app.owner"},
+	}
+	for _, tt := range tests {
+		p, err := Compile(tt.source, testVariables)
+		if err != nil {
+			t.Errorf("Compile(%s): %v", tt.source, err)
+			continue
+		}
+		_, err = p.Eval(testVars())
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Eval(%s) error = %v, want %q", tt.source, err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+// Package policy evaluates the pass/fail rules of an organization (POLICY_FILE) on the
+// latest results of its apps: a policy is an expression denying findings, dependencies
+// or apps, e.g. critical findings open for more than a week in production apps.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.yaml.in/yaml/v3"
+)
+
+// Policy scopes: what the deny expression of a policy is evaluated on
+const (
+	ScopeFinding    = "finding"    // each open finding of the app
+	ScopeDependency = "dependency" // each dependency of the app's lockfiles
+	ScopeApp        = "app"        // the app
+)
+
+// schemas are the variables the deny expressions of each scope can use
+var schemas = map[string][]Variable{
+	ScopeFinding:    {appVariable, findingVariable},
+	ScopeDependency: {appVariable, dependencyVariable},
+	ScopeApp:        {appVariable},
+}
+
+var (
+	appVariable = Variable{Name: "app", TypeName: "policy.App", Fields: map[string]*cel.Type{
+		"name": cel.StringType, "path": cel.StringType, "type": cel.StringType, "owner": cel.StringType,
+		"tags": cel.ListType(cel.StringType), "auditors": cel.ListType(cel.StringType), "failed_auditors": cel.ListType(cel.StringType),
+		"findings": cel.IntType, "critical": cel.IntType, "high": cel.IntType, "moderate": cel.IntType, "low": cel.IntType, "info": cel.IntType,
+	}}
+	findingVariable = Variable{Name: "finding", TypeName: "policy.Finding", Fields: map[string]*cel.Type{
+		"auditor": cel.StringType, "package": cel.StringType, "severity": cel.StringType, "cve": cel.StringType,
+		"title": cel.StringType, "age_days": cel.IntType, "vulnerable_versions": cel.StringType,
+		"patched_versions": cel.StringType, "fixable": cel.BoolType,
+	}}
+	dependencyVariable = Variable{Name: "dependency", TypeName: "policy.Dependency", Fields: map[string]*cel.Type{
+		"name": cel.StringType, "version": cel.StringType, "ecosystem": cel.StringType, "scope": cel.StringType,
+		"direct": cel.BoolType, "license": cel.StringType,
+	}}
+)
+
+// Policy is a rule of the policy file
+type Policy struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+	Scope       string `yaml:"scope"`  // finding (default), dependency or app
+	Deny        string `yaml:"deny"`   // expression true for what violates the policy
+	Action      string `yaml:"action"` // fail (default) or warn
+
+	program *Program
+}
+
+// Set is the policies of a policy file
+type Set struct {
+	Path     string
+	Policies []*Policy
+}
+
+// Input is what the policies of an app are evaluated on
+type Input struct {
+	App          models.AppConfig
+	Results      []models.AuditResult // latest result of each auditor, with its vulnerabilities
+	Failed       []string             // auditors that failed
+	Dependencies []models.Dependency  // read only when NeedsDependencies
+	Now          time.Time
+}
+
+// Load reads and compiles a policy file
+func Load(path string) (*Set, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy file: %w", err)
+	}
+
+	var file struct {
+		Policies []*Policy `yaml:"policies"`
+	}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+
+	set := &Set{Path: path, Policies: file.Policies}
+	var names []string
+	for i, p := range set.Policies {
+		if p.Name == "" {
+			return nil, fmt.Errorf("%s: policy %d has no name", path, i+1)
+		}
+		if slices.Contains(names, p.Name) {
+			return nil, fmt.Errorf("%s: policy %s is defined twice", path, p.Name)
+		}
+		names = append(names, p.Name)
+
+		if p.Scope == "" {
+			p.Scope = ScopeFinding
+		}
+		if p.Action == "" {
+			p.Action = models.PolicyActionFail
+		}
+		schema, ok := schemas[p.Scope]
+		if !ok {
+			return nil, fmt.Errorf("%s: policy %s: invalid scope %q (expected finding, dependency or app)", path, p.Name, p.Scope)
+		}
+		if p.Action != models.PolicyActionFail && p.Action != models.PolicyActionWarn {
+			return nil, fmt.Errorf("%s: policy %s: invalid action %q (expected fail or warn)", path, p.Name, p.Action)
+		}
+		if p.Deny == "" {
+			return nil, fmt.Errorf("%s: policy %s has no deny expression", path, p.Name)
+		}
+		if p.program, err = Compile(p.Deny, schema); err != nil {
+			return nil, fmt.Errorf("%s: policy %s: %w", path, p.Name, err)
+		}
+	}
+
+	return set, nil
+}
+
+// NeedsDependencies returns true if a policy is evaluated on the dependencies of apps,
+// which are then read from their lockfiles
+func (s *Set) NeedsDependencies() bool {
+	return slices.ContainsFunc(s.Policies, func(p *Policy) bool { return p.Scope == ScopeDependency })
+}
+
+// Evaluate returns the policies an app violates, in the order of the file. A policy
+// whose expression fails on the app (e.g. divides by zero) is violated
+// with its error, so that a fail policy does not pass unchecked, and the errors are
+// also returned.
+func (s *Set) Evaluate(input Input) ([]models.PolicyViolation, error) {
+	app := appVars(input)
+
+	var violations []models.PolicyViolation
+	var errs []error
+	for _, p := range s.Policies {
+		violation := models.PolicyViolation{
+			Policy:      p.Name,
+			Description: p.Description,
+			Action:      p.Action,
+			AppName:     input.App.Name,
+		}
+
+		denied, err := p.evaluate(input, app, &violation)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("policy %s: %w", p.Name, err))
+			violation.Error = err.Error()
+		}
+		if denied || err != nil {
+			violations = append(violations, violation)
+		}
+	}
+
+	return violations, errors.Join(errs...)
+}
+
+// evaluate evaluates the policy on its scope of the input, adding the findings or
+// dependencies it denies to the subjects of violation
+func (p *Policy) evaluate(input Input, app map[string]any, violation *models.PolicyViolation) (bool, error) {
+	switch p.Scope {
+	case ScopeApp:
+		return p.program.Eval(map[string]any{"app": app})
+
+	case ScopeDependency:
+		for _, dep := range input.Dependencies {
+			denied, err := p.program.Eval(map[string]any{"app": app, "dependency": dependencyVars(dep)})
+			if err != nil {
+				return false, err
+			}
+			if denied {
+				subject := dep.Name + "@" + dep.Version
+				if dep.License != "" {
+					subject += " (" + dep.License + ")"
+				}
+				violation.Subjects = appendUnique(violation.Subjects, subject)
+			}
+		}
+
+	default:
+		for _, r := range input.Results {
+			for _, v := range r.Vulnerabilities {
+				denied, err := p.program.Eval(map[string]any{"app": app, "finding": findingVars(r.AuditorType, v, input.Now)})
+				if err != nil {
+					return false, err
+				}
+				if denied {
+					subject := v.PackageName + " " + v.CVEID
+					if v.CVEID == "" {
+						subject = v.PackageName + ": " + v.Title
+					}
+					violation.Subjects = appendUnique(violation.Subjects, subject)
+				}
+			}
+		}
+	}
+
+	return len(violation.Subjects) > 0, nil
+}
+
+// appVars is the app variable of expressions
+func appVars(input Input) map[string]any {
+	var auditors []string
+	counts := map[string]int{}
+	for _, r := range input.Results {
+		auditors = append(auditors, r.AuditorType)
+		counts["findings"] += r.TotalVulnerabilities
+		counts[models.SeverityCritical] += r.CriticalCount
+		counts[models.SeverityHigh] += r.HighCount
+		counts[models.SeverityModerate] += r.ModerateCount
+		counts[models.SeverityLow] += r.LowCount
+		counts[models.SeverityInfo] += r.InfoCount
+	}
+
+	return map[string]any{
+		"name":            input.App.Name,
+		"path":            input.App.Path,
+		"type":            input.App.Type,
+		"owner":           input.App.Owner,
+		"tags":            nonNil(input.App.Tags),
+		"auditors":        nonNil(auditors),
+		"failed_auditors": nonNil(input.Failed),
+		"findings":        counts["findings"],
+		"critical":        counts[models.SeverityCritical],
+		"high":            counts[models.SeverityHigh],
+		"moderate":        counts[models.SeverityModerate],
+		"low":             counts[models.SeverityLow],
+		"info":            counts[models.SeverityInfo],
+	}
+}
+
+// findingVars is the finding variable of expressions; age_days is -1 when unknown
+func findingVars(auditor string, v models.Vulnerability, now time.Time) map[string]any {
+	return map[string]any{
+		"auditor":             auditor,
+		"package":             v.PackageName,
+		"severity":            v.Severity,
+		"cve":                 v.CVEID,
+		"title":               v.Title,
+		"age_days":            v.AgeDays(now),
+		"vulnerable_versions": v.VulnerableVersions,
+		"patched_versions":    v.PatchedVersions,
+		"fixable":             v.PatchedVersions != "",
+	}
+}
+
+// dependencyVars is the dependency variable of expressions
+func dependencyVars(dep models.Dependency) map[string]any {
+	return map[string]any{
+		"name":      dep.Name,
+		"version":   dep.Version,
+		"ecosystem": dep.Ecosystem,
+		"scope":     dep.Scope,
+		"direct":    dep.Direct,
+		"license":   dep.License,
+	}
+}
+
+// nonNil returns items, or an empty list for nil
+func nonNil(items []string) []string {
+	if items == nil {
+		return []string{}
+	}
+	return items
+}
+
+func appendUnique(items []string, item string) []string {
+	if slices.Contains(items, item) {
+		return items
+	}
+	return append(items, item)
+}
@@ -0,0 +1,162 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+func writePolicies(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policies.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testInput(tags ...string) Input {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tenDaysAgo := now.AddDate(0, 0, -10)
+	yesterday := now.AddDate(0, 0, -1)
+	return Input{
+		App: models.AppConfig{Name: "shop", Tags: tags},
+		Results: []models.AuditResult{{
+			AuditorType:   "npm",
+			CriticalCount: 2,
+			Vulnerabilities: []models.Vulnerability{
+				{PackageName: "lodash", CVEID: "CVE-2021-23337", Severity: models.SeverityCritical, FirstSeenAt: &tenDaysAgo},
+				{PackageName: "axios", CVEID: "CVE-2023-45857", Severity: models.SeverityCritical, FirstSeenAt: &yesterday},
+				{PackageName: "minimist", Title: "Prototype Pollution", Severity: models.SeverityHigh, PatchedVersions: ">=1.2.6", FirstSeenAt: &tenDaysAgo},
+			},
+		}},
+		Failed: []string{"composer"},
+		Dependencies: []models.Dependency{
+			{Name: "lodash", Version: "4.17.20", License: "MIT"},
+			{Name: "readline-sync", Version: "1.4.10", License: "GPL-3.0-only"},
+			{Name: "libfoo", Version: "2.0.0", License: "LGPL-2.1"},
+			{Name: "unlicensed", Version: "1.0.0"},
+		},
+		Now: now,
+	}
+}
+
+// TestExamplePolicies evaluates the policies of policies.example.yaml
+func TestExamplePolicies(t *testing.T) {
+	set, err := Load(filepath.Join("..", "..", "policies.example.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !set.NeedsDependencies() {
+		t.Error("NeedsDependencies = false, want true for no-gpl-licenses")
+	}
+
+	violations, err := set.Evaluate(testInput("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]string{
+		"no-old-criticals-in-prod": {"lodash CVE-2021-23337"},
+		"no-gpl-licenses":          {"readline-sync@1.4.10 (GPL-3.0-only)", "libfoo@2.0.0 (LGPL-2.1)"},
+		"all-auditors-succeed":     nil,
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("violations = %+v, want %d", violations, len(want))
+	}
+	for _, v := range violations {
+		subjects, ok := want[v.Policy]
+		if !ok {
+			t.Errorf("unexpected violation of %s", v.Policy)
+			continue
+		}
+		if !slices.Equal(v.Subjects, subjects) {
+			t.Errorf("%s subjects = %q, want %q", v.Policy, v.Subjects, subjects)
+		}
+		if v.AppName != "shop" || v.Description == "" {
+			t.Errorf("%s = %+v, want the app and description", v.Policy, v)
+		}
+	}
+
+	// Internal apps may use GPL dependencies, and untagged apps have no production policy
+	violations, err = set.Evaluate(testInput("internal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range violations {
+		if v.Policy != "all-auditors-succeed" {
+			t.Errorf("unexpected violation of %s for an internal app", v.Policy)
+		}
+		if v.Failing() {
+			t.Errorf("%s is failing, want its warn action", v.Policy)
+		}
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		content string
+		err     string
+	}{
+		{"policies:\n  - deny: 'true'\n", "policy 1 has no name"},
+		{"policies:\n  - {name: a, deny: 'true'}\n  - {name: a, deny: 'true'}\n", "policy a is defined twice"},
+		{"policies:\n  - {name: a, scope: fleet, deny: 'true'}\n", `invalid scope "fleet"`},
+		{"policies:\n  - {name: a, action: block, deny: 'true'}\n", `invalid action "block"`},
+		{"policies:\n  - {name: a}\n", "policy a has no deny expression"},
+		{"policies:\n  - {name: a, scope: app, deny: 'finding.severity == \"high\"'}\n", "undeclared reference to 'finding'"},
+		{"policies:\n  - {name: a, deny: 'index(app.tags)'}\n", "undeclared reference to 'index'"},
+		{"policies:\n  - {name: a, deny: 'app.name > 3'}\n", "no matching overload for '_>_' applied to '(string, int)'"},
+		{"policies:\n  - {name: a, deny: \"finding.title.matches('CVE-\\\\d+')\"}\n", "token recognition error"},
+		{"policies: [", "invalid policy file"},
+	}
+	for _, tt := range tests {
+		_, err := Load(writePolicies(t, tt.content))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Load(%q) error = %v, want %q", tt.content, err, tt.err)
+		}
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("Load of a missing file succeeded")
+	}
+}
+
+// TestEvaluateError checks that a policy failing to evaluate is violated with its
+// error, with the policy's action, rather than passing
+func TestEvaluateError(t *testing.T) {
+	set, err := Load(writePolicies(t, `policies:
+  - name: broken
+    scope: dependency
+    deny: 'dependency.license.matches("^GPL") || dependency.version.matches(dependency.name + "(")'
+  - name: warned
+    action: warn
+    deny: 'finding.severity == "critical" && finding.age_days / (finding.age_days - 10) > 0'
+  - name: fine
+    scope: app
+    deny: 'app.critical > 1'
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	violations, err := set.Evaluate(testInput())
+	if err == nil {
+		t.Fatal("Evaluate error = nil, want the errors of broken and warned")
+	}
+	if len(violations) != 3 {
+		t.Fatalf("violations = %+v, want 3", violations)
+	}
+	if v := violations[0]; v.Policy != "broken" || !v.Failing() || !strings.Contains(v.Error, "missing closing )") {
+		t.Errorf("broken = %+v, want a failing violation with its error", v)
+	}
+	if v := violations[1]; v.Policy != "warned" || v.Failing() || !strings.Contains(v.Error, "division by zero") {
+		t.Errorf("warned = %+v, want a warn violation with its error", v)
+	}
+	if v := violations[2]; v.Policy != "fine" || v.Error != "" {
+		t.Errorf("fine = %+v, want a violation without error", v)
+	}
+}
@@ -0,0 +1,143 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.yaml.in/yaml/v3"
+)
+
+// AlertRuleOptions are the thresholds of the Prometheus alerting rules, from the config
+type AlertRuleOptions struct {
+	Job                 string        // Prometheus job scraping serve's /metrics
+	StaleAfter          time.Duration // time without an audit after which an app is stale
+	Severities          []string      // severities that notify (SEVERITY_THRESHOLD and above)
+	NotificationRetries bool          // the job queue retries failed notifications
+	EmailWebhook        bool          // the Resend webhook records email bounces
+}
+
+// alertRuleFile is a Prometheus rule file
+type alertRuleFile struct {
+	Groups []alertRuleGroup `yaml:"groups"`
+}
+
+type alertRuleGroup struct {
+	Name  string      `yaml:"name"`
+	Rules []alertRule `yaml:"rules"`
+}
+
+type alertRule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// promDuration formats d as a Prometheus duration, e.g. 7d or 36h
+func promDuration(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
+
+// GenerateAlertRules creates the recommended Prometheus alerting rules over the metrics
+// served by serve at /metrics: serve down, apps not audited, vulnerabilities that
+// notify, and notifications that failed
+func GenerateAlertRules(opts AlertRuleOptions) ([]byte, error) {
+	rules := []alertRule{
+		{
+			Alert:  "AuditChecksDown",
+			Expr:   fmt.Sprintf(`up{job=%q} == 0`, opts.Job),
+			For:    "10m",
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "audit-checks metrics cannot be scraped",
+				"description": "Prometheus could not scrape /metrics of audit-checks serve on {{ $labels.instance }} for 10 minutes.",
+			},
+		},
+		{
+			Alert:  "AuditChecksAuditStale",
+			Expr:   fmt.Sprintf("time() - audit_checks_app_last_audit_timestamp_seconds > %d", int64(opts.StaleAfter.Seconds())),
+			For:    "1h",
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "{{ $labels.app }} has not been audited for " + promDuration(opts.StaleAfter),
+				"description": "The last audit of {{ $labels.app }} is older than " + promDuration(opts.StaleAfter) + ", or it was never audited: check the schedule and `audit-checks doctor`.",
+			},
+		},
+		{
+			Alert:  "AuditChecksCriticalVulnerabilities",
+			Expr:   `audit_checks_open_vulnerabilities{severity="critical"} > 0`,
+			For:    "15m",
+			Labels: map[string]string{"severity": "critical"},
+			Annotations: map[string]string{
+				"summary":     "{{ $labels.app }} has {{ $value }} critical vulnerabilities",
+				"description": "The latest audits of {{ $labels.app }} report {{ $value }} critical vulnerabilities.",
+			},
+		},
+	}
+
+	var severities []string
+	for _, severity := range opts.Severities {
+		if severity != models.SeverityCritical {
+			severities = append(severities, severity)
+		}
+	}
+	if len(severities) > 0 {
+		label := strings.Join(severities, "/")
+		rules = append(rules, alertRule{
+			Alert:  "AuditChecksVulnerabilities",
+			Expr:   fmt.Sprintf(`sum by (app) (audit_checks_open_vulnerabilities{severity=~%q}) > 0`, strings.Join(severities, "|")),
+			For:    "1h",
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "{{ $labels.app }} has {{ $value }} " + label + " vulnerabilities",
+				"description": "The latest audits of {{ $labels.app }} report {{ $value }} " + label + " vulnerabilities.",
+			},
+		})
+	}
+
+	if opts.NotificationRetries {
+		rules = append(rules, alertRule{
+			Alert:  "AuditChecksNotificationFailed",
+			Expr:   "increase(audit_checks_notification_retries_failed_total[1h]) > 0",
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "Notifications of {{ $labels.app }} failed",
+				"description": "Retries of the notifications of {{ $labels.app }} failed for good in the last hour: `audit-checks jobs --type notify --status failed` shows why.",
+			},
+		})
+	}
+
+	if opts.EmailWebhook {
+		rules = append(rules, alertRule{
+			Alert:  "AuditChecksEmailBounced",
+			Expr:   `increase(audit_checks_emails_total{status=~"bounced|complained"}[1d]) > 0`,
+			Labels: map[string]string{"severity": "warning"},
+			Annotations: map[string]string{
+				"summary":     "Audit emails bounced",
+				"description": "Emails of audit-checks bounced or were marked as spam in the last day; their recipients no longer get notified until `audit-checks suppressions remove`.",
+			},
+		})
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(alertRuleFile{Groups: []alertRuleGroup{{Name: "audit-checks", Rules: rules}}}); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,76 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// confluenceStatusColours are the colours of the status lozenges of the severities
+var confluenceStatusColours = map[string]string{
+	models.SeverityCritical: "Red",
+	models.SeverityHigh:     "Red",
+	models.SeverityModerate: "Yellow",
+	models.SeverityLow:      "Blue",
+	models.SeverityInfo:     "Grey",
+}
+
+// confluenceTemplate is the body of an app's Confluence page, in Confluence's storage
+// format (XHTML with Confluence macros)
+var confluenceTemplate = htmltemplate.Must(htmltemplate.New("confluence").Funcs(map[string]any{
+	"upper":   strings.ToUpper,
+	"default": defaultValue,
+	"join":    strings.Join,
+	"colour":  func(severity string) string { return defaultValue("Grey", confluenceStatusColours[severity]) },
+	// Replaced for each page, see GenerateConfluencePage
+	"firstSeen": func(v models.Vulnerability) string { return "" },
+}).Parse(`<ac:structured-macro ac:name="info"><ac:rich-text-body><p>Updated by {{.GeneratedBy}} after each audit of {{.AppName}}; changes made here are overwritten.</p></ac:rich-text-body></ac:structured-macro>
+<p><strong>Last audit:</strong> {{.GeneratedAt}}<br/>
+<strong>Auditors:</strong> {{join .Auditors ", "}}<br/>
+<strong>Path:</strong> {{.AppPath}}{{if .RunID}}<br/>
+<strong>Run ID:</strong> {{.RunID}}{{end}}</p>
+<h2>Summary</h2>
+<table><tbody>
+<tr><th>Auditor</th><th>Critical</th><th>High</th><th>Moderate</th><th>Low</th><th>Info</th><th>Total</th></tr>
+{{range .Sections}}<tr><td>{{.AuditorType}}{{if .ToolVersion}} ({{.ToolVersion}}){{end}}</td><td>{{.Summary.Critical}}</td><td>{{.Summary.High}}</td><td>{{.Summary.Moderate}}</td><td>{{.Summary.Low}}</td><td>{{.Summary.Info}}</td><td>{{.Summary.Total}}</td></tr>
+{{end}}{{if gt (len .Sections) 1}}<tr><th>Total</th><th>{{.Summary.Critical}}</th><th>{{.Summary.High}}</th><th>{{.Summary.Moderate}}</th><th>{{.Summary.Low}}</th><th>{{.Summary.Info}}</th><th>{{.Summary.Total}}</th></tr>
+{{end}}</tbody></table>
+{{range .Failures}}<ac:structured-macro ac:name="warning"><ac:rich-text-body><p><strong>{{.AuditorType}} failed ({{.Kind}}):</strong> {{.Message}}{{if .Hint}}<br/>{{.Hint}}{{end}}</p></ac:rich-text-body></ac:structured-macro>
+{{end}}{{range .Sections}}<h2>{{.AuditorType}}</h2>
+{{if .Questionable}}<ac:structured-macro ac:name="note"><ac:rich-text-body><p><strong>Questionable result:</strong> {{.Questionable}}. Findings may be missing; run the audit by hand to confirm.</p></ac:rich-text-body></ac:structured-macro>
+{{end}}{{if eq .Summary.Total 0}}<p>No vulnerabilities found.</p>
+{{else}}<table><tbody>
+<tr><th>Severity</th><th>Package</th><th>Advisory</th><th>Versions</th><th>First seen</th></tr>
+{{range .Vulnerabilities}}<tr><td><ac:structured-macro ac:name="status"><ac:parameter ac:name="colour">{{colour .Severity}}</ac:parameter><ac:parameter ac:name="title">{{upper .Severity}}</ac:parameter></ac:structured-macro></td><td>{{.PackageName}}</td><td>{{if .URL}}<a href="{{.URL}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}{{if .CVEID}} ({{.CVEID}}){{end}}{{if .Recommendation}}<br/><strong>Recommendation:</strong> {{.Recommendation}}{{end}}{{if .FixSnippet}}<pre>{{.FixSnippet}}</pre>{{end}}</td><td>{{default "Unknown" .VulnerableVersions}}<br/>fixed in {{default "Unknown" .PatchedVersions}}</td><td>{{default "-" (firstSeen .)}}</td></tr>
+{{end}}</tbody></table>
+{{end}}{{with .AIAnalysis}}<h3>AI Analysis</h3>
+<p>{{.Summary}}</p>
+{{if .Priority}}<p><strong>Recommended fix order:</strong> {{join .Priority ", "}}</p>
+{{end}}{{if .Remediation}}<pre>{{range .Remediation}}{{.}}
+{{end}}</pre>
+{{end}}{{if .RiskAssessment}}<p><strong>Risk assessment:</strong> {{.RiskAssessment}}</p>
+{{end}}{{with .Provenance}}<p><em>Analysis by {{.String}}</em></p>
+{{end}}{{end}}{{end}}`))
+
+// GenerateConfluencePage creates the body of an app's Confluence page from its combined
+// report, in Confluence's storage format
+func GenerateConfluencePage(report *models.CombinedAppReport) ([]byte, error) {
+	// Ages are relative to the report, not to when the page is read
+	tmpl, err := confluenceTemplate.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone template: %w", err)
+	}
+	generatedAt := report.GeneratedAt
+	tmpl.Funcs(map[string]any{
+		"firstSeen": func(v models.Vulnerability) string { return v.FirstSeenLabel(generatedAt) },
+	})
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newCombinedData(report)); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
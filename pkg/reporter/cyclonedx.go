@@ -0,0 +1,202 @@
+package reporter
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// cycloneDXBOM is a CycloneDX 1.5 JSON BOM
+type cycloneDXBOM struct {
+	BOMFormat       string                   `json:"bomFormat"`
+	SpecVersion     string                   `json:"specVersion"`
+	SerialNumber    string                   `json:"serialNumber"`
+	Version         int                      `json:"version"`
+	Metadata        cycloneDXMetadata        `json:"metadata"`
+	Components      []cycloneDXComponent     `json:"components"`
+	Dependencies    []cycloneDXDependency    `json:"dependencies"`
+	Vulnerabilities []cycloneDXVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Type     string             `json:"type"`
+	BOMRef   string             `json:"bom-ref,omitempty"`
+	Group    string             `json:"group,omitempty"`
+	Name     string             `json:"name"`
+	Version  string             `json:"version,omitempty"`
+	Scope    string             `json:"scope,omitempty"`
+	Licenses []cycloneDXLicense `json:"licenses,omitempty"`
+	PURL     string             `json:"purl,omitempty"`
+}
+
+// cycloneDXLicense is either an SPDX expression or a license known by its name only
+type cycloneDXLicense struct {
+	Expression string                 `json:"expression,omitempty"`
+	License    *cycloneDXNamedLicense `json:"license,omitempty"`
+}
+
+type cycloneDXNamedLicense struct {
+	Name string `json:"name"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// GenerateCycloneDX creates a CycloneDX 1.5 JSON SBOM of an app from its dependency
+// inventory, e.g. for Dependency-Track. The app, at appVersion, is the BOM's component:
+// it depends on its direct dependencies, and packages depend on the packages their
+// lockfile entries require. Dev dependencies are optional components.
+func GenerateCycloneDX(appName, appVersion string, deps []models.Dependency, toolVersion string, created time.Time) ([]byte, error) {
+	bom, _, err := newCycloneDXBOM(appName, appVersion, deps, toolVersion, created)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(bom, "", "  ")
+}
+
+// cycloneDXAppRef is the reference of the app, the BOM's component
+const cycloneDXAppRef = "application"
+
+// newCycloneDXBOM builds the CycloneDX BOM of an app, with the references of its
+// components by package name
+func newCycloneDXBOM(appName, appVersion string, deps []models.Dependency, toolVersion string, created time.Time) (cycloneDXBOM, map[string][]string, error) {
+	const appRef = cycloneDXAppRef
+
+	serial, err := uuidV4()
+	if err != nil {
+		return cycloneDXBOM{}, nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	bom := cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + serial,
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: created.UTC().Format("2006-01-02T15:04:05Z"),
+			Tools: cycloneDXTools{Components: []cycloneDXComponent{{
+				Type:    "application",
+				Name:    "audit-checks",
+				Version: toolVersion,
+			}}},
+			Component: cycloneDXComponent{
+				Type:    "application",
+				BOMRef:  appRef,
+				Name:    appName,
+				Version: appVersion,
+			},
+		},
+		Components:   []cycloneDXComponent{},
+		Dependencies: []cycloneDXDependency{},
+	}
+
+	// References by ecosystem and name@version, for the dependencies between packages
+	refs := make(map[string]string, len(deps))
+	refsByName := make(map[string][]string)
+	used := map[string]bool{appRef: true}
+	for _, dep := range deps {
+		key := dep.Ecosystem + "\x00" + dep.Name + "@" + dep.Version
+		if _, ok := refs[key]; ok {
+			continue
+		}
+
+		purl := packageURL(dep)
+		ref := purl
+		if ref == "" {
+			ref = dep.Ecosystem + "/" + dep.Name + "@" + dep.Version
+		}
+		for base, n := ref, 2; used[ref]; n++ {
+			ref = fmt.Sprintf("%s#%d", base, n)
+		}
+		used[ref] = true
+		refs[key] = ref
+		refsByName[dep.Name] = append(refsByName[dep.Name], ref)
+
+		component := cycloneDXComponent{
+			Type:     "library",
+			BOMRef:   ref,
+			Name:     dep.Name,
+			Version:  dep.Version,
+			Scope:    "required",
+			Licenses: cycloneDXLicenses(dep.License),
+			PURL:     purl,
+		}
+		// Scoped npm packages and composer packages are grouped by scope and vendor
+		if group, name, ok := strings.Cut(dep.Name, "/"); ok && name != "" {
+			component.Group, component.Name = group, name
+		}
+		if dep.Scope == models.DependencyScopeDev {
+			component.Scope = "optional"
+		}
+		bom.Components = append(bom.Components, component)
+	}
+
+	appDependency := cycloneDXDependency{Ref: appRef, DependsOn: []string{}}
+	dependencies := make(map[string]*cycloneDXDependency, len(refs))
+	for _, dep := range deps {
+		ref := refs[dep.Ecosystem+"\x00"+dep.Name+"@"+dep.Version]
+
+		if dep.Direct && !slices.Contains(appDependency.DependsOn, ref) {
+			appDependency.DependsOn = append(appDependency.DependsOn, ref)
+		}
+
+		dependency, ok := dependencies[ref]
+		if !ok {
+			dependency = &cycloneDXDependency{Ref: ref, DependsOn: []string{}}
+			dependencies[ref] = dependency
+		}
+		for _, required := range dep.DependsOn {
+			if related, ok := refs[dep.Ecosystem+"\x00"+required]; ok && !slices.Contains(dependency.DependsOn, related) {
+				dependency.DependsOn = append(dependency.DependsOn, related)
+			}
+		}
+	}
+
+	bom.Dependencies = append(bom.Dependencies, appDependency)
+	for _, component := range bom.Components {
+		bom.Dependencies = append(bom.Dependencies, *dependencies[component.BOMRef])
+	}
+
+	return bom, refsByName, nil
+}
+
+// cycloneDXLicenses returns the licenses of a dependency: its SPDX expression, or the
+// license as recorded when it is not one (e.g. "SEE LICENSE IN LICENSE.md")
+func cycloneDXLicenses(license string) []cycloneDXLicense {
+	license = strings.TrimSpace(license)
+	if license == "" {
+		return nil
+	}
+	if spdxLicense(license) != spdxNoAssertion {
+		return []cycloneDXLicense{{Expression: license}}
+	}
+	return []cycloneDXLicense{{License: &cycloneDXNamedLicense{Name: license}}}
+}
+
+// uuidV4 returns a random UUID
+func uuidV4() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16]), nil
+}
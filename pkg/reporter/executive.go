@@ -0,0 +1,280 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+// ExecutiveDir is the subdirectory of the report output directory for executive reports
+const ExecutiveDir = "executive"
+
+// executiveFuncs contains the functions shared by the executive report templates
+var executiveFuncs = map[string]any{
+	"title": strings.Title,
+	"date":  func(t time.Time) string { return t.Local().Format("2006-01-02") },
+	"delta": func(current, previous int) string {
+		switch d := current - previous; {
+		case d > 0:
+			return fmt.Sprintf("+%d", d)
+		case d < 0:
+			return fmt.Sprintf("%d", d)
+		default:
+			return "±0"
+		}
+	},
+	"percent": func(c models.SLACompliance) string { return fmt.Sprintf("%.0f%%", c.Percent()) },
+	"join":    strings.Join,
+	"default": defaultValue,
+	"add":     func(a, b int) int { return a + b },
+}
+
+// executiveMarkdownTemplate is the Markdown executive report
+var executiveMarkdownTemplate = template.Must(template.New("executive").Funcs(executiveFuncs).Parse(
+	`# Security Executive Report
+
+**Period:** {{date .PeriodStart}} to {{date .PeriodEnd}}
+**Generated:** {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}
+**Apps:** {{.AppsAudited}} of {{.TotalApps}} audited ({{.Audits}} audits)
+
+---
+
+## Open Findings
+
+| Severity | Now | Previous | Change |
+|----------|-----|----------|--------|
+| Critical | {{.Current.Critical}} | {{.Previous.Critical}} | {{delta .Current.Critical .Previous.Critical}} |
+| High | {{.Current.High}} | {{.Previous.High}} | {{delta .Current.High .Previous.High}} |
+| Moderate | {{.Current.Moderate}} | {{.Previous.Moderate}} | {{delta .Current.Moderate .Previous.Moderate}} |
+| Low | {{.Current.Low}} | {{.Previous.Low}} | {{delta .Current.Low .Previous.Low}} |
+| Info | {{.Current.Info}} | {{.Previous.Info}} | {{delta .Current.Info .Previous.Info}} |
+| **Total** | **{{.Current.Total}}** | **{{.Previous.Total}}** | **{{delta .Current.Total .Previous.Total}}** |
+
+## SLA Compliance
+
+| Severity | SLA | Open | Within SLA | Overdue | Compliance |
+|----------|-----|------|------------|---------|------------|
+{{range .SLA}}| {{title .Severity}} | {{.SLADays}} days | {{.Open}} | {{.WithinSLA}} | {{.Overdue}} | {{percent .}} |
+{{end}}{{if .Overdue}}
+### Most Overdue
+
+| App | Package | Advisory | Severity | Open for | SLA |
+|-----|---------|----------|----------|----------|-----|
+{{range .Overdue}}| {{.AppName}} | {{.PackageName}} | {{default .Title .CVEID}} | {{title .Severity}} | {{.AgeDays}} days | {{.SLADays}} days |
+{{end}}{{end}}
+## Top Offenders
+
+{{if .TopOffenders}}| App | Critical | High | Total | Change |
+|-----|----------|------|-------|--------|
+{{range .TopOffenders}}| {{.AppName}} | {{.Current.Critical}} | {{.Current.High}} | {{.Current.Total}} | {{delta .Current.Total .Previous.Total}} |
+{{end}}{{else}}No app has open findings.
+{{end}}
+## Notable New Advisories
+
+{{if .NewAdvisories}}| Advisory | Package | Severity | Apps | First seen |
+|----------|---------|----------|------|------------|
+{{range .NewAdvisories}}| {{if .URL}}[{{default .Title .ID}}]({{.URL}}){{else}}{{default .Title .ID}}{{end}} | {{.PackageName}} | {{title .Severity}} | {{join .Apps ", "}} | {{date .FirstSeen}} |
+{{end}}{{else}}No new critical or high advisories this period.
+{{end}}{{with .AIAnalysis}}
+## AI Fleet Analysis
+
+{{.Summary}}
+{{if .Themes}}
+**Themes:**
+
+{{range .Themes}}- {{.}}
+{{end}}{{end}}{{if .SystemicPackages}}
+**Systemic packages:**
+
+| Package | Severity | Apps | Note |
+|---------|----------|------|------|
+{{range .SystemicPackages}}| {{.Package}} | {{title .Severity}} | {{len .Apps}} ({{join .Apps ", "}}) | {{.Note}} |
+{{end}}{{end}}{{if .Campaigns}}
+**Recommended campaigns:**
+
+{{range $i, $c := .Campaigns}}{{add $i 1}}. {{$c}}
+{{end}}{{end}}{{with .Provenance}}
+*Analysis by {{.}}*
+{{end}}{{end}}
+---
+
+*Generated by Audit Checks*
+`))
+
+// executiveHTMLTemplate is the HTML executive report, also used as the email body
+var executiveHTMLTemplate = htmltemplate.Must(htmltemplate.New("executive").Funcs(executiveFuncs).Parse(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <title>Security Executive Report</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 800px; margin: 0 auto; padding: 20px; }
+        .header { background: #f8f9fa; padding: 20px; border-radius: 8px; margin-bottom: 20px; }
+        .header h1 { margin: 0 0 10px 0; color: #212529; }
+        table { width: 100%; border-collapse: collapse; margin: 20px 0; }
+        th, td { padding: 8px 12px; text-align: left; border-bottom: 1px solid #dee2e6; }
+        th { background: #f8f9fa; }
+        .overdue { color: #dc3545; font-weight: bold; }
+        .provenance { color: #6c757d; font-size: 12px; }
+        .footer { text-align: center; color: #6c757d; font-size: 12px; margin-top: 30px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Security Executive Report</h1>
+            <p><strong>Period:</strong> {{date .PeriodStart}} to {{date .PeriodEnd}}</p>
+            <p><strong>Apps:</strong> {{.AppsAudited}} of {{.TotalApps}} audited ({{.Audits}} audits)</p>
+        </div>
+
+        <h2>Open Findings</h2>
+        <table>
+            <tr><th>Severity</th><th>Now</th><th>Previous</th><th>Change</th></tr>
+            <tr><td>Critical</td><td>{{.Current.Critical}}</td><td>{{.Previous.Critical}}</td><td>{{delta .Current.Critical .Previous.Critical}}</td></tr>
+            <tr><td>High</td><td>{{.Current.High}}</td><td>{{.Previous.High}}</td><td>{{delta .Current.High .Previous.High}}</td></tr>
+            <tr><td>Moderate</td><td>{{.Current.Moderate}}</td><td>{{.Previous.Moderate}}</td><td>{{delta .Current.Moderate .Previous.Moderate}}</td></tr>
+            <tr><td>Low</td><td>{{.Current.Low}}</td><td>{{.Previous.Low}}</td><td>{{delta .Current.Low .Previous.Low}}</td></tr>
+            <tr><td>Info</td><td>{{.Current.Info}}</td><td>{{.Previous.Info}}</td><td>{{delta .Current.Info .Previous.Info}}</td></tr>
+            <tr><th>Total</th><th>{{.Current.Total}}</th><th>{{.Previous.Total}}</th><th>{{delta .Current.Total .Previous.Total}}</th></tr>
+        </table>
+
+        <h2>SLA Compliance</h2>
+        <table>
+            <tr><th>Severity</th><th>SLA</th><th>Open</th><th>Within SLA</th><th>Overdue</th><th>Compliance</th></tr>
+            {{range .SLA}}
+            <tr><td>{{title .Severity}}</td><td>{{.SLADays}} days</td><td>{{.Open}}</td><td>{{.WithinSLA}}</td><td{{if gt .Overdue 0}} class="overdue"{{end}}>{{.Overdue}}</td><td>{{percent .}}</td></tr>
+            {{end}}
+        </table>
+
+        {{if .Overdue}}
+        <h3>Most Overdue</h3>
+        <table>
+            <tr><th>App</th><th>Package</th><th>Advisory</th><th>Severity</th><th>Open for</th><th>SLA</th></tr>
+            {{range .Overdue}}
+            <tr><td>{{.AppName}}</td><td>{{.PackageName}}</td><td>{{default .Title .CVEID}}</td><td>{{title .Severity}}</td><td class="overdue">{{.AgeDays}} days</td><td>{{.SLADays}} days</td></tr>
+            {{end}}
+        </table>
+        {{end}}
+
+        <h2>Top Offenders</h2>
+        {{if .TopOffenders}}
+        <table>
+            <tr><th>App</th><th>Critical</th><th>High</th><th>Total</th><th>Change</th></tr>
+            {{range .TopOffenders}}
+            <tr><td>{{.AppName}}</td><td>{{.Current.Critical}}</td><td>{{.Current.High}}</td><td>{{.Current.Total}}</td><td>{{delta .Current.Total .Previous.Total}}</td></tr>
+            {{end}}
+        </table>
+        {{else}}
+        <p>No app has open findings.</p>
+        {{end}}
+
+        <h2>Notable New Advisories</h2>
+        {{if .NewAdvisories}}
+        <table>
+            <tr><th>Advisory</th><th>Package</th><th>Severity</th><th>Apps</th><th>First seen</th></tr>
+            {{range .NewAdvisories}}
+            <tr><td>{{if .URL}}<a href="{{.URL}}">{{default .Title .ID}}</a>{{else}}{{default .Title .ID}}{{end}}</td><td>{{.PackageName}}</td><td>{{title .Severity}}</td><td>{{join .Apps ", "}}</td><td>{{date .FirstSeen}}</td></tr>
+            {{end}}
+        </table>
+        {{else}}
+        <p>No new critical or high advisories this period.</p>
+        {{end}}
+
+        {{with .AIAnalysis}}
+        <h2>AI Fleet Analysis</h2>
+        <p>{{.Summary}}</p>
+        {{if .Themes}}
+        <h3>Themes</h3>
+        <ul>
+            {{range .Themes}}<li>{{.}}</li>{{end}}
+        </ul>
+        {{end}}
+        {{if .SystemicPackages}}
+        <h3>Systemic Packages</h3>
+        <table>
+            <tr><th>Package</th><th>Severity</th><th>Apps</th><th>Note</th></tr>
+            {{range .SystemicPackages}}
+            <tr><td>{{.Package}}</td><td>{{title .Severity}}</td><td>{{len .Apps}} ({{join .Apps ", "}})</td><td>{{.Note}}</td></tr>
+            {{end}}
+        </table>
+        {{end}}
+        {{if .Campaigns}}
+        <h3>Recommended Campaigns</h3>
+        <ol>
+            {{range .Campaigns}}<li>{{.}}</li>{{end}}
+        </ol>
+        {{end}}
+        {{with .Provenance}}<p class="provenance">Analysis by {{.}}</p>{{end}}
+        {{end}}
+
+        <div class="footer">
+            <p>Generated by Audit Checks on {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}</p>
+        </div>
+    </div>
+</body>
+</html>
+`))
+
+// GenerateExecutiveMarkdown renders the executive report as Markdown
+func GenerateExecutiveMarkdown(report *models.ExecutiveReport) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := executiveMarkdownTemplate.Execute(&buf, report); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// GenerateExecutiveHTML renders the executive report as HTML
+func GenerateExecutiveHTML(report *models.ExecutiveReport) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := executiveHTMLTemplate.Execute(&buf, report); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SaveExecutiveReport writes the executive report as Markdown and HTML to the
+// executive subdirectory of the output directory. Returns the file paths.
+// Format: executive-{periodEnd}{extension}, so a report regenerated on the same day replaces the earlier one.
+func (m *Manager) SaveExecutiveReport(report *models.ExecutiveReport) ([]string, error) {
+	dir := filepath.Join(m.outputDir, ExecutiveDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create executive report directory: %w", err)
+	}
+
+	base := "executive-" + report.PeriodEnd.Local().Format("2006-01-02")
+
+	var filePaths []string
+	for _, output := range []struct {
+		extension string
+		generate  func(*models.ExecutiveReport) ([]byte, error)
+	}{
+		{".md", GenerateExecutiveMarkdown},
+		{".html", GenerateExecutiveHTML},
+	} {
+		content, err := output.generate(report)
+		if err != nil {
+			return filePaths, fmt.Errorf("failed to generate executive report: %w", err)
+		}
+
+		filePath := filepath.Join(dir, base+output.extension)
+		if err := os.WriteFile(filePath, content, 0644); err != nil {
+			return filePaths, fmt.Errorf("failed to write executive report file: %w", err)
+		}
+		filePaths = append(filePaths, filePath)
+
+		zap.S().Infof("Executive report generated file=%s", filePath)
+	}
+
+	return filePaths, nil
+}
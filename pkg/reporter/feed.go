@@ -0,0 +1,161 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// FeedFile is the file name of the feed in the report output directory
+const FeedFile = "feed.atom"
+
+// atomFeed is an Atom (RFC 4287) feed
+type atomFeed struct {
+	XMLName   xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Updated   string      `xml:"updated"`
+	Links     []atomLink  `xml:"link,omitempty"`
+	Author    atomAuthor  `xml:"author"`
+	Generator string      `xml:"generator"`
+	Entries   []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Summary string      `xml:"summary"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// feedEntryTemplate is the content of a feed entry: the list of the new findings
+var feedEntryTemplate = htmltemplate.Must(htmltemplate.New("feed").Funcs(map[string]any{
+	"upper": strings.ToUpper,
+}).Parse(`<ul>{{range .}}
+<li><strong>{{upper .Severity}}</strong> {{.PackageName}}: {{if .URL}}<a href="{{.URL}}">{{.Title}}</a>{{else}}{{.Title}}{{end}}{{if .CVEID}} ({{.CVEID}}){{end}} <em>{{.AuditorType}}</em></li>{{end}}
+</ul>`))
+
+// feedEntryTitle describes an entry's findings, e.g. "shop: 2 new findings (1 critical, 1 high)"
+func feedEntryTitle(entry models.FeedEntry) string {
+	noun := "findings"
+	if len(entry.Findings) == 1 {
+		noun = "finding"
+	}
+
+	var counts []string
+	for _, c := range []struct {
+		count    int
+		severity string
+	}{
+		{entry.Summary.Critical, models.SeverityCritical},
+		{entry.Summary.High, models.SeverityHigh},
+		{entry.Summary.Moderate, models.SeverityModerate},
+		{entry.Summary.Low, models.SeverityLow},
+		{entry.Summary.Info, models.SeverityInfo},
+	} {
+		if c.count > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", c.count, c.severity))
+		}
+	}
+
+	title := fmt.Sprintf("%s: %d new %s", entry.AppName, len(entry.Findings), noun)
+	if len(counts) > 0 {
+		title += " (" + strings.Join(counts, ", ") + ")"
+	}
+	return title
+}
+
+// GenerateFeed creates an Atom feed with an entry per run and app
+func GenerateFeed(feed *models.Feed) ([]byte, error) {
+	doc := atomFeed{
+		ID:        "urn:audit-checks:feed",
+		Title:     feed.Title,
+		Updated:   feed.UpdatedAt.UTC().Format(time.RFC3339),
+		Author:    atomAuthor{Name: "audit-checks"},
+		Generator: "audit-checks",
+		Entries:   []atomEntry{},
+	}
+	if feed.URL != "" {
+		doc.ID = feed.URL
+		doc.Links = []atomLink{{Rel: "self", Href: feed.URL}}
+	}
+
+	for _, entry := range feed.Entries {
+		var content bytes.Buffer
+		if err := feedEntryTemplate.Execute(&content, entry.Findings); err != nil {
+			return nil, fmt.Errorf("failed to execute template: %w", err)
+		}
+		title := feedEntryTitle(entry)
+		doc.Entries = append(doc.Entries, atomEntry{
+			ID:      "urn:audit-checks:run:" + entry.RunID + ":" + url.PathEscape(entry.AppName),
+			Title:   title,
+			Updated: entry.UpdatedAt.UTC().Format(time.RFC3339),
+			Summary: title,
+			Content: atomContent{Type: "html", Body: content.String()},
+		})
+	}
+
+	data, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal feed: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// SaveFeed writes the Atom feed to path, or to feed.atom in the output directory when
+// empty. The file is replaced at once, so readers polling it never get half a feed.
+func (m *Manager) SaveFeed(feed *models.Feed, path string) error {
+	if path == "" {
+		path = filepath.Join(m.outputDir, FeedFile)
+	}
+	data, err := GenerateFeed(feed)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create feed directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".feed-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write feed: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write feed: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write feed: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write feed: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write feed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,242 @@
+package reporter
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// GitLabReportVersion is the version of the GitLab security report schema the
+// Dependency Scanning report follows
+const GitLabReportVersion = "15.0.7"
+
+// gitlabTimeFormat is the timestamp format of the report's scan, in UTC without zone
+const gitlabTimeFormat = "2006-01-02T15:04:05"
+
+// gitlabDependencyFiles are the files an auditor's findings are located in, most
+// specific first: the first one found in the app is used, the first one otherwise.
+// Auditors left out (system packages, secrets) are not dependency scanning.
+var gitlabDependencyFiles = map[string][]string{
+	"npm":         {"package-lock.json", "npm-shrinkwrap.json", "yarn.lock"},
+	"pnpm":        {"pnpm-lock.yaml"},
+	"composer":    {"composer.lock"},
+	"go":          {"go.mod"},
+	"cargo":       {"Cargo.lock"},
+	"java":        {"gradle.lockfile", "pom.xml", "build.gradle.kts", "build.gradle"},
+	"dotnet":      {"packages.lock.json"},
+	"pub":         {"pubspec.lock"},
+	"terraform":   {".terraform.lock.hcl"},
+	"helm":        {"Chart.yaml"},
+	"osv":         {"package-lock.json", "yarn.lock", "composer.lock"},
+	"policy":      {"package.json", "composer.json"},
+	"outdated":    {"package-lock.json", "composer.lock"},
+	"supplychain": {"package-lock.json", "yarn.lock", "pnpm-lock.yaml"},
+	"php":         {"composer.json"},
+	"node":        {"package.json"},
+}
+
+// gitlabSeverities maps vulnerability severities to the report's
+var gitlabSeverities = map[string]string{
+	models.SeverityCritical: "Critical",
+	models.SeverityHigh:     "High",
+	models.SeverityModerate: "Medium",
+	models.SeverityLow:      "Low",
+	models.SeverityInfo:     "Info",
+}
+
+type gitlabReport struct {
+	Version         string                `json:"version"`
+	Scan            gitlabScan            `json:"scan"`
+	Vulnerabilities []gitlabVulnerability `json:"vulnerabilities"`
+}
+
+type gitlabScan struct {
+	Analyzer  gitlabTool `json:"analyzer"`
+	Scanner   gitlabTool `json:"scanner"`
+	Type      string     `json:"type"`
+	StartTime string     `json:"start_time"`
+	EndTime   string     `json:"end_time"`
+	Status    string     `json:"status"`
+}
+
+type gitlabTool struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	Version string       `json:"version"`
+	Vendor  gitlabVendor `json:"vendor"`
+}
+
+type gitlabVendor struct {
+	Name string `json:"name"`
+}
+
+type gitlabVulnerability struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description,omitempty"`
+	Severity    string             `json:"severity"`
+	Solution    string             `json:"solution,omitempty"`
+	Identifiers []gitlabIdentifier `json:"identifiers"`
+	Links       []gitlabLink       `json:"links,omitempty"`
+	Location    gitlabLocation     `json:"location"`
+}
+
+type gitlabIdentifier struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	URL   string `json:"url,omitempty"`
+}
+
+type gitlabLink struct {
+	URL string `json:"url"`
+}
+
+type gitlabLocation struct {
+	File       string           `json:"file"`
+	Dependency gitlabDependency `json:"dependency"`
+}
+
+type gitlabDependency struct {
+	Package gitlabPackage `json:"package"`
+	Version string        `json:"version,omitempty"`
+}
+
+type gitlabPackage struct {
+	Name string `json:"name"`
+}
+
+// GenerateGitLabDependencyScanning creates a GitLab Dependency Scanning report of audit
+// results, which GitLab shows in the pipeline's Security tab, the merge request widget
+// and the Vulnerability Report when a CI job uploads it as an
+// artifacts:reports:dependency_scanning artifact. Findings are located in the lockfile
+// of their auditor, relative to the working directory (the project checkout in CI).
+func GenerateGitLabDependencyScanning(results []*models.AuditResult, toolVersion string, start, end time.Time) ([]byte, error) {
+	tool := gitlabTool{
+		ID:      "audit-checks",
+		Name:    "audit-checks",
+		Version: toolVersion,
+		Vendor:  gitlabVendor{Name: "audit-checks"},
+	}
+	report := gitlabReport{
+		Version: GitLabReportVersion,
+		Scan: gitlabScan{
+			Analyzer:  tool,
+			Scanner:   tool,
+			Type:      "dependency_scanning",
+			StartTime: start.UTC().Format(gitlabTimeFormat),
+			EndTime:   end.UTC().Format(gitlabTimeFormat),
+			Status:    "success",
+		},
+		Vulnerabilities: []gitlabVulnerability{},
+	}
+
+	workDir, _ := os.Getwd()
+	seen := make(map[string]bool)
+
+	for _, result := range results {
+		candidates, ok := gitlabDependencyFiles[result.AuditorType]
+		if !ok || len(result.Vulnerabilities) == 0 {
+			continue
+		}
+		file := gitlabLocationFile(workDir, result.AppPath, candidates)
+
+		for _, v := range result.Vulnerabilities {
+			// The same finding reported twice for a file (e.g. by npm and osv) is one vulnerability
+			id := gitlabVulnerabilityID(file, v)
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			name := v.Title
+			if name == "" {
+				name = v.CVEID
+			}
+			vuln := gitlabVulnerability{
+				ID:          id,
+				Name:        fmt.Sprintf("%s: %s", v.PackageName, name),
+				Description: v.Description,
+				Severity:    gitlabSeverity(v.Severity),
+				Solution:    gitlabSolution(v),
+				Identifiers: gitlabIdentifiers(v),
+				Location: gitlabLocation{
+					File:       file,
+					Dependency: gitlabDependency{Package: gitlabPackage{Name: v.PackageName}},
+				},
+			}
+			if v.URL != "" {
+				vuln.Links = []gitlabLink{{URL: v.URL}}
+			}
+			report.Vulnerabilities = append(report.Vulnerabilities, vuln)
+		}
+	}
+
+	return json.MarshalIndent(report, "", "  ")
+}
+
+// gitlabLocationFile returns the dependency file of an app among candidates, relative
+// to workDir when the app is inside it
+func gitlabLocationFile(workDir, appPath string, candidates []string) string {
+	file := filepath.Join(appPath, candidates[0])
+	for _, candidate := range candidates {
+		if _, err := os.Stat(filepath.Join(appPath, candidate)); err == nil {
+			file = filepath.Join(appPath, candidate)
+			break
+		}
+	}
+
+	if filepath.IsAbs(file) && workDir != "" {
+		if rel, err := filepath.Rel(workDir, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+// gitlabVulnerabilityID returns a stable ID of a finding in a file, so GitLab tracks
+// it across pipelines
+func gitlabVulnerabilityID(file string, v models.Vulnerability) string {
+	sum := sha256.Sum256([]byte(file + "\x00" + v.FindingKey()))
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// gitlabSeverity returns the report severity of a vulnerability severity
+func gitlabSeverity(severity string) string {
+	if s, ok := gitlabSeverities[severity]; ok {
+		return s
+	}
+	return "Unknown"
+}
+
+// gitlabSolution returns how to fix a vulnerability
+func gitlabSolution(v models.Vulnerability) string {
+	if v.Recommendation != "" {
+		return v.Recommendation
+	}
+	if v.PatchedVersions != "" {
+		return fmt.Sprintf("Upgrade %s to %s", v.PackageName, v.PatchedVersions)
+	}
+	return ""
+}
+
+// gitlabIdentifiers returns the identifiers of a vulnerability: its CVE or GHSA, or an
+// audit-checks identifier built from its title
+func gitlabIdentifiers(v models.Vulnerability) []gitlabIdentifier {
+	id := v.CVEID
+	switch {
+	case strings.HasPrefix(id, "CVE-"):
+		return []gitlabIdentifier{{Type: "cve", Name: id, Value: id, URL: "https://nvd.nist.gov/vuln/detail/" + id}}
+	case strings.HasPrefix(id, "GHSA-"):
+		return []gitlabIdentifier{{Type: "ghsa", Name: id, Value: id, URL: "https://github.com/advisories/" + id}}
+	case id != "":
+		return []gitlabIdentifier{{Type: "audit-checks", Name: id, Value: id, URL: v.URL}}
+	}
+	return []gitlabIdentifier{{Type: "audit-checks", Name: v.Title, Value: v.PackageName + ":" + v.Title}}
+}
@@ -0,0 +1,157 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// HTMLReporter generates standalone HTML reports, readable in any browser or attached
+// to a chat message: an auditor's report, or an app's combined report with a section
+// per auditor
+type HTMLReporter struct{}
+
+// NewHTMLReporter creates a new HTMLReporter
+func NewHTMLReporter() *HTMLReporter {
+	return &HTMLReporter{}
+}
+
+// Format returns "html"
+func (r *HTMLReporter) Format() string {
+	return "html"
+}
+
+// Extension returns ".html"
+func (r *HTMLReporter) Extension() string {
+	return ".html"
+}
+
+// htmlTemplate is the layout of the HTML reports
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(map[string]any{
+	"title":   strings.Title,
+	"default": defaultValue,
+	"join":    strings.Join,
+	"add":     func(a, b int) int { return a + b },
+	// Replaced for each report, see GenerateCombined
+	"firstSeen": func(v models.Vulnerability) string { return "" },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>Security Audit Report: {{.AppName}}</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 1000px; margin: 0 auto; padding: 20px; }
+        .header { background: #f8f9fa; padding: 20px; border-radius: 8px; margin-bottom: 20px; }
+        .header h1 { margin: 0 0 10px 0; color: #212529; }
+        table { width: 100%; border-collapse: collapse; margin: 20px 0; }
+        th, td { padding: 8px 12px; text-align: left; border-bottom: 1px solid #dee2e6; vertical-align: top; }
+        th { background: #f8f9fa; }
+        .critical { color: #dc3545; font-weight: bold; }
+        .high { color: #fd7e14; font-weight: bold; }
+        .muted { color: #6c757d; }
+        .warning { background: #fff3cd; border-left: 4px solid #ffc107; padding: 10px 15px; margin: 15px 0; }
+        .failure { background: #f8d7da; border-left: 4px solid #dc3545; padding: 10px 15px; margin: 15px 0; }
+        pre { background: #f8f9fa; padding: 10px; border-radius: 4px; overflow-x: auto; }
+        .footer { text-align: center; color: #6c757d; font-size: 12px; margin-top: 30px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <div class="header">
+            <h1>Security Audit Report: {{.AppName}}</h1>
+            <p><strong>Generated:</strong> {{.GeneratedAt}}<br>
+            <strong>Auditors:</strong> {{join .Auditors ", "}}<br>
+            <strong>Path:</strong> {{.AppPath}}{{if .RunID}}<br>
+            <strong>Run ID:</strong> {{.RunID}}{{end}}</p>
+        </div>
+
+        <h2>Summary</h2>
+        <table>
+            <tr><th>Auditor</th><th>Critical</th><th>High</th><th>Moderate</th><th>Low</th><th>Info</th><th>Total</th></tr>
+            {{range .Sections}}
+            <tr><td>{{.AuditorType}}{{if .ToolVersion}} <span class="muted">({{.ToolVersion}})</span>{{end}}</td><td class="critical">{{.Summary.Critical}}</td><td class="high">{{.Summary.High}}</td><td>{{.Summary.Moderate}}</td><td>{{.Summary.Low}}</td><td>{{.Summary.Info}}</td><td>{{.Summary.Total}}</td></tr>
+            {{end}}
+            {{if gt (len .Sections) 1}}<tr><th>Total</th><th class="critical">{{.Summary.Critical}}</th><th class="high">{{.Summary.High}}</th><th>{{.Summary.Moderate}}</th><th>{{.Summary.Low}}</th><th>{{.Summary.Info}}</th><th>{{.Summary.Total}}</th></tr>{{end}}
+        </table>
+
+        {{range .Failures}}
+        <div class="failure"><strong>{{.AuditorType}} failed ({{.Kind}}):</strong> {{.Message}}{{if .Hint}}<br>{{.Hint}}{{end}}</div>
+        {{end}}
+
+        {{range .Sections}}
+        <h2>{{.AuditorType}}</h2>
+        {{if .Questionable}}<div class="warning"><strong>Questionable result:</strong> {{.Questionable}}. Findings may be missing; run the audit by hand to confirm.</div>{{end}}
+        {{if eq .Summary.Total 0}}
+        <p>{{if .Questionable}}No vulnerabilities parsed, but the result is questionable (see above).{{else}}No vulnerabilities found.{{end}}</p>
+        {{else}}
+        <table>
+            <tr><th>#</th><th>Severity</th><th>Package</th><th>Advisory</th><th>Versions</th><th>First seen</th></tr>
+            {{range $i, $v := .Vulnerabilities}}
+            <tr>
+                <td>{{add $i 1}}</td>
+                <td class="{{$v.Severity}}">{{title $v.Severity}}</td>
+                <td>{{$v.PackageName}}</td>
+                <td>{{if $v.URL}}<a href="{{$v.URL}}">{{$v.Title}}</a>{{else}}{{$v.Title}}{{end}}{{if $v.CVEID}} <span class="muted">{{$v.CVEID}}</span>{{end}}
+                    {{if $v.Description}}<br><span class="muted">{{$v.Description}}</span>{{end}}
+                    {{if $v.Recommendation}}<br><strong>Recommendation:</strong> {{$v.Recommendation}}{{end}}
+                    {{if $v.FixSnippet}}<pre>{{$v.FixSnippet}}</pre>{{end}}</td>
+                <td>{{default "Unknown" $v.VulnerableVersions}}<br><span class="muted">fixed in {{default "Unknown" $v.PatchedVersions}}</span></td>
+                <td>{{default "-" (firstSeen $v)}}</td>
+            </tr>
+            {{end}}
+        </table>
+        {{end}}
+        {{with .AIAnalysis}}
+        <h3>AI Analysis</h3>
+        <p>{{.Summary}}</p>
+        {{if .Priority}}<p><strong>Recommended fix order:</strong> {{join .Priority ", "}}</p>{{end}}
+        {{if .Remediation}}<pre>{{range .Remediation}}{{.}}
+{{end}}</pre>{{end}}
+        {{if .RiskAssessment}}<p><strong>Risk assessment:</strong> {{.RiskAssessment}}</p>{{end}}
+        {{with .Provenance}}<p class="muted"><em>Analysis by {{.String}}</em></p>{{end}}
+        {{end}}
+        {{end}}
+
+        <div class="footer">
+            <p>Generated by {{.GeneratedBy}}</p>
+        </div>
+    </div>
+</body>
+</html>
+`))
+
+// Generate creates an HTML report of an auditor's result
+func (r *HTMLReporter) Generate(report *models.Report) ([]byte, error) {
+	combined := &models.CombinedAppReport{
+		RunID:       report.AuditResult.RunID,
+		AppName:     report.AppName,
+		AppPath:     report.AppPath,
+		Reports:     []*models.Report{report},
+		GeneratedAt: report.GeneratedAt,
+	}
+	return r.GenerateCombined(combined)
+}
+
+// GenerateCombined creates an HTML report of an app with a section per auditor
+func (r *HTMLReporter) GenerateCombined(report *models.CombinedAppReport) ([]byte, error) {
+	// Ages are relative to the report, not to when it is read
+	tmpl, err := htmlTemplate.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone template: %w", err)
+	}
+	generatedAt := report.GeneratedAt
+	tmpl.Funcs(map[string]any{
+		"firstSeen": func(v models.Vulnerability) string { return v.FirstSeenLabel(generatedAt) },
+	})
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newCombinedData(report)); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -3,6 +3,7 @@ package reporter
 import (
 	"encoding/json"
 
+	"github.com/shadowbane/audit-checks/pkg/buildinfo"
 	"github.com/shadowbane/audit-checks/pkg/models"
 )
 
@@ -29,7 +30,10 @@ type jsonReport struct {
 	AppName         string             `json:"app_name"`
 	AppPath         string             `json:"app_path"`
 	AuditorType     string             `json:"auditor_type"`
+	ToolVersion     string             `json:"tool_version,omitempty"`
+	Questionable    string             `json:"questionable,omitempty"` // why the result may be a false negative
 	GeneratedAt     string             `json:"generated_at"`
+	GeneratedBy     string             `json:"generated_by"` // the audit-checks build, e.g. "audit-checks v1.2.0 (3f2a9c1d0b7e)"
 	Summary         jsonSummary        `json:"summary"`
 	Vulnerabilities []jsonVuln         `json:"vulnerabilities"`
 	AIAnalysis      *models.AIAnalysis `json:"ai_analysis,omitempty"`
@@ -41,6 +45,7 @@ type jsonSummary struct {
 	High     int `json:"high"`
 	Moderate int `json:"moderate"`
 	Low      int `json:"low"`
+	Info     int `json:"info"`
 }
 
 type jsonVuln struct {
@@ -53,21 +58,32 @@ type jsonVuln struct {
 	VulnerableVersions string `json:"vulnerable_versions,omitempty"`
 	PatchedVersions    string `json:"patched_versions,omitempty"`
 	URL                string `json:"url,omitempty"`
+	FixSnippet         string `json:"fix_snippet,omitempty"`
+	FirstSeenAt        string `json:"first_seen_at,omitempty"` // first audit of the app and auditor that reported it
 }
 
 // Generate creates a JSON report
 func (r *JSONReporter) Generate(report *models.Report) ([]byte, error) {
+	return json.MarshalIndent(newJSONReport(report), "", "  ")
+}
+
+// newJSONReport converts an auditor's report to its JSON output
+func newJSONReport(report *models.Report) jsonReport {
 	output := jsonReport{
-		AppName:     report.AppName,
-		AppPath:     report.AppPath,
-		AuditorType: report.AuditorType,
-		GeneratedAt: report.GeneratedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		AppName:      report.AppName,
+		AppPath:      report.AppPath,
+		AuditorType:  report.AuditorType,
+		ToolVersion:  report.AuditResult.ToolVersion,
+		Questionable: report.AuditResult.Questionable,
+		GeneratedAt:  report.GeneratedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		GeneratedBy:  buildinfo.Get().String(),
 		Summary: jsonSummary{
 			Total:    report.AuditResult.TotalVulnerabilities,
 			Critical: report.AuditResult.CriticalCount,
 			High:     report.AuditResult.HighCount,
 			Moderate: report.AuditResult.ModerateCount,
 			Low:      report.AuditResult.LowCount,
+			Info:     report.AuditResult.InfoCount,
 		},
 		Vulnerabilities: make([]jsonVuln, 0, len(report.Vulnerabilities)),
 		AIAnalysis:      report.AIAnalysis,
@@ -84,15 +100,66 @@ func (r *JSONReporter) Generate(report *models.Report) ([]byte, error) {
 			VulnerableVersions: v.VulnerableVersions,
 			PatchedVersions:    v.PatchedVersions,
 			URL:                v.URL,
+			FixSnippet:         v.FixSnippet,
+			FirstSeenAt:        firstSeenAt(v),
 		})
 	}
 
+	return output
+}
+
+// jsonCombinedReport is the structure for the combined JSON output of an app
+type jsonCombinedReport struct {
+	AppName     string                `json:"app_name"`
+	AppPath     string                `json:"app_path"`
+	RunID       string                `json:"run_id,omitempty"`
+	GeneratedAt string                `json:"generated_at"`
+	GeneratedBy string                `json:"generated_by"`
+	Summary     jsonSummary           `json:"summary"` // of every auditor
+	Auditors    []jsonReport          `json:"auditors"`
+	Failures    []models.AuditFailure `json:"failures,omitempty"`
+}
+
+// GenerateCombined creates a JSON report of an app with the report of each auditor
+func (r *JSONReporter) GenerateCombined(report *models.CombinedAppReport) ([]byte, error) {
+	summary := report.GetCombinedSummary()
+	output := jsonCombinedReport{
+		AppName:     report.AppName,
+		AppPath:     report.AppPath,
+		RunID:       report.RunID,
+		GeneratedAt: report.GeneratedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		GeneratedBy: buildinfo.Get().String(),
+		Summary: jsonSummary{
+			Total:    summary.Total,
+			Critical: summary.Critical,
+			High:     summary.High,
+			Moderate: summary.Moderate,
+			Low:      summary.Low,
+			Info:     summary.Info,
+		},
+		Auditors: make([]jsonReport, 0, len(report.Reports)),
+		Failures: report.Failures,
+	}
+
+	for _, auditReport := range report.Reports {
+		output.Auditors = append(output.Auditors, newJSONReport(auditReport))
+	}
+
 	return json.MarshalIndent(output, "", "  ")
 }
 
+// firstSeenAt formats when a vulnerability was first seen; empty when unknown
+func firstSeenAt(v models.Vulnerability) string {
+	if v.FirstSeenAt == nil {
+		return ""
+	}
+	return v.FirstSeenAt.UTC().Format("2006-01-02T15:04:05Z")
+}
+
 // jsonSummaryReport is the structure for summary JSON output
 type jsonSummaryReport struct {
 	GeneratedAt          string           `json:"generated_at"`
+	GeneratedBy          string           `json:"generated_by"`
 	TotalApps            int              `json:"total_apps"`
 	AppsWithVulns        int              `json:"apps_with_vulnerabilities"`
 	TotalVulnerabilities int              `json:"total_vulnerabilities"`
@@ -103,6 +170,7 @@ type jsonSummaryReport struct {
 type jsonAppSummary struct {
 	AppName     string      `json:"app_name"`
 	AuditorType string      `json:"auditor_type"`
+	ToolVersion string      `json:"tool_version,omitempty"`
 	Summary     jsonSummary `json:"summary"`
 }
 
@@ -110,6 +178,7 @@ type jsonAppSummary struct {
 func (r *JSONReporter) GenerateSummary(summary *models.AuditSummary) ([]byte, error) {
 	output := jsonSummaryReport{
 		GeneratedAt:          summary.GeneratedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		GeneratedBy:          buildinfo.Get().String(),
 		TotalApps:            summary.TotalApps,
 		AppsWithVulns:        summary.AppsWithVulns,
 		TotalVulnerabilities: summary.TotalVulnerabilities,
@@ -119,6 +188,7 @@ func (r *JSONReporter) GenerateSummary(summary *models.AuditSummary) ([]byte, er
 			High:     summary.HighCount,
 			Moderate: summary.ModerateCount,
 			Low:      summary.LowCount,
+			Info:     summary.InfoCount,
 		},
 		Apps: make([]jsonAppSummary, 0, len(summary.Results)),
 	}
@@ -127,12 +197,14 @@ func (r *JSONReporter) GenerateSummary(summary *models.AuditSummary) ([]byte, er
 		output.Apps = append(output.Apps, jsonAppSummary{
 			AppName:     result.AppName,
 			AuditorType: result.AuditorType,
+			ToolVersion: result.ToolVersion,
 			Summary: jsonSummary{
 				Total:    result.TotalVulnerabilities,
 				Critical: result.CriticalCount,
 				High:     result.HighCount,
 				Moderate: result.ModerateCount,
 				Low:      result.LowCount,
+				Info:     result.InfoCount,
 			},
 		})
 	}
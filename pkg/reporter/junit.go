@@ -0,0 +1,152 @@
+package reporter
+
+import (
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// junitCleanCase is the test case of an auditor without findings, so that CI shows the
+// audit as passed rather than empty
+const junitCleanCase = "no known vulnerabilities"
+
+// JUnitReporter generates JUnit XML reports, which CI systems (Jenkins, GitLab) show in
+// their test tabs: each package with findings is a test suite and each finding a failed
+// test case
+type JUnitReporter struct{}
+
+// NewJUnitReporter creates a new JUnitReporter
+func NewJUnitReporter() *JUnitReporter {
+	return &JUnitReporter{}
+}
+
+// Format returns "junit"
+func (r *JUnitReporter) Format() string {
+	return "junit"
+}
+
+// Extension returns ".junit.xml"
+func (r *JUnitReporter) Extension() string {
+	return ".junit.xml"
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// Generate creates a JUnit XML report of an auditor's result. Test suites are named
+// {app}.{auditor}.{package}, in package order.
+func (r *JUnitReporter) Generate(report *models.Report) ([]byte, error) {
+	prefix := report.AppName + "." + report.AuditorType
+	timestamp := report.GeneratedAt.UTC().Format("2006-01-02T15:04:05")
+
+	output := junitTestSuites{
+		Name: fmt.Sprintf("audit-checks %s (%s)", report.AppName, report.AuditorType),
+	}
+
+	byPackage := make(map[string]int)
+	for _, v := range report.Vulnerabilities {
+		i, ok := byPackage[v.PackageName]
+		if !ok {
+			i = len(output.Suites)
+			byPackage[v.PackageName] = i
+			output.Suites = append(output.Suites, junitTestSuite{
+				Name:      prefix + "." + v.PackageName,
+				Timestamp: timestamp,
+			})
+		}
+		suite := &output.Suites[i]
+
+		name := v.Title
+		if v.CVEID != "" {
+			name = v.CVEID + ": " + v.Title
+		}
+		suite.Cases = append(suite.Cases, junitTestCase{
+			Name:      name,
+			ClassName: suite.Name,
+			Failure: &junitFailure{
+				Message: fmt.Sprintf("%s severity vulnerability in %s", v.Severity, v.PackageName),
+				Type:    v.Severity,
+				Text:    junitFailureText(v),
+			},
+		})
+		suite.Tests++
+		suite.Failures++
+	}
+	slices.SortStableFunc(output.Suites, func(a, b junitTestSuite) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if len(output.Suites) == 0 {
+		output.Suites = []junitTestSuite{{
+			Name:      prefix,
+			Tests:     1,
+			Timestamp: timestamp,
+			Cases:     []junitTestCase{{Name: junitCleanCase, ClassName: prefix}},
+		}}
+	}
+
+	for _, suite := range output.Suites {
+		output.Tests += suite.Tests
+		output.Failures += suite.Failures
+	}
+
+	data, err := xml.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// junitFailureText returns the details of a finding shown by CI under its failed test
+func junitFailureText(v models.Vulnerability) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Package: %s\n", v.PackageName)
+	fmt.Fprintf(&sb, "Severity: %s\n", v.Severity)
+	if v.CVEID != "" {
+		fmt.Fprintf(&sb, "ID: %s\n", v.CVEID)
+	}
+	if v.VulnerableVersions != "" {
+		fmt.Fprintf(&sb, "Vulnerable versions: %s\n", v.VulnerableVersions)
+	}
+	if v.PatchedVersions != "" {
+		fmt.Fprintf(&sb, "Patched versions: %s\n", v.PatchedVersions)
+	}
+	if v.URL != "" {
+		fmt.Fprintf(&sb, "More info: %s\n", v.URL)
+	}
+	if v.Recommendation != "" {
+		fmt.Fprintf(&sb, "Recommendation: %s\n", v.Recommendation)
+	}
+	if v.Description != "" {
+		fmt.Fprintf(&sb, "\n%s\n", v.Description)
+	}
+	return sb.String()
+}
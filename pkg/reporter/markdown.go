@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strings"
 	"text/template"
+	"time"
 
+	"github.com/shadowbane/audit-checks/pkg/buildinfo"
 	"github.com/shadowbane/audit-checks/pkg/models"
 )
 
@@ -33,15 +35,19 @@ var templateFuncs = template.FuncMap{
 	"title":   strings.Title,
 	"default": defaultValue,
 	"add":     func(a, b int) int { return a + b },
+	"join":    strings.Join,
 }
 
 // markdownTemplateStr is the raw template string
 const markdownTemplateStr = `# Security Audit Report: {{.AppName}}
 
 **Generated:** {{.GeneratedAt}}
-**Auditor:** {{.AuditorType}}
-**Path:** {{.AppPath}}
-
+**Auditor:** {{.AuditorType}}{{if .ToolVersion}} ({{.ToolVersion}}){{end}}
+**Path:** {{.AppPath}}{{if .RunID}}
+**Run ID:** {{.RunID}}{{end}}
+{{if .Questionable}}
+> **Questionable result:** {{.Questionable}}. Findings may be missing; run the audit by hand to confirm.
+{{end}}
 ---
 
 ## Summary
@@ -52,37 +58,17 @@ const markdownTemplateStr = `# Security Audit Report: {{.AppName}}
 | High | {{.Summary.High}} |
 | Moderate | {{.Summary.Moderate}} |
 | Low | {{.Summary.Low}} |
+| Info | {{.Summary.Info}} |
 | **Total** | **{{.Summary.Total}}** |
 
 {{if eq .Summary.Total 0}}
-No vulnerabilities found.
+{{if .Questionable}}No vulnerabilities parsed, but the result is questionable (see above).{{else}}No vulnerabilities found.{{end}}
 {{else}}
 ---
 
 ## Vulnerabilities
 
-{{range $i, $v := .Vulnerabilities}}
-### {{add $i 1}}. {{$v.PackageName}} - {{$v.Title}} ({{$v.Severity | title}})
-
-| Field | Value |
-|-------|-------|
-| **Severity** | {{$v.Severity | upper}} |
-| **CVE** | {{$v.CVEID | default "N/A"}} |
-| **Affected Versions** | {{$v.VulnerableVersions | default "Unknown"}} |
-| **Patched Versions** | {{$v.PatchedVersions | default "Unknown"}} |
-{{if $v.URL}}| **Reference** | [Link]({{$v.URL}}) |{{end}}
-
-{{if $v.Description}}
-**Description:** {{$v.Description}}
-{{end}}
-
-{{if $v.Recommendation}}
-**Recommendation:** {{$v.Recommendation}}
-{{end}}
-
----
-
-{{end}}
+` + markdownVulnerabilitiesStr + `
 {{end}}
 
 {{if .AIAnalysis}}
@@ -115,17 +101,114 @@ No vulnerabilities found.
 
 {{.AIAnalysis.RiskAssessment}}
 {{end}}
+{{with .AIAnalysis.Provenance}}
+*Analysis by {{.}}*
+{{end}}
 {{end}}
 
 ---
 
-*Generated by Audit Checks*
+*Generated by {{.GeneratedBy}}*
+`
+
+// markdownVulnerabilitiesStr lists the vulnerabilities of an auditor's report, in the
+// per-auditor and combined templates
+const markdownVulnerabilitiesStr = `{{range $i, $v := .Vulnerabilities}}
+### {{add $i 1}}. {{$v.PackageName}} - {{$v.Title}} ({{$v.Severity | title}})
+
+| Field | Value |
+|-------|-------|
+| **Severity** | {{$v.Severity | upper}} |
+| **CVE** | {{$v.CVEID | default "N/A"}} |
+| **Affected Versions** | {{$v.VulnerableVersions | default "Unknown"}} |
+| **Patched Versions** | {{$v.PatchedVersions | default "Unknown"}} |
+{{with firstSeen $v}}| **First Seen** | {{.}} |
+{{end}}{{if $v.URL}}| **Reference** | [Link]({{$v.URL}}) |{{end}}
+
+{{if $v.Description}}
+**Description:** {{$v.Description}}
+{{end}}
+
+{{if $v.Recommendation}}
+**Recommendation:** {{$v.Recommendation}}
+{{end}}
+
+{{if $v.FixSnippet}}
+**Suggested fix:**
+
+` + "```json" + `
+{{$v.FixSnippet}}
+` + "```" + `
+{{end}}
+
+---
+
+{{end}}`
+
+// combinedTemplateStr is the template for an app's combined report, with a section
+// per auditor
+const combinedTemplateStr = `# Security Audit Report: {{.AppName}}
+
+**Generated:** {{.GeneratedAt}}
+**Auditors:** {{join .Auditors ", "}}
+**Path:** {{.AppPath}}{{if .RunID}}
+**Run ID:** {{.RunID}}{{end}}
+
+---
+
+## Summary
+
+| Auditor | Critical | High | Moderate | Low | Info | Total |
+|---------|----------|------|----------|-----|------|-------|
+{{range .Sections}}| {{.AuditorType}}{{if .ToolVersion}} ({{.ToolVersion}}){{end}} | {{.Summary.Critical}} | {{.Summary.High}} | {{.Summary.Moderate}} | {{.Summary.Low}} | {{.Summary.Info}} | {{.Summary.Total}} |
+{{end}}| **Total** | **{{.Summary.Critical}}** | **{{.Summary.High}}** | **{{.Summary.Moderate}}** | **{{.Summary.Low}}** | **{{.Summary.Info}}** | **{{.Summary.Total}}** |
+{{if .Failures}}
+## Failed Auditors
+
+{{range .Failures}}- **{{.AuditorType}}** ({{.Kind}}): {{.Message}}{{if .Hint}}. {{.Hint}}{{end}}
+{{end}}{{end}}
+{{range .Sections}}
+---
+
+## {{.AuditorType}}
+{{if .Questionable}}
+> **Questionable result:** {{.Questionable}}. Findings may be missing; run the audit by hand to confirm.
+{{end}}
+{{if eq .Summary.Total 0}}
+{{if .Questionable}}No vulnerabilities parsed, but the result is questionable (see above).{{else}}No vulnerabilities found.{{end}}
+{{else}}
+` + markdownVulnerabilitiesStr + `
+{{end}}
+{{if .AIAnalysis}}
+### AI Analysis
+
+{{.AIAnalysis.Summary}}
+{{if .AIAnalysis.Priority}}
+**Recommended fix order:** {{join .AIAnalysis.Priority ", "}}
+{{end}}
+{{if .AIAnalysis.Remediation}}
+` + "```bash" + `
+{{range .AIAnalysis.Remediation}}{{.}}
+{{end}}` + "```" + `
+{{end}}
+{{if .AIAnalysis.RiskAssessment}}
+**Risk assessment:** {{.AIAnalysis.RiskAssessment}}
+{{end}}
+{{with .AIAnalysis.Provenance}}
+*Analysis by {{.}}*
+{{end}}
+{{end}}
+{{end}}
+---
+
+*Generated by {{.GeneratedBy}}*
 `
 
 // summaryTemplateStr is the template for summary reports
 const summaryTemplateStr = `# Security Audit Summary Report
 
-**Generated:** {{.GeneratedAt}}
+**Generated:** {{.GeneratedAt}}{{if .RunID}}
+**Run ID:** {{.RunID}}{{end}}
 
 ---
 
@@ -135,7 +218,9 @@ const summaryTemplateStr = `# Security Audit Summary Report
 |--------|-------|
 | Total Apps Audited | {{.TotalApps}} |
 | Apps with Vulnerabilities | {{.AppsWithVulns}} |
-| Total Vulnerabilities | {{.TotalVulnerabilities}} |
+| Total Vulnerabilities | {{.TotalVulnerabilities}} |{{if .PolicyViolations}}
+| Policy Violations | {{len .PolicyViolations}} |{{end}}{{if .PolicyErrors}}
+| Policies Not Evaluated | {{.PolicyErrors}} |{{end}}
 
 ## Severity Breakdown
 
@@ -145,15 +230,46 @@ const summaryTemplateStr = `# Security Audit Summary Report
 | High | {{.HighCount}} |
 | Moderate | {{.ModerateCount}} |
 | Low | {{.LowCount}} |
+| Info | {{.InfoCount}} |
 
 ---
+{{if .AIAnalysis}}
+## AI Fleet Analysis
 
+{{.AIAnalysis.Summary}}
+{{if .AIAnalysis.Themes}}
+### Themes
+
+{{range .AIAnalysis.Themes}}- {{.}}
+{{end}}{{end}}{{if .AIAnalysis.SystemicPackages}}
+### Systemic Packages
+
+| Package | Severity | Apps | Note |
+|---------|----------|------|------|
+{{range .AIAnalysis.SystemicPackages}}| {{.Package}} | {{title .Severity}} | {{join .Apps ", "}} | {{.Note}} |
+{{end}}{{end}}{{if .AIAnalysis.Campaigns}}
+### Recommended Campaigns
+
+{{range $i, $c := .AIAnalysis.Campaigns}}{{add $i 1}}. {{$c}}
+{{end}}{{end}}{{with .AIAnalysis.Provenance}}
+*Analysis by {{.}}*
+{{end}}
+---
+{{end}}{{if .PolicyViolations}}
+## Policy Violations
+
+| App | Policy | Action | Violated By |
+|-----|--------|--------|-------------|
+{{range .PolicyViolations}}| {{.AppName}} | {{.Policy}} | {{upper .Action}} | {{if .Error}}Could not be evaluated: {{.Error}}{{else if .Subjects}}{{join .Subjects ", "}}{{else}}{{.Description}}{{end}} |
+{{end}}
+---
+{{end}}
 ## Per-App Results
 
 {{range .Results}}
 ### {{.AppName}}
 
-**Auditor:** {{.AuditorType}}
+**Auditor:** {{.AuditorType}}{{if .ToolVersion}} ({{.ToolVersion}}){{end}}
 
 | Severity | Count |
 |----------|-------|
@@ -161,27 +277,33 @@ const summaryTemplateStr = `# Security Audit Summary Report
 | High | {{.HighCount}} |
 | Moderate | {{.ModerateCount}} |
 | Low | {{.LowCount}} |
+| Info | {{.InfoCount}} |
 | **Total** | **{{.TotalVulnerabilities}}** |
 
 ---
 
 {{end}}
 
-*Generated by Audit Checks*
+*Generated by {{.GeneratedBy}}*
 `
 
 // markdownData holds data for the markdown template
 type markdownData struct {
-	AppName     string
-	AppPath     string
-	AuditorType string
-	GeneratedAt string
-	Summary     struct {
+	AppName      string
+	AppPath      string
+	AuditorType  string
+	ToolVersion  string
+	RunID        string
+	Questionable string
+	GeneratedAt  string
+	GeneratedBy  string
+	Summary      struct {
 		Total    int
 		Critical int
 		High     int
 		Moderate int
 		Low      int
+		Info     int
 	}
 	Vulnerabilities []models.Vulnerability
 	AIAnalysis      *models.AIAnalysis
@@ -189,11 +311,20 @@ type markdownData struct {
 
 // Generate creates a Markdown report
 func (r *MarkdownReporter) Generate(report *models.Report) ([]byte, error) {
+	return executeMarkdown(markdownTemplateStr, newMarkdownData(report), report.GeneratedAt)
+}
+
+// newMarkdownData returns the template data of an auditor's report
+func newMarkdownData(report *models.Report) markdownData {
 	data := markdownData{
 		AppName:         report.AppName,
 		AppPath:         report.AppPath,
 		AuditorType:     report.AuditorType,
+		ToolVersion:     report.AuditResult.ToolVersion,
+		RunID:           report.AuditResult.RunID,
+		Questionable:    report.AuditResult.Questionable,
 		GeneratedAt:     report.GeneratedAt.UTC().Format("2006-01-02 15:04:05 UTC"),
+		GeneratedBy:     buildinfo.Get().String(),
 		Vulnerabilities: report.Vulnerabilities,
 		AIAnalysis:      report.AIAnalysis,
 	}
@@ -202,8 +333,53 @@ func (r *MarkdownReporter) Generate(report *models.Report) ([]byte, error) {
 	data.Summary.High = report.AuditResult.HighCount
 	data.Summary.Moderate = report.AuditResult.ModerateCount
 	data.Summary.Low = report.AuditResult.LowCount
+	data.Summary.Info = report.AuditResult.InfoCount
+	return data
+}
+
+// combinedData holds data for the combined template
+type combinedData struct {
+	AppName     string
+	AppPath     string
+	RunID       string
+	GeneratedAt string
+	GeneratedBy string
+	Auditors    []string
+	Summary     models.Summary
+	Sections    []markdownData
+	Failures    []models.AuditFailure
+}
+
+// newCombinedData returns the template data of an app's combined report, for the
+// Markdown and HTML reporters
+func newCombinedData(report *models.CombinedAppReport) combinedData {
+	data := combinedData{
+		AppName:     report.AppName,
+		AppPath:     report.AppPath,
+		RunID:       report.RunID,
+		GeneratedAt: report.GeneratedAt.UTC().Format("2006-01-02 15:04:05 UTC"),
+		GeneratedBy: buildinfo.Get().String(),
+		Summary:     report.GetCombinedSummary(),
+		Failures:    report.Failures,
+	}
+	for _, auditReport := range report.Reports {
+		data.Auditors = append(data.Auditors, auditReport.AuditorType)
+		data.Sections = append(data.Sections, newMarkdownData(auditReport))
+	}
+	return data
+}
+
+// GenerateCombined creates a Markdown report of an app with a section per auditor
+func (r *MarkdownReporter) GenerateCombined(report *models.CombinedAppReport) ([]byte, error) {
+	return executeMarkdown(combinedTemplateStr, newCombinedData(report), report.GeneratedAt)
+}
+
+// executeMarkdown renders a Markdown template, with the ages of findings relative to
+// generatedAt, not to when the report is read
+func executeMarkdown(templateStr string, data any, generatedAt time.Time) ([]byte, error) {
+	firstSeen := func(v models.Vulnerability) string { return v.FirstSeenLabel(generatedAt) }
 
-	tmpl, err := template.New("markdown").Funcs(templateFuncs).Parse(markdownTemplateStr)
+	tmpl, err := template.New("markdown").Funcs(templateFuncs).Funcs(template.FuncMap{"firstSeen": firstSeen}).Parse(templateStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse template: %w", err)
 	}
@@ -219,6 +395,8 @@ func (r *MarkdownReporter) Generate(report *models.Report) ([]byte, error) {
 // summaryData holds data for the summary template
 type summaryData struct {
 	GeneratedAt          string
+	GeneratedBy          string
+	RunID                string
 	TotalApps            int
 	AppsWithVulns        int
 	TotalVulnerabilities int
@@ -226,13 +404,19 @@ type summaryData struct {
 	HighCount            int
 	ModerateCount        int
 	LowCount             int
+	InfoCount            int
 	Results              []*models.AuditResult
+	AIAnalysis           *models.FleetAnalysis
+	PolicyViolations     []models.PolicyViolation
+	PolicyErrors         int
 }
 
 // GenerateSummary creates a summary Markdown report
 func (r *MarkdownReporter) GenerateSummary(summary *models.AuditSummary) ([]byte, error) {
 	data := summaryData{
 		GeneratedAt:          summary.GeneratedAt.UTC().Format("2006-01-02 15:04:05 UTC"),
+		GeneratedBy:          buildinfo.Get().String(),
+		RunID:                summary.RunID,
 		TotalApps:            summary.TotalApps,
 		AppsWithVulns:        summary.AppsWithVulns,
 		TotalVulnerabilities: summary.TotalVulnerabilities,
@@ -240,7 +424,11 @@ func (r *MarkdownReporter) GenerateSummary(summary *models.AuditSummary) ([]byte
 		HighCount:            summary.HighCount,
 		ModerateCount:        summary.ModerateCount,
 		LowCount:             summary.LowCount,
+		InfoCount:            summary.InfoCount,
 		Results:              summary.Results,
+		AIAnalysis:           summary.AIAnalysis,
+		PolicyViolations:     summary.PolicyViolations,
+		PolicyErrors:         summary.PolicyErrors,
 	}
 
 	tmpl, err := template.New("summary").Funcs(templateFuncs).Parse(summaryTemplateStr)
@@ -0,0 +1,111 @@
+package reporter
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// AppReportFiles returns the report files of an app in the output directory written
+// before before, recognized by the filename template: a report of one of auditorTypes
+// or a combined report of the app. Reports are dated by the {date} of their name, or
+// by their modification time when it cannot be parsed. Returns nothing when the
+// template has no {app}, as the reports of the apps cannot be told apart.
+func (m *Manager) AppReportFiles(appName string, auditorTypes []string, before time.Time) ([]string, error) {
+	m.mu.RLock()
+	template, layout := m.filenameTemplate, m.timestampFormat
+	m.mu.RUnlock()
+
+	if !strings.Contains(template, "{app}") {
+		return nil, nil
+	}
+	pattern := reportFilePattern(template, layout, appName, auditorTypes)
+
+	var files []string
+	err := filepath.WalkDir(m.outputDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == m.outputDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(m.outputDir, path)
+		if err != nil {
+			return nil
+		}
+		match := pattern.FindStringSubmatch(filepath.ToSlash(rel))
+		if match == nil {
+			return nil
+		}
+
+		var date time.Time
+		dated := false
+		if i := pattern.SubexpIndex("date"); i >= 0 {
+			parsed, err := time.Parse(layout, match[i])
+			date, dated = parsed, err == nil
+		}
+		if !dated {
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			date = info.ModTime()
+		}
+		if date.Before(before) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// reportFilePattern returns the regular expression of the report file names of an app
+// from the filename template, relative to the output directory. Placeholders left
+// empty drop the separator next to them, as in buildFilename.
+func reportFilePattern(template, layout, appName string, auditorTypes []string) *regexp.Regexp {
+	auditors := make([]string, len(auditorTypes))
+	for i, auditorType := range auditorTypes {
+		auditors[i] = regexp.QuoteMeta(auditorType)
+	}
+
+	// The digits and letters of the layout become digits and letters of the date
+	var date strings.Builder
+	for _, r := range layout {
+		switch {
+		case unicode.IsDigit(r):
+			date.WriteString(`\d`)
+		case unicode.IsLetter(r):
+			date.WriteString(`[A-Za-z]`)
+		default:
+			date.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	pattern := regexp.QuoteMeta(filepath.ToSlash(template))
+	for _, optional := range [][2]string{
+		{"{auditor}", "(?:" + strings.Join(auditors, "|") + ")"},
+		{"{run_id}", `[0-9A-Z]{26}`},
+	} {
+		placeholder, value := optional[0], optional[1]
+		quoted := regexp.QuoteMeta(placeholder)
+		for _, sep := range []string{"-", "_", ".", " "} {
+			pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta(sep)+quoted, "(?:"+regexp.QuoteMeta(sep)+value+")?")
+			pattern = strings.ReplaceAll(pattern, quoted+regexp.QuoteMeta(sep), "(?:"+value+regexp.QuoteMeta(sep)+")?")
+		}
+		pattern = strings.ReplaceAll(pattern, quoted, "(?:"+value+")?")
+	}
+	app := strings.NewReplacer("/", "_", "\\", "_").Replace(appName)
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta("{app}"), regexp.QuoteMeta(app))
+	pattern = strings.Replace(pattern, regexp.QuoteMeta("{date}"), "(?P<date>"+date.String()+")", 1)
+	pattern = strings.ReplaceAll(pattern, regexp.QuoteMeta("{date}"), date.String())
+
+	return regexp.MustCompile("^" + pattern + `(?:\.[a-z]+)+$`)
+}
@@ -1,14 +1,16 @@
 package reporter
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/shadowbane/audit-checks/pkg/helpers"
 	"github.com/shadowbane/audit-checks/pkg/models"
-	"go.uber.org/zap"
 )
 
 // Reporter defines the interface for report generators
@@ -23,18 +25,70 @@ type Reporter interface {
 	Generate(report *models.Report) ([]byte, error)
 }
 
+// AppReporter defines the interface for report generators covering a whole app rather
+// than one auditor's result, e.g. its SBOM, generated once all of its auditors ran
+type AppReporter interface {
+	// Format returns the report format name (e.g., "cyclonedx")
+	Format() string
+
+	// Extension returns the file extension (e.g., ".cdx.json")
+	Extension() string
+
+	// GenerateApp creates the report content
+	GenerateApp(report *AppReport) ([]byte, error)
+}
+
+// AppReport is an app's audit, as app reporters see it
+type AppReport struct {
+	AppName         string
+	AppVersion      string // from package.json or composer.json, empty when unknown
+	RunID           string
+	Dependencies    []models.Dependency    // the app's inventory, empty without lockfile
+	Vulnerabilities []models.Vulnerability // findings of every auditor
+	GeneratedAt     time.Time
+}
+
+// Report file names (REPORT_FILENAME_TEMPLATE, REPORT_TIMESTAMP_FORMAT)
+const (
+	// DefaultFilenameTemplate names reports {app}-{auditor}-{date}, and summaries summary-{date}
+	DefaultFilenameTemplate = "{app}-{auditor}-{date}"
+	// DefaultTimestampFormat is the Go layout of {date}, in UTC
+	DefaultTimestampFormat = "2006-01-02-150405"
+)
+
 // Manager manages report generation and output
 type Manager struct {
-	reporters map[string]Reporter
-	outputDir string
-	mu        sync.RWMutex
+	reporters        map[string]Reporter
+	appReporters     map[string]AppReporter
+	outputDir        string
+	filenameTemplate string
+	timestampFormat  string
+	mu               sync.RWMutex
 }
 
 // NewManager creates a new report manager
 func NewManager(outputDir string) *Manager {
 	return &Manager{
-		reporters: make(map[string]Reporter),
-		outputDir: outputDir,
+		reporters:        make(map[string]Reporter),
+		appReporters:     make(map[string]AppReporter),
+		outputDir:        outputDir,
+		filenameTemplate: DefaultFilenameTemplate,
+		timestampFormat:  DefaultTimestampFormat,
+	}
+}
+
+// SetFilenameTemplate sets the file name of the reports, without extension, from
+// {app}, {auditor}, {run_id} and {date} (now in UTC, formatted with the Go layout
+// timestampFormat). A template may name subdirectories of the output directory, e.g.
+// {date}/{app}-{auditor}. Empty arguments keep the defaults.
+func (m *Manager) SetFilenameTemplate(template, timestampFormat string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if template != "" {
+		m.filenameTemplate = template
+	}
+	if timestampFormat != "" {
+		m.timestampFormat = timestampFormat
 	}
 }
 
@@ -45,6 +99,13 @@ func (m *Manager) Register(r Reporter) {
 	m.reporters[r.Format()] = r
 }
 
+// RegisterApp adds an app reporter to the manager
+func (m *Manager) RegisterApp(r AppReporter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.appReporters[r.Format()] = r
+}
+
 // Get returns a reporter by format name
 func (m *Manager) Get(format string) (Reporter, bool) {
 	m.mu.RLock()
@@ -58,25 +119,30 @@ func (m *Manager) Formats() []string {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	formats := make([]string, 0, len(m.reporters))
+	formats := make([]string, 0, len(m.reporters)+len(m.appReporters))
 	for format := range m.reporters {
 		formats = append(formats, format)
 	}
+	for format := range m.appReporters {
+		formats = append(formats, format)
+	}
 	return formats
 }
 
 // GenerateAll generates reports in all registered formats.
 // Returns a slice of generated file paths.
-func (m *Manager) GenerateAll(report *models.Report) ([]string, error) {
+func (m *Manager) GenerateAll(ctx context.Context, report *models.Report) ([]string, error) {
+	log := helpers.Logger(ctx)
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
 	var filePaths []string
 
 	for format, reporter := range m.reporters {
-		filePath, err := m.generateAndSave(report, reporter)
+		filePath, err := m.generateAndSave(ctx, report, reporter)
 		if err != nil {
-			zap.S().Errorf("Failed to generate report format=%s app=%s error=%v",
+			log.Errorf("Failed to generate report format=%s app=%s error=%v",
 				format,
 				report.AppName,
 				err,
@@ -91,7 +157,9 @@ func (m *Manager) GenerateAll(report *models.Report) ([]string, error) {
 
 // GenerateFormats generates reports only for specified formats.
 // Returns a slice of generated file paths.
-func (m *Manager) GenerateFormats(report *models.Report, formats []string) ([]string, error) {
+func (m *Manager) GenerateFormats(ctx context.Context, report *models.Report, formats []string) ([]string, error) {
+	log := helpers.Logger(ctx)
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -100,13 +168,16 @@ func (m *Manager) GenerateFormats(report *models.Report, formats []string) ([]st
 	for _, format := range formats {
 		reporter, ok := m.reporters[format]
 		if !ok {
-			zap.S().Warnf("Unknown report format: %s", format)
+			// App reports are generated by GenerateAppFormats
+			if _, ok := m.appReporters[format]; !ok {
+				log.Warnf("Unknown report format: %s", format)
+			}
 			continue
 		}
 
-		filePath, err := m.generateAndSave(report, reporter)
+		filePath, err := m.generateAndSave(ctx, report, reporter)
 		if err != nil {
-			zap.S().Errorf("Failed to generate report format=%s app=%s error=%v",
+			log.Errorf("Failed to generate report format=%s app=%s error=%v",
 				format,
 				report.AppName,
 				err,
@@ -119,22 +190,76 @@ func (m *Manager) GenerateFormats(report *models.Report, formats []string) ([]st
 	return filePaths, nil
 }
 
+// GenerateAppFormats generates the app reports of the specified formats, skipping the
+// formats of per-auditor reports. Returns a slice of generated file paths.
+func (m *Manager) GenerateAppFormats(ctx context.Context, report *AppReport, formats []string) ([]string, error) {
+	log := helpers.Logger(ctx)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var filePaths []string
+
+	for _, format := range formats {
+		reporter, ok := m.appReporters[format]
+		if !ok {
+			continue
+		}
+
+		content, err := reporter.GenerateApp(report)
+		if err != nil {
+			return filePaths, fmt.Errorf("failed to generate %s report: %w", format, err)
+		}
+
+		filename := m.buildFilename(report.AppName, "", report.RunID, reporter.Extension())
+		filePath := filepath.Join(m.outputDir, filename)
+
+		if err := writeReportFile(filePath, content); err != nil {
+			return filePaths, fmt.Errorf("failed to write report file: %w", err)
+		}
+
+		log.Infof("Report generated format=%s app=%s file=%s", format, report.AppName, filePath)
+		filePaths = append(filePaths, filePath)
+	}
+
+	return filePaths, nil
+}
+
+// HasAppFormats returns true if any of the formats is generated by an app reporter
+func (m *Manager) HasAppFormats(formats []string) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, format := range formats {
+		if _, ok := m.appReporters[format]; ok {
+			return true
+		}
+	}
+	return false
+}
+
 // generateAndSave generates a report and saves it to disk.
 // Returns the generated file path.
-func (m *Manager) generateAndSave(report *models.Report, reporter Reporter) (string, error) {
+func (m *Manager) generateAndSave(ctx context.Context, report *models.Report, reporter Reporter) (string, error) {
+	log := helpers.Logger(ctx)
+
 	content, err := reporter.Generate(report)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate %s report: %w", reporter.Format(), err)
 	}
 
-	filename := m.buildFilename(report.AppName, report.AuditorType, reporter.Extension())
+	var runID string
+	if report.AuditResult != nil {
+		runID = report.AuditResult.RunID
+	}
+	filename := m.buildFilename(report.AppName, report.AuditorType, runID, reporter.Extension())
 	filePath := filepath.Join(m.outputDir, filename)
 
-	if err := os.WriteFile(filePath, content, 0644); err != nil {
+	if err := writeReportFile(filePath, content); err != nil {
 		return "", fmt.Errorf("failed to write report file: %w", err)
 	}
 
-	zap.S().Infof("Report generated format=%s app=%s auditor=%s file=%s",
+	log.Infof("Report generated format=%s app=%s auditor=%s file=%s",
 		reporter.Format(),
 		report.AppName,
 		report.AuditorType,
@@ -144,18 +269,45 @@ func (m *Manager) generateAndSave(report *models.Report, reporter Reporter) (str
 	return filePath, nil
 }
 
-// buildFilename creates a filename for the report
-// Format: {appName}-{auditorType}-{timestamp}{extension}
-func (m *Manager) buildFilename(appName, auditorType, extension string) string {
-	timestamp := time.Now().UTC().Format("2006-01-02-150405")
-	if auditorType != "" {
-		return fmt.Sprintf("%s-%s-%s%s", appName, auditorType, timestamp, extension)
+// buildFilename creates a filename for the report from the filename template.
+// Placeholders without a value are dropped with the separator next to them, so the
+// default template names summaries summary-{date}.
+func (m *Manager) buildFilename(appName, auditorType, runID, extension string) string {
+	values := map[string]string{
+		"{app}":     appName,
+		"{auditor}": auditorType,
+		"{run_id}":  runID,
+		"{date}":    time.Now().UTC().Format(m.timestampFormat),
+	}
+
+	name := m.filenameTemplate
+	for placeholder, value := range values {
+		// Values never name directories of their own
+		value = strings.NewReplacer("/", "_", "\\", "_").Replace(value)
+		if value == "" {
+			for _, sep := range []string{"-", "_", ".", " "} {
+				name = strings.ReplaceAll(name, sep+placeholder, "")
+				name = strings.ReplaceAll(name, placeholder+sep, "")
+			}
+		}
+		name = strings.ReplaceAll(name, placeholder, value)
+	}
+
+	return filepath.Clean(name) + extension
+}
+
+// writeReportFile writes a report, creating the subdirectories named by the template
+func writeReportFile(filePath string, content []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
 	}
-	return fmt.Sprintf("%s-%s%s", appName, timestamp, extension)
+	return os.WriteFile(filePath, content, 0644)
 }
 
 // GenerateSummaryReport generates a summary report across all apps
-func (m *Manager) GenerateSummaryReport(summary *models.AuditSummary, formats []string) error {
+func (m *Manager) GenerateSummaryReport(ctx context.Context, summary *models.AuditSummary, formats []string) error {
+	log := helpers.Logger(ctx)
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -169,25 +321,25 @@ func (m *Manager) GenerateSummaryReport(summary *models.AuditSummary, formats []
 		if summaryReporter, ok := reporter.(SummaryReporter); ok {
 			content, err := summaryReporter.GenerateSummary(summary)
 			if err != nil {
-				zap.S().Errorf("Failed to generate summary report format=%s error=%v",
+				log.Errorf("Failed to generate summary report format=%s error=%v",
 					format,
 					err,
 				)
 				continue
 			}
 
-			filename := m.buildFilename("summary", "", reporter.Extension())
+			filename := m.buildFilename("summary", "", summary.RunID, reporter.Extension())
 			filePath := filepath.Join(m.outputDir, filename)
 
-			if err := os.WriteFile(filePath, content, 0644); err != nil {
-				zap.S().Errorf("Failed to write summary report format=%s error=%v",
+			if err := writeReportFile(filePath, content); err != nil {
+				log.Errorf("Failed to write summary report format=%s error=%v",
 					format,
 					err,
 				)
 				continue
 			}
 
-			zap.S().Infof("Summary report generated format=%s file=%s",
+			log.Infof("Summary report generated format=%s file=%s",
 				format,
 				filePath,
 			)
@@ -201,3 +353,50 @@ func (m *Manager) GenerateSummaryReport(summary *models.AuditSummary, formats []
 type SummaryReporter interface {
 	GenerateSummary(summary *models.AuditSummary) ([]byte, error)
 }
+
+// CombinedReporter is an optional interface for reporters that render an app's results
+// of every auditor as one document, with a section per auditor
+type CombinedReporter interface {
+	GenerateCombined(report *models.CombinedAppReport) ([]byte, error)
+}
+
+// GenerateCombined generates the combined report of an app in the specified formats,
+// skipping the formats whose reporter has none. Returns a slice of generated file paths.
+func (m *Manager) GenerateCombined(ctx context.Context, report *models.CombinedAppReport, formats []string) ([]string, error) {
+	log := helpers.Logger(ctx)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var filePaths []string
+
+	for _, format := range formats {
+		reporter, ok := m.reporters[format]
+		if !ok {
+			log.Warnf("Unknown combined report format: %s", format)
+			continue
+		}
+		combinedReporter, ok := reporter.(CombinedReporter)
+		if !ok {
+			log.Warnf("Report format %s has no combined report", format)
+			continue
+		}
+
+		content, err := combinedReporter.GenerateCombined(report)
+		if err != nil {
+			return filePaths, fmt.Errorf("failed to generate combined %s report: %w", format, err)
+		}
+
+		filename := m.buildFilename(report.AppName, "", report.RunID, reporter.Extension())
+		filePath := filepath.Join(m.outputDir, filename)
+
+		if err := writeReportFile(filePath, content); err != nil {
+			return filePaths, fmt.Errorf("failed to write report file: %w", err)
+		}
+
+		log.Infof("Combined report generated format=%s app=%s auditors=%d file=%s", format, report.AppName, len(report.Reports), filePath)
+		filePaths = append(filePaths, filePath)
+	}
+
+	return filePaths, nil
+}
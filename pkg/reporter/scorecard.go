@@ -0,0 +1,74 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+	"go.yaml.in/yaml/v3"
+)
+
+// ScorecardDir is the subdirectory of the report output directory for app scorecards
+const ScorecardDir = "scorecards"
+
+// ScorecardFormats are the supported scorecard file formats
+var ScorecardFormats = []string{"yaml", "json"}
+
+// GenerateScorecard renders a scorecard as YAML or JSON
+func GenerateScorecard(scorecard models.Scorecard, format string) ([]byte, error) {
+	switch format {
+	case "yaml":
+		var buf bytes.Buffer
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(scorecard); err != nil {
+			return nil, err
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case "json":
+		content, err := json.MarshalIndent(scorecard, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(content, '\n'), nil
+	default:
+		return nil, fmt.Errorf("unknown scorecard format %q (expected yaml or json)", format)
+	}
+}
+
+// SaveScorecards writes each app's scorecard to <scorecards dir>/<app>.<format>,
+// replacing the previous one so catalogs always read the latest. Returns the
+// written file paths.
+func (m *Manager) SaveScorecards(scorecards []models.Scorecard, formats []string) ([]string, error) {
+	dir := filepath.Join(m.outputDir, ScorecardDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create scorecard directory: %w", err)
+	}
+
+	var filePaths []string
+	for _, scorecard := range scorecards {
+		for _, format := range formats {
+			content, err := GenerateScorecard(scorecard, format)
+			if err != nil {
+				return filePaths, fmt.Errorf("failed to generate scorecard of %s: %w", scorecard.Metadata.Name, err)
+			}
+
+			filePath := filepath.Join(dir, filepath.Base(scorecard.Metadata.Name)+"."+format)
+			if err := os.WriteFile(filePath, content, 0644); err != nil {
+				return filePaths, fmt.Errorf("failed to write scorecard file: %w", err)
+			}
+			filePaths = append(filePaths, filePath)
+
+			zap.S().Debugf("Scorecard generated app=%s file=%s", scorecard.Metadata.Name, filePath)
+		}
+	}
+
+	return filePaths, nil
+}
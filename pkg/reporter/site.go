@@ -0,0 +1,276 @@
+package reporter
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+// SiteDir is the subdirectory of the report output directory for the site
+const SiteDir = "site"
+
+// Size of the trend charts, in pixels
+const (
+	siteChartWidth  = 760
+	siteChartHeight = 200
+	siteChartMargin = 30
+)
+
+// siteChartSeries are the lines of the trend charts, with their colors
+var siteChartSeries = []struct {
+	Name  string
+	Color string
+	Count func(models.Summary) int
+}{
+	{"Total", "#6c757d", func(s models.Summary) int { return s.Total }},
+	{"Critical", "#dc3545", func(s models.Summary) int { return s.Critical }},
+	{"High", "#fd7e14", func(s models.Summary) int { return s.High }},
+}
+
+// siteFuncs contains the functions of the site templates
+var siteFuncs = map[string]any{
+	"title":   strings.Title,
+	"date":    func(t time.Time) string { return t.Local().Format("2006-01-02") },
+	"default": defaultValue,
+	"chart":   siteTrendChart,
+}
+
+// siteTemplate is the layout of the site pages, with the index and app page bodies
+var siteTemplate = htmltemplate.Must(htmltemplate.New("site").Funcs(siteFuncs).Parse(`
+{{define "head"}}<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <title>{{.}}</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; }
+        .container { max-width: 1000px; margin: 0 auto; padding: 20px; }
+        .header { background: #f8f9fa; padding: 20px; border-radius: 8px; margin-bottom: 20px; }
+        .header h1 { margin: 0 0 10px 0; color: #212529; }
+        table { width: 100%; border-collapse: collapse; margin: 20px 0; }
+        th, td { padding: 8px 12px; text-align: left; border-bottom: 1px solid #dee2e6; }
+        th { background: #f8f9fa; }
+        .critical { color: #dc3545; font-weight: bold; }
+        .high { color: #fd7e14; font-weight: bold; }
+        .overdue { color: #dc3545; font-weight: bold; }
+        .muted { color: #6c757d; }
+        .legend span { margin-right: 16px; font-size: 13px; }
+        .footer { text-align: center; color: #6c757d; font-size: 12px; margin-top: 30px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+{{end}}
+{{define "foot"}}
+        <div class="footer">
+            <p>Generated by Audit Checks on {{.Format "2006-01-02 15:04:05 MST"}}</p>
+        </div>
+    </div>
+</body>
+</html>
+{{end}}
+{{define "summary"}}
+        <table>
+            <tr><th>Critical</th><th>High</th><th>Moderate</th><th>Low</th><th>Info</th><th>Total</th></tr>
+            <tr><td class="critical">{{.Critical}}</td><td class="high">{{.High}}</td><td>{{.Moderate}}</td><td>{{.Low}}</td><td>{{.Info}}</td><td><strong>{{.Total}}</strong></td></tr>
+        </table>
+{{end}}
+{{define "index"}}{{template "head" "Security Dashboard"}}
+        <div class="header">
+            <h1>Security Dashboard</h1>
+            <p>Open findings of {{len .Site.Apps}} apps, from their latest audits.</p>
+        </div>
+{{template "summary" .Site.Current}}
+        <h2>Trend</h2>
+        {{chart .Site.Trend}}
+
+        <h2>Apps</h2>
+        <table>
+            <tr><th>App</th><th>Owner</th><th>Last audit</th><th>Critical</th><th>High</th><th>Moderate</th><th>Low</th><th>Total</th></tr>
+            {{range .Site.Apps}}
+            <tr>
+                <td><a href="{{index $.Pages .Name}}">{{.Name}}</a>{{if not .Enabled}} <span class="muted">(disabled)</span>{{end}}</td>
+                <td>{{default "-" .Owner}}</td>
+                <td>{{with .LastAuditAt}}{{date .}}{{else}}<span class="muted">never</span>{{end}}</td>
+                <td class="critical">{{.Current.Critical}}</td><td class="high">{{.Current.High}}</td><td>{{.Current.Moderate}}</td><td>{{.Current.Low}}</td><td><strong>{{.Current.Total}}</strong></td>
+            </tr>
+            {{end}}
+        </table>
+{{template "foot" .Site.GeneratedAt}}{{end}}
+{{define "app"}}{{template "head" .App.Name}}
+        <p><a href="../index.html">&larr; All apps</a></p>
+        <div class="header">
+            <h1>{{.App.Name}}</h1>
+            {{with .App.Owner}}<p><strong>Owner:</strong> {{.}}</p>{{end}}
+            <p><strong>Last audit:</strong> {{with .App.LastAuditAt}}{{date .}}{{else}}never{{end}} ({{.App.Audits}} audits{{with .App.Auditors}}; {{range $i, $a := .}}{{if $i}}, {{end}}{{$a}}{{end}}{{end}})</p>
+        </div>
+{{template "summary" .App.Current}}
+        <h2>Trend</h2>
+        {{chart .App.Trend}}
+
+        <h2>Latest Findings</h2>
+        {{if .App.Findings}}
+        <table>
+            <tr><th>Severity</th><th>Package</th><th>Advisory</th><th>Auditor</th><th>First seen</th><th>Open for</th></tr>
+            {{range .App.Findings}}
+            <tr>
+                <td class="{{.Severity}}">{{title .Severity}}</td>
+                <td>{{.PackageName}}</td>
+                <td>{{if .URL}}<a href="{{.URL}}">{{default .CVEID .Title}}</a>{{else}}{{default .CVEID .Title}}{{end}}{{if and .CVEID .Title}} <span class="muted">{{.CVEID}}</span>{{end}}</td>
+                <td>{{.AuditorType}}</td>
+                <td>{{date .FirstSeen}}</td>
+                <td{{if and (gt .SLADays 0) (gt .AgeDays .SLADays)}} class="overdue"{{end}}>{{.AgeDays}} days</td>
+            </tr>
+            {{end}}
+        </table>
+        {{else}}
+        <p>No open findings.</p>
+        {{end}}
+{{template "foot" .GeneratedAt}}{{end}}
+`))
+
+// siteIndexPage is the data of the site's index
+type siteIndexPage struct {
+	Site  *models.Site
+	Pages map[string]string // app name -> page path, relative to the index
+}
+
+// siteAppPage is the data of an app's page
+type siteAppPage struct {
+	App         models.SiteApp
+	GeneratedAt time.Time
+}
+
+// SaveSite writes the site of the audit history as static HTML files to dir, or to
+// the site subdirectory of the output directory when empty: index.html listing the
+// apps and apps/<app>.html for each app. Returns the file paths.
+func (m *Manager) SaveSite(site *models.Site, dir string) ([]string, error) {
+	if dir == "" {
+		dir = filepath.Join(m.outputDir, SiteDir)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "apps"), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create site directory: %w", err)
+	}
+
+	pages := make(map[string]string, len(site.Apps))
+	used := make(map[string]bool, len(site.Apps))
+	for _, app := range site.Apps {
+		slug := siteSlug(app.Name)
+		for n := 2; used[slug]; n++ {
+			slug = fmt.Sprintf("%s-%d", siteSlug(app.Name), n)
+		}
+		used[slug] = true
+		pages[app.Name] = "apps/" + slug + ".html"
+	}
+
+	var filePaths []string
+	write := func(name, template string, data any) error {
+		var buf bytes.Buffer
+		if err := siteTemplate.ExecuteTemplate(&buf, template, data); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.WriteFile(filePath, buf.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write site file: %w", err)
+		}
+		filePaths = append(filePaths, filePath)
+		return nil
+	}
+
+	if err := write("index.html", "index", siteIndexPage{Site: site, Pages: pages}); err != nil {
+		return filePaths, err
+	}
+	for _, app := range site.Apps {
+		if err := write(pages[app.Name], "app", siteAppPage{App: app, GeneratedAt: site.GeneratedAt}); err != nil {
+			return filePaths, err
+		}
+	}
+
+	zap.S().Infof("Site generated dir=%s pages=%d", dir, len(filePaths))
+
+	return filePaths, nil
+}
+
+// siteSlug returns the file name of an app's page: its name with the characters other
+// than letters, digits, dots, dashes and underscores replaced
+func siteSlug(name string) string {
+	slug := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '-'
+	}, name)
+	if slug = strings.Trim(slug, "."); slug == "" {
+		slug = "app"
+	}
+	return slug
+}
+
+// siteTrendChart draws the total, critical and high open findings of trend as an
+// inline SVG line chart, days spaced by time
+func siteTrendChart(trend []models.TrendPoint) htmltemplate.HTML {
+	if len(trend) == 0 {
+		return `<p class="muted">No audits yet.</p>`
+	}
+
+	first, last := trend[0].Day, trend[len(trend)-1].Day
+	span := last.Sub(first).Hours()
+	peak := 1
+	for _, p := range trend {
+		peak = max(peak, p.Summary.Total)
+	}
+
+	plotWidth := float64(siteChartWidth - 2*siteChartMargin)
+	plotHeight := float64(siteChartHeight - 2*siteChartMargin)
+	x := func(day time.Time) float64 {
+		if span == 0 {
+			return siteChartMargin + plotWidth/2
+		}
+		return siteChartMargin + plotWidth*day.Sub(first).Hours()/span
+	}
+	y := func(n int) float64 {
+		return siteChartMargin + plotHeight*(1-float64(n)/float64(peak))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="100%%" viewBox="0 0 %d %d" role="img">`,
+		siteChartWidth, siteChartHeight)
+	fmt.Fprintf(&sb, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#dee2e6"/>`,
+		siteChartMargin, y(0), siteChartWidth-siteChartMargin, y(0))
+	fmt.Fprintf(&sb, `<text x="4" y="%.1f" font-size="11" fill="#6c757d">%d</text>`, y(peak)+4, peak)
+	fmt.Fprintf(&sb, `<text x="4" y="%.1f" font-size="11" fill="#6c757d">0</text>`, y(0)+4)
+	fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="11" fill="#6c757d">%s</text>`,
+		siteChartMargin, siteChartHeight-8, first.Format("2006-01-02"))
+	fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="11" fill="#6c757d" text-anchor="end">%s</text>`,
+		siteChartWidth-siteChartMargin, siteChartHeight-8, last.Format("2006-01-02"))
+
+	for _, series := range siteChartSeries {
+		points := make([]string, 0, len(trend))
+		for _, p := range trend {
+			points = append(points, fmt.Sprintf("%.1f,%.1f", x(p.Day), y(series.Count(p.Summary))))
+		}
+		if len(points) == 1 {
+			fmt.Fprintf(&sb, `<circle cx="%.1f" cy="%.1f" r="3" fill="%s"/>`,
+				x(trend[0].Day), y(series.Count(trend[0].Summary)), series.Color)
+			continue
+		}
+		fmt.Fprintf(&sb, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`,
+			series.Color, strings.Join(points, " "))
+	}
+	sb.WriteString(`</svg><div class="legend">`)
+	for _, series := range siteChartSeries {
+		fmt.Fprintf(&sb, `<span style="color: %s">&#9644; %s</span>`, series.Color, series.Name)
+	}
+	sb.WriteString(`</div>`)
+
+	return htmltemplate.HTML(sb.String())
+}
@@ -0,0 +1,204 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// spdxNoAssertion is the SPDX value for information that was not determined
+const spdxNoAssertion = "NOASSERTION"
+
+// spdxDocument is an SPDX 2.3 JSON document
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID                string            `json:"SPDXID"`
+	Name                  string            `json:"name"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// spdxIDUnsafe matches the runs of characters replaced by "-" in SPDX identifiers
+var spdxIDUnsafe = regexp.MustCompile(`[^A-Za-z0-9.]+`)
+
+// spdxLicenseToken matches the license identifiers and operators of an SPDX expression
+var spdxLicenseToken = regexp.MustCompile(`^(?:[A-Za-z0-9.-]+\+?|\(|\))$`)
+
+// GenerateSPDX creates an SPDX 2.3 JSON SBOM of an app from its dependency inventory.
+// The app is the described package: it depends on its direct production dependencies,
+// direct dev dependencies are DEV_DEPENDENCY_OF it, and packages depend on the packages
+// their lockfile entries require. Licenses recorded in the lockfiles are declared.
+func GenerateSPDX(appName string, deps []models.Dependency, toolVersion string, created time.Time) ([]byte, error) {
+	const appID = "SPDXRef-Application"
+
+	doc := spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              appName,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/audit-checks/%s-%s", url.PathEscape(appName), helpers.MustNewULID()),
+		CreationInfo: spdxCreationInfo{
+			Created:  created.UTC().Format("2006-01-02T15:04:05Z"),
+			Creators: []string{"Tool: audit-checks-" + toolVersion},
+		},
+		Packages: []spdxPackage{{
+			SPDXID:                appID,
+			Name:                  appName,
+			DownloadLocation:      spdxNoAssertion,
+			LicenseConcluded:      spdxNoAssertion,
+			LicenseDeclared:       spdxNoAssertion,
+			CopyrightText:         spdxNoAssertion,
+			PrimaryPackagePurpose: "APPLICATION",
+		}},
+		Relationships: []spdxRelationship{{
+			SPDXElementID:      "SPDXRef-DOCUMENT",
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: appID,
+		}},
+	}
+
+	// Identifiers by ecosystem and name@version, for the relationships between packages
+	ids := make(map[string]string, len(deps))
+	used := map[string]bool{appID: true}
+	for _, dep := range deps {
+		key := dep.Ecosystem + "\x00" + dep.Name + "@" + dep.Version
+		if _, ok := ids[key]; ok {
+			continue
+		}
+
+		id := "SPDXRef-Package-" + strings.Trim(spdxIDUnsafe.ReplaceAllString(dep.Ecosystem+"-"+dep.Name+"-"+dep.Version, "-"), "-")
+		for base, n := id, 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[id] = true
+		ids[key] = id
+
+		pkg := spdxPackage{
+			SPDXID:                id,
+			Name:                  dep.Name,
+			VersionInfo:           dep.Version,
+			DownloadLocation:      spdxNoAssertion,
+			LicenseConcluded:      spdxNoAssertion,
+			LicenseDeclared:       spdxLicense(dep.License),
+			CopyrightText:         spdxNoAssertion,
+			PrimaryPackagePurpose: "LIBRARY",
+		}
+		if purl := packageURL(dep); purl != "" {
+			pkg.ExternalRefs = []spdxExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  purl,
+			}}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+	}
+
+	for _, dep := range deps {
+		id := ids[dep.Ecosystem+"\x00"+dep.Name+"@"+dep.Version]
+
+		if dep.Direct {
+			relationship := spdxRelationship{SPDXElementID: appID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: id}
+			if dep.Scope == models.DependencyScopeDev {
+				relationship = spdxRelationship{SPDXElementID: id, RelationshipType: "DEV_DEPENDENCY_OF", RelatedSPDXElement: appID}
+			}
+			doc.Relationships = append(doc.Relationships, relationship)
+		}
+
+		for _, ref := range dep.DependsOn {
+			if related, ok := ids[dep.Ecosystem+"\x00"+ref]; ok {
+				doc.Relationships = append(doc.Relationships, spdxRelationship{
+					SPDXElementID:      id,
+					RelationshipType:   "DEPENDS_ON",
+					RelatedSPDXElement: related,
+				})
+			}
+		}
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// spdxLicense returns license when it looks like an SPDX license expression, and
+// NOASSERTION otherwise (e.g. "SEE LICENSE IN LICENSE.md", "UNLICENSED", "proprietary")
+func spdxLicense(license string) string {
+	expression := strings.NewReplacer("(", " ( ", ")", " ) ").Replace(license)
+	tokens := strings.Fields(expression)
+	if len(tokens) == 0 {
+		return spdxNoAssertion
+	}
+
+	// Identifiers and AND/OR/WITH operators alternate, ignoring parentheses
+	expectID := true
+	for _, token := range tokens {
+		switch {
+		case token == "(" || token == ")":
+			continue
+		case token == "AND" || token == "OR" || token == "WITH":
+			if expectID {
+				return spdxNoAssertion
+			}
+		case !spdxLicenseToken.MatchString(token):
+			return spdxNoAssertion
+		case !expectID:
+			return spdxNoAssertion
+		case strings.EqualFold(token, "UNLICENSED") || strings.EqualFold(token, "proprietary"):
+			return spdxNoAssertion
+		}
+		expectID = !expectID
+	}
+	if expectID {
+		return spdxNoAssertion
+	}
+	return license
+}
+
+// packageURL returns the purl of a dependency, or an empty string for unknown ecosystems
+func packageURL(dep models.Dependency) string {
+	switch dep.Ecosystem {
+	case "npm":
+		// Scoped packages have the scope as namespace, with "@" encoded
+		return "pkg:npm/" + strings.Replace(dep.Name, "@", "%40", 1) + "@" + url.PathEscape(dep.Version)
+	case "Packagist":
+		return "pkg:composer/" + strings.ToLower(dep.Name) + "@" + url.PathEscape(dep.Version)
+	}
+	return ""
+}
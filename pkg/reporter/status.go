@@ -0,0 +1,123 @@
+package reporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	htmltemplate "html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+)
+
+// StatusDir is the subdirectory of the report output directory for the status page
+const StatusDir = "status"
+
+// statusRefreshSeconds is how often a wallboard reloads the status page
+const statusRefreshSeconds = 300
+
+// statusLabels are the captions of the status colours
+var statusLabels = map[string]string{
+	models.StatusGreen:  "OK",
+	models.StatusYellow: "Attention",
+	models.StatusRed:    "Action needed",
+}
+
+// statusTemplate is the layout of the status page: a tile per app, with no scripts or
+// external assets
+var statusTemplate = htmltemplate.Must(htmltemplate.New("status").Funcs(map[string]any{
+	"label": func(status string) string { return statusLabels[status] },
+	"date":  func(t time.Time) string { return t.Local().Format("2006-01-02") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1">
+    <meta http-equiv="refresh" content="{{.Refresh}}">
+    <title>{{.Page.Title}}</title>
+    <style>
+        body { font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; line-height: 1.6; color: #333; margin: 0; background: #f8f9fa; }
+        .container { padding: 20px; }
+        h1 { margin: 0 0 5px 0; color: #212529; }
+        .counts span { margin-right: 16px; }
+        .tiles { display: grid; grid-template-columns: repeat(auto-fill, minmax(220px, 1fr)); gap: 12px; margin-top: 20px; }
+        .tile { border-radius: 8px; padding: 14px 16px; color: #fff; }
+        .tile .name { font-size: 20px; font-weight: bold; word-break: break-word; }
+        .green { background: #198754; }
+        .yellow { background: #ffc107; color: #212529; }
+        .red { background: #dc3545; }
+        .muted { color: #6c757d; }
+        .footer { text-align: center; color: #6c757d; font-size: 12px; margin-top: 30px; }
+    </style>
+</head>
+<body>
+    <div class="container">
+        <h1>{{.Page.Title}}</h1>
+        <p class="counts"><span>{{index .Page.Counts "red"}} action needed</span><span>{{index .Page.Counts "yellow"}} attention</span><span>{{index .Page.Counts "green"}} OK</span></p>
+        <div class="tiles">
+            {{range .Page.Apps}}
+            <div class="tile {{.Status}}">
+                <div class="name">{{.Name}}</div>
+                <div>{{label .Status}}</div>
+                <div>{{with .LastAuditAt}}Last audit: {{date .}}{{else}}Never audited{{end}}{{if and .Stale .LastAuditAt}} (stale){{end}}</div>
+            </div>
+            {{else}}
+            <p class="muted">No apps.</p>
+            {{end}}
+        </div>
+        <div class="footer">
+            <p>Updated {{.Page.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}</p>
+        </div>
+    </div>
+</body>
+</html>
+`))
+
+// statusPageData is the data of the status page
+type statusPageData struct {
+	Page    *models.StatusPage
+	Refresh int
+}
+
+// SaveStatusPage writes the status page to dir, or to the status subdirectory of the
+// output directory when empty: index.html for browsers and wallboards, and
+// status.json with the same content for other dashboards. Returns the file paths.
+func (m *Manager) SaveStatusPage(page *models.StatusPage, dir string) ([]string, error) {
+	if dir == "" {
+		dir = filepath.Join(m.outputDir, StatusDir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create status page directory: %w", err)
+	}
+
+	var html bytes.Buffer
+	if err := statusTemplate.Execute(&html, statusPageData{Page: page, Refresh: statusRefreshSeconds}); err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+	data, err := json.MarshalIndent(page, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal status page: %w", err)
+	}
+
+	var filePaths []string
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{
+		{"index.html", html.Bytes()},
+		{"status.json", data},
+	} {
+		filePath := filepath.Join(dir, file.name)
+		if err := os.WriteFile(filePath, file.data, 0644); err != nil {
+			return filePaths, fmt.Errorf("failed to write status page: %w", err)
+		}
+		filePaths = append(filePaths, filePath)
+	}
+
+	zap.S().Infof("Status page generated dir=%s apps=%d", dir, len(page.Apps))
+
+	return filePaths, nil
+}
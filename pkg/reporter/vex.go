@@ -0,0 +1,198 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// cycloneDXVulnerability is a vulnerability of a CycloneDX BOM, with the components it
+// affects (VDR) and its analysis (VEX)
+type cycloneDXVulnerability struct {
+	BOMRef         string              `json:"bom-ref"`
+	ID             string              `json:"id"`
+	Source         *cycloneDXSource    `json:"source,omitempty"`
+	Ratings        []cycloneDXRating   `json:"ratings"`
+	Description    string              `json:"description,omitempty"`
+	Detail         string              `json:"detail,omitempty"`
+	Recommendation string              `json:"recommendation,omitempty"`
+	Advisories     []cycloneDXAdvisory `json:"advisories,omitempty"`
+	Analysis       cycloneDXAnalysis   `json:"analysis"`
+	Affects        []cycloneDXAffect   `json:"affects"`
+	Properties     []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+type cycloneDXSource struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type cycloneDXRating struct {
+	Severity string `json:"severity"`
+	Method   string `json:"method,omitempty"`
+}
+
+type cycloneDXAdvisory struct {
+	URL string `json:"url"`
+}
+
+type cycloneDXAnalysis struct {
+	State       string   `json:"state"`
+	Response    []string `json:"response,omitempty"`
+	Detail      string   `json:"detail,omitempty"`
+	FirstIssued string   `json:"firstIssued,omitempty"`
+	LastUpdated string   `json:"lastUpdated,omitempty"`
+}
+
+type cycloneDXAffect struct {
+	Ref      string                   `json:"ref"`
+	Versions []cycloneDXAffectVersion `json:"versions,omitempty"`
+}
+
+type cycloneDXAffectVersion struct {
+	Version string `json:"version"`
+	Status  string `json:"status"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// cycloneDXSeverities maps finding severities to CycloneDX rating severities
+var cycloneDXSeverities = map[string]string{
+	models.SeverityCritical: "critical",
+	models.SeverityHigh:     "high",
+	models.SeverityModerate: "medium",
+	models.SeverityLow:      "low",
+	models.SeverityInfo:     "info",
+}
+
+// CycloneDXReporter writes an app's CycloneDX 1.5 SBOM with its findings embedded as
+// vulnerabilities, so that one file gives downstream tools both the inventory and
+// which of its components are vulnerable (VDR) and what is done about it (VEX)
+type CycloneDXReporter struct {
+	toolVersion string
+}
+
+// NewCycloneDXReporter creates a new CycloneDXReporter, naming audit-checks at
+// toolVersion as the BOM's tool
+func NewCycloneDXReporter(toolVersion string) *CycloneDXReporter {
+	return &CycloneDXReporter{toolVersion: toolVersion}
+}
+
+// Format returns the format name
+func (r *CycloneDXReporter) Format() string {
+	return "cyclonedx"
+}
+
+// Extension returns the file extension
+func (r *CycloneDXReporter) Extension() string {
+	return ".cdx.json"
+}
+
+// GenerateApp creates the SBOM of an app with its findings
+func (r *CycloneDXReporter) GenerateApp(report *AppReport) ([]byte, error) {
+	return GenerateCycloneDXVEX(report.AppName, report.AppVersion, report.Dependencies, report.Vulnerabilities,
+		r.toolVersion, report.GeneratedAt)
+}
+
+// GenerateCycloneDXVEX creates the CycloneDX 1.5 JSON SBOM of an app, as
+// GenerateCycloneDX does, with its findings as vulnerabilities. A finding affects the
+// installed versions of its package, or the app itself when its package is not in the
+// inventory (e.g. the PHP runtime). Findings reported by several auditors are listed
+// once. Findings are open, so their analysis is in_triage.
+func GenerateCycloneDXVEX(appName, appVersion string, deps []models.Dependency, vulns []models.Vulnerability, toolVersion string, created time.Time) ([]byte, error) {
+	bom, refsByName, err := newCycloneDXBOM(appName, appVersion, deps, toolVersion, created)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string, len(bom.Components))
+	for _, component := range bom.Components {
+		versions[component.BOMRef] = component.Version
+	}
+
+	bom.Vulnerabilities = []cycloneDXVulnerability{}
+	byKey := make(map[string]int)
+	for _, v := range vulns {
+		affects := cycloneDXAffects(refsByName[v.PackageName], versions)
+
+		if i, ok := byKey[v.FindingKey()]; ok {
+			existing := &bom.Vulnerabilities[i]
+			for _, affect := range affects {
+				if !slices.ContainsFunc(existing.Affects, func(a cycloneDXAffect) bool { return a.Ref == affect.Ref }) {
+					existing.Affects = append(existing.Affects, affect)
+				}
+			}
+			continue
+		}
+		byKey[v.FindingKey()] = len(bom.Vulnerabilities)
+
+		id := v.CVEID
+		if id == "" {
+			id = v.Title
+		}
+		vulnerability := cycloneDXVulnerability{
+			BOMRef:         fmt.Sprintf("vulnerability-%d", len(bom.Vulnerabilities)+1),
+			ID:             id,
+			Ratings:        []cycloneDXRating{{Severity: cycloneDXSeverity(v.Severity), Method: "other"}},
+			Description:    v.Title,
+			Detail:         v.Description,
+			Recommendation: v.Recommendation,
+			Affects:        affects,
+			Analysis: cycloneDXAnalysis{
+				State:       "in_triage",
+				LastUpdated: created.UTC().Format("2006-01-02T15:04:05Z"),
+			},
+		}
+		if v.URL != "" {
+			vulnerability.Source = &cycloneDXSource{URL: v.URL}
+			vulnerability.Advisories = []cycloneDXAdvisory{{URL: v.URL}}
+		}
+		if v.FirstSeenAt != nil {
+			vulnerability.Analysis.FirstIssued = v.FirstSeenAt.UTC().Format("2006-01-02T15:04:05Z")
+		}
+		if v.VulnerableVersions != "" {
+			vulnerability.Properties = append(vulnerability.Properties,
+				cycloneDXProperty{Name: "audit-checks:vulnerable_versions", Value: v.VulnerableVersions})
+		}
+		if v.PatchedVersions != "" {
+			vulnerability.Analysis.Response = []string{"update"}
+			vulnerability.Properties = append(vulnerability.Properties,
+				cycloneDXProperty{Name: "audit-checks:patched_versions", Value: v.PatchedVersions})
+		}
+		bom.Vulnerabilities = append(bom.Vulnerabilities, vulnerability)
+	}
+
+	return json.MarshalIndent(bom, "", "  ")
+}
+
+// cycloneDXAffects returns the components a finding affects: the installed versions of
+// its package (refs), or the app
+func cycloneDXAffects(refs []string, versions map[string]string) []cycloneDXAffect {
+	if len(refs) == 0 {
+		return []cycloneDXAffect{{Ref: cycloneDXAppRef}}
+	}
+
+	affects := make([]cycloneDXAffect, 0, len(refs))
+	for _, ref := range refs {
+		affect := cycloneDXAffect{Ref: ref}
+		if version := versions[ref]; version != "" {
+			affect.Versions = []cycloneDXAffectVersion{{Version: version, Status: "affected"}}
+		}
+		affects = append(affects, affect)
+	}
+	return affects
+}
+
+// cycloneDXSeverity returns the CycloneDX rating severity of a finding severity
+func cycloneDXSeverity(severity string) string {
+	if s, ok := cycloneDXSeverities[severity]; ok {
+		return s
+	}
+	return "unknown"
+}
@@ -0,0 +1,315 @@
+package reporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// xlsxMaxSheetName is the longest sheet name Excel accepts
+const xlsxMaxSheetName = 31
+
+// xlsxFindingColumns are the columns of a sheet of findings, with their widths
+var xlsxFindingColumns = []xlsxColumn{
+	{"Severity", 10},
+	{"Package", 30},
+	{"ID", 22},
+	{"Title", 50},
+	{"Auditor", 12},
+	{"Vulnerable Versions", 22},
+	{"Patched Versions", 20},
+	{"First Seen", 12},
+	{"URL", 40},
+	{"Recommendation", 50},
+}
+
+// xlsxSummaryColumns are the columns of the summary sheet, with their widths
+var xlsxSummaryColumns = []xlsxColumn{
+	{"App", 30},
+	{"Auditors", 30},
+	{"Critical", 10},
+	{"High", 10},
+	{"Moderate", 10},
+	{"Low", 10},
+	{"Info", 10},
+	{"Total", 10},
+}
+
+// XLSXReporter generates Excel workbooks: the run's summary is a workbook with a sheet
+// of the findings counts of each app by severity and a sheet of findings per app, an
+// auditor's report a workbook with the sheet of its findings
+type XLSXReporter struct{}
+
+// NewXLSXReporter creates a new XLSXReporter
+func NewXLSXReporter() *XLSXReporter {
+	return &XLSXReporter{}
+}
+
+// Format returns "xlsx"
+func (r *XLSXReporter) Format() string {
+	return "xlsx"
+}
+
+// Extension returns ".xlsx"
+func (r *XLSXReporter) Extension() string {
+	return ".xlsx"
+}
+
+// xlsxColumn is a column of a sheet
+type xlsxColumn struct {
+	Header string
+	Width  float64
+}
+
+// xlsxSheet is a sheet of a workbook. Cells are strings or ints.
+type xlsxSheet struct {
+	Name    string
+	Columns []xlsxColumn
+	Rows    [][]any
+}
+
+// Generate creates a workbook of an auditor's findings
+func (r *XLSXReporter) Generate(report *models.Report) ([]byte, error) {
+	sheet := xlsxSheet{Name: report.AppName, Columns: xlsxFindingColumns}
+	for _, v := range report.Vulnerabilities {
+		sheet.Rows = append(sheet.Rows, xlsxFindingRow(report.AuditorType, v))
+	}
+	return writeXLSX([]xlsxSheet{sheet}, report.GeneratedAt)
+}
+
+// GenerateSummary creates a workbook of the run: a summary sheet counting the findings
+// of each app by severity, then a sheet per app listing its findings by severity
+func (r *XLSXReporter) GenerateSummary(summary *models.AuditSummary) ([]byte, error) {
+	sheets := []xlsxSheet{{Name: "Summary", Columns: xlsxSummaryColumns}}
+
+	// Results are sorted by app, then auditor
+	var appSheet *xlsxSheet
+	var counts []any
+	for _, result := range summary.Results {
+		if appSheet == nil || appSheet.Name != result.AppName {
+			sheets = append(sheets, xlsxSheet{Name: result.AppName, Columns: xlsxFindingColumns})
+			appSheet = &sheets[len(sheets)-1]
+			counts = []any{result.AppName, result.AuditorType, 0, 0, 0, 0, 0, 0}
+			sheets[0].Rows = append(sheets[0].Rows, counts)
+		} else {
+			counts[1] = counts[1].(string) + ", " + result.AuditorType
+		}
+
+		for i, n := range []int{result.CriticalCount, result.HighCount, result.ModerateCount,
+			result.LowCount, result.InfoCount, result.TotalVulnerabilities} {
+			counts[2+i] = counts[2+i].(int) + n
+		}
+		for _, v := range result.Vulnerabilities {
+			appSheet.Rows = append(appSheet.Rows, xlsxFindingRow(result.AuditorType, v))
+		}
+	}
+
+	// Most severe first, in each auditor's order
+	for _, sheet := range sheets[1:] {
+		slices.SortStableFunc(sheet.Rows, func(a, b []any) int {
+			return models.SeverityOrder[b[0].(string)] - models.SeverityOrder[a[0].(string)]
+		})
+	}
+
+	sheets[0].Rows = append(sheets[0].Rows, []any{
+		"Total", "",
+		summary.CriticalCount, summary.HighCount, summary.ModerateCount,
+		summary.LowCount, summary.InfoCount, summary.TotalVulnerabilities,
+	})
+
+	return writeXLSX(sheets, summary.GeneratedAt)
+}
+
+// xlsxFindingRow returns the row of a finding reported by auditorType
+func xlsxFindingRow(auditorType string, v models.Vulnerability) []any {
+	firstSeen := ""
+	if v.FirstSeenAt != nil {
+		firstSeen = v.FirstSeenAt.UTC().Format("2006-01-02")
+	}
+	return []any{
+		v.Severity, v.PackageName, v.CVEID, v.Title, auditorType,
+		v.VulnerableVersions, v.PatchedVersions, firstSeen, v.URL, v.Recommendation,
+	}
+}
+
+// writeXLSX writes sheets as an Office Open XML workbook. Each sheet has a bold, frozen
+// header row with filters. Sheet names are made valid and unique, as Excel requires.
+func writeXLSX(sheets []xlsxSheet, modified time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	add := func(name, content string) error {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+		if err != nil {
+			return err
+		}
+		_, err = w.Write([]byte(xml.Header + content))
+		return err
+	}
+
+	var contentTypes, workbook, workbookRels strings.Builder
+	contentTypes.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	workbookRels.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rIdStyles" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`)
+
+	used := make(map[string]bool, len(sheets))
+	for i, sheet := range sheets {
+		n := i + 1
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" `+
+			`ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, n)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`,
+			xlsxEscape(xlsxSheetName(sheet.Name, used)), n, n)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" `+
+			`Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" `+
+			`Target="worksheets/sheet%d.xml"/>`, n, n)
+
+		if err := add(fmt.Sprintf("xl/worksheets/sheet%d.xml", n), xlsxWorksheet(sheet)); err != nil {
+			return nil, err
+		}
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(`</Relationships>`)
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+		// Style 1 is the bold header
+		{"xl/styles.xml", `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+			`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+			`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+			`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+			`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+			`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+			`</styleSheet>`},
+	}
+	for _, part := range parts {
+		if err := add(part.name, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// xlsxWorksheet returns the XML of a sheet, with inline strings
+func xlsxWorksheet(sheet xlsxSheet) string {
+	var sb strings.Builder
+	lastCol := xlsxColumnName(len(sheet.Columns) - 1)
+
+	sb.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	sb.WriteString(`<sheetViews><sheetView workbookViewId="0">` +
+		`<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	sb.WriteString(`<cols>`)
+	for i, col := range sheet.Columns {
+		fmt.Fprintf(&sb, `<col min="%d" max="%d" width="%g" customWidth="1"/>`, i+1, i+1, col.Width)
+	}
+	sb.WriteString(`</cols><sheetData>`)
+
+	header := make([]any, len(sheet.Columns))
+	for i, col := range sheet.Columns {
+		header[i] = col.Header
+	}
+	xlsxWriteRow(&sb, 1, header, 1)
+	for i, row := range sheet.Rows {
+		xlsxWriteRow(&sb, i+2, row, 0)
+	}
+
+	sb.WriteString(`</sheetData>`)
+	fmt.Fprintf(&sb, `<autoFilter ref="A1:%s%d"/>`, lastCol, len(sheet.Rows)+1)
+	sb.WriteString(`</worksheet>`)
+	return sb.String()
+}
+
+// xlsxWriteRow writes row n of a sheet, its cells in style; empty strings are left out
+func xlsxWriteRow(sb *strings.Builder, n int, row []any, style int) {
+	fmt.Fprintf(sb, `<row r="%d">`, n)
+	for i, value := range row {
+		ref := xlsxColumnName(i) + strconv.Itoa(n)
+		styleAttr := ""
+		if style != 0 {
+			styleAttr = fmt.Sprintf(` s="%d"`, style)
+		}
+		switch v := value.(type) {
+		case int:
+			fmt.Fprintf(sb, `<c r="%s"%s><v>%d</v></c>`, ref, styleAttr, v)
+		case string:
+			if v == "" {
+				continue
+			}
+			fmt.Fprintf(sb, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`,
+				ref, styleAttr, xlsxEscape(v))
+		}
+	}
+	sb.WriteString(`</row>`)
+}
+
+// xlsxColumnName returns the letters of the column at index i, e.g. A, Z, AA
+func xlsxColumnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+// xlsxSheetName returns a valid sheet name for name that is not in used, and marks it
+// used: without the characters Excel forbids, at most 31 characters long, and suffixed
+// with a number when another sheet has it (sheet names are case-insensitive)
+func xlsxSheetName(name string, used map[string]bool) string {
+	name = strings.Trim(strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, name), "'")
+	if name == "" {
+		name = "Sheet"
+	}
+
+	candidate := xlsxTruncate(name, xlsxMaxSheetName)
+	for n := 2; used[strings.ToLower(candidate)]; n++ {
+		suffix := fmt.Sprintf(" (%d)", n)
+		candidate = xlsxTruncate(name, xlsxMaxSheetName-len(suffix)) + suffix
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
+
+// xlsxTruncate returns s cut to at most n characters
+func xlsxTruncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
+
+// xlsxEscape escapes s for XML text and attributes; characters XML does not allow are
+// replaced
+func xlsxEscape(s string) string {
+	var sb strings.Builder
+	_ = xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
@@ -0,0 +1,538 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/config/dblogger"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// auditResultSummaryColumns are the columns loaded when listing audit results
+var auditResultSummaryColumns = []string{
+	"id", "run_id", "app_name", "auditor_type", "total_vulnerabilities",
+	"critical_count", "high_count", "moderate_count", "low_count", "info_count", "created_at",
+}
+
+// GormStore implements the Store interface on a GORM database
+type GormStore struct {
+	db *gorm.DB
+}
+
+// NewGormStore creates a new GormStore on an open database with migrations applied
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+// OpenSQLite opens the SQLite database at cfg.DBSQLitePath and runs migrations
+func OpenSQLite(cfg *config.Config) (*GormStore, error) {
+	gormConfig := &gorm.Config{
+		Logger: &dblogger.ZapLogger{
+			Config: gormlogger.Config{
+				SlowThreshold:             time.Second,
+				LogLevel:                  dblogger.LogLevelToGormLevel(cfg.GetDBLogLevel()),
+				IgnoreRecordNotFoundError: true,
+				ParameterizedQueries:      true,
+			},
+		},
+	}
+
+	zap.S().Debugf("Connecting to SQLite database at %s", cfg.DBSQLitePath)
+
+	db, err := gorm.Open(sqlite.Open(cfg.DBSQLitePath), gormConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	// Run migrations
+	if err := Migrate(db); err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	// SQLite works best with a single connection for write operations
+	sqlDB, err := db.DB()
+	if err == nil {
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	zap.S().Infof("Database initialized at %s", cfg.DBSQLitePath)
+
+	return NewGormStore(db), nil
+}
+
+// DB returns the underlying database
+func (s *GormStore) DB() *gorm.DB {
+	return s.db
+}
+
+// Apps returns every configured app
+func (s *GormStore) Apps() ([]models.App, error) {
+	var apps []models.App
+	if err := s.db.Find(&apps).Error; err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// ResumeExpiredPauses re-enables apps whose pause ended before now.
+// Pause times are stored in UTC so they compare as text.
+func (s *GormStore) ResumeExpiredPauses(now time.Time) (int64, error) {
+	resumed := s.db.Model(&models.App{}).
+		Where("paused_until IS NOT NULL AND paused_until <= ?", now.UTC()).
+		Update("paused_until", nil)
+	return resumed.RowsAffected, resumed.Error
+}
+
+// SaveTelegramTopicID stores the Telegram forum topic created for an app
+func (s *GormStore) SaveTelegramTopicID(appName string, topicID int) error {
+	return s.db.Model(&models.App{}).Where("name = ?", appName).
+		Update("telegram_topic_id", topicID).Error
+}
+
+// SaveDiscordThreadID stores the Discord thread created for an app
+func (s *GormStore) SaveDiscordThreadID(appName, threadID string) error {
+	return s.db.Model(&models.App{}).Where("name = ?", appName).
+		Update("discord_thread_id", threadID).Error
+}
+
+// SavePagerDutyDedupKey stores the deduplication key of an app's open PagerDuty incident
+func (s *GormStore) SavePagerDutyDedupKey(appName, dedupKey string) error {
+	return s.db.Model(&models.App{}).Where("name = ?", appName).
+		Update("pagerduty_dedup_key", dedupKey).Error
+}
+
+// SaveOpsgenieAliases stores the aliases of an app's open Opsgenie alerts
+func (s *GormStore) SaveOpsgenieAliases(appName string, aliases []string) error {
+	return s.db.Model(&models.App{}).Where("name = ?", appName).
+		Update("opsgenie_aliases", models.StringArray(aliases)).Error
+}
+
+// SaveGitLabIssue stores the reference of an app's open GitLab issue
+func (s *GormStore) SaveGitLabIssue(appName, issue string) error {
+	return s.db.Model(&models.App{}).Where("name = ?", appName).
+		Update("gitlab_issue", issue).Error
+}
+
+// SaveNotificationStatus stores when an app was last notified and the channels that failed
+func (s *GormStore) SaveNotificationStatus(appName string, at time.Time, failed []string) error {
+	return s.db.Model(&models.App{}).Where("name = ?", appName).
+		Updates(map[string]any{"last_notified_at": at, "last_notify_failed": models.StringArray(failed)}).Error
+}
+
+// SaveAuditResult stores an audit result with its vulnerabilities. Its raw output is
+// stored once per content, for every result that has it.
+func (s *GormStore) SaveAuditResult(result *models.AuditResult) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if result.RawOutput != "" {
+			raw := models.RawOutput{
+				Hash:    models.RawOutputHash(result.RawOutput),
+				Content: result.RawOutput,
+				Size:    len(result.RawOutput),
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&raw).Error; err != nil {
+				return fmt.Errorf("failed to store raw output: %w", err)
+			}
+			result.RawOutputHash = raw.Hash
+		}
+		return tx.Create(result).Error
+	})
+}
+
+// AuditResults returns every audit result, oldest first
+func (s *GormStore) AuditResults() ([]models.AuditResult, error) {
+	var results []models.AuditResult
+	err := s.db.Select(auditResultSummaryColumns).
+		Order("created_at").
+		Find(&results).Error
+	return results, err
+}
+
+// RecentAuditResults returns up to limit audit results of an app, newest first
+func (s *GormStore) RecentAuditResults(appName string, limit int) ([]models.AuditResult, error) {
+	var results []models.AuditResult
+	err := s.db.Select(auditResultSummaryColumns).
+		Where("app_name = ?", appName).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&results).Error
+	return results, err
+}
+
+// Vulnerabilities returns the vulnerabilities of the given audit results
+func (s *GormStore) Vulnerabilities(resultIDs []string) ([]models.Vulnerability, error) {
+	var vulns []models.Vulnerability
+	err := s.db.Where("audit_result_id IN ?", resultIDs).Find(&vulns).Error
+	return vulns, err
+}
+
+// VulnerabilityHistory returns every vulnerability ever reported
+func (s *GormStore) VulnerabilityHistory() ([]models.Vulnerability, error) {
+	var history []models.Vulnerability
+	err := s.db.Select("audit_result_id", "package_name", "cve_id", "title").Find(&history).Error
+	return history, err
+}
+
+// FindingsFirstSeen returns when each finding of an app and auditor was first seen
+func (s *GormStore) FindingsFirstSeen(appName, auditorType string) (map[string]time.Time, error) {
+	var results []models.AuditResult
+	if err := s.db.Select("id", "created_at").
+		Where("app_name = ? AND auditor_type = ?", appName, auditorType).
+		Find(&results).Error; err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return map[string]time.Time{}, nil
+	}
+
+	createdAt := make(map[string]time.Time, len(results))
+	for _, r := range results {
+		createdAt[r.ID] = r.CreatedAt
+	}
+
+	var history []models.Vulnerability
+	err := s.db.Select("audit_result_id", "package_name", "cve_id", "title", "first_seen_at").
+		Where("audit_result_id IN (?)", s.db.Model(&models.AuditResult{}).Select("id").
+			Where("app_name = ? AND auditor_type = ?", appName, auditorType)).
+		Find(&history).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return firstSeen(history, createdAt), nil
+}
+
+// firstSeen returns the earliest sighting of each finding in history: the first-seen
+// time recorded with it, or the creation time of its audit result
+func firstSeen(history []models.Vulnerability, createdAt map[string]time.Time) map[string]time.Time {
+	seen := make(map[string]time.Time)
+	for _, v := range history {
+		at, ok := createdAt[v.AuditResultID]
+		if !ok {
+			continue
+		}
+		if v.FirstSeenAt != nil && v.FirstSeenAt.Before(at) {
+			at = *v.FirstSeenAt
+		}
+		key := v.FindingKey()
+		if prev, ok := seen[key]; !ok || at.Before(prev) {
+			seen[key] = at
+		}
+	}
+	return seen
+}
+
+// VulnerabilitiesFirstSeenSince returns the vulnerabilities first seen at or after since
+func (s *GormStore) VulnerabilitiesFirstSeenSince(since time.Time) ([]models.Vulnerability, error) {
+	var vulns []models.Vulnerability
+	err := s.db.Where("first_seen_at >= ?", since).Find(&vulns).Error
+	return vulns, err
+}
+
+// SaveRunEvent stores a progress event of a run
+func (s *GormStore) SaveRunEvent(event *models.RunEvent) error {
+	return s.db.Create(event).Error
+}
+
+// PurgeRunEvents deletes progress events created before cutoff
+func (s *GormStore) PurgeRunEvents(cutoff time.Time) error {
+	return s.db.Where("created_at < ?", cutoff).Delete(&models.RunEvent{}).Error
+}
+
+// errDryRun rolls back the transaction of a dry-run purge
+var errDryRun = errors.New("dry run")
+
+// purgeChunkSize is the number of audit results deleted per query, below SQLite's
+// limit on query parameters
+const purgeChunkSize = 500
+
+// PurgeAppData deletes the history of an app created before purge.Before
+func (s *GormStore) PurgeAppData(purge *models.DataPurge) ([]string, error) {
+	var files []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var results []models.AuditResult
+		err := tx.Select("id", "auditor_type", "raw_output_hash", "raw_output_file", "log_file", "created_at").
+			Where("app_name = ?", purge.AppName).
+			Order("created_at").
+			Find(&results).Error
+		if err != nil {
+			return fmt.Errorf("failed to query audit results: %w", err)
+		}
+
+		latest := make(map[string]string, len(results)) // auditor -> result ID
+		for _, r := range results {
+			latest[r.AuditorType] = r.ID
+		}
+
+		var ids, hashes, candidates, logFiles []string
+		for _, r := range results {
+			if !r.CreatedAt.Before(purge.Before) || (purge.KeepLatest && latest[r.AuditorType] == r.ID) {
+				continue
+			}
+			ids = append(ids, r.ID)
+			if r.RawOutputHash != "" && !slices.Contains(hashes, r.RawOutputHash) {
+				hashes = append(hashes, r.RawOutputHash)
+			}
+			for _, file := range []string{r.RawOutputFile, r.LogFile} {
+				if file != "" && !slices.Contains(candidates, file) {
+					candidates = append(candidates, file)
+				}
+			}
+			if r.LogFile != "" {
+				logFiles = append(logFiles, r.LogFile)
+			}
+		}
+
+		for chunk := range slices.Chunk(ids, purgeChunkSize) {
+			deleted := tx.Where("audit_result_id IN ?", chunk).Delete(&models.Vulnerability{})
+			if deleted.Error != nil {
+				return fmt.Errorf("failed to delete vulnerabilities: %w", deleted.Error)
+			}
+			purge.Vulnerabilities += deleted.RowsAffected
+
+			deleted = tx.Where("id IN ?", chunk).Delete(&models.AuditResult{})
+			if deleted.Error != nil {
+				return fmt.Errorf("failed to delete audit results: %w", deleted.Error)
+			}
+			purge.AuditResults += deleted.RowsAffected
+		}
+
+		// Raw outputs are shared by identical results, of any app
+		for chunk := range slices.Chunk(hashes, purgeChunkSize) {
+			deleted := tx.Where("hash IN ? AND hash NOT IN (?)", chunk,
+				tx.Model(&models.AuditResult{}).Select("raw_output_hash").Where("raw_output_hash IN ?", chunk)).
+				Delete(&models.RawOutput{})
+			if deleted.Error != nil {
+				return fmt.Errorf("failed to delete raw outputs: %w", deleted.Error)
+			}
+			purge.RawOutputs += deleted.RowsAffected
+		}
+
+		// So are run logs, by the apps audited in the same run. The app's lines are
+		// removed from those only other apps' results refer to.
+		for _, file := range candidates {
+			var used, own int64
+			if err := tx.Model(&models.AuditResult{}).Where("raw_output_file = ? OR log_file = ?", file, file).Count(&used).Error; err != nil {
+				return fmt.Errorf("failed to query audit results: %w", err)
+			}
+			if used > 0 {
+				err := tx.Model(&models.AuditResult{}).
+					Where("(raw_output_file = ? OR log_file = ?) AND app_name = ?", file, file, purge.AppName).
+					Count(&own).Error
+				if err != nil {
+					return fmt.Errorf("failed to query audit results: %w", err)
+				}
+			}
+			switch {
+			case used == 0:
+				files = append(files, file)
+			case own == 0 && slices.Contains(logFiles, file):
+				purge.RedactedFiles = append(purge.RedactedFiles, file)
+			default:
+				purge.KeptFiles = append(purge.KeptFiles, file)
+			}
+		}
+
+		deleted := tx.Where("app_name = ? AND created_at < ?", purge.AppName, purge.Before).Delete(&models.RunEvent{})
+		if deleted.Error != nil {
+			return fmt.Errorf("failed to delete run events: %w", deleted.Error)
+		}
+		purge.RunEvents = deleted.RowsAffected
+
+		deleted = tx.Where("app_name = ? AND created_at < ?", purge.AppName, purge.Before).Delete(&models.EmailDelivery{})
+		if deleted.Error != nil {
+			return fmt.Errorf("failed to delete email deliveries: %w", deleted.Error)
+		}
+		purge.EmailDeliveries = deleted.RowsAffected
+
+		deleted = tx.Where("app_name = ? AND created_at < ?", purge.AppName, purge.Before).Delete(&models.CachedAIAnalysis{})
+		if deleted.Error != nil {
+			return fmt.Errorf("failed to delete cached AI analyses: %w", deleted.Error)
+		}
+		purge.AIAnalyses = deleted.RowsAffected
+
+		deleted = tx.Where("app_name = ? AND created_at < ?", purge.AppName, purge.Before).Delete(&models.ActivityLog{})
+		if deleted.Error != nil {
+			return fmt.Errorf("failed to delete activity log entries: %w", deleted.Error)
+		}
+		purge.Activities = deleted.RowsAffected
+
+		// Queued and running jobs are left to finish
+		finished := []string{models.JobSucceeded, models.JobFailed}
+		deleted = tx.Where("app_name = ? AND created_at < ? AND status IN ?", purge.AppName, purge.Before, finished).Delete(&models.Job{})
+		if deleted.Error != nil {
+			return fmt.Errorf("failed to delete jobs: %w", deleted.Error)
+		}
+		purge.Jobs = deleted.RowsAffected
+		err = tx.Model(&models.Job{}).
+			Where("app_name = ? AND created_at < ? AND status NOT IN ?", purge.AppName, purge.Before, finished).
+			Count(&purge.KeptJobs).Error
+		if err != nil {
+			return fmt.Errorf("failed to query jobs: %w", err)
+		}
+
+		// The package inventory, triage decisions, email suppressions and notification
+		// state still apply to the latest results, kept for retention
+		var app models.App
+		if err := tx.Where("name = ?", purge.AppName).Limit(1).Find(&app).Error; err != nil {
+			return fmt.Errorf("failed to query app: %w", err)
+		}
+		if purge.KeepLatest {
+			purge.KeptNotificationState = app.HasNotificationState()
+		} else {
+			deleted = tx.Where("app_name = ? AND created_at < ?", purge.AppName, purge.Before).Delete(&models.AppPackage{})
+			if deleted.Error != nil {
+				return fmt.Errorf("failed to delete app packages: %w", deleted.Error)
+			}
+			purge.AppPackages = deleted.RowsAffected
+
+			deleted = tx.Where("app_name = ? AND updated_at < ?", purge.AppName, purge.Before).Delete(&models.FindingTriage{})
+			if deleted.Error != nil {
+				return fmt.Errorf("failed to delete finding triages: %w", deleted.Error)
+			}
+			purge.Triages = deleted.RowsAffected
+
+			deleted = tx.Where("app_name = ? AND created_at < ?", purge.AppName, purge.Before).Delete(&models.EmailSuppression{})
+			if deleted.Error != nil {
+				return fmt.Errorf("failed to delete email suppressions: %w", deleted.Error)
+			}
+			purge.EmailSuppressions = deleted.RowsAffected
+
+			if app.LastNotifiedAt != nil && !app.LastNotifiedAt.Before(purge.Before) {
+				purge.KeptNotificationState = true
+			} else if app.HasNotificationState() {
+				err := tx.Model(&models.App{}).Where("id = ?", app.ID).Updates(map[string]any{
+					"telegram_topic_id":   0,
+					"discord_thread_id":   "",
+					"pagerduty_dedup_key": "",
+					"opsgenie_aliases":    models.StringArray(nil),
+					"gitlab_issue":        "",
+					"last_notified_at":    nil,
+					"last_notify_failed":  models.StringArray(nil),
+				}).Error
+				if err != nil {
+					return fmt.Errorf("failed to clear notification state: %w", err)
+				}
+				purge.NotificationState = true
+			}
+		}
+
+		if purge.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return files, nil
+}
+
+// CachedAIAnalysis returns the cached AI analysis stored under key, or nil if there is none
+func (s *GormStore) CachedAIAnalysis(key string) (*models.CachedAIAnalysis, error) {
+	var entry models.CachedAIAnalysis
+	err := s.db.Where("key = ?", key).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// SaveCachedAIAnalysis creates or replaces a cached AI analysis
+func (s *GormStore) SaveCachedAIAnalysis(entry *models.CachedAIAnalysis) error {
+	return s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(entry).Error
+}
+
+// PurgeCachedAIAnalyses deletes cached AI analyses created before cutoff
+func (s *GormStore) PurgeCachedAIAnalyses(cutoff time.Time) error {
+	return s.db.Where("created_at < ?", cutoff).Delete(&models.CachedAIAnalysis{}).Error
+}
+
+// SaveActivity stores an activity log entry
+func (s *GormStore) SaveActivity(entry *models.ActivityLog) error {
+	return s.db.Create(entry).Error
+}
+
+// Setting returns a stored setting
+func (s *GormStore) Setting(key string) (string, bool, error) {
+	var setting models.Setting
+	err := s.db.Where("key = ?", key).First(&setting).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return setting.Value, true, nil
+}
+
+// SaveAppPackages replaces the packages recorded for an app
+func (s *GormStore) SaveAppPackages(appName string, packages []models.AppPackage) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("app_name = ?", appName).Delete(&models.AppPackage{}).Error; err != nil {
+			return err
+		}
+		if len(packages) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(packages, 500).Error
+	})
+}
+
+// AppPackages returns the recorded packages of an ecosystem with one of the given names, across all apps
+func (s *GormStore) AppPackages(ecosystem string, names []string) ([]models.AppPackage, error) {
+	var packages []models.AppPackage
+	// Query in chunks to stay below SQLite's limit on query parameters
+	for chunk := range slices.Chunk(names, 500) {
+		var found []models.AppPackage
+		if err := s.db.Where("ecosystem = ? AND name IN ?", ecosystem, chunk).Find(&found).Error; err != nil {
+			return nil, err
+		}
+		packages = append(packages, found...)
+	}
+	return packages, nil
+}
+
+// SaveEmailDelivery creates or replaces an email delivery with its status
+func (s *GormStore) SaveEmailDelivery(delivery *models.EmailDelivery) error {
+	return s.db.Save(delivery).Error
+}
+
+// EmailSuppressions returns the email addresses no longer sent to
+func (s *GormStore) EmailSuppressions() ([]models.EmailSuppression, error) {
+	var suppressions []models.EmailSuppression
+	err := s.db.Order("address").Find(&suppressions).Error
+	return suppressions, err
+}
+
+// FindingTriages returns the triage decisions made on the findings of an app
+func (s *GormStore) FindingTriages(appName string) ([]models.FindingTriage, error) {
+	var triages []models.FindingTriage
+	err := s.db.Where("app_name = ?", appName).Find(&triages).Error
+	return triages, err
+}
+
+// SaveSetting creates or replaces a setting
+func (s *GormStore) SaveSetting(key, value string) error {
+	setting := models.Setting{Key: key, Value: value}
+	return s.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&setting).Error
+}
+
+// Close closes the database connection
+func (s *GormStore) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// Store defines the interface for persisting apps, audit results and run history.
+// The application only talks to a Store, so the SQLite database can be replaced,
+// e.g. by a service collecting results centrally or an in-memory store in tests.
+type Store interface {
+	// Apps returns every configured app
+	Apps() ([]models.App, error)
+
+	// ResumeExpiredPauses re-enables apps whose pause ended before now and returns how many were resumed
+	ResumeExpiredPauses(now time.Time) (int64, error)
+
+	// SaveTelegramTopicID stores the Telegram forum topic created for an app
+	SaveTelegramTopicID(appName string, topicID int) error
+
+	// SaveDiscordThreadID stores the Discord thread created for an app
+	SaveDiscordThreadID(appName, threadID string) error
+
+	// SavePagerDutyDedupKey stores the deduplication key of an app's open PagerDuty incident ("" when resolved)
+	SavePagerDutyDedupKey(appName, dedupKey string) error
+
+	// SaveOpsgenieAliases stores the aliases of an app's open Opsgenie alerts
+	SaveOpsgenieAliases(appName string, aliases []string) error
+
+	// SaveGitLabIssue stores the reference (<project>#<iid>) of an app's open GitLab issue ("" when closed)
+	SaveGitLabIssue(appName, issue string) error
+
+	// SaveNotificationStatus stores when an app was last notified and the channels that failed
+	SaveNotificationStatus(appName string, at time.Time, failed []string) error
+
+	// SaveAuditResult stores an audit result with its vulnerabilities, assigning its ID
+	SaveAuditResult(result *models.AuditResult) error
+
+	// AuditResults returns every audit result, oldest first, without vulnerabilities or raw output
+	AuditResults() ([]models.AuditResult, error)
+
+	// RecentAuditResults returns up to limit audit results of an app, newest first,
+	// without vulnerabilities or raw output
+	RecentAuditResults(appName string, limit int) ([]models.AuditResult, error)
+
+	// Vulnerabilities returns the vulnerabilities of the given audit results
+	Vulnerabilities(resultIDs []string) ([]models.Vulnerability, error)
+
+	// VulnerabilityHistory returns every vulnerability ever reported, with only
+	// the fields identifying a finding (result ID, package, CVE and title)
+	VulnerabilityHistory() ([]models.Vulnerability, error)
+
+	// FindingsFirstSeen returns when each finding ever reported by an auditor for an app
+	// was first seen, by models.Vulnerability.FindingKey
+	FindingsFirstSeen(appName, auditorType string) (map[string]time.Time, error)
+
+	// VulnerabilitiesFirstSeenSince returns the vulnerabilities of every audit result
+	// whose finding was first seen at or after since, including those of the later
+	// audits that reported it again
+	VulnerabilitiesFirstSeenSince(since time.Time) ([]models.Vulnerability, error)
+
+	// SaveRunEvent stores a progress event of a run
+	SaveRunEvent(event *models.RunEvent) error
+
+	// PurgeRunEvents deletes progress events created before cutoff
+	PurgeRunEvents(cutoff time.Time) error
+
+	// PurgeAppData deletes the history of an app created before purge.Before (audit
+	// results with their vulnerabilities and raw outputs, run events, email deliveries,
+	// cached AI analyses, activity log entries, finished jobs and, unless
+	// purge.KeepLatest, its package inventory, triage decisions, email suppressions and
+	// notification state), counting it in purge, or only counts it with purge.DryRun. Returns the run
+	// log and raw output files of the deleted results no remaining result refers to. The
+	// run logs only other apps' results refer to are listed in purge.RedactedFiles, for
+	// the app's lines to be removed; the other files in purge.KeptFiles.
+	PurgeAppData(purge *models.DataPurge) ([]string, error)
+
+	// CachedAIAnalysis returns the cached AI analysis stored under key, or nil if there is none
+	CachedAIAnalysis(key string) (*models.CachedAIAnalysis, error)
+
+	// SaveCachedAIAnalysis creates or replaces a cached AI analysis
+	SaveCachedAIAnalysis(entry *models.CachedAIAnalysis) error
+
+	// PurgeCachedAIAnalyses deletes cached AI analyses created before cutoff
+	PurgeCachedAIAnalyses(cutoff time.Time) error
+
+	// SaveActivity stores an activity log entry
+	SaveActivity(entry *models.ActivityLog) error
+
+	// SaveAppPackages replaces the packages recorded for an app
+	SaveAppPackages(appName string, packages []models.AppPackage) error
+
+	// AppPackages returns the recorded packages of an ecosystem with one of the given names, across all apps
+	AppPackages(ecosystem string, names []string) ([]models.AppPackage, error)
+
+	// SaveEmailDelivery creates or replaces an email delivery with its status
+	SaveEmailDelivery(delivery *models.EmailDelivery) error
+
+	// EmailSuppressions returns the email addresses no longer sent to
+	EmailSuppressions() ([]models.EmailSuppression, error)
+
+	// FindingTriages returns the triage decisions made on the findings of an app
+	FindingTriages(appName string) ([]models.FindingTriage, error)
+
+	// Setting returns a stored setting; ok is false when it is not set
+	Setting(key string) (value string, ok bool, err error)
+
+	// SaveSetting creates or replaces a setting
+	SaveSetting(key, value string) error
+
+	// Close releases the store's resources
+	Close() error
+}
@@ -0,0 +1,214 @@
+package store
+
+import (
+	"fmt"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// metricsView is a read-only view for dashboards (e.g. Grafana's SQLite data source),
+// so they don't query the GORM tables directly
+type metricsView struct {
+	Name  string
+	Query string
+}
+
+// metricsViews are created in order, as later views select from earlier ones. Times
+// are compared through julianday(), as stored timestamps may carry different offsets;
+// days and datetimes are UTC.
+var metricsViews = []metricsView{
+	{
+		// Latest result of every auditor of every configured app
+		Name: "metrics_latest_results",
+		Query: `
+SELECT id, app_name, auditor_type, total_vulnerabilities, critical_count, high_count,
+       moderate_count, low_count, info_count, datetime(created_at) AS created_at
+FROM (
+    SELECT r.*, ROW_NUMBER() OVER (
+        PARTITION BY r.app_name, r.auditor_type
+        ORDER BY julianday(r.created_at) DESC, r.id DESC
+    ) AS rn
+    FROM audit_results r
+    JOIN apps a ON a.name = r.app_name
+)
+WHERE rn = 1`,
+	},
+	{
+		// Audits per app and day, with the vulnerabilities of the day's last result of each auditor
+		Name: "metrics_daily_app_counts",
+		Query: `
+SELECT day, app_name,
+       COUNT(*) AS audits,
+       SUM(CASE WHEN rn = 1 THEN total_vulnerabilities ELSE 0 END) AS total,
+       SUM(CASE WHEN rn = 1 THEN critical_count ELSE 0 END) AS critical,
+       SUM(CASE WHEN rn = 1 THEN high_count ELSE 0 END) AS high,
+       SUM(CASE WHEN rn = 1 THEN moderate_count ELSE 0 END) AS moderate,
+       SUM(CASE WHEN rn = 1 THEN low_count ELSE 0 END) AS low,
+       SUM(CASE WHEN rn = 1 THEN info_count ELSE 0 END) AS info
+FROM (
+    SELECT r.*, date(r.created_at) AS day, ROW_NUMBER() OVER (
+        PARTITION BY r.app_name, r.auditor_type, date(r.created_at)
+        ORDER BY julianday(r.created_at) DESC, r.id DESC
+    ) AS rn
+    FROM audit_results r
+)
+GROUP BY day, app_name`,
+	},
+	{
+		// Open vulnerabilities per app and severity, from the latest results
+		Name: "metrics_open_by_severity",
+		Query: `
+SELECT l.app_name, v.severity, COUNT(*) AS open
+FROM metrics_latest_results l
+JOIN vulnerabilities v ON v.audit_result_id = l.id
+GROUP BY l.app_name, v.severity`,
+	},
+	{
+		// Every finding (app, auditor, package and CVE, or title without one) with its first
+		// and last sighting, and the first later audit of the same auditor that no longer
+		// reported it. Unresolved findings have a NULL resolved_at.
+		Name: "metrics_findings",
+		Query: `
+SELECT app_name, auditor_type, package_name, finding_id, severity,
+       datetime(first_seen) AS first_seen,
+       datetime(last_seen) AS last_seen,
+       datetime(resolved_at) AS resolved_at,
+       ROUND(resolved_at - first_seen, 2) AS resolve_days
+FROM (
+    SELECT s.*, (
+        SELECT MIN(julianday(n.created_at))
+        FROM audit_results n
+        WHERE n.app_name = s.app_name AND n.auditor_type = s.auditor_type
+          AND julianday(n.created_at) > s.last_seen
+    ) AS resolved_at
+    FROM (
+        SELECT r.app_name, r.auditor_type, v.package_name,
+               COALESCE(NULLIF(v.cve_id, ''), v.title) AS finding_id,
+               v.severity,
+               julianday(r.created_at) AS last_seen,
+               MIN(julianday(r.created_at)) OVER finding AS first_seen,
+               ROW_NUMBER() OVER (finding ORDER BY julianday(r.created_at) DESC, r.id DESC) AS rn
+        FROM vulnerabilities v
+        JOIN audit_results r ON r.id = v.audit_result_id
+        WINDOW finding AS (PARTITION BY r.app_name, r.auditor_type, v.package_name, COALESCE(NULLIF(v.cve_id, ''), v.title))
+    ) s
+    WHERE s.rn = 1
+)`,
+	},
+	{
+		// Mean time to remediate per app and severity, in days, over resolved findings
+		Name: "metrics_mttr",
+		Query: `
+SELECT app_name, severity, COUNT(*) AS resolved, ROUND(AVG(resolve_days), 2) AS mttr_days
+FROM metrics_findings
+WHERE resolved_at IS NOT NULL
+GROUP BY app_name, severity`,
+	},
+	{
+		// Gemini analyses per day, model and prompt version, with their latency and tokens;
+		// cached analyses are counted but cost neither
+		Name: "metrics_ai_analyses",
+		Query: `
+SELECT date(created_at) AS day, ai_provider AS provider, ai_model AS model,
+       ai_prompt_version AS prompt_version,
+       COUNT(*) AS analyses,
+       SUM(CASE WHEN ai_fallback THEN 1 ELSE 0 END) AS fallbacks,
+       SUM(CASE WHEN ai_cached THEN 1 ELSE 0 END) AS cached,
+       ROUND(AVG(CASE WHEN ai_cached THEN NULL ELSE ai_latency_ms END)) AS avg_latency_ms,
+       MAX(ai_latency_ms) AS max_latency_ms,
+       SUM(ai_prompt_tokens) AS prompt_tokens,
+       SUM(ai_output_tokens) AS output_tokens
+FROM audit_results
+WHERE ai_provider IS NOT NULL AND ai_provider != ''
+GROUP BY day, provider, model, prompt_version`,
+	},
+}
+
+// Migrate migrates the tables of every model and recreates the metrics views, so their
+// definitions follow the tables on upgrades
+func Migrate(db *gorm.DB) error {
+	hadInfoCount := db.Migrator().HasColumn(&models.AuditResult{}, "InfoCount")
+	hadRawOutputColumn := db.Migrator().HasColumn(&models.AuditResult{}, "raw_output")
+
+	if err := db.AutoMigrate(models.AllModels()...); err != nil {
+		return err
+	}
+
+	// Results saved before info findings were counted get their count from their vulnerabilities
+	if !hadInfoCount {
+		err := db.Exec(`UPDATE audit_results SET info_count = (
+    SELECT COUNT(*) FROM vulnerabilities v WHERE v.audit_result_id = audit_results.id AND v.severity = ?
+)`, models.SeverityInfo).Error
+		if err != nil {
+			return fmt.Errorf("failed to count info findings of earlier results: %w", err)
+		}
+	}
+
+	// Raw outputs saved with their results are stored once per content
+	if hadRawOutputColumn {
+		if err := moveRawOutputs(db); err != nil {
+			return fmt.Errorf("failed to move raw outputs to raw_outputs: %w", err)
+		}
+	}
+
+	// Drop in reverse order, as views depend on the earlier ones
+	for i := len(metricsViews) - 1; i >= 0; i-- {
+		if err := db.Exec("DROP VIEW IF EXISTS " + metricsViews[i].Name).Error; err != nil {
+			return fmt.Errorf("failed to drop view %s: %w", metricsViews[i].Name, err)
+		}
+	}
+	for _, view := range metricsViews {
+		if err := db.Exec("CREATE VIEW " + view.Name + " AS" + view.Query).Error; err != nil {
+			return fmt.Errorf("failed to create view %s: %w", view.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// moveRawOutputs moves the raw outputs of audit_results to raw_outputs, one copy per
+// content, drops their column and reclaims its space
+func moveRawOutputs(db *gorm.DB) error {
+	type row struct {
+		ID        string
+		RawOutput string
+	}
+
+	for {
+		var rows []row
+		err := db.Table("audit_results").Select("id", "raw_output").
+			Where("raw_output IS NOT NULL AND raw_output != ''").
+			Limit(100).Find(&rows).Error
+		if err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			break
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			for _, r := range rows {
+				raw := models.RawOutput{Hash: models.RawOutputHash(r.RawOutput), Content: r.RawOutput, Size: len(r.RawOutput)}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&raw).Error; err != nil {
+					return err
+				}
+				err := tx.Table("audit_results").Where("id = ?", r.ID).
+					Updates(map[string]any{"raw_output_hash": raw.Hash, "raw_output": ""}).Error
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := db.Migrator().DropColumn(&models.AuditResult{}, "raw_output"); err != nil {
+		return err
+	}
+	return db.Exec("VACUUM").Error
+}
@@ -0,0 +1,60 @@
+package testharness
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// FakeAuditor implements the auditor.Auditor interface with a canned result or error.
+// Register it under the name of a real auditor to replace it, or under a new name
+// (used as the app type) to test how the application handles its results.
+type FakeAuditor struct {
+	AuditorName string              // returned by Name
+	Detects     bool                // returned by Detect
+	Result      *models.AuditResult // returned by Audit (a copy, with the app's name and path set)
+	Err         error               // returned by Audit instead of Result when set
+
+	calls []models.AppConfig
+	mu    sync.Mutex
+}
+
+// Name returns AuditorName
+func (a *FakeAuditor) Name() string {
+	return a.AuditorName
+}
+
+// Detect returns Detects
+func (a *FakeAuditor) Detect(path string) bool {
+	return a.Detects
+}
+
+// Audit records the call and returns Err, or a copy of Result
+func (a *FakeAuditor) Audit(ctx context.Context, app models.AppConfig) (*models.AuditResult, error) {
+	a.mu.Lock()
+	a.calls = append(a.calls, app)
+	a.mu.Unlock()
+
+	if a.Err != nil {
+		return nil, a.Err
+	}
+
+	result := &models.AuditResult{}
+	if a.Result != nil {
+		*result = *a.Result
+		result.Vulnerabilities = append([]models.Vulnerability(nil), a.Result.Vulnerabilities...)
+	}
+	result.AuditorType = a.AuditorName
+	result.AppName = app.Name
+	result.AppPath = app.Path
+	result.UpdateCounts()
+	return result, nil
+}
+
+// Calls returns the apps Audit was called with, in order
+func (a *FakeAuditor) Calls() []models.AppConfig {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]models.AppConfig(nil), a.calls...)
+}
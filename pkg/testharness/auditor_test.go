@@ -0,0 +1,37 @@
+package testharness_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestFakeAuditor(t *testing.T) {
+	ctx := context.Background()
+	auditor := &testharness.FakeAuditor{AuditorName: "npm", Detects: true, Result: criticalResult()}
+
+	result, err := auditor.Audit(ctx, models.AppConfig{Name: "shop", Path: "/srv/shop"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.AppName != "shop" || result.AppPath != "/srv/shop" || result.AuditorType != "npm" || result.CriticalCount != 1 || result.TotalVulnerabilities != 2 {
+		t.Errorf("result = %+v, want shop's npm result with its counts", result)
+	}
+
+	// The result is a copy: changing it leaves the canned result alone
+	result.Vulnerabilities[0].Severity = models.SeverityLow
+	if auditor.Result.Vulnerabilities[0].Severity != models.SeverityCritical {
+		t.Error("Audit returned the canned vulnerabilities rather than a copy")
+	}
+
+	auditor.Err = errors.New("npm not found in PATH")
+	if _, err := auditor.Audit(ctx, models.AppConfig{Name: "blog"}); err != auditor.Err {
+		t.Errorf("Audit error = %v, want %v", err, auditor.Err)
+	}
+	if calls := auditor.Calls(); len(calls) != 2 || calls[0].Name != "shop" || calls[1].Name != "blog" {
+		t.Errorf("calls = %+v, want shop then blog", calls)
+	}
+}
@@ -0,0 +1,189 @@
+package testharness
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/exporter"
+)
+
+// Credentials the mock Confluence server accepts
+const (
+	MockConfluenceUser     = "audit@example.com"
+	MockConfluenceAPIToken = "confluence-testharness"
+	MockConfluenceSpace    = "SEC"
+)
+
+// ConfluencePage is a page of the mock Confluence server
+type ConfluencePage struct {
+	ID       string
+	Space    string
+	Title    string
+	ParentID string // of the first ancestor given when the page was created
+	Version  int    // 1 when created, incremented by each update
+	Body     string // storage format
+}
+
+// MockConfluence is a Confluence content API server keeping the pages created and
+// updated through it
+type MockConfluence struct {
+	Server *httptest.Server
+
+	pages      []ConfluencePage
+	failStatus int
+	mu         sync.Mutex
+}
+
+// NewMockConfluence starts a mock Confluence server, stopped when the test ends
+func NewMockConfluence(t testing.TB) *MockConfluence {
+	t.Helper()
+
+	m := &MockConfluence{}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Exporter returns a Confluence exporter publishing "Security audit: {app}" pages to
+// the SEC space, under the page parentPageID when not empty
+func (m *MockConfluence) Exporter(parentPageID string) *exporter.ConfluenceExporter {
+	return exporter.NewConfluenceExporter(m.Server.URL, MockConfluenceUser, MockConfluenceAPIToken,
+		MockConfluenceSpace, parentPageID, "Security audit: {app}")
+}
+
+// Fail makes every following request fail with status
+func (m *MockConfluence) Fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus = status
+}
+
+// Pages returns the pages, in the order they were created
+func (m *MockConfluence) Pages() []ConfluencePage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ConfluencePage(nil), m.pages...)
+}
+
+// confluenceRequest is the body of a page creation or update
+type confluenceRequest struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Space struct {
+		Key string `json:"key"`
+	} `json:"space"`
+	Ancestors []struct {
+		ID string `json:"id"`
+	} `json:"ancestors"`
+	Version *struct {
+		Number int `json:"number"`
+	} `json:"version"`
+	Body struct {
+		Storage struct {
+			Value          string `json:"value"`
+			Representation string `json:"representation"`
+		} `json:"storage"`
+	} `json:"body"`
+}
+
+// handle answers GET and POST /rest/api/content and PUT /rest/api/content/{id}
+func (m *MockConfluence) handle(w http.ResponseWriter, r *http.Request) {
+	if user, token, ok := r.BasicAuth(); !ok || user != MockConfluenceUser || token != MockConfluenceAPIToken {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failStatus != 0 {
+		http.Error(w, "testharness failure", m.failStatus)
+		return
+	}
+
+	id, hasID := strings.CutPrefix(r.URL.Path, "/rest/api/content/")
+	switch {
+	case r.URL.Path == "/rest/api/content" && r.Method == http.MethodGet:
+		m.search(w, r)
+	case r.URL.Path == "/rest/api/content" && r.Method == http.MethodPost:
+		m.save(w, r, "")
+	case hasID && r.Method == http.MethodPut:
+		m.save(w, r, id)
+	default:
+		http.Error(w, "Not Found", http.StatusNotFound)
+	}
+}
+
+// search answers the lookup of a page by space and title
+func (m *MockConfluence) search(w http.ResponseWriter, r *http.Request) {
+	type result struct {
+		ID      string `json:"id"`
+		Title   string `json:"title"`
+		Version struct {
+			Number int `json:"number"`
+		} `json:"version"`
+	}
+	results := []result{}
+	for _, page := range m.pages {
+		if page.Space == r.URL.Query().Get("spaceKey") && page.Title == r.URL.Query().Get("title") {
+			found := result{ID: page.ID, Title: page.Title}
+			found.Version.Number = page.Version
+			results = append(results, found)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"results": results, "size": len(results)})
+}
+
+// save creates a page, or updates the page id, requiring the next version number
+func (m *MockConfluence) save(w http.ResponseWriter, r *http.Request, id string) {
+	var request confluenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.Type != "page" || request.Title == "" || request.Space.Key == "" || request.Body.Storage.Representation != "storage" {
+		http.Error(w, "type, title, space and a storage body are required", http.StatusBadRequest)
+		return
+	}
+
+	if id == "" {
+		page := ConfluencePage{
+			ID:      strconv.Itoa(100000 + len(m.pages)),
+			Space:   request.Space.Key,
+			Title:   request.Title,
+			Version: 1,
+			Body:    request.Body.Storage.Value,
+		}
+		if len(request.Ancestors) > 0 {
+			page.ParentID = request.Ancestors[0].ID
+		}
+		m.pages = append(m.pages, page)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": page.ID})
+		return
+	}
+
+	for i := range m.pages {
+		if m.pages[i].ID != id {
+			continue
+		}
+		if request.Version == nil || request.Version.Number != m.pages[i].Version+1 {
+			http.Error(w, "Version must be incremented on update", http.StatusConflict)
+			return
+		}
+		m.pages[i].Title = request.Title
+		m.pages[i].Version = request.Version.Number
+		m.pages[i].Body = request.Body.Storage.Value
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": id})
+		return
+	}
+	http.Error(w, "No content found with id "+id, http.StatusNotFound)
+}
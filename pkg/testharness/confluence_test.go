@@ -0,0 +1,45 @@
+package testharness_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestMockConfluence(t *testing.T) {
+	ctx := context.Background()
+	confluence := testharness.NewMockConfluence(t)
+	e := confluence.Exporter("1000")
+
+	page, err := e.Publish(ctx, "shop", []byte("<p>2 vulnerabilities</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !page.Created || page.Version != 1 || page.Title != "Security audit: shop" {
+		t.Errorf("page = %+v, want the page of shop created", page)
+	}
+
+	// The page is found by title and updated
+	updated, err := e.Publish(ctx, "shop", []byte("<p>no vulnerabilities</p>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Created || updated.ID != page.ID || updated.Version != 2 {
+		t.Errorf("page = %+v, want version 2 of %s", updated, page.ID)
+	}
+
+	pages := confluence.Pages()
+	if len(pages) != 1 {
+		t.Fatalf("pages = %+v, want 1", pages)
+	}
+	if p := pages[0]; p.Space != testharness.MockConfluenceSpace || p.ParentID != "1000" || p.Version != 2 || p.Body != "<p>no vulnerabilities</p>" {
+		t.Errorf("page = %+v, want the updated page under 1000", p)
+	}
+
+	confluence.Fail(http.StatusUnauthorized)
+	if _, err := e.Publish(ctx, "shop", []byte("<p></p>")); err == nil {
+		t.Error("Publish succeeded with a failing Confluence")
+	}
+}
@@ -0,0 +1,152 @@
+package testharness
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/exporter"
+)
+
+// MockDefectDojoAPIKey is the API key the mock DefectDojo server accepts
+const MockDefectDojoAPIKey = "defectdojo-testharness"
+
+// DefectDojoScan is a scan imported into the mock DefectDojo server
+type DefectDojoScan struct {
+	Fields   map[string]string   // form fields, e.g. product_name, engagement_name, service
+	Findings []DefectDojoFinding // of the uploaded Generic Findings Import report
+}
+
+// DefectDojoFinding is a finding of an imported scan
+type DefectDojoFinding struct {
+	Title            string `json:"title"`
+	Severity         string `json:"severity"`
+	CVE              string `json:"cve"`
+	ComponentName    string `json:"component_name"`
+	Mitigation       string `json:"mitigation"`
+	UniqueIDFromTool string `json:"unique_id_from_tool"`
+	Date             string `json:"date"`
+}
+
+// MockDefectDojo is a DefectDojo import-scan API server recording the imported scans.
+// Products and engagements are numbered by name, as auto_create_context creates them.
+type MockDefectDojo struct {
+	Server *httptest.Server
+
+	scans       []DefectDojoScan
+	products    map[string]int
+	engagements map[string]int
+	failStatus  int
+	mu          sync.Mutex
+}
+
+// NewMockDefectDojo starts a mock DefectDojo server, stopped when the test ends
+func NewMockDefectDojo(t testing.TB) *MockDefectDojo {
+	t.Helper()
+
+	m := &MockDefectDojo{products: make(map[string]int), engagements: make(map[string]int)}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Exporter returns a DefectDojo exporter importing into the audit-checks engagement of
+// products of type audit-checks
+func (m *MockDefectDojo) Exporter() *exporter.DefectDojoExporter {
+	return exporter.NewDefectDojoExporter(m.Server.URL, MockDefectDojoAPIKey, "audit-checks", "audit-checks")
+}
+
+// Fail makes every following import fail with status
+func (m *MockDefectDojo) Fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus = status
+}
+
+// Scans returns the imported scans, in order
+func (m *MockDefectDojo) Scans() []DefectDojoScan {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]DefectDojoScan(nil), m.scans...)
+}
+
+// handle answers POST /api/v2/import-scan/
+func (m *MockDefectDojo) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost || r.URL.Path != "/api/v2/import-scan/" {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string]string{"detail": "Not found."})
+		return
+	}
+	if r.Header.Get("Authorization") != "Token "+MockDefectDojoAPIKey {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"detail": "Invalid token."})
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failStatus != 0 {
+		w.WriteHeader(m.failStatus)
+		_ = json.NewEncoder(w).Encode(map[string]string{"detail": "testharness failure"})
+		return
+	}
+
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		writeDefectDojoError(w, "file", "multipart form expected")
+		return
+	}
+	scan := DefectDojoScan{Fields: make(map[string]string)}
+	for name, values := range r.MultipartForm.Value {
+		scan.Fields[name] = values[0]
+	}
+	for _, required := range []string{"scan_type", "product_name", "engagement_name"} {
+		if scan.Fields[required] == "" {
+			writeDefectDojoError(w, required, "This field is required.")
+			return
+		}
+	}
+
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		writeDefectDojoError(w, "file", "No file was submitted.")
+		return
+	}
+	defer file.Close()
+	var report struct {
+		Findings []DefectDojoFinding `json:"findings"`
+	}
+	if err := json.NewDecoder(file).Decode(&report); err != nil {
+		writeDefectDojoError(w, "file", "Invalid Generic Findings Import report: "+err.Error())
+		return
+	}
+	scan.Findings = report.Findings
+	m.scans = append(m.scans, scan)
+
+	product := scan.Fields["product_name"]
+	if _, ok := m.products[product]; !ok {
+		m.products[product] = len(m.products) + 1
+	}
+	engagement := product + "\x00" + scan.Fields["engagement_name"]
+	if _, ok := m.engagements[engagement]; !ok {
+		m.engagements[engagement] = len(m.engagements) + 1
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"scan_type":     scan.Fields["scan_type"],
+		"product_id":    m.products[product],
+		"engagement_id": m.engagements[engagement],
+		"test_id":       len(m.scans),
+	})
+}
+
+// writeDefectDojoError writes a validation error as the DefectDojo API does
+func writeDefectDojoError(w http.ResponseWriter, field, message string) {
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string][]string{field: {message}})
+}
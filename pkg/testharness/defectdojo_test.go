@@ -0,0 +1,42 @@
+package testharness_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestMockDefectDojo(t *testing.T) {
+	ctx := context.Background()
+	dd := testharness.NewMockDefectDojo(t)
+	e := dd.Exporter()
+
+	result := criticalReport().Reports[0].AuditResult
+	first, err := e.Export(ctx, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := e.Export(ctx, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ProductID != 1 || second.ProductID != first.ProductID || second.EngagementID != first.EngagementID || second.TestID != 2 {
+		t.Errorf("imports = %+v, %+v, want two tests of the same product and engagement", first, second)
+	}
+
+	scans := dd.Scans()
+	if len(scans) != 2 || scans[0].Fields["product_name"] != "shop" || scans[0].Fields["engagement_name"] != "audit-checks" {
+		t.Fatalf("scans = %+v, want shop's imports into the audit-checks engagement", scans)
+	}
+	findings := scans[0].Findings
+	if len(findings) != 2 || findings[0].CVE != "CVE-2021-23337" || findings[0].Severity != "Critical" || findings[0].ComponentName != "lodash" {
+		t.Errorf("findings = %+v, want the critical lodash finding first", findings)
+	}
+
+	dd.Fail(http.StatusBadRequest)
+	if _, err := e.Export(ctx, result); err == nil {
+		t.Error("Export succeeded with a failing DefectDojo")
+	}
+}
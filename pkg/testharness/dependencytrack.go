@@ -0,0 +1,151 @@
+package testharness
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/exporter"
+)
+
+// MockDependencyTrackAPIKey is the API key the mock Dependency-Track server accepts
+const MockDependencyTrackAPIKey = "dependencytrack-testharness"
+
+// DependencyTrackUpload is a BOM uploaded to the mock Dependency-Track server
+type DependencyTrackUpload struct {
+	ProjectName    string
+	ProjectVersion string
+	ProjectTags    []string
+	AutoCreate     bool
+	IsLatest       bool
+	BOM            DependencyTrackBOM // decoded from base64
+}
+
+// DependencyTrackBOM is the part of an uploaded CycloneDX BOM tests look at
+type DependencyTrackBOM struct {
+	BOMFormat   string `json:"bomFormat"`
+	SpecVersion string `json:"specVersion"`
+	Metadata    struct {
+		Component DependencyTrackComponent `json:"component"`
+	} `json:"metadata"`
+	Components   []DependencyTrackComponent `json:"components"`
+	Dependencies []struct {
+		Ref       string   `json:"ref"`
+		DependsOn []string `json:"dependsOn"`
+	} `json:"dependencies"`
+}
+
+// DependencyTrackComponent is a component of an uploaded BOM
+type DependencyTrackComponent struct {
+	BOMRef  string `json:"bom-ref"`
+	Group   string `json:"group"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Scope   string `json:"scope"`
+	PURL    string `json:"purl"`
+}
+
+// MockDependencyTrack is a Dependency-Track BOM upload API server recording the uploads
+type MockDependencyTrack struct {
+	Server *httptest.Server
+
+	uploads    []DependencyTrackUpload
+	failStatus int
+	mu         sync.Mutex
+}
+
+// NewMockDependencyTrack starts a mock Dependency-Track server, stopped when the test ends
+func NewMockDependencyTrack(t testing.TB) *MockDependencyTrack {
+	t.Helper()
+
+	m := &MockDependencyTrack{}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Exporter returns a Dependency-Track exporter tagging the projects it creates with audit-checks
+func (m *MockDependencyTrack) Exporter() *exporter.DependencyTrackExporter {
+	return exporter.NewDependencyTrackExporter(m.Server.URL, MockDependencyTrackAPIKey, []string{"audit-checks"})
+}
+
+// Fail makes every following upload fail with status
+func (m *MockDependencyTrack) Fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus = status
+}
+
+// Uploads returns the uploaded BOMs, in order
+func (m *MockDependencyTrack) Uploads() []DependencyTrackUpload {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]DependencyTrackUpload(nil), m.uploads...)
+}
+
+// handle answers PUT /api/v1/bom
+func (m *MockDependencyTrack) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut || r.URL.Path != "/api/v1/bom" {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	if r.Header.Get("X-Api-Key") != MockDependencyTrackAPIKey {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failStatus != 0 {
+		http.Error(w, "testharness failure", m.failStatus)
+		return
+	}
+
+	var request struct {
+		ProjectName    string `json:"projectName"`
+		ProjectVersion string `json:"projectVersion"`
+		ProjectTags    []struct {
+			Name string `json:"name"`
+		} `json:"projectTags"`
+		AutoCreate bool   `json:"autoCreate"`
+		IsLatest   bool   `json:"isLatest"`
+		BOM        string `json:"bom"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if request.ProjectName == "" || request.ProjectVersion == "" {
+		http.Error(w, "projectName and projectVersion are required", http.StatusBadRequest)
+		return
+	}
+
+	upload := DependencyTrackUpload{
+		ProjectName:    request.ProjectName,
+		ProjectVersion: request.ProjectVersion,
+		AutoCreate:     request.AutoCreate,
+		IsLatest:       request.IsLatest,
+	}
+	for _, tag := range request.ProjectTags {
+		upload.ProjectTags = append(upload.ProjectTags, tag.Name)
+	}
+	content, err := base64.StdEncoding.DecodeString(request.BOM)
+	if err == nil {
+		err = json.Unmarshal(content, &upload.BOM)
+	}
+	if err != nil || upload.BOM.BOMFormat != "CycloneDX" {
+		http.Error(w, "The uploaded BOM is invalid", http.StatusBadRequest)
+		return
+	}
+	m.uploads = append(m.uploads, upload)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"token": fmt.Sprintf("00000000-0000-4000-8000-%012d", len(m.uploads)),
+	})
+}
@@ -0,0 +1,52 @@
+package testharness_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/reporter"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestMockDependencyTrack(t *testing.T) {
+	ctx := context.Background()
+	dt := testharness.NewMockDependencyTrack(t)
+	e := dt.Exporter()
+
+	deps := []models.Dependency{
+		{Ecosystem: "npm", Name: "express", Version: "4.18.2", Scope: models.DependencyScopeProd, Direct: true, DependsOn: []string{"lodash@4.17.20"}},
+		{Ecosystem: "npm", Name: "lodash", Version: "4.17.20", Scope: models.DependencyScopeProd},
+	}
+	bom, err := reporter.GenerateCycloneDX("shop", "1.2.0", deps, "test", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := e.Upload(ctx, "shop", "1.2.0", bom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token == "" {
+		t.Error("Upload returned no token")
+	}
+
+	uploads := dt.Uploads()
+	if len(uploads) != 1 {
+		t.Fatalf("uploads = %+v, want 1", uploads)
+	}
+	upload := uploads[0]
+	if upload.ProjectName != "shop" || upload.ProjectVersion != "1.2.0" || !upload.AutoCreate || !slices.Equal(upload.ProjectTags, []string{"audit-checks"}) {
+		t.Errorf("upload = %+v, want shop 1.2.0 created and tagged audit-checks", upload)
+	}
+	if upload.BOM.BOMFormat != "CycloneDX" || len(upload.BOM.Components) != 2 || upload.BOM.Metadata.Component.Name != "shop" {
+		t.Errorf("BOM = %+v, want the components of shop", upload.BOM)
+	}
+
+	dt.Fail(http.StatusForbidden)
+	if _, err := e.Upload(ctx, "shop", "1.2.0", bom); err == nil {
+		t.Error("Upload succeeded with a failing Dependency-Track")
+	}
+}
@@ -0,0 +1,190 @@
+package testharness
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// MockDiscordToken is the bot token the mock Discord server accepts
+const MockDiscordToken = "testharness-discord-token"
+
+// DiscordCall is a request received by the mock Discord server
+type DiscordCall struct {
+	Method  string          // HTTP method
+	Path    string          // e.g. /channels/42/messages, /webhooks/1/token
+	Query   string          // e.g. wait=true&thread_id=1001
+	Payload json.RawMessage // the JSON body, or payload_json of multipart requests
+	Files   []string        // names of the attached files
+}
+
+// MockDiscord is a Discord API server recording requests. It answers channel
+// lookups, thread creation, channel messages and webhook executions like
+// Discord does.
+type MockDiscord struct {
+	Server *httptest.Server
+
+	// Forum makes channels and webhooks behave like forum channels, where
+	// threads are created with their first message
+	Forum bool
+
+	calls     []DiscordCall
+	failures  map[string]discordFailure // route -> error
+	threadID  int
+	messageID int
+	mu        sync.Mutex
+}
+
+// discordFailure is an error the mock returns for a route
+type discordFailure struct {
+	status  int
+	code    int
+	message string
+}
+
+// NewMockDiscord starts a mock Discord server, stopped when the test ends
+func NewMockDiscord(t testing.TB) *MockDiscord {
+	t.Helper()
+
+	m := &MockDiscord{failures: make(map[string]discordFailure), threadID: 1000}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Notifier returns a Discord notifier posting to channelID as a bot through the mock server
+func (m *MockDiscord) Notifier(channelID string) *notifier.DiscordNotifier {
+	return notifier.NewDiscordNotifierWithEndpoint(MockDiscordToken, channelID, "", true, m.Server.URL)
+}
+
+// WebhookNotifier returns a Discord notifier posting through a webhook of the mock server
+func (m *MockDiscord) WebhookNotifier() *notifier.DiscordNotifier {
+	return notifier.NewDiscordNotifierWithEndpoint("", "", m.Server.URL+"/webhooks/1/"+MockDiscordToken, true, m.Server.URL)
+}
+
+// Fail makes every following request to route fail with a Discord JSON error.
+// Routes are "channel" (lookup), "threads", "messages" and "webhook"; e.g.
+// Fail("messages", http.StatusNotFound, 10003, "Unknown Channel") simulates a deleted thread.
+func (m *MockDiscord) Fail(route string, status, code int, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures[route] = discordFailure{status: status, code: code, message: message}
+}
+
+// Calls returns the requests received, in order
+func (m *MockDiscord) Calls() []DiscordCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]DiscordCall(nil), m.calls...)
+}
+
+// handle answers a Discord API request
+func (m *MockDiscord) handle(w http.ResponseWriter, r *http.Request) {
+	call := DiscordCall{Method: r.Method, Path: r.URL.Path, Query: r.URL.RawQuery}
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err == nil {
+			call.Payload = json.RawMessage(r.FormValue("payload_json"))
+			for i := 0; ; i++ {
+				files := r.MultipartForm.File["files["+strconv.Itoa(i)+"]"]
+				if len(files) == 0 {
+					break
+				}
+				call.Files = append(call.Files, files[0].Filename)
+			}
+		}
+	} else if r.Body != nil {
+		var payload json.RawMessage
+		if json.NewDecoder(r.Body).Decode(&payload) == nil {
+			call.Payload = payload
+		}
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	route := ""
+	switch {
+	case len(parts) == 3 && parts[0] == "webhooks":
+		route = "webhook"
+	case len(parts) == 2 && parts[0] == "channels" && r.Method == http.MethodGet:
+		route = "channel"
+	case len(parts) == 3 && parts[0] == "channels":
+		route = parts[2]
+	}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	failure, fail := m.failures[route]
+	m.mu.Unlock()
+
+	if route != "webhook" && r.Header.Get("Authorization") != "Bot "+MockDiscordToken {
+		writeDiscordResponse(w, http.StatusUnauthorized, map[string]any{"code": 0, "message": "401: Unauthorized"})
+		return
+	}
+	if route == "webhook" && parts[2] != MockDiscordToken {
+		writeDiscordResponse(w, http.StatusUnauthorized, map[string]any{"code": 50027, "message": "Invalid Webhook Token"})
+		return
+	}
+	if fail {
+		writeDiscordResponse(w, failure.status, map[string]any{"code": failure.code, "message": failure.message})
+		return
+	}
+
+	var payload map[string]any
+	_ = json.Unmarshal(call.Payload, &payload)
+
+	switch route {
+	case "channel":
+		channelType := 0
+		if m.Forum {
+			channelType = 15
+		}
+		writeDiscordResponse(w, http.StatusOK, map[string]any{"id": parts[1], "type": channelType})
+	case "threads":
+		threadID := m.nextID(&m.threadID)
+		writeDiscordResponse(w, http.StatusCreated, map[string]any{"id": threadID, "type": 11, "parent_id": parts[1], "name": payload["name"]})
+	case "messages":
+		writeDiscordResponse(w, http.StatusOK, m.message(parts[1]))
+	case "webhook":
+		channelID := "1"
+		switch {
+		case r.URL.Query().Get("thread_id") != "":
+			channelID = r.URL.Query().Get("thread_id")
+		case payload["thread_name"] != nil && !m.Forum:
+			writeDiscordResponse(w, http.StatusBadRequest, map[string]any{"code": 220003, "message": "Webhooks can only create threads in forum channels"})
+			return
+		case payload["thread_name"] != nil:
+			channelID = m.nextID(&m.threadID)
+		case m.Forum:
+			writeDiscordResponse(w, http.StatusBadRequest, map[string]any{"code": 220001, "message": "Webhooks posted to forum channels must have a thread_name or thread_id"})
+			return
+		}
+		writeDiscordResponse(w, http.StatusOK, m.message(channelID))
+	default:
+		writeDiscordResponse(w, http.StatusNotFound, map[string]any{"code": 0, "message": "404: Not Found"})
+	}
+}
+
+// nextID increments a counter and returns it as a snowflake string
+func (m *MockDiscord) nextID(counter *int) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	*counter++
+	return strconv.Itoa(*counter)
+}
+
+// message returns a sent message in channelID
+func (m *MockDiscord) message(channelID string) map[string]any {
+	return map[string]any{"id": m.nextID(&m.messageID), "channel_id": channelID}
+}
+
+// writeDiscordResponse writes a Discord API response
+func writeDiscordResponse(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
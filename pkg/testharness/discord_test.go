@@ -0,0 +1,51 @@
+package testharness_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestMockDiscord(t *testing.T) {
+	discord := testharness.NewMockDiscord(t)
+
+	result, err := notifyCombined(t, discord.Notifier("42"), criticalReport(), models.NotificationConfig{DiscordEnabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DiscordThreadID == "" {
+		t.Fatal("no Discord thread was created for shop")
+	}
+
+	var posted bool
+	for _, call := range discord.Calls() {
+		if call.Method == http.MethodPost && call.Path == "/channels/"+result.DiscordThreadID+"/messages" {
+			posted = strings.Contains(string(call.Payload), "lodash")
+		}
+	}
+	if !posted {
+		t.Errorf("calls = %+v, want the summary of shop in its thread", discord.Calls())
+	}
+
+	// A deleted thread fails the post
+	discord.Fail("messages", http.StatusNotFound, 10003, "Unknown Channel")
+	config := models.NotificationConfig{DiscordEnabled: true, DiscordThreadID: result.DiscordThreadID}
+	if _, err := notifyCombined(t, discord.Notifier("42"), criticalReport(), config); err == nil {
+		t.Error("NotifyAllCombined succeeded with a failing Discord")
+	}
+}
+
+func TestMockDiscordWebhook(t *testing.T) {
+	discord := testharness.NewMockDiscord(t)
+
+	if _, err := notifyCombined(t, discord.WebhookNotifier(), criticalReport(), models.NotificationConfig{DiscordEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	calls := discord.Calls()
+	if len(calls) == 0 || !strings.HasPrefix(calls[len(calls)-1].Path, "/webhooks/") || !strings.Contains(string(calls[len(calls)-1].Payload), "lodash") {
+		t.Errorf("calls = %+v, want the summary of shop posted through the webhook", calls)
+	}
+}
@@ -0,0 +1,99 @@
+package testharness_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+// criticalResult is an audit result with one critical and one moderate vulnerability
+func criticalResult() *models.AuditResult {
+	return &models.AuditResult{
+		Vulnerabilities: []models.Vulnerability{
+			{PackageName: "lodash", CVEID: "CVE-2021-23337", Title: "Command Injection", Severity: models.SeverityCritical},
+			{PackageName: "minimist", Title: "Prototype Pollution", Severity: models.SeverityModerate, PatchedVersions: ">=1.2.6"},
+		},
+	}
+}
+
+// criticalReport is the combined report of shop with criticalResult as its npm audit
+func criticalReport() *models.CombinedAppReport {
+	result := criticalResult()
+	result.AppName, result.AppPath, result.AuditorType = "shop", "/srv/shop", "npm"
+	result.UpdateCounts()
+
+	combined := models.NewCombinedAppReport("shop", "/srv/shop")
+	combined.RunID = "01TESTRUN"
+	combined.AddReport(models.NewReport(result, nil), nil)
+	return combined
+}
+
+// cleanReport is the combined report of shop without findings
+func cleanReport() *models.CombinedAppReport {
+	combined := models.NewCombinedAppReport("shop", "/srv/shop")
+	combined.RunID = "01TESTRUN"
+	return combined
+}
+
+// notifyCombined sends report through a manager with the notifier n alone
+func notifyCombined(t *testing.T, n notifier.Notifier, report *models.CombinedAppReport, config models.NotificationConfig) (*notifier.NotificationResult, error) {
+	t.Helper()
+
+	manager := notifier.NewManager(false)
+	manager.Register(n)
+	config.AppName = report.AppName
+	return manager.NotifyAllCombined(context.Background(), report, config)
+}
+
+// TestApplicationRun runs an audit as the package documentation shows: a fake
+// auditor's result is stored in a MemoryStore and notified through the mock Telegram
+func TestApplicationRun(t *testing.T) {
+	cfg := testharness.Config(t)
+	st := testharness.NewMemoryStore()
+	st.AddApp(models.App{Name: "shop", Path: testharness.AppDir(t, nil), Type: "npm", TelegramEnabled: true, Enabled: true})
+	tg := testharness.NewMockTelegram(t)
+
+	app := testharness.NewApplication(t, cfg, st)
+	app.NotifierManager.Register(tg.Notifier(t, -100123))
+	auditor := &testharness.FakeAuditor{AuditorName: "npm", Detects: true, Result: criticalResult()}
+	app.AuditorRegistry.Register(auditor)
+
+	if err := app.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls := auditor.Calls(); len(calls) != 1 || calls[0].Name != "shop" {
+		t.Errorf("auditor calls = %+v, want one for shop", calls)
+	}
+
+	results := st.Results()
+	if len(results) != 1 {
+		t.Fatalf("results = %+v, want 1", results)
+	}
+	if r := results[0]; r.AppName != "shop" || r.AuditorType != "npm" || r.CriticalCount != 1 || r.ModerateCount != 1 {
+		t.Errorf("result = %+v, want shop's npm result with 1 critical and 1 moderate", r)
+	}
+	if len(st.Events()) == 0 {
+		t.Error("no run events were saved")
+	}
+
+	// The reports are sent as documents in the app's new forum topic, captioned with the summary
+	calls := tg.Calls()
+	if len(calls) != 2 || calls[0].Method != "createForumTopic" || calls[0].Params["name"] != "Security: shop" {
+		t.Fatalf("Telegram calls = %+v, want the topic of shop and the reports", calls)
+	}
+	if media := calls[1].Params["media"]; calls[1].Method != "sendMediaGroup" || !strings.Contains(media, "Security Alert: shop") || !strings.Contains(media, "lodash (CRITICAL") {
+		t.Errorf("Telegram reports = %+v, want the summary of shop's findings", calls[1])
+	}
+	apps, err := st.Apps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if apps[0].TelegramTopicID == 0 {
+		t.Error("the Telegram topic of shop was not saved")
+	}
+}
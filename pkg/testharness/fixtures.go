@@ -0,0 +1,88 @@
+package testharness
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Canned tool outputs, as printed by the tool versions the auditors support
+const (
+	FixtureNPMAudit           = "npm-audit.json"            // npm audit --json: 1 critical, 2 moderate
+	FixtureNPMAuditClean      = "npm-audit-clean.json"      // npm audit --json: no vulnerabilities
+	FixtureComposerAudit      = "composer-audit.json"       // composer audit --format=json: 1 high, 1 medium, 1 abandoned
+	FixtureComposerAuditClean = "composer-audit-clean.json" // composer audit --format=json: no advisories
+	FixtureNPMOutdated        = "npm-outdated.json"         // npm outdated --json --long: 2 majors behind
+	FixtureComposerOutdated   = "composer-outdated.json"    // composer outdated --format=json: 1 major behind
+)
+
+//go:embed fixtures/*.json
+var fixtures embed.FS
+
+// Fixture returns a canned tool output by name (one of the Fixture constants)
+func Fixture(name string) []byte {
+	data, err := fixtures.ReadFile("fixtures/" + name)
+	if err != nil {
+		panic(fmt.Sprintf("testharness: unknown fixture %q", name))
+	}
+	return data
+}
+
+// ToolResponse is what a fake tool prints when called with arguments starting with Args
+type ToolResponse struct {
+	Args     string // argument prefix, e.g. "audit --json"
+	Output   []byte // printed on stdout
+	ExitCode int    // e.g. 1 for npm audit with vulnerabilities
+}
+
+// FakeTool installs a fake binary called name in a directory prepended to PATH for the
+// duration of the test, so auditors run it instead of the real tool. It prints version
+// for --version and the output of the first response whose Args prefix the arguments;
+// other arguments fail with exit code 127. Fake tools are shell scripts (Unix only).
+func FakeTool(t testing.TB, name, version string, responses ...ToolResponse) {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	var script strings.Builder
+	script.WriteString("#!/bin/sh\ncase \"$*\" in\n")
+	fmt.Fprintf(&script, "--version*) echo %s; exit 0;;\n", shellQuote(version))
+	for i, response := range responses {
+		output := filepath.Join(dir, fmt.Sprintf("%s.%d.out", name, i))
+		if err := os.WriteFile(output, response.Output, 0644); err != nil {
+			t.Fatalf("testharness: failed to write fake %s output: %v", name, err)
+		}
+		fmt.Fprintf(&script, "%s*) cat %s; exit %d;;\n", shellQuote(response.Args), shellQuote(output), response.ExitCode)
+	}
+	fmt.Fprintf(&script, "esac\necho \"testharness: unexpected %s $*\" >&2\nexit 127\n", name)
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script.String()), 0755); err != nil {
+		t.Fatalf("testharness: failed to write fake %s: %v", name, err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// AppDir creates an app directory with the given files (path relative to the directory -> content)
+func AppDir(t testing.TB, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("testharness: failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("testharness: failed to write %s: %v", path, err)
+		}
+	}
+	return dir
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,59 @@
+package testharness_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/auditor"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+// TestFakeTool runs the real npm and Composer auditors against fake tools printing
+// the canned outputs
+func TestFakeTool(t *testing.T) {
+	ctx := context.Background()
+	testharness.FakeTool(t, "npm", "10.8.2",
+		testharness.ToolResponse{Args: "audit --json", Output: testharness.Fixture(testharness.FixtureNPMAudit), ExitCode: 1})
+	testharness.FakeTool(t, "composer", "Composer version 2.7.7",
+		testharness.ToolResponse{Args: "audit --format=json", Output: testharness.Fixture(testharness.FixtureComposerAudit), ExitCode: 1})
+
+	dir := testharness.AppDir(t, map[string]string{
+		"package.json":      `{"name": "shop"}`,
+		"package-lock.json": `{"lockfileVersion": 3}`,
+		"composer.json":     `{"name": "acme/shop"}`,
+		"composer.lock":     `{"packages": []}`,
+	})
+	app := models.AppConfig{Name: "shop", Path: dir}
+
+	npm := auditor.NewNPMAuditor(nil)
+	if !npm.Detect(dir) {
+		t.Fatal("npm auditor does not detect the app directory")
+	}
+	result, err := npm.Audit(ctx, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.CriticalCount != 1 || result.ModerateCount != 2 {
+		t.Errorf("npm result = %+v, want 1 critical and 2 moderate", result)
+	}
+
+	result, err = auditor.NewComposerAuditor(nil).Audit(ctx, app)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.HighCount != 1 || result.ModerateCount != 1 {
+		t.Errorf("composer result = %+v, want 1 high and 1 medium", result)
+	}
+}
+
+func TestFakeToolUnexpectedArguments(t *testing.T) {
+	testharness.FakeTool(t, "npm", "10.8.2")
+	dir := testharness.AppDir(t, map[string]string{"package.json": "{}"})
+
+	_, err := auditor.NewNPMAuditor(nil).Audit(context.Background(), models.AppConfig{Name: "shop", Path: dir})
+	if err == nil || !strings.Contains(err.Error(), "exit 127") {
+		t.Errorf("Audit error = %v, want the fake tool's exit code 127", err)
+	}
+}
@@ -0,0 +1,249 @@
+package testharness
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// Mock GitLab access token and default project
+const (
+	MockGitLabToken   = "glpat-testharness"
+	MockGitLabProject = "acme/security"
+)
+
+// GitLabIssue is an issue of the mock GitLab server
+type GitLabIssue struct {
+	Project      string
+	IID          int
+	Title        string
+	Description  string
+	Labels       []string
+	Confidential bool
+	State        string   // opened or closed
+	Notes        []string // comments, in order
+}
+
+// MockGitLab is a GitLab Issues API server keeping the issues of any project, numbered
+// per project as GitLab does
+type MockGitLab struct {
+	Server *httptest.Server
+
+	issues     []*GitLabIssue
+	failStatus int
+	mu         sync.Mutex
+}
+
+// NewMockGitLab starts a mock GitLab server, stopped when the test ends
+func NewMockGitLab(t testing.TB) *MockGitLab {
+	t.Helper()
+
+	m := &MockGitLab{}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Notifier returns a GitLab notifier creating issues in MockGitLabProject, labeled security
+func (m *MockGitLab) Notifier() *notifier.GitLabNotifier {
+	return notifier.NewGitLabNotifier(m.Server.URL, MockGitLabToken, MockGitLabProject, []string{"security"}, true)
+}
+
+// Fail makes every following request fail with status
+func (m *MockGitLab) Fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus = status
+}
+
+// Issues returns copies of the issues, in the order they were created
+func (m *MockGitLab) Issues() []GitLabIssue {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	issues := make([]GitLabIssue, len(m.issues))
+	for i, issue := range m.issues {
+		issues[i] = *issue
+		issues[i].Notes = append([]string(nil), issue.Notes...)
+	}
+	return issues
+}
+
+// Delete deletes an issue, as a project owner can
+func (m *MockGitLab) Delete(project string, iid int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, issue := range m.issues {
+		if issue.Project == project && issue.IID == iid {
+			m.issues = append(m.issues[:i], m.issues[i+1:]...)
+			return
+		}
+	}
+}
+
+// Close closes an issue, as a developer can by hand
+func (m *MockGitLab) Close(project string, iid int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if issue := m.find(project, iid); issue != nil {
+		issue.State = "closed"
+	}
+}
+
+// gitlabIssueRequest is the body of an issue create or edit request
+type gitlabIssueRequest struct {
+	Title        *string `json:"title"`
+	Description  *string `json:"description"`
+	Labels       *string `json:"labels"`
+	Confidential bool    `json:"confidential"`
+	StateEvent   string  `json:"state_event"`
+}
+
+// handle answers POST /api/v4/projects/{id}/issues, PUT /api/v4/projects/{id}/issues/{iid}
+// and POST /api/v4/projects/{id}/issues/{iid}/notes
+func (m *MockGitLab) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Header.Get("PRIVATE-TOKEN") != MockGitLabToken {
+		writeGitLabError(w, http.StatusUnauthorized, "401 Unauthorized")
+		return
+	}
+
+	// The project is URL-encoded in a single path segment, e.g. acme%2Fsecurity
+	rest, ok := strings.CutPrefix(r.URL.EscapedPath(), "/api/v4/projects/")
+	segments := strings.Split(rest, "/")
+	if !ok || len(segments) < 2 || segments[1] != "issues" {
+		writeGitLabError(w, http.StatusNotFound, "404 Not Found")
+		return
+	}
+	project, err := url.PathUnescape(segments[0])
+	if err != nil {
+		writeGitLabError(w, http.StatusNotFound, "404 Project Not Found")
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failStatus != 0 {
+		writeGitLabError(w, m.failStatus, "testharness failure")
+		return
+	}
+
+	var body gitlabIssueRequest
+	var note struct {
+		Body string `json:"body"`
+	}
+
+	switch {
+	case r.Method == http.MethodPost && len(segments) == 2:
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Title == nil || *body.Title == "" {
+			writeGitLabError(w, http.StatusBadRequest, "title is missing")
+			return
+		}
+		iid := 1
+		for _, issue := range m.issues {
+			if issue.Project == project && issue.IID >= iid {
+				iid = issue.IID + 1
+			}
+		}
+		issue := &GitLabIssue{Project: project, IID: iid, Title: *body.Title, Confidential: body.Confidential, State: "opened"}
+		m.apply(issue, body)
+		m.issues = append(m.issues, issue)
+		writeGitLabIssue(w, http.StatusCreated, m.Server.URL, issue)
+
+	case r.Method == http.MethodPut && len(segments) == 3:
+		issue := m.find(project, atoi(segments[2]))
+		if issue == nil {
+			writeGitLabError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeGitLabError(w, http.StatusBadRequest, "invalid body")
+			return
+		}
+		m.apply(issue, body)
+		writeGitLabIssue(w, http.StatusOK, m.Server.URL, issue)
+
+	case r.Method == http.MethodPost && len(segments) == 4 && segments[3] == "notes":
+		issue := m.find(project, atoi(segments[2]))
+		if issue == nil {
+			writeGitLabError(w, http.StatusNotFound, "404 Not found")
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&note); err != nil || note.Body == "" {
+			writeGitLabError(w, http.StatusBadRequest, "body is missing")
+			return
+		}
+		issue.Notes = append(issue.Notes, note.Body)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": len(issue.Notes), "body": note.Body})
+
+	default:
+		writeGitLabError(w, http.StatusNotFound, "404 Not Found")
+	}
+}
+
+// find returns an issue of a project, or nil
+func (m *MockGitLab) find(project string, iid int) *GitLabIssue {
+	for _, issue := range m.issues {
+		if issue.Project == project && issue.IID == iid {
+			return issue
+		}
+	}
+	return nil
+}
+
+// apply applies the fields of a create or edit request to an issue
+func (m *MockGitLab) apply(issue *GitLabIssue, body gitlabIssueRequest) {
+	if body.Title != nil && *body.Title != "" {
+		issue.Title = *body.Title
+	}
+	if body.Description != nil {
+		issue.Description = *body.Description
+	}
+	if body.Labels != nil {
+		issue.Labels = strings.Split(*body.Labels, ",")
+	}
+	switch body.StateEvent {
+	case "close":
+		issue.State = "closed"
+	case "reopen":
+		issue.State = "opened"
+	}
+}
+
+// atoi returns the integer in s, or 0
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// writeGitLabIssue writes an issue as the Issues API returns it
+func writeGitLabIssue(w http.ResponseWriter, status int, serverURL string, issue *GitLabIssue) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"iid":          issue.IID,
+		"title":        issue.Title,
+		"state":        issue.State,
+		"confidential": issue.Confidential,
+		"labels":       issue.Labels,
+		"web_url":      fmt.Sprintf("%s/%s/-/issues/%d", serverURL, issue.Project, issue.IID),
+	})
+}
+
+// writeGitLabError writes an error response as the GitLab API does
+func writeGitLabError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"message": message})
+}
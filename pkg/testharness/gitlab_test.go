@@ -0,0 +1,57 @@
+package testharness_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+// TestMockGitLab follows the issue of an app through the manager: created, updated,
+// replaced when closed by hand and closed once the findings are gone
+func TestMockGitLab(t *testing.T) {
+	ctx := context.Background()
+	gl := testharness.NewMockGitLab(t)
+	manager := notifier.NewManager(false)
+	manager.Register(gl.Notifier())
+	config := models.NotificationConfig{AppName: "shop", GitLabEnabled: true}
+
+	ref, err := manager.SyncGitLab(ctx, criticalReport(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != testharness.MockGitLabProject+"#1" {
+		t.Fatalf("issue = %q, want the first of %s", ref, testharness.MockGitLabProject)
+	}
+	issue := gl.Issues()[0]
+	if issue.State != "opened" || !slices.Contains(issue.Labels, "security") || !strings.Contains(issue.Description, "CVE-2021-23337") {
+		t.Errorf("issue = %+v, want an open security issue listing CVE-2021-23337", issue)
+	}
+
+	config.GitLabIssue = ref
+	if got, err := manager.SyncGitLab(ctx, criticalReport(), config); err != nil || got != ref {
+		t.Fatalf("SyncGitLab = %q, %v, want %s updated", got, err, ref)
+	}
+
+	gl.Close(testharness.MockGitLabProject, 1)
+	ref, err = manager.SyncGitLab(ctx, criticalReport(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref != testharness.MockGitLabProject+"#2" {
+		t.Fatalf("issue = %q, want a new issue replacing the one closed by hand", ref)
+	}
+
+	config.GitLabIssue = ref
+	if got, err := manager.SyncGitLab(ctx, cleanReport(), config); err != nil || got != "" {
+		t.Fatalf("SyncGitLab = %q, %v, want the issue closed", got, err)
+	}
+	issue = gl.Issues()[1]
+	if issue.State != "closed" || len(issue.Notes) == 0 || !strings.Contains(issue.Notes[len(issue.Notes)-1], "01TESTRUN") {
+		t.Errorf("issue = %+v, want it closed with a note of the run", issue)
+	}
+}
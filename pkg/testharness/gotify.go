@@ -0,0 +1,108 @@
+package testharness
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// MockGotifyToken is the application token the mock Gotify server accepts
+const MockGotifyToken = "AGotifyTestharness"
+
+// GotifyMessage is a message pushed to the mock Gotify server
+type GotifyMessage struct {
+	Title    string         `json:"title"`
+	Message  string         `json:"message"`
+	Priority int            `json:"priority"`
+	Extras   map[string]any `json:"extras"`
+}
+
+// MockGotify is a Gotify server recording the messages pushed to POST /message
+type MockGotify struct {
+	Server *httptest.Server
+
+	messages   []GotifyMessage
+	failStatus int
+	mu         sync.Mutex
+}
+
+// NewMockGotify starts a mock Gotify server, stopped when the test ends
+func NewMockGotify(t testing.TB) *MockGotify {
+	t.Helper()
+
+	m := &MockGotify{}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Notifier returns a Gotify notifier pushing to the mock server, with the default
+// priorities
+func (m *MockGotify) Notifier() *notifier.GotifyNotifier {
+	return notifier.NewGotifyNotifier(m.Server.URL, MockGotifyToken, nil, true)
+}
+
+// Fail makes every following request fail with status
+func (m *MockGotify) Fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus = status
+}
+
+// Messages returns the messages pushed, in order
+func (m *MockGotify) Messages() []GotifyMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]GotifyMessage(nil), m.messages...)
+}
+
+// handle answers POST /message with a JSON message
+func (m *MockGotify) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Header.Get("X-Gotify-Key") != MockGotifyToken {
+		writeGotifyError(w, http.StatusUnauthorized, "you need to provide a valid access token or user credentials to access this api")
+		return
+	}
+	if r.Method != http.MethodPost || r.URL.Path != "/message" {
+		writeGotifyError(w, http.StatusNotFound, "page not found")
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failStatus != 0 {
+		writeGotifyError(w, m.failStatus, "testharness failure")
+		return
+	}
+
+	var message GotifyMessage
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil || message.Message == "" {
+		writeGotifyError(w, http.StatusBadRequest, "Field 'message' is required")
+		return
+	}
+	m.messages = append(m.messages, message)
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":       len(m.messages),
+		"appid":    1,
+		"title":    message.Title,
+		"message":  message.Message,
+		"priority": message.Priority,
+	})
+}
+
+// writeGotifyError writes an error response as the Gotify server does
+func writeGotifyError(w http.ResponseWriter, status int, description string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"error":            http.StatusText(status),
+		"errorCode":        status,
+		"errorDescription": description,
+	})
+}
@@ -0,0 +1,30 @@
+package testharness_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestMockGotify(t *testing.T) {
+	gotify := testharness.NewMockGotify(t)
+
+	if _, err := notifyCombined(t, gotify.Notifier(), criticalReport(), models.NotificationConfig{GotifyEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	messages := gotify.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("messages = %+v, want 1", messages)
+	}
+	if m := messages[0]; !strings.Contains(m.Title, "shop") || !strings.Contains(m.Message, "lodash") || m.Priority == 0 {
+		t.Errorf("message = %+v, want the summary of shop with a priority", m)
+	}
+
+	gotify.Fail(http.StatusUnauthorized)
+	if _, err := notifyCombined(t, gotify.Notifier(), criticalReport(), models.NotificationConfig{GotifyEnabled: true}); err == nil {
+		t.Error("NotifyAllCombined succeeded with a failing Gotify")
+	}
+}
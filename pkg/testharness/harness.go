@@ -0,0 +1,90 @@
+// Package testharness helps write integration tests for new auditors and notifiers
+// without real tools, credentials or a shared database. It provides a test
+// configuration with temporary directories, SQLite and in-memory stores, a fake
+// auditor, canned npm and Composer outputs that can be served by fake tools on
+// PATH, and mock Telegram, Discord, Mattermost, Resend, SMTP, webhook, PagerDuty,
+// Opsgenie, ntfy, Gotify, Zulip, GitLab, DefectDojo, Dependency-Track and Confluence
+// servers that record what was sent.
+//
+// A typical test builds an application against a store and mock notifiers:
+//
+//	cfg := testharness.Config(t)
+//	st := testharness.NewMemoryStore()
+//	tg := testharness.NewMockTelegram(t)
+//	app := testharness.NewApplication(t, cfg, st)
+//	app.NotifierManager.Register(tg.Notifier(t, -100123))
+//	app.AuditorRegistry.Register(&testharness.FakeAuditor{AuditorName: "npm", Result: result})
+//
+// The package is only meant to be imported from tests.
+package testharness
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/application"
+	"github.com/shadowbane/audit-checks/pkg/config"
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/store"
+)
+
+// Config returns a configuration for tests: the database, reports and logs live
+// in a temporary directory, notifications and optional integrations are disabled,
+// and no environment variables or .env file are read.
+func Config(t testing.TB) *config.Config {
+	t.Helper()
+
+	dir := t.TempDir()
+	return &config.Config{
+		AppEnv:       "testing",
+		LogLevel:     "debug",
+		LogDirectory: filepath.Join(dir, "logs"),
+		LogMaxAge:    30,
+		DBSQLitePath: filepath.Join(dir, "audit-checks.db"),
+		DBLogLevel:   "silent",
+		Operator:     "testharness",
+		Settings: config.Settings{
+			SeverityThreshold:     models.SeverityLow,
+			ReportFormats:         []string{"json", "markdown"},
+			ReportOutputDir:       filepath.Join(dir, "reports"),
+			MaxConcurrent:         1,
+			RetryAttempts:         1,
+			ScheduleMinInterval:   6 * time.Hour,
+			ScheduleBaseInterval:  24 * time.Hour,
+			ScheduleMaxInterval:   7 * 24 * time.Hour,
+			OSVCacheDir:           filepath.Join(dir, "osv-cache"),
+			OutdatedAuditSeverity: models.SeverityInfo,
+		},
+	}
+}
+
+// NewSQLiteStore returns a store backed by a fresh SQLite database in a temporary
+// directory, with migrations applied. It is closed when the test ends.
+func NewSQLiteStore(t testing.TB) *store.GormStore {
+	t.Helper()
+
+	st, err := store.OpenSQLite(Config(t))
+	if err != nil {
+		t.Fatalf("testharness: failed to open SQLite store: %v", err)
+	}
+	t.Cleanup(func() { st.Close() })
+	return st
+}
+
+// NewApplication creates an application on cfg and st. Apps added to st before
+// the call are loaded; the application is closed when the test ends.
+func NewApplication(t testing.TB, cfg *config.Config, st store.Store) *application.Application {
+	t.Helper()
+
+	if err := cfg.EnsureDirectories(); err != nil {
+		t.Fatalf("testharness: failed to create directories: %v", err)
+	}
+
+	app, err := application.NewWithStore(cfg, st)
+	if err != nil {
+		t.Fatalf("testharness: failed to create application: %v", err)
+	}
+	t.Cleanup(func() { app.Close() })
+	return app
+}
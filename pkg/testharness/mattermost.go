@@ -0,0 +1,137 @@
+package testharness
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// Mock Mattermost credentials and paths
+const (
+	MockMattermostToken       = "testharness-token"
+	MockMattermostChannelID   = "testharnesschannel0000000000"
+	MockMattermostWebhookPath = "/hooks/testharness"
+)
+
+// MattermostPost is a post received by the mock Mattermost server, from the bot or
+// the incoming webhook
+type MattermostPost struct {
+	ChannelID string // bot posts
+	Channel   string // webhook posts overriding the webhook's channel
+	Username  string // webhook posts
+	Message   string
+	Webhook   bool
+}
+
+// MockMattermost is a Mattermost server recording the posts created through
+// /api/v4/posts and the incoming webhook
+type MockMattermost struct {
+	Server *httptest.Server
+
+	posts      []MattermostPost
+	failStatus int
+	mu         sync.Mutex
+}
+
+// NewMockMattermost starts a mock Mattermost server, stopped when the test ends
+func NewMockMattermost(t testing.TB) *MockMattermost {
+	t.Helper()
+
+	m := &MockMattermost{}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Notifier returns a Mattermost notifier posting as the bot to MockMattermostChannelID
+func (m *MockMattermost) Notifier() *notifier.MattermostNotifier {
+	return notifier.NewMattermostNotifier(m.Server.URL, MockMattermostToken, MockMattermostChannelID, "", true)
+}
+
+// WebhookNotifier returns a Mattermost notifier posting through the incoming webhook
+func (m *MockMattermost) WebhookNotifier() *notifier.MattermostNotifier {
+	return notifier.NewMattermostNotifier("", "", "", m.Server.URL+MockMattermostWebhookPath, true)
+}
+
+// Fail makes every following request fail with status
+func (m *MockMattermost) Fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus = status
+}
+
+// Posts returns the posts received, in order
+func (m *MockMattermost) Posts() []MattermostPost {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]MattermostPost(nil), m.posts...)
+}
+
+// handle answers POST /api/v4/posts and POST MockMattermostWebhookPath
+func (m *MockMattermost) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || (r.URL.Path != "/api/v4/posts" && r.URL.Path != MockMattermostWebhookPath) {
+		writeMattermostError(w, http.StatusNotFound, "api.context.404.app_error", "Sorry, we could not find the page.")
+		return
+	}
+	webhook := r.URL.Path == MockMattermostWebhookPath
+	if !webhook && r.Header.Get("Authorization") != "Bearer "+MockMattermostToken {
+		writeMattermostError(w, http.StatusUnauthorized, "api.context.session_expired.app_error", "Invalid or expired session, please login again.")
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failStatus != 0 {
+		writeMattermostError(w, m.failStatus, "testharness.failure", "testharness failure")
+		return
+	}
+
+	var body struct {
+		ChannelID string `json:"channel_id"`
+		Message   string `json:"message"`
+		Text      string `json:"text"`
+		Channel   string `json:"channel"`
+		Username  string `json:"username"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeMattermostError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing post in request body.")
+		return
+	}
+
+	if webhook {
+		if body.Text == "" {
+			writeMattermostError(w, http.StatusBadRequest, "web.incoming_webhook.text.app_error", "No text specified.")
+			return
+		}
+		m.posts = append(m.posts, MattermostPost{Channel: body.Channel, Username: body.Username, Message: body.Text, Webhook: true})
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte("ok"))
+		return
+	}
+
+	if body.ChannelID == "" {
+		writeMattermostError(w, http.StatusBadRequest, "api.context.invalid_body_param.app_error", "Invalid or missing channel_id in request body.")
+		return
+	}
+	m.posts = append(m.posts, MattermostPost{ChannelID: body.ChannelID, Message: body.Message})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":         "testharnesspost",
+		"channel_id": body.ChannelID,
+		"message":    body.Message,
+	})
+}
+
+// writeMattermostError writes an error response as the Mattermost API does
+func writeMattermostError(w http.ResponseWriter, status int, id, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"id": id, "message": message, "status_code": status})
+}
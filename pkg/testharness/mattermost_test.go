@@ -0,0 +1,37 @@
+package testharness_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestMockMattermost(t *testing.T) {
+	mm := testharness.NewMockMattermost(t)
+
+	if _, err := notifyCombined(t, mm.Notifier(), criticalReport(), models.NotificationConfig{MattermostEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	posts := mm.Posts()
+	if len(posts) == 0 || posts[0].Webhook || posts[0].ChannelID != testharness.MockMattermostChannelID || !strings.Contains(posts[0].Message, "lodash") {
+		t.Fatalf("posts = %+v, want the summary of shop posted by the bot", posts)
+	}
+
+	webhook := testharness.NewMockMattermost(t)
+	config := models.NotificationConfig{MattermostEnabled: true, MattermostChannel: "shop-alerts"}
+	if _, err := notifyCombined(t, webhook.WebhookNotifier(), criticalReport(), config); err != nil {
+		t.Fatal(err)
+	}
+	posts = webhook.Posts()
+	if len(posts) == 0 || !posts[0].Webhook || posts[0].Channel != "shop-alerts" {
+		t.Errorf("posts = %+v, want the summary of shop posted to its channel through the webhook", posts)
+	}
+
+	mm.Fail(http.StatusForbidden)
+	if _, err := notifyCombined(t, mm.Notifier(), criticalReport(), models.NotificationConfig{MattermostEnabled: true}); err == nil {
+		t.Error("NotifyAllCombined succeeded with a failing Mattermost")
+	}
+}
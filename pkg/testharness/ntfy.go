@@ -0,0 +1,121 @@
+package testharness
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// MockNtfyToken is the access token the mock ntfy server accepts
+const MockNtfyToken = "tk_testharness"
+
+// NtfyAction is a button of a message published to the mock ntfy server
+type NtfyAction struct {
+	Action string `json:"action"`
+	Label  string `json:"label"`
+	URL    string `json:"url"`
+}
+
+// NtfyMessage is a message published to the mock ntfy server
+type NtfyMessage struct {
+	Topic    string       `json:"topic"`
+	Title    string       `json:"title"`
+	Message  string       `json:"message"`
+	Priority int          `json:"priority"`
+	Tags     []string     `json:"tags"`
+	Click    string       `json:"click"`
+	Actions  []NtfyAction `json:"actions"`
+}
+
+// MockNtfy is a ntfy server recording the messages published as JSON
+type MockNtfy struct {
+	Server *httptest.Server
+
+	messages   []NtfyMessage
+	failStatus int
+	mu         sync.Mutex
+}
+
+// NewMockNtfy starts a mock ntfy server, stopped when the test ends
+func NewMockNtfy(t testing.TB) *MockNtfy {
+	t.Helper()
+
+	m := &MockNtfy{}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Notifier returns a ntfy notifier publishing to the mock server, with the default
+// priorities; reportDir and reportURL enable the report buttons when set
+func (m *MockNtfy) Notifier(topicPrefix, reportDir, reportURL string) *notifier.NtfyNotifier {
+	return notifier.NewNtfyNotifier(notifier.NtfyConfig{
+		ServerURL:   m.Server.URL,
+		Token:       MockNtfyToken,
+		TopicPrefix: topicPrefix,
+		ReportURL:   reportURL,
+		ReportDir:   reportDir,
+	}, true)
+}
+
+// Fail makes every following request fail with status
+func (m *MockNtfy) Fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus = status
+}
+
+// Messages returns the messages published, in order
+func (m *MockNtfy) Messages() []NtfyMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]NtfyMessage(nil), m.messages...)
+}
+
+// handle answers POST / with a JSON message
+func (m *MockNtfy) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Header.Get("Authorization") != "Bearer "+MockNtfyToken {
+		writeNtfyError(w, http.StatusUnauthorized, 40101, "unauthorized")
+		return
+	}
+	if r.Method != http.MethodPost || r.URL.Path != "/" {
+		writeNtfyError(w, http.StatusNotFound, 40401, "page not found")
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failStatus != 0 {
+		writeNtfyError(w, m.failStatus, m.failStatus*100, "testharness failure")
+		return
+	}
+
+	var message NtfyMessage
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil || message.Topic == "" {
+		writeNtfyError(w, http.StatusBadRequest, 40005, "invalid request: topic invalid")
+		return
+	}
+	m.messages = append(m.messages, message)
+
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"id":       "testharness",
+		"event":    "message",
+		"topic":    message.Topic,
+		"title":    message.Title,
+		"message":  message.Message,
+		"priority": message.Priority,
+	})
+}
+
+// writeNtfyError writes an error response as the ntfy server does
+func writeNtfyError(w http.ResponseWriter, status, code int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"code": code, "http": status, "error": message})
+}
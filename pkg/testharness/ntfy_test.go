@@ -0,0 +1,38 @@
+package testharness_test
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestMockNtfy(t *testing.T) {
+	ntfy := testharness.NewMockNtfy(t)
+	n := ntfy.Notifier("audit", "", "")
+
+	if _, err := notifyCombined(t, n, criticalReport(), models.NotificationConfig{NtfyEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	messages := ntfy.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("messages = %+v, want 1", messages)
+	}
+	if m := messages[0]; m.Topic != "audit-shop" || m.Priority != 5 || !strings.Contains(m.Title+m.Message, "shop") {
+		t.Errorf("message = %+v, want an urgent message of shop to audit-shop", m)
+	}
+
+	if _, err := notifyCombined(t, n, criticalReport(), models.NotificationConfig{NtfyEnabled: true, NtfyTopic: "shop-team"}); err != nil {
+		t.Fatal(err)
+	}
+	if messages := ntfy.Messages(); messages[len(messages)-1].Topic != "shop-team" {
+		t.Errorf("topic = %s, want the app's own topic", messages[len(messages)-1].Topic)
+	}
+
+	ntfy.Fail(http.StatusTooManyRequests)
+	if _, err := notifyCombined(t, n, criticalReport(), models.NotificationConfig{NtfyEnabled: true}); err == nil {
+		t.Error("NotifyAllCombined succeeded with a failing ntfy")
+	}
+}
@@ -0,0 +1,164 @@
+package testharness
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// MockOpsgenieAPIKey is the API key the mock Opsgenie server accepts
+const MockOpsgenieAPIKey = "testharness-genie-key"
+
+// OpsgenieAlert is an alert created on the mock Opsgenie server
+type OpsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description"`
+	Tags        []string          `json:"tags"`
+	Details     map[string]string `json:"details"`
+	Entity      string            `json:"entity"`
+	Source      string            `json:"source"`
+	Priority    string            `json:"priority"`
+	Count       int               `json:"-"` // create requests deduplicated into the alert
+	Open        bool              `json:"-"`
+}
+
+// MockOpsgenie is an Opsgenie Alert API server deduplicating alerts by alias, as
+// Opsgenie does, and recording which are open
+type MockOpsgenie struct {
+	Server *httptest.Server
+
+	alerts     []*OpsgenieAlert
+	closed     []string // aliases of close requests, in order
+	failStatus int
+	mu         sync.Mutex
+}
+
+// NewMockOpsgenie starts a mock Opsgenie server, stopped when the test ends
+func NewMockOpsgenie(t testing.TB) *MockOpsgenie {
+	t.Helper()
+
+	m := &MockOpsgenie{}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Notifier returns an Opsgenie notifier sending alerts to the mock server, with the
+// default priorities
+func (m *MockOpsgenie) Notifier() *notifier.OpsgenieNotifier {
+	return notifier.NewOpsgenieNotifier(MockOpsgenieAPIKey, m.Server.URL, nil, true)
+}
+
+// Fail makes every following request fail with status
+func (m *MockOpsgenie) Fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus = status
+}
+
+// Alerts returns copies of the alerts created, in order
+func (m *MockOpsgenie) Alerts() []OpsgenieAlert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	alerts := make([]OpsgenieAlert, len(m.alerts))
+	for i, alert := range m.alerts {
+		alerts[i] = *alert
+	}
+	return alerts
+}
+
+// OpenAlerts returns the aliases of the open alerts
+func (m *MockOpsgenie) OpenAlerts() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var aliases []string
+	for _, alert := range m.alerts {
+		if alert.Open {
+			aliases = append(aliases, alert.Alias)
+		}
+	}
+	return aliases
+}
+
+// Closed returns the aliases of the close requests received, in order
+func (m *MockOpsgenie) Closed() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.closed...)
+}
+
+// handle answers POST /v2/alerts and POST /v2/alerts/{alias}/close?identifierType=alias
+func (m *MockOpsgenie) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Header.Get("Authorization") != "GenieKey "+MockOpsgenieAPIKey {
+		writeOpsgenieResponse(w, http.StatusUnauthorized, "Key format is not valid!")
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failStatus != 0 {
+		writeOpsgenieResponse(w, m.failStatus, "testharness failure")
+		return
+	}
+
+	path := r.URL.Path
+	switch {
+	case r.Method == http.MethodPost && path == "/v2/alerts":
+		var alert OpsgenieAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil || alert.Message == "" {
+			writeOpsgenieResponse(w, http.StatusUnprocessableEntity, "Message can not be empty.")
+			return
+		}
+		for _, existing := range m.alerts {
+			if existing.Open && existing.Alias == alert.Alias {
+				existing.Count++
+				writeOpsgenieResponse(w, http.StatusAccepted, "")
+				return
+			}
+		}
+		alert.Count = 1
+		alert.Open = true
+		m.alerts = append(m.alerts, &alert)
+		writeOpsgenieResponse(w, http.StatusAccepted, "")
+
+	case r.Method == http.MethodPost && strings.HasPrefix(path, "/v2/alerts/") && strings.HasSuffix(path, "/close"):
+		if r.URL.Query().Get("identifierType") != "alias" {
+			writeOpsgenieResponse(w, http.StatusBadRequest, "identifierType must be alias")
+			return
+		}
+		alias := strings.TrimSuffix(strings.TrimPrefix(path, "/v2/alerts/"), "/close")
+		m.closed = append(m.closed, alias)
+		for _, alert := range m.alerts {
+			if alert.Alias == alias {
+				alert.Open = false
+			}
+		}
+		writeOpsgenieResponse(w, http.StatusAccepted, "")
+
+	default:
+		writeOpsgenieResponse(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// writeOpsgenieResponse writes an Alert API response; requests are accepted with 202
+func writeOpsgenieResponse(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	body := map[string]any{"took": 0.01, "requestId": "testharness"}
+	if status == http.StatusAccepted {
+		body["result"] = "Request will be processed"
+	} else {
+		body["message"] = message
+	}
+	_ = json.NewEncoder(w).Encode(body)
+}
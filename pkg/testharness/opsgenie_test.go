@@ -0,0 +1,46 @@
+package testharness_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+// TestMockOpsgenie follows the alerts of an app through the manager: created every
+// run, deduplicated by alias and closed once the findings are gone
+func TestMockOpsgenie(t *testing.T) {
+	ctx := context.Background()
+	og := testharness.NewMockOpsgenie(t)
+	manager := notifier.NewManager(false)
+	manager.Register(og.Notifier())
+	config := models.NotificationConfig{AppName: "shop", OpsgenieEnabled: true}
+
+	alias := notifier.OpsgenieAlias("shop", "npm", models.SeverityCritical)
+	for range 2 {
+		aliases, err := manager.SyncOpsgenie(ctx, criticalReport(), config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(aliases, []string{alias}) {
+			t.Fatalf("aliases = %v, want %s", aliases, alias)
+		}
+		config.OpsgenieAliases = aliases
+	}
+
+	alerts := og.Alerts()
+	if len(alerts) != 1 || alerts[0].Alias != alias || alerts[0].Count != 2 || alerts[0].Priority != "P1" {
+		t.Fatalf("alerts = %+v, want one P1 alert created twice", alerts)
+	}
+
+	aliases, err := manager.SyncOpsgenie(ctx, cleanReport(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aliases) != 0 || len(og.OpenAlerts()) != 0 || !slices.Equal(og.Closed(), []string{alias}) {
+		t.Errorf("aliases = %v, open = %v, closed = %v, want the alert closed", aliases, og.OpenAlerts(), og.Closed())
+	}
+}
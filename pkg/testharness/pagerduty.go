@@ -0,0 +1,133 @@
+package testharness
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// MockPagerDutyRoutingKey is the routing key the mock PagerDuty server accepts
+const MockPagerDutyRoutingKey = "testharness-routing-key"
+
+// PagerDutyEvent is an event received by the mock PagerDuty server
+type PagerDutyEvent struct {
+	RoutingKey  string `json:"routing_key"`
+	EventAction string `json:"event_action"` // trigger or resolve
+	DedupKey    string `json:"dedup_key"`
+	Payload     *struct {
+		Summary       string         `json:"summary"`
+		Source        string         `json:"source"`
+		Severity      string         `json:"severity"`
+		CustomDetails map[string]any `json:"custom_details"`
+	} `json:"payload"`
+}
+
+// MockPagerDuty is a PagerDuty Events API v2 server recording the events and the
+// incidents they leave open
+type MockPagerDuty struct {
+	Server *httptest.Server
+
+	events     []PagerDutyEvent
+	open       map[string]bool // dedup key -> incident open
+	failStatus int
+	mu         sync.Mutex
+}
+
+// NewMockPagerDuty starts a mock PagerDuty server, stopped when the test ends
+func NewMockPagerDuty(t testing.TB) *MockPagerDuty {
+	t.Helper()
+
+	m := &MockPagerDuty{open: make(map[string]bool)}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// URL returns the endpoint to pass to notifier.NewPagerDutyNotifierWithEndpoint
+func (m *MockPagerDuty) URL() string {
+	return m.Server.URL + "/v2/enqueue"
+}
+
+// Notifier returns a PagerDuty notifier sending events to the mock server
+func (m *MockPagerDuty) Notifier() *notifier.PagerDutyNotifier {
+	return notifier.NewPagerDutyNotifierWithEndpoint(MockPagerDutyRoutingKey, true, m.URL())
+}
+
+// Fail makes every following event fail with status
+func (m *MockPagerDuty) Fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus = status
+}
+
+// Events returns the events received, in order
+func (m *MockPagerDuty) Events() []PagerDutyEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]PagerDutyEvent(nil), m.events...)
+}
+
+// OpenIncidents returns the dedup keys of the incidents triggered and not resolved
+func (m *MockPagerDuty) OpenIncidents() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var keys []string
+	for key, open := range m.open {
+		if open {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// handle answers POST /v2/enqueue
+func (m *MockPagerDuty) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost || r.URL.Path != "/v2/enqueue" {
+		writePagerDutyError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	var event PagerDutyEvent
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		writePagerDutyError(w, http.StatusBadRequest, "Invalid JSON body")
+		return
+	}
+	if event.RoutingKey != MockPagerDutyRoutingKey {
+		writePagerDutyError(w, http.StatusBadRequest, "Invalid routing key")
+		return
+	}
+
+	m.mu.Lock()
+	status := m.failStatus
+	if status == 0 {
+		m.events = append(m.events, event)
+		switch event.EventAction {
+		case "trigger":
+			m.open[event.DedupKey] = true
+		case "resolve":
+			delete(m.open, event.DedupKey)
+		}
+	}
+	m.mu.Unlock()
+
+	if status != 0 {
+		writePagerDutyError(w, status, "testharness failure")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": "success", "message": "Event processed", "dedup_key": event.DedupKey})
+}
+
+// writePagerDutyError writes an Events API error response
+func writePagerDutyError(w http.ResponseWriter, status int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": "invalid event", "message": message, "errors": []string{message}})
+}
@@ -0,0 +1,56 @@
+package testharness_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+// TestMockPagerDuty follows the incident of an app through the manager: triggered
+// when criticals are found, kept while they stay and resolved once they are gone
+func TestMockPagerDuty(t *testing.T) {
+	ctx := context.Background()
+	pd := testharness.NewMockPagerDuty(t)
+	manager := notifier.NewManager(false)
+	manager.Register(pd.Notifier())
+	config := models.NotificationConfig{AppName: "shop", PagerDutyEnabled: true}
+
+	key, err := manager.SyncPagerDuty(ctx, criticalReport(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if open := pd.OpenIncidents(); !slices.Equal(open, []string{key}) {
+		t.Fatalf("open incidents = %v, want %s", open, key)
+	}
+	event := pd.Events()[0]
+	if event.EventAction != "trigger" || event.Payload == nil || event.Payload.Severity != "critical" || !strings.Contains(event.Payload.Summary, "CVE-2021-23337") {
+		t.Errorf("event = %+v, want a critical trigger of CVE-2021-23337", event)
+	}
+
+	config.PagerDutyDedupKey = key
+	if _, err := manager.SyncPagerDuty(ctx, criticalReport(), config); err != nil {
+		t.Fatal(err)
+	}
+	if events := pd.Events(); len(events) != 1 {
+		t.Errorf("events = %+v, want no new event while the incident is open", events)
+	}
+
+	pd.Fail(http.StatusInternalServerError)
+	if got, err := manager.SyncPagerDuty(ctx, cleanReport(), config); err == nil || got != key {
+		t.Errorf("SyncPagerDuty = %q, %v, want the open incident kept with an error", got, err)
+	}
+
+	pd.Fail(0)
+	if got, err := manager.SyncPagerDuty(ctx, cleanReport(), config); err != nil || got != "" {
+		t.Fatalf("SyncPagerDuty = %q, %v, want the incident resolved", got, err)
+	}
+	if open := pd.OpenIncidents(); len(open) != 0 {
+		t.Errorf("open incidents = %v, want none", open)
+	}
+}
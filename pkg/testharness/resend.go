@@ -0,0 +1,110 @@
+package testharness
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// MockResendAPIKey is the API key the mock Resend server accepts
+const MockResendAPIKey = "re_testharness"
+
+// Email is an email received by the mock Resend server
+type Email struct {
+	From    string   `json:"from"`
+	To      []string `json:"to"`
+	Subject string   `json:"subject"`
+	HTML    string   `json:"html"`
+	Text    string   `json:"text,omitempty"` // plain-text alternative
+}
+
+// MockResend is a Resend API server recording the emails sent
+type MockResend struct {
+	Server *httptest.Server
+
+	emails      []Email
+	failStatus  int
+	failMessage string
+	mu          sync.Mutex
+}
+
+// NewMockResend starts a mock Resend server, stopped when the test ends
+func NewMockResend(t testing.TB) *MockResend {
+	t.Helper()
+
+	m := &MockResend{}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// URL returns the endpoint to pass to notifier.NewEmailNotifierWithURL
+func (m *MockResend) URL() string {
+	return m.Server.URL + "/emails"
+}
+
+// Notifier returns an email notifier sending from fromEmail through the mock server
+func (m *MockResend) Notifier(fromEmail string) *notifier.EmailNotifier {
+	return notifier.NewEmailNotifierWithURL(MockResendAPIKey, fromEmail, m.URL())
+}
+
+// Fail makes every following email fail with status and message, as Resend reports errors
+func (m *MockResend) Fail(status int, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus, m.failMessage = status, message
+}
+
+// Emails returns the emails received, in order
+func (m *MockResend) Emails() []Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Email(nil), m.emails...)
+}
+
+// handle answers POST /emails
+func (m *MockResend) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost || r.URL.Path != "/emails" {
+		writeResendError(w, http.StatusNotFound, "not_found", "The requested endpoint does not exist.")
+		return
+	}
+	if strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ") != MockResendAPIKey {
+		writeResendError(w, http.StatusUnauthorized, "validation_error", "API key is invalid")
+		return
+	}
+
+	var email Email
+	if err := json.NewDecoder(r.Body).Decode(&email); err != nil {
+		writeResendError(w, http.StatusUnprocessableEntity, "validation_error", "Invalid JSON body")
+		return
+	}
+
+	m.mu.Lock()
+	status, message := m.failStatus, m.failMessage
+	if status == 0 {
+		m.emails = append(m.emails, email)
+	}
+	count := len(m.emails)
+	m.mu.Unlock()
+
+	if status != 0 {
+		writeResendError(w, status, "application_error", message)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]any{"id": fmt.Sprintf("testharness-%d", count)})
+}
+
+// writeResendError writes a Resend API error response
+func writeResendError(w http.ResponseWriter, status int, name, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"statusCode": status, "name": name, "message": message})
+}
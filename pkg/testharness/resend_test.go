@@ -0,0 +1,65 @@
+package testharness_test
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+// checkReportEmail checks the email of shop's npm report sent by the email notifier
+func checkReportEmail(t *testing.T, emails []testharness.Email) {
+	t.Helper()
+
+	if len(emails) != 1 {
+		t.Fatalf("emails = %+v, want 1", emails)
+	}
+	email := emails[0]
+	if email.From != "audit@example.com" || !slices.Equal(email.To, []string{"dev@example.com"}) {
+		t.Errorf("email from %s to %v, want from audit@example.com to dev@example.com", email.From, email.To)
+	}
+	if !strings.Contains(email.Subject, "shop") {
+		t.Errorf("subject = %q, want the app", email.Subject)
+	}
+	for _, body := range []string{email.HTML, email.Text} {
+		if !strings.Contains(body, "CVE-2021-23337") {
+			t.Errorf("body = %q, want the critical CVE", body)
+		}
+	}
+}
+
+func TestMockResend(t *testing.T) {
+	ctx := context.Background()
+	resend := testharness.NewMockResend(t)
+	n := resend.Notifier("audit@example.com")
+
+	report := criticalReport().Reports[0]
+	if err := n.Send(ctx, report, []string{"dev@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	checkReportEmail(t, resend.Emails())
+
+	violations := []models.PolicyViolation{{Policy: "no-old-criticals-in-prod", AppName: "shop", Action: models.PolicyActionFail, Subjects: []string{"lodash CVE-2021-23337"}}}
+	if err := n.SendPolicyViolations(ctx, "shop", "01TESTRUN", violations, []string{"dev@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if emails := resend.Emails(); len(emails) != 2 || !strings.Contains(emails[1].Text, "no-old-criticals-in-prod") {
+		t.Errorf("emails = %+v, want the policy violation", emails)
+	}
+
+	resend.Fail(http.StatusTooManyRequests, "Too many requests")
+	if err := n.SendTestEmail(ctx, []string{"dev@example.com"}); err == nil || !strings.Contains(err.Error(), "Too many requests") {
+		t.Errorf("SendTestEmail error = %v, want the Resend error", err)
+	}
+	if len(resend.Emails()) != 2 {
+		t.Error("a failed email was recorded")
+	}
+
+	if err := testharness.NewMockResend(t).Notifier("audit@example.com").Send(ctx, report, nil); err != nil {
+		t.Errorf("Send without recipients: %v", err)
+	}
+}
@@ -0,0 +1,224 @@
+package testharness
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// MockSMTP credentials the mock SMTP server accepts
+const (
+	MockSMTPUsername = "testharness"
+	MockSMTPPassword = "testharness-password"
+)
+
+// MockSMTP is an unencrypted SMTP server on localhost recording the emails sent, with
+// the same Email records as MockResend
+type MockSMTP struct {
+	Listener net.Listener
+
+	emails      []Email
+	failCode    int
+	failMessage string
+	mu          sync.Mutex
+}
+
+// NewMockSMTP starts a mock SMTP server, stopped when the test ends
+func NewMockSMTP(t testing.TB) *MockSMTP {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("testharness: failed to start SMTP server: %v", err)
+	}
+
+	m := &MockSMTP{Listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go m.serve(conn)
+		}
+	}()
+	return m
+}
+
+// Config returns the SMTP settings of the mock server
+func (m *MockSMTP) Config() notifier.SMTPConfig {
+	addr := m.Listener.Addr().(*net.TCPAddr)
+	return notifier.SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     addr.Port,
+		Username: MockSMTPUsername,
+		Password: MockSMTPPassword,
+		TLS:      notifier.SMTPTLSNone,
+	}
+}
+
+// Notifier returns an email notifier sending from fromEmail through the mock server
+func (m *MockSMTP) Notifier(fromEmail string) *notifier.EmailNotifier {
+	return notifier.NewSMTPEmailNotifier(m.Config(), fromEmail)
+}
+
+// Fail makes every following message be rejected with an SMTP reply code (e.g. 554) and message
+func (m *MockSMTP) Fail(code int, message string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failCode, m.failMessage = code, message
+}
+
+// Emails returns the emails received, in order. From and To hold the envelope addresses.
+func (m *MockSMTP) Emails() []Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Email(nil), m.emails...)
+}
+
+// serve answers the commands of one SMTP session
+func (m *MockSMTP) serve(conn net.Conn) {
+	text := textproto.NewConn(conn)
+	defer text.Close()
+
+	reply := func(format string, args ...any) bool {
+		return text.PrintfLine(format, args...) == nil
+	}
+
+	if !reply("220 testharness ESMTP") {
+		return
+	}
+
+	var email Email
+	authenticated := false
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+
+		ok := true
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			ok = reply("250-testharness") && reply("250-8BITMIME") && reply("250 AUTH PLAIN")
+		case "AUTH":
+			mechanism, initial, _ := strings.Cut(arg, " ")
+			credentials, _ := base64.StdEncoding.DecodeString(initial)
+			if strings.ToUpper(mechanism) == "PLAIN" && string(credentials) == "\x00"+MockSMTPUsername+"\x00"+MockSMTPPassword {
+				authenticated = true
+				ok = reply("235 2.7.0 Authentication successful")
+			} else {
+				ok = reply("535 5.7.8 Authentication credentials invalid")
+			}
+		case "MAIL":
+			if !authenticated {
+				ok = reply("530 5.7.0 Authentication required")
+				break
+			}
+			email = Email{From: smtpPath(arg)}
+			ok = reply("250 2.1.0 OK")
+		case "RCPT":
+			email.To = append(email.To, smtpPath(arg))
+			ok = reply("250 2.1.5 OK")
+		case "DATA":
+			if !reply("354 End data with <CR><LF>.<CR><LF>") {
+				return
+			}
+			data, err := io.ReadAll(text.DotReader())
+			if err != nil {
+				return
+			}
+			ok = m.receive(email, data, reply)
+		case "RSET", "NOOP":
+			ok = reply("250 2.0.0 OK")
+		case "QUIT":
+			reply("221 2.0.0 Bye")
+			return
+		default:
+			ok = reply("502 5.5.2 Command not recognized")
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// receive records a message sent with DATA, or rejects it when failing
+func (m *MockSMTP) receive(email Email, data []byte, reply func(string, ...any) bool) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failCode != 0 {
+		return reply("%d %s", m.failCode, m.failMessage)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		return reply("554 5.6.0 Malformed message: %s", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		return reply("554 5.6.0 Malformed subject: %s", err)
+	}
+	email.Subject = subject
+
+	// A multipart/alternative message holds the plain-text alternative and the HTML body
+	mediaType, params, _ := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if mediaType != "multipart/alternative" {
+		body := io.Reader(msg.Body)
+		if strings.EqualFold(msg.Header.Get("Content-Transfer-Encoding"), "quoted-printable") {
+			body = quotedprintable.NewReader(body)
+		}
+		html, err := io.ReadAll(body)
+		if err != nil {
+			return reply("554 5.6.0 Malformed body: %s", err)
+		}
+		email.HTML = string(html)
+	} else {
+		parts := multipart.NewReader(msg.Body, params["boundary"])
+		for {
+			// NextPart decodes quoted-printable parts
+			part, err := parts.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return reply("554 5.6.0 Malformed body: %s", err)
+			}
+			content, err := io.ReadAll(part)
+			if err != nil {
+				return reply("554 5.6.0 Malformed body: %s", err)
+			}
+			switch partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type")); partType {
+			case "text/plain":
+				email.Text = string(content)
+			case "text/html":
+				email.HTML = string(content)
+			}
+		}
+	}
+
+	m.emails = append(m.emails, email)
+	return reply("250 2.0.0 OK queued as %d", len(m.emails))
+}
+
+// smtpPath returns the address of a MAIL FROM:<address> or RCPT TO:<address> argument
+func smtpPath(arg string) string {
+	_, path, _ := strings.Cut(arg, ":")
+	path, _, _ = strings.Cut(strings.TrimSpace(path), " ")
+	return strings.Trim(path, "<>")
+}
@@ -0,0 +1,28 @@
+package testharness_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestMockSMTP(t *testing.T) {
+	ctx := context.Background()
+	smtp := testharness.NewMockSMTP(t)
+	n := smtp.Notifier("audit@example.com")
+
+	if err := n.Send(ctx, criticalReport().Reports[0], []string{"dev@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	checkReportEmail(t, smtp.Emails())
+
+	smtp.Fail(554, "Message rejected")
+	if err := n.SendTestEmail(ctx, []string{"dev@example.com"}); err == nil || !strings.Contains(err.Error(), "554") {
+		t.Errorf("SendTestEmail error = %v, want the SMTP reply", err)
+	}
+	if len(smtp.Emails()) != 1 {
+		t.Error("a rejected email was recorded")
+	}
+}
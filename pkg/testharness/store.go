@@ -0,0 +1,597 @@
+package testharness
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"github.com/shadowbane/audit-checks/pkg/models"
+)
+
+// MemoryStore implements the store.Store interface in memory. Apps are seeded
+// with AddApp; everything the application saves can be inspected afterwards.
+type MemoryStore struct {
+	apps       []models.App
+	results    []models.AuditResult
+	events     []models.RunEvent
+	activities []models.ActivityLog
+	packages   []models.AppPackage
+	deliveries []models.EmailDelivery
+	suppressed []models.EmailSuppression
+	triages    []models.FindingTriage
+	analyses   map[string]models.CachedAIAnalysis
+	settings   map[string]string
+	mu         sync.Mutex
+}
+
+// NewMemoryStore creates a new, empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{analyses: make(map[string]models.CachedAIAnalysis), settings: make(map[string]string)}
+}
+
+// AddEmailSuppression suppresses an email address, as a bounce or 'suppressions add' does
+func (s *MemoryStore) AddEmailSuppression(suppression models.EmailSuppression) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	suppression.Address = strings.ToLower(suppression.Address)
+	s.suppressed = append(s.suppressed, suppression)
+}
+
+// AddFindingTriage adds a triage decision on a finding of an app
+func (s *MemoryStore) AddFindingTriage(triage models.FindingTriage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.triages = append(s.triages, triage)
+}
+
+// AddApp adds an app, assigning its ID
+func (s *MemoryStore) AddApp(app models.App) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if app.ID == "" {
+		app.ID = helpers.MustNewULID()
+	}
+	now := time.Now()
+	app.CreatedAt, app.UpdatedAt = now, now
+	s.apps = append(s.apps, app)
+}
+
+// Apps returns every app
+func (s *MemoryStore) Apps() ([]models.App, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.apps), nil
+}
+
+// ResumeExpiredPauses re-enables apps whose pause ended before now
+func (s *MemoryStore) ResumeExpiredPauses(now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resumed int64
+	for i := range s.apps {
+		if s.apps[i].PausedUntil != nil && !s.apps[i].PausedUntil.After(now) {
+			s.apps[i].PausedUntil = nil
+			resumed++
+		}
+	}
+	return resumed, nil
+}
+
+// SaveTelegramTopicID stores the Telegram forum topic created for an app
+func (s *MemoryStore) SaveTelegramTopicID(appName string, topicID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apps {
+		if s.apps[i].Name == appName {
+			s.apps[i].TelegramTopicID = topicID
+			return nil
+		}
+	}
+	return fmt.Errorf("app %s not found", appName)
+}
+
+// SaveDiscordThreadID stores the Discord thread created for an app
+func (s *MemoryStore) SaveDiscordThreadID(appName, threadID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apps {
+		if s.apps[i].Name == appName {
+			s.apps[i].DiscordThreadID = threadID
+			return nil
+		}
+	}
+	return fmt.Errorf("app %s not found", appName)
+}
+
+// SavePagerDutyDedupKey stores the deduplication key of an app's open PagerDuty incident
+func (s *MemoryStore) SavePagerDutyDedupKey(appName, dedupKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apps {
+		if s.apps[i].Name == appName {
+			s.apps[i].PagerDutyDedupKey = dedupKey
+			return nil
+		}
+	}
+	return fmt.Errorf("app %s not found", appName)
+}
+
+// SaveOpsgenieAliases stores the aliases of an app's open Opsgenie alerts
+func (s *MemoryStore) SaveOpsgenieAliases(appName string, aliases []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apps {
+		if s.apps[i].Name == appName {
+			s.apps[i].OpsgenieAliases = aliases
+			return nil
+		}
+	}
+	return fmt.Errorf("app %s not found", appName)
+}
+
+// SaveGitLabIssue stores the reference of an app's open GitLab issue
+func (s *MemoryStore) SaveGitLabIssue(appName, issue string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apps {
+		if s.apps[i].Name == appName {
+			s.apps[i].GitLabIssue = issue
+			return nil
+		}
+	}
+	return fmt.Errorf("app %s not found", appName)
+}
+
+// SaveNotificationStatus stores when an app was last notified and the channels that failed
+func (s *MemoryStore) SaveNotificationStatus(appName string, at time.Time, failed []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.apps {
+		if s.apps[i].Name == appName {
+			s.apps[i].LastNotifiedAt = &at
+			s.apps[i].LastNotifyFailed = failed
+			return nil
+		}
+	}
+	return fmt.Errorf("app %s not found", appName)
+}
+
+// SaveAuditResult stores an audit result, assigning IDs and creation times like the database does
+func (s *MemoryStore) SaveAuditResult(result *models.AuditResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if result.ID == "" {
+		result.ID = helpers.MustNewULID()
+	}
+	if result.CreatedAt.IsZero() {
+		result.CreatedAt = time.Now()
+	}
+	for i := range result.Vulnerabilities {
+		v := &result.Vulnerabilities[i]
+		if v.ID == "" {
+			v.ID = helpers.MustNewULID()
+		}
+		v.AuditResultID = result.ID
+		if v.CreatedAt.IsZero() {
+			v.CreatedAt = result.CreatedAt
+		}
+	}
+
+	stored := *result
+	stored.Vulnerabilities = slices.Clone(result.Vulnerabilities)
+	s.results = append(s.results, stored)
+	return nil
+}
+
+// AuditResults returns every audit result, oldest first, without vulnerabilities or raw output
+func (s *MemoryStore) AuditResults() ([]models.AuditResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]models.AuditResult, 0, len(s.results))
+	for _, r := range s.results {
+		results = append(results, summary(r))
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].CreatedAt.Before(results[j].CreatedAt) })
+	return results, nil
+}
+
+// RecentAuditResults returns up to limit audit results of an app, newest first
+func (s *MemoryStore) RecentAuditResults(appName string, limit int) ([]models.AuditResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var results []models.AuditResult
+	for _, r := range s.results {
+		if r.AppName == appName {
+			results = append(results, summary(r))
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].CreatedAt.After(results[j].CreatedAt) })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Vulnerabilities returns the vulnerabilities of the given audit results
+func (s *MemoryStore) Vulnerabilities(resultIDs []string) ([]models.Vulnerability, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var vulns []models.Vulnerability
+	for _, r := range s.results {
+		if slices.Contains(resultIDs, r.ID) {
+			vulns = append(vulns, r.Vulnerabilities...)
+		}
+	}
+	return vulns, nil
+}
+
+// VulnerabilityHistory returns every vulnerability ever reported
+func (s *MemoryStore) VulnerabilityHistory() ([]models.Vulnerability, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var history []models.Vulnerability
+	for _, r := range s.results {
+		history = append(history, r.Vulnerabilities...)
+	}
+	return history, nil
+}
+
+// FindingsFirstSeen returns when each finding of an app and auditor was first seen
+func (s *MemoryStore) FindingsFirstSeen(appName, auditorType string) (map[string]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[string]time.Time)
+	for _, r := range s.results {
+		if r.AppName != appName || r.AuditorType != auditorType {
+			continue
+		}
+		for _, v := range r.Vulnerabilities {
+			at := r.CreatedAt
+			if v.FirstSeenAt != nil && v.FirstSeenAt.Before(at) {
+				at = *v.FirstSeenAt
+			}
+			if prev, ok := seen[v.FindingKey()]; !ok || at.Before(prev) {
+				seen[v.FindingKey()] = at
+			}
+		}
+	}
+	return seen, nil
+}
+
+// VulnerabilitiesFirstSeenSince returns the vulnerabilities first seen at or after since
+func (s *MemoryStore) VulnerabilitiesFirstSeenSince(since time.Time) ([]models.Vulnerability, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var vulns []models.Vulnerability
+	for _, r := range s.results {
+		for _, v := range r.Vulnerabilities {
+			if v.FirstSeenAt != nil && !v.FirstSeenAt.Before(since) {
+				vulns = append(vulns, v)
+			}
+		}
+	}
+	return vulns, nil
+}
+
+// SaveRunEvent stores a progress event of a run
+func (s *MemoryStore) SaveRunEvent(event *models.RunEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	event.ID = uint(len(s.events) + 1)
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	s.events = append(s.events, *event)
+	return nil
+}
+
+// PurgeRunEvents deletes progress events created before cutoff
+func (s *MemoryStore) PurgeRunEvents(cutoff time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = slices.DeleteFunc(s.events, func(e models.RunEvent) bool { return e.CreatedAt.Before(cutoff) })
+	return nil
+}
+
+// PurgeAppData deletes the history of an app created before purge.Before. It holds
+// no jobs, so purges none.
+func (s *MemoryStore) PurgeAppData(purge *models.DataPurge) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	latest := make(map[string]time.Time)
+	for _, r := range s.results {
+		if r.AppName == purge.AppName && r.CreatedAt.After(latest[r.AuditorType]) {
+			latest[r.AuditorType] = r.CreatedAt
+		}
+	}
+	purged := func(r models.AuditResult) bool {
+		return r.AppName == purge.AppName && r.CreatedAt.Before(purge.Before) &&
+			!(purge.KeepLatest && r.CreatedAt.Equal(latest[r.AuditorType]))
+	}
+
+	var kept []models.AuditResult
+	var candidates, logFiles []string
+	for _, r := range s.results {
+		if !purged(r) {
+			kept = append(kept, r)
+			continue
+		}
+		purge.AuditResults++
+		purge.Vulnerabilities += int64(len(r.Vulnerabilities))
+		for _, file := range []string{r.RawOutputFile, r.LogFile} {
+			if file != "" && !slices.Contains(candidates, file) {
+				candidates = append(candidates, file)
+			}
+		}
+		if r.LogFile != "" {
+			logFiles = append(logFiles, r.LogFile)
+		}
+	}
+
+	var files []string
+	for _, file := range candidates {
+		refers := func(r models.AuditResult) bool { return r.RawOutputFile == file || r.LogFile == file }
+		own := func(r models.AuditResult) bool { return refers(r) && r.AppName == purge.AppName }
+		switch {
+		case !slices.ContainsFunc(kept, refers):
+			files = append(files, file)
+		case !slices.ContainsFunc(kept, own) && slices.Contains(logFiles, file):
+			purge.RedactedFiles = append(purge.RedactedFiles, file)
+		default:
+			purge.KeptFiles = append(purge.KeptFiles, file)
+		}
+	}
+
+	before := func(appName string, createdAt time.Time) bool {
+		return appName == purge.AppName && createdAt.Before(purge.Before)
+	}
+	events := slices.DeleteFunc(slices.Clone(s.events), func(e models.RunEvent) bool { return before(e.AppName, e.CreatedAt) })
+	deliveries := slices.DeleteFunc(slices.Clone(s.deliveries), func(d models.EmailDelivery) bool { return before(d.AppName, d.CreatedAt) })
+	purge.RunEvents = int64(len(s.events) - len(events))
+	purge.EmailDeliveries = int64(len(s.deliveries) - len(deliveries))
+
+	for key, entry := range s.analyses {
+		if before(entry.AppName, entry.CreatedAt) {
+			purge.AIAnalyses++
+			if !purge.DryRun {
+				delete(s.analyses, key)
+			}
+		}
+	}
+
+	activities := slices.DeleteFunc(slices.Clone(s.activities), func(a models.ActivityLog) bool { return before(a.AppName, a.CreatedAt) })
+	purge.Activities = int64(len(s.activities) - len(activities))
+
+	// The package inventory, triage decisions, email suppressions and notification
+	// state still apply to the latest results, kept for retention
+	packages, triages, suppressed := s.packages, s.triages, s.suppressed
+	i := slices.IndexFunc(s.apps, func(app models.App) bool { return app.Name == purge.AppName })
+	var app models.App
+	if i >= 0 {
+		app = s.apps[i]
+	}
+	if purge.KeepLatest {
+		purge.KeptNotificationState = app.HasNotificationState()
+	} else {
+		packages = slices.DeleteFunc(slices.Clone(s.packages), func(p models.AppPackage) bool { return before(p.AppName, p.CreatedAt) })
+		triages = slices.DeleteFunc(slices.Clone(s.triages), func(t models.FindingTriage) bool { return before(t.AppName, t.UpdatedAt) })
+		purge.AppPackages = int64(len(s.packages) - len(packages))
+		suppressed = slices.DeleteFunc(slices.Clone(s.suppressed), func(e models.EmailSuppression) bool { return before(e.AppName, e.CreatedAt) })
+		purge.Triages = int64(len(s.triages) - len(triages))
+		purge.EmailSuppressions = int64(len(s.suppressed) - len(suppressed))
+
+		if app.LastNotifiedAt != nil && !app.LastNotifiedAt.Before(purge.Before) {
+			purge.KeptNotificationState = true
+		} else if app.HasNotificationState() {
+			purge.NotificationState = true
+			if !purge.DryRun {
+				app.TelegramTopicID, app.DiscordThreadID, app.PagerDutyDedupKey, app.GitLabIssue = 0, "", "", ""
+				app.OpsgenieAliases, app.LastNotifiedAt, app.LastNotifyFailed = nil, nil, nil
+				s.apps[i] = app
+			}
+		}
+	}
+
+	if !purge.DryRun {
+		s.results, s.events, s.deliveries = kept, events, deliveries
+		s.activities, s.suppressed, s.packages, s.triages = activities, suppressed, packages, triages
+	}
+	return files, nil
+}
+
+// CachedAIAnalysis returns the cached AI analysis stored under key, or nil if there is none
+func (s *MemoryStore) CachedAIAnalysis(key string) (*models.CachedAIAnalysis, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.analyses[key]
+	if !ok {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+// SaveCachedAIAnalysis creates or replaces a cached AI analysis
+func (s *MemoryStore) SaveCachedAIAnalysis(entry *models.CachedAIAnalysis) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	s.analyses[entry.Key] = *entry
+	return nil
+}
+
+// PurgeCachedAIAnalyses deletes cached AI analyses created before cutoff
+func (s *MemoryStore) PurgeCachedAIAnalyses(cutoff time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	maps.DeleteFunc(s.analyses, func(_ string, entry models.CachedAIAnalysis) bool { return entry.CreatedAt.Before(cutoff) })
+	return nil
+}
+
+// SaveActivity stores an activity log entry
+func (s *MemoryStore) SaveActivity(entry *models.ActivityLog) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = helpers.MustNewULID()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	s.activities = append(s.activities, *entry)
+	return nil
+}
+
+// SaveAppPackages replaces the packages recorded for an app
+func (s *MemoryStore) SaveAppPackages(appName string, packages []models.AppPackage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.packages = slices.DeleteFunc(s.packages, func(p models.AppPackage) bool { return p.AppName == appName })
+	for _, p := range packages {
+		if p.ID == "" {
+			p.ID = helpers.MustNewULID()
+		}
+		p.CreatedAt = time.Now()
+		s.packages = append(s.packages, p)
+	}
+	return nil
+}
+
+// AppPackages returns the recorded packages of an ecosystem with one of the given names, across all apps
+func (s *MemoryStore) AppPackages(ecosystem string, names []string) ([]models.AppPackage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var packages []models.AppPackage
+	for _, p := range s.packages {
+		if p.Ecosystem == ecosystem && slices.Contains(names, p.Name) {
+			packages = append(packages, p)
+		}
+	}
+	return packages, nil
+}
+
+// Setting returns a stored setting
+func (s *MemoryStore) Setting(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.settings[key]
+	return value, ok, nil
+}
+
+// SaveEmailDelivery creates or replaces an email delivery with its status
+func (s *MemoryStore) SaveEmailDelivery(delivery *models.EmailDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.deliveries {
+		if s.deliveries[i].ID == delivery.ID {
+			s.deliveries[i] = *delivery
+			return nil
+		}
+	}
+	s.deliveries = append(s.deliveries, *delivery)
+	return nil
+}
+
+// EmailSuppressions returns the suppressed email addresses, in the order they were added
+func (s *MemoryStore) EmailSuppressions() ([]models.EmailSuppression, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.suppressed), nil
+}
+
+// FindingTriages returns the triage decisions made on the findings of an app
+func (s *MemoryStore) FindingTriages(appName string) ([]models.FindingTriage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var triages []models.FindingTriage
+	for _, t := range s.triages {
+		if t.AppName == appName {
+			triages = append(triages, t)
+		}
+	}
+	return triages, nil
+}
+
+// SaveSetting creates or replaces a setting
+func (s *MemoryStore) SaveSetting(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings[key] = value
+	return nil
+}
+
+// Close does nothing; the stored data stays available for assertions
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// Results returns the stored audit results with their vulnerabilities, in the order they were saved
+func (s *MemoryStore) Results() []models.AuditResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.results)
+}
+
+// Events returns the stored progress events, in the order they were saved
+func (s *MemoryStore) Events() []models.RunEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.events)
+}
+
+// Activities returns the stored activity log entries, in the order they were saved
+func (s *MemoryStore) Activities() []models.ActivityLog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.activities)
+}
+
+// EmailDeliveries returns the stored email deliveries, in the order they were first saved
+func (s *MemoryStore) EmailDeliveries() []models.EmailDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.deliveries)
+}
+
+// summary returns an audit result without its vulnerabilities and raw output, as listed by the store
+func summary(r models.AuditResult) models.AuditResult {
+	r.Vulnerabilities = nil
+	r.RawOutput = ""
+	return r
+}
@@ -0,0 +1,185 @@
+package testharness_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/store"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+// TestMemoryStore checks that MemoryStore behaves like the SQLite store it stands in
+// for, by running the same calls on both
+func TestMemoryStore(t *testing.T) {
+	stores := []struct {
+		name   string
+		open   func(t *testing.T) store.Store
+		addApp func(t *testing.T, st store.Store, app models.App)
+	}{
+		{
+			name: "memory",
+			open: func(t *testing.T) store.Store { return testharness.NewMemoryStore() },
+			addApp: func(t *testing.T, st store.Store, app models.App) {
+				st.(*testharness.MemoryStore).AddApp(app)
+			},
+		},
+		{
+			name: "sqlite",
+			open: func(t *testing.T) store.Store { return testharness.NewSQLiteStore(t) },
+			addApp: func(t *testing.T, st store.Store, app models.App) {
+				if err := st.(*store.GormStore).DB().Create(&app).Error; err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range stores {
+		t.Run(tt.name, func(t *testing.T) {
+			st := tt.open(t)
+			testStore(t, st, func(app models.App) { tt.addApp(t, st, app) })
+		})
+	}
+}
+
+// testStore runs the calls the application makes on st
+func testStore(t *testing.T, st store.Store, addApp func(models.App)) {
+	now := time.Now().UTC().Truncate(time.Second)
+	pausedUntil := now.Add(-time.Hour)
+	addApp(models.App{Name: "shop", Path: "/srv/shop", Type: "npm", Enabled: true, PausedUntil: &pausedUntil})
+	addApp(models.App{Name: "blog", Path: "/srv/blog", Type: "composer", Enabled: true})
+
+	if resumed, err := st.ResumeExpiredPauses(now); err != nil || resumed != 1 {
+		t.Errorf("ResumeExpiredPauses = %d, %v, want 1 app resumed", resumed, err)
+	}
+	if err := st.SaveTelegramTopicID("shop", 101); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SaveOpsgenieAliases("shop", []string{"audit-checks:shop:npm:critical"}); err != nil {
+		t.Fatal(err)
+	}
+	apps, err := st.Apps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := slices.IndexFunc(apps, func(app models.App) bool { return app.Name == "shop" })
+	if len(apps) != 2 || i < 0 {
+		t.Fatalf("apps = %+v, want shop and blog", apps)
+	}
+	if shop := apps[i]; shop.PausedUntil != nil || shop.TelegramTopicID != 101 || !slices.Equal(shop.OpsgenieAliases, []string{"audit-checks:shop:npm:critical"}) {
+		t.Errorf("shop = %+v, want it resumed with its topic and alerts", shop)
+	}
+
+	// Two audits of shop two days apart, reporting lodash both times
+	old := criticalResult()
+	old.AppName, old.AuditorType, old.CreatedAt = "shop", "npm", now.Add(-48*time.Hour)
+	old.UpdateCounts()
+	latest := criticalResult()
+	latest.AppName, latest.AuditorType, latest.CreatedAt = "shop", "npm", now
+	latest.Vulnerabilities = latest.Vulnerabilities[:1]
+	latest.UpdateCounts()
+	for _, result := range []*models.AuditResult{old, latest} {
+		if err := st.SaveAuditResult(result); err != nil {
+			t.Fatal(err)
+		}
+		if result.ID == "" {
+			t.Fatal("SaveAuditResult assigned no ID")
+		}
+	}
+
+	results, err := st.AuditResults()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ID != old.ID || results[1].ID != latest.ID || results[0].Vulnerabilities != nil {
+		t.Errorf("AuditResults = %+v, want both results oldest first, without vulnerabilities", results)
+	}
+	if recent, err := st.RecentAuditResults("shop", 1); err != nil || len(recent) != 1 || recent[0].ID != latest.ID || recent[0].CriticalCount != 1 {
+		t.Errorf("RecentAuditResults = %+v, %v, want the latest result", recent, err)
+	}
+	vulns, err := st.Vulnerabilities([]string{latest.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vulns) != 1 || vulns[0].PackageName != "lodash" || vulns[0].AuditResultID != latest.ID {
+		t.Errorf("Vulnerabilities = %+v, want lodash of the latest result", vulns)
+	}
+	seen, err := st.FindingsFirstSeen("shop", "npm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if at := seen[vulns[0].FindingKey()]; !at.Equal(old.CreatedAt) || len(seen) != 2 {
+		t.Errorf("FindingsFirstSeen = %v, want lodash first seen with the old result", seen)
+	}
+
+	for _, entry := range []*models.ActivityLog{
+		{Action: models.ActivityAppDataPurged, AppName: "shop", CreatedAt: now.Add(-48 * time.Hour)},
+		{Action: models.ActivityAppDataPurged, AppName: "blog", CreatedAt: now.Add(-48 * time.Hour)},
+	} {
+		if err := st.SaveActivity(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	purge := &models.DataPurge{AppName: "shop", Before: now.Add(-24 * time.Hour)}
+	if _, err := st.PurgeAppData(purge); err != nil {
+		t.Fatal(err)
+	}
+	if purge.AuditResults != 1 || purge.Vulnerabilities != 2 || purge.Activities != 1 || !purge.NotificationState || purge.KeptNotificationState {
+		t.Errorf("purge = %+v, want the old result and its 2 vulnerabilities, the activity of shop and its notification state", purge)
+	}
+	apps, err = st.Apps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i := slices.IndexFunc(apps, func(app models.App) bool { return app.Name == "shop" }); i < 0 || apps[i].HasNotificationState() {
+		t.Errorf("apps after the purge = %+v, want the notification state of shop forgotten", apps)
+	}
+	if results, err := st.AuditResults(); err != nil || len(results) != 1 || results[0].ID != latest.ID {
+		t.Errorf("AuditResults after the purge = %+v, %v, want the latest result", results, err)
+	}
+
+	packages := []models.AppPackage{
+		{AppName: "shop", Ecosystem: "npm", Name: "lodash", Version: "4.17.20"},
+		{AppName: "shop", Ecosystem: "npm", Name: "express", Version: "4.18.2"},
+	}
+	if err := st.SaveAppPackages("shop", packages); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.SaveAppPackages("shop", packages[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := st.AppPackages("npm", []string{"lodash", "express"}); err != nil || len(found) != 1 || found[0].Version != "4.17.20" {
+		t.Errorf("AppPackages = %+v, %v, want the packages replaced by lodash", found, err)
+	}
+
+	if entry, err := st.CachedAIAnalysis("key"); err != nil || entry != nil {
+		t.Errorf("CachedAIAnalysis = %+v, %v, want none", entry, err)
+	}
+	if err := st.SaveCachedAIAnalysis(&models.CachedAIAnalysis{Key: "key", AppName: "shop", Analysis: "{}", CreatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	if entry, err := st.CachedAIAnalysis("key"); err != nil || entry == nil || entry.Analysis != "{}" {
+		t.Errorf("CachedAIAnalysis = %+v, %v, want the saved analysis", entry, err)
+	}
+	if err := st.PurgeCachedAIAnalyses(now.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if entry, err := st.CachedAIAnalysis("key"); err != nil || entry != nil {
+		t.Errorf("CachedAIAnalysis after the purge = %+v, %v, want none", entry, err)
+	}
+
+	if _, ok, err := st.Setting("status_token"); err != nil || ok {
+		t.Errorf("Setting = %v, %v, want it unset", ok, err)
+	}
+	for _, value := range []string{"a", "b"} {
+		if err := st.SaveSetting("status_token", value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if value, ok, err := st.Setting("status_token"); err != nil || !ok || value != "b" {
+		t.Errorf("Setting = %q, %v, %v, want the value saved last", value, ok, err)
+	}
+}
@@ -0,0 +1,232 @@
+package testharness
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// MockTelegramToken is the bot token the mock Telegram server accepts
+const MockTelegramToken = "123456:testharness"
+
+// TelegramCall is a Bot API request received by the mock Telegram server
+type TelegramCall struct {
+	Method string            // e.g. sendMessage, createForumTopic
+	Params map[string]string // form fields; uploaded files are listed by field name with their file name
+}
+
+// MockTelegram is a Bot API server recording requests. It answers getMe, getChat,
+// getChatMember, createForumTopic, sendMessage and sendMediaGroup like Telegram does,
+// and any other method with an empty successful result. The group is a forum where
+// the bot is an admin with the Manage Topics right unless set otherwise.
+type MockTelegram struct {
+	Server *httptest.Server
+
+	calls       []TelegramCall
+	failures    map[string]string // method -> error description
+	isForum     bool
+	permissions map[string]any // default permissions of the members of the group
+	member      map[string]any // the bot's chat member
+	topicID     int
+	messageID   int
+	mu          sync.Mutex
+}
+
+// NewMockTelegram starts a mock Telegram server, stopped when the test ends
+func NewMockTelegram(t testing.TB) *MockTelegram {
+	t.Helper()
+
+	m := &MockTelegram{
+		failures:    make(map[string]string),
+		isForum:     true,
+		permissions: map[string]any{"can_send_messages": true, "can_send_documents": true},
+		member:      map[string]any{"status": "administrator", "can_manage_topics": true},
+		topicID:     100,
+	}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Endpoint returns the API endpoint to pass to notifier.NewTelegramNotifierWithEndpoint
+func (m *MockTelegram) Endpoint() string {
+	return m.Server.URL + "/bot%s/%s"
+}
+
+// Notifier returns a Telegram notifier posting to groupID through the mock server
+func (m *MockTelegram) Notifier(t testing.TB, groupID int64) *notifier.TelegramNotifier {
+	t.Helper()
+
+	n, err := notifier.NewTelegramNotifierWithEndpoint(MockTelegramToken, groupID, true, m.Endpoint())
+	if err != nil {
+		t.Fatalf("testharness: failed to create Telegram notifier: %v", err)
+	}
+	return n
+}
+
+// Fail makes every following call of method fail with description, as Telegram
+// reports errors (e.g. "Bad Request: message thread not found")
+func (m *MockTelegram) Fail(method, description string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures[method] = description
+}
+
+// SetGroup sets whether the group is a forum and the default permissions of its
+// members reported by getChat, e.g. {"can_send_messages": true, "can_send_documents": false}
+func (m *MockTelegram) SetGroup(isForum bool, permissions map[string]any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.isForum, m.permissions = isForum, permissions
+}
+
+// SetBotMember sets the bot's chat member reported by getChatMember, e.g.
+// {"status": "administrator", "can_manage_topics": false} or {"status": "member"}
+func (m *MockTelegram) SetBotMember(member map[string]any) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.member = member
+}
+
+// Calls returns the requests received, in order. getMe, sent when a notifier is created, is left out.
+func (m *MockTelegram) Calls() []TelegramCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	calls := make([]TelegramCall, 0, len(m.calls))
+	for _, call := range m.calls {
+		if call.Method != "getMe" {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Messages returns the text of the messages sent, in order
+func (m *MockTelegram) Messages() []string {
+	var messages []string
+	for _, call := range m.Calls() {
+		if call.Method == "sendMessage" {
+			messages = append(messages, call.Params["text"])
+		}
+	}
+	return messages
+}
+
+// handle answers a Bot API request: /bot<token>/<method>
+func (m *MockTelegram) handle(w http.ResponseWriter, r *http.Request) {
+	token, method, ok := strings.Cut(strings.TrimPrefix(r.URL.Path, "/bot"), "/")
+	if !ok || token != MockTelegramToken {
+		writeTelegramResponse(w, false, nil, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	params := make(map[string]string)
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		if err := r.ParseMultipartForm(32 << 20); err == nil {
+			for key, files := range r.MultipartForm.File {
+				params[key] = files[0].Filename
+			}
+		}
+	} else {
+		_ = r.ParseForm()
+	}
+	for key := range r.Form {
+		params[key] = r.Form.Get(key)
+	}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, TelegramCall{Method: method, Params: params})
+	description, fail := m.failures[method]
+	m.mu.Unlock()
+
+	if fail {
+		writeTelegramResponse(w, false, nil, http.StatusBadRequest, description)
+		return
+	}
+
+	switch method {
+	case "getMe":
+		writeTelegramResponse(w, true, map[string]any{
+			"id": 123456, "is_bot": true, "first_name": "Audit Checks", "username": "audit_checks_test_bot",
+		}, 0, "")
+	case "getChat":
+		m.mu.Lock()
+		chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+		chat := map[string]any{
+			"id": chatID, "type": "supergroup", "title": "Audit Checks Test",
+			"is_forum": m.isForum, "permissions": m.permissions,
+		}
+		m.mu.Unlock()
+		writeTelegramResponse(w, true, chat, 0, "")
+	case "getChatMember":
+		m.mu.Lock()
+		member := map[string]any{"user": map[string]any{"id": 123456, "is_bot": true, "first_name": "Audit Checks"}}
+		for key, value := range m.member {
+			member[key] = value
+		}
+		m.mu.Unlock()
+		writeTelegramResponse(w, true, member, 0, "")
+	case "createForumTopic":
+		m.mu.Lock()
+		m.topicID++
+		topicID := m.topicID
+		m.mu.Unlock()
+		writeTelegramResponse(w, true, map[string]any{
+			"message_thread_id": topicID, "name": params["name"], "icon_color": 7322096,
+		}, 0, "")
+	case "sendMessage":
+		writeTelegramResponse(w, true, m.message(params), 0, "")
+	case "sendMediaGroup":
+		var media []json.RawMessage
+		_ = json.Unmarshal([]byte(params["media"]), &media)
+		messages := make([]map[string]any, 0, len(media))
+		for range media {
+			messages = append(messages, m.message(params))
+		}
+		writeTelegramResponse(w, true, messages, 0, "")
+	default:
+		writeTelegramResponse(w, true, true, 0, "")
+	}
+}
+
+// message returns a sent message for the request parameters
+func (m *MockTelegram) message(params map[string]string) map[string]any {
+	m.mu.Lock()
+	m.messageID++
+	id := m.messageID
+	m.mu.Unlock()
+
+	chatID, _ := strconv.ParseInt(params["chat_id"], 10, 64)
+	threadID, _ := strconv.Atoi(params["message_thread_id"])
+	return map[string]any{
+		"message_id":        id,
+		"message_thread_id": threadID,
+		"date":              0,
+		"chat":              map[string]any{"id": chatID, "type": "supergroup", "is_forum": true},
+		"text":              params["text"],
+	}
+}
+
+// writeTelegramResponse writes a Bot API response
+func writeTelegramResponse(w http.ResponseWriter, ok bool, result any, code int, description string) {
+	response := map[string]any{"ok": ok}
+	if ok {
+		response["result"] = result
+	} else {
+		response["error_code"] = code
+		response["description"] = description
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(code)
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,73 @@
+package testharness_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestMockTelegram(t *testing.T) {
+	ctx := context.Background()
+	tg := testharness.NewMockTelegram(t)
+	n := tg.Notifier(t, -100123)
+
+	topicID, err := n.SendCombinedToTopic(ctx, criticalReport(), "shop", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if topicID != 101 {
+		t.Errorf("topic ID = %d, want 101, the first created by the mock", topicID)
+	}
+
+	// The existing topic is reused
+	if _, err := n.SendCombinedToTopic(ctx, criticalReport(), "shop", topicID); err != nil {
+		t.Fatal(err)
+	}
+	calls := tg.Calls()
+	if len(calls) != 3 || calls[0].Method != "createForumTopic" || calls[0].Params["chat_id"] != "-100123" {
+		t.Fatalf("calls = %+v, want a topic and two summaries", calls)
+	}
+	for _, call := range calls[1:] {
+		if call.Params["message_thread_id"] != strconv.Itoa(topicID) || !strings.Contains(call.Params["text"], "lodash (CRITICAL)") {
+			t.Errorf("call = %+v, want the summary of shop in its topic", call)
+		}
+	}
+
+	if err := n.SendTestMessage(ctx, "hello"); err != nil {
+		t.Fatal(err)
+	}
+	if messages := tg.Messages(); len(messages) == 0 || messages[len(messages)-1] != "hello" {
+		t.Errorf("messages = %q, want the test message last", messages)
+	}
+
+	tg.Fail("sendMessage", "Bad Request: chat not found")
+	if err := n.SendTestMessage(ctx, "hello"); err == nil || !strings.Contains(err.Error(), "chat not found") {
+		t.Errorf("SendTestMessage error = %v, want the Telegram error", err)
+	}
+}
+
+func TestMockTelegramGroup(t *testing.T) {
+	tg := testharness.NewMockTelegram(t)
+	n := tg.Notifier(t, -100123)
+
+	check, err := n.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !check.Ready() || check.BotUsername != "audit_checks_test_bot" {
+		t.Errorf("check = %+v, want a ready forum", check)
+	}
+
+	tg.SetGroup(false, map[string]any{"can_send_messages": true, "can_send_documents": false})
+	tg.SetBotMember(map[string]any{"status": "member"})
+	check, err = n.Check()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if check.Ready() || check.IsForum || check.CanManageTopics || check.CanSendDocuments {
+		t.Errorf("check = %+v, want a group without topics where documents can't be sent", check)
+	}
+}
@@ -0,0 +1,102 @@
+package testharness
+
+import (
+	"crypto/hmac"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// MockWebhookSecret is the secret the notifiers of MockWebhook sign requests with
+const MockWebhookSecret = "testharness-secret"
+
+// WebhookDelivery is a request received by the mock webhook receiver
+type WebhookDelivery struct {
+	Event       string
+	Delivery    string
+	SignatureOK bool // the signature header matches the body signed with MockWebhookSecret
+	Body        []byte
+}
+
+// MockWebhook is a webhook receiver recording the deliveries
+type MockWebhook struct {
+	Server *httptest.Server
+
+	deliveries []WebhookDelivery
+	failStatus int
+	mu         sync.Mutex
+}
+
+// NewMockWebhook starts a mock webhook receiver, stopped when the test ends
+func NewMockWebhook(t testing.TB) *MockWebhook {
+	t.Helper()
+
+	m := &MockWebhook{}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// URL returns the receiver's URL
+func (m *MockWebhook) URL() string {
+	return m.Server.URL + "/hook"
+}
+
+// Notifier returns a webhook notifier delivering every app's notifications to the
+// receiver, signed with MockWebhookSecret
+func (m *MockWebhook) Notifier() *notifier.WebhookNotifier {
+	return notifier.NewWebhookNotifier([]string{m.URL()}, MockWebhookSecret, true)
+}
+
+// Fail makes every following delivery fail with status
+func (m *MockWebhook) Fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus = status
+}
+
+// Deliveries returns the deliveries received, in order
+func (m *MockWebhook) Deliveries() []WebhookDelivery {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]WebhookDelivery(nil), m.deliveries...)
+}
+
+// handle answers POST /hook
+func (m *MockWebhook) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/hook" {
+		http.NotFound(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	signature := notifier.WebhookSignature(MockWebhookSecret, body)
+	delivery := WebhookDelivery{
+		Event:       r.Header.Get(notifier.WebhookEventHeader),
+		Delivery:    r.Header.Get(notifier.WebhookDeliveryHeader),
+		SignatureOK: hmac.Equal([]byte(r.Header.Get(notifier.WebhookSignatureHeader)), []byte(signature)),
+		Body:        body,
+	}
+
+	m.mu.Lock()
+	status := m.failStatus
+	if status == 0 {
+		m.deliveries = append(m.deliveries, delivery)
+	}
+	m.mu.Unlock()
+
+	if status != 0 {
+		http.Error(w, "testharness failure", status)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,37 @@
+package testharness_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestMockWebhook(t *testing.T) {
+	hook := testharness.NewMockWebhook(t)
+
+	if _, err := notifyCombined(t, hook.Notifier(), criticalReport(), models.NotificationConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	deliveries := hook.Deliveries()
+	if len(deliveries) != 1 {
+		t.Fatalf("deliveries = %+v, want 1", deliveries)
+	}
+	delivery := deliveries[0]
+	if !delivery.SignatureOK || delivery.Event == "" || delivery.Delivery == "" {
+		t.Errorf("delivery = %+v, want a signed event with its ID", delivery)
+	}
+	var payload struct {
+		AppName string `json:"app_name"`
+	}
+	if err := json.Unmarshal(delivery.Body, &payload); err != nil || payload.AppName != "shop" {
+		t.Errorf("body = %s, want the report of shop", delivery.Body)
+	}
+
+	hook.Fail(http.StatusBadGateway)
+	if _, err := notifyCombined(t, hook.Notifier(), criticalReport(), models.NotificationConfig{}); err == nil {
+		t.Error("NotifyAllCombined succeeded with a failing receiver")
+	}
+}
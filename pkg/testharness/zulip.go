@@ -0,0 +1,143 @@
+package testharness
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/notifier"
+)
+
+// Mock Zulip bot credentials and stream
+const (
+	MockZulipBotEmail = "audit-bot@testharness.zulipchat.com"
+	MockZulipAPIKey   = "testharness-api-key"
+	MockZulipStream   = "security"
+)
+
+// ZulipMessage is a stream message sent to the mock Zulip server
+type ZulipMessage struct {
+	Stream  string
+	Topic   string
+	Content string
+}
+
+// ZulipUpload is a file uploaded to the mock Zulip server
+type ZulipUpload struct {
+	Filename string
+	Content  []byte
+	URI      string
+}
+
+// MockZulip is a Zulip server recording the messages sent to /api/v1/messages and the
+// files uploaded to /api/v1/user_uploads
+type MockZulip struct {
+	Server *httptest.Server
+
+	messages   []ZulipMessage
+	uploads    []ZulipUpload
+	failStatus int
+	mu         sync.Mutex
+}
+
+// NewMockZulip starts a mock Zulip server, stopped when the test ends
+func NewMockZulip(t testing.TB) *MockZulip {
+	t.Helper()
+
+	m := &MockZulip{}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.handle))
+	t.Cleanup(m.Server.Close)
+	return m
+}
+
+// Notifier returns a Zulip notifier posting as the mock bot to MockZulipStream
+func (m *MockZulip) Notifier() *notifier.ZulipNotifier {
+	return notifier.NewZulipNotifier(m.Server.URL, MockZulipBotEmail, MockZulipAPIKey, MockZulipStream, true)
+}
+
+// Fail makes every following request fail with status
+func (m *MockZulip) Fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failStatus = status
+}
+
+// Messages returns the messages sent, in order
+func (m *MockZulip) Messages() []ZulipMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ZulipMessage(nil), m.messages...)
+}
+
+// Uploads returns the files uploaded, in order
+func (m *MockZulip) Uploads() []ZulipUpload {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ZulipUpload(nil), m.uploads...)
+}
+
+// handle answers POST /api/v1/messages and POST /api/v1/user_uploads
+func (m *MockZulip) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if email, key, ok := r.BasicAuth(); !ok || email != MockZulipBotEmail || key != MockZulipAPIKey {
+		writeZulipError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid API key")
+		return
+	}
+	if r.Method != http.MethodPost || (r.URL.Path != "/api/v1/messages" && r.URL.Path != "/api/v1/user_uploads") {
+		writeZulipError(w, http.StatusNotFound, "BAD_REQUEST", "Not Found")
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.failStatus != 0 {
+		writeZulipError(w, m.failStatus, "BAD_REQUEST", "testharness failure")
+		return
+	}
+
+	if r.URL.Path == "/api/v1/user_uploads" {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			writeZulipError(w, http.StatusBadRequest, "BAD_REQUEST", "You must specify a file to upload")
+			return
+		}
+		defer file.Close()
+		content, _ := io.ReadAll(file)
+		uri := fmt.Sprintf("/user_uploads/2/%02d/%s", len(m.uploads)+1, header.Filename)
+		m.uploads = append(m.uploads, ZulipUpload{Filename: header.Filename, Content: content, URI: uri})
+		_ = json.NewEncoder(w).Encode(map[string]any{"result": "success", "msg": "", "uri": uri})
+		return
+	}
+
+	if r.FormValue("type") != "stream" || r.FormValue("to") == "" {
+		writeZulipError(w, http.StatusBadRequest, "BAD_REQUEST", "Missing 'to' argument")
+		return
+	}
+	if r.FormValue("topic") == "" {
+		writeZulipError(w, http.StatusBadRequest, "BAD_REQUEST", "Missing topic")
+		return
+	}
+	if r.FormValue("to") != MockZulipStream {
+		writeZulipError(w, http.StatusBadRequest, "STREAM_DOES_NOT_EXIST", fmt.Sprintf("Stream '%s' does not exist", r.FormValue("to")))
+		return
+	}
+	m.messages = append(m.messages, ZulipMessage{
+		Stream:  r.FormValue("to"),
+		Topic:   r.FormValue("topic"),
+		Content: r.FormValue("content"),
+	})
+
+	_ = json.NewEncoder(w).Encode(map[string]any{"result": "success", "msg": "", "id": len(m.messages)})
+}
+
+// writeZulipError writes an error response as the Zulip API does
+func writeZulipError(w http.ResponseWriter, status int, code, msg string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]any{"result": "error", "msg": msg, "code": code})
+}
@@ -0,0 +1,43 @@
+package testharness_test
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shadowbane/audit-checks/pkg/models"
+	"github.com/shadowbane/audit-checks/pkg/testharness"
+)
+
+func TestMockZulip(t *testing.T) {
+	zulip := testharness.NewMockZulip(t)
+
+	report := criticalReport()
+	file := filepath.Join(t.TempDir(), "shop-npm.md")
+	if err := os.WriteFile(file, []byte("# shop"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report.ReportFiles = append(report.ReportFiles, file)
+
+	if _, err := notifyCombined(t, zulip.Notifier(), report, models.NotificationConfig{ZulipEnabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	uploads := zulip.Uploads()
+	if len(uploads) != 1 || uploads[0].Filename != "shop-npm.md" || string(uploads[0].Content) != "# shop" {
+		t.Fatalf("uploads = %+v, want the report file", uploads)
+	}
+	messages := zulip.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("messages = %+v, want 1", messages)
+	}
+	if m := messages[0]; m.Stream != testharness.MockZulipStream || m.Topic != "shop" || !strings.Contains(m.Content, "lodash") || !strings.Contains(m.Content, uploads[0].URI) {
+		t.Errorf("message = %+v, want the summary of shop linking its report in the shop topic", m)
+	}
+
+	zulip.Fail(http.StatusBadRequest)
+	if _, err := notifyCombined(t, zulip.Notifier(), criticalReport(), models.NotificationConfig{ZulipEnabled: true}); err == nil {
+		t.Error("NotifyAllCombined succeeded with a failing Zulip")
+	}
+}
@@ -0,0 +1,236 @@
+package updater
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/shadowbane/audit-checks/pkg/helpers"
+	"go.uber.org/zap"
+)
+
+const (
+	latestReleaseURL = "https://api.github.com/repos/shadowbane/audit-checks/releases/latest"
+	checksumsAsset   = "checksums.txt"
+	binaryPrefix     = "audit-checks"
+)
+
+// Release represents a GitHub release
+type Release struct {
+	TagName string  `json:"tag_name"`
+	Name    string  `json:"name"`
+	HTMLURL string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset represents a downloadable file attached to a GitHub release
+type Asset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
+}
+
+// FindAsset returns the asset with the given name
+func (r *Release) FindAsset(name string) (*Asset, bool) {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i], true
+		}
+	}
+	return nil, false
+}
+
+// Updater checks for and installs new releases from GitHub
+type Updater struct {
+	currentVersion string
+	client         *http.Client
+}
+
+// New creates a new Updater for the given running version
+func New(currentVersion string) *Updater {
+	return &Updater{
+		currentVersion: currentVersion,
+		client: &http.Client{
+			Timeout: 5 * time.Minute,
+		},
+	}
+}
+
+// IsDevBuild returns true if the running binary was not built from a release tag
+func (u *Updater) IsDevBuild() bool {
+	return helpers.ExtractVersion(u.currentVersion) == ""
+}
+
+// LatestRelease fetches the latest published release
+func (u *Updater) LatestRelease(ctx context.Context) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", latestReleaseURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API error: status %d", resp.StatusCode)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+
+	return &release, nil
+}
+
+// IsNewer returns true if the release is newer than the running version.
+// Development builds are never considered outdated.
+func (u *Updater) IsNewer(release *Release) bool {
+	if u.IsDevBuild() {
+		return false
+	}
+	return helpers.CompareVersions(release.TagName, u.currentVersion) > 0
+}
+
+// CheckForUpdate returns the latest release if it is newer than the running version.
+// Returns nil if the running version is up to date.
+func (u *Updater) CheckForUpdate(ctx context.Context) (*Release, error) {
+	release, err := u.LatestRelease(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !u.IsNewer(release) {
+		return nil, nil
+	}
+
+	return release, nil
+}
+
+// AssetName returns the release asset name for the current platform
+func AssetName() string {
+	return fmt.Sprintf("%s-%s-%s", binaryPrefix, runtime.GOOS, runtime.GOARCH)
+}
+
+// Update downloads the release binary for the current platform, verifies its
+// SHA-256 checksum against the release checksums file, and replaces the
+// running executable. Returns the path of the replaced executable.
+func (u *Updater) Update(ctx context.Context, release *Release) (string, error) {
+	assetName := AssetName()
+
+	asset, ok := release.FindAsset(assetName)
+	if !ok {
+		return "", fmt.Errorf("release %s has no binary for %s/%s", release.TagName, runtime.GOOS, runtime.GOARCH)
+	}
+
+	checksums, ok := release.FindAsset(checksumsAsset)
+	if !ok {
+		return "", fmt.Errorf("release %s has no %s; refusing to install an unverified binary", release.TagName, checksumsAsset)
+	}
+
+	expected, err := u.fetchChecksum(ctx, checksums.BrowserDownloadURL, assetName)
+	if err != nil {
+		return "", err
+	}
+
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate running executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+		exePath = resolved
+	}
+
+	// Download next to the executable so the final rename stays on one filesystem
+	tmpFile, err := os.CreateTemp(filepath.Dir(exePath), ".audit-checks-update-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	zap.S().Infof("Downloading %s from %s", assetName, asset.BrowserDownloadURL)
+
+	actual, err := u.download(ctx, asset.BrowserDownloadURL, tmpFile)
+	tmpFile.Close()
+	if err != nil {
+		return "", err
+	}
+
+	if actual != expected {
+		return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", assetName, expected, actual)
+	}
+
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return "", fmt.Errorf("failed to make binary executable: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, exePath); err != nil {
+		return "", fmt.Errorf("failed to replace %s: %w", exePath, err)
+	}
+
+	zap.S().Infof("Updated %s to %s", exePath, release.TagName)
+
+	return exePath, nil
+}
+
+// download streams a URL into w and returns the hex SHA-256 of the content
+func (u *Updater) download(ctx context.Context, url string, w io.Writer) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: status %d", url, resp.StatusCode)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w, hash), resp.Body); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// fetchChecksum downloads the checksums file and returns the checksum for assetName.
+// The file uses the sha256sum format: "<hex>  <filename>" per line.
+func (u *Updater) fetchChecksum(ctx context.Context, url, assetName string) (string, error) {
+	var buf strings.Builder
+	if _, err := u.download(ctx, url, &buf); err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(buf.String()))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if strings.TrimPrefix(fields[1], "*") == assetName {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+
+	return "", fmt.Errorf("no checksum found for %s in %s", assetName, checksumsAsset)
+}